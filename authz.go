@@ -61,6 +61,16 @@ func TaskResource(orgID ID) resource {
 	return resource(fmt.Sprintf("org/%s/task", orgID))
 }
 
+// ListenerResource represents the listener resource scoped to an organization.
+func ListenerResource(orgID ID) resource {
+	return resource(fmt.Sprintf("org/%s/listener", orgID))
+}
+
+// ReplicationResource represents the replication resource scoped to an organization.
+func ReplicationResource(orgID ID) resource {
+	return resource(fmt.Sprintf("org/%s/replication", orgID))
+}
+
 // BucketResource constructs a bucket resource.
 func BucketResource(id ID) resource {
 	return resource(fmt.Sprintf("bucket/%s", id))
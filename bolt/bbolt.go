@@ -154,6 +154,16 @@ func (c *Client) initialize(ctx context.Context) error {
 			return err
 		}
 
+		// Always create Listener bucket.
+		if err := c.initializeListeners(ctx, tx); err != nil {
+			return err
+		}
+
+		// Always create Replication bucket.
+		if err := c.initializeReplications(ctx, tx); err != nil {
+			return err
+		}
+
 		// Always create UserResourceMapping bucket.
 		if err := c.initializeUserResourceMappings(ctx, tx); err != nil {
 			return err
@@ -174,6 +184,11 @@ func (c *Client) initialize(ctx context.Context) error {
 			return err
 		}
 
+		// Always create BucketPurge bucket.
+		if err := c.initializeBucketPurges(ctx, tx); err != nil {
+			return err
+		}
+
 		return nil
 	}); err != nil {
 		return err
@@ -409,6 +409,18 @@ func (c *Client) updateBucket(ctx context.Context, tx *bolt.Tx, id platform.ID,
 		b.RetentionPeriod = *upd.RetentionPeriod
 	}
 
+	if upd.PartitionDuration != nil {
+		b.PartitionDuration = *upd.PartitionDuration
+	}
+
+	if upd.SchemaType != nil {
+		b.SchemaType = *upd.SchemaType
+	}
+
+	if upd.Schema != nil {
+		b.Schema = upd.Schema
+	}
+
 	if upd.Name != nil {
 		key, err := bucketIndexKey(b)
 		if err != nil {
@@ -463,6 +475,9 @@ func (c *Client) deleteBucket(ctx context.Context, tx *bolt.Tx, id platform.ID)
 	if err := tx.Bucket(bucketBucket).Delete(encodedID); err != nil {
 		return err
 	}
+	if err := c.putBucketPurge(ctx, tx, b.OrganizationID, id); err != nil {
+		return err
+	}
 	return c.deleteUserResourceMappings(ctx, tx, platform.UserResourceMappingFilter{
 		ResourceID:   id,
 		ResourceType: platform.BucketResourceType,
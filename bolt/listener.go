@@ -0,0 +1,135 @@
+package bolt
+
+import (
+	"context"
+	"encoding/json"
+
+	bolt "github.com/coreos/bbolt"
+	"github.com/influxdata/platform"
+	kerrors "github.com/influxdata/platform/kit/errors"
+)
+
+var (
+	listenerBucket = []byte("listenersv1")
+)
+
+var _ platform.ListenerService = (*Client)(nil)
+
+func (c *Client) initializeListeners(ctx context.Context, tx *bolt.Tx) error {
+	if _, err := tx.CreateBucketIfNotExists(listenerBucket); err != nil {
+		return err
+	}
+	return nil
+}
+
+// FindListeners returns all listeners.
+func (c *Client) FindListeners(ctx context.Context) ([]*platform.Listener, error) {
+	listeners := []*platform.Listener{}
+	err := c.db.View(func(tx *bolt.Tx) error {
+		cur := tx.Bucket(listenerBucket).Cursor()
+		for k, v := cur.First(); k != nil; k, v = cur.Next() {
+			l := &platform.Listener{}
+			if err := json.Unmarshal(v, l); err != nil {
+				return err
+			}
+			listeners = append(listeners, l)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return listeners, nil
+}
+
+// FindListenerByID finds a single listener by its ID.
+func (c *Client) FindListenerByID(ctx context.Context, id platform.ID) (*platform.Listener, error) {
+	var l *platform.Listener
+	err := c.db.View(func(tx *bolt.Tx) error {
+		var err error
+		l, err = c.findListenerByID(ctx, tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (c *Client) findListenerByID(ctx context.Context, tx *bolt.Tx, id platform.ID) (*platform.Listener, error) {
+	encID, err := id.Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	v := tx.Bucket(listenerBucket).Get(encID)
+	if v == nil {
+		return nil, kerrors.Errorf(kerrors.NotFound, "listener with ID %v not found", id)
+	}
+
+	l := &platform.Listener{}
+	if err := json.Unmarshal(v, l); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// CreateListener creates a new listener and assigns it an ID.
+func (c *Client) CreateListener(ctx context.Context, l *platform.Listener) error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		l.ID = c.IDGenerator.ID()
+		return c.putListener(ctx, tx, l)
+	})
+}
+
+// PutListener puts a listener without setting its ID.
+func (c *Client) PutListener(ctx context.Context, l *platform.Listener) error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		return c.putListener(ctx, tx, l)
+	})
+}
+
+func (c *Client) putListener(ctx context.Context, tx *bolt.Tx, l *platform.Listener) error {
+	v, err := json.Marshal(l)
+	if err != nil {
+		return err
+	}
+	encID, err := l.ID.Encode()
+	if err != nil {
+		return err
+	}
+	return tx.Bucket(listenerBucket).Put(encID, v)
+}
+
+// UpdateListener updates a single listener with a changeset.
+func (c *Client) UpdateListener(ctx context.Context, id platform.ID, upd platform.ListenerUpdate) (*platform.Listener, error) {
+	var l *platform.Listener
+	err := c.db.Update(func(tx *bolt.Tx) error {
+		var err error
+		if l, err = c.findListenerByID(ctx, tx, id); err != nil {
+			return err
+		}
+		if err := upd.Apply(l); err != nil {
+			return kerrors.InvalidDataf("%v", err)
+		}
+		return c.putListener(ctx, tx, l)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// DeleteListener removes a listener by its ID.
+func (c *Client) DeleteListener(ctx context.Context, id platform.ID) error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		if _, err := c.findListenerByID(ctx, tx, id); err != nil {
+			return err
+		}
+		encID, err := id.Encode()
+		if err != nil {
+			return err
+		}
+		return tx.Bucket(listenerBucket).Delete(encID)
+	})
+}
@@ -0,0 +1,31 @@
+package bolt_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/platform"
+	platformtesting "github.com/influxdata/platform/testing"
+)
+
+func initListenerService(f platformtesting.ListenerFields, t *testing.T) (platform.ListenerService, func()) {
+	c, closeFn, err := NewTestClient()
+	if err != nil {
+		t.Fatalf("failed to create new bolt test client: %v", err)
+	}
+
+	c.IDGenerator = f.IDGenerator
+	ctx := context.TODO()
+
+	for _, l := range f.Listeners {
+		if err := c.PutListener(ctx, l); err != nil {
+			t.Fatalf("failed to populate test listeners: %v", err)
+		}
+	}
+
+	return c, closeFn
+}
+
+func TestListenerService(t *testing.T) {
+	platformtesting.ListenerService(initListenerService, t)
+}
@@ -304,7 +304,10 @@ func (c *Client) DeleteOrganization(ctx context.Context, id platform.ID) error {
 		if err := c.deleteOrganizationsBuckets(ctx, tx, id); err != nil {
 			return err
 		}
-		return c.deleteOrganization(ctx, tx, id)
+		if err := c.deleteOrganization(ctx, tx, id); err != nil {
+			return err
+		}
+		return c.putBucketPurge(ctx, tx, id, platform.InvalidID())
 	})
 }
 
@@ -0,0 +1,142 @@
+package bolt
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"time"
+
+	bolt "github.com/coreos/bbolt"
+	"github.com/influxdata/platform"
+)
+
+var (
+	bucketPurgeBucket = []byte("bucketpurgesv1")
+)
+
+var _ platform.BucketPurgeService = (*Client)(nil)
+
+func (c *Client) initializeBucketPurges(ctx context.Context, tx *bolt.Tx) error {
+	if _, err := tx.CreateBucketIfNotExists(bucketPurgeBucket); err != nil {
+		return err
+	}
+	return nil
+}
+
+// FindBucketPurges returns all purges that have yet to complete, oldest first.
+func (c *Client) FindBucketPurges(ctx context.Context) ([]*platform.BucketPurge, error) {
+	var ps []*platform.BucketPurge
+	err := c.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketPurgeBucket).ForEach(func(k, v []byte) error {
+			p, err := decodeBucketPurge(k, v)
+			if err != nil {
+				return err
+			}
+			ps = append(ps, p)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(ps, func(i, j int) bool {
+		return ps[i].CreatedAt.Before(ps[j].CreatedAt)
+	})
+	return ps, nil
+}
+
+// DeleteBucketPurge marks the purge of the given bucket, or of the whole organization
+// if bucketID is invalid, as complete.
+func (c *Client) DeleteBucketPurge(ctx context.Context, orgID, bucketID platform.ID) error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		key, err := encodeBucketPurgeKey(orgID, bucketID)
+		if err != nil {
+			return err
+		}
+		return tx.Bucket(bucketPurgeBucket).Delete(key)
+	})
+}
+
+// putBucketPurge records that the data of the given bucket must be purged.
+// If bucketID is invalid, the data of every bucket of the organization must be purged,
+// which supersedes any purge of a single bucket of the organization.
+func (c *Client) putBucketPurge(ctx context.Context, tx *bolt.Tx, orgID, bucketID platform.ID) error {
+	b := tx.Bucket(bucketPurgeBucket)
+	key, err := encodeBucketPurgeKey(orgID, bucketID)
+	if err != nil {
+		return err
+	}
+
+	if !bucketID.Valid() {
+		// Collect the keys first, as deleting while iterating would skip keys.
+		var keys [][]byte
+		prefix := key[:platform.IDLength]
+		cur := b.Cursor()
+		for k, _ := cur.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = cur.Next() {
+			keys = append(keys, k)
+		}
+		for _, k := range keys {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+	} else {
+		orgKey, err := encodeBucketPurgeKey(orgID, 0)
+		if err != nil {
+			return err
+		}
+		if b.Get(orgKey) != nil {
+			// The whole organization is already being purged.
+			return nil
+		}
+	}
+
+	createdAt, err := c.time().UTC().MarshalText()
+	if err != nil {
+		return err
+	}
+	return b.Put(key, createdAt)
+}
+
+// encodeBucketPurgeKey returns the key of a purge: the organization ID followed by the bucket ID,
+// which is all zeros when the whole organization is purged.
+func encodeBucketPurgeKey(orgID, bucketID platform.ID) ([]byte, error) {
+	key := make([]byte, 2*platform.IDLength)
+
+	o, err := orgID.Encode()
+	if err != nil {
+		return nil, err
+	}
+	copy(key, o)
+
+	if bucketID.Valid() {
+		b, err := bucketID.Encode()
+		if err != nil {
+			return nil, err
+		}
+		copy(key[platform.IDLength:], b)
+	} else {
+		copy(key[platform.IDLength:], bytes.Repeat([]byte{'0'}, platform.IDLength))
+	}
+	return key, nil
+}
+
+func decodeBucketPurge(k, v []byte) (*platform.BucketPurge, error) {
+	p := &platform.BucketPurge{}
+	if err := p.OrganizationID.Decode(k[:platform.IDLength]); err != nil {
+		return nil, err
+	}
+	if b := k[platform.IDLength:]; !bytes.Equal(b, bytes.Repeat([]byte{'0'}, platform.IDLength)) {
+		if err := p.BucketID.Decode(b); err != nil {
+			return nil, err
+		}
+	}
+
+	var t time.Time
+	if err := t.UnmarshalText(v); err != nil {
+		return nil, err
+	}
+	p.CreatedAt = t
+	return p, nil
+}
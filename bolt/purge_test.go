@@ -0,0 +1,68 @@
+package bolt_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/platform"
+)
+
+func TestClient_BucketPurges(t *testing.T) {
+	c, closeFn, err := NewTestClient()
+	if err != nil {
+		t.Fatalf("failed to create new bolt client: %v", err)
+	}
+	defer closeFn()
+	ctx := context.Background()
+
+	org := &platform.Organization{Name: "o"}
+	if err := c.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+	var buckets []*platform.Bucket
+	for _, name := range []string{"b1", "b2"} {
+		b := &platform.Bucket{Name: name, OrganizationID: org.ID}
+		if err := c.CreateBucket(ctx, b); err != nil {
+			t.Fatal(err)
+		}
+		buckets = append(buckets, b)
+	}
+
+	// Deleting a bucket records a purge of its data.
+	if err := c.DeleteBucket(ctx, buckets[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	ps, err := c.FindBucketPurges(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ps) != 1 || ps[0].OrganizationID != org.ID || ps[0].BucketID != buckets[0].ID {
+		t.Fatalf("expected a purge of bucket %s, got %+v", buckets[0].ID, ps)
+	}
+	if ps[0].CreatedAt.IsZero() {
+		t.Fatal("expected the purge creation time to be set")
+	}
+
+	// Deleting the organization replaces the purges of its buckets with a purge of the whole organization.
+	if err := c.DeleteOrganization(ctx, org.ID); err != nil {
+		t.Fatal(err)
+	}
+	ps, err = c.FindBucketPurges(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ps) != 1 || ps[0].OrganizationID != org.ID || ps[0].BucketID.Valid() {
+		t.Fatalf("expected a purge of organization %s, got %+v", org.ID, ps)
+	}
+
+	if err := c.DeleteBucketPurge(ctx, org.ID, platform.InvalidID()); err != nil {
+		t.Fatal(err)
+	}
+	ps, err = c.FindBucketPurges(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ps) != 0 {
+		t.Fatalf("expected no pending purges, got %+v", ps)
+	}
+}
@@ -0,0 +1,138 @@
+package bolt
+
+import (
+	"context"
+	"encoding/json"
+
+	bolt "github.com/coreos/bbolt"
+	"github.com/influxdata/platform"
+	kerrors "github.com/influxdata/platform/kit/errors"
+)
+
+var (
+	replicationBucket = []byte("replicationsv1")
+)
+
+var _ platform.ReplicationService = (*Client)(nil)
+
+func (c *Client) initializeReplications(ctx context.Context, tx *bolt.Tx) error {
+	if _, err := tx.CreateBucketIfNotExists(replicationBucket); err != nil {
+		return err
+	}
+	return nil
+}
+
+// FindReplications returns all replications.
+func (c *Client) FindReplications(ctx context.Context) ([]*platform.Replication, error) {
+	replications := []*platform.Replication{}
+	err := c.db.View(func(tx *bolt.Tx) error {
+		cur := tx.Bucket(replicationBucket).Cursor()
+		for k, v := cur.First(); k != nil; k, v = cur.Next() {
+			r := &platform.Replication{}
+			if err := json.Unmarshal(v, r); err != nil {
+				return err
+			}
+			replications = append(replications, r)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return replications, nil
+}
+
+// FindReplicationByID finds a single replication by its ID.
+func (c *Client) FindReplicationByID(ctx context.Context, id platform.ID) (*platform.Replication, error) {
+	var r *platform.Replication
+	err := c.db.View(func(tx *bolt.Tx) error {
+		var err error
+		r, err = c.findReplicationByID(ctx, tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (c *Client) findReplicationByID(ctx context.Context, tx *bolt.Tx, id platform.ID) (*platform.Replication, error) {
+	encID, err := id.Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	v := tx.Bucket(replicationBucket).Get(encID)
+	if v == nil {
+		return nil, kerrors.Errorf(kerrors.NotFound, "replication with ID %v not found", id)
+	}
+
+	r := &platform.Replication{}
+	if err := json.Unmarshal(v, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// CreateReplication creates a new replication and assigns it an ID.
+func (c *Client) CreateReplication(ctx context.Context, r *platform.Replication) error {
+	if err := r.Valid(); err != nil {
+		return kerrors.InvalidDataf("%v", err)
+	}
+	return c.db.Update(func(tx *bolt.Tx) error {
+		r.ID = c.IDGenerator.ID()
+		return c.putReplication(ctx, tx, r)
+	})
+}
+
+// PutReplication puts a replication without setting its ID.
+func (c *Client) PutReplication(ctx context.Context, r *platform.Replication) error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		return c.putReplication(ctx, tx, r)
+	})
+}
+
+func (c *Client) putReplication(ctx context.Context, tx *bolt.Tx, r *platform.Replication) error {
+	v, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	encID, err := r.ID.Encode()
+	if err != nil {
+		return err
+	}
+	return tx.Bucket(replicationBucket).Put(encID, v)
+}
+
+// UpdateReplication updates a single replication with a changeset.
+func (c *Client) UpdateReplication(ctx context.Context, id platform.ID, upd platform.ReplicationUpdate) (*platform.Replication, error) {
+	var r *platform.Replication
+	err := c.db.Update(func(tx *bolt.Tx) error {
+		var err error
+		if r, err = c.findReplicationByID(ctx, tx, id); err != nil {
+			return err
+		}
+		if err := upd.Apply(r); err != nil {
+			return kerrors.InvalidDataf("%v", err)
+		}
+		return c.putReplication(ctx, tx, r)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// DeleteReplication removes a replication by its ID.
+func (c *Client) DeleteReplication(ctx context.Context, id platform.ID) error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		if _, err := c.findReplicationByID(ctx, tx, id); err != nil {
+			return err
+		}
+		encID, err := id.Encode()
+		if err != nil {
+			return err
+		}
+		return tx.Bucket(replicationBucket).Delete(encID)
+	})
+}
@@ -0,0 +1,31 @@
+package bolt_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/platform"
+	platformtesting "github.com/influxdata/platform/testing"
+)
+
+func initReplicationService(f platformtesting.ReplicationFields, t *testing.T) (platform.ReplicationService, func()) {
+	c, closeFn, err := NewTestClient()
+	if err != nil {
+		t.Fatalf("failed to create new bolt test client: %v", err)
+	}
+
+	c.IDGenerator = f.IDGenerator
+	ctx := context.TODO()
+
+	for _, l := range f.Replications {
+		if err := c.PutReplication(ctx, l); err != nil {
+			t.Fatalf("failed to populate test replications: %v", err)
+		}
+	}
+
+	return c, closeFn
+}
+
+func TestReplicationService(t *testing.T) {
+	platformtesting.ReplicationService(initReplicationService, t)
+}
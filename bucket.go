@@ -25,8 +25,26 @@ type Bucket struct {
 	Name                string        `json:"name"`
 	RetentionPolicyName string        `json:"rp,omitempty"` // This to support v1 sources
 	RetentionPeriod     time.Duration `json:"retentionPeriod"`
+	// PartitionDuration is the duration of the time partitions of the data of the bucket,
+	// which retention removes whole once they expire. Zero means the data is not partitioned.
+	PartitionDuration time.Duration `json:"partitionDuration,omitempty"`
+	// SchemaType is SchemaTypeExplicit when the points written to the bucket must match Schema.
+	SchemaType SchemaType `json:"schemaType,omitempty"`
+	// Schema is the declared schema of a bucket with an explicit schema.
+	Schema []MeasurementSchema `json:"schema,omitempty"`
 }
 
+// SchemaType is whether the points written to a bucket must match a declared schema.
+type SchemaType string
+
+const (
+	// SchemaTypeImplicit buckets accept fields of any type, as long as the type of each field
+	// of a measurement does not change. Buckets with no schema type have an implicit schema.
+	SchemaTypeImplicit SchemaType = "implicit"
+	// SchemaTypeExplicit buckets only accept the fields of their declared schema.
+	SchemaTypeExplicit SchemaType = "explicit"
+)
+
 // BucketService represents a service for managing bucket data.
 type BucketService interface {
 	// FindBucketByID returns a single bucket by ID.
@@ -53,8 +71,12 @@ type BucketService interface {
 // BucketUpdate represents updates to a bucket.
 // Only fields which are set are updated.
 type BucketUpdate struct {
-	Name            *string        `json:"name,omitempty"`
-	RetentionPeriod *time.Duration `json:"retentionPeriod,omitempty"`
+	Name              *string        `json:"name,omitempty"`
+	RetentionPeriod   *time.Duration `json:"retentionPeriod,omitempty"`
+	PartitionDuration *time.Duration `json:"partitionDuration,omitempty"`
+	SchemaType        *SchemaType    `json:"schemaType,omitempty"`
+	// Schema replaces the declared schema when it is not nil.
+	Schema []MeasurementSchema `json:"schema,omitempty"`
 }
 
 // BucketFilter represents a set of filter that restrict the returned results.
@@ -77,3 +99,104 @@ type FindOptions struct {
 func InternalBucketID(t BucketType) (*ID, error) {
 	return IDFromString(fmt.Sprintf("%d", t))
 }
+
+// BucketStats summarizes the data stored in a bucket.
+type BucketStats struct {
+	BucketID ID `json:"bucketID"`
+	// Size is the size of the data of the bucket in TSM files, in bytes. Data yet to be
+	// written to TSM files is not included.
+	Size int64 `json:"size"`
+	// SeriesN is the estimated number of distinct series of the bucket, whatever their fields.
+	SeriesN int64 `json:"seriesCount"`
+	// FieldSeriesN is the number of series of the bucket, where each field of a series counts separately.
+	FieldSeriesN int64 `json:"fieldSeriesCount"`
+	// PointN is the number of points of the bucket.
+	PointN int64 `json:"pointCount"`
+	// OldestTime and NewestTime are the times of the oldest and newest points of the bucket,
+	// and are zero if it holds no points.
+	OldestTime time.Time `json:"oldestTime"`
+	NewestTime time.Time `json:"newestTime"`
+	// Measurements are the cardinalities of the measurements of the bucket, sorted by name.
+	Measurements []MeasurementCardinality `json:"measurements"`
+}
+
+// MeasurementCardinality is the number of series of a measurement.
+type MeasurementCardinality struct {
+	Name string `json:"name"`
+	// SeriesN is the estimated number of distinct series of the measurement, whatever their fields:
+	// the number of series of its most common field.
+	SeriesN int64 `json:"seriesCount"`
+	// FieldSeriesN is the number of series of the measurement, where each field of a series counts separately.
+	FieldSeriesN int64 `json:"fieldSeriesCount"`
+}
+
+// BucketStatsService represents a service for summarizing the data stored in buckets.
+type BucketStatsService interface {
+	// FindBucketStats returns statistics of the data of a bucket of an organization.
+	FindBucketStats(ctx context.Context, orgID, bucketID ID) (*BucketStats, error)
+}
+
+// Types of the fields of a bucket schema.
+const (
+	FieldTypeFloat    = "float"
+	FieldTypeInteger  = "integer"
+	FieldTypeUnsigned = "unsigned"
+	FieldTypeString   = "string"
+	FieldTypeBoolean  = "boolean"
+)
+
+// BucketSchema is the type of each field of each measurement of the data of a bucket.
+type BucketSchema struct {
+	BucketID   ID         `json:"bucketID"`
+	SchemaType SchemaType `json:"schemaType"`
+	// Measurements are sorted by name.
+	Measurements []MeasurementSchema `json:"measurements"`
+}
+
+// MeasurementSchema is the type of each field of a measurement.
+type MeasurementSchema struct {
+	Name string `json:"name"`
+	// Fields are sorted by name.
+	Fields []FieldSchema `json:"fields"`
+}
+
+// FieldSchema is the type of a field, one of the FieldType constants.
+type FieldSchema struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// ValidateSchema returns an error if a declared schema has an unknown field type, or
+// declares a measurement or a field twice.
+func ValidateSchema(measurements []MeasurementSchema) error {
+	names := make(map[string]bool, len(measurements))
+	for _, m := range measurements {
+		if m.Name == "" {
+			return fmt.Errorf("schema measurement has no name")
+		} else if names[m.Name] {
+			return fmt.Errorf("schema measurement %q is declared twice", m.Name)
+		}
+		names[m.Name] = true
+
+		fields := make(map[string]bool, len(m.Fields))
+		for _, f := range m.Fields {
+			switch f.Type {
+			case FieldTypeFloat, FieldTypeInteger, FieldTypeUnsigned, FieldTypeString, FieldTypeBoolean:
+			default:
+				return fmt.Errorf("schema field %q of measurement %q has unknown type %q", f.Name, m.Name, f.Type)
+			}
+			if fields[f.Name] {
+				return fmt.Errorf("schema field %q of measurement %q is declared twice", f.Name, m.Name)
+			}
+			fields[f.Name] = true
+		}
+	}
+	return nil
+}
+
+// BucketSchemaService represents a service for finding the schema of the data stored in buckets.
+type BucketSchemaService interface {
+	// FindBucketSchema returns the type of each field of each measurement of the data of a bucket
+	// of an organization.
+	FindBucketSchema(ctx context.Context, orgID, bucketID ID) (*BucketSchema, error)
+}
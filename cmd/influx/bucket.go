@@ -131,6 +131,7 @@ type BucketFindFlags struct {
 	id    string
 	org   string
 	orgID string
+	stats bool
 }
 
 var bucketFindFlags BucketFindFlags
@@ -146,6 +147,7 @@ func init() {
 	bucketFindCmd.Flags().StringVarP(&bucketFindFlags.id, "id", "i", "", "bucket ID")
 	bucketFindCmd.Flags().StringVarP(&bucketFindFlags.orgID, "org-id", "", "", "bucket organization ID")
 	bucketFindCmd.Flags().StringVarP(&bucketFindFlags.org, "org", "o", "", "bucket organization name")
+	bucketFindCmd.Flags().BoolVarP(&bucketFindFlags.stats, "stats", "", false, "show statistics of the data stored in each bucket")
 
 	bucketCmd.AddCommand(bucketFindCmd)
 }
@@ -196,6 +198,11 @@ func bucketFindF(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
+	if bucketFindFlags.stats {
+		writeBucketStats(buckets)
+		return
+	}
+
 	w := internal.NewTabWriter(os.Stdout)
 	w.WriteHeaders(
 		"ID",
@@ -216,6 +223,79 @@ func bucketFindF(cmd *cobra.Command, args []string) {
 	w.Flush()
 }
 
+// writeBucketStats writes the statistics of the data stored in each of buckets, followed by
+// the cardinality of each of their measurements.
+func writeBucketStats(buckets []*platform.Bucket) {
+	if flags.local {
+		fmt.Println("bucket statistics are only available from a server")
+		os.Exit(1)
+	}
+
+	s := &http.BucketService{
+		Addr:  flags.host,
+		Token: flags.token,
+	}
+
+	stats := make([]*platform.BucketStats, 0, len(buckets))
+	for _, b := range buckets {
+		st, err := s.FindBucketStats(context.Background(), b.OrganizationID, b.ID)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		stats = append(stats, st)
+	}
+
+	w := internal.NewTabWriter(os.Stdout)
+	w.WriteHeaders(
+		"ID",
+		"Name",
+		"Size",
+		"Series",
+		"FieldSeries",
+		"Points",
+		"Oldest",
+		"Newest",
+	)
+	for i, b := range buckets {
+		var oldest, newest string
+		if st := stats[i]; st.PointN > 0 {
+			oldest, newest = st.OldestTime.Format(time.RFC3339Nano), st.NewestTime.Format(time.RFC3339Nano)
+		}
+		w.Write(map[string]interface{}{
+			"ID":          b.ID.String(),
+			"Name":        b.Name,
+			"Size":        stats[i].Size,
+			"Series":      stats[i].SeriesN,
+			"FieldSeries": stats[i].FieldSeriesN,
+			"Points":      stats[i].PointN,
+			"Oldest":      oldest,
+			"Newest":      newest,
+		})
+	}
+	w.Flush()
+
+	fmt.Println()
+	w = internal.NewTabWriter(os.Stdout)
+	w.WriteHeaders(
+		"BucketID",
+		"Measurement",
+		"Series",
+		"FieldSeries",
+	)
+	for i, b := range buckets {
+		for _, m := range stats[i].Measurements {
+			w.Write(map[string]interface{}{
+				"BucketID":    b.ID.String(),
+				"Measurement": m.Name,
+				"Series":      m.SeriesN,
+				"FieldSeries": m.FieldSeriesN,
+			})
+		}
+	}
+	w.Flush()
+}
+
 // BucketUpdateFlags define the Update Command
 type BucketUpdateFlags struct {
 	id        string
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/influxdata/platform/http"
+	"github.com/influxdata/platform/kit/signals"
+	"github.com/spf13/cobra"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the data of a bucket",
+	Long: `Export the data of a bucket as line protocol, which can be imported into
+another bucket with "influx import", or as annotated CSV. The data is written as
+it is received, so any time range can be exported.`,
+	Args: cobra.NoArgs,
+	RunE: exportF,
+}
+
+var exportFlags struct {
+	OrgID     string
+	Org       string
+	BucketID  string
+	Bucket    string
+	Start     string
+	Stop      string
+	Predicate string
+	Format    string
+	Out       string
+	Compress  bool
+}
+
+func init() {
+	exportCmd.Flags().StringVar(&exportFlags.OrgID, "org-id", "", "id of the organization that owns the bucket")
+	exportCmd.Flags().StringVarP(&exportFlags.Org, "org", "o", "", "name of the organization that owns the bucket")
+	exportCmd.Flags().StringVar(&exportFlags.BucketID, "bucket-id", "", "ID of the bucket to export")
+	exportCmd.Flags().StringVarP(&exportFlags.Bucket, "bucket", "b", "", "name of the bucket to export")
+	exportCmd.Flags().StringVar(&exportFlags.Start, "start", "", "only export data at or after this time (RFC3339 format)")
+	exportCmd.Flags().StringVar(&exportFlags.Stop, "stop", "", "only export data at or before this time (RFC3339 format)")
+	exportCmd.Flags().StringVar(&exportFlags.Predicate, "predicate", "", `only export the series matching this Flux predicate, such as (r) => r._measurement == "cpu"`)
+	exportCmd.Flags().StringVar(&exportFlags.Format, "format", http.ExportFormatLineProtocol, "format of the exported data: lp or csv")
+	exportCmd.Flags().StringVar(&exportFlags.Out, "out", "", "file to export to; defaults to stdout")
+	exportCmd.Flags().BoolVar(&exportFlags.Compress, "compress", false, "compress the exported data with gzip")
+}
+
+func exportF(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	if exportFlags.Org != "" && exportFlags.OrgID != "" {
+		cmd.Usage()
+		return fmt.Errorf("Please specify one of org or org-id")
+	}
+
+	if exportFlags.Bucket != "" && exportFlags.BucketID != "" {
+		cmd.Usage()
+		return fmt.Errorf("Please specify one of bucket or bucket-id")
+	}
+
+	if exportFlags.Format != http.ExportFormatLineProtocol && exportFlags.Format != http.ExportFormatCSV {
+		cmd.Usage()
+		return fmt.Errorf("invalid format %q", exportFlags.Format)
+	}
+
+	filter := http.ExportFilter{
+		Predicate: exportFlags.Predicate,
+		Format:    exportFlags.Format,
+	}
+	var err error
+	if exportFlags.Start != "" {
+		if filter.Start, err = time.Parse(time.RFC3339Nano, exportFlags.Start); err != nil {
+			return fmt.Errorf("invalid start: %v", err)
+		}
+	}
+	if exportFlags.Stop != "" {
+		if filter.Stop, err = time.Parse(time.RFC3339Nano, exportFlags.Stop); err != nil {
+			return fmt.Errorf("invalid stop: %v", err)
+		}
+	}
+
+	bucket, err := findBucket(ctx, exportFlags.Org, exportFlags.OrgID, exportFlags.Bucket, exportFlags.BucketID)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if exportFlags.Out != "" {
+		f, err := os.Create(exportFlags.Out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	bw := bufio.NewWriter(w)
+	w = bw
+	var gw *gzip.Writer
+	if exportFlags.Compress {
+		gw = gzip.NewWriter(bw)
+		w = gw
+	}
+
+	s := &http.ExportService{
+		Addr:  flags.host,
+		Token: flags.token,
+	}
+
+	ctx = signals.WithStandardSignals(ctx)
+	if err := s.Export(ctx, bucket.OrganizationID, bucket.ID, filter, w); err != nil {
+		return err
+	}
+
+	if gw != nil {
+		if err := gw.Close(); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
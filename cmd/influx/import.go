@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/platform/http"
+	"github.com/influxdata/platform/kit/signals"
+	"github.com/influxdata/platform/models"
+	"github.com/influxdata/platform/write"
+	"github.com/spf13/cobra"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import path/to/points.txt",
+	Short: "Import line protocol into a bucket",
+	Long: `Import a file of line protocol, such as one made with "influx export", into a
+bucket. The file may be compressed with gzip. The offset of the data written so
+far is recorded in a checkpoint file, so that an interrupted import resumes where
+it stopped when run again; the checkpoint file is removed once the import completes.
+Use - to import from stdin, which cannot be resumed.`,
+	Args: cobra.ExactArgs(1),
+	RunE: importF,
+}
+
+var importFlags struct {
+	OrgID      string
+	Org        string
+	BucketID   string
+	Bucket     string
+	Precision  string
+	Checkpoint string
+}
+
+func init() {
+	importCmd.Flags().StringVar(&importFlags.OrgID, "org-id", "", "id of the organization that owns the bucket")
+	importCmd.Flags().StringVarP(&importFlags.Org, "org", "o", "", "name of the organization that owns the bucket")
+	importCmd.Flags().StringVar(&importFlags.BucketID, "bucket-id", "", "ID of destination bucket")
+	importCmd.Flags().StringVarP(&importFlags.Bucket, "bucket", "b", "", "name of destination bucket")
+	importCmd.Flags().StringVarP(&importFlags.Precision, "precision", "p", "ns", "precision of the timestamps of the lines")
+	importCmd.Flags().StringVar(&importFlags.Checkpoint, "checkpoint", "", "checkpoint file of the import; defaults to the path of the file with a .checkpoint extension")
+}
+
+func importF(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	if importFlags.Org != "" && importFlags.OrgID != "" {
+		cmd.Usage()
+		return fmt.Errorf("Please specify one of org or org-id")
+	}
+
+	if importFlags.Bucket != "" && importFlags.BucketID != "" {
+		cmd.Usage()
+		return fmt.Errorf("Please specify one of bucket or bucket-id")
+	}
+
+	if !models.ValidPrecision(importFlags.Precision) {
+		cmd.Usage()
+		return fmt.Errorf("invalid precision")
+	}
+
+	bucket, err := findBucket(ctx, importFlags.Org, importFlags.OrgID, importFlags.Bucket, importFlags.BucketID)
+	if err != nil {
+		return err
+	}
+
+	var in io.Reader = os.Stdin
+	checkpoint := importFlags.Checkpoint
+	if args[0] != "-" {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+
+		if checkpoint == "" {
+			checkpoint = args[0] + ".checkpoint"
+		}
+	}
+
+	r, err := decompress(in)
+	if err != nil {
+		return err
+	}
+
+	// Skip the data written by a previous import.
+	var offset int64
+	if checkpoint != "" {
+		if offset, err = readCheckpoint(checkpoint); err != nil {
+			return err
+		}
+		if _, err := io.CopyN(ioutil.Discard, r, offset); err != nil {
+			return fmt.Errorf("unable to resume import at offset %d: %v", offset, err)
+		}
+		if offset > 0 {
+			fmt.Fprintf(os.Stderr, "Resuming import at offset %d\n", offset)
+		}
+	}
+
+	var checkpointErr error
+	s := write.Batcher{
+		Service: &http.WriteService{
+			Addr:      flags.host,
+			Token:     flags.token,
+			Precision: importFlags.Precision,
+			// The batcher retries its batches.
+			MaxRetries: -1,
+		},
+	}
+	if checkpoint != "" {
+		s.Checkpoint = func(n int64) {
+			if err := ioutil.WriteFile(checkpoint, []byte(strconv.FormatInt(offset+n, 10)), 0666); err != nil && checkpointErr == nil {
+				checkpointErr = err
+			}
+		}
+	}
+
+	ctx = signals.WithStandardSignals(ctx)
+	if err := s.Write(ctx, bucket.OrganizationID, bucket.ID, r); err != nil {
+		if err == context.Canceled {
+			return nil
+		}
+		return err
+	}
+	if checkpointErr != nil {
+		return fmt.Errorf("unable to write checkpoint: %v", checkpointErr)
+	}
+
+	if checkpoint != "" {
+		if err := os.Remove(checkpoint); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// decompress returns a reader of the data of r, decompressing it if it was compressed with gzip.
+func decompress(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(2)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		return gzip.NewReader(br)
+	}
+	return br, nil
+}
+
+// readCheckpoint returns the offset recorded in the checkpoint file at path, or zero if it does not exist.
+func readCheckpoint(path string) (int64, error) {
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+
+	offset, err := strconv.ParseInt(strings.TrimSpace(string(b)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid checkpoint file %s: %v", path, err)
+	}
+	return offset, nil
+}
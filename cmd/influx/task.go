@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/influxdata/flux/repl"
@@ -49,15 +51,27 @@ func runF(cmd *cobra.Command, args []string) {
 	cmd.Usage()
 }
 
+var revisionCmd = &cobra.Command{
+	Use:   "revision",
+	Short: "revision related commands",
+	Run:   revisionF,
+}
+
+func revisionF(cmd *cobra.Command, args []string) {
+	cmd.Usage()
+}
+
 func init() {
 	taskCmd.AddCommand(runCmd)
 	taskCmd.AddCommand(logCmd)
+	taskCmd.AddCommand(revisionCmd)
 }
 
 // TaskCreateFlags define the Create Command
 type TaskCreateFlags struct {
-	org   string
-	orgID string
+	org    string
+	orgID  string
+	labels []string
 }
 
 var taskCreateFlags TaskCreateFlags
@@ -72,6 +86,7 @@ func init() {
 
 	taskCreateCmd.Flags().StringVarP(&taskCreateFlags.org, "org", "", "", "organization name")
 	taskCreateCmd.Flags().StringVarP(&taskCreateFlags.orgID, "org-id", "", "", "id of the organization that owns the task")
+	taskCreateCmd.Flags().StringSliceVarP(&taskCreateFlags.labels, "label", "", nil, "task label formatted as key=value; may be repeated")
 	taskCreateCmd.MarkFlagRequired("flux")
 
 	taskCmd.AddCommand(taskCreateCmd)
@@ -95,8 +110,15 @@ func taskCreateF(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
+	labels, err := parseTaskLabels(taskCreateFlags.labels)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
 	t := &platform.Task{
-		Flux: flux,
+		Flux:   flux,
+		Labels: labels,
 	}
 
 	if taskCreateFlags.org != "" && taskCreateFlags.orgID == "" {
@@ -170,9 +192,12 @@ func taskCreateF(cmd *cobra.Command, args []string) {
 
 // taskFindFlags define the Find Command
 type TaskFindFlags struct {
-	user  string
-	id    string
-	orgID string
+	user   string
+	id     string
+	orgID  string
+	name   string
+	status string
+	labels []string
 }
 
 var taskFindFlags TaskFindFlags
@@ -187,6 +212,9 @@ func init() {
 	taskFindCmd.Flags().StringVarP(&taskFindFlags.id, "id", "i", "", "task ID")
 	taskFindCmd.Flags().StringVarP(&taskFindFlags.user, "user-id", "n", "", "task owner ID")
 	taskFindCmd.Flags().StringVarP(&taskFindFlags.orgID, "org-id", "", "", "task organization ID")
+	taskFindCmd.Flags().StringVarP(&taskFindFlags.name, "name", "", "", "find tasks whose name contains this string")
+	taskFindCmd.Flags().StringVarP(&taskFindFlags.status, "status", "", "", "task status")
+	taskFindCmd.Flags().StringSliceVarP(&taskFindFlags.labels, "label", "", nil, "task label formatted as key=value; may be repeated")
 
 	taskCmd.AddCommand(taskFindCmd)
 }
@@ -216,8 +244,22 @@ func taskFindF(cmd *cobra.Command, args []string) {
 		filter.Organization = id
 	}
 
+	if taskFindFlags.name != "" {
+		filter.Name = &taskFindFlags.name
+	}
+
+	if taskFindFlags.status != "" {
+		filter.Status = &taskFindFlags.status
+	}
+
+	labels, err := parseTaskLabels(taskFindFlags.labels)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	filter.Labels = labels
+
 	var tasks []*platform.Task
-	var err error
 
 	if taskFindFlags.id != "" {
 		id, err := platform.IDFromString(taskFindFlags.id)
@@ -241,32 +283,14 @@ func taskFindF(cmd *cobra.Command, args []string) {
 		}
 	}
 
-	w := internal.NewTabWriter(os.Stdout)
-	w.WriteHeaders(
-		"ID",
-		"Name",
-		"Organization",
-		"Status",
-		"Every",
-		"Cron",
-	)
-	for _, t := range tasks {
-		w.Write(map[string]interface{}{
-			"ID":           t.ID.String(),
-			"Name":         t.Name,
-			"Organization": t.Organization.String(),
-			"Status":       t.Status,
-			"Every":        t.Every,
-			"Cron":         t.Cron,
-		})
-	}
-	w.Flush()
+	writeTasks(tasks)
 }
 
 // taskUpdateFlags define the Update Command
 type TaskUpdateFlags struct {
 	id     string
 	status string
+	labels []string
 }
 
 var taskUpdateFlags TaskUpdateFlags
@@ -280,6 +304,7 @@ func init() {
 
 	taskUpdateCmd.Flags().StringVarP(&taskUpdateFlags.id, "id", "i", "", "task ID (required)")
 	taskUpdateCmd.Flags().StringVarP(&taskUpdateFlags.status, "status", "", "", "update task status")
+	taskUpdateCmd.Flags().StringSliceVarP(&taskUpdateFlags.labels, "label", "", nil, "replace task labels with this key=value label; may be repeated")
 	taskUpdateCmd.MarkFlagRequired("id")
 
 	taskCmd.AddCommand(taskUpdateCmd)
@@ -302,6 +327,18 @@ func taskUpdateF(cmd *cobra.Command, args []string) {
 		update.Status = &taskUpdateFlags.status
 	}
 
+	if cmd.Flags().Changed("label") {
+		labels, err := parseTaskLabels(taskUpdateFlags.labels)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		update.Labels = labels
+		if update.Labels == nil {
+			update.Labels = map[string]string{}
+		}
+	}
+
 	if len(args) > 0 {
 		flux, err := repl.LoadQuery(args[0])
 		if err != nil {
@@ -402,6 +439,208 @@ func taskDeleteF(cmd *cobra.Command, args []string) {
 	w.Flush()
 }
 
+// writeTasks prints tasks as a table, including their labels.
+func writeTasks(tasks []*platform.Task) {
+	w := internal.NewTabWriter(os.Stdout)
+	w.WriteHeaders(
+		"ID",
+		"Name",
+		"Organization",
+		"Status",
+		"Every",
+		"Cron",
+		"Labels",
+	)
+	for _, t := range tasks {
+		w.Write(map[string]interface{}{
+			"ID":           t.ID.String(),
+			"Name":         t.Name,
+			"Organization": t.Organization.String(),
+			"Status":       t.Status,
+			"Every":        t.Every,
+			"Cron":         t.Cron,
+			"Labels":       formatTaskLabels(t.Labels),
+		})
+	}
+	w.Flush()
+}
+
+// parseTaskLabels parses labels formatted as key=value.
+// It returns nil if no labels are given.
+func parseTaskLabels(ls []string) (map[string]string, error) {
+	if len(ls) == 0 {
+		return nil, nil
+	}
+
+	labels := make(map[string]string, len(ls))
+	for _, l := range ls {
+		kv := strings.SplitN(l, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("label %q must be formatted as key=value", l)
+		}
+		labels[kv[0]] = kv[1]
+	}
+	return labels, nil
+}
+
+func formatTaskLabels(labels map[string]string) string {
+	ls := make([]string, 0, len(labels))
+	for k, v := range labels {
+		ls = append(ls, k+"="+v)
+	}
+	sort.Strings(ls)
+	return strings.Join(ls, ",")
+}
+
+var taskBulkCmd = &cobra.Command{
+	Use:   "bulk",
+	Short: "Update or delete all tasks matching a filter",
+	Run:   taskBulkF,
+}
+
+func taskBulkF(cmd *cobra.Command, args []string) {
+	cmd.Usage()
+}
+
+// TaskBulkFlags define the filter of the bulk commands
+type TaskBulkFlags struct {
+	user   string
+	orgID  string
+	name   string
+	status string
+	labels []string
+}
+
+var taskBulkFlags TaskBulkFlags
+
+// TaskBulkUpdateFlags define the bulk Update command
+type TaskBulkUpdateFlags struct {
+	status string
+	labels []string
+}
+
+var taskBulkUpdateFlags TaskBulkUpdateFlags
+
+func init() {
+	taskBulkCmd.PersistentFlags().StringVarP(&taskBulkFlags.user, "user-id", "n", "", "task owner ID")
+	taskBulkCmd.PersistentFlags().StringVarP(&taskBulkFlags.orgID, "org-id", "", "", "task organization ID")
+	taskBulkCmd.PersistentFlags().StringVarP(&taskBulkFlags.name, "name", "", "", "match tasks whose name contains this string")
+	taskBulkCmd.PersistentFlags().StringVarP(&taskBulkFlags.status, "status", "", "", "match tasks with this status")
+	taskBulkCmd.PersistentFlags().StringSliceVarP(&taskBulkFlags.labels, "label", "", nil, "match tasks with this key=value label; may be repeated")
+	taskBulkCmd.MarkPersistentFlagRequired("org-id")
+
+	taskBulkUpdateCmd := &cobra.Command{
+		Use:   "update",
+		Short: "Update the status or labels of all matching tasks",
+		Run:   taskBulkUpdateF,
+	}
+	taskBulkUpdateCmd.Flags().StringVarP(&taskBulkUpdateFlags.status, "set-status", "", "", "new task status")
+	taskBulkUpdateCmd.Flags().StringSliceVarP(&taskBulkUpdateFlags.labels, "set-label", "", nil, "replace task labels with this key=value label; may be repeated")
+
+	taskBulkDeleteCmd := &cobra.Command{
+		Use:   "delete",
+		Short: "Delete all matching tasks",
+		Run:   taskBulkDeleteF,
+	}
+
+	taskBulkCmd.AddCommand(taskBulkUpdateCmd)
+	taskBulkCmd.AddCommand(taskBulkDeleteCmd)
+	taskCmd.AddCommand(taskBulkCmd)
+}
+
+func taskBulkFilter() (platform.TaskFilter, error) {
+	filter := platform.TaskFilter{}
+	if taskBulkFlags.user != "" {
+		id, err := platform.IDFromString(taskBulkFlags.user)
+		if err != nil {
+			return filter, err
+		}
+		filter.User = id
+	}
+
+	if taskBulkFlags.orgID != "" {
+		id, err := platform.IDFromString(taskBulkFlags.orgID)
+		if err != nil {
+			return filter, err
+		}
+		filter.Organization = id
+	}
+
+	if taskBulkFlags.name != "" {
+		filter.Name = &taskBulkFlags.name
+	}
+
+	if taskBulkFlags.status != "" {
+		filter.Status = &taskBulkFlags.status
+	}
+
+	labels, err := parseTaskLabels(taskBulkFlags.labels)
+	if err != nil {
+		return filter, err
+	}
+	filter.Labels = labels
+
+	return filter, nil
+}
+
+func taskBulkUpdateF(cmd *cobra.Command, args []string) {
+	s := &http.TaskService{
+		Addr:  flags.host,
+		Token: flags.token,
+	}
+
+	filter, err := taskBulkFilter()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	update := platform.TaskUpdate{}
+	if taskBulkUpdateFlags.status != "" {
+		update.Status = &taskBulkUpdateFlags.status
+	}
+
+	if cmd.Flags().Changed("set-label") {
+		update.Labels, err = parseTaskLabels(taskBulkUpdateFlags.labels)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if update.Labels == nil {
+			update.Labels = map[string]string{}
+		}
+	}
+
+	tasks, err := s.UpdateTasks(context.Background(), filter, update)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	writeTasks(tasks)
+}
+
+func taskBulkDeleteF(cmd *cobra.Command, args []string) {
+	s := &http.TaskService{
+		Addr:  flags.host,
+		Token: flags.token,
+	}
+
+	filter, err := taskBulkFilter()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	tasks, err := s.DeleteTasks(context.Background(), filter)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	writeTasks(tasks)
+}
+
 // taskLogFindFlags define the Delete command
 type TaskLogFindFlags struct {
 	taskID string
@@ -564,6 +803,7 @@ func taskRunFindF(cmd *cobra.Command, args []string) {
 		"StartedAt",
 		"FinishedAt",
 		"RequestedAt",
+		"Revision",
 	)
 	for _, r := range runs {
 		w.Write(map[string]interface{}{
@@ -574,6 +814,7 @@ func taskRunFindF(cmd *cobra.Command, args []string) {
 			"StartedAt":    r.StartedAt,
 			"FinishedAt":   r.FinishedAt,
 			"RequestedAt":  r.RequestedAt,
+			"Revision":     r.Revision,
 		})
 	}
 	w.Flush()
@@ -624,3 +865,255 @@ func runRetryF(cmd *cobra.Command, args []string) {
 
 	fmt.Printf("Retry for task %s's run %s queued.\n", taskID, runID)
 }
+
+// TaskRevisionFindFlags define the revision find command
+type TaskRevisionFindFlags struct {
+	taskID   string
+	revision int
+}
+
+var taskRevisionFindFlags TaskRevisionFindFlags
+
+func init() {
+	taskRevisionFindCmd := &cobra.Command{
+		Use:   "find",
+		Short: "find revisions of a task's script",
+		Run:   taskRevisionFindF,
+	}
+
+	taskRevisionFindCmd.Flags().StringVarP(&taskRevisionFindFlags.taskID, "task-id", "", "", "task id (required)")
+	taskRevisionFindCmd.Flags().IntVarP(&taskRevisionFindFlags.revision, "revision", "r", 0, "revision number")
+	taskRevisionFindCmd.MarkFlagRequired("task-id")
+
+	revisionCmd.AddCommand(taskRevisionFindCmd)
+}
+
+func taskRevisionFindF(cmd *cobra.Command, args []string) {
+	s := &http.TaskService{
+		Addr:  flags.host,
+		Token: flags.token,
+	}
+
+	var taskID platform.ID
+	if err := taskID.DecodeFromString(taskRevisionFindFlags.taskID); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	var revs []*platform.TaskRevision
+	if taskRevisionFindFlags.revision != 0 {
+		rev, err := s.FindTaskRevision(context.Background(), taskID, taskRevisionFindFlags.revision)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		revs = append(revs, rev)
+	} else {
+		var err error
+		revs, _, err = s.FindTaskRevisions(context.Background(), taskID)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+
+	w := internal.NewTabWriter(os.Stdout)
+	w.WriteHeaders(
+		"Revision",
+		"TaskID",
+		"Name",
+		"Author",
+		"CreatedAt",
+		"Every",
+		"Cron",
+	)
+	for _, r := range revs {
+		w.Write(map[string]interface{}{
+			"Revision":  r.Revision,
+			"TaskID":    r.TaskID.String(),
+			"Name":      r.Name,
+			"Author":    r.Author.String(),
+			"CreatedAt": r.CreatedAt,
+			"Every":     r.Every,
+			"Cron":      r.Cron,
+		})
+	}
+	w.Flush()
+}
+
+type TaskRollbackFlags struct {
+	id       string
+	revision int
+}
+
+var taskRollbackFlags TaskRollbackFlags
+
+func init() {
+	cmd := &cobra.Command{
+		Use:   "rollback",
+		Short: "roll a task back to an earlier revision of its script",
+		Run:   taskRollbackF,
+	}
+
+	cmd.Flags().StringVarP(&taskRollbackFlags.id, "id", "i", "", "task id (required)")
+	cmd.Flags().IntVarP(&taskRollbackFlags.revision, "revision", "r", 0, "revision to roll back to (required)")
+	cmd.MarkFlagRequired("id")
+	cmd.MarkFlagRequired("revision")
+
+	taskCmd.AddCommand(cmd)
+}
+
+func taskRollbackF(cmd *cobra.Command, args []string) {
+	s := &http.TaskService{
+		Addr:  flags.host,
+		Token: flags.token,
+	}
+
+	var id platform.ID
+	if err := id.DecodeFromString(taskRollbackFlags.id); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	t, err := s.RollbackTask(context.Background(), id, taskRollbackFlags.revision)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	w := internal.NewTabWriter(os.Stdout)
+	w.WriteHeaders(
+		"ID",
+		"Name",
+		"Organization",
+		"Status",
+		"Revision",
+		"Every",
+		"Cron",
+	)
+	w.Write(map[string]interface{}{
+		"ID":           t.ID.String(),
+		"Name":         t.Name,
+		"Organization": t.Organization.String(),
+		"Status":       t.Status,
+		"Revision":     t.Revision,
+		"Every":        t.Every,
+		"Cron":         t.Cron,
+	})
+	w.Flush()
+}
+
+type TaskPreviewFlags struct {
+	id  string
+	now string
+}
+
+var taskPreviewFlags TaskPreviewFlags
+
+func init() {
+	cmd := &cobra.Command{
+		Use:   "preview",
+		Short: "print the tables a task would write, without writing them",
+		Run:   taskPreviewF,
+	}
+
+	cmd.Flags().StringVarP(&taskPreviewFlags.id, "id", "i", "", "task id (required)")
+	cmd.Flags().StringVarP(&taskPreviewFlags.now, "now", "", "", "time to execute the task at, in RFC3339 format (defaults to the current time)")
+	cmd.MarkFlagRequired("id")
+
+	taskCmd.AddCommand(cmd)
+}
+
+func taskPreviewF(cmd *cobra.Command, args []string) {
+	s := &http.TaskService{
+		Addr:  flags.host,
+		Token: flags.token,
+	}
+
+	var id platform.ID
+	if err := id.DecodeFromString(taskPreviewFlags.id); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	now := time.Now()
+	if taskPreviewFlags.now != "" {
+		t, err := time.Parse(time.RFC3339, taskPreviewFlags.now)
+		if err != nil {
+			fmt.Printf("error parsing now: %v\n", err)
+			os.Exit(1)
+		}
+		now = t
+	}
+
+	if err := s.PreviewTask(context.Background(), id, now, os.Stdout); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+// TaskStatsFlags define the Stats command
+type TaskStatsFlags struct {
+	id string
+}
+
+var taskStatsFlags TaskStatsFlags
+
+func init() {
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Show run statistics of a task",
+		Run:   taskStatsF,
+	}
+
+	cmd.Flags().StringVarP(&taskStatsFlags.id, "id", "i", "", "task id (required)")
+	cmd.MarkFlagRequired("id")
+
+	taskCmd.AddCommand(cmd)
+}
+
+func taskStatsF(cmd *cobra.Command, args []string) {
+	s := &http.TaskService{
+		Addr:  flags.host,
+		Token: flags.token,
+	}
+
+	var id platform.ID
+	if err := id.DecodeFromString(taskStatsFlags.id); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	stats, err := s.FindTaskStats(context.Background(), id)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	w := internal.NewTabWriter(os.Stdout)
+	w.WriteHeaders(
+		"ID",
+		"Succeeded",
+		"Failed",
+		"Canceled",
+		"RowsWritten",
+		"LastDuration",
+		"MeanDuration",
+		"MaxDuration",
+		"LastLag",
+		"MaxLag",
+	)
+	w.Write(map[string]interface{}{
+		"ID":           stats.TaskID.String(),
+		"Succeeded":    stats.RunsSucceeded,
+		"Failed":       stats.RunsFailed,
+		"Canceled":     stats.RunsCanceled,
+		"RowsWritten":  stats.RowsWritten,
+		"LastDuration": stats.LastRunDuration,
+		"MeanDuration": stats.MeanRunDuration,
+		"MaxDuration":  stats.MaxRunDuration,
+		"LastLag":      stats.LastLag,
+		"MaxLag":       stats.MaxLag,
+	})
+	w.Flush()
+}
@@ -20,7 +20,9 @@ var writeCmd = &cobra.Command{
 	Use:   "write line protocol or @/path/to/points.txt",
 	Short: "Write points to influxdb",
 	Long: `Write a single line of line protocol to influx db,
-		or add an entire file specified with an @ prefix`,
+		or add an entire file specified with an @ prefix.
+		Annotated CSV, as output by queries, and JSON rows with
+		a mapping of their columns are written with --format`,
 	Args: cobra.ExactArgs(1),
 	RunE: fluxWriteF,
 }
@@ -31,6 +33,7 @@ var writeFlags struct {
 	BucketID  string
 	Bucket    string
 	Precision string
+	Format    string
 }
 
 func init() {
@@ -63,6 +66,12 @@ func init() {
 	if p := viper.GetString("PRECISION"); p != "" {
 		writeFlags.Precision = p
 	}
+
+	writeCmd.PersistentFlags().StringVar(&writeFlags.Format, "format", http.WriteFormatLineProtocol, "format of the points: lp, csv or json")
+	viper.BindEnv("FORMAT")
+	if f := viper.GetString("FORMAT"); f != "" {
+		writeFlags.Format = f
+	}
 }
 
 func fluxWriteF(cmd *cobra.Command, args []string) error {
@@ -83,44 +92,16 @@ func fluxWriteF(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("invalid precision")
 	}
 
-	bs := &http.BucketService{
-		Addr:  flags.host,
-		Token: flags.token,
-	}
-
-	var err error
-	filter := platform.BucketFilter{}
-
-	if writeFlags.BucketID != "" {
-		filter.ID, err = platform.IDFromString(writeFlags.BucketID)
-		if err != nil {
-			return err
-		}
-	}
-	if writeFlags.Bucket != "" {
-		filter.Name = &writeFlags.Bucket
-	}
-
-	if writeFlags.OrgID != "" {
-		filter.OrganizationID, err = platform.IDFromString(writeFlags.OrgID)
-		if err != nil {
-			return err
-		}
-	}
-	if writeFlags.Org != "" {
-		filter.Organization = &writeFlags.Org
+	if !http.ValidWriteFormat(writeFlags.Format) {
+		cmd.Usage()
+		return fmt.Errorf("invalid format")
 	}
 
-	buckets, n, err := bs.FindBuckets(ctx, filter)
+	bucket, err := findBucket(ctx, writeFlags.Org, writeFlags.OrgID, writeFlags.Bucket, writeFlags.BucketID)
 	if err != nil {
 		return err
 	}
-
-	if n == 0 {
-		return fmt.Errorf("bucket does not exist")
-	}
-
-	bucketID, orgID := buckets[0].ID, buckets[0].OrganizationID
+	bucketID, orgID := bucket.ID, bucket.OrganizationID
 
 	var r io.Reader
 	if args[0] == "-" {
@@ -136,12 +117,18 @@ func fluxWriteF(cmd *cobra.Command, args []string) error {
 		r = strings.NewReader(args[0])
 	}
 
-	s := write.Batcher{
-		Service: &http.WriteService{
-			Addr:      flags.host,
-			Token:     flags.token,
-			Precision: writeFlags.Precision,
-		},
+	ws := &http.WriteService{
+		Addr:      flags.host,
+		Token:     flags.token,
+		Precision: writeFlags.Precision,
+		Format:    writeFlags.Format,
+	}
+	var s platform.WriteService = ws
+	// CSV and JSON cannot be split into batches of lines, and are written at once.
+	if writeFlags.Format == http.WriteFormatLineProtocol {
+		// The batcher retries its batches, rather than the client buffering them.
+		ws.MaxRetries = -1
+		s = &write.Batcher{Service: ws}
 	}
 
 	ctx = signals.WithStandardSignals(ctx)
@@ -150,3 +137,46 @@ func fluxWriteF(cmd *cobra.Command, args []string) error {
 	}
 	return nil
 }
+
+// findBucket returns the bucket with the name bucket or the ID bucketID, of the organization
+// with the name org or the ID orgID.
+func findBucket(ctx context.Context, org, orgID, bucket, bucketID string) (*platform.Bucket, error) {
+	bs := &http.BucketService{
+		Addr:  flags.host,
+		Token: flags.token,
+	}
+
+	var err error
+	filter := platform.BucketFilter{}
+
+	if bucketID != "" {
+		filter.ID, err = platform.IDFromString(bucketID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if bucket != "" {
+		filter.Name = &bucket
+	}
+
+	if orgID != "" {
+		filter.OrganizationID, err = platform.IDFromString(orgID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if org != "" {
+		filter.Organization = &org
+	}
+
+	buckets, n, err := bs.FindBuckets(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if n == 0 {
+		return nil, fmt.Errorf("bucket does not exist")
+	}
+
+	return buckets[0], nil
+}
@@ -0,0 +1,340 @@
+// Package dumptsm dumps the index and blocks of a TSM file.
+package dumptsm
+
+import (
+	"encoding/binary"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/influxdata/platform/models"
+	"github.com/influxdata/platform/tsdb"
+	"github.com/influxdata/platform/tsdb/tsm1"
+)
+
+// Command represents the program execution for "influx_inspect dumptsm".
+type Command struct {
+	Stderr io.Writer
+	Stdout io.Writer
+
+	dumpIndex  bool
+	dumpBlocks bool
+	dumpAll    bool
+	filterKey  string
+	path       string
+}
+
+// NewCommand returns a new instance of Command.
+func NewCommand() *Command {
+	return &Command{
+		Stderr: os.Stderr,
+		Stdout: os.Stdout,
+	}
+}
+
+// Run executes the command.
+func (cmd *Command) Run(args ...string) error {
+	fs := flag.NewFlagSet("dumptsm", flag.ExitOnError)
+	fs.BoolVar(&cmd.dumpIndex, "index", false, "Dump raw index data")
+	fs.BoolVar(&cmd.dumpBlocks, "blocks", false, "Dump raw block data")
+	fs.BoolVar(&cmd.dumpAll, "all", false, "Dump all data. Caution: This may print a lot of information")
+	fs.StringVar(&cmd.filterKey, "filter-key", "", "Only display index and block data that match this key substring")
+	fs.SetOutput(cmd.Stdout)
+	fs.Usage = cmd.printUsage
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.Arg(0) == "" {
+		fmt.Fprintf(cmd.Stdout, "TSM file not specified\n\n")
+		fs.Usage()
+		return nil
+	}
+	cmd.path = fs.Arg(0)
+	cmd.dumpBlocks = cmd.dumpBlocks || cmd.dumpAll || cmd.filterKey != ""
+	cmd.dumpIndex = cmd.dumpIndex || cmd.dumpAll || cmd.filterKey != ""
+	return cmd.dump()
+}
+
+func (cmd *Command) dump() error {
+	f, err := os.Open(cmd.path)
+	if err != nil {
+		return err
+	}
+
+	// Get the file size
+	stat, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	r, err := tsm1.NewTSMReader(f)
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("error opening TSM file: %s", err.Error())
+	}
+	defer r.Close()
+
+	minTime, maxTime := r.TimeRange()
+	keyCount := r.KeyCount()
+
+	blockStats := &blockStats{}
+
+	println := func(a ...interface{}) { fmt.Fprintln(cmd.Stdout, a...) }
+	println("Summary:")
+	fmt.Fprintf(cmd.Stdout, "  File: %s\n", cmd.path)
+	fmt.Fprintf(cmd.Stdout, "  Time Range: %s - %s\n",
+		time.Unix(0, minTime).UTC().Format(time.RFC3339Nano),
+		time.Unix(0, maxTime).UTC().Format(time.RFC3339Nano),
+	)
+	fmt.Fprintf(cmd.Stdout, "  Duration: %s ", time.Unix(0, maxTime).Sub(time.Unix(0, minTime)))
+	fmt.Fprintf(cmd.Stdout, "  Series: %d ", keyCount)
+	fmt.Fprintf(cmd.Stdout, "  File Size: %d\n", stat.Size())
+	println()
+
+	tw := tabwriter.NewWriter(cmd.Stdout, 8, 8, 1, '\t', 0)
+
+	if cmd.dumpIndex {
+		println("Index:")
+		println()
+
+		fmt.Fprintln(tw, "  "+strings.Join([]string{"Pos", "Min Time", "Max Time", "Ofs", "Size", "Org", "Bucket", "Key", "Field"}, "\t"))
+		var pos int
+		for i := 0; i < keyCount; i++ {
+			key, _ := r.KeyAt(i)
+			k := decodeKey(key)
+			for _, e := range r.Entries(key) {
+				pos++
+				if cmd.filterKey != "" && !strings.Contains(k.String(), cmd.filterKey) {
+					continue
+				}
+
+				fmt.Fprintln(tw, "  "+strings.Join([]string{
+					strconv.FormatInt(int64(pos), 10),
+					time.Unix(0, e.MinTime).UTC().Format(time.RFC3339Nano),
+					time.Unix(0, e.MaxTime).UTC().Format(time.RFC3339Nano),
+					strconv.FormatInt(int64(e.Offset), 10),
+					strconv.FormatInt(int64(e.Size), 10),
+					k.org,
+					k.bucket,
+					k.series,
+					k.field,
+				}, "\t"))
+			}
+		}
+		tw.Flush()
+		println()
+	}
+
+	tw = tabwriter.NewWriter(cmd.Stdout, 8, 8, 1, '\t', 0)
+	fmt.Fprintln(tw, "  "+strings.Join([]string{"Blk", "Chk", "Ofs", "Len", "Type", "Min Time", "Points", "Enc [T/V]", "Len [T/V]"}, "\t"))
+
+	// Starting at 5 because the magic number is 4 bytes + 1 byte version
+	i := int64(5)
+	var blockCount, pointCount, blockSize int64
+	indexSize := r.IndexSize()
+
+	// Start at the beginning and read every block
+	for j := 0; j < keyCount; j++ {
+		key, _ := r.KeyAt(j)
+		k := decodeKey(key)
+		for _, e := range r.Entries(key) {
+			checksum, buf, err := r.ReadBytes(&e, nil)
+			if err != nil {
+				return err
+			}
+
+			blockSize += int64(e.Size)
+
+			if cmd.filterKey != "" && !strings.Contains(k.String(), cmd.filterKey) {
+				i += int64(e.Size)
+				blockCount++
+				continue
+			}
+
+			blockType := buf[0]
+			ts, values, err := unpackBlock(buf[1:])
+			if err != nil {
+				return err
+			}
+			count := tsm1.BlockCount(buf)
+			pointCount += int64(count)
+
+			tsEncoding := timeEnc[int(ts[0]>>4)]
+			vEncoding := encDescs[blockType][values[0]>>4]
+			typeDesc := blockTypes[blockType]
+
+			blockStats.inc(0, ts[0]>>4)
+			blockStats.inc(int(blockType+1), values[0]>>4)
+			blockStats.size(len(buf))
+
+			if cmd.dumpBlocks {
+				fmt.Fprintln(tw, "  "+strings.Join([]string{
+					strconv.FormatInt(blockCount, 10),
+					strconv.FormatUint(uint64(checksum), 10),
+					strconv.FormatInt(i, 10),
+					strconv.FormatInt(int64(len(buf)), 10),
+					typeDesc,
+					time.Unix(0, e.MinTime).UTC().Format(time.RFC3339Nano),
+					strconv.FormatInt(int64(count), 10),
+					fmt.Sprintf("%s/%s", tsEncoding, vEncoding),
+					fmt.Sprintf("%d/%d", len(ts), len(values)),
+				}, "\t"))
+			}
+
+			i += int64(e.Size)
+			blockCount++
+		}
+	}
+
+	if cmd.dumpBlocks {
+		println("Blocks:")
+		tw.Flush()
+		println()
+	}
+
+	var blockSizeAvg int64
+	if blockCount > 0 {
+		blockSizeAvg = blockSize / blockCount
+	}
+	fmt.Fprintf(cmd.Stdout, "Statistics\n")
+	fmt.Fprintf(cmd.Stdout, "  Blocks:\n")
+	fmt.Fprintf(cmd.Stdout, "    Total: %d Size: %d Min: %d Max: %d Avg: %d\n",
+		blockCount, blockSize, blockStats.min, blockStats.max, blockSizeAvg)
+	fmt.Fprintf(cmd.Stdout, "  Index:\n")
+	fmt.Fprintf(cmd.Stdout, "    Total: %d Size: %d\n", blockCount, indexSize)
+	fmt.Fprintf(cmd.Stdout, "  Points:\n")
+	fmt.Fprintf(cmd.Stdout, "    Total: %d", pointCount)
+	println()
+
+	println("  Encoding:")
+	for i, counts := range blockStats.counts {
+		if len(counts) == 0 {
+			continue
+		}
+		fmt.Fprintf(cmd.Stdout, "    %s: ", strings.Title(fieldType[i]))
+		for j, v := range counts {
+			fmt.Fprintf(cmd.Stdout, "\t%s: %d (%d%%) ", encDescs[byte(i-1)][j], v, int(float64(v)/float64(blockCount)*100))
+		}
+		println()
+	}
+	fmt.Fprintf(cmd.Stdout, "  Compression:\n")
+	fmt.Fprintf(cmd.Stdout, "    Per block: %0.2f bytes/point\n", float64(blockSize)/float64(pointCount))
+	fmt.Fprintf(cmd.Stdout, "    Total: %0.2f bytes/point\n", float64(stat.Size())/float64(pointCount))
+
+	return nil
+}
+
+// key is a TSM key with the organization and bucket of its name decoded.
+type key struct {
+	org, bucket string
+	series      string // The series key, without its name.
+	field       string
+}
+
+// decodeKey decodes the organization, bucket, series and field of a TSM key. The organization
+// and bucket are left empty if the name of the series is not an encoded organization and bucket.
+func decodeKey(b []byte) key {
+	seriesKey, field := tsm1.SeriesAndFieldFromCompositeKey(b)
+	name, tags := models.ParseKeyBytes(seriesKey)
+
+	k := key{series: string(tags.HashKey()), field: string(field)}
+	if len(name) == 16 {
+		var nameBytes [16]byte
+		copy(nameBytes[:], name)
+		org, bucket := tsdb.DecodeName(nameBytes)
+		k.org, k.bucket = org.String(), bucket.String()
+	} else {
+		k.series = string(name) + k.series
+	}
+	k.series = strings.TrimPrefix(k.series, ",")
+	return k
+}
+
+func (k key) String() string {
+	return k.org + "/" + k.bucket + "," + k.series + "#!~#" + k.field
+}
+
+// unpackBlock returns the encoded timestamps and values of a block, without its type.
+func unpackBlock(buf []byte) (ts, values []byte, err error) {
+	tsLen, i := binary.Uvarint(buf)
+	if i <= 0 || int(i)+int(tsLen) >= len(buf) {
+		return nil, nil, fmt.Errorf("unable to unpack block")
+	}
+	return buf[int(i) : int(i)+int(tsLen)], buf[int(i)+int(tsLen):], nil
+}
+
+var (
+	fieldType = []string{
+		"timestamp", "float", "int", "bool", "string", "unsigned",
+	}
+	blockTypes = map[byte]string{
+		tsm1.BlockFloat64:  "float64",
+		tsm1.BlockInteger:  "int64",
+		tsm1.BlockBoolean:  "bool",
+		tsm1.BlockString:   "string",
+		tsm1.BlockUnsigned: "unsigned",
+	}
+	timeEnc = []string{
+		"none", "s8b", "rle",
+	}
+	encDescs = map[byte][]string{
+		255:                {"none", "s8b", "rle"}, // timestamps, as the type before the first block type.
+		tsm1.BlockFloat64:  {"none", "gor"},
+		tsm1.BlockInteger:  {"none", "s8b", "rle"},
+		tsm1.BlockBoolean:  {"none", "bp"},
+		tsm1.BlockString:   {"none", "snpy", "dict"},
+		tsm1.BlockUnsigned: {"none", "s8b", "rle"},
+	}
+)
+
+type blockStats struct {
+	min, max int
+	counts   [][]int
+}
+
+func (b *blockStats) inc(typ int, enc byte) {
+	for len(b.counts) <= typ {
+		b.counts = append(b.counts, []int{})
+	}
+	for len(b.counts[typ]) <= int(enc) {
+		b.counts[typ] = append(b.counts[typ], 0)
+	}
+	b.counts[typ][enc]++
+}
+
+func (b *blockStats) size(sz int) {
+	if b.min == 0 || sz < b.min {
+		b.min = sz
+	}
+	if b.max == 0 || sz > b.max {
+		b.max = sz
+	}
+}
+
+// printUsage prints the usage message to STDOUT.
+func (cmd *Command) printUsage() {
+	usage := `Dumps low-level details about a TSM file.
+
+Usage: influx_inspect dumptsm [flags] <path>
+
+    -index
+            Dump raw index data.
+    -blocks
+            Dump raw block data.
+    -all
+            Dump all data. Caution: This may print a lot of information.
+    -filter-key <name>
+            Only display data matching this key substring. Keys are displayed as
+            <org id>/<bucket id>,<tags>#!~#<field>.
+`
+
+	fmt.Fprint(cmd.Stdout, usage)
+}
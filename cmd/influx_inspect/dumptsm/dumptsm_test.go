@@ -0,0 +1,92 @@
+package dumptsm_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/platform"
+	"github.com/influxdata/platform/cmd/influx_inspect/dumptsm"
+	"github.com/influxdata/platform/models"
+	"github.com/influxdata/platform/tsdb"
+	"github.com/influxdata/platform/tsdb/tsm1"
+)
+
+func TestCommand_Run(t *testing.T) {
+	dir, err := ioutil.TempDir("", "influx_inspect_dumptsm_")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	name := tsdb.EncodeName(platform.ID(1), platform.ID(2))
+	key := func(host, field string) []byte {
+		tags := models.NewTags(map[string]string{tsdb.MeasurementTagKey: "cpu", tsdb.FieldKeyTagKey: field, "host": host})
+		return tsm1.SeriesFieldKeyBytes(string(models.MakeKey(name[:], tags)), field)
+	}
+
+	path := filepath.Join(dir, tsm1.DefaultFormatFileName(1, 1)+"."+tsm1.TSMFileExtension)
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := tsm1.NewTSMWriter(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(key("a", "user"), tsm1.Values{tsm1.NewValue(1, 1.0), tsm1.NewValue(2, 2.0)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(key("b", "user"), tsm1.Values{tsm1.NewValue(3, int64(3))}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteIndex(); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("summary", func(t *testing.T) {
+		var buf bytes.Buffer
+		cmd := dumptsm.NewCommand()
+		cmd.Stdout, cmd.Stderr = &buf, &buf
+		if err := cmd.Run(path); err != nil {
+			t.Fatal(err)
+		}
+
+		out := buf.String()
+		for _, exp := range []string{"Series: 2", "Total: 2 Size:", "Total: 3\n"} {
+			if !strings.Contains(out, exp) {
+				t.Errorf("expected output to contain %q:\n%s", exp, out)
+			}
+		}
+		if strings.Contains(out, "Min Time") {
+			t.Errorf("unexpected index or blocks in summary:\n%s", out)
+		}
+	})
+
+	t.Run("filter key", func(t *testing.T) {
+		var buf bytes.Buffer
+		cmd := dumptsm.NewCommand()
+		cmd.Stdout, cmd.Stderr = &buf, &buf
+		if err := cmd.Run("-filter-key", "host=b", path); err != nil {
+			t.Fatal(err)
+		}
+
+		// Keys are displayed with their organization and bucket decoded.
+		out := buf.String()
+		if !strings.Contains(strings.Join(strings.Fields(out), " "), "0000000000000001 0000000000000002 _f=user,_m=cpu,host=b user") {
+			t.Errorf("expected the index entry of the filtered key:\n%s", out)
+		}
+		if strings.Contains(out, "host=a") {
+			t.Errorf("unexpected entry of a key not matching the filter:\n%s", out)
+		}
+		if !strings.Contains(out, "int64") || strings.Contains(out, "float64") {
+			t.Errorf("expected only the integer block of the filtered key:\n%s", out)
+		}
+	})
+}
@@ -0,0 +1,391 @@
+// Package export exports the data of a bucket to line protocol.
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/platform"
+	"github.com/influxdata/platform/internal/fs"
+	"github.com/influxdata/platform/models"
+	"github.com/influxdata/platform/pkg/escape"
+	"github.com/influxdata/platform/storage"
+	"github.com/influxdata/platform/tsdb"
+	"github.com/influxdata/platform/tsdb/tsm1"
+)
+
+// Command represents the program execution for "influx_inspect export".
+type Command struct {
+	Stderr io.Writer
+	Stdout io.Writer
+
+	enginePath string
+	orgID      platform.ID
+	bucketID   platform.ID
+	out        string
+	compress   bool
+
+	startTime int64
+	endTime   int64
+}
+
+// NewCommand returns a new instance of Command.
+func NewCommand() *Command {
+	return &Command{
+		Stderr: os.Stderr,
+		Stdout: os.Stdout,
+	}
+}
+
+// Run executes the command.
+func (cmd *Command) Run(args ...string) error {
+	enginePath, err := fs.EnginePath()
+	if err != nil {
+		return err
+	}
+
+	var orgID, bucketID, start, end string
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	fs.StringVar(&cmd.enginePath, "engine-path", enginePath, "path to the storage engine files of influxd")
+	fs.StringVar(&orgID, "org-id", "", "organization of the bucket to export")
+	fs.StringVar(&bucketID, "bucket-id", "", "bucket to export")
+	fs.StringVar(&start, "start", "", "optional: the start time to export (RFC3339 format)")
+	fs.StringVar(&end, "end", "", "optional: the end time to export (RFC3339 format)")
+	fs.StringVar(&cmd.out, "out", "", "optional: file to export to; defaults to STDOUT")
+	fs.BoolVar(&cmd.compress, "compress", false, "compress the output with gzip")
+	fs.SetOutput(cmd.Stdout)
+	fs.Usage = cmd.printUsage
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	} else if fs.NArg() > 0 || orgID == "" || bucketID == "" {
+		fs.Usage()
+		return nil
+	}
+
+	if err := cmd.orgID.DecodeFromString(orgID); err != nil {
+		return fmt.Errorf("invalid org-id: %v", err)
+	}
+	if err := cmd.bucketID.DecodeFromString(bucketID); err != nil {
+		return fmt.Errorf("invalid bucket-id: %v", err)
+	}
+
+	cmd.startTime, cmd.endTime = math.MinInt64, math.MaxInt64
+	if start != "" {
+		t, err := time.Parse(time.RFC3339, start)
+		if err != nil {
+			return err
+		}
+		cmd.startTime = t.UnixNano()
+	}
+	if end != "" {
+		t, err := time.Parse(time.RFC3339, end)
+		if err != nil {
+			return err
+		}
+		cmd.endTime = t.UnixNano()
+	}
+	if cmd.startTime > cmd.endTime {
+		return fmt.Errorf("end time before start time")
+	}
+
+	return cmd.export()
+}
+
+func (cmd *Command) export() error {
+	var w io.Writer = cmd.Stdout
+	if cmd.out != "" {
+		f, err := os.Create(cmd.out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	bw := bufio.NewWriter(w)
+	w = bw
+	if cmd.compress {
+		gzw := gzip.NewWriter(bw)
+		defer gzw.Close()
+		w = gzw
+	}
+
+	// The WAL is read first, as its deletes also apply to the older data of the TSM files.
+	config := storage.NewConfig()
+	cache, deletes, err := cmd.readWALFiles(config.GetWALPath(cmd.enginePath))
+	if err != nil {
+		return err
+	}
+	if err := cmd.writeTSMFiles(w, config.GetEnginePath(cmd.enginePath), deletes); err != nil {
+		return err
+	}
+	if err := cmd.writeCache(w, cache); err != nil {
+		return err
+	}
+
+	if gzw, ok := w.(*gzip.Writer); ok {
+		if err := gzw.Close(); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// prefix returns the prefix of the series keys of the exported bucket.
+func (cmd *Command) prefix() []byte {
+	name := tsdb.EncodeName(cmd.orgID, cmd.bucketID)
+	return append(models.MakeKey(name[:], nil), ',')
+}
+
+// timeRange is a range of time deleted from a key, inclusive.
+type timeRange struct {
+	min, max int64
+}
+
+// walDeletes are the ranges of time deleted from keys by the entries of the WAL.
+type walDeletes map[string][]timeRange
+
+// add deletes the range of time between min and max from every key of keys.
+func (d walDeletes) add(keys [][]byte, min, max int64) {
+	for _, key := range keys {
+		d[string(key)] = append(d[string(key)], timeRange{min: min, max: max})
+	}
+}
+
+// apply removes the values of key deleted by the WAL.
+func (d walDeletes) apply(key []byte, values tsm1.Values) tsm1.Values {
+	for _, tr := range d[string(key)] {
+		values = values.Exclude(tr.min, tr.max)
+	}
+	return values
+}
+
+// writeTSMFiles writes the data of the bucket in every TSM file of dir. Deleted values
+// are skipped, as the tombstones of each file are applied when its values are read, and
+// then the deletes of the WAL, which may not have been written to tombstones yet.
+func (cmd *Command) writeTSMFiles(w io.Writer, dir string, deletes walDeletes) error {
+	paths, err := filepath.Glob(filepath.Join(dir, "*."+tsm1.TSMFileExtension))
+	if err != nil {
+		return err
+	}
+
+	prefix := cmd.prefix()
+	for _, path := range paths {
+		if err := cmd.writeTSMFile(w, path, prefix, deletes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cmd *Command) writeTSMFile(w io.Writer, path string, prefix []byte, deletes walDeletes) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	r, err := tsm1.NewTSMReader(f)
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	defer r.Close()
+
+	if min, max := r.TimeRange(); min > cmd.endTime || max < cmd.startTime {
+		return nil
+	}
+
+	for i := r.Seek(prefix); i < r.KeyCount(); i++ {
+		key, _ := r.KeyAt(i)
+		if !bytes.HasPrefix(key, prefix) {
+			break
+		}
+
+		values, err := r.ReadAll(key)
+		if err != nil {
+			fmt.Fprintf(cmd.Stderr, "%s: unable to read key %q: %v\n", path, key, err)
+			continue
+		}
+		if err := cmd.writeValues(w, key, deletes.apply(key, values)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readWALFiles reads the WAL segments of dir. The entries of the segments are applied in
+// order to a cache, so that the values of later deletes are skipped, and the deletes of the
+// keys of the bucket are returned so they can be applied to the TSM files as well.
+func (cmd *Command) readWALFiles(dir string) (*tsm1.Cache, walDeletes, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, fmt.Sprintf("%s*.%s", tsm1.WALFilePrefix, tsm1.WALFileExtension)))
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.Strings(paths)
+
+	cache := tsm1.NewCache(0)
+	deletes := make(walDeletes)
+	for _, path := range paths {
+		if err := cmd.readWALFile(cache, deletes, path); err != nil {
+			return nil, nil, err
+		}
+	}
+	return cache, deletes, nil
+}
+
+// writeCache writes the data of the bucket in cache.
+func (cmd *Command) writeCache(w io.Writer, cache *tsm1.Cache) error {
+	prefix := cmd.prefix()
+	for _, key := range cache.Keys() {
+		if !bytes.HasPrefix(key, prefix) {
+			continue
+		}
+		if err := cmd.writeValues(w, key, cache.Values(key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readWALFile applies the entries of the WAL segment at path to cache, and adds its deletes
+// of the keys of the bucket to deletes. Unlike the cache loader of the engine, a corrupt
+// segment is read up to its first corrupt entry but never truncated.
+func (cmd *Command) readWALFile(cache *tsm1.Cache, deletes walDeletes, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	r := tsm1.NewWALSegmentReader(f)
+	defer r.Close()
+
+	prefix := cmd.prefix()
+	for r.Next() {
+		entry, err := r.Read()
+		if err != nil {
+			fmt.Fprintf(cmd.Stderr, "%s: corrupt entry at position %d, skipping the rest of the file: %v\n", path, r.Count(), err)
+			return nil
+		}
+
+		switch t := entry.(type) {
+		case *tsm1.WriteWALEntry:
+			if err := cache.WriteMulti(t.Values); err != nil {
+				return err
+			}
+		case *tsm1.DeleteRangeWALEntry:
+			cache.DeleteRange(t.Keys, t.Min, t.Max)
+			deletes.add(keysWithPrefix(t.Keys, prefix), t.Min, t.Max)
+		case *tsm1.DeleteWALEntry:
+			cache.Delete(t.Keys)
+			deletes.add(keysWithPrefix(t.Keys, prefix), math.MinInt64, math.MaxInt64)
+		}
+	}
+	return nil
+}
+
+// keysWithPrefix returns the keys of keys that begin with prefix.
+func keysWithPrefix(keys [][]byte, prefix []byte) [][]byte {
+	var a [][]byte
+	for _, key := range keys {
+		if bytes.HasPrefix(key, prefix) {
+			a = append(a, key)
+		}
+	}
+	return a
+}
+
+// writeValues writes the values of the TSM key in the exported time range as line protocol.
+// The measurement and field of the lines are restored from the tags the storage engine stores
+// them in, and those tags are removed.
+func (cmd *Command) writeValues(w io.Writer, key []byte, values []tsm1.Value) error {
+	seriesKey, _ := tsm1.SeriesAndFieldFromCompositeKey(key)
+	_, tags := models.ParseKeyBytes(seriesKey)
+
+	measurement := tags.Get(tsdb.MeasurementTagKeyBytes)
+	field := tags.Get(tsdb.FieldKeyTagKeyBytes)
+	userTags := make(models.Tags, 0, len(tags))
+	for _, tag := range tags {
+		if k := string(tag.Key); k != tsdb.MeasurementTagKey && k != tsdb.FieldKeyTagKey {
+			userTags = append(userTags, tag)
+		}
+	}
+
+	buf := models.MakeKey(measurement, userTags)
+	buf = append(buf, ' ')
+	buf = append(buf, escape.Bytes(field)...)
+	buf = append(buf, '=')
+	n := len(buf)
+
+	for _, value := range values {
+		ts := value.UnixNano()
+		if ts < cmd.startTime || ts > cmd.endTime {
+			continue
+		}
+
+		buf = buf[:n]
+		switch v := value.Value().(type) {
+		case float64:
+			buf = strconv.AppendFloat(buf, v, 'g', -1, 64)
+		case int64:
+			buf = strconv.AppendInt(buf, v, 10)
+			buf = append(buf, 'i')
+		case uint64:
+			buf = strconv.AppendUint(buf, v, 10)
+			buf = append(buf, 'u')
+		case bool:
+			buf = strconv.AppendBool(buf, v)
+		case string:
+			buf = append(buf, '"')
+			buf = append(buf, models.EscapeStringField(v)...)
+			buf = append(buf, '"')
+		default:
+			buf = append(buf, []byte(fmt.Sprintf("%v", v))...)
+		}
+		buf = append(buf, ' ')
+		buf = strconv.AppendInt(buf, ts, 10)
+		buf = append(buf, '\n')
+
+		if _, err := w.Write(buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printUsage prints the usage message to STDOUT.
+func (cmd *Command) printUsage() {
+	usage := `Exports the data of a bucket to line protocol, with the measurement and field
+of every point restored. The output can be written back with "influx write".
+
+Usage: influx_inspect export [flags]
+
+    -engine-path <path>
+            Path to the storage engine files of influxd.
+            Defaults to "~/.influxdbv2/engine".
+    -org-id <id>
+            Organization of the bucket to export. Required.
+    -bucket-id <id>
+            Bucket to export. Required.
+    -start <time>
+            The start of the time range to export, in RFC3339 format.
+    -end <time>
+            The end of the time range to export, in RFC3339 format.
+    -out <path>
+            File to export to. Defaults to STDOUT.
+    -compress
+            Compress the output with gzip.
+`
+
+	fmt.Fprint(cmd.Stdout, usage)
+}
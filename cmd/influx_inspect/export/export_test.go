@@ -0,0 +1,150 @@
+package export_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/platform"
+	"github.com/influxdata/platform/cmd/influx_inspect/export"
+	"github.com/influxdata/platform/models"
+	"github.com/influxdata/platform/storage"
+	"github.com/influxdata/platform/tsdb"
+	"github.com/influxdata/platform/tsdb/tsm1"
+)
+
+func TestCommand_Run(t *testing.T) {
+	dir, err := ioutil.TempDir("", "influx_inspect_export_")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	org, bucket, other := platform.ID(1), platform.ID(2), platform.ID(3)
+
+	// Points written through the engine stay in its WAL.
+	engine := storage.NewEngine(dir, storage.NewConfig())
+	if err := engine.Open(); err != nil {
+		t.Fatal(err)
+	}
+	for _, b := range []platform.ID{bucket, other} {
+		points, err := tsdb.ExplodePoints(org, b, []models.Point{
+			models.MustNewPoint("cpu", models.NewTags(map[string]string{"host": "a b"}), map[string]interface{}{"user": 1.5, "count": int64(3)}, time.Unix(0, 20)),
+			models.MustNewPoint("mem", nil, map[string]interface{}{"state": `ok "now"`}, time.Unix(0, 30)),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := engine.WritePoints(points); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := engine.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Older points are in a TSM file.
+	name := tsdb.EncodeName(org, bucket)
+	tags := models.NewTags(map[string]string{tsdb.MeasurementTagKey: "cpu", tsdb.FieldKeyTagKey: "user", "host": "a b"})
+	key := tsm1.SeriesFieldKeyBytes(string(models.MakeKey(name[:], tags)), "user")
+	MustWriteTSM(t, filepath.Join(storage.NewConfig().GetEnginePath(dir), tsm1.DefaultFormatFileName(1, 1)+"."+tsm1.TSMFileExtension),
+		key, tsm1.Values{tsm1.NewValue(5, 0.5), tsm1.NewValue(10, 1.0)})
+
+	var buf bytes.Buffer
+	cmd := export.NewCommand()
+	cmd.Stdout, cmd.Stderr = &buf, &buf
+	if err := cmd.Run("-engine-path", dir, "-org-id", org.String(), "-bucket-id", bucket.String(), "-start", "1970-01-01T00:00:00.000000006Z"); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	sort.Strings(lines)
+	exp := []string{
+		`cpu,host=a\ b count=3i 20`,
+		`cpu,host=a\ b user=1 10`,
+		`cpu,host=a\ b user=1.5 20`,
+		`mem state="ok \"now\"" 30`,
+	}
+	if got := strings.Join(lines, "\n"); got != strings.Join(exp, "\n") {
+		t.Fatalf("unexpected output:\ngot:\n%s\nexp:\n%s", got, strings.Join(exp, "\n"))
+	}
+}
+
+func TestCommand_Run_WALDeletes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "influx_inspect_export_")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	org, bucket := platform.ID(1), platform.ID(2)
+	name := tsdb.EncodeName(org, bucket)
+	config := storage.NewConfig()
+
+	tags := models.NewTags(map[string]string{tsdb.MeasurementTagKey: "cpu", tsdb.FieldKeyTagKey: "user"})
+	user := tsm1.SeriesFieldKeyBytes(string(models.MakeKey(name[:], tags)), "user")
+	tags = models.NewTags(map[string]string{tsdb.MeasurementTagKey: "cpu", tsdb.FieldKeyTagKey: "system"})
+	system := tsm1.SeriesFieldKeyBytes(string(models.MakeKey(name[:], tags)), "system")
+
+	if err := os.MkdirAll(config.GetEnginePath(dir), 0777); err != nil {
+		t.Fatal(err)
+	}
+	MustWriteTSM(t, filepath.Join(config.GetEnginePath(dir), tsm1.DefaultFormatFileName(1, 1)+"."+tsm1.TSMFileExtension),
+		user, tsm1.Values{tsm1.NewValue(5, 0.5), tsm1.NewValue(10, 1.0), tsm1.NewValue(15, 1.5)})
+	MustWriteTSM(t, filepath.Join(config.GetEnginePath(dir), tsm1.DefaultFormatFileName(2, 1)+"."+tsm1.TSMFileExtension),
+		system, tsm1.Values{tsm1.NewValue(5, 2.0)})
+
+	// The deletes in the WAL have not been written to tombstones of the TSM files.
+	wal := tsm1.NewWAL(config.GetWALPath(dir))
+	if err := wal.Open(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wal.DeleteRange([][]byte{user}, 10, 15); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wal.Delete([][]byte{system}); err != nil {
+		t.Fatal(err)
+	}
+	if err := wal.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	cmd := export.NewCommand()
+	cmd.Stdout, cmd.Stderr = &buf, &buf
+	if err := cmd.Run("-engine-path", dir, "-org-id", org.String(), "-bucket-id", bucket.String()); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, exp := strings.TrimSpace(buf.String()), `cpu user=0.5 5`; got != exp {
+		t.Fatalf("unexpected output:\ngot:\n%s\nexp:\n%s", got, exp)
+	}
+}
+
+// MustWriteTSM writes a TSM file at path with the values of a single key.
+func MustWriteTSM(tb testing.TB, path string, key []byte, values tsm1.Values) {
+	tb.Helper()
+
+	f, err := os.Create(path)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	w, err := tsm1.NewTSMWriter(f)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	if err := w.Write(key, values); err != nil {
+		tb.Fatal(err)
+	}
+	if err := w.WriteIndex(); err != nil {
+		tb.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		tb.Fatal(err)
+	}
+}
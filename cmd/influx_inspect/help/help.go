@@ -0,0 +1,44 @@
+// Package help contains the help for the influx_inspect command.
+package help
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Command displays help for command-line sub-commands.
+type Command struct {
+	Stdout io.Writer
+}
+
+// NewCommand returns a new instance of Command.
+func NewCommand() *Command {
+	return &Command{
+		Stdout: os.Stdout,
+	}
+}
+
+// Run executes the command.
+func (cmd *Command) Run(args ...string) error {
+	fmt.Fprintln(cmd.Stdout, strings.TrimSpace(usage))
+	return nil
+}
+
+const usage = `
+Usage: influx_inspect [[command] [arguments]]
+
+The commands are:
+
+    buildtsi             converts in-memory (TSM-based) shards to TSI
+    dumptsm              dumps low-level details about a TSM file
+    export               exports the data of a bucket to line protocol
+    help                 display this help message
+    report               displays series and cardinality by organization, bucket and measurement
+    verify               verifies the integrity of the TSM, series and index files
+
+"help" is the default command.
+
+Use "influx_inspect [command] -help" for more information about a command.
+`
@@ -0,0 +1,294 @@
+// Package report reports the series cardinality of the TSM files of the storage engine.
+package report
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/influxdata/platform"
+	"github.com/influxdata/platform/internal/fs"
+	"github.com/influxdata/platform/models"
+	"github.com/influxdata/platform/pkg/estimator"
+	"github.com/influxdata/platform/pkg/estimator/hll"
+	"github.com/influxdata/platform/storage"
+	"github.com/influxdata/platform/tsdb"
+	"github.com/influxdata/platform/tsdb/tsm1"
+)
+
+// Command represents the program execution for "influx_inspect report".
+type Command struct {
+	Stderr io.Writer
+	Stdout io.Writer
+
+	orgID    string
+	bucketID string
+	detailed bool
+	exact    bool
+}
+
+// NewCommand returns a new instance of Command.
+func NewCommand() *Command {
+	return &Command{
+		Stderr: os.Stderr,
+		Stdout: os.Stdout,
+	}
+}
+
+// Run executes the command.
+func (cmd *Command) Run(args ...string) error {
+	enginePath, err := fs.EnginePath()
+	if err != nil {
+		return err
+	}
+
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+	fs.StringVar(&enginePath, "engine-path", enginePath, "path to the storage engine files of influxd")
+	fs.StringVar(&cmd.orgID, "org-id", "", "optional: only report the buckets of this organization")
+	fs.StringVar(&cmd.bucketID, "bucket-id", "", "optional: only report this bucket")
+	fs.BoolVar(&cmd.detailed, "detailed", false, "report the cardinality of every measurement")
+	fs.BoolVar(&cmd.exact, "exact", false, "report exact counts instead of estimates; uses more memory")
+	fs.SetOutput(cmd.Stdout)
+	fs.Usage = cmd.printUsage
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	} else if fs.NArg() > 0 {
+		fs.Usage()
+		return nil
+	}
+	return cmd.run(enginePath)
+}
+
+func (cmd *Command) run(enginePath string) error {
+	var orgID, bucketID platform.ID
+	if cmd.orgID != "" {
+		if err := orgID.DecodeFromString(cmd.orgID); err != nil {
+			return fmt.Errorf("invalid org-id: %v", err)
+		}
+	}
+	if cmd.bucketID != "" {
+		if err := bucketID.DecodeFromString(cmd.bucketID); err != nil {
+			return fmt.Errorf("invalid bucket-id: %v", err)
+		}
+	}
+
+	dir := storage.NewConfig().GetEnginePath(enginePath)
+	paths, err := filepath.Glob(filepath.Join(dir, "*."+tsm1.TSMFileExtension))
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	buckets := make(map[bucketKey]*bucketCardinality)
+	total := cmd.newCounter()
+	var key []byte
+	for _, path := range paths {
+		if err := readKeys(path, func(seriesKey []byte) {
+			name, tags := models.ParseKeyBytes(seriesKey)
+			if len(name) != 16 {
+				// Not a key written by the storage engine of influxd.
+				return
+			}
+			var nameBytes [16]byte
+			copy(nameBytes[:], name)
+			org, bucket := tsdb.DecodeName(nameBytes)
+			if (cmd.orgID != "" && org != orgID) || (cmd.bucketID != "" && bucket != bucketID) {
+				return
+			}
+
+			b := buckets[bucketKey{org, bucket}]
+			if b == nil {
+				b = &bucketCardinality{series: cmd.newCounter(), measurements: make(map[string]estimator.Sketch)}
+				buckets[bucketKey{org, bucket}] = b
+			}
+
+			// The key of the series without its field identifies the series across its fields.
+			key = append(key[:0], name...)
+			for _, tag := range tags {
+				if string(tag.Key) != tsdb.FieldKeyTagKey {
+					key = append(append(append(append(key, ','), tag.Key...), '='), tag.Value...)
+				}
+			}
+			total.Add(key)
+			b.series.Add(key)
+
+			if cmd.detailed {
+				m := string(tags.Get(tsdb.MeasurementTagKeyBytes))
+				sketch := b.measurements[m]
+				if sketch == nil {
+					sketch = cmd.newCounter()
+					b.measurements[m] = sketch
+				}
+				sketch.Add(key)
+			}
+		}); err != nil {
+			return err
+		}
+	}
+
+	keys := make([]bucketKey, 0, len(buckets))
+	for k := range buckets {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].org != keys[j].org {
+			return keys[i].org < keys[j].org
+		}
+		return keys[i].bucket < keys[j].bucket
+	})
+
+	tw := tabwriter.NewWriter(cmd.Stdout, 8, 2, 1, ' ', 0)
+	if cmd.detailed {
+		fmt.Fprintln(tw, strings.Join([]string{"Org", "Bucket", "Measurement", "Series"}, "\t"))
+	} else {
+		fmt.Fprintln(tw, strings.Join([]string{"Org", "Bucket", "Series"}, "\t"))
+	}
+	for _, k := range keys {
+		b := buckets[k]
+		if !cmd.detailed {
+			fmt.Fprintf(tw, "%s\t%s\t%d\n", k.org, k.bucket, b.series.Count())
+			continue
+		}
+
+		names := make([]string, 0, len(b.measurements))
+		for name := range b.measurements {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%d\n", k.org, k.bucket, name, b.measurements[name].Count())
+		}
+	}
+	tw.Flush()
+
+	fmt.Fprintf(cmd.Stdout, "\nSummary:\n")
+	fmt.Fprintf(cmd.Stdout, "  Files: %d\n", len(paths))
+	fmt.Fprintf(cmd.Stdout, "  Buckets: %d\n", len(buckets))
+	fmt.Fprintf(cmd.Stdout, "  Series (%s): %d\n", cmd.countKind(), total.Count())
+	fmt.Fprintf(cmd.Stdout, "Completed in %s\n", time.Since(start))
+	return nil
+}
+
+// readKeys calls fn with the series key of every key of the TSM file at path.
+// A series key is passed once for every field of the series.
+func readKeys(path string, fn func(seriesKey []byte)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	r, err := tsm1.NewTSMReader(f)
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	defer r.Close()
+
+	for i := 0; i < r.KeyCount(); i++ {
+		key, _ := r.KeyAt(i)
+		seriesKey, _ := tsm1.SeriesAndFieldFromCompositeKey(key)
+		fn(seriesKey)
+	}
+	return nil
+}
+
+type bucketKey struct {
+	org, bucket platform.ID
+}
+
+type bucketCardinality struct {
+	series       estimator.Sketch
+	measurements map[string]estimator.Sketch
+}
+
+func (cmd *Command) newCounter() estimator.Sketch {
+	if cmd.exact {
+		return &exactCounter{keys: make(map[string]struct{})}
+	}
+	return hll.NewDefaultPlus()
+}
+
+func (cmd *Command) countKind() string {
+	if cmd.exact {
+		return "exact"
+	}
+	return "est."
+}
+
+// exactCounter is an estimator.Sketch that counts the distinct values added to it exactly.
+type exactCounter struct {
+	keys map[string]struct{}
+}
+
+func (c *exactCounter) Add(v []byte) {
+	c.keys[string(v)] = struct{}{}
+}
+
+func (c *exactCounter) Count() uint64 {
+	return uint64(len(c.keys))
+}
+
+func (c *exactCounter) Merge(s estimator.Sketch) error {
+	other, ok := s.(*exactCounter)
+	if !ok {
+		return fmt.Errorf("cannot merge %T into an exact counter", s)
+	}
+	for k := range other.keys {
+		c.keys[k] = struct{}{}
+	}
+	return nil
+}
+
+func (c *exactCounter) Bytes() int {
+	var n int
+	for k := range c.keys {
+		n += len(k)
+	}
+	return n
+}
+
+func (c *exactCounter) Clone() estimator.Sketch {
+	other := &exactCounter{keys: make(map[string]struct{}, len(c.keys))}
+	for k := range c.keys {
+		other.keys[k] = struct{}{}
+	}
+	return other
+}
+
+func (c *exactCounter) MarshalBinary() ([]byte, error) {
+	return nil, fmt.Errorf("exact counters cannot be marshaled")
+}
+
+func (c *exactCounter) UnmarshalBinary(data []byte) error {
+	return fmt.Errorf("exact counters cannot be unmarshaled")
+}
+
+// printUsage prints the usage message to STDOUT.
+func (cmd *Command) printUsage() {
+	usage := `Displays the series cardinality of the TSM files of influxd, by organization
+and bucket. The series of a measurement are counted once whatever their fields.
+
+Usage: influx_inspect report [flags]
+
+    -engine-path <path>
+            Path to the storage engine files of influxd.
+            Defaults to "~/.influxdbv2/engine".
+    -org-id <id>
+            Only report the buckets of this organization.
+    -bucket-id <id>
+            Only report this bucket.
+    -detailed
+            Report the cardinality of every measurement of every bucket.
+    -exact
+            Report exact counts instead of estimates. Uses more memory.
+`
+
+	fmt.Fprint(cmd.Stdout, usage)
+}
@@ -0,0 +1,124 @@
+package report_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/platform"
+	"github.com/influxdata/platform/cmd/influx_inspect/report"
+	"github.com/influxdata/platform/models"
+	"github.com/influxdata/platform/storage"
+	"github.com/influxdata/platform/tsdb"
+	"github.com/influxdata/platform/tsdb/tsm1"
+)
+
+func TestCommand_Run(t *testing.T) {
+	dir, err := ioutil.TempDir("", "influx_inspect_report_")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	org, bucket, other := platform.ID(1), platform.ID(2), platform.ID(3)
+	key := func(bucketID platform.ID, measurement, host, field string) []byte {
+		name := tsdb.EncodeName(org, bucketID)
+		tags := models.NewTags(map[string]string{tsdb.MeasurementTagKey: measurement, tsdb.FieldKeyTagKey: field, "host": host})
+		return tsm1.SeriesFieldKeyBytes(string(models.MakeKey(name[:], tags)), field)
+	}
+
+	// The series of a measurement are counted once whatever their fields, and
+	// once whatever the files they are in.
+	tsmDir := storage.NewConfig().GetEnginePath(dir)
+	if err := os.MkdirAll(tsmDir, 0777); err != nil {
+		t.Fatal(err)
+	}
+	MustWriteTSM(t, filepath.Join(tsmDir, tsm1.DefaultFormatFileName(1, 1)+"."+tsm1.TSMFileExtension),
+		key(bucket, "cpu", "a", "system"),
+		key(bucket, "cpu", "a", "user"),
+		key(bucket, "cpu", "b", "user"),
+		key(other, "disk", "a", "used"),
+	)
+	MustWriteTSM(t, filepath.Join(tsmDir, tsm1.DefaultFormatFileName(2, 1)+"."+tsm1.TSMFileExtension),
+		key(bucket, "cpu", "b", "user"),
+		key(bucket, "mem", "a", "free"),
+	)
+
+	tests := []struct {
+		name string
+		args []string
+		exp  []string
+	}{
+		{
+			name: "buckets",
+			args: []string{"-exact"},
+			exp: []string{
+				"Org Bucket Series",
+				"0000000000000001 0000000000000002 3",
+				"0000000000000001 0000000000000003 1",
+			},
+		},
+		{
+			name: "measurements of a bucket",
+			args: []string{"-exact", "-detailed", "-bucket-id", bucket.String()},
+			exp: []string{
+				"Org Bucket Measurement Series",
+				"0000000000000001 0000000000000002 cpu 2",
+				"0000000000000001 0000000000000002 mem 1",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			cmd := report.NewCommand()
+			cmd.Stdout, cmd.Stderr = &buf, &buf
+			if err := cmd.Run(append(tt.args, "-engine-path", dir)...); err != nil {
+				t.Fatal(err)
+			}
+
+			// Compare the table, ignoring its alignment.
+			var got []string
+			for _, line := range strings.Split(buf.String(), "\n") {
+				if line == "" {
+					break
+				}
+				got = append(got, strings.Join(strings.Fields(line), " "))
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.exp, "\n") {
+				t.Fatalf("unexpected report:\ngot:\n%s\nexp:\n%s", strings.Join(got, "\n"), strings.Join(tt.exp, "\n"))
+			}
+		})
+	}
+}
+
+// MustWriteTSM writes a TSM file at path with a value for each of keys.
+func MustWriteTSM(tb testing.TB, path string, keys ...[]byte) {
+	tb.Helper()
+	sort.Slice(keys, func(i, j int) bool { return bytes.Compare(keys[i], keys[j]) < 0 })
+
+	f, err := os.Create(path)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	w, err := tsm1.NewTSMWriter(f)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	for _, key := range keys {
+		if err := w.Write(key, tsm1.Values{tsm1.NewValue(1, 1.0)}); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	if err := w.WriteIndex(); err != nil {
+		tb.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		tb.Fatal(err)
+	}
+}
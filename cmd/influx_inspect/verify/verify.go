@@ -0,0 +1,409 @@
+// Package verify verifies the integrity of the TSM, series and index files of the storage engine.
+package verify
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/influxdata/platform/internal/fs"
+	"github.com/influxdata/platform/storage"
+	"github.com/influxdata/platform/tsdb"
+	"github.com/influxdata/platform/tsdb/tsi1"
+	"github.com/influxdata/platform/tsdb/tsm1"
+)
+
+// Command represents the program execution for "influx_inspect verify".
+type Command struct {
+	Stderr  io.Writer
+	Stdout  io.Writer
+	Verbose bool
+
+	problems int // Number of problems found.
+}
+
+// NewCommand returns a new instance of Command.
+func NewCommand() *Command {
+	return &Command{
+		Stderr: os.Stderr,
+		Stdout: os.Stdout,
+	}
+}
+
+// Run executes the command.
+func (cmd *Command) Run(args ...string) error {
+	enginePath, err := fs.EnginePath()
+	if err != nil {
+		return err
+	}
+
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	fs.StringVar(&enginePath, "engine-path", enginePath, "path to the storage engine files of influxd")
+	fs.BoolVar(&cmd.Verbose, "v", false, "verbose: report healthy files as well")
+	fs.SetOutput(cmd.Stdout)
+	fs.Usage = cmd.printUsage
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	} else if fs.NArg() > 0 {
+		fs.Usage()
+		return nil
+	}
+	return cmd.run(enginePath)
+}
+
+func (cmd *Command) run(enginePath string) error {
+	if _, err := os.Stat(enginePath); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	config := storage.NewConfig()
+
+	if err := cmd.verifyTSM(config.GetEnginePath(enginePath)); err != nil {
+		return err
+	}
+
+	// The series file is opened once, read-only, for both the series file and the index.
+	// The index cannot be checked against a series file that cannot be opened.
+	sfilePath := config.GetSeriesFilePath(enginePath)
+	sfile := tsdb.NewSeriesFile(sfilePath)
+	sfile.ReadOnly = true
+	if err := sfile.Open(); err != nil {
+		cmd.problems++
+		fmt.Fprintf(cmd.Stdout, "%s: could not open series file: %v\n", sfilePath, err)
+	} else {
+		defer sfile.Close()
+		if err := cmd.verifySeriesFile(sfile); err != nil {
+			return err
+		}
+		if err := cmd.verifyIndex(config.GetIndexPath(enginePath), sfile); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(cmd.Stdout, "Verification completed in %s\n", time.Since(start))
+	if cmd.problems > 0 {
+		return fmt.Errorf("%d problems found", cmd.problems)
+	}
+	return nil
+}
+
+// verifyTSM checks the checksum of every block of every TSM file in dir.
+func (cmd *Command) verifyTSM(dir string) error {
+	paths, err := filesWithExt(dir, "."+tsm1.TSMFileExtension)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(cmd.Stdout, 16, 8, 0, '\t', 0)
+	defer tw.Flush()
+
+	var totalBlocks int
+	for _, path := range paths {
+		blocks, broken, err := verifyTSMFile(path)
+		totalBlocks += blocks
+		switch {
+		case err != nil:
+			cmd.problems++
+			fmt.Fprintf(tw, "%s: could not read file: %v\n", path, err)
+		case broken > 0:
+			cmd.problems++
+			fmt.Fprintf(tw, "%s: %d/%d blocks are corrupt\n", path, broken, blocks)
+		case cmd.Verbose:
+			fmt.Fprintf(tw, "%s: healthy\n", path)
+		}
+	}
+	fmt.Fprintf(tw, "Verified %d blocks in %d TSM files\n", totalBlocks, len(paths))
+	return nil
+}
+
+// verifyTSMFile returns the number of blocks of the TSM file at path, and how many of them
+// do not match their checksum.
+func verifyTSMFile(path string) (blocks, broken int, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	r, err := tsm1.NewTSMReader(f)
+	if err != nil {
+		f.Close()
+		return 0, 0, err
+	}
+	defer r.Close()
+
+	iter := r.BlockIterator()
+	for iter.Next() {
+		_, _, _, _, checksum, buf, err := iter.Read()
+		if err != nil {
+			return blocks, broken, err
+		}
+		blocks++
+		if crc32.ChecksumIEEE(buf) != checksum {
+			broken++
+		}
+	}
+	return blocks, broken, iter.Err()
+}
+
+// verifySeriesFile checks that every entry of the segments of sfile can be read and belongs
+// to its partition, and that the index of each partition finds every series.
+func (cmd *Command) verifySeriesFile(sfile *tsdb.SeriesFile) error {
+	tw := tabwriter.NewWriter(cmd.Stdout, 16, 8, 0, '\t', 0)
+	defer tw.Flush()
+
+	var seriesN int
+	for i := 0; i < tsdb.SeriesFilePartitionN; i++ {
+		n, problems, err := verifySeriesPartition(sfile, i)
+		if err != nil {
+			return err
+		}
+		seriesN += n
+		for _, p := range problems {
+			cmd.problems++
+			fmt.Fprintf(tw, "%s: %s\n", sfile.SeriesPartitionPath(i), p)
+		}
+		if len(problems) == 0 && cmd.Verbose {
+			fmt.Fprintf(tw, "%s: healthy\n", sfile.SeriesPartitionPath(i))
+		}
+	}
+	fmt.Fprintf(tw, "Verified %d series in %d series file partitions\n", seriesN, tsdb.SeriesFilePartitionN)
+	return nil
+}
+
+// verifySeriesPartition verifies the i-th partition of sfile. It returns the number of
+// series of the partition, and a description of each problem found.
+func verifySeriesPartition(sfile *tsdb.SeriesFile, i int) (seriesN int, problems []string, err error) {
+	dir := sfile.SeriesPartitionPath(i)
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	// Read the entries of every segment, in order, keeping the key of every series not deleted.
+	keys := make(map[tsdb.SeriesID][]byte)
+	var lastID uint64
+	for _, fi := range fis {
+		if !tsdb.IsValidSeriesSegmentFilename(fi.Name()) {
+			continue
+		}
+
+		id, err := tsdb.ParseSeriesSegmentFilename(fi.Name())
+		if err != nil {
+			return 0, nil, err
+		}
+
+		segment := tsdb.NewSeriesSegment(id, filepath.Join(dir, fi.Name()))
+		if err := segment.Open(); err != nil {
+			problems = append(problems, fmt.Sprintf("segment %s: could not open: %v", fi.Name(), err))
+			continue
+		}
+
+		err = safely(func() error {
+			return segment.ForEachEntry(func(flag uint8, typedID tsdb.SeriesIDTyped, offset int64, key []byte) error {
+				id := typedID.SeriesID()
+				if sfile.SeriesIDPartitionID(id) != i {
+					return fmt.Errorf("series id %d at offset %d belongs to partition %d", id.RawID(), offset, sfile.SeriesIDPartitionID(id))
+				}
+
+				switch flag {
+				case tsdb.SeriesEntryInsertFlag:
+					if id.RawID() <= lastID {
+						return fmt.Errorf("series id %d at offset %d is not after the previous id %d", id.RawID(), offset, lastID)
+					} else if p := sfile.SeriesKeyPartitionID(key); p != i {
+						return fmt.Errorf("series key of id %d at offset %d belongs to partition %d", id.RawID(), offset, p)
+					}
+					lastID = id.RawID()
+					// Copy the key, as the segment is unmapped once read.
+					keys[id] = append([]byte(nil), key...)
+				case tsdb.SeriesEntryTombstoneFlag:
+					delete(keys, id)
+				}
+				return nil
+			})
+		})
+		segment.Close()
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("segment %s: %v", fi.Name(), err))
+		}
+	}
+
+	// Every series must be found by its key and id.
+	p := sfile.Partitions()[i]
+	for id, key := range keys {
+		err := safely(func() error {
+			if got := p.FindIDBySeriesKey(key); got != id {
+				return fmt.Errorf("series id %d is found as %d by its key", id.RawID(), got.RawID())
+			} else if got := p.SeriesKey(id); !bytes.Equal(got, key) {
+				return fmt.Errorf("series id %d does not find its key", id.RawID())
+			}
+			return nil
+		})
+		if err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	return len(keys), problems, nil
+}
+
+// verifyIndex checks that every TSI index file can be read and only refers to series of
+// sfile, that every log file entry matches its checksum, and that every file listed by
+// the manifest of a partition exists.
+func (cmd *Command) verifyIndex(dir string, sfile *tsdb.SeriesFile) error {
+	tw := tabwriter.NewWriter(cmd.Stdout, 16, 8, 0, '\t', 0)
+	defer tw.Flush()
+
+	manifests, err := filesWithExt(dir, tsi1.ManifestFileName)
+	if err != nil {
+		return err
+	}
+	for _, path := range manifests {
+		m, _, err := tsi1.ReadManifestFile(path)
+		if err != nil {
+			cmd.problems++
+			fmt.Fprintf(tw, "%s: could not read manifest: %v\n", path, err)
+			continue
+		}
+		for _, name := range m.Files {
+			if _, err := os.Stat(filepath.Join(filepath.Dir(path), name)); err != nil {
+				cmd.problems++
+				fmt.Fprintf(tw, "%s: listed file %s: %v\n", path, name, err)
+			}
+		}
+	}
+
+	logFiles, err := filesWithExt(dir, tsi1.LogFileExt)
+	if err != nil {
+		return err
+	}
+	for _, path := range logFiles {
+		if err := verifyLogFile(path); err != nil {
+			cmd.problems++
+			fmt.Fprintf(tw, "%s: %v\n", path, err)
+		} else if cmd.Verbose {
+			fmt.Fprintf(tw, "%s: healthy\n", path)
+		}
+	}
+
+	indexFiles, err := filesWithExt(dir, tsi1.IndexFileExt)
+	if err != nil {
+		return err
+	}
+	for _, path := range indexFiles {
+		if err := verifyIndexFile(sfile, path); err != nil {
+			cmd.problems++
+			fmt.Fprintf(tw, "%s: %v\n", path, err)
+		} else if cmd.Verbose {
+			fmt.Fprintf(tw, "%s: healthy\n", path)
+		}
+	}
+
+	fmt.Fprintf(tw, "Verified %d log files and %d index files\n", len(logFiles), len(indexFiles))
+	return nil
+}
+
+// verifyLogFile checks every entry of the TSI log file at path against its checksum.
+func verifyLogFile(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	for buf := data; len(buf) > 0; {
+		var e tsi1.LogEntry
+		if err := e.UnmarshalBinary(buf); err == io.ErrShortBuffer {
+			return fmt.Errorf("partial entry at offset %d", len(data)-len(buf))
+		} else if err != nil {
+			return fmt.Errorf("entry at offset %d: %v", len(data)-len(buf), err)
+		}
+		buf = buf[e.Size:]
+	}
+	return nil
+}
+
+// verifyIndexFile checks that the TSI index file at path can be read, and that every series it
+// refers to is in sfile.
+func verifyIndexFile(sfile *tsdb.SeriesFile, path string) error {
+	return safely(func() error {
+		f := tsi1.NewIndexFile(sfile)
+		f.SetPath(path)
+		if err := f.Open(); err != nil {
+			return err
+		}
+		defer f.Close()
+
+		ids, err := f.SeriesIDSet()
+		if err != nil {
+			return err
+		}
+
+		var missing int
+		ids.ForEach(func(id tsdb.SeriesID) {
+			if len(sfile.SeriesKey(id)) == 0 && !sfile.IsDeleted(id) {
+				missing++
+			}
+		})
+		if missing > 0 {
+			return fmt.Errorf("%d series are not in the series file", missing)
+		}
+		return nil
+	})
+}
+
+// safely calls fn, returning any panic as an error. Corrupt files can cause the
+// readers of the engine to panic.
+func safely(fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	return fn()
+}
+
+// filesWithExt returns the paths of the files below dir whose names end with ext, in lexical order.
+func filesWithExt(dir, ext string) ([]string, error) {
+	var paths []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if os.IsNotExist(err) && path == dir {
+			return filepath.SkipDir
+		} else if err != nil {
+			return err
+		}
+		if !info.IsDir() && strings.HasSuffix(path, ext) {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	return paths, err
+}
+
+// printUsage prints the usage message to STDOUT.
+func (cmd *Command) printUsage() {
+	usage := `Verifies the integrity of the storage engine files of influxd.
+
+The checksum of every block of every TSM file is checked. Every entry of the
+series file must be readable, and belong to its partition. Every TSI index file
+must be readable, and only refer to series of the series file, and every entry
+of the TSI log files must match its checksum.
+
+Usage: influx_inspect verify [flags]
+
+    -engine-path PATH
+            Path to the storage engine files of influxd.
+            Defaults to "$HOME/.influxdbv2/engine".
+    -v
+            Report healthy files as well as corrupt ones.
+`
+
+	fmt.Fprint(cmd.Stdout, usage)
+}
@@ -0,0 +1,123 @@
+package verify_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/platform"
+	"github.com/influxdata/platform/cmd/influx_inspect/verify"
+	"github.com/influxdata/platform/models"
+	"github.com/influxdata/platform/storage"
+	"github.com/influxdata/platform/tsdb"
+	"github.com/influxdata/platform/tsdb/tsm1"
+)
+
+func TestCommand_Run(t *testing.T) {
+	dir, err := ioutil.TempDir("", "influx_inspect_verify_")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// The engine creates the series file and index.
+	engine := storage.NewEngine(dir, storage.NewConfig())
+	if err := engine.Open(); err != nil {
+		t.Fatal(err)
+	}
+	points, err := tsdb.ExplodePoints(platform.ID(1), platform.ID(2), []models.Point{
+		models.MustNewPoint("cpu", models.NewTags(map[string]string{"host": "a"}), map[string]interface{}{"user": 1.5}, time.Unix(0, 20)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.WritePoints(points); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(storage.NewConfig().GetEnginePath(dir), tsm1.DefaultFormatFileName(1, 1)+"."+tsm1.TSMFileExtension)
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := tsm1.NewTSMWriter(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write([]byte("cpu#!~#value"), tsm1.Values{tsm1.NewValue(1, 1.0), tsm1.NewValue(2, 2.0)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteIndex(); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(dir string) (string, error) {
+		var buf bytes.Buffer
+		cmd := verify.NewCommand()
+		cmd.Stdout, cmd.Stderr = &buf, &buf
+		err := cmd.Run("-engine-path", dir)
+		return buf.String(), err
+	}
+
+	t.Run("healthy", func(t *testing.T) {
+		out, err := run(dir)
+		if err != nil {
+			t.Fatalf("unexpected error: %v\n%s", err, out)
+		}
+		for _, exp := range []string{"Verified 1 blocks in 1 TSM files", "Verified 1 series in 8 series file partitions"} {
+			if !strings.Contains(out, exp) {
+				t.Errorf("expected output to contain %q:\n%s", exp, out)
+			}
+		}
+	})
+
+	t.Run("corrupt block", func(t *testing.T) {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The first block follows the 5 byte header and its 4 byte checksum.
+		data[10] ^= 0xff
+		if err := ioutil.WriteFile(path, data, 0666); err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := run(dir)
+		if err == nil || err.Error() != "1 problems found" {
+			t.Fatalf("unexpected error: %v\n%s", err, out)
+		}
+		if !strings.Contains(out, path+": 1/1 blocks are corrupt") {
+			t.Errorf("expected a corrupt block to be reported:\n%s", out)
+		}
+	})
+
+	t.Run("missing series file", func(t *testing.T) {
+		empty := filepath.Join(dir, "empty")
+		if err := os.Mkdir(empty, 0777); err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := run(empty)
+		if err == nil || err.Error() != "1 problems found" {
+			t.Fatalf("unexpected error: %v\n%s", err, out)
+		}
+		if !strings.Contains(out, "could not open series file") {
+			t.Errorf("expected the missing series file to be reported:\n%s", out)
+		}
+
+		// The series file is only read, never created.
+		if _, err := os.Stat(storage.NewConfig().GetSeriesFilePath(empty)); !os.IsNotExist(err) {
+			t.Fatalf("expected no series file to be created, got %v", err)
+		}
+	})
+}
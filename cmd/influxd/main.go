@@ -21,13 +21,18 @@ import (
 	"github.com/influxdata/platform/kit/cli"
 	"github.com/influxdata/platform/kit/prom"
 	"github.com/influxdata/platform/kit/signals"
+	"github.com/influxdata/platform/listener"
 	influxlogger "github.com/influxdata/platform/logger"
 	"github.com/influxdata/platform/nats"
 	"github.com/influxdata/platform/query"
 	_ "github.com/influxdata/platform/query/builtin"
+	fstorage "github.com/influxdata/platform/query/functions/inputs/storage"
+	"github.com/influxdata/platform/queue"
+	"github.com/influxdata/platform/replication"
 	"github.com/influxdata/platform/snowflake"
 	"github.com/influxdata/platform/source"
 	"github.com/influxdata/platform/storage"
+	"github.com/influxdata/platform/storage/reads"
 	"github.com/influxdata/platform/storage/readservice"
 	"github.com/influxdata/platform/task"
 	taskbackend "github.com/influxdata/platform/task/backend"
@@ -73,6 +78,11 @@ type Main struct {
 	natsPath        string
 	developerMode   bool
 	enginePath      string
+	maxWriteSize    int
+	writeQueue      bool
+	writeQueueSize  int
+	replicationPath string
+	maxTaskMetrics  int
 
 	boltClient *bolt.Client
 	engine     *storage.Engine
@@ -178,6 +188,36 @@ func (m *Main) Run(ctx context.Context, args ...string) error {
 				Default: filepath.Join(dir, "engine"),
 				Desc:    "path to persistent engine files",
 			},
+			{
+				DestP:   &m.maxWriteSize,
+				Flag:    "max-write-body-size",
+				Default: 25000000,
+				Desc:    "maximum size in bytes of the body of a write, once decompressed; 0 for no maximum",
+			},
+			{
+				DestP:   &m.writeQueue,
+				Flag:    "write-queue",
+				Default: false,
+				Desc:    "queue writes in NATS and acknowledge them before they are written to the storage engine",
+			},
+			{
+				DestP:   &m.writeQueueSize,
+				Flag:    "write-queue-max-size",
+				Default: queue.DefaultMaxSize,
+				Desc:    "maximum size in bytes of the queued writes, above which NATS discards the oldest whether or not they were written",
+			},
+			{
+				DestP:   &m.replicationPath,
+				Flag:    "replication-path",
+				Default: filepath.Join(dir, "replicationq"),
+				Desc:    "path to the queues of writes waiting to be replicated to remote instances",
+			},
+			{
+				DestP:   &m.maxTaskMetrics,
+				Flag:    "task-metrics-max-tasks",
+				Default: taskbackend.DefaultMaxTaskMetrics,
+				Desc:    "number of tasks that have their own labeled scheduler metrics; 0 for no limit",
+			},
 		},
 	}
 
@@ -240,8 +280,11 @@ func (m *Main) run(ctx context.Context) (err error) {
 		orgLogSvc        platform.OrganizationOperationLogService = m.boltClient
 		onboardingSvc    platform.OnboardingService               = m.boltClient
 		scraperTargetSvc platform.ScraperTargetStoreService       = m.boltClient
+		listenerSvc      platform.ListenerService                 = m.boltClient
+		replicationSvc   platform.ReplicationService              = m.boltClient
 		telegrafSvc      platform.TelegrafConfigStore             = m.boltClient
 		userResourceSvc  platform.UserResourceMappingService      = m.boltClient
+		bucketPurgeSvc   platform.BucketPurgeService              = m.boltClient
 	)
 
 	chronografSvc, err := server.NewServiceV2(ctx, m.boltClient.DB())
@@ -250,12 +293,35 @@ func (m *Main) run(ctx context.Context) (err error) {
 		return err
 	}
 
+	// Every write accepted by the engine into a bucket with replications is queued to be
+	// sent to the remote instances of the replications.
+	replicationManager := replication.NewManager(replicationSvc, m.replicationPath, func(r *platform.Replication) platform.WriteService {
+		return &http.WriteService{Addr: r.RemoteURL, Token: r.RemoteToken}
+	})
+	replicationManager.Logger = m.logger.With(zap.String("service", "replication"))
+	reg.MustRegister(replicationManager.PrometheusCollectors()...)
+	if err := replicationManager.Sync(ctx); err != nil {
+		m.logger.Error("failed to start replications", zap.Error(err))
+		return err
+	}
+	replicationSvc = &replication.Service{ReplicationService: replicationSvc, Manager: replicationManager}
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		replicationManager.Run(ctx)
+		replicationManager.Logger.Info("Stopping")
+	}()
+
 	var storageQueryService query.ProxyQueryService
 	var pointsWriter storage.PointsWriter
+	var schemaReader fstorage.SchemaReader
+	var storageReader fstorage.Reader
+	var storageStore reads.Store
 	{
 		config := storage.NewConfig()
 
-		m.engine = storage.NewEngine(m.enginePath, config, storage.WithRetentionEnforcer(bucketSvc))
+		m.engine = storage.NewEngine(m.enginePath, config, storage.WithRetentionEnforcer(bucketSvc), storage.WithBucketPurger(bucketPurgeSvc), storage.WithBucketFinder(bucketSvc))
 		m.engine.WithLogger(m.logger)
 		reg.MustRegister(m.engine.PrometheusCollectors()...)
 
@@ -264,7 +330,10 @@ func (m *Main) run(ctx context.Context) (err error) {
 			return err
 		}
 
-		pointsWriter = m.engine
+		pointsWriter = replication.NewWriter(m.engine, replicationManager)
+		schemaReader = readservice.NewSchemaReader(m.engine)
+		storageReader = readservice.NewReader(m.engine)
+		storageStore = readservice.NewStore(m.engine)
 
 		service, err := readservice.NewProxyQueryService(
 			m.engine, bucketSvc, orgSvc, m.logger.With(zap.String("service", "storage-reads")))
@@ -288,7 +357,7 @@ func (m *Main) run(ctx context.Context) (err error) {
 		executor := taskexecutor.NewQueryServiceExecutor(m.logger.With(zap.String("service", "task-executor")), queryService, boltStore)
 
 		lw := taskbackend.NewPointLogWriter(pointsWriter)
-		m.scheduler = taskbackend.NewScheduler(boltStore, executor, lw, time.Now().UTC().Unix(), taskbackend.WithTicker(ctx, time.Second), taskbackend.WithLogger(m.logger))
+		m.scheduler = taskbackend.NewScheduler(boltStore, executor, lw, time.Now().UTC().Unix(), taskbackend.WithTicker(ctx, time.Second), taskbackend.WithLogger(m.logger), taskbackend.WithRunStatsWriter(lw), taskbackend.WithMaxTaskMetrics(m.maxTaskMetrics))
 		m.scheduler.Start(ctx)
 		reg.MustRegister(m.scheduler.PrometheusCollectors()...)
 
@@ -299,7 +368,12 @@ func (m *Main) run(ctx context.Context) (err error) {
 	}
 
 	// NATS streaming server
-	m.natsServer = nats.NewServer(nats.Config{FilestoreDir: m.natsPath})
+	m.natsServer = nats.NewServer(nats.Config{
+		FilestoreDir: m.natsPath,
+		Channels: map[string]nats.ChannelLimits{
+			queue.Subject: queue.Limits(int64(m.writeQueueSize)),
+		},
+	})
 	if err := m.natsServer.Open(); err != nil {
 		m.logger.Error("failed to start nats streaming server", zap.Error(err))
 		return err
@@ -334,6 +408,48 @@ func (m *Main) run(ctx context.Context) (err error) {
 		logger.Info("Stopping")
 	}(m.logger)
 
+	// Writes are queued in NATS when enabled, so that spikes and engine stalls are
+	// absorbed rather than rejected.
+	ingestWriter := pointsWriter
+	if m.writeQueue {
+		writePublisher := nats.NewSyncPublisher("write-publisher")
+		if err := writePublisher.Open(); err != nil {
+			m.logger.Error("failed to connect to streaming server", zap.Error(err))
+			return err
+		}
+		writeSubscriber := nats.NewQueueSubscriber("write-subscriber")
+		writeSubscriber.AckWait = queue.AckWait
+		writeSubscriber.MaxInflight = queue.MaxInflight
+		if err := writeSubscriber.Open(); err != nil {
+			m.logger.Error("failed to connect to streaming server", zap.Error(err))
+			return err
+		}
+
+		queueHandler := queue.NewHandler(pointsWriter)
+		queueHandler.Logger = m.logger.With(zap.String("service", "write-queue"))
+		reg.MustRegister(queueHandler.PrometheusCollectors()...)
+		if err := writeSubscriber.Subscribe(queue.Subject, queue.Group, queueHandler); err != nil {
+			m.logger.Error("failed to subscribe to write queue", zap.Error(err))
+			return err
+		}
+
+		queueWriter := queue.NewWriter(writePublisher)
+		reg.MustRegister(queueWriter.PrometheusCollectors()...)
+		ingestWriter = queueWriter
+	}
+
+	listenerManager := listener.NewManager(listenerSvc, bucketSvc, authSvc, ingestWriter)
+	listenerManager.Logger = m.logger.With(zap.String("service", "listener"))
+	reg.MustRegister(listenerManager.PrometheusCollectors()...)
+	listenerSvc = &listener.Service{ListenerService: listenerSvc, Manager: listenerManager}
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		listenerManager.Run(ctx)
+		listenerManager.Logger.Info("Stopping")
+	}()
+
 	m.httpServer = &nethttp.Server{
 		Addr: m.httpBindAddress,
 	}
@@ -342,7 +458,14 @@ func (m *Main) run(ctx context.Context) (err error) {
 		Logger:                          m.logger,
 		NewBucketService:                source.NewBucketService,
 		NewQueryService:                 source.NewQueryService,
-		PointsWriter:                    pointsWriter,
+		PointsWriter:                    ingestWriter,
+		PointsValidator:                 m.engine,
+		MaxWriteBodySize:                int64(m.maxWriteSize),
+		SchemaReader:                    schemaReader,
+		StorageReader:                   storageReader,
+		StorageStore:                    storageStore,
+		BucketStatsService:              m.engine,
+		BucketSchemaService:             m.engine,
 		AuthorizationService:            authSvc,
 		BucketService:                   bucketSvc,
 		SessionService:                  sessionSvc,
@@ -357,9 +480,12 @@ func (m *Main) run(ctx context.Context) (err error) {
 		ViewService:                     viewSvc,
 		SourceService:                   sourceSvc,
 		MacroService:                    macroSvc,
+		ListenerService:                 listenerSvc,
+		ReplicationService:              replicationSvc,
 		BasicAuthService:                basicAuthSvc,
 		OnboardingService:               onboardingSvc,
 		ProxyQueryService:               storageQueryService,
+		QueryService:                    queryService,
 		TaskService:                     taskSvc,
 		TelegrafService:                 telegrafSvc,
 		ScraperTargetStoreService:       scraperTargetSvc,
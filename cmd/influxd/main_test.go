@@ -3,6 +3,7 @@ package main_test
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -11,6 +12,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/influxdata/platform"
@@ -77,6 +79,153 @@ func TestMain_Write(t *testing.T) {
 	}
 }
 
+func TestMain_WriteValidate(t *testing.T) {
+	m := RunMainOrFail(t, ctx)
+	m.SetupOrFail(t)
+	defer m.ShutdownOrFail(t, ctx)
+
+	if resp, err := nethttp.DefaultClient.Do(m.MustNewHTTPRequest("POST", fmt.Sprintf("/api/v2/write?org=%s&bucket=%s", m.Org.ID, m.Bucket.ID), `m,k=v f=0i 946684800000000000`)); err != nil {
+		t.Fatal(err)
+	} else if err := resp.Body.Close(); err != nil {
+		t.Fatal(err)
+	} else if resp.StatusCode != nethttp.StatusNoContent {
+		t.Fatalf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	// The float f conflicts with the integer f written, and only the series of g is new.
+	resp, err := nethttp.DefaultClient.Do(m.MustNewHTTPRequest("POST", fmt.Sprintf("/api/v2/write/validate?org=%s&bucket=%s", m.Org.ID, m.Bucket.ID), "m,k=v f=1i,g=2 946684800000000000\nm,k=v f=1.5 946684800000000000"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	} else if resp.StatusCode != nethttp.StatusOK {
+		t.Fatalf("unexpected status code: %d: %s", resp.StatusCode, body)
+	}
+
+	var v http.WriteValidation
+	if err := json.Unmarshal(body, &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.Valid || v.RejectedCount != 1 || v.Rejected[0].Line != 2 || v.Rejected[0].Reason != http.RejectedFieldTypeConflict {
+		t.Errorf("unexpected rejected lines: %s", body)
+	}
+	if v.NewSeries != 1 || len(v.NewMeasurements) != 0 || v.TypeConflicts != 1 {
+		t.Errorf("unexpected summary: %s", body)
+	}
+}
+
+func TestMain_WriteQueue(t *testing.T) {
+	m := RunMainOrFail(t, ctx, "--write-queue")
+	m.SetupOrFail(t)
+	defer m.ShutdownOrFail(t, ctx)
+
+	// The write is acknowledged once it is queued.
+	if resp, err := nethttp.DefaultClient.Do(m.MustNewHTTPRequest("POST", fmt.Sprintf("/api/v2/write?org=%s&bucket=%s", m.Org.ID, m.Bucket.ID), `m,k=v f=0i 946684800000000000`)); err != nil {
+		t.Fatal(err)
+	} else if err := resp.Body.Close(); err != nil {
+		t.Fatal(err)
+	} else if resp.StatusCode != nethttp.StatusNoContent {
+		t.Fatalf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	// Query server until the queued write is applied to the engine.
+	qs := `from(bucket:"BUCKET") |> range(start:2000-01-01T00:00:00Z,stop:2000-01-02T00:00:00Z)`
+	exp := `,result,table,_start,_stop,_time,_value,_field,_measurement,k` + "\r\n" +
+		`,result,table,2000-01-01T00:00:00Z,2000-01-02T00:00:00Z,2000-01-01T00:00:00Z,0,f,m,v` + "\r\n\r\n"
+
+	var got string
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(50 * time.Millisecond) {
+		var buf bytes.Buffer
+		req := (http.QueryRequest{Query: qs, Org: m.Org}).WithDefaults()
+		if preq, err := req.ProxyRequest(); err != nil {
+			t.Fatal(err)
+		} else if _, err := m.FluxService().Query(ctx, &buf, preq); err != nil {
+			t.Fatal(err)
+		}
+		if got = buf.String(); got == exp {
+			return
+		}
+	}
+	t.Fatal(cmp.Diff(got, exp))
+}
+
+func TestMain_Replication(t *testing.T) {
+	m := RunMainOrFail(t, ctx)
+	m.SetupOrFail(t)
+	defer m.ShutdownOrFail(t, ctx)
+
+	// The server replicates its bucket to another of its own buckets, as it would to a
+	// remote instance.
+	remote := &platform.Bucket{Name: "REMOTE", OrganizationID: m.Org.ID}
+	if err := (&http.BucketService{Addr: m.URL(), Token: m.Auth.Token}).CreateBucket(ctx, remote); err != nil {
+		t.Fatal(err)
+	}
+	auth := &platform.Authorization{
+		User:        m.User.Name,
+		UserID:      m.User.ID,
+		Permissions: []platform.Permission{platform.WriteBucketPermission(remote.ID)},
+	}
+	if err := (&http.AuthorizationService{Addr: m.URL(), Token: m.Auth.Token}).CreateAuthorization(ctx, auth); err != nil {
+		t.Fatal(err)
+	}
+
+	// Creating a replication requires reading its local bucket.
+	owner := &platform.Authorization{
+		User:   m.User.Name,
+		UserID: m.User.ID,
+		Permissions: []platform.Permission{
+			{Action: platform.CreateAction, Resource: platform.ReplicationResource(m.Org.ID)},
+			platform.ReadBucketPermission(m.Bucket.ID),
+		},
+	}
+	if err := (&http.AuthorizationService{Addr: m.URL(), Token: m.Auth.Token}).CreateAuthorization(ctx, owner); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := (&http.ReplicationService{Addr: m.URL(), Token: owner.Token}).CreateReplication(ctx, &platform.Replication{
+		Name:                 "remote",
+		OrganizationID:       m.Org.ID,
+		LocalBucketID:        m.Bucket.ID,
+		RemoteURL:            m.URL(),
+		RemoteToken:          auth.Token,
+		RemoteOrganizationID: m.Org.ID,
+		RemoteBucketID:       remote.ID,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if resp, err := nethttp.DefaultClient.Do(m.MustNewHTTPRequest("POST", fmt.Sprintf("/api/v2/write?org=%s&bucket=%s", m.Org.ID, m.Bucket.ID), `m,k=v f=0i,g="s" 946684800000000000`)); err != nil {
+		t.Fatal(err)
+	} else if err := resp.Body.Close(); err != nil {
+		t.Fatal(err)
+	} else if resp.StatusCode != nethttp.StatusNoContent {
+		t.Fatalf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	// Query server until the write is replicated to the remote bucket.
+	qs := `from(bucket:"REMOTE") |> range(start:2000-01-01T00:00:00Z,stop:2000-01-02T00:00:00Z) |> filter(fn: (r) => r._field == "f")`
+	exp := `,result,table,_start,_stop,_time,_value,_field,_measurement,k` + "\r\n" +
+		`,result,table,2000-01-01T00:00:00Z,2000-01-02T00:00:00Z,2000-01-01T00:00:00Z,0,f,m,v` + "\r\n\r\n"
+
+	var got string
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(50 * time.Millisecond) {
+		var buf bytes.Buffer
+		req := (http.QueryRequest{Query: qs, Org: m.Org}).WithDefaults()
+		if preq, err := req.ProxyRequest(); err != nil {
+			t.Fatal(err)
+		} else if _, err := m.FluxService().Query(ctx, &buf, preq); err != nil {
+			t.Fatal(err)
+		}
+		if got = buf.String(); got == exp {
+			return
+		}
+	}
+	t.Fatal(cmp.Diff(got, exp))
+}
+
 // Main is a test wrapper for main.Main.
 type Main struct {
 	*main.Main
@@ -130,6 +279,7 @@ func (m *Main) Run(ctx context.Context, args ...string) error {
 	args = append(args, "--bolt-path", filepath.Join(m.Path, "influxd.bolt"))
 	args = append(args, "--engine-path", filepath.Join(m.Path, "engine"))
 	args = append(args, "--nats-path", filepath.Join(m.Path, "nats"))
+	args = append(args, "--replication-path", filepath.Join(m.Path, "replicationq"))
 	args = append(args, "--http-bind-address", "127.0.0.1:0")
 	args = append(args, "--log-level", "debug")
 	return m.Main.Run(ctx, args...)
@@ -1,7 +1,10 @@
 package gather
 
 import (
+	"time"
+
 	"github.com/gogo/protobuf/proto"
+	"github.com/influxdata/platform/models"
 )
 
 // Metrics is the default influx based metrics.
@@ -13,6 +16,11 @@ type Metrics struct {
 	Type      MetricType             `json:"type"`
 }
 
+// Point returns the metrics as a point.
+func (m Metrics) Point() (models.Point, error) {
+	return models.NewPoint(m.Name, models.NewTags(m.Tags), m.Fields, time.Unix(0, m.Timestamp))
+}
+
 // MetricType is prometheus metrics type.
 type MetricType int
 
@@ -0,0 +1,184 @@
+// Package graphite parses the Graphite plaintext protocol into points, using templates
+// to turn the dotted paths of metrics into the measurement, tags and field of points.
+package graphite
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/platform/models"
+)
+
+// DefaultSeparator is the separator that joins the parts of a path that make up the
+// same measurement, tag or field.
+const DefaultSeparator = "."
+
+// DefaultField is the field of points whose template does not name a field.
+const DefaultField = "value"
+
+// Minimum and maximum supported dates for timestamps.
+var (
+	MinDate = time.Date(1901, 12, 13, 0, 0, 0, 0, time.UTC)
+	MaxDate = time.Date(2038, 1, 19, 0, 0, 0, 0, time.UTC)
+)
+
+// Options configure a Parser.
+type Options struct {
+	// Separator joins the parts of a path that make up the same measurement, tag or
+	// field. It is DefaultSeparator if empty.
+	Separator string
+
+	// Templates map paths to points, each of the form
+	//
+	//	[filter] <template> [tag1=value1,tag2=value2]
+	//
+	// A template is a dotted pattern of the parts of a path, each of which is
+	// "measurement", "field", the key of a tag, or empty to skip the part. The last part
+	// may be "measurement*" or "field*" to use the rest of the path. The filter, which may
+	// contain "*" parts, selects the paths that the template applies to; a template
+	// without a filter applies to paths that match no other filter.
+	Templates []string
+
+	// Tags are added to every point, unless the template sets the tag.
+	Tags map[string]string
+}
+
+// Parser parses lines of the Graphite plaintext protocol.
+type Parser struct {
+	matcher *matcher
+	tags    map[string]string
+}
+
+// NewParser returns a parser with opts. It returns an error if a template is invalid.
+func NewParser(opts Options) (*Parser, error) {
+	sep := opts.Separator
+	if sep == "" {
+		sep = DefaultSeparator
+	}
+
+	m := &matcher{root: &node{}, defaultTemplate: &template{parts: []string{"measurement*"}, greedyMeasurement: true, separator: DefaultSeparator}}
+	filters := make(map[string]struct{}, len(opts.Templates))
+	for i, t := range opts.Templates {
+		filter, tmpl, err := parseTemplate(t, sep)
+		if err != nil {
+			return nil, fmt.Errorf("template %d: %v", i, err)
+		}
+		if _, ok := filters[filter]; ok {
+			return nil, fmt.Errorf("template %d: duplicate filter %q", i, filter)
+		}
+		filters[filter] = struct{}{}
+		m.add(filter, tmpl)
+	}
+
+	for k, v := range opts.Tags {
+		if k == "" || v == "" {
+			return nil, fmt.Errorf("invalid tag %q=%q", k, v)
+		}
+	}
+	return &Parser{matcher: m, tags: opts.Tags}, nil
+}
+
+// parseTemplate returns the filter and template of a template of Options.
+func parseTemplate(s, sep string) (string, *template, error) {
+	parts := strings.Fields(s)
+	if len(parts) == 0 {
+		return "", nil, fmt.Errorf("empty template")
+	}
+	if len(parts) > 3 {
+		return "", nil, fmt.Errorf("invalid template %q", s)
+	}
+
+	pattern, filter, tags := parts[0], "", ""
+	switch {
+	case len(parts) == 3:
+		filter, pattern, tags = parts[0], parts[1], parts[2]
+	case len(parts) == 2 && strings.Contains(parts[1], "="):
+		tags = parts[1]
+	case len(parts) == 2:
+		filter, pattern = parts[0], parts[1]
+	}
+
+	if filter != "" {
+		for _, p := range strings.Split(filter, ".") {
+			if p == "" {
+				return "", nil, fmt.Errorf("filter %q contains a blank part", filter)
+			}
+			if strings.Contains(p, "*") && p != "*" {
+				return "", nil, fmt.Errorf("filter %q contains an invalid wildcard", filter)
+			}
+		}
+	}
+
+	var defaultTags map[string]string
+	if tags != "" {
+		defaultTags = make(map[string]string)
+		for _, kv := range strings.Split(tags, ",") {
+			p := strings.Split(kv, "=")
+			if len(p) != 2 || p[0] == "" || p[1] == "" {
+				return "", nil, fmt.Errorf("invalid tag %q", kv)
+			}
+			defaultTags[p[0]] = p[1]
+		}
+	}
+
+	tmpl, err := newTemplate(pattern, defaultTags, sep)
+	if err != nil {
+		return "", nil, err
+	}
+	return filter, tmpl, nil
+}
+
+// Parse returns the point of a line of the form "<path> <value> [timestamp]". The
+// timestamp is in seconds since the epoch; points without a timestamp, or with a
+// timestamp of -1, have the time now.
+func (p *Parser) Parse(line string, now time.Time) (models.Point, error) {
+	fields := strings.Fields(line)
+	if len(fields) != 2 && len(fields) != 3 {
+		return nil, fmt.Errorf("received %q which doesn't have required fields", line)
+	}
+
+	measurement, tags, field, err := p.matcher.match(fields[0]).apply(fields[0])
+	if err != nil {
+		return nil, err
+	}
+	if measurement == "" {
+		measurement = fields[0]
+	}
+	if field == "" {
+		field = DefaultField
+	}
+
+	v, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil {
+		return nil, fmt.Errorf("field %q value: %v", fields[0], err)
+	}
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return nil, fmt.Errorf("field %q value: %v is unsupported", fields[0], v)
+	}
+
+	t := now
+	if len(fields) == 3 {
+		unix, err := strconv.ParseFloat(fields[2], 64)
+		if err != nil {
+			return nil, fmt.Errorf("field %q time: %v", fields[0], err)
+		}
+
+		// -1 is the time now, see https://github.com/graphite-project/carbon/issues/54.
+		if unix != -1 {
+			t = time.Unix(int64(unix), int64((unix-math.Floor(unix))*float64(time.Second)))
+			if t.Before(MinDate) || t.After(MaxDate) {
+				return nil, fmt.Errorf("timestamp out of range")
+			}
+		}
+	}
+
+	for k, v := range p.tags {
+		if _, ok := tags[k]; !ok {
+			tags[k] = v
+		}
+	}
+	return models.NewPoint(measurement, models.NewTags(tags), models.Fields{field: v}, t)
+}
@@ -0,0 +1,234 @@
+package graphite_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/platform/graphite"
+)
+
+func TestParser_Parse(t *testing.T) {
+	now := time.Unix(1541030400, 0)
+
+	tests := []struct {
+		name      string
+		templates []string
+		tags      map[string]string
+		separator string
+		line      string
+		want      string
+		err       string
+	}{
+		{
+			name: "default template",
+			line: "servers.localhost.cpu_load 11 1435077219",
+			want: "servers.localhost.cpu_load value=11 1435077219000000000",
+		},
+		{
+			name:      "tags",
+			templates: []string{"measurement.region.host"},
+			line:      "cpu.us-west.server01 50 1435077219",
+			want:      "cpu,host=server01,region=us-west value=50 1435077219000000000",
+		},
+		{
+			name:      "joined tag",
+			templates: []string{"host.host.host.measurement.region"},
+			line:      "server01.example.org.cpu.us-west 1 1435077219",
+			want:      "cpu,host=server01.example.org,region=us-west value=1 1435077219000000000",
+		},
+		{
+			name:      "greedy measurement",
+			templates: []string{"env.zone.host.measurement*"},
+			line:      "prod.us-west.server01.cpu.load 1 1435077219",
+			want:      "cpu.load,env=prod,host=server01,zone=us-west value=1 1435077219000000000",
+		},
+		{
+			name:      "skipped parts",
+			templates: []string{".zone..measurement*"},
+			line:      "ignore.us-west.ignore-this-too.cpu.load 1 1435077219",
+			want:      "cpu.load,zone=us-west value=1 1435077219000000000",
+		},
+		{
+			name:      "greedy field",
+			templates: []string{"env.zone.host.measurement.measurement.field*"},
+			line:      "prod.us-west.server01.cpu.util.idle.percent 99 1435077219",
+			want:      "cpu.util,env=prod,host=server01,zone=us-west idle.percent=99 1435077219000000000",
+		},
+		{
+			name:      "separator",
+			templates: []string{"env.zone.host.measurement.measurement.field*"},
+			separator: "_",
+			line:      "prod.us-west.server01.cpu.util.idle.percent 99 1435077219",
+			want:      "cpu_util,env=prod,host=server01,zone=us-west idle_percent=99 1435077219000000000",
+		},
+		{
+			name:      "field",
+			templates: []string{"measurement.host.field"},
+			line:      "cpu.server01.idle 99 1435077219",
+			want:      "cpu,host=server01 idle=99 1435077219000000000",
+		},
+		{
+			name:      "exact filter before wildcard",
+			templates: []string{"servers.* .wrong.measurement*", "servers.localhost .host.measurement*"},
+			line:      "servers.localhost.cpu_load 11 1435077219",
+			want:      "cpu_load,host=localhost value=11 1435077219000000000",
+		},
+		{
+			name: "longest filter",
+			templates: []string{
+				"*.* .wrong.measurement*",
+				"servers.* .wrong.measurement*",
+				"servers.localhost .wrong.measurement*",
+				"servers.localhost.cpu .host.resource.measurement*",
+				"*.localhost .wrong.measurement*",
+			},
+			line: "servers.localhost.cpu.cpu_load 11 1435077219",
+			want: "cpu_load,host=localhost,resource=cpu value=11 1435077219000000000",
+		},
+		{
+			name:      "no filter matches",
+			templates: []string{"servers.* .host.measurement*", "measurement*"},
+			line:      "other.localhost.cpu_load 11 1435077219",
+			want:      "other.localhost.cpu_load value=11 1435077219000000000",
+		},
+		{
+			name:      "template tags and tags",
+			templates: []string{"servers.localhost .host.measurement* zone=1c"},
+			tags:      map[string]string{"region": "us-east", "host": "default"},
+			line:      "servers.localhost.cpu_load 11 1435077219",
+			want:      "cpu_load,host=localhost,region=us-east,zone=1c value=11 1435077219000000000",
+		},
+		{
+			name:      "template tags without filter",
+			templates: []string{"measurement.host region=us-east"},
+			line:      "cpu.server01 1 1435077219",
+			want:      "cpu,host=server01,region=us-east value=1 1435077219000000000",
+		},
+		{
+			name: "fractional timestamp",
+			line: "cpu 1 1435077219.5",
+			want: "cpu value=1 1435077219500000000",
+		},
+		{
+			name: "no timestamp",
+			line: "cpu 50.5",
+			want: "cpu value=50.5 1541030400000000000",
+		},
+		{
+			name: "timestamp of now",
+			line: "cpu 50.5 -1",
+			want: "cpu value=50.5 1541030400000000000",
+		},
+		{
+			name: "missing value",
+			line: "1419972457825",
+			err:  `received "1419972457825" which doesn't have required fields`,
+		},
+		{
+			name: "invalid value",
+			line: "cpu 50.554z 1419972457",
+			err:  `field "cpu" value: strconv.ParseFloat: parsing "50.554z": invalid syntax`,
+		},
+		{
+			name: "NaN value",
+			line: "cpu NaN 1419972457",
+			err:  `field "cpu" value: NaN is unsupported`,
+		},
+		{
+			name: "invalid time",
+			line: "cpu 50.554 14199724z57825",
+			err:  `field "cpu" time: strconv.ParseFloat: parsing "14199724z57825": invalid syntax`,
+		},
+		{
+			name: "time out of range",
+			line: "cpu 50.554 1419972457825",
+			err:  "timestamp out of range",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := graphite.NewParser(graphite.Options{Separator: tt.separator, Templates: tt.templates, Tags: tt.tags})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			pt, err := p.Parse(tt.line, now)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("got error %v, want %s", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := pt.String(); got != tt.want {
+				t.Errorf("got point %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewParser_InvalidTemplates(t *testing.T) {
+	tests := []struct {
+		name      string
+		templates []string
+		err       string
+	}{
+		{
+			name:      "no measurement",
+			templates: []string{"a.b.c"},
+			err:       `template 0: no measurement in template "a.b.c"`,
+		},
+		{
+			name:      "greedy measurement and field",
+			templates: []string{"env.measurement*.field*"},
+			err:       `template 0: either 'field*' or 'measurement*' can be used in template "env.measurement*.field*", but not both`,
+		},
+		{
+			name:      "too many parts",
+			templates: []string{"a.* measurement.host zone=a extra"},
+			err:       `template 0: invalid template "a.* measurement.host zone=a extra"`,
+		},
+		{
+			name:      "duplicate filter",
+			templates: []string{"servers.* measurement.host", "servers.* .measurement"},
+			err:       `template 1: duplicate filter "servers.*"`,
+		},
+		{
+			name:      "duplicate default template",
+			templates: []string{"measurement", "measurement.host"},
+			err:       `template 1: duplicate filter ""`,
+		},
+		{
+			name:      "invalid wildcard",
+			templates: []string{"servers.local* measurement"},
+			err:       `template 0: filter "servers.local*" contains an invalid wildcard`,
+		},
+		{
+			name:      "blank filter part",
+			templates: []string{"servers..cpu measurement"},
+			err:       `template 0: filter "servers..cpu" contains a blank part`,
+		},
+		{
+			name:      "invalid tag",
+			templates: []string{"servers.* measurement zone"},
+			err:       `template 0: invalid tag "zone"`,
+		},
+		{
+			name:      "empty tag value",
+			templates: []string{"servers.* measurement zone="},
+			err:       `template 0: invalid tag "zone="`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := graphite.NewParser(graphite.Options{Templates: tt.templates})
+			if err == nil || err.Error() != tt.err {
+				t.Fatalf("got error %v, want %s", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package graphite
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// template maps the parts of a path to the measurement, tags and field of a point.
+type template struct {
+	parts             []string
+	tags              map[string]string
+	greedyMeasurement bool
+	separator         string
+}
+
+func newTemplate(pattern string, tags map[string]string, sep string) (*template, error) {
+	t := &template{parts: strings.Split(pattern, "."), tags: tags, separator: sep}
+
+	var measurement, greedyField bool
+	for _, p := range t.parts {
+		switch p {
+		case "measurement":
+			measurement = true
+		case "measurement*":
+			measurement, t.greedyMeasurement = true, true
+		case "field*":
+			greedyField = true
+		}
+	}
+
+	if !measurement {
+		return nil, fmt.Errorf("no measurement in template %q", pattern)
+	}
+	if greedyField && t.greedyMeasurement {
+		return nil, fmt.Errorf("either 'field*' or 'measurement*' can be used in template %q, but not both", pattern)
+	}
+	return t, nil
+}
+
+// apply returns the measurement, tags and field of a path.
+func (t *template) apply(path string) (string, map[string]string, string, error) {
+	parts := strings.Split(path, ".")
+
+	var (
+		measurement []string
+		field       string
+		tags        = make(map[string][]string)
+	)
+	for k, v := range t.tags {
+		tags[k] = append(tags[k], v)
+	}
+
+	for i, p := range t.parts {
+		if i >= len(parts) {
+			break
+		}
+
+		switch p {
+		case "measurement":
+			measurement = append(measurement, parts[i])
+		case "field":
+			if field != "" {
+				return "", nil, "", fmt.Errorf("'field' can only be used once in each template: %q", path)
+			}
+			field = parts[i]
+		case "field*":
+			field = strings.Join(parts[i:], t.separator)
+		case "measurement*":
+			measurement = append(measurement, parts[i:]...)
+		case "":
+		default:
+			tags[p] = append(tags[p], parts[i])
+		}
+
+		if p == "field*" || p == "measurement*" {
+			break
+		}
+	}
+
+	out := make(map[string]string, len(tags))
+	for k, v := range tags {
+		out[k] = strings.Join(v, t.separator)
+	}
+	return strings.Join(measurement, t.separator), out, field, nil
+}
+
+// matcher selects the template of a path by the filters of the templates.
+type matcher struct {
+	root            *node
+	defaultTemplate *template
+}
+
+func (m *matcher) add(filter string, t *template) {
+	if filter == "" {
+		m.defaultTemplate = t
+		return
+	}
+	m.root.insert(strings.Split(filter, "."), t)
+}
+
+// match returns the template of the most specific filter that matches path, or the
+// default template if none do.
+func (m *matcher) match(path string) *template {
+	if t := m.root.search(strings.Split(path, ".")); t != nil {
+		return t
+	}
+	return m.defaultTemplate
+}
+
+// node is a part of a filter in a tree of filters. The children of a node are sorted
+// by their part, with a "*" part last.
+type node struct {
+	part     string
+	children nodes
+	template *template
+}
+
+func (n *node) insert(parts []string, t *template) {
+	if len(parts) == 0 {
+		n.template = t
+		return
+	}
+
+	for _, c := range n.children {
+		if c.part == parts[0] {
+			c.insert(parts[1:], t)
+			return
+		}
+	}
+
+	c := &node{part: parts[0]}
+	n.children = append(n.children, c)
+	sort.Sort(n.children)
+
+	// A wildcard inherits the template of its parent.
+	if parts[0] == "*" {
+		c.template = n.template
+	}
+	c.insert(parts[1:], t)
+}
+
+func (n *node) search(parts []string) *template {
+	if len(parts) == 0 || len(n.children) == 0 {
+		return n.template
+	}
+
+	// A wildcard child is sorted last, so it is excluded from the binary search.
+	length := len(n.children)
+	wildcard := n.children[length-1].part == "*"
+	if wildcard {
+		length--
+	}
+
+	i := sort.Search(length, func(i int) bool { return n.children[i].part >= parts[0] })
+	if i < length && n.children[i].part == parts[0] {
+		return n.children[i].search(parts[1:])
+	}
+	if wildcard {
+		return n.children[len(n.children)-1].search(parts[1:])
+	}
+	return n.template
+}
+
+// nodes sorts by their parts, with a "*" part after every other part.
+type nodes []*node
+
+func (n nodes) Less(i, j int) bool {
+	if n[i].part == "*" || n[j].part == "*" {
+		return n[j].part == "*" && n[i].part != "*"
+	}
+	return n[i].part < n[j].part
+}
+
+func (n nodes) Swap(i, j int) { n[i], n[j] = n[j], n[i] }
+func (n nodes) Len() int      { return len(n) }
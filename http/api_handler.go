@@ -7,7 +7,9 @@ import (
 	"github.com/influxdata/platform"
 	"github.com/influxdata/platform/chronograf/server"
 	"github.com/influxdata/platform/query"
+	fstorage "github.com/influxdata/platform/query/functions/inputs/storage"
 	"github.com/influxdata/platform/storage"
+	"github.com/influxdata/platform/storage/reads"
 	"go.uber.org/zap"
 )
 
@@ -23,10 +25,14 @@ type APIHandler struct {
 	ViewHandler          *ViewHandler
 	SourceHandler        *SourceHandler
 	MacroHandler         *MacroHandler
+	ListenerHandler      *ListenerHandler
+	ReplicationHandler   *ReplicationHandler
 	TaskHandler          *TaskHandler
 	TelegrafHandler      *TelegrafHandler
 	QueryHandler         *FluxHandler
 	WriteHandler         *WriteHandler
+	ExportHandler        *ExportHandler
+	PromHandler          *PromHandler
 	SetupHandler         *SetupHandler
 	SessionHandler       *SessionHandler
 }
@@ -40,6 +46,13 @@ type APIBackend struct {
 	NewQueryService  func(*platform.Source) (query.ProxyQueryService, error)
 
 	PointsWriter                    storage.PointsWriter
+	PointsValidator                 storage.PointsValidator
+	MaxWriteBodySize                int64
+	SchemaReader                    fstorage.SchemaReader
+	StorageReader                   fstorage.Reader
+	StorageStore                    reads.Store
+	BucketStatsService              platform.BucketStatsService
+	BucketSchemaService             platform.BucketSchemaService
 	AuthorizationService            platform.AuthorizationService
 	BucketService                   platform.BucketService
 	SessionService                  platform.SessionService
@@ -54,9 +67,12 @@ type APIBackend struct {
 	ViewService                     platform.ViewService
 	SourceService                   platform.SourceService
 	MacroService                    platform.MacroService
+	ListenerService                 platform.ListenerService
+	ReplicationService              platform.ReplicationService
 	BasicAuthService                platform.BasicAuthService
 	OnboardingService               platform.OnboardingService
 	ProxyQueryService               query.ProxyQueryService
+	QueryService                    query.QueryService
 	TaskService                     platform.TaskService
 	TelegrafService                 platform.TelegrafConfigStore
 	ScraperTargetStoreService       platform.ScraperTargetStoreService
@@ -74,6 +90,9 @@ func NewAPIHandler(b *APIBackend) *APIHandler {
 	h.BucketHandler = NewBucketHandler(b.UserResourceMappingService)
 	h.BucketHandler.BucketService = b.BucketService
 	h.BucketHandler.BucketOperationLogService = b.BucketOperationLogService
+	h.BucketHandler.SchemaReader = b.SchemaReader
+	h.BucketHandler.BucketStatsService = b.BucketStatsService
+	h.BucketHandler.BucketSchemaService = b.BucketSchemaService
 
 	h.OrgHandler = NewOrgHandler(b.UserResourceMappingService)
 	h.OrgHandler.OrganizationService = b.OrganizationService
@@ -95,6 +114,12 @@ func NewAPIHandler(b *APIBackend) *APIHandler {
 	h.MacroHandler = NewMacroHandler()
 	h.MacroHandler.MacroService = b.MacroService
 
+	h.ListenerHandler = NewListenerHandler()
+	h.ListenerHandler.ListenerService = b.ListenerService
+
+	h.ReplicationHandler = NewReplicationHandler()
+	h.ReplicationHandler.ReplicationService = b.ReplicationService
+
 	h.AuthorizationHandler = NewAuthorizationHandler()
 	h.AuthorizationHandler.AuthorizationService = b.AuthorizationService
 	h.AuthorizationHandler.Logger = b.Logger.With(zap.String("handler", "auth"))
@@ -111,6 +136,7 @@ func NewAPIHandler(b *APIBackend) *APIHandler {
 	h.TaskHandler.TaskService = b.TaskService
 	h.TaskHandler.AuthorizationService = b.AuthorizationService
 	h.TaskHandler.UserResourceMappingService = b.UserResourceMappingService
+	h.TaskHandler.QueryService = b.QueryService
 
 	h.TelegrafHandler = NewTelegrafHandler(
 		b.Logger.With(zap.String("handler", "telegraf")),
@@ -121,8 +147,23 @@ func NewAPIHandler(b *APIBackend) *APIHandler {
 	h.WriteHandler = NewWriteHandler(b.PointsWriter)
 	h.WriteHandler.OrganizationService = b.OrganizationService
 	h.WriteHandler.BucketService = b.BucketService
+	h.WriteHandler.PointsValidator = b.PointsValidator
+	h.WriteHandler.MaxBodySize = b.MaxWriteBodySize
 	h.WriteHandler.Logger = b.Logger.With(zap.String("handler", "write"))
 
+	h.ExportHandler = NewExportHandler()
+	h.ExportHandler.OrganizationService = b.OrganizationService
+	h.ExportHandler.BucketService = b.BucketService
+	h.ExportHandler.Reader = b.StorageReader
+	h.ExportHandler.Logger = b.Logger.With(zap.String("handler", "export"))
+
+	h.PromHandler = NewPromHandler()
+	h.PromHandler.OrganizationService = b.OrganizationService
+	h.PromHandler.BucketService = b.BucketService
+	h.PromHandler.PointsWriter = b.PointsWriter
+	h.PromHandler.Store = b.StorageStore
+	h.PromHandler.Logger = b.Logger.With(zap.String("handler", "prom"))
+
 	h.QueryHandler = NewFluxHandler()
 	h.QueryHandler.OrganizationService = b.OrganizationService
 	h.QueryHandler.Logger = b.Logger.With(zap.String("handler", "query"))
@@ -141,6 +182,7 @@ var apiLinks = map[string]interface{}{
 	"dashboards":     "/api/v2/dashboards",
 	"views":          "/api/v2/views",
 	"write":          "/api/v2/write",
+	"export":         "/api/v2/export",
 	"orgs":           "/api/v2/orgs",
 	"authorizations": "/api/v2/authorizations",
 	"buckets":        "/api/v2/buckets",
@@ -149,6 +191,12 @@ var apiLinks = map[string]interface{}{
 	"tasks":          "/api/v2/tasks",
 	"macros":         "/api/v2/macros",
 	"telegrafs":      "/api/v2/telegrafs",
+	"listeners":      "/api/v2/listeners",
+	"replications":   "/api/v2/replications",
+	"prom": map[string]string{
+		"write": "/api/v2/prom/write",
+		"read":  "/api/v2/prom/read",
+	},
 	"query": map[string]string{
 		"self":        "/api/v2/query",
 		"ast":         "/api/v2/query/ast",
@@ -202,6 +250,16 @@ func (h *APIHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if strings.HasPrefix(r.URL.Path, "/api/v2/export") {
+		h.ExportHandler.ServeHTTP(w, r)
+		return
+	}
+
+	if strings.HasPrefix(r.URL.Path, "/api/v2/prom") {
+		h.PromHandler.ServeHTTP(w, r)
+		return
+	}
+
 	if strings.HasPrefix(r.URL.Path, "/api/v2/query") {
 		h.QueryHandler.ServeHTTP(w, r)
 		return
@@ -262,6 +320,16 @@ func (h *APIHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if strings.HasPrefix(r.URL.Path, "/api/v2/listeners") {
+		h.ListenerHandler.ServeHTTP(w, r)
+		return
+	}
+
+	if strings.HasPrefix(r.URL.Path, "/api/v2/replications") {
+		h.ReplicationHandler.ServeHTTP(w, r)
+		return
+	}
+
 	if strings.HasPrefix(r.URL.Path, "/chronograf/") {
 		h.ChronografHandler.ServeHTTP(w, r)
 		return
@@ -22,6 +22,10 @@ type AuthenticationHandler struct {
 	// hanlder used to register routes does not matter.
 	noAuthRouter *httprouter.Router
 
+	// bearerRouter holds the routes that accept the Bearer scheme as well as the
+	// Token scheme.
+	bearerRouter *httprouter.Router
+
 	Handler http.Handler
 }
 
@@ -31,6 +35,7 @@ func NewAuthenticationHandler() *AuthenticationHandler {
 		Logger:       zap.NewNop(),
 		Handler:      http.DefaultServeMux,
 		noAuthRouter: httprouter.New(),
+		bearerRouter: httprouter.New(),
 	}
 }
 
@@ -40,6 +45,12 @@ func (h *AuthenticationHandler) RegisterNoAuthRoute(method, path string) {
 	h.noAuthRouter.HandlerFunc(method, path, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
 }
 
+// RegisterBearerRoute allows routes to authenticate with a Bearer token, for clients
+// that cannot send any other scheme.
+func (h *AuthenticationHandler) RegisterBearerRoute(method, path string) {
+	h.bearerRouter.HandlerFunc(method, path, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+}
+
 const (
 	tokenAuthScheme   = "token"
 	sessionAuthScheme = "session"
@@ -47,7 +58,11 @@ const (
 
 // ProbeAuthScheme probes the http request for the requests for token or cookie session.
 func ProbeAuthScheme(r *http.Request) (string, error) {
-	_, tokenErr := GetToken(r)
+	return probeAuthScheme(r, GetToken)
+}
+
+func probeAuthScheme(r *http.Request, getToken func(*http.Request) (string, error)) (string, error) {
+	_, tokenErr := getToken(r)
 	_, sessErr := decodeCookieSession(r.Context(), r)
 
 	if tokenErr != nil && sessErr != nil {
@@ -69,7 +84,7 @@ func (h *AuthenticationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request
 	}
 
 	ctx := r.Context()
-	scheme, err := ProbeAuthScheme(r)
+	scheme, err := probeAuthScheme(r, h.getToken)
 	if err != nil {
 		ForbiddenError(ctx, err, w)
 		// THIS IS TEMPORARY, remove after all errors endpoints converted.
@@ -109,7 +124,7 @@ func (h *AuthenticationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request
 }
 
 func (h *AuthenticationHandler) extractAuthorization(ctx context.Context, r *http.Request) (context.Context, error) {
-	t, err := GetToken(r)
+	t, err := h.getToken(r)
 	if err != nil {
 		return ctx, err
 	}
@@ -122,6 +137,15 @@ func (h *AuthenticationHandler) extractAuthorization(ctx context.Context, r *htt
 	return platcontext.SetAuthorizer(ctx, a), nil
 }
 
+// getToken parses the token of r, accepting the Bearer scheme on the routes
+// registered with RegisterBearerRoute.
+func (h *AuthenticationHandler) getToken(r *http.Request) (string, error) {
+	if handler, _, _ := h.bearerRouter.Lookup(r.Method, r.URL.Path); handler != nil {
+		return GetBearerToken(r)
+	}
+	return GetToken(r)
+}
+
 func (h *AuthenticationHandler) extractSession(ctx context.Context, r *http.Request) (context.Context, error) {
 	k, err := decodeCookieSession(ctx, r)
 	if err != nil {
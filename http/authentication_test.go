@@ -291,3 +291,53 @@ func TestAuthenticationHandler_NoAuthRoutes(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthenticationHandler_RegisterBearerRoute(t *testing.T) {
+	tests := []struct {
+		name   string
+		bearer bool
+		code   int
+	}{
+		{
+			name:   "route is a bearer route",
+			bearer: true,
+			code:   http.StatusOK,
+		},
+		{
+			name: "route is not a bearer route",
+			code: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := platformhttp.NewAuthenticationHandler()
+			h.AuthorizationService = &mock.AuthorizationService{
+				FindAuthorizationByTokenFn: func(ctx context.Context, token string) (*platform.Authorization, error) {
+					if token != "abc123" {
+						return nil, fmt.Errorf("authorization not found")
+					}
+					return &platform.Authorization{}, nil
+				},
+			}
+			h.SessionService = mock.NewSessionService()
+			h.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			if tt.bearer {
+				h.RegisterBearerRoute("POST", "/api/v2/prom/write")
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "/api/v2/prom/write", nil)
+			r.Header.Set("Authorization", "Bearer abc123")
+
+			h.ServeHTTP(w, r)
+
+			if got, want := w.Code, tt.code; got != want {
+				t.Errorf("expected status code to be %d got %d", want, got)
+			}
+		})
+	}
+}
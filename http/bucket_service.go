@@ -11,8 +11,13 @@ import (
 	"strings"
 	"time"
 
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/parser"
+	"github.com/influxdata/flux/semantic"
 	"github.com/influxdata/platform"
+	pcontext "github.com/influxdata/platform/context"
 	errors "github.com/influxdata/platform/kit/errors"
+	fstorage "github.com/influxdata/platform/query/functions/inputs/storage"
 	"github.com/julienschmidt/httprouter"
 )
 
@@ -23,6 +28,9 @@ type BucketHandler struct {
 	BucketService              platform.BucketService
 	BucketOperationLogService  platform.BucketOperationLogService
 	UserResourceMappingService platform.UserResourceMappingService
+	SchemaReader               fstorage.SchemaReader
+	BucketStatsService         platform.BucketStatsService
+	BucketSchemaService        platform.BucketSchemaService
 }
 
 const (
@@ -33,6 +41,13 @@ const (
 	bucketsIDMembersIDPath = "/api/v2/buckets/:id/members/:userID"
 	bucketsIDOwnersPath    = "/api/v2/buckets/:id/owners"
 	bucketsIDOwnersIDPath  = "/api/v2/buckets/:id/owners/:userID"
+	bucketsIDStatsPath     = "/api/v2/buckets/:id/stats"
+	bucketsIDSchemaPath    = "/api/v2/buckets/:id/schema"
+
+	bucketsIDSchemaMeasurementsPath = "/api/v2/buckets/:id/schema/measurements"
+	bucketsIDSchemaFieldsPath       = "/api/v2/buckets/:id/schema/fields"
+	bucketsIDSchemaTagKeysPath      = "/api/v2/buckets/:id/schema/tagKeys"
+	bucketsIDSchemaTagValuesPath    = "/api/v2/buckets/:id/schema/tagValues"
 )
 
 // NewBucketHandler returns a new instance of BucketHandler.
@@ -48,6 +63,13 @@ func NewBucketHandler(mappingService platform.UserResourceMappingService) *Bucke
 	h.HandlerFunc("GET", bucketsIDLogPath, h.handleGetBucketLog)
 	h.HandlerFunc("PATCH", bucketsIDPath, h.handlePatchBucket)
 	h.HandlerFunc("DELETE", bucketsIDPath, h.handleDeleteBucket)
+	h.HandlerFunc("GET", bucketsIDStatsPath, h.handleGetBucketStats)
+	h.HandlerFunc("GET", bucketsIDSchemaPath, h.handleGetBucketSchema)
+
+	h.HandlerFunc("GET", bucketsIDSchemaMeasurementsPath, h.handleGetBucketMeasurements)
+	h.HandlerFunc("GET", bucketsIDSchemaFieldsPath, h.handleGetBucketFields)
+	h.HandlerFunc("GET", bucketsIDSchemaTagKeysPath, h.handleGetBucketTagKeys)
+	h.HandlerFunc("GET", bucketsIDSchemaTagValuesPath, h.handleGetBucketTagValues)
 
 	h.HandlerFunc("POST", bucketsIDMembersPath, newPostMemberHandler(h.UserResourceMappingService, platform.BucketResourceType, platform.Member))
 	h.HandlerFunc("GET", bucketsIDMembersPath, newGetMembersHandler(h.UserResourceMappingService, platform.Member))
@@ -62,12 +84,15 @@ func NewBucketHandler(mappingService platform.UserResourceMappingService) *Bucke
 
 // bucket is used for serialization/deserialization with duration string syntax.
 type bucket struct {
-	ID                  platform.ID     `json:"id,omitempty"`
-	OrganizationID      platform.ID     `json:"organizationID,omitempty"`
-	Organization        string          `json:"organization,omitempty"`
-	Name                string          `json:"name"`
-	RetentionPolicyName string          `json:"rp,omitempty"` // This to support v1 sources
-	RetentionRules      []retentionRule `json:"retentionRules"`
+	ID                  platform.ID                  `json:"id,omitempty"`
+	OrganizationID      platform.ID                  `json:"organizationID,omitempty"`
+	Organization        string                       `json:"organization,omitempty"`
+	Name                string                       `json:"name"`
+	RetentionPolicyName string                       `json:"rp,omitempty"` // This to support v1 sources
+	RetentionRules      []retentionRule              `json:"retentionRules"`
+	PartitionSeconds    int64                        `json:"partitionSeconds,omitempty"`
+	SchemaType          platform.SchemaType          `json:"schemaType,omitempty"`
+	Schema              []platform.MeasurementSchema `json:"schema,omitempty"`
 }
 
 // retentionRule is the retention rule action for a bucket.
@@ -91,6 +116,15 @@ func (b *bucket) toPlatform() (*platform.Bucket, error) {
 		}
 	}
 
+	partition, err := partitionDuration(b.PartitionSeconds)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateBucketSchema(b.SchemaType, b.Schema); err != nil {
+		return nil, err
+	}
+
 	return &platform.Bucket{
 		ID:                  b.ID,
 		OrganizationID:      b.OrganizationID,
@@ -98,9 +132,39 @@ func (b *bucket) toPlatform() (*platform.Bucket, error) {
 		Name:                b.Name,
 		RetentionPolicyName: b.RetentionPolicyName,
 		RetentionPeriod:     d,
+		PartitionDuration:   partition,
+		SchemaType:          b.SchemaType,
+		Schema:              b.Schema,
 	}, nil
 }
 
+// minPartitionDuration is the shortest partition duration of a bucket. It bounds the
+// number of TSM files written for the data of the bucket.
+const minPartitionDuration = time.Hour
+
+// partitionDuration returns the partition duration of a bucket given in seconds.
+func partitionDuration(seconds int64) (time.Duration, error) {
+	d := time.Duration(seconds) * time.Second
+	if d != 0 && d < minPartitionDuration {
+		return 0, errors.InvalidDataf("partition seconds must be 0, or at least %d", int64(minPartitionDuration/time.Second))
+	}
+	return d, nil
+}
+
+// validateBucketSchema returns an error if a bucket has an unknown schema type or an
+// invalid declared schema.
+func validateBucketSchema(typ platform.SchemaType, schema []platform.MeasurementSchema) error {
+	switch typ {
+	case "", platform.SchemaTypeImplicit, platform.SchemaTypeExplicit:
+	default:
+		return errors.InvalidDataf("unknown schema type %q", typ)
+	}
+	if err := platform.ValidateSchema(schema); err != nil {
+		return errors.InvalidDataf("%v", err)
+	}
+	return nil
+}
+
 func newBucket(pb *platform.Bucket) *bucket {
 	if pb == nil {
 		return nil
@@ -122,13 +186,19 @@ func newBucket(pb *platform.Bucket) *bucket {
 		Name:                pb.Name,
 		RetentionPolicyName: pb.RetentionPolicyName,
 		RetentionRules:      rules,
+		PartitionSeconds:    int64(pb.PartitionDuration / time.Second),
+		SchemaType:          pb.SchemaType,
+		Schema:              pb.Schema,
 	}
 }
 
 // bucketUpdate is used for serialization/deserialization with retention rules.
 type bucketUpdate struct {
-	Name           *string         `json:"name,omitempty"`
-	RetentionRules []retentionRule `json:"retentionRules,omitempty"`
+	Name             *string                      `json:"name,omitempty"`
+	RetentionRules   []retentionRule              `json:"retentionRules,omitempty"`
+	PartitionSeconds *int64                       `json:"partitionSeconds,omitempty"`
+	SchemaType       *platform.SchemaType         `json:"schemaType,omitempty"`
+	Schema           []platform.MeasurementSchema `json:"schema,omitempty"`
 }
 
 func (b *bucketUpdate) toPlatform() (*platform.BucketUpdate, error) {
@@ -145,9 +215,29 @@ func (b *bucketUpdate) toPlatform() (*platform.BucketUpdate, error) {
 		}
 	}
 
+	var partition *time.Duration
+	if b.PartitionSeconds != nil {
+		p, err := partitionDuration(*b.PartitionSeconds)
+		if err != nil {
+			return nil, err
+		}
+		partition = &p
+	}
+
+	var typ platform.SchemaType
+	if b.SchemaType != nil {
+		typ = *b.SchemaType
+	}
+	if err := validateBucketSchema(typ, b.Schema); err != nil {
+		return nil, err
+	}
+
 	return &platform.BucketUpdate{
-		Name:            b.Name,
-		RetentionPeriod: &d,
+		Name:              b.Name,
+		RetentionPeriod:   &d,
+		PartitionDuration: partition,
+		SchemaType:        b.SchemaType,
+		Schema:            b.Schema,
 	}, nil
 }
 
@@ -159,6 +249,13 @@ func newBucketUpdate(pb *platform.BucketUpdate) *bucketUpdate {
 	up := &bucketUpdate{
 		Name:           pb.Name,
 		RetentionRules: []retentionRule{},
+		SchemaType:     pb.SchemaType,
+		Schema:         pb.Schema,
+	}
+
+	if pb.PartitionDuration != nil {
+		s := int64(*pb.PartitionDuration / time.Second)
+		up.PartitionSeconds = &s
 	}
 
 	if pb.RetentionPeriod != nil {
@@ -282,6 +379,144 @@ func (h *BucketHandler) handleGetBucket(w http.ResponseWriter, r *http.Request)
 	}
 }
 
+// handleGetBucketStats is the HTTP handler for the GET /api/v2/buckets/:id/stats route.
+func (h *BucketHandler) handleGetBucketStats(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodeGetBucketRequest(ctx, r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	b, err := h.BucketService.FindBucketByID(ctx, req.BucketID)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := authorizeReadBucket(ctx, b); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	stats, err := h.BucketStatsService.FindBucketStats(ctx, b.OrganizationID, b.ID)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newBucketStatsResponse(stats)); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+}
+
+// bucketStatsResponse encodes the times of platform.BucketStats as strings, which are
+// omitted when the bucket holds no points.
+type bucketStatsResponse struct {
+	Links map[string]string `json:"links"`
+	platform.BucketStats
+	OldestTime string `json:"oldestTime,omitempty"`
+	NewestTime string `json:"newestTime,omitempty"`
+}
+
+func newBucketStatsResponse(s *platform.BucketStats) *bucketStatsResponse {
+	res := &bucketStatsResponse{
+		Links: map[string]string{
+			"self":   fmt.Sprintf("/api/v2/buckets/%s/stats", s.BucketID),
+			"bucket": fmt.Sprintf("/api/v2/buckets/%s", s.BucketID),
+		},
+		BucketStats: *s,
+	}
+	if res.Measurements == nil {
+		res.Measurements = []platform.MeasurementCardinality{}
+	}
+	if !s.OldestTime.IsZero() {
+		res.OldestTime = s.OldestTime.UTC().Format(time.RFC3339Nano)
+		res.NewestTime = s.NewestTime.UTC().Format(time.RFC3339Nano)
+	}
+	return res
+}
+
+// toPlatform decodes the times of r into its BucketStats.
+func (r *bucketStatsResponse) toPlatform() (*platform.BucketStats, error) {
+	s := r.BucketStats
+	for _, t := range []struct {
+		s string
+		t *time.Time
+	}{
+		{r.OldestTime, &s.OldestTime},
+		{r.NewestTime, &s.NewestTime},
+	} {
+		if t.s == "" {
+			continue
+		}
+		var err error
+		if *t.t, err = time.Parse(time.RFC3339Nano, t.s); err != nil {
+			return nil, err
+		}
+	}
+	return &s, nil
+}
+
+// handleGetBucketSchema is the HTTP handler for the GET /api/v2/buckets/:id/schema route.
+func (h *BucketHandler) handleGetBucketSchema(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodeGetBucketRequest(ctx, r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	b, err := h.BucketService.FindBucketByID(ctx, req.BucketID)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := authorizeReadBucket(ctx, b); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	schema, err := h.BucketSchemaService.FindBucketSchema(ctx, b.OrganizationID, b.ID)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+	schema.SchemaType = b.SchemaType
+	if schema.SchemaType == "" {
+		schema.SchemaType = platform.SchemaTypeImplicit
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newBucketSchemaResponse(schema)); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+}
+
+// bucketSchemaResponse is the type of each field of the data of a bucket.
+type bucketSchemaResponse struct {
+	Links map[string]string `json:"links"`
+	platform.BucketSchema
+}
+
+func newBucketSchemaResponse(s *platform.BucketSchema) *bucketSchemaResponse {
+	res := &bucketSchemaResponse{
+		Links: map[string]string{
+			"self":   fmt.Sprintf("/api/v2/buckets/%s/schema", s.BucketID),
+			"bucket": fmt.Sprintf("/api/v2/buckets/%s", s.BucketID),
+		},
+		BucketSchema: *s,
+	}
+	if res.Measurements == nil {
+		res.Measurements = []platform.MeasurementSchema{}
+	}
+	return res
+}
+
 type getBucketRequest struct {
 	BucketID platform.ID
 }
@@ -507,6 +742,70 @@ func (s *BucketService) FindBucketByID(ctx context.Context, id platform.ID) (*pl
 	return br.toPlatform()
 }
 
+// FindBucketStats returns statistics of the data of a bucket. The organization of the
+// bucket is found by the server, so orgID is unused.
+func (s *BucketService) FindBucketStats(ctx context.Context, orgID, bucketID platform.ID) (*platform.BucketStats, error) {
+	u, err := newURL(s.Addr, bucketIDStatsPath(bucketID))
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	SetToken(s.Token, req)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var sr bucketStatsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&sr); err != nil {
+		return nil, err
+	}
+	return sr.toPlatform()
+}
+
+// FindBucketSchema returns the type of each field of the data of a bucket. The
+// organization of the bucket is found by the server, so orgID is unused.
+func (s *BucketService) FindBucketSchema(ctx context.Context, orgID, bucketID platform.ID) (*platform.BucketSchema, error) {
+	u, err := newURL(s.Addr, bucketIDSchemaPath(bucketID))
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	SetToken(s.Token, req)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var sr bucketSchemaResponse
+	if err := json.NewDecoder(resp.Body).Decode(&sr); err != nil {
+		return nil, err
+	}
+	return &sr.BucketSchema, nil
+}
+
 // FindBucket returns the first bucket that matches filter.
 func (s *BucketService) FindBucket(ctx context.Context, filter platform.BucketFilter) (*platform.Bucket, error) {
 	bs, n, err := s.FindBuckets(ctx, filter)
@@ -691,6 +990,14 @@ func bucketIDPath(id platform.ID) string {
 	return path.Join(bucketPath, id.String())
 }
 
+func bucketIDStatsPath(id platform.ID) string {
+	return path.Join(bucketPath, id.String(), "stats")
+}
+
+func bucketIDSchemaPath(id platform.ID) string {
+	return path.Join(bucketPath, id.String(), "schema")
+}
+
 // hanldeGetBucketLog retrieves a bucket log by the buckets ID.
 func (h *BucketHandler) handleGetBucketLog(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -768,3 +1075,184 @@ func newBucketLogResponse(id platform.ID, es []*platform.OperationLogEntry) *ope
 		Log: log,
 	}
 }
+
+// handleGetBucketMeasurements is the HTTP handler for the GET /api/v2/buckets/:id/schema/measurements route.
+func (h *BucketHandler) handleGetBucketMeasurements(w http.ResponseWriter, r *http.Request) {
+	h.getBucketTagValues(w, r, "_measurement")
+}
+
+// handleGetBucketFields is the HTTP handler for the GET /api/v2/buckets/:id/schema/fields route.
+func (h *BucketHandler) handleGetBucketFields(w http.ResponseWriter, r *http.Request) {
+	h.getBucketTagValues(w, r, "_field")
+}
+
+// handleGetBucketTagValues is the HTTP handler for the GET /api/v2/buckets/:id/schema/tagValues route.
+func (h *BucketHandler) handleGetBucketTagValues(w http.ResponseWriter, r *http.Request) {
+	tag := r.URL.Query().Get("tag")
+	if tag == "" {
+		EncodeError(r.Context(), errors.InvalidDataf("tag is required"), w)
+		return
+	}
+	h.getBucketTagValues(w, r, tag)
+}
+
+func (h *BucketHandler) getBucketTagValues(w http.ResponseWriter, r *http.Request, tag string) {
+	ctx := r.Context()
+
+	req, err := decodeGetBucketSchemaRequest(ctx, r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	spec, err := h.tagKeysSpec(ctx, req)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	values, err := h.SchemaReader.ReadTagValues(ctx, fstorage.TagValuesSpec{TagKeysSpec: spec, TagKey: tag}, req.Start, req.Stop)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newBucketSchemaValuesResponse(values)); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+}
+
+// handleGetBucketTagKeys is the HTTP handler for the GET /api/v2/buckets/:id/schema/tagKeys route.
+func (h *BucketHandler) handleGetBucketTagKeys(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodeGetBucketSchemaRequest(ctx, r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	spec, err := h.tagKeysSpec(ctx, req)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	keys, err := h.SchemaReader.ReadTagKeys(ctx, spec, req.Start, req.Stop)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newBucketSchemaValuesResponse(keys)); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+}
+
+// tagKeysSpec returns the spec selecting the series of the bucket of req.
+func (h *BucketHandler) tagKeysSpec(ctx context.Context, req *getBucketSchemaRequest) (fstorage.TagKeysSpec, error) {
+	b, err := h.BucketService.FindBucketByID(ctx, req.BucketID)
+	if err != nil {
+		return fstorage.TagKeysSpec{}, err
+	}
+	if err := authorizeReadBucket(ctx, b); err != nil {
+		return fstorage.TagKeysSpec{}, err
+	}
+	return fstorage.TagKeysSpec{
+		OrganizationID: b.OrganizationID,
+		BucketID:       b.ID,
+		Predicate:      req.Predicate,
+	}, nil
+}
+
+// authorizeReadBucket returns an error if the authorizer of ctx may not read the bucket b.
+func authorizeReadBucket(ctx context.Context, b *platform.Bucket) error {
+	a, err := pcontext.GetAuthorizer(ctx)
+	if err != nil {
+		return err
+	}
+	if !a.Allowed(platform.ReadBucketPermission(b.ID)) {
+		return errors.Forbiddenf("insufficient permissions to read bucket %s", b.ID)
+	}
+	return nil
+}
+
+type getBucketSchemaRequest struct {
+	BucketID platform.ID
+	// Start and Stop are zero when the time range is unbounded.
+	Start     execute.Time
+	Stop      execute.Time
+	Predicate *semantic.FunctionExpression
+}
+
+func decodeGetBucketSchemaRequest(ctx context.Context, r *http.Request) (*getBucketSchemaRequest, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	id := params.ByName("id")
+	if id == "" {
+		return nil, errors.InvalidDataf("url missing id")
+	}
+
+	req := &getBucketSchemaRequest{}
+	if err := req.BucketID.DecodeFromString(id); err != nil {
+		return nil, err
+	}
+
+	qp := r.URL.Query()
+	if v := qp.Get("start"); v != "" {
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return nil, errors.InvalidDataf("invalid start: %v", err)
+		}
+		req.Start = execute.Time(t.UnixNano())
+	}
+	if v := qp.Get("stop"); v != "" {
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return nil, errors.InvalidDataf("invalid stop: %v", err)
+		}
+		req.Stop = execute.Time(t.UnixNano())
+	}
+	if v := qp.Get("predicate"); v != "" {
+		fn, err := parsePredicate(v)
+		if err != nil {
+			return nil, err
+		}
+		req.Predicate = fn
+	}
+
+	return req, nil
+}
+
+// parsePredicate parses a Flux predicate function, such as (r) => r.host == "a".
+func parsePredicate(s string) (*semantic.FunctionExpression, error) {
+	astProg, err := parser.NewAST(s)
+	if err != nil {
+		return nil, errors.InvalidDataf("invalid predicate: %v", err)
+	}
+	prog, err := semantic.New(astProg)
+	if err != nil {
+		return nil, errors.InvalidDataf("invalid predicate: %v", err)
+	}
+
+	if len(prog.Body) == 1 {
+		if stmt, ok := prog.Body[0].(*semantic.ExpressionStatement); ok {
+			if fn, ok := stmt.Expression.(*semantic.FunctionExpression); ok {
+				return fn, nil
+			}
+		}
+	}
+	return nil, errors.InvalidDataf("predicate must be a single function")
+}
+
+type bucketSchemaValuesResponse struct {
+	Values []string `json:"values"`
+}
+
+func newBucketSchemaValuesResponse(values []string) *bucketSchemaValuesResponse {
+	if values == nil {
+		values = []string{}
+	}
+	return &bucketSchemaValuesResponse{Values: values}
+}
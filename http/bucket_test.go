@@ -8,12 +8,18 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/influxdata/flux/execute"
 	"github.com/influxdata/platform"
+	pcontext "github.com/influxdata/platform/context"
 	"github.com/influxdata/platform/inmem"
 	"github.com/influxdata/platform/mock"
+	fstorage "github.com/influxdata/platform/query/functions/inputs/storage"
 	platformtesting "github.com/influxdata/platform/testing"
 	"github.com/julienschmidt/httprouter"
 )
@@ -327,6 +333,25 @@ func TestService_handlePostBucket(t *testing.T) {
 `,
 			},
 		},
+		{
+			name: "create a bucket with an invalid schema",
+			fields: fields{
+				&mock.BucketService{},
+			},
+			args: args{
+				bucket: &platform.Bucket{
+					Name:           "hello",
+					OrganizationID: platformtesting.MustIDBase16("6f626f7274697320"),
+					SchemaType:     platform.SchemaTypeExplicit,
+					Schema: []platform.MeasurementSchema{
+						{Name: "cpu", Fields: []platform.FieldSchema{{Name: "usage", Type: "decimal"}}},
+					},
+				},
+			},
+			wants: wants{
+				statusCode: http.StatusUnprocessableEntity,
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -738,6 +763,434 @@ func TestService_handlePatchBucket(t *testing.T) {
 	}
 }
 
+func TestService_handleGetBucketTagValues(t *testing.T) {
+	type args struct {
+		path        string
+		rawQuery    string
+		permissions []platform.Permission
+	}
+	type wants struct {
+		statusCode int
+		body       string
+		spec       fstorage.TagValuesSpec
+		start      execute.Time
+		stop       execute.Time
+	}
+
+	bucketID := platformtesting.MustIDBase16("020f755c3c082000")
+	orgID := platformtesting.MustIDBase16("020f755c3c082001")
+
+	tests := []struct {
+		name  string
+		args  args
+		wants wants
+	}{
+		{
+			name: "get measurements",
+			args: args{
+				permissions: []platform.Permission{platform.ReadBucketPermission(bucketID)},
+				path:        "measurements",
+			},
+			wants: wants{
+				statusCode: http.StatusOK,
+				body:       `{"values": ["a", "b"]}`,
+				spec: fstorage.TagValuesSpec{
+					TagKeysSpec: fstorage.TagKeysSpec{OrganizationID: orgID, BucketID: bucketID},
+					TagKey:      "_measurement",
+				},
+			},
+		},
+		{
+			name: "get tag values in time range with predicate",
+			args: args{
+				permissions: []platform.Permission{platform.ReadBucketPermission(bucketID)},
+				path:        "tagValues",
+				rawQuery:    "tag=host&start=1970-01-01T00:00:01Z&stop=1970-01-01T00:00:02Z&predicate=" + url.QueryEscape(`(r) => r._measurement == "cpu"`),
+			},
+			wants: wants{
+				statusCode: http.StatusOK,
+				body:       `{"values": ["a", "b"]}`,
+				spec: fstorage.TagValuesSpec{
+					TagKeysSpec: fstorage.TagKeysSpec{OrganizationID: orgID, BucketID: bucketID},
+					TagKey:      "host",
+				},
+				start: execute.Time(time.Second),
+				stop:  execute.Time(2 * time.Second),
+			},
+		},
+		{
+			name: "get tag keys without permission to read the bucket",
+			args: args{
+				path: "tagKeys",
+				permissions: []platform.Permission{
+					platform.WriteBucketPermission(bucketID),
+					platform.ReadBucketPermission(platformtesting.MustIDBase16("020f755c3c082002")),
+				},
+			},
+			wants: wants{
+				statusCode: http.StatusForbidden,
+			},
+		},
+		{
+			name: "get tag values without tag",
+			args: args{
+				permissions: []platform.Permission{platform.ReadBucketPermission(bucketID)},
+				path:        "tagValues",
+			},
+			wants: wants{
+				statusCode: http.StatusUnprocessableEntity,
+			},
+		},
+		{
+			name: "get tag values with invalid predicate",
+			args: args{
+				permissions: []platform.Permission{platform.ReadBucketPermission(bucketID)},
+				path:        "tagValues",
+				rawQuery:    "tag=host&predicate=" + url.QueryEscape(`r.host == "a"`),
+			},
+			wants: wants{
+				statusCode: http.StatusUnprocessableEntity,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schemaReader := &fakeSchemaReader{values: []string{"a", "b"}}
+
+			h := NewBucketHandler(mock.NewUserResourceMappingService())
+			h.SchemaReader = schemaReader
+			h.BucketService = &mock.BucketService{
+				FindBucketByIDFn: func(ctx context.Context, id platform.ID) (*platform.Bucket, error) {
+					return &platform.Bucket{ID: id, OrganizationID: orgID}, nil
+				},
+			}
+
+			r := httptest.NewRequest("GET", "http://any.url/api/v2/buckets/020f755c3c082000/schema/"+tt.args.path+"?"+tt.args.rawQuery, nil)
+			r = withBucketPermissions(r, tt.args.permissions...)
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+
+			if res.StatusCode != tt.wants.statusCode {
+				t.Fatalf("%q. handleGetBucketTagValues() = %v, want %v: %s", tt.name, res.StatusCode, tt.wants.statusCode, body)
+			}
+			if tt.wants.body == "" {
+				return
+			}
+			if eq, _ := jsonEqual(string(body), tt.wants.body); !eq {
+				t.Errorf("%q. handleGetBucketTagValues() = \n***%v***\n,\nwant\n***%v***", tt.name, string(body), tt.wants.body)
+			}
+
+			spec := schemaReader.spec
+			if (spec.Predicate != nil) != strings.Contains(tt.args.rawQuery, "predicate") {
+				t.Errorf("%q. unexpected predicate %v", tt.name, spec.Predicate)
+			}
+			spec.Predicate = nil
+			if !reflect.DeepEqual(spec, tt.wants.spec) {
+				t.Errorf("%q. got spec %+v, want %+v", tt.name, spec, tt.wants.spec)
+			}
+			if schemaReader.start != tt.wants.start || schemaReader.stop != tt.wants.stop {
+				t.Errorf("%q. got time range [%v, %v], want [%v, %v]", tt.name, schemaReader.start, schemaReader.stop, tt.wants.start, tt.wants.stop)
+			}
+		})
+	}
+}
+
+func withBucketPermissions(r *http.Request, perms ...platform.Permission) *http.Request {
+	return r.WithContext(pcontext.SetAuthorizer(r.Context(), &platform.Authorization{
+		Status:      platform.Active,
+		Permissions: perms,
+	}))
+}
+
+type fakeSchemaReader struct {
+	values      []string
+	spec        fstorage.TagValuesSpec
+	start, stop execute.Time
+}
+
+func (r *fakeSchemaReader) ReadTagKeys(ctx context.Context, spec fstorage.TagKeysSpec, start, stop execute.Time) ([]string, error) {
+	r.spec, r.start, r.stop = fstorage.TagValuesSpec{TagKeysSpec: spec}, start, stop
+	return r.values, nil
+}
+
+func (r *fakeSchemaReader) ReadTagValues(ctx context.Context, spec fstorage.TagValuesSpec, start, stop execute.Time) ([]string, error) {
+	r.spec, r.start, r.stop = spec, start, stop
+	return r.values, nil
+}
+
+func TestService_handleGetBucketStats(t *testing.T) {
+	bucketID := platformtesting.MustIDBase16("020f755c3c082000")
+	orgID := platformtesting.MustIDBase16("020f755c3c082001")
+
+	tests := []struct {
+		name  string
+		stats platform.BucketStats
+		body  string
+	}{
+		{
+			name: "get stats of bucket with data",
+			stats: platform.BucketStats{
+				BucketID:     bucketID,
+				Size:         1024,
+				SeriesN:      2,
+				FieldSeriesN: 3,
+				PointN:       10,
+				OldestTime:   time.Date(2018, 11, 1, 0, 0, 0, 0, time.UTC),
+				NewestTime:   time.Date(2018, 11, 2, 0, 0, 0, 0, time.UTC),
+				Measurements: []platform.MeasurementCardinality{
+					{Name: "cpu", SeriesN: 2, FieldSeriesN: 3},
+				},
+			},
+			body: `
+{
+  "links": {
+    "self": "/api/v2/buckets/020f755c3c082000/stats",
+    "bucket": "/api/v2/buckets/020f755c3c082000"
+  },
+  "bucketID": "020f755c3c082000",
+  "size": 1024,
+  "seriesCount": 2,
+  "fieldSeriesCount": 3,
+  "pointCount": 10,
+  "oldestTime": "2018-11-01T00:00:00Z",
+  "newestTime": "2018-11-02T00:00:00Z",
+  "measurements": [
+    {
+      "name": "cpu",
+      "seriesCount": 2,
+      "fieldSeriesCount": 3
+    }
+  ]
+}
+`,
+		},
+		{
+			name:  "get stats of empty bucket",
+			stats: platform.BucketStats{BucketID: bucketID},
+			body: `
+{
+  "links": {
+    "self": "/api/v2/buckets/020f755c3c082000/stats",
+    "bucket": "/api/v2/buckets/020f755c3c082000"
+  },
+  "bucketID": "020f755c3c082000",
+  "size": 0,
+  "seriesCount": 0,
+  "fieldSeriesCount": 0,
+  "pointCount": 0,
+  "measurements": []
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewBucketHandler(mock.NewUserResourceMappingService())
+			h.BucketService = &mock.BucketService{
+				FindBucketByIDFn: func(ctx context.Context, id platform.ID) (*platform.Bucket, error) {
+					return &platform.Bucket{ID: id, OrganizationID: orgID}, nil
+				},
+			}
+			h.BucketStatsService = bucketStatsServiceFunc(func(ctx context.Context, o, b platform.ID) (*platform.BucketStats, error) {
+				if o != orgID || b != bucketID {
+					return nil, fmt.Errorf("unexpected bucket %s of organization %s", b, o)
+				}
+				stats := tt.stats
+				return &stats, nil
+			})
+
+			r := httptest.NewRequest("GET", "http://any.url/api/v2/buckets/020f755c3c082000/stats", nil)
+			r = withBucketPermissions(r, platform.ReadBucketPermission(bucketID))
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+
+			if res.StatusCode != http.StatusOK {
+				t.Fatalf("%q. handleGetBucketStats() = %v, want %v: %s", tt.name, res.StatusCode, http.StatusOK, body)
+			}
+			if eq, _ := jsonEqual(string(body), tt.body); !eq {
+				t.Errorf("%q. handleGetBucketStats() = \n***%v***\n,\nwant\n***%v***", tt.name, string(body), tt.body)
+			}
+
+			// The client decodes the stats that were encoded.
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				h.ServeHTTP(w, withBucketPermissions(r, platform.ReadBucketPermission(bucketID)))
+			}))
+			defer server.Close()
+			client := BucketService{Addr: server.URL}
+			stats, err := client.FindBucketStats(context.Background(), orgID, bucketID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.stats.Measurements == nil {
+				tt.stats.Measurements = []platform.MeasurementCardinality{}
+			}
+			if !reflect.DeepEqual(*stats, tt.stats) {
+				t.Errorf("%q. FindBucketStats() = %+v, want %+v", tt.name, *stats, tt.stats)
+			}
+		})
+	}
+}
+
+func TestService_handleGetBucketStats_Forbidden(t *testing.T) {
+	bucketID := platformtesting.MustIDBase16("020f755c3c082000")
+
+	h := NewBucketHandler(mock.NewUserResourceMappingService())
+	h.BucketService = &mock.BucketService{
+		FindBucketByIDFn: func(ctx context.Context, id platform.ID) (*platform.Bucket, error) {
+			return &platform.Bucket{ID: id, OrganizationID: platformtesting.MustIDBase16("020f755c3c082001")}, nil
+		},
+	}
+	h.BucketStatsService = bucketStatsServiceFunc(func(ctx context.Context, o, b platform.ID) (*platform.BucketStats, error) {
+		t.Fatal("stats read without permission to read the bucket")
+		return nil, nil
+	})
+
+	r := httptest.NewRequest("GET", "http://any.url/api/v2/buckets/020f755c3c082000/stats", nil)
+	r = withBucketPermissions(r, platform.WriteBucketPermission(bucketID))
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, r)
+
+	if res := w.Result(); res.StatusCode != http.StatusForbidden {
+		t.Fatalf("handleGetBucketStats() = %v, want %v", res.StatusCode, http.StatusForbidden)
+	}
+}
+
+type bucketStatsServiceFunc func(ctx context.Context, orgID, bucketID platform.ID) (*platform.BucketStats, error)
+
+func (fn bucketStatsServiceFunc) FindBucketStats(ctx context.Context, orgID, bucketID platform.ID) (*platform.BucketStats, error) {
+	return fn(ctx, orgID, bucketID)
+}
+
+func TestService_handleGetBucketSchema(t *testing.T) {
+	bucketID := platformtesting.MustIDBase16("020f755c3c082000")
+	orgID := platformtesting.MustIDBase16("020f755c3c082001")
+
+	tests := []struct {
+		name       string
+		schemaType platform.SchemaType
+		schema     []platform.MeasurementSchema
+		body       string
+	}{
+		{
+			name: "get schema of bucket with data",
+			schema: []platform.MeasurementSchema{
+				{Name: "cpu", Fields: []platform.FieldSchema{{Name: "usage", Type: platform.FieldTypeFloat}}},
+			},
+			body: `
+{
+  "links": {
+    "self": "/api/v2/buckets/020f755c3c082000/schema",
+    "bucket": "/api/v2/buckets/020f755c3c082000"
+  },
+  "bucketID": "020f755c3c082000",
+  "schemaType": "implicit",
+  "measurements": [
+    {
+      "name": "cpu",
+      "fields": [
+        {
+          "name": "usage",
+          "type": "float"
+        }
+      ]
+    }
+  ]
+}
+`,
+		},
+		{
+			name:       "get schema of empty bucket with explicit schema",
+			schemaType: platform.SchemaTypeExplicit,
+			body: `
+{
+  "links": {
+    "self": "/api/v2/buckets/020f755c3c082000/schema",
+    "bucket": "/api/v2/buckets/020f755c3c082000"
+  },
+  "bucketID": "020f755c3c082000",
+  "schemaType": "explicit",
+  "measurements": []
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewBucketHandler(mock.NewUserResourceMappingService())
+			h.BucketService = &mock.BucketService{
+				FindBucketByIDFn: func(ctx context.Context, id platform.ID) (*platform.Bucket, error) {
+					return &platform.Bucket{ID: id, OrganizationID: orgID, SchemaType: tt.schemaType}, nil
+				},
+			}
+			h.BucketSchemaService = bucketSchemaServiceFunc(func(ctx context.Context, o, b platform.ID) (*platform.BucketSchema, error) {
+				if o != orgID || b != bucketID {
+					return nil, fmt.Errorf("unexpected bucket %s of organization %s", b, o)
+				}
+				return &platform.BucketSchema{BucketID: b, Measurements: tt.schema}, nil
+			})
+
+			r := httptest.NewRequest("GET", "http://any.url/api/v2/buckets/020f755c3c082000/schema", nil)
+			r = withBucketPermissions(r, platform.ReadBucketPermission(bucketID))
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+
+			if res.StatusCode != http.StatusOK {
+				t.Fatalf("%q. handleGetBucketSchema() = %v, want %v: %s", tt.name, res.StatusCode, http.StatusOK, body)
+			}
+			if eq, _ := jsonEqual(string(body), tt.body); !eq {
+				t.Errorf("%q. handleGetBucketSchema() = \n***%v***\n,\nwant\n***%v***", tt.name, string(body), tt.body)
+			}
+		})
+	}
+}
+
+func TestService_handleGetBucketSchema_Forbidden(t *testing.T) {
+	bucketID := platformtesting.MustIDBase16("020f755c3c082000")
+
+	h := NewBucketHandler(mock.NewUserResourceMappingService())
+	h.BucketService = &mock.BucketService{
+		FindBucketByIDFn: func(ctx context.Context, id platform.ID) (*platform.Bucket, error) {
+			return &platform.Bucket{ID: id, OrganizationID: platformtesting.MustIDBase16("020f755c3c082001")}, nil
+		},
+	}
+	h.BucketSchemaService = bucketSchemaServiceFunc(func(ctx context.Context, o, b platform.ID) (*platform.BucketSchema, error) {
+		t.Fatal("schema read without permission to read the bucket")
+		return nil, nil
+	})
+
+	r := httptest.NewRequest("GET", "http://any.url/api/v2/buckets/020f755c3c082000/schema", nil)
+	r = withBucketPermissions(r, platform.WriteBucketPermission(bucketID))
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, r)
+
+	if res := w.Result(); res.StatusCode != http.StatusForbidden {
+		t.Fatalf("handleGetBucketSchema() = %v, want %v", res.StatusCode, http.StatusForbidden)
+	}
+}
+
+type bucketSchemaServiceFunc func(ctx context.Context, orgID, bucketID platform.ID) (*platform.BucketSchema, error)
+
+func (fn bucketSchemaServiceFunc) FindBucketSchema(ctx context.Context, orgID, bucketID platform.ID) (*platform.BucketSchema, error) {
+	return fn(ctx, orgID, bucketID)
+}
+
 func initBucketService(f platformtesting.BucketFields, t *testing.T) (platform.BucketService, func()) {
 	svc := inmem.NewService()
 	svc.IDGenerator = f.IDGenerator
@@ -769,3 +1222,28 @@ func initBucketService(f platformtesting.BucketFields, t *testing.T) (platform.B
 func TestBucketService(t *testing.T) {
 	platformtesting.BucketService(initBucketService, t)
 }
+
+func TestBucket_partitionSeconds(t *testing.T) {
+	b := &bucket{Name: "b", PartitionSeconds: 86400}
+	pb, err := b.toPlatform()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := pb.PartitionDuration, 24*time.Hour; got != exp {
+		t.Fatalf("unexpected partition duration: got %v, exp %v", got, exp)
+	}
+	if got := newBucket(pb).PartitionSeconds; got != 86400 {
+		t.Fatalf("unexpected partition seconds: got %v, exp 86400", got)
+	}
+
+	b.PartitionSeconds = 60
+	if _, err := b.toPlatform(); err == nil {
+		t.Fatal("expected a partition duration shorter than an hour to be rejected")
+	}
+
+	secs := int64(60)
+	upd := &bucketUpdate{PartitionSeconds: &secs}
+	if _, err := upd.toPlatform(); err == nil {
+		t.Fatal("expected an update to a partition duration shorter than an hour to be rejected")
+	}
+}
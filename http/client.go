@@ -1,7 +1,10 @@
 package http
 
 import (
+	"bytes"
+	"context"
 	"crypto/tls"
+	"encoding/json"
 	"net/http"
 	"net/url"
 )
@@ -88,6 +91,46 @@ func newClient(scheme string, insecure bool) *traceClient {
 	return hc
 }
 
+// doJSON sends a request to the service at addr with body encoded as JSON, if it is not
+// nil, and decodes the response into v, if it is not nil.
+func doJSON(ctx context.Context, addr, token string, insecureSkipVerify bool, method, p string, body, v interface{}) error {
+	u, err := newURL(addr, p)
+	if err != nil {
+		return err
+	}
+
+	var octets []byte
+	if body != nil {
+		if octets, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequest(method, u.String(), bytes.NewReader(octets))
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	SetToken(token, req)
+
+	hc := newClient(u.Scheme, insecureSkipVerify)
+	resp, err := hc.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return err
+	}
+	if v == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
 // traceClient always injects any opentracing trace into the client requests.
 type traceClient struct {
 	http.Client
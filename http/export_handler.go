@@ -0,0 +1,317 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/NYTimes/gziphandler"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/csv"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/platform"
+	pcontext "github.com/influxdata/platform/context"
+	"github.com/influxdata/platform/kit/errors"
+	"github.com/influxdata/platform/models"
+	"github.com/influxdata/platform/pkg/escape"
+	fstorage "github.com/influxdata/platform/query/functions/inputs/storage"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+const (
+	exportPath = "/api/v2/export"
+
+	// ExportFormatLineProtocol exports data as line protocol, which can be written back as is.
+	ExportFormatLineProtocol = "lp"
+	// ExportFormatCSV exports data as annotated CSV, with a table for each series.
+	ExportFormatCSV = "csv"
+)
+
+// ExportHandler streams the data of a bucket.
+type ExportHandler struct {
+	*httprouter.Router
+
+	Logger *zap.Logger
+
+	BucketService       platform.BucketService
+	OrganizationService platform.OrganizationService
+
+	Reader fstorage.Reader
+}
+
+// NewExportHandler creates a new handler at /api/v2/export to stream the data of buckets.
+func NewExportHandler() *ExportHandler {
+	h := &ExportHandler{
+		Router: httprouter.New(),
+		Logger: zap.NewNop(),
+	}
+
+	h.Handler("GET", exportPath, gziphandler.GzipHandler(http.HandlerFunc(h.handleExport)))
+	return h
+}
+
+// handleExport is the HTTP handler for the GET /api/v2/export route. The data is read
+// one series at a time and written as it is read, so that any time range can be exported.
+func (h *ExportHandler) handleExport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	a, err := pcontext.GetAuthorizer(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	req, err := decodeExportRequest(ctx, r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	logger := h.Logger.With(zap.String("org", req.Org), zap.String("bucket", req.Bucket))
+
+	bucket, err := findBucket(ctx, h.OrganizationService, h.BucketService, req.Org, req.Bucket, logger)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if !a.Allowed(platform.ReadBucketPermission(bucket.ID)) {
+		EncodeError(ctx, errors.Forbiddenf("insufficient permissions for export"), w)
+		return
+	}
+
+	tables, err := h.Reader.Read(ctx, fstorage.ReadSpec{
+		OrganizationID: bucket.OrganizationID,
+		BucketID:       bucket.ID,
+		Predicate:      req.Predicate,
+		GroupMode:      fstorage.GroupModeAll,
+	}, req.Start, req.Stop)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	switch req.Format {
+	case ExportFormatCSV:
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		_, err = csv.NewResultEncoder(csv.DefaultEncoderConfig()).Encode(w, exportResult{tables: tables})
+	default:
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		err = writeLineProtocol(w, tables)
+	}
+	if err != nil {
+		// The status has been sent with the first data, so the response can only be cut short.
+		logger.Info("Error exporting data", zap.Error(err))
+	}
+}
+
+type exportRequest struct {
+	Org    string
+	Bucket string
+	// Start and Stop are inclusive.
+	Start     execute.Time
+	Stop      execute.Time
+	Predicate *semantic.FunctionExpression
+	Format    string
+}
+
+func decodeExportRequest(ctx context.Context, r *http.Request) (*exportRequest, error) {
+	qp := r.URL.Query()
+	req := &exportRequest{
+		Org:    qp.Get("org"),
+		Bucket: qp.Get("bucket"),
+		Start:  execute.Time(models.MinNanoTime),
+		Stop:   execute.Time(models.MaxNanoTime),
+		Format: ExportFormatLineProtocol,
+	}
+	if req.Bucket == "" {
+		return nil, errors.InvalidDataf("bucket required")
+	}
+
+	if v := qp.Get("start"); v != "" {
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return nil, errors.InvalidDataf("invalid start: %v", err)
+		}
+		req.Start = execute.Time(t.UnixNano())
+	}
+	if v := qp.Get("stop"); v != "" {
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return nil, errors.InvalidDataf("invalid stop: %v", err)
+		}
+		req.Stop = execute.Time(t.UnixNano())
+	}
+	if req.Start > req.Stop {
+		return nil, errors.InvalidDataf("stop before start")
+	}
+
+	if v := qp.Get("predicate"); v != "" {
+		fn, err := parsePredicate(v)
+		if err != nil {
+			return nil, err
+		}
+		req.Predicate = fn
+	}
+
+	switch v := qp.Get("format"); v {
+	case "":
+	case ExportFormatLineProtocol, ExportFormatCSV:
+		req.Format = v
+	default:
+		return nil, errors.InvalidDataf("invalid format %q", v)
+	}
+
+	return req, nil
+}
+
+// exportResult is the single result of an export encoded as annotated CSV.
+type exportResult struct {
+	tables flux.TableIterator
+}
+
+func (r exportResult) Name() string               { return "_result" }
+func (r exportResult) Tables() flux.TableIterator { return r.tables }
+
+// writeLineProtocol writes the rows of tables, each of which holds a single series, as line
+// protocol. The measurement and field of each line are read from the _measurement and _field
+// columns, and the other string columns of the group key are its tags.
+func writeLineProtocol(w io.Writer, tables flux.TableIterator) error {
+	var buf []byte
+	return tables.Do(func(tbl flux.Table) error {
+		var measurement, field []byte
+		var tags models.Tags
+		key := tbl.Key()
+		for j, c := range key.Cols() {
+			if c.Type != flux.TString {
+				continue
+			}
+			switch v := key.ValueString(j); c.Label {
+			case "_measurement":
+				measurement = []byte(v)
+			case "_field":
+				field = []byte(v)
+			default:
+				tags = append(tags, models.NewTag([]byte(c.Label), []byte(v)))
+			}
+		}
+		sort.Sort(tags)
+
+		prefix := models.MakeKey(measurement, tags)
+		prefix = append(prefix, ' ')
+		prefix = append(prefix, escape.Bytes(field)...)
+		prefix = append(prefix, '=')
+
+		timeIdx := execute.ColIdx(execute.DefaultTimeColLabel, tbl.Cols())
+		valueIdx := execute.ColIdx(execute.DefaultValueColLabel, tbl.Cols())
+		if timeIdx < 0 || valueIdx < 0 {
+			return fmt.Errorf("table is missing the %s or %s column", execute.DefaultTimeColLabel, execute.DefaultValueColLabel)
+		}
+
+		return tbl.Do(func(cr flux.ColReader) error {
+			times := cr.Times(timeIdx)
+			for i := 0; i < cr.Len(); i++ {
+				buf = append(buf[:0], prefix...)
+				switch cr.Cols()[valueIdx].Type {
+				case flux.TFloat:
+					buf = strconv.AppendFloat(buf, cr.Floats(valueIdx)[i], 'g', -1, 64)
+				case flux.TInt:
+					buf = strconv.AppendInt(buf, cr.Ints(valueIdx)[i], 10)
+					buf = append(buf, 'i')
+				case flux.TUInt:
+					buf = strconv.AppendUint(buf, cr.UInts(valueIdx)[i], 10)
+					buf = append(buf, 'u')
+				case flux.TBool:
+					buf = strconv.AppendBool(buf, cr.Bools(valueIdx)[i])
+				case flux.TString:
+					buf = append(buf, '"')
+					buf = append(buf, models.EscapeStringField(cr.Strings(valueIdx)[i])...)
+					buf = append(buf, '"')
+				default:
+					return fmt.Errorf("unsupported value type %v", cr.Cols()[valueIdx].Type)
+				}
+				buf = append(buf, ' ')
+				buf = strconv.AppendInt(buf, int64(times[i]), 10)
+				buf = append(buf, '\n')
+
+				if _, err := w.Write(buf); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+}
+
+// ExportFilter selects the data of a bucket to export.
+type ExportFilter struct {
+	// Start and Stop are inclusive. A zero time leaves the range unbounded.
+	Start time.Time
+	Stop  time.Time
+	// Predicate is a Flux predicate function that the series must match, such as (r) => r.host == "a".
+	Predicate string
+	// Format is ExportFormatLineProtocol or ExportFormatCSV, and defaults to line protocol.
+	Format string
+}
+
+// ExportService exports the data of buckets over HTTP.
+type ExportService struct {
+	Addr               string
+	Token              string
+	InsecureSkipVerify bool
+}
+
+// Export writes the data of the bucket that matches filter to w as it is received.
+func (s *ExportService) Export(ctx context.Context, orgID, bucketID platform.ID, filter ExportFilter, w io.Writer) error {
+	u, err := newURL(s.Addr, exportPath)
+	if err != nil {
+		return err
+	}
+
+	params := u.Query()
+	params.Set("org", orgID.String())
+	params.Set("bucket", bucketID.String())
+	if !filter.Start.IsZero() {
+		params.Set("start", filter.Start.Format(time.RFC3339Nano))
+	}
+	if !filter.Stop.IsZero() {
+		params.Set("stop", filter.Stop.Format(time.RFC3339Nano))
+	}
+	if filter.Predicate != "" {
+		params.Set("predicate", filter.Predicate)
+	}
+	if filter.Format != "" {
+		params.Set("format", filter.Format)
+	}
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return err
+	}
+	SetToken(s.Token, req)
+	req = req.WithContext(ctx)
+
+	// The response is compressed in transit, and transparently decompressed by the client.
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return err
+	}
+
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
@@ -0,0 +1,172 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/platform"
+	pcontext "github.com/influxdata/platform/context"
+	"github.com/influxdata/platform/mock"
+	fstorage "github.com/influxdata/platform/query/functions/inputs/storage"
+	platformtesting "github.com/influxdata/platform/testing"
+)
+
+func TestExportHandler_handleExport(t *testing.T) {
+	orgID := platformtesting.MustIDBase16("020f755c3c082000")
+	bucketID := platformtesting.MustIDBase16("020f755c3c082001")
+
+	tables := []*executetest.Table{
+		{
+			KeyCols: []string{"_start", "_stop", "_field", "_measurement", "host"},
+			ColMeta: []flux.ColMeta{
+				{Label: "_start", Type: flux.TTime},
+				{Label: "_stop", Type: flux.TTime},
+				{Label: "_time", Type: flux.TTime},
+				{Label: "_value", Type: flux.TFloat},
+				{Label: "_field", Type: flux.TString},
+				{Label: "_measurement", Type: flux.TString},
+				{Label: "host", Type: flux.TString},
+			},
+			Data: [][]interface{}{
+				{execute.Time(0), execute.Time(100), execute.Time(10), 1.5, "usage user", "cpu", "a b"},
+				{execute.Time(0), execute.Time(100), execute.Time(20), 2.0, "usage user", "cpu", "a b"},
+			},
+		},
+		{
+			KeyCols: []string{"_start", "_stop", "_field", "_measurement"},
+			ColMeta: []flux.ColMeta{
+				{Label: "_start", Type: flux.TTime},
+				{Label: "_stop", Type: flux.TTime},
+				{Label: "_time", Type: flux.TTime},
+				{Label: "_value", Type: flux.TString},
+				{Label: "_field", Type: flux.TString},
+				{Label: "_measurement", Type: flux.TString},
+			},
+			Data: [][]interface{}{
+				{execute.Time(0), execute.Time(100), execute.Time(30), `say "hi"`, "msg", "log"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		query       string
+		permissions []platform.Permission
+		status      int
+		contentType string
+		body        string
+	}{
+		{
+			name:        "export line protocol",
+			query:       "?org=020f755c3c082000&bucket=020f755c3c082001&stop=1970-01-01T00:00:00.0000001Z",
+			permissions: []platform.Permission{platform.ReadBucketPermission(bucketID)},
+			status:      http.StatusOK,
+			contentType: "text/plain; charset=utf-8",
+			body: `cpu,host=a\ b usage\ user=1.5 10
+cpu,host=a\ b usage\ user=2 20
+log msg="say \"hi\"" 30
+`,
+		},
+		{
+			name:        "export annotated csv",
+			query:       "?org=020f755c3c082000&bucket=020f755c3c082001&format=csv",
+			permissions: []platform.Permission{platform.ReadBucketPermission(bucketID)},
+			status:      http.StatusOK,
+			contentType: "text/csv; charset=utf-8",
+		},
+		{
+			name:        "export without permission",
+			query:       "?org=020f755c3c082000&bucket=020f755c3c082001",
+			permissions: []platform.Permission{platform.WriteBucketPermission(bucketID)},
+			status:      http.StatusForbidden,
+		},
+		{
+			name:        "export with invalid format",
+			query:       "?org=020f755c3c082000&bucket=020f755c3c082001&format=xml",
+			permissions: []platform.Permission{platform.ReadBucketPermission(bucketID)},
+			status:      http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orgService := &mock.OrganizationService{
+				FindOrganizationByIDF: func(ctx context.Context, id platform.ID) (*platform.Organization, error) {
+					return &platform.Organization{ID: id}, nil
+				},
+			}
+			bucketService := mock.NewBucketService()
+			bucketService.FindBucketFn = func(ctx context.Context, filter platform.BucketFilter) (*platform.Bucket, error) {
+				return &platform.Bucket{ID: *filter.ID, OrganizationID: *filter.OrganizationID}, nil
+			}
+			reader := &fakeReader{tables: tables}
+
+			h := NewExportHandler()
+			h.OrganizationService = orgService
+			h.BucketService = bucketService
+			h.Reader = reader
+
+			r := httptest.NewRequest("GET", "http://any.url/api/v2/export"+tt.query, nil)
+			r = r.WithContext(pcontext.SetAuthorizer(r.Context(), &platform.Authorization{
+				Status:      platform.Active,
+				Permissions: tt.permissions,
+			}))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+			if res.StatusCode != tt.status {
+				t.Fatalf("got status %d, want %d: %s", res.StatusCode, tt.status, body)
+			}
+			if got := res.Header.Get("Content-Type"); tt.contentType != "" && got != tt.contentType {
+				t.Errorf("got content type %q, want %q", got, tt.contentType)
+			}
+			if tt.body != "" && string(body) != tt.body {
+				t.Errorf("got body:\n%s\nwant:\n%s", body, tt.body)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			if reader.spec.OrganizationID != orgID || reader.spec.BucketID != bucketID {
+				t.Errorf("read org %s and bucket %s, want %s and %s", reader.spec.OrganizationID, reader.spec.BucketID, orgID, bucketID)
+			}
+			if reader.spec.GroupMode != fstorage.GroupModeAll {
+				t.Errorf("read with group mode %v, want a table per series", reader.spec.GroupMode)
+			}
+		})
+	}
+}
+
+type fakeReader struct {
+	fakeSchemaReader
+	tables      []*executetest.Table
+	spec        fstorage.ReadSpec
+	start, stop execute.Time
+}
+
+func (r *fakeReader) Read(ctx context.Context, spec fstorage.ReadSpec, start, stop execute.Time) (flux.TableIterator, error) {
+	r.spec, r.start, r.stop = spec, start, stop
+	return tableIterator(r.tables), nil
+}
+
+func (r *fakeReader) Close() {}
+
+type tableIterator []*executetest.Table
+
+func (it tableIterator) Do(f func(flux.Table) error) error {
+	for _, tbl := range it {
+		tbl.Normalize()
+		if err := f(tbl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
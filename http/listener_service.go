@@ -0,0 +1,325 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+
+	"github.com/influxdata/platform"
+	pcontext "github.com/influxdata/platform/context"
+	kerrors "github.com/influxdata/platform/kit/errors"
+	"github.com/influxdata/platform/listener"
+	"github.com/julienschmidt/httprouter"
+)
+
+const (
+	listenersPath = "/api/v2/listeners"
+)
+
+// ListenerHandler is the handler for the listener service
+type ListenerHandler struct {
+	*httprouter.Router
+
+	ListenerService platform.ListenerService
+}
+
+// NewListenerHandler creates a new ListenerHandler
+func NewListenerHandler() *ListenerHandler {
+	h := &ListenerHandler{
+		Router: httprouter.New(),
+	}
+
+	h.HandlerFunc("GET", listenersPath, h.handleGetListeners)
+	h.HandlerFunc("POST", listenersPath, h.handlePostListener)
+	h.HandlerFunc("GET", listenersPath+"/:id", h.handleGetListener)
+	h.HandlerFunc("PATCH", listenersPath+"/:id", h.handlePatchListener)
+	h.HandlerFunc("DELETE", listenersPath+"/:id", h.handleDeleteListener)
+
+	return h
+}
+
+type listenersLinks struct {
+	Self string `json:"self"`
+}
+
+type getListenersResponse struct {
+	Listeners []listenerResponse `json:"listeners"`
+	Links     listenersLinks     `json:"links"`
+}
+
+func (r getListenersResponse) ToPlatform() []*platform.Listener {
+	ls := make([]*platform.Listener, len(r.Listeners))
+	for i := range r.Listeners {
+		ls[i] = r.Listeners[i].Listener
+	}
+	return ls
+}
+
+func newGetListenersResponse(ls []*platform.Listener) getListenersResponse {
+	resp := getListenersResponse{
+		Listeners: make([]listenerResponse, 0, len(ls)),
+		Links: listenersLinks{
+			Self: listenersPath,
+		},
+	}
+
+	for _, l := range ls {
+		resp.Listeners = append(resp.Listeners, newListenerResponse(l))
+	}
+
+	return resp
+}
+
+type listenerLinks struct {
+	Self         string `json:"self"`
+	Bucket       string `json:"bucket"`
+	Organization string `json:"organization"`
+}
+
+type listenerResponse struct {
+	*platform.Listener
+	Links listenerLinks `json:"links"`
+}
+
+// newListenerResponse returns the response of a listener, without its token.
+func newListenerResponse(l *platform.Listener) listenerResponse {
+	redacted := *l
+	redacted.Token = ""
+	return listenerResponse{
+		Listener: &redacted,
+		Links: listenerLinks{
+			Self:         listenerIDPath(l.ID),
+			Bucket:       fmt.Sprintf("/api/v2/buckets/%s", l.BucketID),
+			Organization: fmt.Sprintf("/api/v2/orgs/%s", l.OrganizationID),
+		},
+	}
+}
+
+func (h *ListenerHandler) handleGetListeners(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	a, err := pcontext.GetAuthorizer(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	ls, err := h.ListenerService.FindListeners(ctx)
+	if err != nil {
+		EncodeError(ctx, kerrors.InternalErrorf("could not read listeners: %v", err), w)
+		return
+	}
+
+	// Only the listeners of organizations that the request may read are returned.
+	readable := ls[:0]
+	for _, l := range ls {
+		if a.Allowed(platform.Permission{Action: platform.ReadAction, Resource: platform.ListenerResource(l.OrganizationID)}) {
+			readable = append(readable, l)
+		}
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newGetListenersResponse(readable)); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *ListenerHandler) handleGetListener(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := requestID(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	l, err := h.ListenerService.FindListenerByID(ctx, id)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	perm := platform.Permission{Action: platform.ReadAction, Resource: platform.ListenerResource(l.OrganizationID)}
+	if err := authorizeOrg(ctx, perm, "listeners", l.OrganizationID); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newListenerResponse(l)); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *ListenerHandler) handlePostListener(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	l := &platform.Listener{}
+	if err := json.NewDecoder(r.Body).Decode(l); err != nil {
+		EncodeError(ctx, kerrors.MalformedDataf("%v", err), w)
+		return
+	}
+	if err := listener.Validate(l); err != nil {
+		EncodeError(ctx, kerrors.InvalidDataf("%v", err), w)
+		return
+	}
+
+	perm := platform.Permission{Action: platform.CreateAction, Resource: platform.ListenerResource(l.OrganizationID)}
+	if err := authorizeOrg(ctx, perm, "listeners", l.OrganizationID); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := h.ListenerService.CreateListener(ctx, l); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusCreated, newListenerResponse(l)); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *ListenerHandler) handlePatchListener(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := requestID(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	var upd platform.ListenerUpdate
+	if err := json.NewDecoder(r.Body).Decode(&upd); err != nil {
+		EncodeError(ctx, kerrors.MalformedDataf("%v", err), w)
+		return
+	}
+
+	l, err := h.ListenerService.FindListenerByID(ctx, id)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	// Moving a listener to another organization requires writing the listeners of both.
+	orgIDs := []platform.ID{l.OrganizationID}
+	if upd.OrganizationID != nil && *upd.OrganizationID != l.OrganizationID {
+		orgIDs = append(orgIDs, *upd.OrganizationID)
+	}
+	for _, orgID := range orgIDs {
+		perm := platform.Permission{Action: platform.WriteAction, Resource: platform.ListenerResource(orgID)}
+		if err := authorizeOrg(ctx, perm, "listeners", orgID); err != nil {
+			EncodeError(ctx, err, w)
+			return
+		}
+	}
+
+	// The templates are validated before the update, as the service only validates
+	// the other fields of a listener.
+	if err := upd.Apply(l); err != nil {
+		EncodeError(ctx, kerrors.InvalidDataf("%v", err), w)
+		return
+	}
+	if err := listener.Validate(l); err != nil {
+		EncodeError(ctx, kerrors.InvalidDataf("%v", err), w)
+		return
+	}
+
+	l, err = h.ListenerService.UpdateListener(ctx, id, upd)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newListenerResponse(l)); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *ListenerHandler) handleDeleteListener(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := requestID(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	l, err := h.ListenerService.FindListenerByID(ctx, id)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	perm := platform.Permission{Action: platform.DeleteAction, Resource: platform.ListenerResource(l.OrganizationID)}
+	if err := authorizeOrg(ctx, perm, "listeners", l.OrganizationID); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := h.ListenerService.DeleteListener(ctx, id); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListenerService is a listener service over HTTP to the influxdb server
+type ListenerService struct {
+	Addr               string
+	Token              string
+	InsecureSkipVerify bool
+}
+
+// FindListenerByID finds a single listener by its ID
+func (s *ListenerService) FindListenerByID(ctx context.Context, id platform.ID) (*platform.Listener, error) {
+	var lr listenerResponse
+	if err := s.do(ctx, "GET", listenerIDPath(id), nil, &lr); err != nil {
+		return nil, err
+	}
+	return lr.Listener, nil
+}
+
+// FindListeners returns all listeners
+func (s *ListenerService) FindListeners(ctx context.Context) ([]*platform.Listener, error) {
+	var lr getListenersResponse
+	if err := s.do(ctx, "GET", listenersPath, nil, &lr); err != nil {
+		return nil, err
+	}
+	return lr.ToPlatform(), nil
+}
+
+// CreateListener creates a new listener and assigns it an ID
+func (s *ListenerService) CreateListener(ctx context.Context, l *platform.Listener) error {
+	if err := l.Valid(); err != nil {
+		return kerrors.InvalidDataf("%v", err)
+	}
+	return s.do(ctx, "POST", listenersPath, l, l)
+}
+
+// UpdateListener updates a single listener with a changeset
+func (s *ListenerService) UpdateListener(ctx context.Context, id platform.ID, upd platform.ListenerUpdate) (*platform.Listener, error) {
+	var l platform.Listener
+	if err := s.do(ctx, "PATCH", listenerIDPath(id), upd, &l); err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+// DeleteListener removes a listener by its ID
+func (s *ListenerService) DeleteListener(ctx context.Context, id platform.ID) error {
+	return s.do(ctx, "DELETE", listenerIDPath(id), nil, nil)
+}
+
+func (s *ListenerService) do(ctx context.Context, method, p string, body, v interface{}) error {
+	return doJSON(ctx, s.Addr, s.Token, s.InsecureSkipVerify, method, p, body, v)
+}
+
+func listenerIDPath(id platform.ID) string {
+	return path.Join(listenersPath, id.String())
+}
@@ -0,0 +1,316 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/influxdata/platform"
+	pcontext "github.com/influxdata/platform/context"
+	"github.com/influxdata/platform/inmem"
+	"github.com/influxdata/platform/mock"
+	platformtesting "github.com/influxdata/platform/testing"
+)
+
+func TestListenerService_handleGetListeners(t *testing.T) {
+	svc := inmem.NewService()
+	l := &platform.Listener{
+		ID:             platformtesting.MustIDBase16("020f755c3c082000"),
+		Name:           "devices",
+		Protocol:       platform.ListenerProtocolTCP,
+		BindAddress:    ":8094",
+		OrganizationID: platformtesting.MustIDBase16("020f755c3c082001"),
+		BucketID:       platformtesting.MustIDBase16("020f755c3c082002"),
+		Token:          "token",
+	}
+	if err := svc.PutListener(context.Background(), l); err != nil {
+		t.Fatal(err)
+	}
+
+	other := *l
+	other.ID = platformtesting.MustIDBase16("020f755c3c082003")
+	other.OrganizationID = platformtesting.MustIDBase16("020f755c3c082004")
+	if err := svc.PutListener(context.Background(), &other); err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewListenerHandler()
+	h.ListenerService = svc
+
+	// Only the listener of the organization that the request may read is returned.
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, withListenerPermissions(httptest.NewRequest("GET", "http://any.url/api/v2/listeners", nil), readListeners))
+
+	res := w.Result()
+	body, _ := ioutil.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("got status %d, want %d: %s", res.StatusCode, http.StatusOK, body)
+	}
+
+	want := `{"listeners":[{"id":"020f755c3c082000","name":"devices","protocol":"tcp","bindAddress":":8094","organizationID":"020f755c3c082001","bucketID":"020f755c3c082002","links":{"self":"/api/v2/listeners/020f755c3c082000","bucket":"/api/v2/buckets/020f755c3c082002","organization":"/api/v2/orgs/020f755c3c082001"}}],"links":{"self":"/api/v2/listeners"}}`
+	if eq, _ := jsonEqual(string(body), want); !eq {
+		t.Errorf("got body %s, want %s", body, want)
+	}
+}
+
+func TestListenerService_handlePostListener(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{
+			name:   "valid listener",
+			body:   `{"name":"devices","protocol":"udp","bindAddress":":8089","organizationID":"020f755c3c082001","bucketID":"020f755c3c082002","token":"token"}`,
+			status: http.StatusCreated,
+		},
+		{
+			name:   "unknown protocol",
+			body:   `{"name":"devices","protocol":"sctp","bindAddress":":8089","organizationID":"020f755c3c082001","bucketID":"020f755c3c082002","token":"token"}`,
+			status: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "missing token",
+			body:   `{"name":"devices","protocol":"udp","bindAddress":":8089","organizationID":"020f755c3c082001","bucketID":"020f755c3c082002"}`,
+			status: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "graphite listener",
+			body:   `{"name":"carbon","protocol":"tcp","format":"graphite","bindAddress":":2003","organizationID":"020f755c3c082001","bucketID":"020f755c3c082002","token":"token","graphite":{"templates":["servers.* .host.measurement*"]}}`,
+			status: http.StatusCreated,
+		},
+		{
+			name:   "invalid graphite template",
+			body:   `{"name":"carbon","protocol":"tcp","format":"graphite","bindAddress":":2003","organizationID":"020f755c3c082001","bucketID":"020f755c3c082002","token":"token","graphite":{"templates":["host.region"]}}`,
+			status: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "malformed body",
+			body:   `{"name":`,
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := inmem.NewService()
+			svc.IDGenerator = mock.NewIDGenerator("020f755c3c082000", t)
+
+			h := NewListenerHandler()
+			h.ListenerService = svc
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, withListenerPermissions(httptest.NewRequest("POST", "http://any.url/api/v2/listeners", bytes.NewBufferString(tt.body)), createListeners))
+
+			res := w.Result()
+			if res.StatusCode != tt.status {
+				body, _ := ioutil.ReadAll(res.Body)
+				t.Errorf("got status %d, want %d: %s", res.StatusCode, tt.status, body)
+			}
+		})
+	}
+}
+
+func TestListenerService_authorization(t *testing.T) {
+	svc := inmem.NewService()
+	svc.IDGenerator = mock.NewIDGenerator("020f755c3c082000", t)
+	l := &platform.Listener{
+		ID:             platformtesting.MustIDBase16("020f755c3c082003"),
+		Name:           "devices",
+		Protocol:       platform.ListenerProtocolTCP,
+		BindAddress:    ":8094",
+		OrganizationID: platformtesting.MustIDBase16("020f755c3c082004"),
+		BucketID:       platformtesting.MustIDBase16("020f755c3c082002"),
+		Token:          "token",
+	}
+	if err := svc.PutListener(context.Background(), l); err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewListenerHandler()
+	h.ListenerService = svc
+
+	all := []platform.Permission{readListeners, createListeners, writeListeners, deleteListeners}
+	tests := []struct {
+		name   string
+		method string
+		url    string
+		body   string
+		perms  []platform.Permission
+		status int
+	}{
+		{
+			name:   "create in another organization",
+			method: "POST",
+			url:    "http://any.url/api/v2/listeners",
+			body:   `{"name":"devices","protocol":"udp","bindAddress":":8089","organizationID":"020f755c3c082004","bucketID":"020f755c3c082002","token":"token"}`,
+			perms:  all,
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "create without create permission",
+			method: "POST",
+			url:    "http://any.url/api/v2/listeners",
+			body:   `{"name":"devices","protocol":"udp","bindAddress":":8089","organizationID":"020f755c3c082001","bucketID":"020f755c3c082002","token":"token"}`,
+			perms:  []platform.Permission{readListeners, writeListeners},
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "read a listener of another organization",
+			method: "GET",
+			url:    "http://any.url/api/v2/listeners/020f755c3c082003",
+			perms:  all,
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "move a listener of another organization",
+			method: "PATCH",
+			url:    "http://any.url/api/v2/listeners/020f755c3c082003",
+			body:   `{"organizationID":"020f755c3c082001"}`,
+			perms:  all,
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "delete a listener of another organization",
+			method: "DELETE",
+			url:    "http://any.url/api/v2/listeners/020f755c3c082003",
+			perms:  all,
+			status: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, withListenerPermissions(httptest.NewRequest(tt.method, tt.url, bytes.NewBufferString(tt.body)), tt.perms...))
+
+			res := w.Result()
+			if res.StatusCode != tt.status {
+				body, _ := ioutil.ReadAll(res.Body)
+				t.Errorf("got status %d, want %d: %s", res.StatusCode, tt.status, body)
+			}
+		})
+	}
+
+	if _, err := svc.FindListenerByID(context.Background(), l.ID); err != nil {
+		t.Errorf("listener of another organization was deleted: %v", err)
+	}
+}
+
+func TestListenerService_handlePatchListener(t *testing.T) {
+	svc := inmem.NewService()
+	l := &platform.Listener{
+		ID:             platformtesting.MustIDBase16("020f755c3c082000"),
+		Name:           "carbon",
+		Protocol:       platform.ListenerProtocolTCP,
+		BindAddress:    ":2003",
+		OrganizationID: platformtesting.MustIDBase16("020f755c3c082001"),
+		BucketID:       platformtesting.MustIDBase16("020f755c3c082002"),
+		Token:          "token",
+	}
+	if err := svc.PutListener(context.Background(), l); err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewListenerHandler()
+	h.ListenerService = svc
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		want   string
+	}{
+		{
+			name:   "invalid graphite template",
+			body:   `{"format":"graphite","graphite":{"templates":["host.region"]}}`,
+			status: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "new token",
+			body:   `{"token":"secret"}`,
+			status: http.StatusOK,
+			want:   `{"id":"020f755c3c082000","name":"carbon","protocol":"tcp","bindAddress":":2003","organizationID":"020f755c3c082001","bucketID":"020f755c3c082002","links":{"self":"/api/v2/listeners/020f755c3c082000","bucket":"/api/v2/buckets/020f755c3c082002","organization":"/api/v2/orgs/020f755c3c082001"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, withListenerPermissions(httptest.NewRequest("PATCH", "http://any.url/api/v2/listeners/020f755c3c082000", bytes.NewBufferString(tt.body)), writeListeners))
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+			if res.StatusCode != tt.status {
+				t.Fatalf("got status %d, want %d: %s", res.StatusCode, tt.status, body)
+			}
+			if tt.want == "" {
+				return
+			}
+			if eq, _ := jsonEqual(string(body), tt.want); !eq {
+				t.Errorf("got body %s, want %s", body, tt.want)
+			}
+		})
+	}
+
+	got, err := svc.FindListenerByID(context.Background(), l.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Format != "" || got.Token != "secret" {
+		t.Errorf("got format %q and token %q, want no format and token %q", got.Format, got.Token, "secret")
+	}
+}
+
+// withListenerPermissions returns r with an authorizer allowed the actions of perms on
+// the listeners of organization 020f755c3c082001 and, for the conformance tests,
+// 020f755c3c082000.
+func withListenerPermissions(r *http.Request, perms ...platform.Permission) *http.Request {
+	a := &platform.Authorization{Status: platform.Active}
+	for _, org := range []string{"020f755c3c082000", "020f755c3c082001"} {
+		for _, p := range perms {
+			p.Resource = platform.ListenerResource(platformtesting.MustIDBase16(org))
+			a.Permissions = append(a.Permissions, p)
+		}
+	}
+	return r.WithContext(pcontext.SetAuthorizer(r.Context(), a))
+}
+
+var (
+	readListeners   = platform.Permission{Action: platform.ReadAction}
+	createListeners = platform.Permission{Action: platform.CreateAction}
+	writeListeners  = platform.Permission{Action: platform.WriteAction}
+	deleteListeners = platform.Permission{Action: platform.DeleteAction}
+)
+
+func initListenerService(f platformtesting.ListenerFields, t *testing.T) (platform.ListenerService, func()) {
+	t.Helper()
+	svc := inmem.NewService()
+	svc.IDGenerator = f.IDGenerator
+
+	ctx := context.Background()
+	for _, l := range f.Listeners {
+		if err := svc.PutListener(ctx, l); err != nil {
+			t.Fatalf("failed to populate listeners")
+		}
+	}
+
+	handler := NewListenerHandler()
+	handler.ListenerService = svc
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.ServeHTTP(w, withListenerPermissions(r, readListeners, createListeners, writeListeners, deleteListeners))
+	}))
+	client := ListenerService{
+		Addr: server.URL,
+	}
+	done := server.Close
+
+	return &client, done
+}
+
+func TestListenerService(t *testing.T) {
+	platformtesting.RedactedListenerService(initListenerService, t)
+}
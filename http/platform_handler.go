@@ -34,6 +34,10 @@ func NewPlatformHandler(b *APIBackend) *PlatformHandler {
 	h.RegisterNoAuthRoute("POST", "/api/v2/setup")
 	h.RegisterNoAuthRoute("GET", "/api/v2/setup")
 
+	// Prometheus remote write and read only send bearer tokens.
+	h.RegisterBearerRoute("POST", promWritePath)
+	h.RegisterBearerRoute("POST", promReadPath)
+
 	return &PlatformHandler{
 		AssetHandler: NewAssetHandler(),
 		APIHandler:   h,
@@ -0,0 +1,213 @@
+package http
+
+import (
+	"io/ioutil"
+	"net/http"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/types"
+	"github.com/golang/snappy"
+	"github.com/influxdata/influxdb/prometheus/remote"
+	"github.com/influxdata/platform"
+	pcontext "github.com/influxdata/platform/context"
+	"github.com/influxdata/platform/kit/errors"
+	"github.com/influxdata/platform/models"
+	"github.com/influxdata/platform/prometheus"
+	fstorage "github.com/influxdata/platform/query/functions/inputs/storage"
+	"github.com/influxdata/platform/storage"
+	"github.com/influxdata/platform/storage/reads"
+	"github.com/influxdata/platform/tsdb"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+const (
+	promWritePath = "/api/v2/prom/write"
+	promReadPath  = "/api/v2/prom/read"
+)
+
+// PromHandler receives the samples of Prometheus remote writes and serves Prometheus
+// remote reads.
+type PromHandler struct {
+	*httprouter.Router
+
+	Logger *zap.Logger
+
+	BucketService       platform.BucketService
+	OrganizationService platform.OrganizationService
+
+	PointsWriter storage.PointsWriter
+	Store        reads.Store
+}
+
+// NewPromHandler creates a new handler at /api/v2/prom for Prometheus remote writes and reads.
+func NewPromHandler() *PromHandler {
+	h := &PromHandler{
+		Router: httprouter.New(),
+		Logger: zap.NewNop(),
+	}
+
+	h.HandlerFunc("POST", promWritePath, h.handleWrite)
+	h.HandlerFunc("POST", promReadPath, h.handleRead)
+	return h
+}
+
+// handleWrite is the HTTP handler for the POST /api/v2/prom/write route.
+func (h *PromHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	a, err := pcontext.GetAuthorizer(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	qp := r.URL.Query()
+	logger := h.Logger.With(zap.String("org", qp.Get("org")), zap.String("bucket", qp.Get("bucket")))
+
+	bucket, err := findBucket(ctx, h.OrganizationService, h.BucketService, qp.Get("org"), qp.Get("bucket"), logger)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if !a.Allowed(platform.WriteBucketPermission(bucket.ID)) {
+		EncodeError(ctx, errors.Forbiddenf("insufficient permissions for write"), w)
+		return
+	}
+
+	var req remote.WriteRequest
+	if err := decodePromRequest(r, &req); err != nil {
+		logger.Info("Error decoding remote write", zap.Error(err))
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	ms, err := prometheus.WriteRequestToMetrics(&req)
+	if err != nil {
+		EncodeError(ctx, errors.Wrap(err, "invalid remote write", errors.InvalidData), w)
+		return
+	}
+
+	points := make([]models.Point, 0, len(ms))
+	for _, m := range ms {
+		pt, err := m.Point()
+		if err != nil {
+			EncodeError(ctx, errors.Wrap(err, "invalid sample", errors.InvalidData), w)
+			return
+		}
+		points = append(points, pt)
+	}
+
+	exploded, err := tsdb.ExplodePoints(bucket.OrganizationID, bucket.ID, points)
+	if err != nil {
+		logger.Info("Error exploding points", zap.Error(err))
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := h.PointsWriter.WritePoints(exploded); err != nil {
+		EncodeError(ctx, errors.BadRequestError(err.Error()), w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleRead is the HTTP handler for the POST /api/v2/prom/read route.
+func (h *PromHandler) handleRead(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	a, err := pcontext.GetAuthorizer(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	qp := r.URL.Query()
+	logger := h.Logger.With(zap.String("org", qp.Get("org")), zap.String("bucket", qp.Get("bucket")))
+
+	bucket, err := findBucket(ctx, h.OrganizationService, h.BucketService, qp.Get("org"), qp.Get("bucket"), logger)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if !a.Allowed(platform.ReadBucketPermission(bucket.ID)) {
+		EncodeError(ctx, errors.Forbiddenf("insufficient permissions for read"), w)
+		return
+	}
+
+	var req remote.ReadRequest
+	if err := decodePromRequest(r, &req); err != nil {
+		logger.Info("Error decoding remote read", zap.Error(err))
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	src, err := h.Store.GetSource(fstorage.ReadSpec{
+		OrganizationID: bucket.OrganizationID,
+		BucketID:       bucket.ID,
+	})
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+	any, err := types.MarshalAny(src)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	var enc prometheus.ReadResponseEncoder
+	for _, q := range req.Queries {
+		rq, err := prometheus.NewReadQuery(q, any)
+		if err != nil {
+			EncodeError(ctx, errors.Wrap(err, "invalid query", errors.InvalidData), w)
+			return
+		}
+
+		rs, err := h.Store.Read(ctx, rq.Request)
+		if err != nil {
+			EncodeError(ctx, err, w)
+			return
+		}
+
+		err = enc.EncodeResult(rq, rs)
+		if rs != nil {
+			rs.Close()
+		}
+		if err != nil {
+			logger.Info("Error reading series", zap.Error(err))
+			EncodeError(ctx, err, w)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.Header().Set("Content-Encoding", "snappy")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(snappy.Encode(nil, enc.Bytes())); err != nil {
+		logger.Info("Error writing remote read response", zap.Error(err))
+	}
+}
+
+// decodePromRequest decodes the snappy compressed protocol buffer body of r into pb.
+func decodePromRequest(r *http.Request, pb proto.Message) error {
+	defer r.Body.Close()
+
+	compressed, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+
+	data, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		return errors.Wrap(err, "invalid snappy", errors.InvalidData)
+	}
+
+	if err := proto.Unmarshal(data, pb); err != nil {
+		return errors.Wrap(err, "invalid protocol buffer", errors.InvalidData)
+	}
+	return nil
+}
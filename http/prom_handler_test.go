@@ -0,0 +1,278 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/influxdata/influxdb/prometheus/remote"
+	"github.com/influxdata/platform"
+	pcontext "github.com/influxdata/platform/context"
+	"github.com/influxdata/platform/mock"
+	"github.com/influxdata/platform/models"
+	fstorage "github.com/influxdata/platform/query/functions/inputs/storage"
+	"github.com/influxdata/platform/storage/reads"
+	"github.com/influxdata/platform/storage/reads/datatypes"
+	platformtesting "github.com/influxdata/platform/testing"
+	"github.com/influxdata/platform/tsdb"
+	"github.com/influxdata/platform/tsdb/cursors"
+)
+
+func TestPromHandler_handleWrite(t *testing.T) {
+	orgID := platformtesting.MustIDBase16("020f755c3c082000")
+	bucketID := platformtesting.MustIDBase16("020f755c3c082001")
+
+	body := mustEncodeProm(t, &remote.WriteRequest{
+		Timeseries: []*remote.TimeSeries{
+			{
+				Labels: []*remote.LabelPair{
+					{Name: "__name__", Value: "go_goroutines"},
+					{Name: "job", Value: "prometheus"},
+				},
+				Samples: []*remote.Sample{{TimestampMs: 1000, Value: 36}},
+			},
+		},
+	})
+
+	tests := []struct {
+		name        string
+		body        []byte
+		permissions []platform.Permission
+		status      int
+		points      []string
+	}{
+		{
+			name:        "write samples",
+			body:        body,
+			permissions: []platform.Permission{platform.WriteBucketPermission(bucketID)},
+			status:      http.StatusNoContent,
+			points:      []string{"go_goroutines,job=prometheus value=36 1000000000"},
+		},
+		{
+			name:        "write without permission",
+			body:        body,
+			permissions: []platform.Permission{platform.ReadBucketPermission(bucketID)},
+			status:      http.StatusForbidden,
+		},
+		{
+			name:        "write without snappy",
+			body:        []byte("not snappy"),
+			permissions: []platform.Permission{platform.WriteBucketPermission(bucketID)},
+			status:      http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pw := &mock.PointsWriter{}
+
+			h := NewPromHandler()
+			h.OrganizationService = newPromOrgService()
+			h.BucketService = newPromBucketService()
+			h.PointsWriter = pw
+
+			w := servePromRequest(h, "/api/v2/prom/write", tt.body, tt.permissions)
+			res := w.Result()
+			if res.StatusCode != tt.status {
+				b, _ := ioutil.ReadAll(res.Body)
+				t.Fatalf("got status %d, want %d: %s", res.StatusCode, tt.status, b)
+			}
+
+			var got []string
+			for _, pt := range pw.Points {
+				// Strip the encoded org and bucket that prefix the exploded points.
+				name, tags := models.ParseKeyBytes(pt.Key())
+				if exp := tsdb.EncodeName(orgID, bucketID); !bytes.Equal(name, exp[:]) {
+					t.Fatalf("got points written to %x, want %x", name, exp)
+				}
+				fields, _ := pt.Fields()
+				p, err := models.NewPoint(tags.GetString(tsdb.MeasurementTagKey), tagsWithoutStorageKeys(tags), models.Fields{tags.GetString(tsdb.FieldKeyTagKey): fields["value"]}, pt.Time())
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, p.String())
+			}
+			if !reflect.DeepEqual(got, tt.points) {
+				t.Errorf("got points %v, want %v", got, tt.points)
+			}
+		})
+	}
+}
+
+func TestPromHandler_handleRead(t *testing.T) {
+	bucketID := platformtesting.MustIDBase16("020f755c3c082001")
+
+	store := &fakeStore{
+		series: []fakeSeries{
+			{
+				tags: models.NewTags(map[string]string{tsdb.MeasurementTagKey: "go_goroutines", tsdb.FieldKeyTagKey: "value", "job": "prometheus"}),
+				ts:   []int64{1000000000, 2000000000},
+				vs:   []float64{36, 37},
+			},
+		},
+	}
+
+	h := NewPromHandler()
+	h.OrganizationService = newPromOrgService()
+	h.BucketService = newPromBucketService()
+	h.Store = store
+
+	body := mustEncodeProm(t, &remote.ReadRequest{
+		Queries: []*remote.Query{
+			{
+				StartTimestampMs: 1000,
+				EndTimestampMs:   2000,
+				Matchers:         []*remote.LabelMatcher{{Name: "__name__", Value: "go_goroutines"}},
+			},
+		},
+	})
+
+	w := servePromRequest(h, "/api/v2/prom/read", body, []platform.Permission{platform.ReadBucketPermission(bucketID)})
+	res := w.Result()
+	b, _ := ioutil.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("got status %d, want %d: %s", res.StatusCode, http.StatusOK, b)
+	}
+
+	if got, exp := reads.PredicateToExprString(store.req.Predicate), `'_m' = "go_goroutines" AND '_f' =~ /^(?:value|counter|gauge|sum|count)$/`; got != exp {
+		t.Errorf("got predicate %s, want %s", got, exp)
+	}
+	if got, exp := store.req.TimestampRange, (datatypes.TimestampRange{Start: 1000000000, End: 2000000000}); got != exp {
+		t.Errorf("got time range %v, want %v", got, exp)
+	}
+
+	data, err := snappy.Decode(nil, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var resp remote.ReadResponse
+	if err := proto.Unmarshal(data, &resp); err != nil {
+		t.Fatal(err)
+	}
+	exp := remote.ReadResponse{
+		Results: []*remote.QueryResult{
+			{
+				Timeseries: []*remote.TimeSeries{
+					{
+						Labels: []*remote.LabelPair{
+							{Name: "__name__", Value: "go_goroutines"},
+							{Name: "job", Value: "prometheus"},
+						},
+						Samples: []*remote.Sample{
+							{TimestampMs: 1000, Value: 36},
+							{TimestampMs: 2000, Value: 37},
+						},
+					},
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(resp, exp) {
+		t.Errorf("got response %v, want %v", resp, exp)
+	}
+}
+
+func newPromOrgService() *mock.OrganizationService {
+	return &mock.OrganizationService{
+		FindOrganizationByIDF: func(ctx context.Context, id platform.ID) (*platform.Organization, error) {
+			return &platform.Organization{ID: id}, nil
+		},
+	}
+}
+
+func newPromBucketService() *mock.BucketService {
+	bucketService := mock.NewBucketService()
+	bucketService.FindBucketFn = func(ctx context.Context, filter platform.BucketFilter) (*platform.Bucket, error) {
+		return &platform.Bucket{ID: *filter.ID, OrganizationID: *filter.OrganizationID}, nil
+	}
+	return bucketService
+}
+
+func servePromRequest(h *PromHandler, path string, body []byte, permissions []platform.Permission) *httptest.ResponseRecorder {
+	r := httptest.NewRequest("POST", "http://any.url"+path+"?org=020f755c3c082000&bucket=020f755c3c082001", bytes.NewReader(body))
+	r = r.WithContext(pcontext.SetAuthorizer(r.Context(), &platform.Authorization{
+		Status:      platform.Active,
+		Permissions: permissions,
+	}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func mustEncodeProm(t *testing.T, pb proto.Message) []byte {
+	t.Helper()
+	data, err := proto.Marshal(pb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return snappy.Encode(nil, data)
+}
+
+func tagsWithoutStorageKeys(tags models.Tags) models.Tags {
+	var out models.Tags
+	for _, t := range tags {
+		if k := string(t.Key); k != tsdb.MeasurementTagKey && k != tsdb.FieldKeyTagKey {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+type fakeStore struct {
+	reads.Store
+	series []fakeSeries
+	req    *datatypes.ReadRequest
+}
+
+func (s *fakeStore) GetSource(rs fstorage.ReadSpec) (proto.Message, error) {
+	return &datatypes.ReadRequest{}, nil
+}
+
+func (s *fakeStore) Read(ctx context.Context, req *datatypes.ReadRequest) (reads.ResultSet, error) {
+	s.req = req
+	return &fakeResultSet{series: s.series, i: -1}, nil
+}
+
+type fakeSeries struct {
+	tags models.Tags
+	ts   []int64
+	vs   []float64
+}
+
+type fakeResultSet struct {
+	series []fakeSeries
+	i      int
+}
+
+func (rs *fakeResultSet) Next() bool {
+	rs.i++
+	return rs.i < len(rs.series)
+}
+
+func (rs *fakeResultSet) Cursor() cursors.Cursor {
+	s := rs.series[rs.i]
+	return &fakeFloatCursor{a: &cursors.FloatArray{Timestamps: s.ts, Values: s.vs}}
+}
+
+func (rs *fakeResultSet) Tags() models.Tags { return rs.series[rs.i].tags }
+func (rs *fakeResultSet) Close()            {}
+func (rs *fakeResultSet) Err() error        { return nil }
+
+type fakeFloatCursor struct {
+	a *cursors.FloatArray
+}
+
+func (c *fakeFloatCursor) Next() *cursors.FloatArray {
+	a := c.a
+	c.a = &cursors.FloatArray{}
+	return a
+}
+
+func (c *fakeFloatCursor) Close()     {}
+func (c *fakeFloatCursor) Err() error { return nil }
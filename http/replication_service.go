@@ -0,0 +1,320 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+
+	"github.com/influxdata/platform"
+	pcontext "github.com/influxdata/platform/context"
+	kerrors "github.com/influxdata/platform/kit/errors"
+	"github.com/julienschmidt/httprouter"
+)
+
+const (
+	replicationsPath = "/api/v2/replications"
+)
+
+// ReplicationHandler is the handler for the replication service
+type ReplicationHandler struct {
+	*httprouter.Router
+
+	ReplicationService platform.ReplicationService
+}
+
+// NewReplicationHandler creates a new ReplicationHandler
+func NewReplicationHandler() *ReplicationHandler {
+	h := &ReplicationHandler{
+		Router: httprouter.New(),
+	}
+
+	h.HandlerFunc("GET", replicationsPath, h.handleGetReplications)
+	h.HandlerFunc("POST", replicationsPath, h.handlePostReplication)
+	h.HandlerFunc("GET", replicationsPath+"/:id", h.handleGetReplication)
+	h.HandlerFunc("PATCH", replicationsPath+"/:id", h.handlePatchReplication)
+	h.HandlerFunc("DELETE", replicationsPath+"/:id", h.handleDeleteReplication)
+
+	return h
+}
+
+type replicationsLinks struct {
+	Self string `json:"self"`
+}
+
+type getReplicationsResponse struct {
+	Replications []replicationResponse `json:"replications"`
+	Links        replicationsLinks     `json:"links"`
+}
+
+func (r getReplicationsResponse) ToPlatform() []*platform.Replication {
+	rs := make([]*platform.Replication, len(r.Replications))
+	for i := range r.Replications {
+		rs[i] = r.Replications[i].Replication
+	}
+	return rs
+}
+
+func newGetReplicationsResponse(rs []*platform.Replication) getReplicationsResponse {
+	resp := getReplicationsResponse{
+		Replications: make([]replicationResponse, 0, len(rs)),
+		Links: replicationsLinks{
+			Self: replicationsPath,
+		},
+	}
+
+	for _, r := range rs {
+		resp.Replications = append(resp.Replications, newReplicationResponse(r))
+	}
+
+	return resp
+}
+
+type replicationLinks struct {
+	Self         string `json:"self"`
+	LocalBucket  string `json:"localBucket"`
+	Organization string `json:"organization"`
+}
+
+type replicationResponse struct {
+	*platform.Replication
+	Links replicationLinks `json:"links"`
+}
+
+// newReplicationResponse returns the response of a replication, without its remote token.
+func newReplicationResponse(r *platform.Replication) replicationResponse {
+	redacted := *r
+	redacted.RemoteToken = ""
+	return replicationResponse{
+		Replication: &redacted,
+		Links: replicationLinks{
+			Self:         replicationIDPath(r.ID),
+			LocalBucket:  fmt.Sprintf("/api/v2/buckets/%s", r.LocalBucketID),
+			Organization: fmt.Sprintf("/api/v2/orgs/%s", r.OrganizationID),
+		},
+	}
+}
+
+func (h *ReplicationHandler) handleGetReplications(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	a, err := pcontext.GetAuthorizer(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	rs, err := h.ReplicationService.FindReplications(ctx)
+	if err != nil {
+		EncodeError(ctx, kerrors.InternalErrorf("could not read replications: %v", err), w)
+		return
+	}
+
+	// Only the replications of organizations that the request may read are returned.
+	readable := rs[:0]
+	for _, rp := range rs {
+		if a.Allowed(platform.Permission{Action: platform.ReadAction, Resource: platform.ReplicationResource(rp.OrganizationID)}) {
+			readable = append(readable, rp)
+		}
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newGetReplicationsResponse(readable)); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *ReplicationHandler) handleGetReplication(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := requestID(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	rp, err := h.ReplicationService.FindReplicationByID(ctx, id)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	perm := platform.Permission{Action: platform.ReadAction, Resource: platform.ReplicationResource(rp.OrganizationID)}
+	if err := authorizeOrg(ctx, perm, "replications", rp.OrganizationID); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newReplicationResponse(rp)); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *ReplicationHandler) handlePostReplication(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	rp := &platform.Replication{}
+	if err := json.NewDecoder(r.Body).Decode(rp); err != nil {
+		EncodeError(ctx, kerrors.MalformedDataf("%v", err), w)
+		return
+	}
+	if err := rp.Valid(); err != nil {
+		EncodeError(ctx, kerrors.InvalidDataf("%v", err), w)
+		return
+	}
+
+	perm := platform.Permission{Action: platform.CreateAction, Resource: platform.ReplicationResource(rp.OrganizationID)}
+	if err := authorizeOrg(ctx, perm, "replications", rp.OrganizationID); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+	// The points of the local bucket are sent to the remote instance, so it must
+	// also be readable.
+	a, err := pcontext.GetAuthorizer(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+	if !a.Allowed(platform.ReadBucketPermission(rp.LocalBucketID)) {
+		EncodeError(ctx, &platform.Error{
+			Code: platform.EForbidden,
+			Msg:  fmt.Sprintf("insufficient permissions to read bucket %s", rp.LocalBucketID),
+		}, w)
+		return
+	}
+
+	if err := h.ReplicationService.CreateReplication(ctx, rp); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusCreated, newReplicationResponse(rp)); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *ReplicationHandler) handlePatchReplication(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := requestID(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	var upd platform.ReplicationUpdate
+	if err := json.NewDecoder(r.Body).Decode(&upd); err != nil {
+		EncodeError(ctx, kerrors.MalformedDataf("%v", err), w)
+		return
+	}
+
+	rp, err := h.ReplicationService.FindReplicationByID(ctx, id)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	perm := platform.Permission{Action: platform.WriteAction, Resource: platform.ReplicationResource(rp.OrganizationID)}
+	if err := authorizeOrg(ctx, perm, "replications", rp.OrganizationID); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	rp, err = h.ReplicationService.UpdateReplication(ctx, id, upd)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newReplicationResponse(rp)); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *ReplicationHandler) handleDeleteReplication(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := requestID(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	rp, err := h.ReplicationService.FindReplicationByID(ctx, id)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	perm := platform.Permission{Action: platform.DeleteAction, Resource: platform.ReplicationResource(rp.OrganizationID)}
+	if err := authorizeOrg(ctx, perm, "replications", rp.OrganizationID); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := h.ReplicationService.DeleteReplication(ctx, id); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ReplicationService is a replication service over HTTP to the influxdb server
+type ReplicationService struct {
+	Addr               string
+	Token              string
+	InsecureSkipVerify bool
+}
+
+// FindReplicationByID finds a single replication by its ID
+func (s *ReplicationService) FindReplicationByID(ctx context.Context, id platform.ID) (*platform.Replication, error) {
+	var lr replicationResponse
+	if err := s.do(ctx, "GET", replicationIDPath(id), nil, &lr); err != nil {
+		return nil, err
+	}
+	return lr.Replication, nil
+}
+
+// FindReplications returns all replications
+func (s *ReplicationService) FindReplications(ctx context.Context) ([]*platform.Replication, error) {
+	var lr getReplicationsResponse
+	if err := s.do(ctx, "GET", replicationsPath, nil, &lr); err != nil {
+		return nil, err
+	}
+	return lr.ToPlatform(), nil
+}
+
+// CreateReplication creates a new replication and assigns it an ID
+func (s *ReplicationService) CreateReplication(ctx context.Context, r *platform.Replication) error {
+	if err := r.Valid(); err != nil {
+		return kerrors.InvalidDataf("%v", err)
+	}
+	return s.do(ctx, "POST", replicationsPath, r, r)
+}
+
+// UpdateReplication updates a single replication with a changeset
+func (s *ReplicationService) UpdateReplication(ctx context.Context, id platform.ID, upd platform.ReplicationUpdate) (*platform.Replication, error) {
+	var r platform.Replication
+	if err := s.do(ctx, "PATCH", replicationIDPath(id), upd, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// DeleteReplication removes a replication by its ID
+func (s *ReplicationService) DeleteReplication(ctx context.Context, id platform.ID) error {
+	return s.do(ctx, "DELETE", replicationIDPath(id), nil, nil)
+}
+
+func (s *ReplicationService) do(ctx context.Context, method, p string, body, v interface{}) error {
+	return doJSON(ctx, s.Addr, s.Token, s.InsecureSkipVerify, method, p, body, v)
+}
+
+func replicationIDPath(id platform.ID) string {
+	return path.Join(replicationsPath, id.String())
+}
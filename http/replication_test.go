@@ -0,0 +1,304 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/influxdata/platform"
+	pcontext "github.com/influxdata/platform/context"
+	"github.com/influxdata/platform/inmem"
+	"github.com/influxdata/platform/mock"
+	platformtesting "github.com/influxdata/platform/testing"
+)
+
+func TestReplicationService_handleGetReplications(t *testing.T) {
+	svc := inmem.NewService()
+	rp := &platform.Replication{
+		ID:                   platformtesting.MustIDBase16("020f755c3c082000"),
+		Name:                 "cloud",
+		OrganizationID:       platformtesting.MustIDBase16("020f755c3c082001"),
+		LocalBucketID:        platformtesting.MustIDBase16("020f755c3c082002"),
+		RemoteURL:            "https://cloud:9999",
+		RemoteToken:          "token",
+		RemoteOrganizationID: platformtesting.MustIDBase16("020f755c3c082003"),
+		RemoteBucketID:       platformtesting.MustIDBase16("020f755c3c082004"),
+	}
+	if err := svc.PutReplication(context.Background(), rp); err != nil {
+		t.Fatal(err)
+	}
+
+	other := *rp
+	other.ID = platformtesting.MustIDBase16("020f755c3c082005")
+	other.OrganizationID = platformtesting.MustIDBase16("020f755c3c082006")
+	if err := svc.PutReplication(context.Background(), &other); err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewReplicationHandler()
+	h.ReplicationService = svc
+
+	// Only the replication of the organization that the request may read is returned,
+	// without its remote token.
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, withReplicationPermissions(httptest.NewRequest("GET", "http://any.url/api/v2/replications", nil), readReplications))
+
+	res := w.Result()
+	body, _ := ioutil.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("got status %d, want %d: %s", res.StatusCode, http.StatusOK, body)
+	}
+
+	want := `{"replications":[{"id":"020f755c3c082000","name":"cloud","organizationID":"020f755c3c082001","localBucketID":"020f755c3c082002","remoteURL":"https://cloud:9999","remoteOrganizationID":"020f755c3c082003","remoteBucketID":"020f755c3c082004","links":{"self":"/api/v2/replications/020f755c3c082000","localBucket":"/api/v2/buckets/020f755c3c082002","organization":"/api/v2/orgs/020f755c3c082001"}}],"links":{"self":"/api/v2/replications"}}`
+	if eq, _ := jsonEqual(string(body), want); !eq {
+		t.Errorf("got body %s, want %s", body, want)
+	}
+}
+
+func TestReplicationService_handlePostReplication(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{
+			name:   "valid replication",
+			body:   `{"name":"cloud","remoteURL":"https://cloud:9999","remoteToken":"token","organizationID":"020f755c3c082001","localBucketID":"020f755c3c082002","remoteOrganizationID":"020f755c3c082003","remoteBucketID":"020f755c3c082004"}`,
+			status: http.StatusCreated,
+		},
+		{
+			name:   "drop newest",
+			body:   `{"name":"cloud","remoteURL":"https://cloud:9999","remoteToken":"token","maxQueueSizeBytes":1048576,"dropPolicy":"newest","organizationID":"020f755c3c082001","localBucketID":"020f755c3c082002","remoteOrganizationID":"020f755c3c082003","remoteBucketID":"020f755c3c082004"}`,
+			status: http.StatusCreated,
+		},
+		{
+			name:   "unknown drop policy",
+			body:   `{"name":"cloud","remoteURL":"https://cloud:9999","remoteToken":"token","dropPolicy":"random","organizationID":"020f755c3c082001","localBucketID":"020f755c3c082002","remoteOrganizationID":"020f755c3c082003","remoteBucketID":"020f755c3c082004"}`,
+			status: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "invalid remote url",
+			body:   `{"name":"cloud","remoteURL":"cloud:9999","remoteToken":"token","organizationID":"020f755c3c082001","localBucketID":"020f755c3c082002","remoteOrganizationID":"020f755c3c082003","remoteBucketID":"020f755c3c082004"}`,
+			status: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "missing remote token",
+			body:   `{"name":"cloud","remoteURL":"https://cloud:9999","organizationID":"020f755c3c082001","localBucketID":"020f755c3c082002","remoteOrganizationID":"020f755c3c082003","remoteBucketID":"020f755c3c082004"}`,
+			status: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "malformed body",
+			body:   `{"name":`,
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := inmem.NewService()
+			svc.IDGenerator = mock.NewIDGenerator("020f755c3c082000", t)
+
+			h := NewReplicationHandler()
+			h.ReplicationService = svc
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, withReplicationPermissions(httptest.NewRequest("POST", "http://any.url/api/v2/replications", bytes.NewBufferString(tt.body)), createReplications))
+
+			res := w.Result()
+			if res.StatusCode != tt.status {
+				body, _ := ioutil.ReadAll(res.Body)
+				t.Errorf("got status %d, want %d: %s", res.StatusCode, tt.status, body)
+			}
+		})
+	}
+}
+
+func TestReplicationService_authorization(t *testing.T) {
+	svc := inmem.NewService()
+	svc.IDGenerator = mock.NewIDGenerator("020f755c3c082000", t)
+	rp := &platform.Replication{
+		ID:                   platformtesting.MustIDBase16("020f755c3c082005"),
+		Name:                 "cloud",
+		OrganizationID:       platformtesting.MustIDBase16("020f755c3c082006"),
+		LocalBucketID:        platformtesting.MustIDBase16("020f755c3c082002"),
+		RemoteURL:            "https://cloud:9999",
+		RemoteToken:          "token",
+		RemoteOrganizationID: platformtesting.MustIDBase16("020f755c3c082003"),
+		RemoteBucketID:       platformtesting.MustIDBase16("020f755c3c082004"),
+	}
+	if err := svc.PutReplication(context.Background(), rp); err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewReplicationHandler()
+	h.ReplicationService = svc
+
+	all := []platform.Permission{readReplications, createReplications, writeReplications, deleteReplications}
+	tests := []struct {
+		name   string
+		method string
+		url    string
+		body   string
+		perms  []platform.Permission
+		status int
+	}{
+		{
+			name:   "create in another organization",
+			method: "POST",
+			url:    "http://any.url/api/v2/replications",
+			body:   `{"name":"cloud","remoteURL":"https://cloud:9999","remoteToken":"token","organizationID":"020f755c3c082006","localBucketID":"020f755c3c082002","remoteOrganizationID":"020f755c3c082003","remoteBucketID":"020f755c3c082004"}`,
+			perms:  all,
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "create from a bucket that cannot be read",
+			method: "POST",
+			url:    "http://any.url/api/v2/replications",
+			body:   `{"name":"cloud","remoteURL":"https://cloud:9999","remoteToken":"token","organizationID":"020f755c3c082001","localBucketID":"020f755c3c082007","remoteOrganizationID":"020f755c3c082003","remoteBucketID":"020f755c3c082004"}`,
+			perms:  all,
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "read a replication of another organization",
+			method: "GET",
+			url:    "http://any.url/api/v2/replications/020f755c3c082005",
+			perms:  all,
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "update a replication of another organization",
+			method: "PATCH",
+			url:    "http://any.url/api/v2/replications/020f755c3c082005",
+			body:   `{"remoteURL":"https://attacker:9999"}`,
+			perms:  all,
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "delete a replication of another organization",
+			method: "DELETE",
+			url:    "http://any.url/api/v2/replications/020f755c3c082005",
+			perms:  all,
+			status: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, withReplicationPermissions(httptest.NewRequest(tt.method, tt.url, bytes.NewBufferString(tt.body)), tt.perms...))
+
+			res := w.Result()
+			if res.StatusCode != tt.status {
+				body, _ := ioutil.ReadAll(res.Body)
+				t.Errorf("got status %d, want %d: %s", res.StatusCode, tt.status, body)
+			}
+		})
+	}
+
+	got, err := svc.FindReplicationByID(context.Background(), rp.ID)
+	if err != nil {
+		t.Fatalf("replication of another organization was deleted: %v", err)
+	}
+	if got.RemoteURL != rp.RemoteURL {
+		t.Errorf("replication of another organization was updated to %s", got.RemoteURL)
+	}
+}
+
+func TestReplicationService_handlePatchReplication(t *testing.T) {
+	svc := inmem.NewService()
+	rp := &platform.Replication{
+		ID:                   platformtesting.MustIDBase16("020f755c3c082000"),
+		Name:                 "cloud",
+		OrganizationID:       platformtesting.MustIDBase16("020f755c3c082001"),
+		LocalBucketID:        platformtesting.MustIDBase16("020f755c3c082002"),
+		RemoteURL:            "https://cloud:9999",
+		RemoteToken:          "token",
+		RemoteOrganizationID: platformtesting.MustIDBase16("020f755c3c082003"),
+		RemoteBucketID:       platformtesting.MustIDBase16("020f755c3c082004"),
+	}
+	if err := svc.PutReplication(context.Background(), rp); err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewReplicationHandler()
+	h.ReplicationService = svc
+
+	// The token is kept by an update that does not set it, and is not returned.
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, withReplicationPermissions(httptest.NewRequest("PATCH", "http://any.url/api/v2/replications/020f755c3c082000", bytes.NewBufferString(`{"remoteURL":"https://other:9999"}`)), writeReplications))
+
+	res := w.Result()
+	body, _ := ioutil.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("got status %d, want %d: %s", res.StatusCode, http.StatusOK, body)
+	}
+	want := `{"id":"020f755c3c082000","name":"cloud","organizationID":"020f755c3c082001","localBucketID":"020f755c3c082002","remoteURL":"https://other:9999","remoteOrganizationID":"020f755c3c082003","remoteBucketID":"020f755c3c082004","links":{"self":"/api/v2/replications/020f755c3c082000","localBucket":"/api/v2/buckets/020f755c3c082002","organization":"/api/v2/orgs/020f755c3c082001"}}`
+	if eq, _ := jsonEqual(string(body), want); !eq {
+		t.Errorf("got body %s, want %s", body, want)
+	}
+
+	got, err := svc.FindReplicationByID(context.Background(), rp.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.RemoteToken != "token" {
+		t.Errorf("got remote token %q, want %q", got.RemoteToken, "token")
+	}
+}
+
+// withReplicationPermissions returns r with an authorizer allowed the actions of perms
+// on the replications of organization 020f755c3c082001 and, for the conformance tests,
+// 020f755c3c082000, and allowed to read bucket 020f755c3c082002 and, for the
+// conformance tests, 020f755c3c082000.
+func withReplicationPermissions(r *http.Request, perms ...platform.Permission) *http.Request {
+	a := &platform.Authorization{Status: platform.Active}
+	for _, org := range []string{"020f755c3c082000", "020f755c3c082001"} {
+		for _, p := range perms {
+			p.Resource = platform.ReplicationResource(platformtesting.MustIDBase16(org))
+			a.Permissions = append(a.Permissions, p)
+		}
+	}
+	for _, bucket := range []string{"020f755c3c082000", "020f755c3c082002"} {
+		a.Permissions = append(a.Permissions, platform.ReadBucketPermission(platformtesting.MustIDBase16(bucket)))
+	}
+	return r.WithContext(pcontext.SetAuthorizer(r.Context(), a))
+}
+
+var (
+	readReplications   = platform.Permission{Action: platform.ReadAction}
+	createReplications = platform.Permission{Action: platform.CreateAction}
+	writeReplications  = platform.Permission{Action: platform.WriteAction}
+	deleteReplications = platform.Permission{Action: platform.DeleteAction}
+)
+
+func initReplicationService(f platformtesting.ReplicationFields, t *testing.T) (platform.ReplicationService, func()) {
+	t.Helper()
+	svc := inmem.NewService()
+	svc.IDGenerator = f.IDGenerator
+
+	ctx := context.Background()
+	for _, rp := range f.Replications {
+		if err := svc.PutReplication(ctx, rp); err != nil {
+			t.Fatalf("failed to populate replications")
+		}
+	}
+
+	handler := NewReplicationHandler()
+	handler.ReplicationService = svc
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.ServeHTTP(w, withReplicationPermissions(r, readReplications, createReplications, writeReplications, deleteReplications))
+	}))
+	client := ReplicationService{
+		Addr: server.URL,
+	}
+	done := server.Close
+
+	return &client, done
+}
+
+func TestReplicationService(t *testing.T) {
+	platformtesting.RedactedReplicationService(initReplicationService, t)
+}
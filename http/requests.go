@@ -2,9 +2,13 @@ package http
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 
 	"github.com/influxdata/platform"
+	pcontext "github.com/influxdata/platform/context"
+	kerrors "github.com/influxdata/platform/kit/errors"
+	"github.com/julienschmidt/httprouter"
 )
 
 const (
@@ -30,3 +34,34 @@ func queryOrganization(ctx context.Context, r *http.Request, svc platform.Organi
 
 	return svc.FindOrganization(ctx, filter)
 }
+
+// requestID returns the ID of the id parameter of the route of a request.
+func requestID(ctx context.Context) (platform.ID, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	urlID := params.ByName("id")
+	if urlID == "" {
+		return platform.InvalidID(), kerrors.InvalidDataf("url missing id")
+	}
+
+	id, err := platform.IDFromString(urlID)
+	if err != nil {
+		return platform.InvalidID(), kerrors.InvalidDataf("invalid id: %v", err)
+	}
+	return *id, nil
+}
+
+// authorizeOrg returns an error if the authorizer of ctx is not allowed perm, which is
+// on the resources of a kind, such as "listeners", of an organization.
+func authorizeOrg(ctx context.Context, perm platform.Permission, kind string, orgID platform.ID) error {
+	a, err := pcontext.GetAuthorizer(ctx)
+	if err != nil {
+		return err
+	}
+	if !a.Allowed(perm) {
+		return &platform.Error{
+			Code: platform.EForbidden,
+			Msg:  fmt.Sprintf("insufficient permissions to %s the %s of organization %s", perm.Action, kind, orgID),
+		}
+	}
+	return nil
+}
@@ -6,16 +6,21 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"path"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/influxdata/flux/csv"
 	"github.com/influxdata/platform"
 	pcontext "github.com/influxdata/platform/context"
 	kerrors "github.com/influxdata/platform/kit/errors"
+	"github.com/influxdata/platform/query"
 	"github.com/influxdata/platform/task/backend"
+	"github.com/influxdata/platform/task/backend/executor"
 	"github.com/julienschmidt/httprouter"
 	"go.uber.org/zap"
 )
@@ -29,6 +34,9 @@ type TaskHandler struct {
 	AuthorizationService       platform.AuthorizationService
 	OrganizationService        platform.OrganizationService
 	UserResourceMappingService platform.UserResourceMappingService
+
+	// QueryService executes task scripts for previews.
+	QueryService query.QueryService
 }
 
 const (
@@ -43,6 +51,13 @@ const (
 	tasksIDRunsIDPath      = "/api/v2/tasks/:tid/runs/:rid"
 	tasksIDRunsIDLogsPath  = "/api/v2/tasks/:tid/runs/:rid/logs"
 	tasksIDRunsIDRetryPath = "/api/v2/tasks/:tid/runs/:rid/retry"
+
+	tasksIDRevisionsPath           = "/api/v2/tasks/:tid/revisions"
+	tasksIDRevisionsIDPath         = "/api/v2/tasks/:tid/revisions/:rev"
+	tasksIDRevisionsIDRollbackPath = "/api/v2/tasks/:tid/revisions/:rev/rollback"
+
+	tasksIDPreviewPath = "/api/v2/tasks/:tid/preview"
+	tasksIDStatsPath   = "/api/v2/tasks/:tid/stats"
 )
 
 // NewTaskHandler returns a new instance of TaskHandler.
@@ -56,6 +71,8 @@ func NewTaskHandler(mappingService platform.UserResourceMappingService, logger *
 
 	h.HandlerFunc("GET", tasksPath, h.handleGetTasks)
 	h.HandlerFunc("POST", tasksPath, h.handlePostTask)
+	h.HandlerFunc("PATCH", tasksPath, h.handleUpdateTasks)
+	h.HandlerFunc("DELETE", tasksPath, h.handleDeleteTasks)
 
 	h.HandlerFunc("GET", tasksIDPath, h.handleGetTask)
 	h.HandlerFunc("PATCH", tasksIDPath, h.handleUpdateTask)
@@ -77,6 +94,13 @@ func NewTaskHandler(mappingService platform.UserResourceMappingService, logger *
 	h.HandlerFunc("POST", tasksIDRunsIDRetryPath, h.handleRetryRun)
 	h.HandlerFunc("DELETE", tasksIDRunsIDPath, h.handleCancelRun)
 
+	h.HandlerFunc("GET", tasksIDRevisionsPath, h.handleGetRevisions)
+	h.HandlerFunc("GET", tasksIDRevisionsIDPath, h.handleGetRevision)
+	h.HandlerFunc("POST", tasksIDRevisionsIDRollbackPath, h.handleRollbackTask)
+
+	h.HandlerFunc("POST", tasksIDPreviewPath, h.handlePreviewTask)
+	h.HandlerFunc("GET", tasksIDStatsPath, h.handleGetTaskStats)
+
 	return h
 }
 
@@ -88,11 +112,13 @@ type taskResponse struct {
 func newTaskResponse(t platform.Task) taskResponse {
 	return taskResponse{
 		Links: map[string]string{
-			"self":    fmt.Sprintf("/api/v2/tasks/%s", t.ID),
-			"members": fmt.Sprintf("/api/v2/tasks/%s/members", t.ID),
-			"owners":  fmt.Sprintf("/api/v2/tasks/%s/owners", t.ID),
-			"runs":    fmt.Sprintf("/api/v2/tasks/%s/runs", t.ID),
-			"logs":    fmt.Sprintf("/api/v2/tasks/%s/logs", t.ID),
+			"self":      fmt.Sprintf("/api/v2/tasks/%s", t.ID),
+			"members":   fmt.Sprintf("/api/v2/tasks/%s/members", t.ID),
+			"owners":    fmt.Sprintf("/api/v2/tasks/%s/owners", t.ID),
+			"runs":      fmt.Sprintf("/api/v2/tasks/%s/runs", t.ID),
+			"logs":      fmt.Sprintf("/api/v2/tasks/%s/logs", t.ID),
+			"revisions": fmt.Sprintf("/api/v2/tasks/%s/revisions", t.ID),
+			"stats":     fmt.Sprintf("/api/v2/tasks/%s/stats", t.ID),
 		},
 		Task: t,
 	}
@@ -172,6 +198,42 @@ func newRunsResponse(rs []*platform.Run, taskID platform.ID) runsResponse {
 	}
 }
 
+type revisionResponse struct {
+	Links map[string]string `json:"links"`
+	platform.TaskRevision
+}
+
+func newRevisionResponse(r platform.TaskRevision) revisionResponse {
+	return revisionResponse{
+		Links: map[string]string{
+			"self":     fmt.Sprintf("/api/v2/tasks/%s/revisions/%d", r.TaskID, r.Revision),
+			"task":     fmt.Sprintf("/api/v2/tasks/%s", r.TaskID),
+			"rollback": fmt.Sprintf("/api/v2/tasks/%s/revisions/%d/rollback", r.TaskID, r.Revision),
+		},
+		TaskRevision: r,
+	}
+}
+
+type revisionsResponse struct {
+	Links     map[string]string  `json:"links"`
+	Revisions []revisionResponse `json:"revisions"`
+}
+
+func newRevisionsResponse(rs []*platform.TaskRevision, taskID platform.ID) revisionsResponse {
+	res := revisionsResponse{
+		Links: map[string]string{
+			"self": fmt.Sprintf("/api/v2/tasks/%s/revisions", taskID),
+			"task": fmt.Sprintf("/api/v2/tasks/%s", taskID),
+		},
+		Revisions: make([]revisionResponse, len(rs)),
+	}
+
+	for i := range rs {
+		res.Revisions[i] = newRevisionResponse(*rs[i])
+	}
+	return res
+}
+
 func (h *TaskHandler) handleGetTasks(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -225,6 +287,175 @@ func decodeGetTasksRequest(ctx context.Context, r *http.Request) (*getTasksReque
 		req.filter.User = id
 	}
 
+	if name := qp.Get("name"); name != "" {
+		req.filter.Name = &name
+	}
+
+	if status := qp.Get("status"); status != "" {
+		req.filter.Status = &status
+	}
+
+	if labels := qp["label"]; len(labels) > 0 {
+		req.filter.Labels = make(map[string]string, len(labels))
+		for _, l := range labels {
+			kv := strings.SplitN(l, "=", 2)
+			if len(kv) != 2 || kv[0] == "" {
+				return nil, kerrors.InvalidDataf("label %q must be formatted as key=value", l)
+			}
+			req.filter.Labels[kv[0]] = kv[1]
+		}
+	}
+
+	return req, nil
+}
+
+// authorizeBulk checks that the authorizer of ctx has perm on the tasks of the organization
+// of a bulk request. Bulk requests are always scoped to a single organization.
+func (r *getTasksRequest) authorizeBulk(ctx context.Context, perm platform.Permission) error {
+	a, err := pcontext.GetAuthorizer(ctx)
+	if err != nil {
+		return err
+	}
+	if !a.Allowed(perm) {
+		return &platform.Error{
+			Code: platform.EForbidden,
+			Msg:  fmt.Sprintf("insufficient permissions to %s the tasks of organization %s", perm.Action, r.filter.Organization),
+		}
+	}
+	return nil
+}
+
+// forEachTask calls fn for every task matching filter, following pages of FindTasks.
+// Tasks are visited in ID order, so fn may update or delete the task it is given.
+func (h *TaskHandler) forEachTask(ctx context.Context, filter platform.TaskFilter, fn func(*platform.Task) error) error {
+	for {
+		tasks, _, err := h.TaskService.FindTasks(ctx, filter)
+		if err != nil {
+			return err
+		}
+		if len(tasks) == 0 {
+			return nil
+		}
+
+		for _, t := range tasks {
+			if err := fn(t); err != nil {
+				return err
+			}
+		}
+
+		after := tasks[len(tasks)-1].ID
+		filter.After = &after
+	}
+}
+
+func (h *TaskHandler) handleUpdateTasks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodeUpdateTasksRequest(ctx, r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	perm := platform.Permission{Action: platform.WriteAction, Resource: platform.TaskResource(*req.filter.Organization)}
+	if err := req.authorizeBulk(ctx, perm); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	var updated []*platform.Task
+	err = h.forEachTask(ctx, req.filter, func(t *platform.Task) error {
+		task, err := h.TaskService.UpdateTask(ctx, t.ID, req.Update)
+		if err != nil {
+			return err
+		}
+		updated = append(updated, task)
+		return nil
+	})
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newTasksResponse(updated)); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+}
+
+type updateTasksRequest struct {
+	getTasksRequest
+	Update platform.TaskUpdate
+}
+
+func decodeUpdateTasksRequest(ctx context.Context, r *http.Request) (*updateTasksRequest, error) {
+	gr, err := decodeGetTasksRequest(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	if gr.filter.Organization == nil {
+		return nil, kerrors.InvalidDataf("you must provide an organization to update tasks in bulk")
+	}
+
+	var upd platform.TaskUpdate
+	if err := json.NewDecoder(r.Body).Decode(&upd); err != nil {
+		return nil, err
+	}
+	if upd.Flux != nil {
+		return nil, kerrors.InvalidDataf("the script of tasks cannot be updated in bulk")
+	}
+	if upd.Status == nil && upd.Labels == nil {
+		return nil, kerrors.InvalidDataf("you must provide a status or labels to update tasks in bulk")
+	}
+
+	return &updateTasksRequest{
+		getTasksRequest: *gr,
+		Update:          upd,
+	}, nil
+}
+
+func (h *TaskHandler) handleDeleteTasks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodeDeleteTasksRequest(ctx, r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	perm := platform.Permission{Action: platform.DeleteAction, Resource: platform.TaskResource(*req.filter.Organization)}
+	if err := req.authorizeBulk(ctx, perm); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	var deleted []*platform.Task
+	err = h.forEachTask(ctx, req.filter, func(t *platform.Task) error {
+		if err := h.TaskService.DeleteTask(ctx, t.ID); err != nil {
+			return err
+		}
+		deleted = append(deleted, t)
+		return nil
+	})
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newTasksResponse(deleted)); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+}
+
+func decodeDeleteTasksRequest(ctx context.Context, r *http.Request) (*getTasksRequest, error) {
+	req, err := decodeGetTasksRequest(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	if req.filter.Organization == nil {
+		return nil, kerrors.InvalidDataf("you must provide an organization to delete tasks in bulk")
+	}
 	return req, nil
 }
 
@@ -732,6 +963,255 @@ func decodeRetryRunRequest(ctx context.Context, r *http.Request) (*retryRunReque
 	}, nil
 }
 
+func (h *TaskHandler) handleGetRevisions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodeGetTaskRequest(ctx, r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	revs, _, err := h.TaskService.FindTaskRevisions(ctx, req.TaskID)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newRevisionsResponse(revs, req.TaskID)); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+}
+
+// taskStatsResponse encodes the durations and times of platform.TaskStats as strings.
+type taskStatsResponse struct {
+	Links map[string]string `json:"links"`
+	platform.TaskStats
+	LastRunDuration string `json:"lastRunDuration"`
+	MeanRunDuration string `json:"meanRunDuration"`
+	MaxRunDuration  string `json:"maxRunDuration"`
+	LastLag         string `json:"lastLag"`
+	MaxLag          string `json:"maxLag"`
+	LastFinishedAt  string `json:"lastFinishedAt,omitempty"`
+}
+
+func newTaskStatsResponse(s platform.TaskStats) taskStatsResponse {
+	res := taskStatsResponse{
+		Links: map[string]string{
+			"self": fmt.Sprintf("/api/v2/tasks/%s/stats", s.TaskID),
+			"task": fmt.Sprintf("/api/v2/tasks/%s", s.TaskID),
+		},
+		TaskStats:       s,
+		LastRunDuration: s.LastRunDuration.String(),
+		MeanRunDuration: s.MeanRunDuration.String(),
+		MaxRunDuration:  s.MaxRunDuration.String(),
+		LastLag:         s.LastLag.String(),
+		MaxLag:          s.MaxLag.String(),
+	}
+	if !s.LastFinishedAt.IsZero() {
+		res.LastFinishedAt = s.LastFinishedAt.UTC().Format(time.RFC3339Nano)
+	}
+	return res
+}
+
+// toTaskStats decodes the durations and times of r into its TaskStats.
+func (r taskStatsResponse) toTaskStats() (*platform.TaskStats, error) {
+	s := r.TaskStats
+	for _, d := range []struct {
+		s string
+		d *time.Duration
+	}{
+		{r.LastRunDuration, &s.LastRunDuration},
+		{r.MeanRunDuration, &s.MeanRunDuration},
+		{r.MaxRunDuration, &s.MaxRunDuration},
+		{r.LastLag, &s.LastLag},
+		{r.MaxLag, &s.MaxLag},
+	} {
+		var err error
+		if *d.d, err = time.ParseDuration(d.s); err != nil {
+			return nil, err
+		}
+	}
+	if r.LastFinishedAt != "" {
+		t, err := time.Parse(time.RFC3339Nano, r.LastFinishedAt)
+		if err != nil {
+			return nil, err
+		}
+		s.LastFinishedAt = t
+	}
+	return &s, nil
+}
+
+func (h *TaskHandler) handleGetTaskStats(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodeGetTaskRequest(ctx, r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	stats, err := h.TaskService.FindTaskStats(ctx, req.TaskID)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newTaskStatsResponse(*stats)); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *TaskHandler) handleGetRevision(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodeRevisionRequest(ctx, r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	rev, err := h.TaskService.FindTaskRevision(ctx, req.TaskID, req.Revision)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newRevisionResponse(*rev)); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *TaskHandler) handleRollbackTask(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodeRevisionRequest(ctx, r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	task, err := h.TaskService.RollbackTask(ctx, req.TaskID, req.Revision)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newTaskResponse(*task)); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *TaskHandler) handlePreviewTask(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodePreviewTaskRequest(ctx, r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	task, err := h.TaskService.FindTaskByID(ctx, req.TaskID)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	st := &backend.StoreTask{
+		ID:       task.ID,
+		Org:      task.Organization,
+		User:     task.Owner.ID,
+		Name:     task.Name,
+		Script:   task.Flux,
+		Revision: task.Revision,
+	}
+	results, err := executor.Preview(ctx, h.QueryService, st, req.Now)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+	defer results.Release()
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	encoder := csv.NewMultiResultEncoder(csv.DefaultEncoderConfig())
+	n, err := encoder.Encode(w, results)
+	if err != nil {
+		if n == 0 {
+			// Only record the error headers IFF nothing has been written to w.
+			EncodeError(ctx, err, w)
+			return
+		}
+		h.logger.Info("Error writing task preview to client", zap.Error(err))
+	}
+}
+
+type previewTaskRequest struct {
+	TaskID platform.ID
+	Now    time.Time
+}
+
+func decodePreviewTaskRequest(ctx context.Context, r *http.Request) (*previewTaskRequest, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	tid := params.ByName("tid")
+	if tid == "" {
+		return nil, kerrors.InvalidDataf("you must provide a task ID")
+	}
+
+	var ti platform.ID
+	if err := ti.DecodeFromString(tid); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if n := r.URL.Query().Get("now"); n != "" {
+		t, err := time.Parse(time.RFC3339, n)
+		if err != nil {
+			return nil, kerrors.InvalidDataf("now must be an RFC3339 time: %v", err)
+		}
+		now = t
+	}
+
+	return &previewTaskRequest{
+		TaskID: ti,
+		Now:    now,
+	}, nil
+}
+
+type revisionRequest struct {
+	TaskID   platform.ID
+	Revision int
+}
+
+func decodeRevisionRequest(ctx context.Context, r *http.Request) (*revisionRequest, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	tid := params.ByName("tid")
+	if tid == "" {
+		return nil, kerrors.InvalidDataf("you must provide a task ID")
+	}
+	rev := params.ByName("rev")
+	if rev == "" {
+		return nil, kerrors.InvalidDataf("you must provide a revision")
+	}
+
+	var ti platform.ID
+	if err := ti.DecodeFromString(tid); err != nil {
+		return nil, err
+	}
+	revision, err := strconv.Atoi(rev)
+	if err != nil || revision < 1 {
+		return nil, kerrors.InvalidDataf("revision must be a positive integer")
+	}
+
+	return &revisionRequest{
+		TaskID:   ti,
+		Revision: revision,
+	}, nil
+}
+
 // TaskService connects to Influx via HTTP using tokens to manage tasks.
 type TaskService struct {
 	Addr               string
@@ -784,18 +1264,7 @@ func (t TaskService) FindTasks(ctx context.Context, filter platform.TaskFilter)
 		return nil, 0, err
 	}
 
-	val := url.Values{}
-	if filter.After != nil {
-		val.Add("after", filter.After.String())
-	}
-	if filter.Organization != nil {
-		val.Add("organization", filter.Organization.String())
-	}
-	if filter.User != nil {
-		val.Add("user", filter.User.String())
-	}
-
-	u.RawQuery = val.Encode()
+	u.RawQuery = taskFilterValues(filter).Encode()
 
 	req, err := http.NewRequest("GET", u.String(), nil)
 	if err != nil {
@@ -826,6 +1295,84 @@ func (t TaskService) FindTasks(ctx context.Context, filter platform.TaskFilter)
 	return tasks, len(tasks), nil
 }
 
+// UpdateTasks applies upd to every task matching filter and returns the updated tasks.
+// The script of tasks cannot be updated in bulk.
+func (t TaskService) UpdateTasks(ctx context.Context, filter platform.TaskFilter, upd platform.TaskUpdate) ([]*platform.Task, error) {
+	b, err := json.Marshal(upd)
+	if err != nil {
+		return nil, err
+	}
+	return t.bulkTasks(ctx, "PATCH", filter, bytes.NewReader(b))
+}
+
+// DeleteTasks deletes every task matching filter and returns the deleted tasks.
+func (t TaskService) DeleteTasks(ctx context.Context, filter platform.TaskFilter) ([]*platform.Task, error) {
+	return t.bulkTasks(ctx, "DELETE", filter, nil)
+}
+
+func (t TaskService) bulkTasks(ctx context.Context, method string, filter platform.TaskFilter, body io.Reader) ([]*platform.Task, error) {
+	u, err := newURL(t.Addr, tasksPath)
+	if err != nil {
+		return nil, err
+	}
+	u.RawQuery = taskFilterValues(filter).Encode()
+
+	req, err := http.NewRequest(method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	SetToken(t.Token, req)
+
+	hc := newClient(u.Scheme, t.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var tr tasksResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tr); err != nil {
+		return nil, err
+	}
+
+	tasks := make([]*platform.Task, len(tr.Tasks))
+	for i := range tr.Tasks {
+		tasks[i] = &tr.Tasks[i].Task
+	}
+	return tasks, nil
+}
+
+// taskFilterValues encodes filter as the query parameters of the tasks endpoints.
+func taskFilterValues(filter platform.TaskFilter) url.Values {
+	val := url.Values{}
+	if filter.After != nil {
+		val.Add("after", filter.After.String())
+	}
+	if filter.Organization != nil {
+		val.Add("organization", filter.Organization.String())
+	}
+	if filter.User != nil {
+		val.Add("user", filter.User.String())
+	}
+	if filter.Name != nil {
+		val.Add("name", *filter.Name)
+	}
+	if filter.Status != nil {
+		val.Add("status", *filter.Status)
+	}
+	for k, v := range filter.Labels {
+		val.Add("label", k+"="+v)
+	}
+	return val
+}
+
 // CreateTask creates a new task.
 func (t TaskService) CreateTask(ctx context.Context, tsk *platform.Task) error {
 	u, err := newURL(t.Addr, tasksPath)
@@ -1114,6 +1661,188 @@ func (t TaskService) RetryRun(ctx context.Context, taskID, runID platform.ID, re
 	return nil
 }
 
+// FindTaskRevisions returns all recorded revisions of a task's script, oldest first.
+func (t TaskService) FindTaskRevisions(ctx context.Context, taskID platform.ID) ([]*platform.TaskRevision, int, error) {
+	u, err := newURL(t.Addr, taskIDRevisionsPath(taskID))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	SetToken(t.Token, req)
+
+	hc := newClient(u.Scheme, t.InsecureSkipVerify)
+
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		if err.Error() == backend.ErrTaskNotFound.Error() {
+			return nil, 0, backend.ErrTaskNotFound
+		}
+		return nil, 0, err
+	}
+
+	var rs revisionsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&rs); err != nil {
+		return nil, 0, err
+	}
+
+	revs := make([]*platform.TaskRevision, len(rs.Revisions))
+	for i := range rs.Revisions {
+		revs[i] = &rs.Revisions[i].TaskRevision
+	}
+	return revs, len(revs), nil
+}
+
+// FindTaskStats returns statistics about the runs of a task.
+func (t TaskService) FindTaskStats(ctx context.Context, taskID platform.ID) (*platform.TaskStats, error) {
+	u, err := newURL(t.Addr, taskIDStatsPath(taskID))
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	SetToken(t.Token, req)
+
+	hc := newClient(u.Scheme, t.InsecureSkipVerify)
+
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		if err.Error() == backend.ErrTaskNotFound.Error() {
+			return nil, backend.ErrTaskNotFound
+		}
+		return nil, err
+	}
+
+	var rs taskStatsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&rs); err != nil {
+		return nil, err
+	}
+	return rs.toTaskStats()
+}
+
+// FindTaskRevision returns a single revision of a task's script.
+func (t TaskService) FindTaskRevision(ctx context.Context, taskID platform.ID, revision int) (*platform.TaskRevision, error) {
+	u, err := newURL(t.Addr, taskIDRevisionIDPath(taskID, revision))
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	SetToken(t.Token, req)
+
+	hc := newClient(u.Scheme, t.InsecureSkipVerify)
+
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		if err.Error() == backend.ErrRevisionNotFound.Error() {
+			// ErrRevisionNotFound is expected as part of the FindTaskRevision contract,
+			// so return that actual error instead of a different error that looks like it.
+			return nil, backend.ErrRevisionNotFound
+		}
+		return nil, err
+	}
+
+	var r revisionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, err
+	}
+	return &r.TaskRevision, nil
+}
+
+// RollbackTask replaces a task's script with the script of an earlier revision.
+func (t TaskService) RollbackTask(ctx context.Context, taskID platform.ID, revision int) (*platform.Task, error) {
+	u, err := newURL(t.Addr, path.Join(taskIDRevisionIDPath(taskID, revision), "rollback"))
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	SetToken(t.Token, req)
+
+	hc := newClient(u.Scheme, t.InsecureSkipVerify)
+
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		if err.Error() == backend.ErrRevisionNotFound.Error() {
+			return nil, backend.ErrRevisionNotFound
+		}
+		return nil, err
+	}
+
+	var tr taskResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tr); err != nil {
+		return nil, err
+	}
+	return &tr.Task, nil
+}
+
+// PreviewTask executes the script of the task as of now without writing any data,
+// and copies the resulting tables to w as annotated CSV.
+func (t TaskService) PreviewTask(ctx context.Context, id platform.ID, now time.Time, w io.Writer) error {
+	u, err := newURL(t.Addr, path.Join(taskIDPath(id), "preview"))
+	if err != nil {
+		return err
+	}
+
+	val := url.Values{}
+	val.Set("now", now.Format(time.RFC3339))
+	u.RawQuery = val.Encode()
+
+	req, err := http.NewRequest("POST", u.String(), nil)
+	if err != nil {
+		return err
+	}
+	SetToken(t.Token, req)
+
+	hc := newClient(u.Scheme, t.InsecureSkipVerify)
+
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Invalid scripts are reported as platform errors.
+	if err := CheckError(resp, resp.Header.Get(PlatformErrorCodeHeader) != ""); err != nil {
+		return err
+	}
+
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
 func cancelPath(taskID, runID platform.ID) string {
 	return path.Join(taskID.String(), runID.String())
 }
@@ -1157,3 +1886,15 @@ func taskIDRunsPath(id platform.ID) string {
 func taskIDRunIDPath(taskID, runID platform.ID) string {
 	return path.Join(tasksPath, taskID.String(), "runs", runID.String())
 }
+
+func taskIDStatsPath(id platform.ID) string {
+	return path.Join(tasksPath, id.String(), "stats")
+}
+
+func taskIDRevisionsPath(id platform.ID) string {
+	return path.Join(tasksPath, id.String(), "revisions")
+}
+
+func taskIDRevisionIDPath(taskID platform.ID, revision int) string {
+	return path.Join(tasksPath, taskID.String(), "revisions", strconv.Itoa(revision))
+}
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"reflect"
 	"testing"
 
 	"github.com/influxdata/platform"
@@ -15,6 +16,7 @@ import (
 	"github.com/influxdata/platform/logger"
 	"github.com/influxdata/platform/mock"
 	_ "github.com/influxdata/platform/query/builtin"
+	platformtesting "github.com/influxdata/platform/testing"
 )
 
 func TestTaskHandler_handleGetTasks(t *testing.T) {
@@ -70,7 +72,9 @@ func TestTaskHandler_handleGetTasks(t *testing.T) {
         "owners": "/api/v2/tasks/0000000000000001/owners",
         "members": "/api/v2/tasks/0000000000000001/members",
         "runs": "/api/v2/tasks/0000000000000001/runs",
-        "logs": "/api/v2/tasks/0000000000000001/logs"
+        "logs": "/api/v2/tasks/0000000000000001/logs",
+        "revisions": "/api/v2/tasks/0000000000000001/revisions",
+        "stats": "/api/v2/tasks/0000000000000001/stats"
       },
       "id": "0000000000000001",
       "name": "task1",
@@ -88,7 +92,9 @@ func TestTaskHandler_handleGetTasks(t *testing.T) {
         "owners": "/api/v2/tasks/0000000000000002/owners",
         "members": "/api/v2/tasks/0000000000000002/members",
         "runs": "/api/v2/tasks/0000000000000002/runs",
-        "logs": "/api/v2/tasks/0000000000000002/logs"
+        "logs": "/api/v2/tasks/0000000000000002/logs",
+        "revisions": "/api/v2/tasks/0000000000000002/revisions",
+        "stats": "/api/v2/tasks/0000000000000002/stats"
       },
       "id": "0000000000000002",
       "name": "task2",
@@ -181,7 +187,9 @@ func TestTaskHandler_handlePostTasks(t *testing.T) {
     "owners": "/api/v2/tasks/0000000000000001/owners",
     "members": "/api/v2/tasks/0000000000000001/members",
     "runs": "/api/v2/tasks/0000000000000001/runs",
-    "logs": "/api/v2/tasks/0000000000000001/logs"
+    "logs": "/api/v2/tasks/0000000000000001/logs",
+    "revisions": "/api/v2/tasks/0000000000000001/revisions",
+    "stats": "/api/v2/tasks/0000000000000001/stats"
   },
   "id": "0000000000000001",
   "name": "task1",
@@ -231,3 +239,190 @@ func TestTaskHandler_handlePostTasks(t *testing.T) {
 		})
 	}
 }
+
+func TestTaskHandler_handlePreviewTask(t *testing.T) {
+	taskService := &mock.TaskService{
+		FindTaskByIDFn: func(ctx context.Context, id platform.ID) (*platform.Task, error) {
+			return &platform.Task{
+				ID:           id,
+				Organization: 1,
+				Flux:         `option task = {name: "invalid"} from(bucket: "b") |> range(start: -1m)`,
+			}, nil
+		},
+	}
+
+	r := httptest.NewRequest("POST", "http://any.url/api/v2/tasks/0000000000000001/preview", nil)
+	w := httptest.NewRecorder()
+
+	h := NewTaskHandler(mock.NewUserResourceMappingService(), logger.New(os.Stdout))
+	h.TaskService = taskService
+	h.ServeHTTP(w, r)
+
+	res := w.Result()
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("handlePreviewTask() = %v, want %v", res.StatusCode, http.StatusBadRequest)
+	}
+	if code := res.Header.Get(PlatformErrorCodeHeader); code != platform.EInvalid {
+		t.Fatalf("handlePreviewTask() error code = %q, want %q", code, platform.EInvalid)
+	}
+}
+
+// serveBulkTaskRequest serves a bulk tasks request with an authorizer holding permissions.
+func serveBulkTaskRequest(h *TaskHandler, method, url, body string, permissions []platform.Permission) *http.Response {
+	r := httptest.NewRequest(method, url, bytes.NewBufferString(body))
+	r = r.WithContext(pcontext.SetAuthorizer(r.Context(), &platform.Authorization{
+		Status:      platform.Active,
+		Permissions: permissions,
+	}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w.Result()
+}
+
+func TestTaskHandler_handleDeleteTasks(t *testing.T) {
+	var orgID = platformtesting.MustIDBase16("aaaaaaaaaaaaaaaa")
+	var deleted []platform.ID
+	taskService := &mock.TaskService{
+		FindTasksFn: func(ctx context.Context, f platform.TaskFilter) ([]*platform.Task, int, error) {
+			if f.After != nil {
+				return nil, 0, nil
+			}
+			if f.Organization == nil || *f.Organization != orgID {
+				t.Fatalf("expected organization filter %s, got %v", orgID, f.Organization)
+			}
+			if f.Labels["env"] != "dev" {
+				t.Fatalf("expected label filter env=dev, got %v", f.Labels)
+			}
+			return []*platform.Task{{ID: 1, Name: "task1"}, {ID: 2, Name: "task2"}}, 2, nil
+		},
+		DeleteTaskFn: func(ctx context.Context, id platform.ID) error {
+			deleted = append(deleted, id)
+			return nil
+		},
+	}
+
+	h := NewTaskHandler(mock.NewUserResourceMappingService(), logger.New(os.Stdout))
+	h.TaskService = taskService
+
+	allowed := []platform.Permission{{Action: platform.DeleteAction, Resource: platform.TaskResource(orgID)}}
+
+	tests := []struct {
+		name        string
+		url         string
+		permissions []platform.Permission
+		status      int
+		deleted     []platform.ID
+	}{
+		{
+			name:        "without an organization",
+			url:         "http://any.url/api/v2/tasks?label=env=dev",
+			permissions: allowed,
+			status:      http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "without permission",
+			url:    "http://any.url/api/v2/tasks?organization=aaaaaaaaaaaaaaaa&label=env=dev",
+			status: http.StatusForbidden,
+		},
+		{
+			name:        "with a label filter",
+			url:         "http://any.url/api/v2/tasks?organization=aaaaaaaaaaaaaaaa&label=env=dev",
+			permissions: allowed,
+			status:      http.StatusOK,
+			deleted:     []platform.ID{1, 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deleted = nil
+			res := serveBulkTaskRequest(h, "DELETE", tt.url, "", tt.permissions)
+			if res.StatusCode != tt.status {
+				t.Fatalf("handleDeleteTasks() = %v, want %v", res.StatusCode, tt.status)
+			}
+			if !reflect.DeepEqual(deleted, tt.deleted) {
+				t.Fatalf("unexpected deleted tasks: got %v, want %v", deleted, tt.deleted)
+			}
+		})
+	}
+}
+
+func TestTaskHandler_handleUpdateTasks(t *testing.T) {
+	var orgID = platformtesting.MustIDBase16("aaaaaaaaaaaaaaaa")
+	var updated []platform.ID
+	taskService := &mock.TaskService{
+		FindTasksFn: func(ctx context.Context, f platform.TaskFilter) ([]*platform.Task, int, error) {
+			if f.After != nil {
+				return nil, 0, nil
+			}
+			if f.Organization == nil || *f.Organization != orgID {
+				t.Fatalf("expected organization filter %s, got %v", orgID, f.Organization)
+			}
+			return []*platform.Task{{ID: 1, Name: "task1"}}, 1, nil
+		},
+		UpdateTaskFn: func(ctx context.Context, id platform.ID, upd platform.TaskUpdate) (*platform.Task, error) {
+			updated = append(updated, id)
+			return &platform.Task{ID: id, Name: "task1", Status: *upd.Status}, nil
+		},
+	}
+
+	h := NewTaskHandler(mock.NewUserResourceMappingService(), logger.New(os.Stdout))
+	h.TaskService = taskService
+
+	allowed := []platform.Permission{{Action: platform.WriteAction, Resource: platform.TaskResource(orgID)}}
+
+	tests := []struct {
+		name        string
+		url         string
+		body        string
+		permissions []platform.Permission
+		status      int
+	}{
+		{
+			name:        "script update is rejected",
+			url:         "http://any.url/api/v2/tasks?organization=aaaaaaaaaaaaaaaa&name=task",
+			body:        `{"flux": "from(bucket: \"b\")"}`,
+			permissions: allowed,
+			status:      http.StatusUnprocessableEntity,
+		},
+		{
+			name:        "missing update is rejected",
+			url:         "http://any.url/api/v2/tasks?organization=aaaaaaaaaaaaaaaa&name=task",
+			body:        `{}`,
+			permissions: allowed,
+			status:      http.StatusUnprocessableEntity,
+		},
+		{
+			name:        "missing organization is rejected",
+			url:         "http://any.url/api/v2/tasks?name=task",
+			body:        `{"status": "inactive"}`,
+			permissions: allowed,
+			status:      http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "update without permission is rejected",
+			url:    "http://any.url/api/v2/tasks?organization=aaaaaaaaaaaaaaaa&name=task",
+			body:   `{"status": "inactive"}`,
+			status: http.StatusForbidden,
+		},
+		{
+			name:        "status update",
+			url:         "http://any.url/api/v2/tasks?organization=aaaaaaaaaaaaaaaa&name=task",
+			body:        `{"status": "inactive"}`,
+			permissions: allowed,
+			status:      http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if res := serveBulkTaskRequest(h, "PATCH", tt.url, tt.body, tt.permissions); res.StatusCode != tt.status {
+				t.Fatalf("handleUpdateTasks() = %v, want %v", res.StatusCode, tt.status)
+			}
+		})
+	}
+
+	if len(updated) != 1 || updated[0] != 1 {
+		t.Fatalf("expected only task 1 to be updated, got %v", updated)
+	}
+}
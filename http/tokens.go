@@ -7,7 +7,13 @@ import (
 	"strings"
 )
 
-const tokenScheme = "Token " // TODO(goller): I'd like this to be Bearer
+const (
+	tokenScheme = "Token " // TODO(goller): I'd like this to be Bearer
+
+	// bearerScheme is accepted on the routes of clients, such as Prometheus, that
+	// can only send bearer tokens.
+	bearerScheme = "Bearer "
+)
 
 // errors
 var (
@@ -27,6 +33,16 @@ func GetToken(r *http.Request) (string, error) {
 	return header[len(tokenScheme):], nil
 }
 
+// GetBearerToken will parse the token from http Authorization Header, accepting the
+// Bearer scheme as well as the Token scheme.
+func GetBearerToken(r *http.Request) (string, error) {
+	header := r.Header.Get("Authorization")
+	if strings.HasPrefix(header, bearerScheme) {
+		return header[len(bearerScheme):], nil
+	}
+	return GetToken(r)
+}
+
 // SetToken adds the token to the request.
 func SetToken(token string, req *http.Request) {
 	req.Header.Set("Authorization", fmt.Sprintf("%s%s", tokenScheme, token))
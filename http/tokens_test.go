@@ -56,6 +56,15 @@ func TestGetToken(t *testing.T) {
 				result: "tok2",
 			},
 		},
+		{
+			name: "bearer token",
+			args: args{
+				header: "Bearer tok2",
+			},
+			wants: wants{
+				err: ErrAuthBadScheme,
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -76,6 +85,49 @@ func TestGetToken(t *testing.T) {
 
 }
 
+func TestGetBearerToken(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		err    error
+		result string
+	}{
+		{
+			name: "empty header",
+			err:  ErrAuthHeaderMissing,
+		},
+		{
+			name:   "bad basic header",
+			header: "Basic header",
+			err:    ErrAuthBadScheme,
+		},
+		{
+			name:   "good token",
+			header: "Token tok2",
+			result: "tok2",
+		},
+		{
+			name:   "good bearer token",
+			header: "Bearer tok2",
+			result: "tok2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("Authorization", tt.header)
+			result, err := GetBearerToken(req)
+			if err != tt.err {
+				t.Errorf("err incorrect want %v, got %v", tt.err, err)
+				return
+			}
+			if result != tt.result {
+				t.Errorf("result incorrect want %s, got %s", tt.result, result)
+			}
+		})
+	}
+}
+
 func TestSetToken(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -1,12 +1,16 @@
 package http
 
 import (
+	"bytes"
 	"compress/gzip"
 	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"mime"
 	"net/http"
+	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/influxdata/platform"
@@ -14,12 +18,12 @@ import (
 	"github.com/influxdata/platform/kit/errors"
 	"github.com/influxdata/platform/models"
 	"github.com/influxdata/platform/storage"
-	"github.com/influxdata/platform/tsdb"
+	"github.com/influxdata/platform/write"
 	"github.com/julienschmidt/httprouter"
 	"go.uber.org/zap"
 )
 
-// WriteHandler receives line protocol and sends to a publish function.
+// WriteHandler receives line protocol, annotated CSV or JSON and sends to a publish function.
 type WriteHandler struct {
 	*httprouter.Router
 
@@ -29,12 +33,56 @@ type WriteHandler struct {
 	OrganizationService platform.OrganizationService
 
 	PointsWriter storage.PointsWriter
+	// PointsValidator validates the points of writes to /api/v2/write/validate.
+	PointsValidator storage.PointsValidator
+
+	// MaxBodySize is the maximum size in bytes of the body of a write, once decompressed.
+	// There is no maximum when it is 0.
+	MaxBodySize int64
 }
 
 const (
-	writePath = "/api/v2/write"
+	writePath         = "/api/v2/write"
+	writeValidatePath = "/api/v2/write/validate"
 )
 
+// Formats of the body of a write.
+const (
+	// WriteFormatLineProtocol is line protocol, the format of writes of any other content type.
+	WriteFormatLineProtocol = "lp"
+	// WriteFormatCSV is annotated CSV, as output by queries, of the content type text/csv.
+	WriteFormatCSV = "csv"
+	// WriteFormatJSON is rows of JSON with a mapping of their columns, of the content type
+	// application/json. See write.DecodeJSON.
+	WriteFormatJSON = "json"
+)
+
+// writeFormatContentTypes are the content types of the formats of a write.
+var writeFormatContentTypes = map[string]string{
+	WriteFormatLineProtocol: "text/plain; charset=utf-8",
+	WriteFormatCSV:          "text/csv; charset=utf-8",
+	WriteFormatJSON:         "application/json; charset=utf-8",
+}
+
+// ValidWriteFormat checks if the format of a write is known.
+func ValidWriteFormat(format string) bool {
+	_, ok := writeFormatContentTypes[format]
+	return ok
+}
+
+// writeFormat returns the format of a write of contentType.
+func writeFormat(contentType string) string {
+	mt, _, _ := mime.ParseMediaType(contentType)
+	switch mt {
+	case "text/csv":
+		return WriteFormatCSV
+	case "application/json":
+		return WriteFormatJSON
+	default:
+		return WriteFormatLineProtocol
+	}
+}
+
 // NewWriteHandler creates a new handler at /api/v2/write to receive line protocol.
 func NewWriteHandler(writer storage.PointsWriter) *WriteHandler {
 	h := &WriteHandler{
@@ -44,124 +92,266 @@ func NewWriteHandler(writer storage.PointsWriter) *WriteHandler {
 	}
 
 	h.HandlerFunc("POST", writePath, h.handleWrite)
+	h.HandlerFunc("POST", writeValidatePath, h.handleValidate)
 	return h
 }
 
 func (h *WriteHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	wb, ok := h.openWrite(w, r)
+	if !ok {
+		return
+	}
+	defer wb.Close()
+
+	lw := newLineWriter(h.PointsWriter, wb.bucket, wb.req.Precision)
+	if err := wb.writeTo(lw); err != nil {
+		// Decoders of CSV may wrap the error of the body.
+		if err == errBodyTooLarge || (wb.limited != nil && wb.limited.n < 0) {
+			// Lines before the limit may have been written, as the size is only known
+			// once it is exceeded for compressed or chunked bodies.
+			encodeLineProtocolLengthError(w, h.MaxBodySize)
+			return
+		}
+		if lw.overloaded {
+			// Lines before the failed batch may have been written, which is harmless
+			// when the write is retried as points are overwritten.
+			wb.logger.Info("Write rejected by overloaded engine", zap.Error(err))
+			encodeOverloadedError(w, err)
+			return
+		}
+		wb.logger.Info("Error writing points", zap.Error(err))
+		EncodeError(r.Context(), errors.BadRequestError(err.Error()), w)
+		return
+	}
+
+	if err := lw.err(); err != nil {
+		wb.logger.Info("Lines rejected from write", zap.Int("rejected", err.RejectedCount))
+		encodeLineProtocolError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// WriteValidation reports the problems of the lines of a write validated without
+// writing it, and what writing it would create.
+type WriteValidation struct {
+	// Valid is true if every line would be written.
+	Valid bool `json:"valid"`
+	// RejectedCount is the number of lines that would be rejected, of which the first
+	// maxRejectedLines, by line, are listed in Rejected.
+	RejectedCount int            `json:"rejectedCount"`
+	Rejected      []RejectedLine `json:"rejected"`
+	// NewSeries is the number of series that would be created, each of a field of a
+	// measurement.
+	NewSeries       int      `json:"newSeries"`
+	NewMeasurements []string `json:"newMeasurements"`
+	// TypeConflicts is the number of fields of the lines whose type differs from that
+	// of the field already in the bucket or earlier in the write.
+	TypeConflicts int `json:"typeConflicts"`
+}
+
+// handleValidate validates a write as handleWrite would write it, and reports the
+// rejected lines and what the write would create. Nothing is written.
+func (h *WriteHandler) handleValidate(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	defer r.Body.Close()
 
-	in := r.Body
-	if r.Header.Get("Content-Encoding") == "gzip" {
-		var err error
-		in, err = gzip.NewReader(r.Body)
-		if err != nil {
-			EncodeError(ctx, errors.Wrap(err, "invalid gzip", errors.InvalidData), w)
+	if h.PointsValidator == nil {
+		EncodeError(ctx, &platform.Error{Code: platform.EUnavailable, Msg: "write validation is not available"}, w)
+		return
+	}
+
+	wb, ok := h.openWrite(w, r)
+	if !ok {
+		return
+	}
+	defer wb.Close()
+
+	v := storage.NewWriteValidation()
+	lw := newLineWriter(&validatingWriter{v: h.PointsValidator, validation: v}, wb.bucket, wb.req.Precision)
+	if err := wb.writeTo(lw); err != nil {
+		if err == errBodyTooLarge || (wb.limited != nil && wb.limited.n < 0) {
+			encodeLineProtocolLengthError(w, h.MaxBodySize)
 			return
 		}
-		defer in.Close()
+		wb.logger.Info("Error validating points", zap.Error(err))
+		EncodeError(ctx, errors.BadRequestError(err.Error()), w)
+		return
+	}
+
+	res := &WriteValidation{
+		Valid:           true,
+		Rejected:        []RejectedLine{},
+		NewSeries:       v.NewSeries,
+		NewMeasurements: v.NewMeasurements,
+		TypeConflicts:   v.TypeConflicts,
 	}
+	if res.NewMeasurements == nil {
+		res.NewMeasurements = []string{}
+	}
+	if err := lw.err(); err != nil {
+		res.Valid = false
+		res.RejectedCount = err.RejectedCount
+		res.Rejected = err.Rejected
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, res); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+}
+
+// validatingWriter is a storage.PointsWriter that validates points rather than writing
+// them.
+type validatingWriter struct {
+	v          storage.PointsValidator
+	validation *storage.WriteValidation
+}
+
+func (w *validatingWriter) WritePoints(points []models.Point) error {
+	return w.v.ValidatePoints(w.validation, points)
+}
+
+// writeBody is the body of a write to a bucket, as decoded from its request.
+type writeBody struct {
+	req    *postWriteRequest
+	bucket *platform.Bucket
+	logger *zap.Logger
+
+	in      io.Reader
+	limited *limitedReader
+	// gz decompresses the body of the request, when it is compressed.
+	gz io.Closer
+}
+
+// openWrite decodes the request of a write, and checks that the bucket can be written
+// before reading the body. It encodes the error and returns false if it cannot.
+func (h *WriteHandler) openWrite(w http.ResponseWriter, r *http.Request) (*writeBody, bool) {
+	ctx := r.Context()
 
 	a, err := pcontext.GetAuthorizer(ctx)
 	if err != nil {
 		EncodeError(ctx, err, w)
-		return
+		return nil, false
 	}
 
 	req, err := decodeWriteRequest(ctx, r)
 	if err != nil {
 		EncodeError(ctx, err, w)
-		return
+		return nil, false
 	}
 
 	logger := h.Logger.With(zap.String("org", req.Org), zap.String("bucket", req.Bucket))
 
+	bucket, err := findBucket(ctx, h.OrganizationService, h.BucketService, req.Org, req.Bucket, logger)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return nil, false
+	}
+
+	if !a.Allowed(platform.WriteBucketPermission(bucket.ID)) {
+		EncodeError(ctx, errors.Forbiddenf("insufficient permissions for write"), w)
+		return nil, false
+	}
+
+	// The body is only read once the write is authorized.
+	in := r.Body
+	var gz io.Closer
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		in, err = gzip.NewReader(r.Body)
+		if err != nil {
+			EncodeError(ctx, errors.Wrap(err, "invalid gzip", errors.InvalidData), w)
+			return nil, false
+		}
+		gz = in
+	}
+
+	wb := &writeBody{req: req, bucket: bucket, logger: logger, in: in, gz: gz}
+	if h.MaxBodySize > 0 {
+		if r.ContentLength > h.MaxBodySize && in == r.Body {
+			encodeLineProtocolLengthError(w, h.MaxBodySize)
+			return nil, false
+		}
+		wb.limited = &limitedReader{ReadCloser: in, n: h.MaxBodySize}
+		wb.in = wb.limited
+	}
+	return wb, true
+}
+
+// writeTo writes the points of the body, in its format, with lw.
+func (wb *writeBody) writeTo(lw *lineWriter) error {
+	switch wb.req.Format {
+	case WriteFormatCSV:
+		return lw.writeRows(func(fn write.RowFunc) error { return write.DecodeCSV(wb.in, fn) })
+	case WriteFormatJSON:
+		return lw.writeRows(func(fn write.RowFunc) error { return write.DecodeJSON(wb.in, wb.req.Precision, lw.now, fn) })
+	default:
+		return lw.writeAll(wb.in)
+	}
+}
+
+// Close closes the decompression of the body, leaving the body of the request open.
+func (wb *writeBody) Close() error {
+	if wb.gz == nil {
+		return nil
+	}
+	return wb.gz.Close()
+}
+
+// findBucket returns the bucket with the name or ID bucketName, of the organization with the
+// name or ID orgName.
+func findBucket(ctx context.Context, orgSvc platform.OrganizationService, bucketSvc platform.BucketService, orgName, bucketName string, logger *zap.Logger) (*platform.Bucket, error) {
 	var org *platform.Organization
-	if id, err := platform.IDFromString(req.Org); err == nil {
+	if id, err := platform.IDFromString(orgName); err == nil {
 		// Decoded ID successfully. Make sure it's a real org.
-		o, err := h.OrganizationService.FindOrganizationByID(ctx, *id)
+		o, err := orgSvc.FindOrganizationByID(ctx, *id)
 		if err == nil {
 			org = o
 		} else if err != ErrNotFound {
-			EncodeError(ctx, err, w)
-			return
+			return nil, err
 		}
 	}
 	if org == nil {
-		o, err := h.OrganizationService.FindOrganization(ctx, platform.OrganizationFilter{Name: &req.Org})
+		o, err := orgSvc.FindOrganization(ctx, platform.OrganizationFilter{Name: &orgName})
 		if err != nil {
 			logger.Info("Failed to find organization", zap.Error(err))
-			EncodeError(ctx, fmt.Errorf("organization %q not found", req.Org), w)
-			return
+			return nil, fmt.Errorf("organization %q not found", orgName)
 		}
 
 		org = o
 	}
 
 	var bucket *platform.Bucket
-	if id, err := platform.IDFromString(req.Bucket); err == nil {
+	if id, err := platform.IDFromString(bucketName); err == nil {
 		// Decoded ID successfully. Make sure it's a real bucket.
-		b, err := h.BucketService.FindBucket(ctx, platform.BucketFilter{
+		b, err := bucketSvc.FindBucket(ctx, platform.BucketFilter{
 			OrganizationID: &org.ID,
 			ID:             id,
 		})
 		if err == nil {
 			bucket = b
 		} else if err != ErrNotFound {
-			EncodeError(ctx, err, w)
-			return
+			return nil, err
 		}
 	}
 
 	if bucket == nil {
-		b, err := h.BucketService.FindBucket(ctx, platform.BucketFilter{
+		b, err := bucketSvc.FindBucket(ctx, platform.BucketFilter{
 			OrganizationID: &org.ID,
-			Name:           &req.Bucket,
+			Name:           &bucketName,
 		})
 		if err != nil {
 			logger.Info("Failed to find bucket", zap.Stringer("org_id", org.ID), zap.Error(err))
-			EncodeError(ctx, fmt.Errorf("bucket %q not found", req.Bucket), w)
-			return
+			return nil, fmt.Errorf("bucket %q not found", bucketName)
 		}
 
 		bucket = b
 	}
 
-	if !a.Allowed(platform.WriteBucketPermission(bucket.ID)) {
-		EncodeError(ctx, errors.Forbiddenf("insufficient permissions for write"), w)
-		return
-	}
-
-	// TODO(jeff): we should be publishing with the org and bucket instead of
-	// parsing, rewriting, and publishing, but the interface isn't quite there yet.
-	// be sure to remove this when it is there!
-	data, err := ioutil.ReadAll(in)
-	if err != nil {
-		logger.Info("Error reading body", zap.Error(err))
-		EncodeError(ctx, err, w)
-		return
-	}
-
-	points, err := models.ParsePointsWithPrecision(data, time.Now(), req.Precision)
-	if err != nil {
-		logger.Info("Error parsing points", zap.Error(err))
-		EncodeError(ctx, err, w)
-		return
-	}
-
-	exploded, err := tsdb.ExplodePoints(org.ID, bucket.ID, points)
-	if err != nil {
-		logger.Info("Error exploding points", zap.Error(err))
-		EncodeError(ctx, err, w)
-		return
-	}
-
-	if err := h.PointsWriter.WritePoints(exploded); err != nil {
-		EncodeError(ctx, errors.BadRequestError(err.Error()), w)
-		return
-	}
-
-	w.WriteHeader(http.StatusNoContent)
+	return bucket, nil
 }
 
 func decodeWriteRequest(ctx context.Context, r *http.Request) (*postWriteRequest, error) {
@@ -179,6 +369,7 @@ func decodeWriteRequest(ctx context.Context, r *http.Request) (*postWriteRequest
 		Bucket:    qp.Get("bucket"),
 		Org:       qp.Get("org"),
 		Precision: p,
+		Format:    writeFormat(r.Header.Get("Content-Type")),
 	}, nil
 }
 
@@ -186,6 +377,7 @@ type postWriteRequest struct {
 	Org       string
 	Bucket    string
 	Precision string
+	Format    string
 }
 
 // WriteService sends data over HTTP to influxdb via line protocol.
@@ -194,6 +386,17 @@ type WriteService struct {
 	Token              string
 	Precision          string
 	InsecureSkipVerify bool
+	// Format is the format of the data written, one of the WriteFormat constants. The data
+	// is line protocol if it is empty.
+	Format string
+
+	// MaxRetries is the number of times a write is retried when the server is overloaded,
+	// write.DefaultMaxRetries when 0. Writes are not retried when it is negative, and
+	// the body is then not buffered. A write that is still rejected returns a
+	// *write.OverloadedError.
+	MaxRetries int
+	// MaxBackoff is the maximum wait before retrying a write, write.DefaultMaxBackoff when 0.
+	MaxBackoff time.Duration
 }
 
 var _ platform.WriteService = (*WriteService)(nil)
@@ -208,6 +411,14 @@ func (s *WriteService) Write(ctx context.Context, orgID, bucketID platform.ID, r
 		return fmt.Errorf("invalid precision")
 	}
 
+	format := s.Format
+	if format == "" {
+		format = WriteFormatLineProtocol
+	}
+	if !ValidWriteFormat(format) {
+		return fmt.Errorf("invalid format %q", format)
+	}
+
 	u, err := newURL(s.Addr, writePath)
 	if err != nil {
 		return err
@@ -218,12 +429,54 @@ func (s *WriteService) Write(ctx context.Context, orgID, bucketID platform.ID, r
 		return err
 	}
 
+	maxRetries := s.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = write.DefaultMaxRetries
+	}
+
+	maxBackoff := s.MaxBackoff
+	if maxBackoff == 0 {
+		maxBackoff = write.DefaultMaxBackoff
+	}
+
+	var body []byte
+	if maxRetries > 0 {
+		// Retries send the body again.
+		if body, err = ioutil.ReadAll(r); err != nil {
+			return err
+		}
+	}
+
+	for n := 0; ; n++ {
+		if body != nil {
+			r = bytes.NewReader(body)
+		}
+		err := s.write(ctx, u, orgID, bucketID, precision, format, r)
+		oe, ok := err.(*write.OverloadedError)
+		if !ok || n >= maxRetries {
+			return err
+		}
+
+		timer := time.NewTimer(oe.Backoff(n, maxBackoff))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// write sends the gzipped body r. It returns a *write.OverloadedError if the server is
+// overloaded.
+func (s *WriteService) write(ctx context.Context, u *url.URL, orgID, bucketID platform.ID, precision, format string, r io.Reader) error {
 	req, err := http.NewRequest("POST", u.String(), r)
 	if err != nil {
 		return err
 	}
+	req = req.WithContext(ctx)
 
-	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	req.Header.Set("Content-Type", writeFormatContentTypes[format])
 	req.Header.Set("Content-Encoding", "gzip")
 	SetToken(s.Token, req)
 
@@ -249,8 +502,25 @@ func (s *WriteService) Write(ctx context.Context, orgID, bucketID platform.ID, r
 	if err != nil {
 		return err
 	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		if resp.StatusCode == http.StatusServiceUnavailable {
+			return &write.OverloadedError{RetryAfter: retryAfter(resp), Err: err}
+		}
+		return err
+	}
+	return nil
+}
 
-	return CheckError(resp)
+// retryAfter returns the wait in seconds of the Retry-After header of resp, or 0 if
+// there is none.
+func retryAfter(resp *http.Response) time.Duration {
+	secs, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || secs < 0 {
+		return 0
+	}
+	return time.Duration(secs) * time.Second
 }
 
 func compressWithGzip(data io.Reader) (io.Reader, error) {
@@ -3,14 +3,25 @@ package http
 import (
 	"compress/gzip"
 	"context"
+	"encoding/json"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/influxdata/platform"
+	pcontext "github.com/influxdata/platform/context"
+	"github.com/influxdata/platform/mock"
+	"github.com/influxdata/platform/models"
+	"github.com/influxdata/platform/storage"
+	platformtesting "github.com/influxdata/platform/testing"
+	"github.com/influxdata/platform/tsdb"
+	"github.com/influxdata/platform/write"
 )
 
 func TestWriteService_Write(t *testing.T) {
@@ -69,3 +80,504 @@ func TestWriteService_Write(t *testing.T) {
 		})
 	}
 }
+
+func TestWriteService_WriteOverloaded(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxRetries int
+		// overloaded is the number of writes rejected before writes are accepted.
+		overloaded int
+		writes     int
+		wantErr    bool
+	}{
+		{name: "retry", maxRetries: 2, overloaded: 2, writes: 3},
+		{name: "retries exhausted", maxRetries: 1, overloaded: 2, writes: 2, wantErr: true},
+		{name: "no retries", maxRetries: -1, overloaded: 1, writes: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var writes int
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				defer r.Body.Close()
+				in, err := gzip.NewReader(r.Body)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				defer in.Close()
+				if lp, _ := ioutil.ReadAll(in); string(lp) != "m,t1=v1 f1=2" {
+					t.Errorf("got body %q of write %d", lp, writes)
+				}
+
+				if writes++; writes <= tt.overloaded {
+					w.Header().Set(ErrorHeader, "engine overloaded")
+					w.Header().Set("Retry-After", "0")
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			defer ts.Close()
+
+			s := &WriteService{Addr: ts.URL, MaxRetries: tt.maxRetries, MaxBackoff: time.Millisecond}
+			err := s.Write(context.Background(), 1, 2, strings.NewReader("m,t1=v1 f1=2"))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("WriteService.Write() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if _, ok := err.(*write.OverloadedError); tt.wantErr && !ok {
+				t.Errorf("WriteService.Write() error = %T, want *write.OverloadedError", err)
+			}
+			if writes != tt.writes {
+				t.Errorf("got %d writes, want %d", writes, tt.writes)
+			}
+		})
+	}
+}
+
+func TestWriteHandler_handleWrite(t *testing.T) {
+	bucketID := platformtesting.MustIDBase16("020f755c3c082001")
+
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+		maxBodySize int64
+		// schema is the explicit schema the points writer enforces, when it is not nil.
+		schema []platform.MeasurementSchema
+		// conflicts reports each conflicting point of a write, rather than the whole write.
+		conflicts bool
+		status    int
+		written   []string
+		rejected  []RejectedLine
+		// message is the message of the first rejected line, when it is not empty.
+		message string
+	}{
+		{
+			name:    "write lines",
+			body:    "cpu value=1 1\ncpu value=2 2\n",
+			status:  http.StatusNoContent,
+			written: []string{"cpu value=1 1", "cpu value=2 2"},
+		},
+		{
+			name:    "reject malformed line",
+			body:    "cpu value=1 1\ncpu value= 2\ncpu value=3 3",
+			status:  http.StatusBadRequest,
+			written: []string{"cpu value=1 1", "cpu value=3 3"},
+			rejected: []RejectedLine{
+				{Line: 2, Reason: RejectedParseError},
+			},
+		},
+		{
+			name:    "reject field type conflict",
+			body:    "cpu value=1 1\ncpu value=\"a\" 2\ncpu value=3 3",
+			status:  http.StatusBadRequest,
+			written: []string{"cpu value=1 1", "cpu value=3 3"},
+			rejected: []RejectedLine{
+				{Line: 2, Reason: RejectedFieldTypeConflict},
+			},
+		},
+		{
+			name:      "reject conflicting points of write",
+			body:      "cpu value=1 1\ncpu value=\"a\" 2\ncpu value=3 3",
+			conflicts: true,
+			status:    http.StatusBadRequest,
+			written:   []string{"cpu value=1 1", "cpu value=3 3"},
+			rejected: []RejectedLine{
+				{Line: 2, Reason: RejectedFieldTypeConflict},
+			},
+			message: `field type conflict: input field "value" on measurement "cpu" is type string, already exists as type float`,
+		},
+		{
+			name: "reject lines not matching explicit schema",
+			body: "cpu value=1 1\ncpu value=2i 2\ncpu idle=3 3\nmem value=4 4",
+			schema: []platform.MeasurementSchema{
+				{Name: "cpu", Fields: []platform.FieldSchema{{Name: "value", Type: platform.FieldTypeFloat}}},
+			},
+			status:  http.StatusBadRequest,
+			written: []string{"cpu value=1 1"},
+			rejected: []RejectedLine{
+				{Line: 2, Reason: RejectedSchemaMismatch},
+				{Line: 3, Reason: RejectedSchemaMismatch},
+				{Line: 4, Reason: RejectedSchemaMismatch},
+			},
+			message: `input field "value" on measurement "cpu" is type integer, schema declares type float`,
+		},
+		{
+			name:    "reject invalid key",
+			body:    "log msg=\"a\nb\" 1\ncpu,time=a value=2 2\ncpu value=3 3",
+			status:  http.StatusBadRequest,
+			written: []string{"log msg=\"a\nb\" 1", "cpu value=3 3"},
+			rejected: []RejectedLine{
+				{Line: 3, Reason: RejectedInvalidKey},
+			},
+		},
+		{
+			name: "write annotated csv",
+			body: `#datatype,string,long,dateTime:RFC3339,double,string,string
+#group,false,false,false,false,true,true
+#default,_result,,,,,
+,result,table,_time,_value,_field,_measurement
+,,0,1970-01-01T00:00:01Z,1,value,cpu
+`,
+			contentType: "text/csv",
+			status:      http.StatusNoContent,
+			written:     []string{"cpu value=1 1000000000"},
+		},
+		{
+			name:        "reject json rows",
+			body:        `{"mapping": {"measurement": "m", "fields": {"value": "float"}, "time": "t"}, "rows": [{"m": "cpu", "value": 1, "t": 1}, {"m": "cpu", "value": "a", "t": 2}]}`,
+			contentType: "application/json",
+			status:      http.StatusBadRequest,
+			written:     []string{"cpu value=1 1"},
+			rejected: []RejectedLine{
+				{Line: 2, Reason: RejectedParseError},
+			},
+		},
+		{
+			name:        "malformed json",
+			body:        `{"rows": [`,
+			contentType: "application/json",
+			status:      http.StatusBadRequest,
+		},
+		{
+			name:        "body too large",
+			body:        "cpu value=1 1\n",
+			maxBodySize: 4,
+			status:      http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pw := &conflictPointsWriter{types: make(map[string]models.FieldType), conflicts: tt.conflicts, schema: tt.schema}
+
+			h := NewWriteHandler(pw)
+			h.OrganizationService = newPromOrgService()
+			h.BucketService = newPromBucketService()
+			h.MaxBodySize = tt.maxBodySize
+
+			r := httptest.NewRequest("POST", "http://any.url/api/v2/write?org=020f755c3c082000&bucket=020f755c3c082001", strings.NewReader(tt.body))
+			r = r.WithContext(pcontext.SetAuthorizer(r.Context(), &platform.Authorization{
+				Status:      platform.Active,
+				Permissions: []platform.Permission{platform.WriteBucketPermission(bucketID)},
+			}))
+			if tt.contentType != "" {
+				r.Header.Set("Content-Type", tt.contentType)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+			if res.StatusCode != tt.status {
+				t.Fatalf("got status %d, want %d: %s", res.StatusCode, tt.status, body)
+			}
+
+			if !reflect.DeepEqual(pw.written, tt.written) {
+				t.Errorf("got points written %q, want %q", pw.written, tt.written)
+			}
+
+			if tt.rejected == nil {
+				return
+			}
+			var lpe LineProtocolError
+			if err := json.Unmarshal(body, &lpe); err != nil {
+				t.Fatal(err)
+			}
+			if tt.message != "" && len(lpe.Rejected) > 0 && lpe.Rejected[0].Message != tt.message {
+				t.Errorf("got message %q, want %q", lpe.Rejected[0].Message, tt.message)
+			}
+			for i := range lpe.Rejected {
+				lpe.Rejected[i].Message = ""
+			}
+			if !reflect.DeepEqual(lpe.Rejected, tt.rejected) {
+				t.Errorf("got rejected lines %+v, want %+v", lpe.Rejected, tt.rejected)
+			}
+			if lpe.Line != tt.rejected[0].Line || lpe.RejectedCount != len(tt.rejected) {
+				t.Errorf("got first line %d of %d rejected, want %d of %d", lpe.Line, lpe.RejectedCount, tt.rejected[0].Line, len(tt.rejected))
+			}
+		})
+	}
+}
+
+func TestWriteHandler_UnauthorizedGzip(t *testing.T) {
+	h := NewWriteHandler(&mock.PointsWriter{})
+	h.OrganizationService = newPromOrgService()
+	h.BucketService = newPromBucketService()
+
+	// The body is not gzip, which is only found once the write is authorized.
+	r := httptest.NewRequest("POST", "http://any.url/api/v2/write?org=020f755c3c082000&bucket=020f755c3c082001", strings.NewReader("cpu value=1 1"))
+	r.Header.Set("Content-Encoding", "gzip")
+	r = r.WithContext(pcontext.SetAuthorizer(r.Context(), &platform.Authorization{Status: platform.Active}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if got := w.Result().StatusCode; got != http.StatusForbidden {
+		t.Errorf("got status %d, want %d", got, http.StatusForbidden)
+	}
+}
+
+func TestLineWriter_reject(t *testing.T) {
+	lw := newLineWriter(&mock.PointsWriter{}, &platform.Bucket{}, "ns")
+
+	// Reject lines in the reverse of their order, as when the points of the lines of
+	// a batch are rejected after later lines of the batch are rejected when parsed.
+	n := 2*maxRejectedLines + 10
+	for line := n; line > 0; line-- {
+		lw.reject(line, RejectedParseError, "")
+	}
+
+	err := lw.err()
+	if err.RejectedCount != n || err.Line != 1 || len(err.Rejected) != maxRejectedLines {
+		t.Fatalf("got first line %d of %d rejected, %d listed, want 1 of %d, %d listed", err.Line, err.RejectedCount, len(err.Rejected), n, maxRejectedLines)
+	}
+	for i, rl := range err.Rejected {
+		if rl.Line != i+1 {
+			t.Fatalf("got line %d listed at %d, want %d", rl.Line, i, i+1)
+		}
+	}
+}
+
+func TestWriteHandler_Overloaded(t *testing.T) {
+	bucketID := platformtesting.MustIDBase16("020f755c3c082001")
+
+	pw := &mock.PointsWriter{}
+	pw.ForceError(storage.OverloadedError{Err: fmt.Errorf("cache full")})
+	h := NewWriteHandler(pw)
+	h.OrganizationService = newPromOrgService()
+	h.BucketService = newPromBucketService()
+
+	for _, contentType := range []string{"", "text/csv"} {
+		body := "cpu value=1 1"
+		if contentType == "text/csv" {
+			body = "#datatype,string,long,dateTime:RFC3339,double,string,string\n#group,false,false,false,false,true,true\n#default,_result,,,,,\n,result,table,_time,_value,_field,_measurement\n,,0,1970-01-01T00:00:01Z,1,value,cpu\n"
+		}
+		r := httptest.NewRequest("POST", "http://any.url/api/v2/write?org=020f755c3c082000&bucket=020f755c3c082001", strings.NewReader(body))
+		r = r.WithContext(pcontext.SetAuthorizer(r.Context(), &platform.Authorization{
+			Status:      platform.Active,
+			Permissions: []platform.Permission{platform.WriteBucketPermission(bucketID)},
+		}))
+		if contentType != "" {
+			r.Header.Set("Content-Type", contentType)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		res := w.Result()
+		if res.StatusCode != http.StatusServiceUnavailable {
+			t.Errorf("got status %d for %q, want %d", res.StatusCode, contentType, http.StatusServiceUnavailable)
+		}
+		if got := res.Header.Get("Retry-After"); got != "1" {
+			t.Errorf("got Retry-After %q for %q, want 1", got, contentType)
+		}
+	}
+}
+
+func TestWriteHandler_handleValidate(t *testing.T) {
+	bucketID := platformtesting.MustIDBase16("020f755c3c082001")
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		want   WriteValidation
+	}{
+		{
+			name:   "valid lines",
+			body:   "cpu value=1 1\ncpu value=2 2\nmem free=1 1",
+			status: http.StatusOK,
+			want: WriteValidation{
+				Valid:           true,
+				Rejected:        []RejectedLine{},
+				NewSeries:       3,
+				NewMeasurements: []string{"cpu", "mem"},
+			},
+		},
+		{
+			name:   "rejected lines",
+			body:   "cpu value=1 1\ncpu value= 2\ncpu conflict=3 3",
+			status: http.StatusOK,
+			want: WriteValidation{
+				RejectedCount: 2,
+				Rejected: []RejectedLine{
+					{Line: 2, Reason: RejectedParseError},
+					{Line: 3, Reason: RejectedFieldTypeConflict},
+				},
+				NewSeries:       1,
+				NewMeasurements: []string{"cpu"},
+				TypeConflicts:   1,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pw := &mock.PointsWriter{}
+			h := NewWriteHandler(pw)
+			h.PointsValidator = &fakeValidator{}
+			h.OrganizationService = newPromOrgService()
+			h.BucketService = newPromBucketService()
+
+			r := httptest.NewRequest("POST", "http://any.url/api/v2/write/validate?org=020f755c3c082000&bucket=020f755c3c082001", strings.NewReader(tt.body))
+			r = r.WithContext(pcontext.SetAuthorizer(r.Context(), &platform.Authorization{
+				Status:      platform.Active,
+				Permissions: []platform.Permission{platform.WriteBucketPermission(bucketID)},
+			}))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+			if res.StatusCode != tt.status {
+				t.Fatalf("got status %d, want %d: %s", res.StatusCode, tt.status, body)
+			}
+
+			var got WriteValidation
+			if err := json.Unmarshal(body, &got); err != nil {
+				t.Fatal(err)
+			}
+			for i := range got.Rejected {
+				got.Rejected[i].Message = ""
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got validation %+v, want %+v", got, tt.want)
+			}
+			if len(pw.Points) != 0 {
+				t.Errorf("got %d points written, want none", len(pw.Points))
+			}
+		})
+	}
+}
+
+// fakeValidator validates each exploded point as a new series, and each point of a
+// field named conflict as a field type conflict.
+type fakeValidator struct{}
+
+func (fakeValidator) ValidatePoints(v *storage.WriteValidation, points []models.Point) error {
+	var pwe tsdb.PartialWriteError
+	for i, pt := range points {
+		tags := pt.Tags()
+		if string(tags.Get(tsdb.FieldKeyTagKeyBytes)) == "conflict" {
+			if pwe.Conflicts == nil {
+				pwe.Conflicts = make(map[int]tsdb.FieldTypeConflictError)
+			}
+			pwe.Conflicts[i] = tsdb.FieldTypeConflictError{Measurement: "cpu", Field: "conflict", Type: platform.FieldTypeFloat, ExistingType: platform.FieldTypeString}
+			pwe.Dropped++
+			v.TypeConflicts++
+			continue
+		}
+
+		measurement := string(tags.Get(tsdb.MeasurementTagKeyBytes))
+		if !containsString(v.NewMeasurements, measurement) {
+			v.NewMeasurements = append(v.NewMeasurements, measurement)
+		}
+		v.NewSeries++
+	}
+	if pwe.Dropped == 0 {
+		return nil
+	}
+	return pwe
+}
+
+// conflictPointsWriter writes exploded points like the storage engine: it drops series
+// with a time tag, and rejects values whose type differs from the earlier values of the
+// series, with a field type conflict for the write, or for each conflicting point when
+// conflicts is set. Like the engine, writing a point again overwrites it.
+type conflictPointsWriter struct {
+	types     map[string]models.FieldType
+	conflicts bool
+	schema    []platform.MeasurementSchema
+	written   []string
+}
+
+// schemaMismatch returns why the point does not match the schema of the writer, as the
+// storage engine reports it.
+func (w *conflictPointsWriter) schemaMismatch(measurement, field string, typ models.FieldType) (tsdb.SchemaMismatchError, bool) {
+	if w.schema == nil {
+		return tsdb.SchemaMismatchError{}, false
+	}
+	for _, m := range w.schema {
+		if m.Name != measurement {
+			continue
+		}
+		for _, f := range m.Fields {
+			if f.Name == field {
+				got := storage.SchemaFieldType(typ)
+				return tsdb.SchemaMismatchError{Measurement: measurement, Field: field, Type: got, SchemaType: f.Type}, got != f.Type
+			}
+		}
+		return tsdb.SchemaMismatchError{Measurement: measurement, Field: field}, true
+	}
+	return tsdb.SchemaMismatchError{Measurement: measurement}, true
+}
+
+func (w *conflictPointsWriter) WritePoints(points []models.Point) error {
+	var (
+		err        error
+		dropped    [][]byte
+		conflicts  map[int]tsdb.FieldTypeConflictError
+		mismatches map[int]tsdb.SchemaMismatchError
+	)
+	for i, pt := range points {
+		tags := pt.Tags()
+		if tags.Get([]byte("time")) != nil {
+			dropped = append(dropped, pt.Key())
+			continue
+		}
+
+		itr := pt.FieldIterator()
+		itr.Next()
+		if m, ok := w.schemaMismatch(tags.GetString(tsdb.MeasurementTagKey), tags.GetString(tsdb.FieldKeyTagKey), itr.Type()); ok {
+			if mismatches == nil {
+				mismatches = make(map[int]tsdb.SchemaMismatchError)
+			}
+			mismatches[i] = m
+			continue
+		}
+		if typ, ok := w.types[string(pt.Key())]; ok && typ != itr.Type() {
+			if !w.conflicts {
+				err = tsdb.ErrFieldTypeConflict
+				continue
+			}
+			if conflicts == nil {
+				conflicts = make(map[int]tsdb.FieldTypeConflictError)
+			}
+			conflicts[i] = tsdb.FieldTypeConflictError{
+				Measurement:  tags.GetString(tsdb.MeasurementTagKey),
+				Field:        tags.GetString(tsdb.FieldKeyTagKey),
+				Type:         storage.SchemaFieldType(itr.Type()),
+				ExistingType: storage.SchemaFieldType(typ),
+			}
+			continue
+		}
+		w.types[string(pt.Key())] = itr.Type()
+
+		fields, _ := pt.Fields()
+		p := models.MustNewPoint(tags.GetString(tsdb.MeasurementTagKey), tagsWithoutStorageKeys(tags), fields, pt.Time())
+		if !containsString(w.written, p.String()) {
+			w.written = append(w.written, p.String())
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if len(dropped) > 0 || len(conflicts) > 0 || len(mismatches) > 0 {
+		return tsdb.PartialWriteError{
+			Reason:           "invalid tag key",
+			Dropped:          len(dropped) + len(conflicts) + len(mismatches),
+			DroppedKeys:      dropped,
+			Conflicts:        conflicts,
+			SchemaMismatches: mismatches,
+		}
+	}
+	return nil
+}
+
+func containsString(a []string, s string) bool {
+	for _, v := range a {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,352 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/platform"
+	kerrors "github.com/influxdata/platform/kit/errors"
+	"github.com/influxdata/platform/models"
+	"github.com/influxdata/platform/storage"
+	"github.com/influxdata/platform/tsdb"
+	"github.com/influxdata/platform/write"
+)
+
+const (
+	// writeBatchSize is the number of points that are parsed from a write before they
+	// are written, which bounds the memory used by a write of any size.
+	writeBatchSize = 5000
+
+	// maxWriteLineSize is the maximum size in bytes of a line of a write.
+	maxWriteLineSize = 16 * 1024 * 1024
+
+	// maxRejectedLines is the maximum number of rejected lines reported for a write.
+	maxRejectedLines = 1000
+
+	// overloadedRetryAfter is when a write that failed because the engine is overloaded
+	// should be retried.
+	overloadedRetryAfter = time.Second
+)
+
+// Reasons of the lines rejected from a write.
+const (
+	RejectedParseError        = "parse error"
+	RejectedFieldTypeConflict = "field type conflict"
+	RejectedInvalidKey        = "invalid key"
+	RejectedSchemaMismatch    = "schema mismatch"
+)
+
+var errBodyTooLarge = errors.New("body too large")
+
+// RejectedLine is a line of a write that was not written. Line is the number of the
+// row, rather than the line, of a CSV or JSON write.
+type RejectedLine struct {
+	Line    int    `json:"line"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+// LineProtocolError reports the lines of a write that were rejected. The
+// other lines of the write were written.
+type LineProtocolError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// Line is the first rejected line.
+	Line int `json:"line"`
+	// RejectedCount is the number of rejected lines, of which the first
+	// maxRejectedLines, by line, are listed in Rejected.
+	RejectedCount int            `json:"rejectedCount"`
+	Rejected      []RejectedLine `json:"rejected"`
+}
+
+// Error implements the error interface.
+func (e *LineProtocolError) Error() string {
+	return e.Message
+}
+
+// encodeLineProtocolError writes err as a bad request, with the X-Influx-Error
+// header set so that CheckError reports it.
+func encodeLineProtocolError(w http.ResponseWriter, err *LineProtocolError) {
+	msg := err.Message
+	if len(msg) > errorHeaderMaxLength {
+		msg = msg[:errorHeaderMaxLength]
+	}
+	w.Header().Set(ErrorHeader, msg)
+	w.Header().Set(ReferenceHeader, strconv.Itoa(kerrors.MalformedData))
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusBadRequest)
+	_ = json.NewEncoder(w).Encode(err)
+}
+
+// encodeLineProtocolLengthError writes that a body is larger than maxLength bytes.
+func encodeLineProtocolLengthError(w http.ResponseWriter, maxLength int64) {
+	msg := fmt.Sprintf("body exceeds the maximum size of %d bytes", maxLength)
+	w.Header().Set(ErrorHeader, msg)
+	w.Header().Set(ReferenceHeader, strconv.Itoa(kerrors.InvalidData))
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusRequestEntityTooLarge)
+	_ = json.NewEncoder(w).Encode(struct {
+		Code      string `json:"code"`
+		Message   string `json:"message"`
+		MaxLength int64  `json:"maxLength"`
+	}{platform.EInvalid, msg, maxLength})
+}
+
+// encodeOverloadedError writes that a write failed because the engine is overloaded,
+// with a Retry-After header of when it should be retried.
+func encodeOverloadedError(w http.ResponseWriter, err error) {
+	msg := err.Error()
+	if len(msg) > errorHeaderMaxLength {
+		msg = msg[:errorHeaderMaxLength]
+	}
+	w.Header().Set(ErrorHeader, msg)
+	w.Header().Set(ReferenceHeader, strconv.Itoa(kerrors.InternalError))
+	w.Header().Set("Retry-After", strconv.Itoa(int(overloadedRetryAfter/time.Second)))
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusServiceUnavailable)
+	_ = json.NewEncoder(w).Encode(struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}{platform.EUnavailable, err.Error()})
+}
+
+// limitedReader returns errBodyTooLarge once more than n bytes are read.
+type limitedReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (r *limitedReader) Read(p []byte) (int, error) {
+	if r.n < 0 {
+		return 0, errBodyTooLarge
+	}
+	if int64(len(p)) > r.n+1 {
+		p = p[:r.n+1]
+	}
+	n, err := r.ReadCloser.Read(p)
+	r.n -= int64(n)
+	if r.n < 0 {
+		return n, errBodyTooLarge
+	}
+	return n, err
+}
+
+// lineWriter writes the points of the lines of a write in batches. Lines that cannot
+// be written are rejected, rather than failing the write, so that a bad line does not
+// cause the whole write to be retried.
+type lineWriter struct {
+	w           storage.PointsWriter
+	org, bucket platform.ID
+	precision   string
+	now         time.Time
+
+	// points are the exploded points of the batch, and lines the line of each point.
+	points []models.Point
+	lines  []int
+
+	rejected      []RejectedLine
+	rejectedCount int
+
+	// overloaded is set once a write fails because the engine is overloaded, as the
+	// decoders of CSV may wrap the error.
+	overloaded bool
+}
+
+func newLineWriter(w storage.PointsWriter, bucket *platform.Bucket, precision string) *lineWriter {
+	return &lineWriter{
+		w:         w,
+		org:       bucket.OrganizationID,
+		bucket:    bucket.ID,
+		precision: precision,
+		now:       time.Now(),
+	}
+}
+
+// writeAll writes the lines read from r. It returns an error if the points cannot be
+// written at all; rejected lines are reported by err.
+func (lw *lineWriter) writeAll(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxWriteLineSize)
+	scanner.Split(models.ScanLines)
+
+	line := 1
+	for scanner.Scan() {
+		b := scanner.Bytes()
+		if err := lw.add(line, b); err != nil {
+			return err
+		}
+		// A string field value may contain newlines.
+		line += 1 + bytes.Count(b, []byte{'\n'})
+	}
+	if err := scanner.Err(); err != nil {
+		if err == bufio.ErrTooLong {
+			return fmt.Errorf("line %d exceeds the maximum size of %d bytes", line, maxWriteLineSize)
+		}
+		return err
+	}
+	return lw.flush()
+}
+
+// writeRows writes the points of the rows decoded by decode, of a CSV or JSON write. Rows
+// are reported by their number, as their lines are not known. It returns an error if the
+// rows cannot be decoded or the points cannot be written at all.
+func (lw *lineWriter) writeRows(decode func(write.RowFunc) error) error {
+	err := decode(func(row int, pt models.Point, err error) error {
+		if err != nil {
+			lw.reject(row, RejectedParseError, err.Error())
+			return nil
+		}
+		return lw.addPoints(row, []models.Point{pt})
+	})
+	if err != nil {
+		return err
+	}
+	return lw.flush()
+}
+
+// add adds the points of a line to the batch, writing the batch once it is full.
+func (lw *lineWriter) add(line int, b []byte) error {
+	points, err := models.ParsePointsWithPrecision(b, lw.now, lw.precision)
+	if err != nil {
+		lw.reject(line, RejectedParseError, err.Error())
+		return nil
+	}
+	return lw.addPoints(line, points)
+}
+
+// addPoints adds the points of a line or row to the batch, writing the batch once it is full.
+func (lw *lineWriter) addPoints(line int, points []models.Point) error {
+	exploded, err := tsdb.ExplodePoints(lw.org, lw.bucket, points)
+	if err != nil {
+		lw.reject(line, RejectedInvalidKey, err.Error())
+		return nil
+	}
+
+	for _, pt := range exploded {
+		lw.points = append(lw.points, pt)
+		lw.lines = append(lw.lines, line)
+	}
+
+	if len(lw.points) >= writeBatchSize {
+		return lw.flush()
+	}
+	return nil
+}
+
+// flush writes the points of the batch.
+func (lw *lineWriter) flush() error {
+	if len(lw.points) == 0 {
+		return nil
+	}
+	defer func() {
+		lw.points, lw.lines = lw.points[:0], lw.lines[:0]
+	}()
+
+	err := lw.w.WritePoints(lw.points)
+	if err == tsdb.ErrFieldTypeConflict {
+		// The conflicting points are not known, so write each line by itself.
+		return lw.flushLines()
+	}
+	return lw.checkWrite(err, lw.points, lw.lines)
+}
+
+// flushLines writes the points of the batch one line at a time.
+func (lw *lineWriter) flushLines() error {
+	for i := 0; i < len(lw.points); {
+		j := i + 1
+		for j < len(lw.points) && lw.lines[j] == lw.lines[i] {
+			j++
+		}
+
+		err := lw.w.WritePoints(lw.points[i:j])
+		if err == tsdb.ErrFieldTypeConflict {
+			lw.reject(lw.lines[i], RejectedFieldTypeConflict, err.Error())
+		} else if err := lw.checkWrite(err, lw.points[i:j], lw.lines[i:j]); err != nil {
+			return err
+		}
+		i = j
+	}
+	return nil
+}
+
+// checkWrite rejects the lines of the points dropped by a write of points that
+// returned err. It returns err if the write failed altogether.
+func (lw *lineWriter) checkWrite(err error, points []models.Point, lines []int) error {
+	if _, ok := err.(storage.OverloadedError); ok {
+		lw.overloaded = true
+		return err
+	}
+
+	pwe, ok := err.(tsdb.PartialWriteError)
+	if !ok {
+		return err
+	}
+
+	dropped := make(map[string]struct{}, len(pwe.DroppedKeys))
+	for _, k := range pwe.DroppedKeys {
+		dropped[string(k)] = struct{}{}
+	}
+
+	last := 0
+	for i, pt := range points {
+		if lines[i] == last {
+			continue
+		}
+		if c, ok := pwe.Conflicts[i]; ok {
+			lw.reject(lines[i], RejectedFieldTypeConflict, c.Error())
+			last = lines[i]
+		} else if m, ok := pwe.SchemaMismatches[i]; ok {
+			lw.reject(lines[i], RejectedSchemaMismatch, m.Error())
+			last = lines[i]
+		} else if _, ok := dropped[string(pt.Key())]; ok {
+			lw.reject(lines[i], RejectedInvalidKey, pwe.Reason)
+			last = lines[i]
+		}
+	}
+	return nil
+}
+
+// reject records that line was rejected. Lines whose points are rejected when written
+// follow lines rejected later in the same batch when parsed, so the lines are sorted
+// before the list is cut to the first maxRejectedLines, which are then the same
+// whatever order the lines were rejected in.
+func (lw *lineWriter) reject(line int, reason, msg string) {
+	lw.rejectedCount++
+	lw.rejected = append(lw.rejected, RejectedLine{Line: line, Reason: reason, Message: msg})
+	if len(lw.rejected) >= 2*maxRejectedLines {
+		lw.truncateRejected()
+	}
+}
+
+// truncateRejected sorts the rejected lines and keeps the first maxRejectedLines.
+func (lw *lineWriter) truncateRejected() {
+	sort.SliceStable(lw.rejected, func(i, j int) bool { return lw.rejected[i].Line < lw.rejected[j].Line })
+	if len(lw.rejected) > maxRejectedLines {
+		lw.rejected = lw.rejected[:maxRejectedLines]
+	}
+}
+
+// err returns the lines rejected from the write, or nil if every line was written.
+func (lw *lineWriter) err() *LineProtocolError {
+	if lw.rejectedCount == 0 {
+		return nil
+	}
+
+	lw.truncateRejected()
+
+	return &LineProtocolError{
+		Code:          platform.EInvalid,
+		Message:       fmt.Sprintf("partial write: %d lines rejected, first at line %d: %s", lw.rejectedCount, lw.rejected[0].Line, lw.rejected[0].Message),
+		Line:          lw.rejected[0].Line,
+		RejectedCount: lw.rejectedCount,
+		Rejected:      lw.rejected,
+	}
+}
@@ -198,6 +198,18 @@ func (s *Service) UpdateBucket(ctx context.Context, id platform.ID, upd platform
 		b.RetentionPeriod = *upd.RetentionPeriod
 	}
 
+	if upd.PartitionDuration != nil {
+		b.PartitionDuration = *upd.PartitionDuration
+	}
+
+	if upd.SchemaType != nil {
+		b.SchemaType = *upd.SchemaType
+	}
+
+	if upd.Schema != nil {
+		b.Schema = upd.Schema
+	}
+
 	s.bucketKV.Store(b.ID.String(), b)
 
 	return b, nil
@@ -0,0 +1,84 @@
+package inmem
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/influxdata/platform"
+	kerrors "github.com/influxdata/platform/kit/errors"
+)
+
+var _ platform.ListenerService = (*Service)(nil)
+
+func (s *Service) loadListener(id platform.ID) (*platform.Listener, error) {
+	i, ok := s.listenerKV.Load(id.String())
+	if !ok {
+		return nil, kerrors.Errorf(kerrors.NotFound, "listener with ID %v not found", id)
+	}
+
+	l, ok := i.(platform.Listener)
+	if !ok {
+		return nil, fmt.Errorf("type %T is not a listener", i)
+	}
+	return &l, nil
+}
+
+// FindListenerByID implements the platform.ListenerService interface.
+func (s *Service) FindListenerByID(ctx context.Context, id platform.ID) (*platform.Listener, error) {
+	return s.loadListener(id)
+}
+
+// FindListeners implements the platform.ListenerService interface.
+func (s *Service) FindListeners(ctx context.Context) ([]*platform.Listener, error) {
+	var err error
+	listeners := []*platform.Listener{}
+	s.listenerKV.Range(func(_, v interface{}) bool {
+		l, ok := v.(platform.Listener)
+		if !ok {
+			err = fmt.Errorf("type %T is not a listener", v)
+			return false
+		}
+		listeners = append(listeners, &l)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return listeners, nil
+}
+
+// CreateListener implements the platform.ListenerService interface.
+func (s *Service) CreateListener(ctx context.Context, l *platform.Listener) error {
+	l.ID = s.IDGenerator.ID()
+	return s.PutListener(ctx, l)
+}
+
+// UpdateListener implements the platform.ListenerService interface.
+func (s *Service) UpdateListener(ctx context.Context, id platform.ID, upd platform.ListenerUpdate) (*platform.Listener, error) {
+	l, err := s.loadListener(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := upd.Apply(l); err != nil {
+		return nil, kerrors.InvalidDataf("%v", err)
+	}
+	if err := s.PutListener(ctx, l); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// DeleteListener implements the platform.ListenerService interface.
+func (s *Service) DeleteListener(ctx context.Context, id platform.ID) error {
+	if _, err := s.loadListener(id); err != nil {
+		return err
+	}
+	s.listenerKV.Delete(id.String())
+	return nil
+}
+
+// PutListener stores a listener without setting its ID.
+func (s *Service) PutListener(ctx context.Context, l *platform.Listener) error {
+	s.listenerKV.Store(l.ID.String(), *l)
+	return nil
+}
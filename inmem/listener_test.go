@@ -0,0 +1,27 @@
+package inmem
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/platform"
+	platformtesting "github.com/influxdata/platform/testing"
+)
+
+func initListenerService(f platformtesting.ListenerFields, t *testing.T) (platform.ListenerService, func()) {
+	s := NewService()
+	s.IDGenerator = f.IDGenerator
+
+	ctx := context.TODO()
+	for _, l := range f.Listeners {
+		if err := s.PutListener(ctx, l); err != nil {
+			t.Fatalf("failed to populate listeners")
+		}
+	}
+
+	return s, func() {}
+}
+
+func TestListenerService(t *testing.T) {
+	platformtesting.ListenerService(initListenerService, t)
+}
@@ -0,0 +1,87 @@
+package inmem
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/influxdata/platform"
+	kerrors "github.com/influxdata/platform/kit/errors"
+)
+
+var _ platform.ReplicationService = (*Service)(nil)
+
+func (s *Service) loadReplication(id platform.ID) (*platform.Replication, error) {
+	i, ok := s.replicationKV.Load(id.String())
+	if !ok {
+		return nil, kerrors.Errorf(kerrors.NotFound, "replication with ID %v not found", id)
+	}
+
+	r, ok := i.(platform.Replication)
+	if !ok {
+		return nil, fmt.Errorf("type %T is not a replication", i)
+	}
+	return &r, nil
+}
+
+// FindReplicationByID implements the platform.ReplicationService interface.
+func (s *Service) FindReplicationByID(ctx context.Context, id platform.ID) (*platform.Replication, error) {
+	return s.loadReplication(id)
+}
+
+// FindReplications implements the platform.ReplicationService interface.
+func (s *Service) FindReplications(ctx context.Context) ([]*platform.Replication, error) {
+	var err error
+	replications := []*platform.Replication{}
+	s.replicationKV.Range(func(_, v interface{}) bool {
+		r, ok := v.(platform.Replication)
+		if !ok {
+			err = fmt.Errorf("type %T is not a replication", v)
+			return false
+		}
+		replications = append(replications, &r)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return replications, nil
+}
+
+// CreateReplication implements the platform.ReplicationService interface.
+func (s *Service) CreateReplication(ctx context.Context, r *platform.Replication) error {
+	if err := r.Valid(); err != nil {
+		return kerrors.InvalidDataf("%v", err)
+	}
+	r.ID = s.IDGenerator.ID()
+	return s.PutReplication(ctx, r)
+}
+
+// UpdateReplication implements the platform.ReplicationService interface.
+func (s *Service) UpdateReplication(ctx context.Context, id platform.ID, upd platform.ReplicationUpdate) (*platform.Replication, error) {
+	r, err := s.loadReplication(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := upd.Apply(r); err != nil {
+		return nil, kerrors.InvalidDataf("%v", err)
+	}
+	if err := s.PutReplication(ctx, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// DeleteReplication implements the platform.ReplicationService interface.
+func (s *Service) DeleteReplication(ctx context.Context, id platform.ID) error {
+	if _, err := s.loadReplication(id); err != nil {
+		return err
+	}
+	s.replicationKV.Delete(id.String())
+	return nil
+}
+
+// PutReplication stores a replication without setting its ID.
+func (s *Service) PutReplication(ctx context.Context, r *platform.Replication) error {
+	s.replicationKV.Store(r.ID.String(), *r)
+	return nil
+}
@@ -0,0 +1,27 @@
+package inmem
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/platform"
+	platformtesting "github.com/influxdata/platform/testing"
+)
+
+func initReplicationService(f platformtesting.ReplicationFields, t *testing.T) (platform.ReplicationService, func()) {
+	s := NewService()
+	s.IDGenerator = f.IDGenerator
+
+	ctx := context.TODO()
+	for _, l := range f.Replications {
+		if err := s.PutReplication(ctx, l); err != nil {
+			t.Fatalf("failed to populate replications")
+		}
+	}
+
+	return s, func() {}
+}
+
+func TestReplicationService(t *testing.T) {
+	platformtesting.ReplicationService(initReplicationService, t)
+}
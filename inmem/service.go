@@ -24,6 +24,8 @@ type Service struct {
 	dbrpMappingKV         sync.Map
 	userResourceMappingKV sync.Map
 	scraperTargetKV       sync.Map
+	listenerKV            sync.Map
+	replicationKV         sync.Map
 	telegrafConfigKV      sync.Map
 	onboardingKV          sync.Map
 	basicAuthKV           sync.Map
@@ -57,3 +57,12 @@ func BoltFile() (string, error) {
 
 	return file, nil
 }
+
+// EnginePath returns the default path of the storage engine files of influxd.
+func EnginePath() (string, error) {
+	dir, err := InfluxDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "engine"), nil
+}
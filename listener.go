@@ -0,0 +1,161 @@
+package platform
+
+import (
+	"context"
+	"fmt"
+)
+
+// ListenerService describes a service for managing listeners of line protocol.
+type ListenerService interface {
+	// FindListenerByID finds a single listener by its ID.
+	FindListenerByID(ctx context.Context, id ID) (*Listener, error)
+
+	// FindListeners returns all listeners.
+	FindListeners(ctx context.Context) ([]*Listener, error)
+
+	// CreateListener creates a new listener and assigns it an ID.
+	CreateListener(ctx context.Context, l *Listener) error
+
+	// UpdateListener updates a single listener with a changeset.
+	UpdateListener(ctx context.Context, id ID, upd ListenerUpdate) (*Listener, error)
+
+	// DeleteListener removes a listener by its ID.
+	DeleteListener(ctx context.Context, id ID) error
+}
+
+// ListenerProtocol is the protocol that a listener receives line protocol over.
+type ListenerProtocol string
+
+// Protocols of listeners.
+const (
+	// ListenerProtocolUDP listeners receive lines in UDP packets.
+	ListenerProtocolUDP ListenerProtocol = "udp"
+	// ListenerProtocolTCP listeners receive newline-delimited lines over TCP connections.
+	ListenerProtocolTCP ListenerProtocol = "tcp"
+)
+
+// ListenerFormat is the format of the lines that a listener receives.
+type ListenerFormat string
+
+// Formats of listeners.
+const (
+	// ListenerFormatLineProtocol listeners receive line protocol.
+	ListenerFormatLineProtocol ListenerFormat = "lp"
+	// ListenerFormatGraphite listeners receive the Graphite plaintext protocol.
+	ListenerFormatGraphite ListenerFormat = "graphite"
+)
+
+// ListenerGraphite configures how a Graphite listener turns the dotted paths of metrics
+// into the measurement, tags and field of points. See graphite.Options.
+type ListenerGraphite struct {
+	Separator string            `json:"separator,omitempty"`
+	Templates []string          `json:"templates,omitempty"`
+	Tags      map[string]string `json:"tags,omitempty"`
+}
+
+// Listener receives line protocol or Graphite over UDP or TCP, for devices that cannot
+// write over HTTP, and writes its points to a bucket.
+type Listener struct {
+	ID       ID               `json:"id,omitempty"`
+	Name     string           `json:"name"`
+	Protocol ListenerProtocol `json:"protocol"`
+	// BindAddress is the address to listen on, such as ":8089".
+	BindAddress    string `json:"bindAddress"`
+	OrganizationID ID     `json:"organizationID,omitempty"`
+	BucketID       ID     `json:"bucketID,omitempty"`
+	// Token is the token of an authorization to write to the bucket. The listener does
+	// not run if the authorization is not active or cannot write to the bucket. It is
+	// never returned over HTTP.
+	Token string `json:"token,omitempty"`
+	// Format is the format of the lines, line protocol if empty.
+	Format ListenerFormat `json:"format,omitempty"`
+	// Precision is the precision of the timestamps of line protocol, nanoseconds if
+	// empty. Graphite timestamps are always seconds.
+	Precision string `json:"precision,omitempty"`
+	// Graphite configures the templates of a Graphite listener.
+	Graphite *ListenerGraphite `json:"graphite,omitempty"`
+}
+
+// Valid returns an error if a listener is missing a required field, or has an unknown
+// protocol, format or precision. The Graphite templates are validated by the listener
+// package.
+func (l *Listener) Valid() error {
+	switch {
+	case l.Name == "":
+		return fmt.Errorf("name empty")
+	case l.BindAddress == "":
+		return fmt.Errorf("bind address empty")
+	case !l.OrganizationID.Valid():
+		return fmt.Errorf("organization id invalid")
+	case !l.BucketID.Valid():
+		return fmt.Errorf("bucket id invalid")
+	case l.Token == "":
+		return fmt.Errorf("token empty")
+	}
+
+	switch l.Protocol {
+	case ListenerProtocolUDP, ListenerProtocolTCP:
+	default:
+		return fmt.Errorf("unknown protocol %q", l.Protocol)
+	}
+
+	switch l.Precision {
+	case "", "n", "ns", "u", "us", "ms", "s":
+	default:
+		return fmt.Errorf("unknown precision %q", l.Precision)
+	}
+
+	switch l.Format {
+	case "", ListenerFormatLineProtocol, ListenerFormatGraphite:
+	default:
+		return fmt.Errorf("unknown format %q", l.Format)
+	}
+	return nil
+}
+
+// ListenerUpdate is a changeset of a listener. Only fields that are set are updated.
+type ListenerUpdate struct {
+	Name           *string           `json:"name,omitempty"`
+	Protocol       *ListenerProtocol `json:"protocol,omitempty"`
+	BindAddress    *string           `json:"bindAddress,omitempty"`
+	OrganizationID *ID               `json:"organizationID,omitempty"`
+	BucketID       *ID               `json:"bucketID,omitempty"`
+	Token          *string           `json:"token,omitempty"`
+	Format         *ListenerFormat   `json:"format,omitempty"`
+	Precision      *string           `json:"precision,omitempty"`
+	// Graphite replaces the Graphite options of the listener.
+	Graphite *ListenerGraphite `json:"graphite,omitempty"`
+}
+
+// Apply applies the fields that are set to a listener, and returns an error if the
+// updated listener is not valid.
+func (u *ListenerUpdate) Apply(l *Listener) error {
+	if u.Name != nil {
+		l.Name = *u.Name
+	}
+	if u.Protocol != nil {
+		l.Protocol = *u.Protocol
+	}
+	if u.BindAddress != nil {
+		l.BindAddress = *u.BindAddress
+	}
+	if u.OrganizationID != nil {
+		l.OrganizationID = *u.OrganizationID
+	}
+	if u.BucketID != nil {
+		l.BucketID = *u.BucketID
+	}
+	if u.Token != nil {
+		l.Token = *u.Token
+	}
+	if u.Format != nil {
+		l.Format = *u.Format
+	}
+	if u.Precision != nil {
+		l.Precision = *u.Precision
+	}
+	if u.Graphite != nil {
+		l.Graphite = u.Graphite
+	}
+	return l.Valid()
+}
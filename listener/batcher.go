@@ -0,0 +1,240 @@
+package listener
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/influxdata/platform"
+	"github.com/influxdata/platform/graphite"
+	"github.com/influxdata/platform/models"
+	"github.com/influxdata/platform/storage"
+	"github.com/influxdata/platform/tsdb"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+const (
+	// batchSize is the number of points that are buffered before they are written.
+	batchSize = 5000
+
+	// batchInterval is the maximum time that points are buffered before they are written.
+	batchInterval = time.Second
+
+	// pendingLines is the number of received lines that may wait to be parsed. Lines
+	// received over UDP are dropped once it is reached; TCP connections block.
+	pendingLines = 10000
+)
+
+// parseFunc returns the points of a received line.
+type parseFunc func(line []byte, now time.Time) ([]models.Point, error)
+
+// Validate returns an error if a listener is not valid or, for a Graphite listener, if
+// its templates cannot be parsed.
+func Validate(l *platform.Listener) error {
+	if err := l.Valid(); err != nil {
+		return err
+	}
+	if l.Format != platform.ListenerFormatGraphite {
+		return nil
+	}
+	if _, err := graphite.NewParser(graphiteOptions(l.Graphite)); err != nil {
+		return fmt.Errorf("invalid graphite options: %v", err)
+	}
+	return nil
+}
+
+// graphiteOptions returns the options of the graphite.Parser of a Graphite listener.
+func graphiteOptions(g *platform.ListenerGraphite) graphite.Options {
+	if g == nil {
+		return graphite.Options{}
+	}
+	return graphite.Options{Separator: g.Separator, Templates: g.Templates, Tags: g.Tags}
+}
+
+// newParseFunc returns the parseFunc of the format of l.
+func newParseFunc(l *platform.Listener) (parseFunc, error) {
+	if l.Format != platform.ListenerFormatGraphite {
+		return func(line []byte, now time.Time) ([]models.Point, error) {
+			return models.ParsePointsWithPrecision(line, now, l.Precision)
+		}, nil
+	}
+
+	p, err := graphite.NewParser(graphiteOptions(l.Graphite))
+	if err != nil {
+		return nil, err
+	}
+	return func(line []byte, now time.Time) ([]models.Point, error) {
+		pt, err := p.Parse(string(line), now)
+		if err != nil {
+			return nil, err
+		}
+		return []models.Point{pt}, nil
+	}, nil
+}
+
+// batcher parses the lines received by a listener and writes their points in batches.
+type batcher struct {
+	w           storage.PointsWriter
+	org, bucket platform.ID
+	parse       parseFunc
+	logger      *zap.Logger
+
+	received, dropped, malformed prometheus.Counter
+
+	lines chan []byte
+	done  chan struct{}
+
+	mu     sync.RWMutex
+	closed bool
+
+	// points are the exploded points of the batch, and lines the index of the
+	// received line of each point within the batch.
+	points  []models.Point
+	indexes []int
+	n       int
+}
+
+func newBatcher(w storage.PointsWriter, l *platform.Listener, m *metrics, logger *zap.Logger) (*batcher, error) {
+	parse, err := newParseFunc(l)
+	if err != nil {
+		return nil, err
+	}
+
+	labels := prometheus.Labels{"listener_id": l.ID.String(), "protocol": string(l.Protocol)}
+	b := &batcher{
+		w:         w,
+		org:       l.OrganizationID,
+		bucket:    l.BucketID,
+		parse:     parse,
+		logger:    logger,
+		received:  m.Received.With(labels),
+		dropped:   m.Dropped.With(labels),
+		malformed: m.Malformed.With(labels),
+		lines:     make(chan []byte, pendingLines),
+		done:      make(chan struct{}),
+	}
+	go b.run()
+	return b, nil
+}
+
+// add adds a received line to be written. If wait is false the line is dropped
+// rather than waiting for the lines before it to be parsed.
+func (b *batcher) add(line []byte, wait bool) {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 || line[0] == '#' {
+		return
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return
+	}
+
+	b.received.Inc()
+	if wait {
+		b.lines <- line
+		return
+	}
+	select {
+	case b.lines <- line:
+	default:
+		b.dropped.Inc()
+	}
+}
+
+// close writes the lines that have been added and stops the batcher.
+func (b *batcher) close() {
+	b.mu.Lock()
+	if !b.closed {
+		b.closed = true
+		close(b.lines)
+	}
+	b.mu.Unlock()
+	<-b.done
+}
+
+func (b *batcher) run() {
+	defer close(b.done)
+
+	ticker := time.NewTicker(batchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case line, ok := <-b.lines:
+			if !ok {
+				b.flush()
+				return
+			}
+			b.batch(line)
+			if len(b.points) >= batchSize {
+				b.flush()
+			}
+		case <-ticker.C:
+			b.flush()
+		}
+	}
+}
+
+// batch adds the points of a line to the batch.
+func (b *batcher) batch(line []byte) {
+	points, err := b.parse(line, time.Now())
+	if err != nil {
+		b.malformed.Inc()
+		return
+	}
+
+	exploded, err := tsdb.ExplodePoints(b.org, b.bucket, points)
+	if err != nil {
+		b.malformed.Inc()
+		return
+	}
+
+	for _, pt := range exploded {
+		b.points = append(b.points, pt)
+		b.indexes = append(b.indexes, b.n)
+	}
+	b.n++
+}
+
+// flush writes the points of the batch, counting the lines whose points are dropped.
+func (b *batcher) flush() {
+	if len(b.points) == 0 {
+		return
+	}
+	defer func() {
+		b.points, b.indexes, b.n = b.points[:0], b.indexes[:0], 0
+	}()
+
+	err := b.w.WritePoints(b.points)
+	if err == nil {
+		return
+	}
+
+	pwe, ok := err.(tsdb.PartialWriteError)
+	if !ok {
+		b.logger.Info("Failed to write points", zap.Int("lines", b.n), zap.Error(err))
+		b.dropped.Add(float64(b.n))
+		return
+	}
+
+	keys := make(map[string]struct{}, len(pwe.DroppedKeys))
+	for _, k := range pwe.DroppedKeys {
+		keys[string(k)] = struct{}{}
+	}
+
+	dropped := make(map[int]struct{})
+	for i, pt := range b.points {
+		if _, ok := pwe.Conflicts[i]; ok {
+			dropped[b.indexes[i]] = struct{}{}
+		} else if _, ok := pwe.SchemaMismatches[i]; ok {
+			dropped[b.indexes[i]] = struct{}{}
+		} else if _, ok := keys[string(pt.Key())]; ok {
+			dropped[b.indexes[i]] = struct{}{}
+		}
+	}
+	b.dropped.Add(float64(len(dropped)))
+}
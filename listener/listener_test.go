@@ -0,0 +1,244 @@
+package listener_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/influxdata/platform"
+	"github.com/influxdata/platform/inmem"
+	"github.com/influxdata/platform/kit/prom"
+	"github.com/influxdata/platform/kit/prom/promtest"
+	"github.com/influxdata/platform/listener"
+	"github.com/influxdata/platform/mock"
+	"github.com/influxdata/platform/tsdb"
+)
+
+const (
+	orgID    = platform.ID(0x1000)
+	bucketID = platform.ID(0x2000)
+)
+
+func newManager(t *testing.T) (*listener.Manager, *listener.Service, *mock.PointsWriter, *prom.Registry) {
+	t.Helper()
+
+	as := mock.NewAuthorizationService()
+	as.FindAuthorizationByTokenFn = func(_ context.Context, token string) (*platform.Authorization, error) {
+		if token != "write" {
+			return nil, fmt.Errorf("authorization not found")
+		}
+		return &platform.Authorization{
+			Token:       token,
+			Status:      platform.Active,
+			Permissions: []platform.Permission{platform.WriteBucketPermission(bucketID)},
+		}, nil
+	}
+
+	bs := mock.NewBucketService()
+	bs.FindBucketByIDFn = func(_ context.Context, id platform.ID) (*platform.Bucket, error) {
+		return &platform.Bucket{ID: id, OrganizationID: orgID}, nil
+	}
+
+	ls := inmem.NewService()
+	w := &mock.PointsWriter{}
+	m := listener.NewManager(ls, bs, as, w)
+
+	reg := prom.NewRegistry()
+	reg.MustRegister(m.PrometheusCollectors()...)
+	return m, &listener.Service{ListenerService: ls, Manager: m}, w, reg
+}
+
+func newListener(protocol platform.ListenerProtocol, token string) *platform.Listener {
+	return &platform.Listener{
+		Name:           "devices",
+		Protocol:       protocol,
+		BindAddress:    "127.0.0.1:0",
+		OrganizationID: orgID,
+		BucketID:       bucketID,
+		Token:          token,
+		Precision:      "s",
+	}
+}
+
+// waitReceived waits until n lines have been received by the listener id.
+func waitReceived(t *testing.T, reg *prom.Registry, id platform.ID, protocol platform.ListenerProtocol, n float64) {
+	t.Helper()
+
+	labels := map[string]string{"listener_id": id.String(), "protocol": string(protocol)}
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		m := promtest.FindMetric(promtest.MustGather(t, reg), "listener_received_lines_total", labels)
+		if m != nil && m.GetCounter().GetValue() >= n {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %v lines to be received", n)
+}
+
+func counter(t *testing.T, reg *prom.Registry, name string, l *platform.Listener) float64 {
+	t.Helper()
+
+	labels := map[string]string{"listener_id": l.ID.String(), "protocol": string(l.Protocol)}
+	return promtest.MustFindMetric(t, promtest.MustGather(t, reg), name, labels).GetCounter().GetValue()
+}
+
+func TestManager(t *testing.T) {
+	for _, protocol := range []platform.ListenerProtocol{platform.ListenerProtocolUDP, platform.ListenerProtocolTCP} {
+		t.Run(string(protocol), func(t *testing.T) {
+			m, svc, w, reg := newManager(t)
+			defer m.Close()
+
+			ctx := context.Background()
+			l := newListener(protocol, "write")
+			if err := svc.CreateListener(ctx, l); err != nil {
+				t.Fatal(err)
+			}
+
+			addr := m.Addr(l.ID)
+			if addr == nil {
+				t.Fatal("listener is not running")
+			}
+
+			conn, err := net.Dial(addr.Network(), addr.String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := conn.Write([]byte("cpu,host=a usage=1 1541030400\nbad line\ncpu,host=b usage=2 1541030400\n")); err != nil {
+				t.Fatal(err)
+			}
+			conn.Close()
+
+			waitReceived(t, reg, l.ID, protocol, 3)
+			m.Close()
+
+			if got, want := len(w.Points), 2; got != want {
+				t.Fatalf("got %d points, want %d", got, want)
+			}
+			for _, pt := range w.Points {
+				if got := pt.Tags().GetString(tsdb.MeasurementTagKey); got != "cpu" {
+					t.Errorf("got measurement %q, want cpu", got)
+				}
+				if got, want := pt.Time(), time.Unix(1541030400, 0); !got.Equal(want) {
+					t.Errorf("got time %v, want %v", got, want)
+				}
+				if name := pt.Name(); len(name) != 16 {
+					t.Errorf("point is not exploded into its org and bucket: %q", name)
+				}
+			}
+
+			if got := counter(t, reg, "listener_malformed_lines_total", l); got != 1 {
+				t.Errorf("got %v malformed lines, want 1", got)
+			}
+			if got := counter(t, reg, "listener_dropped_lines_total", l); got != 0 {
+				t.Errorf("got %v dropped lines, want 0", got)
+			}
+		})
+	}
+}
+
+func TestManager_Graphite(t *testing.T) {
+	m, svc, w, reg := newManager(t)
+	defer m.Close()
+
+	ctx := context.Background()
+	l := newListener(platform.ListenerProtocolTCP, "write")
+	l.Format = platform.ListenerFormatGraphite
+	l.Graphite = &platform.ListenerGraphite{
+		Templates: []string{"servers.* .host.measurement.field*"},
+		Tags:      map[string]string{"dc": "east"},
+	}
+	if err := svc.CreateListener(ctx, l); err != nil {
+		t.Fatal(err)
+	}
+
+	addr := m.Addr(l.ID)
+	if addr == nil {
+		t.Fatal("listener is not running")
+	}
+	conn, err := net.Dial("tcp", addr.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Write([]byte("servers.a.cpu.load.1m 0.5 1541030400\nservers.a.cpu NaN 1541030400\n")); err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+
+	waitReceived(t, reg, l.ID, l.Protocol, 2)
+	m.Close()
+
+	if got, want := len(w.Points), 1; got != want {
+		t.Fatalf("got %d points, want %d", got, want)
+	}
+	tags := w.Points[0].Tags()
+	for k, want := range map[string]string{tsdb.MeasurementTagKey: "cpu", tsdb.FieldKeyTagKey: "load.1m", "host": "a", "dc": "east"} {
+		if got := tags.GetString(k); got != want {
+			t.Errorf("got tag %s=%q, want %q", k, got, want)
+		}
+	}
+
+	if got := counter(t, reg, "listener_malformed_lines_total", l); got != 1 {
+		t.Errorf("got %v malformed lines, want 1", got)
+	}
+}
+
+func TestManager_WriteError(t *testing.T) {
+	m, svc, w, reg := newManager(t)
+	defer m.Close()
+	w.ForceError(fmt.Errorf("engine closed"))
+
+	ctx := context.Background()
+	l := newListener(platform.ListenerProtocolTCP, "write")
+	if err := svc.CreateListener(ctx, l); err != nil {
+		t.Fatal(err)
+	}
+
+	addr := m.Addr(l.ID)
+	conn, err := net.Dial("tcp", addr.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Write([]byte("cpu usage=1\ncpu usage=2\n")); err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+
+	waitReceived(t, reg, l.ID, l.Protocol, 2)
+	m.Close()
+
+	if got := counter(t, reg, "listener_dropped_lines_total", l); got != 2 {
+		t.Errorf("got %v dropped lines, want 2", got)
+	}
+}
+
+func TestManager_Unauthorized(t *testing.T) {
+	m, svc, _, _ := newManager(t)
+	defer m.Close()
+
+	ctx := context.Background()
+	l := newListener(platform.ListenerProtocolUDP, "read")
+	if err := svc.CreateListener(ctx, l); err != nil {
+		t.Fatal(err)
+	}
+	if addr := m.Addr(l.ID); addr != nil {
+		t.Fatalf("listener with an invalid token is running on %v", addr)
+	}
+
+	token := "write"
+	if _, err := svc.UpdateListener(ctx, l.ID, platform.ListenerUpdate{Token: &token}); err != nil {
+		t.Fatal(err)
+	}
+	if m.Addr(l.ID) == nil {
+		t.Fatal("listener is not running after its token was updated")
+	}
+
+	if err := svc.DeleteListener(ctx, l.ID); err != nil {
+		t.Fatal(err)
+	}
+	if addr := m.Addr(l.ID); addr != nil {
+		t.Fatalf("deleted listener is running on %v", addr)
+	}
+}
@@ -0,0 +1,264 @@
+// Package listener runs the UDP and TCP listeners of line protocol and Graphite that
+// are configured as resources of a platform.ListenerService.
+package listener
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/influxdata/platform"
+	"github.com/influxdata/platform/storage"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// DefaultSyncInterval is how often the running listeners are synced with their
+// configuration, so that revoked tokens stop their listeners.
+const DefaultSyncInterval = 10 * time.Second
+
+// server is a running listener.
+type server interface {
+	Addr() net.Addr
+	Close() error
+}
+
+// running is a listener and the server that runs it.
+type running struct {
+	l platform.Listener
+	s server
+}
+
+// Manager runs the listeners of a ListenerService. A listener runs only while its token
+// is of an active authorization that can write to its bucket.
+type Manager struct {
+	Listeners            platform.ListenerService
+	BucketService        platform.BucketService
+	AuthorizationService platform.AuthorizationService
+	PointsWriter         storage.PointsWriter
+
+	Logger       *zap.Logger
+	SyncInterval time.Duration
+
+	mu      sync.Mutex
+	running map[platform.ID]*running
+	// errs are the reasons that listeners could not be started, so that they are
+	// logged only when they change.
+	errs    map[platform.ID]string
+	metrics *metrics
+}
+
+// NewManager returns a manager of the listeners of ls.
+func NewManager(ls platform.ListenerService, bs platform.BucketService, as platform.AuthorizationService, w storage.PointsWriter) *Manager {
+	return &Manager{
+		Listeners:            ls,
+		BucketService:        bs,
+		AuthorizationService: as,
+		PointsWriter:         w,
+		Logger:               zap.NewNop(),
+		SyncInterval:         DefaultSyncInterval,
+		running:              make(map[platform.ID]*running),
+		errs:                 make(map[platform.ID]string),
+		metrics:              newMetrics(),
+	}
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (m *Manager) PrometheusCollectors() []prometheus.Collector {
+	return m.metrics.PrometheusCollectors()
+}
+
+// Run syncs the listeners every SyncInterval until ctx is done, and then closes them.
+func (m *Manager) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.SyncInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := m.Sync(ctx); err != nil {
+			m.Logger.Info("Failed to sync listeners", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			m.Close()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sync starts the listeners that are not running, restarts those whose configuration
+// has changed, and stops those that have been deleted or are no longer authorized.
+func (m *Manager) Sync(ctx context.Context) error {
+	ls, err := m.Listeners.FindListeners(ctx)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	found := make(map[platform.ID]struct{}, len(ls))
+	for _, l := range ls {
+		found[l.ID] = struct{}{}
+
+		r, ok := m.running[l.ID]
+		if ok && reflect.DeepEqual(r.l, *l) {
+			err = m.authorize(ctx, l)
+		} else {
+			if ok {
+				m.stop(l.ID)
+			}
+			if err = m.authorize(ctx, l); err == nil {
+				err = m.start(l)
+			}
+		}
+
+		if err != nil {
+			m.stop(l.ID)
+			if msg := err.Error(); m.errs[l.ID] != msg {
+				m.errs[l.ID] = msg
+				m.Logger.Info("Listener not running", zap.Stringer("listener_id", l.ID), zap.String("name", l.Name), zap.Error(err))
+			}
+			continue
+		}
+		delete(m.errs, l.ID)
+	}
+
+	for id := range m.running {
+		if _, ok := found[id]; !ok {
+			m.stop(id)
+		}
+	}
+	for id := range m.errs {
+		if _, ok := found[id]; !ok {
+			delete(m.errs, id)
+		}
+	}
+	return nil
+}
+
+// authorize returns an error if the token of l cannot write to its bucket.
+func (m *Manager) authorize(ctx context.Context, l *platform.Listener) error {
+	a, err := m.AuthorizationService.FindAuthorizationByToken(ctx, l.Token)
+	if err != nil {
+		return fmt.Errorf("invalid token: %v", err)
+	}
+	if !a.Allowed(platform.WriteBucketPermission(l.BucketID)) {
+		return fmt.Errorf("token is not authorized to write to bucket %s", l.BucketID)
+	}
+
+	b, err := m.BucketService.FindBucketByID(ctx, l.BucketID)
+	if err != nil {
+		return err
+	}
+	if b.OrganizationID != l.OrganizationID {
+		return fmt.Errorf("bucket %s is not in organization %s", l.BucketID, l.OrganizationID)
+	}
+	return nil
+}
+
+// start runs l. It must be called with mu held.
+func (m *Manager) start(l *platform.Listener) error {
+	logger := m.Logger.With(zap.Stringer("listener_id", l.ID), zap.String("protocol", string(l.Protocol)))
+	b, err := newBatcher(m.PointsWriter, l, m.metrics, logger)
+	if err != nil {
+		return err
+	}
+
+	var s server
+	switch l.Protocol {
+	case platform.ListenerProtocolUDP:
+		s, err = listenUDP(l.BindAddress, b, logger)
+	case platform.ListenerProtocolTCP:
+		s, err = listenTCP(l.BindAddress, b, logger)
+	default:
+		err = fmt.Errorf("unknown protocol %q", l.Protocol)
+	}
+	if err != nil {
+		b.close()
+		return err
+	}
+
+	m.running[l.ID] = &running{l: *l, s: s}
+	logger.Info("Listener started", zap.Stringer("addr", s.Addr()))
+	return nil
+}
+
+// stop closes the listener id if it is running. It must be called with mu held.
+func (m *Manager) stop(id platform.ID) {
+	r, ok := m.running[id]
+	if !ok {
+		return
+	}
+	delete(m.running, id)
+
+	if err := r.s.Close(); err != nil {
+		m.Logger.Info("Failed to close listener", zap.Stringer("listener_id", id), zap.Error(err))
+	}
+}
+
+// Addr returns the address that the listener id is bound to, or nil if it is not running.
+func (m *Manager) Addr(id platform.ID) net.Addr {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if r, ok := m.running[id]; ok {
+		return r.s.Addr()
+	}
+	return nil
+}
+
+// Close stops every listener, writing the lines that they have received.
+func (m *Manager) Close() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for id := range m.running {
+		m.stop(id)
+	}
+}
+
+// Service is a ListenerService that syncs the listeners of a Manager when they are
+// created, updated or deleted, rather than at its next sync.
+type Service struct {
+	platform.ListenerService
+	Manager *Manager
+}
+
+// CreateListener creates a listener and starts it.
+func (s *Service) CreateListener(ctx context.Context, l *platform.Listener) error {
+	if err := s.ListenerService.CreateListener(ctx, l); err != nil {
+		return err
+	}
+	s.sync(ctx)
+	return nil
+}
+
+// UpdateListener updates a listener and restarts it.
+func (s *Service) UpdateListener(ctx context.Context, id platform.ID, upd platform.ListenerUpdate) (*platform.Listener, error) {
+	l, err := s.ListenerService.UpdateListener(ctx, id, upd)
+	if err != nil {
+		return nil, err
+	}
+	s.sync(ctx)
+	return l, nil
+}
+
+// DeleteListener deletes a listener and stops it.
+func (s *Service) DeleteListener(ctx context.Context, id platform.ID) error {
+	if err := s.ListenerService.DeleteListener(ctx, id); err != nil {
+		return err
+	}
+	s.sync(ctx)
+	return nil
+}
+
+func (s *Service) sync(ctx context.Context) {
+	if err := s.Manager.Sync(ctx); err != nil {
+		s.Manager.Logger.Info("Failed to sync listeners", zap.Error(err))
+	}
+}
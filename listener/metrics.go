@@ -0,0 +1,47 @@
+package listener
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// namespace is the leading part of all published metrics for the listeners.
+const namespace = "listener"
+
+// metrics are the counts of the lines of each listener, labelled by the ID and protocol
+// of the listener.
+type metrics struct {
+	Received  *prometheus.CounterVec
+	Dropped   *prometheus.CounterVec
+	Malformed *prometheus.CounterVec
+}
+
+func newMetrics() *metrics {
+	names := []string{"listener_id", "protocol"}
+
+	return &metrics{
+		Received: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "received_lines_total",
+			Help:      "Number of lines received.",
+		}, names),
+
+		Dropped: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "dropped_lines_total",
+			Help:      "Number of lines received whose points could not be written.",
+		}, names),
+
+		Malformed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "malformed_lines_total",
+			Help:      "Number of lines received that could not be parsed.",
+		}, names),
+	}
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (m *metrics) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		m.Received,
+		m.Dropped,
+		m.Malformed,
+	}
+}
@@ -0,0 +1,114 @@
+package listener
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/influxdata/platform/models"
+	"go.uber.org/zap"
+)
+
+// maxTCPLineSize is the maximum size in bytes of a line received over TCP.
+const maxTCPLineSize = 1024 * 1024
+
+// tcpServer receives newline-delimited lines over TCP connections.
+type tcpServer struct {
+	ln     net.Listener
+	b      *batcher
+	logger *zap.Logger
+
+	mu    sync.Mutex
+	conns map[net.Conn]struct{}
+	wg    sync.WaitGroup
+}
+
+func listenTCP(addr string, b *batcher, logger *zap.Logger) (*tcpServer, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &tcpServer{ln: ln, b: b, logger: logger, conns: make(map[net.Conn]struct{})}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+func (s *tcpServer) serve() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			if !isClosed(err) {
+				s.logger.Info("Failed to accept TCP connection", zap.Error(err))
+				continue
+			}
+			return
+		}
+
+		s.mu.Lock()
+		if s.conns == nil {
+			// The server is closed.
+			s.mu.Unlock()
+			conn.Close()
+			return
+		}
+		s.conns[conn] = struct{}{}
+		s.wg.Add(1)
+		s.mu.Unlock()
+
+		go s.handle(conn)
+	}
+}
+
+// handle adds the lines of a connection until it is closed.
+func (s *tcpServer) handle(conn net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		conn.Close()
+	}()
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(nil, maxTCPLineSize)
+	scanner.Split(models.ScanLines)
+	for scanner.Scan() {
+		// The scanner reuses its buffer, so the line is copied.
+		s.b.add(append([]byte(nil), scanner.Bytes()...), true)
+	}
+	if err := scanner.Err(); err != nil && !isClosed(err) {
+		s.logger.Info("Failed to read TCP connection", zap.Stringer("remote_addr", conn.RemoteAddr()), zap.Error(err))
+	}
+}
+
+// Addr returns the address that the server is bound to.
+func (s *tcpServer) Addr() net.Addr {
+	return s.ln.Addr()
+}
+
+// Close closes the listener and its connections, and writes the lines that have
+// been received.
+func (s *tcpServer) Close() error {
+	err := s.ln.Close()
+
+	s.mu.Lock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.conns = nil
+	s.mu.Unlock()
+
+	s.wg.Wait()
+	s.b.close()
+	return err
+}
+
+// isClosed returns whether err is from a use of a closed connection.
+func isClosed(err error) bool {
+	return strings.Contains(err.Error(), "use of closed network connection")
+}
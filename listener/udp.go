@@ -0,0 +1,70 @@
+package listener
+
+import (
+	"bytes"
+	"net"
+
+	"go.uber.org/zap"
+)
+
+// maxUDPPacketSize is the maximum size of a UDP packet.
+const maxUDPPacketSize = 64 * 1024
+
+// udpServer receives lines in UDP packets. A packet may contain many lines.
+type udpServer struct {
+	conn   *net.UDPConn
+	b      *batcher
+	logger *zap.Logger
+	done   chan struct{}
+}
+
+func listenUDP(addr string, b *batcher, logger *zap.Logger) (*udpServer, error) {
+	uaddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenUDP("udp", uaddr)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &udpServer{conn: conn, b: b, logger: logger, done: make(chan struct{})}
+	go s.serve()
+	return s, nil
+}
+
+func (s *udpServer) serve() {
+	defer close(s.done)
+
+	buf := make([]byte, maxUDPPacketSize)
+	for {
+		n, _, err := s.conn.ReadFromUDP(buf)
+		if err != nil {
+			if !isClosed(err) {
+				s.logger.Info("Failed to read UDP packet", zap.Error(err))
+				continue
+			}
+			return
+		}
+
+		// The buffer is reused, so the lines of the packet are copied.
+		packet := make([]byte, n)
+		copy(packet, buf[:n])
+		for _, line := range bytes.Split(packet, []byte{'\n'}) {
+			s.b.add(line, false)
+		}
+	}
+}
+
+// Addr returns the address that the server is bound to.
+func (s *udpServer) Addr() net.Addr {
+	return s.conn.LocalAddr()
+}
+
+// Close stops receiving packets and writes the lines that have been received.
+func (s *udpServer) Close() error {
+	err := s.conn.Close()
+	<-s.done
+	s.b.close()
+	return err
+}
@@ -19,6 +19,11 @@ type TaskService struct {
 	FindRunByIDFn  func(context.Context, platform.ID, platform.ID) (*platform.Run, error)
 	CancelRunFn    func(context.Context, platform.ID, platform.ID) error
 	RetryRunFn     func(context.Context, platform.ID, platform.ID, int64) error
+
+	FindTaskRevisionsFn func(context.Context, platform.ID) ([]*platform.TaskRevision, int, error)
+	FindTaskRevisionFn  func(context.Context, platform.ID, int) (*platform.TaskRevision, error)
+	RollbackTaskFn      func(context.Context, platform.ID, int) (*platform.Task, error)
+	FindTaskStatsFn     func(context.Context, platform.ID) (*platform.TaskStats, error)
 }
 
 func (s *TaskService) FindTaskByID(ctx context.Context, id platform.ID) (*platform.Task, error) {
@@ -61,3 +66,19 @@ func (s *TaskService) CancelRun(ctx context.Context, taskID, runID platform.ID)
 func (s *TaskService) RetryRun(ctx context.Context, taskID, runID platform.ID, requestedAt int64) error {
 	return s.RetryRunFn(ctx, taskID, runID, requestedAt)
 }
+
+func (s *TaskService) FindTaskRevisions(ctx context.Context, taskID platform.ID) ([]*platform.TaskRevision, int, error) {
+	return s.FindTaskRevisionsFn(ctx, taskID)
+}
+
+func (s *TaskService) FindTaskRevision(ctx context.Context, taskID platform.ID, revision int) (*platform.TaskRevision, error) {
+	return s.FindTaskRevisionFn(ctx, taskID, revision)
+}
+
+func (s *TaskService) RollbackTask(ctx context.Context, taskID platform.ID, revision int) (*platform.Task, error) {
+	return s.RollbackTaskFn(ctx, taskID, revision)
+}
+
+func (s *TaskService) FindTaskStats(ctx context.Context, taskID platform.ID) (*platform.TaskStats, error) {
+	return s.FindTaskStatsFn(ctx, taskID)
+}
@@ -394,6 +394,27 @@ func ParsePointsWithPrecision(buf []byte, defaultTime time.Time, precision strin
 
 }
 
+// ScanLines is a split function for a bufio.Scanner that returns each line of
+// line protocol, without its trailing newline. Unlike bufio.ScanLines, a newline
+// within a string field value does not end the line.
+func ScanLines(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+
+	i, line := scanLine(data, 0)
+	switch {
+	case atEOF && i >= len(data):
+		return len(data), line, nil
+	case atEOF || i < len(data)-1:
+		return i + 1, line, nil
+	}
+
+	// Whether the line ends here may depend on the characters that follow an
+	// escape, so request more data.
+	return 0, nil, nil
+}
+
 func parsePoint(buf []byte, defaultTime time.Time, precision string) (Point, error) {
 	// scan the first block which is measurement[,tag1=value1,tag2=value=2...]
 	pos, key, err := scanKey(buf, 0)
@@ -1,6 +1,7 @@
 package models_test
 
 import (
+	"bufio"
 	"bytes"
 	"fmt"
 	"io"
@@ -10,6 +11,7 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"testing/iotest"
 	"time"
 
 	"github.com/influxdata/platform/models"
@@ -2522,6 +2524,33 @@ func BenchmarkTags_HashKey(b *testing.B) {
 	}
 }
 
+func TestScanLines(t *testing.T) {
+	input := "cpu value=1\n\nlog msg=\"a\nb\" 2\nlog,host=a\\\nb msg=\"c\"\ncpu value=3"
+	exp := []string{
+		"cpu value=1",
+		"",
+		"log msg=\"a\nb\" 2",
+		"log,host=a\\\nb msg=\"c\"",
+		"cpu value=3",
+	}
+
+	// Reading a byte at a time splits lines at every position.
+	for _, r := range []io.Reader{strings.NewReader(input), iotest.OneByteReader(strings.NewReader(input))} {
+		scanner := bufio.NewScanner(r)
+		scanner.Split(models.ScanLines)
+		var got []string
+		for scanner.Scan() {
+			got = append(got, scanner.Text())
+		}
+		if err := scanner.Err(); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, exp) {
+			t.Fatalf("got lines %q, expected %q", got, exp)
+		}
+	}
+}
+
 func BenchmarkMakeKey(b *testing.B) {
 	benchmarks := []struct {
 		m []byte
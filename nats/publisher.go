@@ -52,3 +52,38 @@ func (p *AsyncPublisher) Publish(subject string, r io.Reader) error {
 	_, err = p.Connection.PublishAsync(subject, data, ah)
 	return err
 }
+
+// SyncPublisher publishes messages and waits for the server to persist them, so that
+// a message is not lost once Publish returns.
+type SyncPublisher struct {
+	ClientID   string
+	Connection stan.Conn
+}
+
+func NewSyncPublisher(clientID string) *SyncPublisher {
+	return &SyncPublisher{ClientID: clientID}
+}
+
+// Open creates and maintains a connection to NATS server
+func (p *SyncPublisher) Open() error {
+	sc, err := stan.Connect(ServerName, p.ClientID)
+	if err != nil {
+		return err
+	}
+	p.Connection = sc
+	return nil
+}
+
+// Publish publishes the contents of r to subject, returning once the server has
+// acknowledged it.
+func (p *SyncPublisher) Publish(subject string, r io.Reader) error {
+	if p.Connection == nil {
+		return ErrNoNatsConnection
+	}
+
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return p.Connection.Publish(subject, data)
+}
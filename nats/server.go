@@ -2,6 +2,7 @@ package nats
 
 import (
 	"errors"
+	"time"
 
 	stand "github.com/nats-io/nats-streaming-server/server"
 	"github.com/nats-io/nats-streaming-server/stores"
@@ -23,6 +24,9 @@ func (s *Server) Open() error {
 	opts.StoreType = stores.TypeFile
 	opts.ID = ServerName
 	opts.FilestoreDir = s.config.FilestoreDir
+	for name, l := range s.config.Channels {
+		opts.StoreLimits.AddPerChannel(name, l.storeLimits())
+	}
 	server, err := stand.RunServerWithOpts(opts, nil)
 	if err != nil {
 		return err
@@ -42,6 +46,37 @@ func (s *Server) Close() {
 type Config struct {
 	// The directory where nats persists message information
 	FilestoreDir string
+
+	// Channels are the limits of channels by name. Other channels keep at most the
+	// 1000000 messages or 1GB of the defaults of the server.
+	Channels map[string]ChannelLimits
+}
+
+// ChannelLimits are the limits of the messages that a channel keeps. Once a channel
+// exceeds a limit, the server discards its oldest messages, whether or not they have
+// been acknowledged. Zero is no limit.
+type ChannelLimits struct {
+	MaxMsgs  int
+	MaxBytes int64
+	MaxAge   time.Duration
+}
+
+// storeLimits returns the limits of a channel of the store, where a limit of zero
+// inherits the default and a negative limit is no limit.
+func (l ChannelLimits) storeLimits() *stores.ChannelLimits {
+	cl := &stores.ChannelLimits{
+		MsgStoreLimits: stores.MsgStoreLimits{MaxMsgs: l.MaxMsgs, MaxBytes: l.MaxBytes, MaxAge: l.MaxAge},
+	}
+	if cl.MaxMsgs == 0 {
+		cl.MaxMsgs = -1
+	}
+	if cl.MaxBytes == 0 {
+		cl.MaxBytes = -1
+	}
+	if cl.MaxAge == 0 {
+		cl.MaxAge = -1
+	}
+	return cl
 }
 
 // NewServer creates and returns a new server struct from the provided config
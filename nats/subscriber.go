@@ -1,6 +1,9 @@
 package nats
 
 import (
+	"sync"
+	"time"
+
 	stan "github.com/nats-io/go-nats-streaming"
 )
 
@@ -12,10 +15,21 @@ type Subscriber interface {
 type QueueSubscriber struct {
 	ClientID   string
 	Connection stan.Conn
+
+	// AckWait is how long the server waits for a delivered message to be acknowledged
+	// before it redelivers it.
+	AckWait time.Duration
+	// MaxInflight is the number of messages that may be delivered to the subscriber
+	// without being acknowledged.
+	MaxInflight int
 }
 
 func NewQueueSubscriber(clientID string) *QueueSubscriber {
-	return &QueueSubscriber{ClientID: clientID}
+	return &QueueSubscriber{
+		ClientID:    clientID,
+		AckWait:     stan.DefaultAckWait,
+		MaxInflight: 25,
+	}
 }
 
 // Open creates and maintains a connection to NATS server
@@ -30,11 +44,18 @@ func (s *QueueSubscriber) Open() error {
 
 type messageHandler struct {
 	handler Handler
-	sub     subscription
+
+	// sub is set once the subscription is made, which may be after the first
+	// messages of a durable subscription are delivered.
+	mu  sync.RWMutex
+	sub subscription
 }
 
 func (mh *messageHandler) handle(m *stan.Msg) {
-	mh.handler.Process(mh.sub, &message{m: m})
+	mh.mu.RLock()
+	sub := mh.sub
+	mh.mu.RUnlock()
+	mh.handler.Process(sub, &message{m: m})
 }
 
 func (s *QueueSubscriber) Subscribe(subject, group string, handler Handler) error {
@@ -42,11 +63,13 @@ func (s *QueueSubscriber) Subscribe(subject, group string, handler Handler) erro
 		return ErrNoNatsConnection
 	}
 
-	mh := messageHandler{handler: handler}
-	sub, err := s.Connection.QueueSubscribe(subject, group, mh.handle, stan.DurableName(group), stan.SetManualAckMode(), stan.MaxInflight(25))
+	mh := &messageHandler{handler: handler}
+	sub, err := s.Connection.QueueSubscribe(subject, group, mh.handle, stan.DurableName(group), stan.SetManualAckMode(), stan.AckWait(s.AckWait), stan.MaxInflight(s.MaxInflight))
 	if err != nil {
 		return err
 	}
+	mh.mu.Lock()
 	mh.sub = subscription{sub: sub}
+	mh.mu.Unlock()
 	return nil
 }
@@ -18,14 +18,23 @@ type subscription struct {
 }
 
 func (s subscription) Pending() (int64, int64, error) {
+	if s.sub == nil {
+		return 0, 0, ErrNoNatsConnection
+	}
 	messages, bytes, err := s.sub.Pending()
 	return int64(messages), int64(bytes), err
 }
 
 func (s subscription) Delivered() (int64, error) {
+	if s.sub == nil {
+		return 0, ErrNoNatsConnection
+	}
 	return s.sub.Delivered()
 }
 
 func (s subscription) Close() error {
+	if s.sub == nil {
+		return ErrNoNatsConnection
+	}
 	return s.sub.Close()
 }
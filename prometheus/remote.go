@@ -0,0 +1,359 @@
+package prometheus
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/types"
+	"github.com/influxdata/influxdb/prometheus/remote"
+	"github.com/influxdata/platform/gather"
+	"github.com/influxdata/platform/models"
+	"github.com/influxdata/platform/storage/reads"
+	"github.com/influxdata/platform/storage/reads/datatypes"
+	"github.com/influxdata/platform/tsdb"
+	"github.com/influxdata/platform/tsdb/cursors"
+)
+
+// metricNameLabel is the label of the name of a Prometheus metric.
+const metricNameLabel = "__name__"
+
+// The fields of the samples of Prometheus metrics. Remote writes, like gather scrapes of
+// untyped metrics, write the value field. gather writes counters and gauges to the
+// counter and gauge fields, and the sample sums and counts of summaries and histograms
+// to the sum and count fields.
+const (
+	valueField   = "value"
+	counterField = "counter"
+	gaugeField   = "gauge"
+	sumField     = "sum"
+	countField   = "count"
+)
+
+// ErrMissingMetricName is returned when a time series has no metric name.
+var ErrMissingMetricName = errors.New("time series has no metric name")
+
+// WriteRequestToMetrics returns the samples of the time series of req as metrics, named
+// and tagged as gather names and tags the untyped metrics it scrapes. NaN samples are
+// dropped, as gather drops them.
+func WriteRequestToMetrics(req *remote.WriteRequest) ([]gather.Metrics, error) {
+	var ms []gather.Metrics
+	for _, ts := range req.Timeseries {
+		var name string
+		tags := make(map[string]string, len(ts.Labels))
+		for _, l := range ts.Labels {
+			if l.Name == metricNameLabel {
+				name = l.Value
+				continue
+			}
+			tags[l.Name] = l.Value
+		}
+		if name == "" {
+			return nil, ErrMissingMetricName
+		}
+
+		for _, s := range ts.Samples {
+			if math.IsNaN(s.Value) {
+				continue
+			}
+			ms = append(ms, gather.Metrics{
+				Name:      name,
+				Tags:      tags,
+				Fields:    map[string]interface{}{valueField: s.Value},
+				Timestamp: s.TimestampMs * int64(time.Millisecond),
+				Type:      gather.MetricTypeUntyped,
+			})
+		}
+	}
+	return ms, nil
+}
+
+// ReadQuery is a Prometheus remote read query translated to a read of storage.
+type ReadQuery struct {
+	// Request reads the series that may hold the samples of the query.
+	Request *datatypes.ReadRequest
+
+	// names match the metric names of the series read by Request, which storage
+	// cannot match since the names of the sums and counts of summaries and
+	// histograms are those of their measurement with a suffix.
+	names []func(name string) bool
+}
+
+// NewReadQuery returns q as a read of the storage source src, between the start and end
+// time of q.
+func NewReadQuery(q *remote.Query, src *types.Any) (*ReadQuery, error) {
+	rq := &ReadQuery{}
+
+	var root *datatypes.Node
+	for _, m := range q.Matchers {
+		match, err := labelMatcher(m)
+		if err != nil {
+			return nil, err
+		}
+
+		if m.Name != metricNameLabel {
+			root = andNode(root, matcherToNode(m))
+			continue
+		}
+
+		rq.names = append(rq.names, match)
+		if m.Type == remote.MatchType_EQUAL {
+			root = andNode(root, measurementNode(m.Value))
+		}
+	}
+
+	// Only the fields of samples that are read as a series of their own.
+	root = andNode(root, comparisonNode(datatypes.ComparisonRegex, tsdb.FieldKeyTagKey, &datatypes.Node{
+		NodeType: datatypes.NodeTypeLiteral,
+		Value: &datatypes.Node_RegexValue{
+			RegexValue: "^(?:" + strings.Join([]string{valueField, counterField, gaugeField, sumField, countField}, "|") + ")$",
+		},
+	}))
+
+	rq.Request = &datatypes.ReadRequest{
+		ReadSource: src,
+		Predicate:  &datatypes.Predicate{Root: root},
+		TimestampRange: datatypes.TimestampRange{
+			Start: q.StartTimestampMs * int64(time.Millisecond),
+			End:   q.EndTimestampMs * int64(time.Millisecond),
+		},
+		PointsLimit: math.MaxInt64,
+	}
+	return rq, nil
+}
+
+// labels returns the labels of the Prometheus time series of the series tagged with tags,
+// or false if the series is not read by q.
+func (q *ReadQuery) labels(tags models.Tags) ([]*remote.LabelPair, bool) {
+	name, ok := metricName(string(tags.Get(tsdb.MeasurementTagKeyBytes)), string(tags.Get(tsdb.FieldKeyTagKeyBytes)))
+	if !ok {
+		return nil, false
+	}
+	for _, match := range q.names {
+		if !match(name) {
+			return nil, false
+		}
+	}
+	return tagsToLabels(name, tags), true
+}
+
+// metricName returns the name of the Prometheus metric of the samples of field of
+// measurement, or false if the samples of field are not read as a series of their own.
+// The quantiles of summaries and the buckets of histograms are not, since storage does
+// not record which of the two a field is.
+func metricName(measurement, field string) (string, bool) {
+	switch field {
+	case valueField, counterField, gaugeField:
+		return measurement, true
+	case sumField, countField:
+		return measurement + "_" + field, true
+	default:
+		return "", false
+	}
+}
+
+// ReadResponseEncoder encodes the protocol buffer of a remote read response a series at
+// a time. The response is compressed as a whole, so it is held until it is written,
+// but only in its encoded form.
+type ReadResponseEncoder struct {
+	buf    proto.Buffer
+	result proto.Buffer
+
+	// Reused by each series.
+	ts      remote.TimeSeries
+	samples []remote.Sample
+}
+
+// EncodeResult encodes the series of rs, read by q, as the next result of the response.
+// A nil rs is encoded as a result without series.
+func (e *ReadResponseEncoder) EncodeResult(q *ReadQuery, rs reads.ResultSet) error {
+	e.result.Reset()
+	if rs != nil {
+		for rs.Next() {
+			if err := e.encodeSeries(q, rs); err != nil {
+				return err
+			}
+		}
+		if err := rs.Err(); err != nil {
+			return err
+		}
+	}
+
+	// results is field 1 of a ReadResponse.
+	if err := e.buf.EncodeVarint(1<<3 | proto.WireBytes); err != nil {
+		return err
+	}
+	return e.buf.EncodeRawBytes(e.result.Bytes())
+}
+
+func (e *ReadResponseEncoder) encodeSeries(q *ReadQuery, rs reads.ResultSet) error {
+	cur := rs.Cursor()
+	if cur == nil {
+		return nil
+	}
+	defer cur.Close()
+
+	labels, ok := q.labels(rs.Tags())
+	if !ok {
+		return nil
+	}
+
+	fc, ok := cur.(cursors.FloatArrayCursor)
+	if !ok {
+		return nil // Prometheus samples are floats.
+	}
+
+	e.samples = e.samples[:0]
+	for a := fc.Next(); a.Len() > 0; a = fc.Next() {
+		for i, t := range a.Timestamps {
+			e.samples = append(e.samples, remote.Sample{
+				TimestampMs: t / int64(time.Millisecond),
+				Value:       a.Values[i],
+			})
+		}
+	}
+	if err := fc.Err(); err != nil {
+		return err
+	}
+	if len(e.samples) == 0 {
+		return nil
+	}
+
+	e.ts.Labels = labels
+	e.ts.Samples = e.ts.Samples[:0]
+	for i := range e.samples {
+		e.ts.Samples = append(e.ts.Samples, &e.samples[i])
+	}
+
+	// timeseries is field 1 of a QueryResult.
+	if err := e.result.EncodeVarint(1<<3 | proto.WireBytes); err != nil {
+		return err
+	}
+	return e.result.EncodeMessage(&e.ts)
+}
+
+// Bytes returns the encoded response.
+func (e *ReadResponseEncoder) Bytes() []byte {
+	return e.buf.Bytes()
+}
+
+// tagsToLabels returns the tags of a series read from storage as the sorted labels of
+// the Prometheus time series named name.
+func tagsToLabels(name string, tags models.Tags) []*remote.LabelPair {
+	labels := make([]*remote.LabelPair, 0, len(tags))
+	labels = append(labels, &remote.LabelPair{Name: metricNameLabel, Value: name})
+	for _, t := range tags {
+		switch string(t.Key) {
+		case tsdb.MeasurementTagKey, tsdb.FieldKeyTagKey:
+			continue
+		}
+		labels = append(labels, &remote.LabelPair{Name: string(t.Key), Value: string(t.Value)})
+	}
+	sort.Slice(labels, func(i, j int) bool { return labels[i].Name < labels[j].Name })
+	return labels
+}
+
+// labelMatcher returns a func that matches the label values matched by m. As in
+// Prometheus, regular expressions match whole label values.
+func labelMatcher(m *remote.LabelMatcher) (func(string) bool, error) {
+	switch m.Type {
+	case remote.MatchType_EQUAL:
+		return func(v string) bool { return v == m.Value }, nil
+	case remote.MatchType_NOT_EQUAL:
+		return func(v string) bool { return v != m.Value }, nil
+	case remote.MatchType_REGEX_MATCH, remote.MatchType_REGEX_NO_MATCH:
+		re, err := regexp.Compile(anchoredRegex(m.Value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression of label %s: %v", m.Name, err)
+		}
+		if m.Type == remote.MatchType_REGEX_NO_MATCH {
+			return func(v string) bool { return !re.MatchString(v) }, nil
+		}
+		return re.MatchString, nil
+	default:
+		return nil, fmt.Errorf("unknown match type %v", m.Type)
+	}
+}
+
+// matcherToNode returns the comparison of the tag of the label matched by m, which
+// labelMatcher has validated. A series without the tag is compared as if its value were
+// empty, as Prometheus compares a series without the label.
+func matcherToNode(m *remote.LabelMatcher) *datatypes.Node {
+	switch m.Type {
+	case remote.MatchType_EQUAL, remote.MatchType_NOT_EQUAL:
+		op := datatypes.ComparisonEqual
+		if m.Type == remote.MatchType_NOT_EQUAL {
+			op = datatypes.ComparisonNotEqual
+		}
+		return comparisonNode(op, m.Name, &datatypes.Node{
+			NodeType: datatypes.NodeTypeLiteral,
+			Value:    &datatypes.Node_StringValue{StringValue: m.Value},
+		})
+	default:
+		op := datatypes.ComparisonRegex
+		if m.Type == remote.MatchType_REGEX_NO_MATCH {
+			op = datatypes.ComparisonNotRegex
+		}
+		return comparisonNode(op, m.Name, &datatypes.Node{
+			NodeType: datatypes.NodeTypeLiteral,
+			Value:    &datatypes.Node_RegexValue{RegexValue: anchoredRegex(m.Value)},
+		})
+	}
+}
+
+// measurementNode returns the comparison of the measurements of the series of the metric
+// named name: its own measurement, or, for the sum or count of a summary or histogram,
+// that of the summary or histogram.
+func measurementNode(name string) *datatypes.Node {
+	n := comparisonNode(datatypes.ComparisonEqual, tsdb.MeasurementTagKey, &datatypes.Node{
+		NodeType: datatypes.NodeTypeLiteral,
+		Value:    &datatypes.Node_StringValue{StringValue: name},
+	})
+	for _, suffix := range []string{"_" + sumField, "_" + countField} {
+		if base := strings.TrimSuffix(name, suffix); base != name && base != "" {
+			n = logicalNode(datatypes.LogicalOr, n, comparisonNode(datatypes.ComparisonEqual, tsdb.MeasurementTagKey, &datatypes.Node{
+				NodeType: datatypes.NodeTypeLiteral,
+				Value:    &datatypes.Node_StringValue{StringValue: base},
+			}))
+		}
+	}
+	return n
+}
+
+func anchoredRegex(re string) string {
+	return "^(?:" + re + ")$"
+}
+
+func comparisonNode(op datatypes.Node_Comparison, key string, value *datatypes.Node) *datatypes.Node {
+	return &datatypes.Node{
+		NodeType: datatypes.NodeTypeComparisonExpression,
+		Value:    &datatypes.Node_Comparison_{Comparison: op},
+		Children: []*datatypes.Node{
+			{
+				NodeType: datatypes.NodeTypeTagRef,
+				Value:    &datatypes.Node_TagRefValue{TagRefValue: key},
+			},
+			value,
+		},
+	}
+}
+
+func andNode(left, right *datatypes.Node) *datatypes.Node {
+	if left == nil {
+		return right
+	}
+	return logicalNode(datatypes.LogicalAnd, left, right)
+}
+
+func logicalNode(op datatypes.Node_Logical, left, right *datatypes.Node) *datatypes.Node {
+	return &datatypes.Node{
+		NodeType: datatypes.NodeTypeLogicalExpression,
+		Value:    &datatypes.Node_Logical_{Logical: op},
+		Children: []*datatypes.Node{left, right},
+	}
+}
@@ -0,0 +1,241 @@
+package prometheus_test
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/influxdata/influxdb/prometheus/remote"
+	"github.com/influxdata/platform/gather"
+	"github.com/influxdata/platform/models"
+	"github.com/influxdata/platform/prometheus"
+	"github.com/influxdata/platform/storage/reads"
+	"github.com/influxdata/platform/tsdb/cursors"
+)
+
+func TestWriteRequestToMetrics(t *testing.T) {
+	req := &remote.WriteRequest{
+		Timeseries: []*remote.TimeSeries{
+			{
+				Labels: []*remote.LabelPair{
+					{Name: "__name__", Value: "go_goroutines"},
+					{Name: "job", Value: "prometheus"},
+				},
+				Samples: []*remote.Sample{
+					{TimestampMs: 1000, Value: 36},
+					{TimestampMs: 2000, Value: math.NaN()},
+					{TimestampMs: 3000, Value: 37},
+				},
+			},
+		},
+	}
+
+	got, err := prometheus.WriteRequestToMetrics(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tags := map[string]string{"job": "prometheus"}
+	exp := []gather.Metrics{
+		{
+			Name:      "go_goroutines",
+			Tags:      tags,
+			Fields:    map[string]interface{}{"value": 36.0},
+			Timestamp: 1000000000,
+			Type:      gather.MetricTypeUntyped,
+		},
+		{
+			Name:      "go_goroutines",
+			Tags:      tags,
+			Fields:    map[string]interface{}{"value": 37.0},
+			Timestamp: 3000000000,
+			Type:      gather.MetricTypeUntyped,
+		},
+	}
+	if !reflect.DeepEqual(got, exp) {
+		t.Fatalf("got metrics %v, expected %v", got, exp)
+	}
+
+	req.Timeseries[0].Labels = req.Timeseries[0].Labels[1:]
+	if _, err := prometheus.WriteRequestToMetrics(req); err != prometheus.ErrMissingMetricName {
+		t.Fatalf("got error %v, expected %v", err, prometheus.ErrMissingMetricName)
+	}
+}
+
+func TestNewReadQuery(t *testing.T) {
+	const fields = `'_f' =~ /^(?:value|counter|gauge|sum|count)$/`
+	tests := []struct {
+		name     string
+		matchers []*remote.LabelMatcher
+		exp      string
+	}{
+		{
+			name: "no matchers",
+			exp:  fields,
+		},
+		{
+			name: "all match types",
+			matchers: []*remote.LabelMatcher{
+				{Type: remote.MatchType_EQUAL, Name: "__name__", Value: "go_goroutines"},
+				{Type: remote.MatchType_NOT_EQUAL, Name: "job", Value: "node"},
+				{Type: remote.MatchType_REGEX_MATCH, Name: "instance", Value: "a|b"},
+				{Type: remote.MatchType_REGEX_NO_MATCH, Name: "env", Value: "dev.*"},
+			},
+			exp: `'_m' = "go_goroutines" AND 'job' != "node" AND 'instance' =~ /^(?:a|b)$/ AND 'env' !~ /^(?:dev.*)$/ AND ` + fields,
+		},
+		{
+			name: "sum of summary",
+			matchers: []*remote.LabelMatcher{
+				{Type: remote.MatchType_EQUAL, Name: "__name__", Value: "rpc_duration_seconds_sum"},
+			},
+			// PredicateToExprString does not parenthesize the nested OR.
+			exp: `'_m' = "rpc_duration_seconds_sum" OR '_m' = "rpc_duration_seconds" AND ` + fields,
+		},
+		{
+			name: "name regex",
+			matchers: []*remote.LabelMatcher{
+				{Type: remote.MatchType_REGEX_MATCH, Name: "__name__", Value: "go_.*"},
+			},
+			exp: fields,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := prometheus.NewReadQuery(&remote.Query{
+				StartTimestampMs: 1,
+				EndTimestampMs:   2,
+				Matchers:         tt.matchers,
+			}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := reads.PredicateToExprString(q.Request.Predicate); got != tt.exp {
+				t.Errorf("got predicate %s, expected %s", got, tt.exp)
+			}
+			if q.Request.TimestampRange.Start != 1000000 || q.Request.TimestampRange.End != 2000000 {
+				t.Errorf("got time range %v, expected 1000000 to 2000000", q.Request.TimestampRange)
+			}
+		})
+	}
+
+	_, err := prometheus.NewReadQuery(&remote.Query{
+		Matchers: []*remote.LabelMatcher{{Type: remote.MatchType_REGEX_MATCH, Name: "job", Value: "("}},
+	}, nil)
+	if err == nil {
+		t.Error("expected error of invalid regular expression")
+	}
+}
+
+func TestReadResponseEncoder(t *testing.T) {
+	rs := &resultSet{
+		series: []series{
+			{
+				tags: models.NewTags(map[string]string{"_m": "go_goroutines", "_f": "value", "job": "prometheus"}),
+				ts:   []int64{1000000000, 2000000000},
+				vs:   []float64{36, 37},
+			},
+			{
+				tags: models.NewTags(map[string]string{"_m": "go_goroutines_sum", "_f": "value"}),
+				ts:   []int64{1000000000},
+				vs:   []float64{1},
+			},
+			{
+				tags: models.NewTags(map[string]string{"_m": "rpc_seconds", "_f": "sum"}),
+				ts:   []int64{1000000000},
+				vs:   []float64{2},
+			},
+			{
+				tags: models.NewTags(map[string]string{"_m": "rpc_seconds", "_f": "0.5"}),
+				ts:   []int64{1000000000},
+				vs:   []float64{3},
+			},
+		},
+	}
+
+	q, err := prometheus.NewReadQuery(&remote.Query{
+		Matchers: []*remote.LabelMatcher{
+			{Type: remote.MatchType_REGEX_MATCH, Name: "__name__", Value: "go_goroutines|rpc_seconds_sum"},
+		},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var enc prometheus.ReadResponseEncoder
+	if err := enc.EncodeResult(q, rs); err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.EncodeResult(q, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var got remote.ReadResponse
+	if err := proto.Unmarshal(enc.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	exp := remote.ReadResponse{
+		Results: []*remote.QueryResult{
+			{
+				Timeseries: []*remote.TimeSeries{
+					{
+						Labels: []*remote.LabelPair{
+							{Name: "__name__", Value: "go_goroutines"},
+							{Name: "job", Value: "prometheus"},
+						},
+						Samples: []*remote.Sample{
+							{TimestampMs: 1000, Value: 36},
+							{TimestampMs: 2000, Value: 37},
+						},
+					},
+					{
+						Labels:  []*remote.LabelPair{{Name: "__name__", Value: "rpc_seconds_sum"}},
+						Samples: []*remote.Sample{{TimestampMs: 1000, Value: 2}},
+					},
+				},
+			},
+			{},
+		},
+	}
+	if !reflect.DeepEqual(got, exp) {
+		t.Fatalf("got response %v, expected %v", got, exp)
+	}
+}
+
+type series struct {
+	tags models.Tags
+	ts   []int64
+	vs   []float64
+}
+
+type resultSet struct {
+	series []series
+	i      int
+}
+
+func (rs *resultSet) Next() bool {
+	rs.i++
+	return rs.i <= len(rs.series)
+}
+
+func (rs *resultSet) Cursor() cursors.Cursor {
+	s := rs.series[rs.i-1]
+	return &floatCursor{a: &cursors.FloatArray{Timestamps: s.ts, Values: s.vs}}
+}
+
+func (rs *resultSet) Tags() models.Tags { return rs.series[rs.i-1].tags }
+func (rs *resultSet) Close()            {}
+func (rs *resultSet) Err() error        { return nil }
+
+type floatCursor struct {
+	a *cursors.FloatArray
+}
+
+func (c *floatCursor) Next() *cursors.FloatArray {
+	a := c.a
+	c.a = &cursors.FloatArray{}
+	return a
+}
+
+func (c *floatCursor) Close()     {}
+func (c *floatCursor) Err() error { return nil }
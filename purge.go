@@ -0,0 +1,26 @@
+package platform
+
+import (
+	"context"
+	"time"
+)
+
+// BucketPurge records that the data of a deleted bucket, or of every bucket of a deleted
+// organization, has yet to be removed from storage.
+type BucketPurge struct {
+	OrganizationID ID
+	// BucketID is invalid when the whole organization was deleted.
+	BucketID  ID
+	CreatedAt time.Time
+}
+
+// BucketPurgeService tracks the purges of deleted buckets and organizations that have yet to complete.
+// A purge is recorded whenever a bucket or organization is deleted.
+type BucketPurgeService interface {
+	// FindBucketPurges returns all purges that have yet to complete, oldest first.
+	FindBucketPurges(ctx context.Context) ([]*BucketPurge, error)
+
+	// DeleteBucketPurge marks the purge of the given bucket, or of the whole organization
+	// if bucketID is invalid, as complete.
+	DeleteBucketPurge(ctx context.Context, orgID, bucketID ID) error
+}
@@ -21,28 +21,31 @@ func init() {
 
 func createFromSource(prSpec plan.ProcedureSpec, dsid execute.DatasetID, a execute.Administration) (execute.Source, error) {
 	spec := prSpec.(*inputs.FromProcedureSpec)
-	var w execute.Window
 	bounds := a.StreamContext().Bounds()
 	if bounds == nil {
 		return nil, errors.New("nil bounds passed to from")
 	}
 
+	duration := execute.Duration(bounds.Stop) - execute.Duration(bounds.Start)
+	w := execute.Window{
+		Every:  duration,
+		Period: duration,
+		Start:  bounds.Start,
+	}
+	currentTime := w.Start + execute.Time(w.Period)
+
+	// A window is only set together with an aggregate, which storage then applies
+	// to each window. As with the window transformation, the windows are aligned
+	// to the start of the window, if any, or else to the Unix epoch.
+	var windowEvery, windowOffset int64
 	if spec.WindowSet {
-		w = execute.Window{
-			Every:  execute.Duration(spec.Window.Every),
-			Period: execute.Duration(spec.Window.Period),
-			Round:  execute.Duration(spec.Window.Round),
-			Start:  bounds.Start,
-		}
-	} else {
-		duration := execute.Duration(bounds.Stop) - execute.Duration(bounds.Start)
-		w = execute.Window{
-			Every:  duration,
-			Period: duration,
-			Start:  bounds.Start,
+		every := execute.Duration(spec.Window.Every)
+		windowEvery = int64(every)
+		if !spec.Window.Start.IsZero() {
+			start := a.ResolveTime(spec.Window.Start)
+			windowOffset = int64(start - start.Truncate(every))
 		}
 	}
-	currentTime := w.Start + execute.Time(w.Period)
 
 	deps := a.Dependencies()[inputs.FromKind].(storage.Dependencies)
 	req := query.RequestFromContext(a.Context())
@@ -82,6 +85,8 @@ func createFromSource(prSpec plan.ProcedureSpec, dsid execute.DatasetID, a execu
 			GroupMode:       storage.GroupMode(spec.GroupMode),
 			GroupKeys:       spec.GroupKeys,
 			AggregateMethod: spec.AggregateMethod,
+			WindowEvery:     windowEvery,
+			WindowOffset:    windowOffset,
 		},
 		*bounds,
 		w,
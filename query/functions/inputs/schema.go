@@ -0,0 +1,355 @@
+package inputs
+
+import (
+	"fmt"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/functions/inputs"
+	"github.com/influxdata/flux/interpreter"
+	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/platform"
+	"github.com/influxdata/platform/query"
+	"github.com/influxdata/platform/query/functions/inputs/storage"
+	"github.com/pkg/errors"
+)
+
+// The tagKeys and tagValues functions list the tag keys, and the values of a tag, of the series
+// of a bucket from the index, without reading any data. The measurements and fields of a bucket
+// are the values of the _measurement and _field tags respectively.
+const (
+	TagKeysKind   = "tagKeys"
+	TagValuesKind = "tagValues"
+)
+
+type TagKeysOpSpec struct {
+	Bucket    string                       `json:"bucket,omitempty"`
+	BucketID  string                       `json:"bucketID,omitempty"`
+	Start     flux.Time                    `json:"start"`
+	Stop      flux.Time                    `json:"stop"`
+	Predicate *semantic.FunctionExpression `json:"predicate,omitempty"`
+}
+
+type TagValuesOpSpec struct {
+	TagKeysOpSpec
+	Tag string `json:"tag"`
+}
+
+func init() {
+	predicateType := semantic.NewFunctionPolyType(semantic.FunctionPolySignature{
+		Parameters: map[string]semantic.PolyType{
+			"r": semantic.Tvar(1),
+		},
+		Required: semantic.LabelSet{"r"},
+		Return:   semantic.Bool,
+	})
+	tagKeysSignature := semantic.FunctionPolySignature{
+		Parameters: map[string]semantic.PolyType{
+			"bucket":    semantic.String,
+			"bucketID":  semantic.String,
+			"start":     semantic.Tvar(2),
+			"stop":      semantic.Tvar(3),
+			"predicate": predicateType,
+		},
+		Required: nil,
+		Return:   flux.TableObjectType,
+	}
+	tagValuesSignature := semantic.FunctionPolySignature{
+		Parameters: map[string]semantic.PolyType{
+			"bucket":    semantic.String,
+			"bucketID":  semantic.String,
+			"tag":       semantic.String,
+			"start":     semantic.Tvar(2),
+			"stop":      semantic.Tvar(3),
+			"predicate": predicateType,
+		},
+		Required: semantic.LabelSet{"tag"},
+		Return:   flux.TableObjectType,
+	}
+
+	flux.RegisterFunction(TagKeysKind, createTagKeysOpSpec, tagKeysSignature)
+	flux.RegisterOpSpec(TagKeysKind, newTagKeysOp)
+	plan.RegisterProcedureSpec(TagKeysKind, newTagKeysProcedure, TagKeysKind)
+	execute.RegisterSource(TagKeysKind, createTagKeysSource)
+
+	flux.RegisterFunction(TagValuesKind, createTagValuesOpSpec, tagValuesSignature)
+	flux.RegisterOpSpec(TagValuesKind, newTagValuesOp)
+	plan.RegisterProcedureSpec(TagValuesKind, newTagValuesProcedure, TagValuesKind)
+	execute.RegisterSource(TagValuesKind, createTagValuesSource)
+}
+
+func createTagKeysOpSpec(args flux.Arguments, a *flux.Administration) (flux.OperationSpec, error) {
+	spec := new(TagKeysOpSpec)
+	if err := spec.readArgs(args); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+func createTagValuesOpSpec(args flux.Arguments, a *flux.Administration) (flux.OperationSpec, error) {
+	spec := new(TagValuesOpSpec)
+	if err := spec.readArgs(args); err != nil {
+		return nil, err
+	}
+
+	tag, err := args.GetRequiredString("tag")
+	if err != nil {
+		return nil, err
+	}
+	spec.Tag = tag
+	return spec, nil
+}
+
+// readArgs reads the arguments common to tagKeys and tagValues. Without a start or stop,
+// the time range is unbounded.
+func (s *TagKeysOpSpec) readArgs(args flux.Arguments) error {
+	if bucket, ok, err := args.GetString("bucket"); err != nil {
+		return err
+	} else if ok {
+		s.Bucket = bucket
+	}
+
+	if bucketID, ok, err := args.GetString("bucketID"); err != nil {
+		return err
+	} else if ok {
+		s.BucketID = bucketID
+	}
+
+	if s.Bucket == "" && s.BucketID == "" {
+		return errors.New("must specify one of bucket or bucketID")
+	}
+	if s.Bucket != "" && s.BucketID != "" {
+		return errors.New("must specify only one of bucket or bucketID")
+	}
+
+	if start, ok, err := args.GetTime("start"); err != nil {
+		return err
+	} else if ok {
+		s.Start = start
+	}
+
+	if stop, ok, err := args.GetTime("stop"); err != nil {
+		return err
+	} else if ok {
+		s.Stop = stop
+	}
+
+	if f, ok, err := args.GetFunction("predicate"); err != nil {
+		return err
+	} else if ok {
+		fn, err := interpreter.ResolveFunction(f)
+		if err != nil {
+			return err
+		}
+		s.Predicate = fn
+	}
+	return nil
+}
+
+func newTagKeysOp() flux.OperationSpec {
+	return new(TagKeysOpSpec)
+}
+
+func (s *TagKeysOpSpec) Kind() flux.OperationKind {
+	return TagKeysKind
+}
+
+func newTagValuesOp() flux.OperationSpec {
+	return new(TagValuesOpSpec)
+}
+
+func (s *TagValuesOpSpec) Kind() flux.OperationKind {
+	return TagValuesKind
+}
+
+type TagKeysProcedureSpec struct {
+	Bucket    string
+	BucketID  string
+	Start     flux.Time
+	Stop      flux.Time
+	Predicate *semantic.FunctionExpression
+}
+
+func newTagKeysProcedure(qs flux.OperationSpec, pa plan.Administration) (plan.ProcedureSpec, error) {
+	spec, ok := qs.(*TagKeysOpSpec)
+	if !ok {
+		return nil, fmt.Errorf("invalid spec type %T", qs)
+	}
+
+	return &TagKeysProcedureSpec{
+		Bucket:    spec.Bucket,
+		BucketID:  spec.BucketID,
+		Start:     spec.Start,
+		Stop:      spec.Stop,
+		Predicate: spec.Predicate,
+	}, nil
+}
+
+func (s *TagKeysProcedureSpec) Kind() plan.ProcedureKind {
+	return TagKeysKind
+}
+
+func (s *TagKeysProcedureSpec) Copy() plan.ProcedureSpec {
+	ns := *s
+	if s.Predicate != nil {
+		ns.Predicate = s.Predicate.Copy().(*semantic.FunctionExpression)
+	}
+	return &ns
+}
+
+type TagValuesProcedureSpec struct {
+	TagKeysProcedureSpec
+	Tag string
+}
+
+func newTagValuesProcedure(qs flux.OperationSpec, pa plan.Administration) (plan.ProcedureSpec, error) {
+	spec, ok := qs.(*TagValuesOpSpec)
+	if !ok {
+		return nil, fmt.Errorf("invalid spec type %T", qs)
+	}
+
+	tagKeys, err := newTagKeysProcedure(&spec.TagKeysOpSpec, pa)
+	if err != nil {
+		return nil, err
+	}
+	return &TagValuesProcedureSpec{
+		TagKeysProcedureSpec: *tagKeys.(*TagKeysProcedureSpec),
+		Tag:                  spec.Tag,
+	}, nil
+}
+
+func (s *TagValuesProcedureSpec) Kind() plan.ProcedureKind {
+	return TagValuesKind
+}
+
+func (s *TagValuesProcedureSpec) Copy() plan.ProcedureSpec {
+	return &TagValuesProcedureSpec{
+		TagKeysProcedureSpec: *s.TagKeysProcedureSpec.Copy().(*TagKeysProcedureSpec),
+		Tag:                  s.Tag,
+	}
+}
+
+// SchemaDecoder decodes the tag keys or tag values read from storage
+// into a single table with a _value column.
+type SchemaDecoder struct {
+	read   func() ([]string, error)
+	values []string
+	alloc  *memory.Allocator
+}
+
+func (sd *SchemaDecoder) Connect() error {
+	return nil
+}
+
+func (sd *SchemaDecoder) Fetch() (bool, error) {
+	values, err := sd.read()
+	if err != nil {
+		return false, err
+	}
+	sd.values = values
+	return false, nil
+}
+
+func (sd *SchemaDecoder) Decode() (flux.Table, error) {
+	gk, err := execute.NewGroupKeyBuilder(nil).Build()
+	if err != nil {
+		return nil, err
+	}
+
+	b := execute.NewColListTableBuilder(gk, sd.alloc)
+	if _, err := b.AddCol(flux.ColMeta{
+		Label: execute.DefaultValueColLabel,
+		Type:  flux.TString,
+	}); err != nil {
+		return nil, err
+	}
+
+	for _, v := range sd.values {
+		_ = b.AppendString(0, v)
+	}
+
+	return b.Table()
+}
+
+func createTagKeysSource(prSpec plan.ProcedureSpec, dsid execute.DatasetID, a execute.Administration) (execute.Source, error) {
+	spec, ok := prSpec.(*TagKeysProcedureSpec)
+	if !ok {
+		return nil, fmt.Errorf("invalid spec type %T", prSpec)
+	}
+
+	deps, tagKeysSpec, start, stop, err := newTagKeysSpec(spec, a)
+	if err != nil {
+		return nil, err
+	}
+
+	sd := &SchemaDecoder{
+		read: func() ([]string, error) {
+			return deps.Reader.ReadTagKeys(a.Context(), tagKeysSpec, start, stop)
+		},
+		alloc: a.Allocator(),
+	}
+	return inputs.CreateSourceFromDecoder(sd, dsid, a)
+}
+
+func createTagValuesSource(prSpec plan.ProcedureSpec, dsid execute.DatasetID, a execute.Administration) (execute.Source, error) {
+	spec, ok := prSpec.(*TagValuesProcedureSpec)
+	if !ok {
+		return nil, fmt.Errorf("invalid spec type %T", prSpec)
+	}
+
+	deps, tagKeysSpec, start, stop, err := newTagKeysSpec(&spec.TagKeysProcedureSpec, a)
+	if err != nil {
+		return nil, err
+	}
+
+	tagValuesSpec := storage.TagValuesSpec{TagKeysSpec: tagKeysSpec, TagKey: spec.Tag}
+	sd := &SchemaDecoder{
+		read: func() ([]string, error) {
+			return deps.Reader.ReadTagValues(a.Context(), tagValuesSpec, start, stop)
+		},
+		alloc: a.Allocator(),
+	}
+	return inputs.CreateSourceFromDecoder(sd, dsid, a)
+}
+
+// newTagKeysSpec resolves the bucket and time range of spec. An unset start or stop is
+// returned as zero, which storage treats as unbounded.
+func newTagKeysSpec(spec *TagKeysProcedureSpec, a execute.Administration) (deps storage.Dependencies, _ storage.TagKeysSpec, start, stop execute.Time, _ error) {
+	// the dependencies used for FromKind are adequate for what we need here
+	// so there's no need to inject custom dependencies for tagKeys() and tagValues()
+	deps = a.Dependencies()[inputs.FromKind].(storage.Dependencies)
+	req := query.RequestFromContext(a.Context())
+	if req == nil {
+		return deps, storage.TagKeysSpec{}, 0, 0, errors.New("missing request on context")
+	}
+	orgID := req.OrganizationID
+
+	var bucketID platform.ID
+	switch {
+	case spec.Bucket != "":
+		b, ok := deps.BucketLookup.Lookup(orgID, spec.Bucket)
+		if !ok {
+			return deps, storage.TagKeysSpec{}, 0, 0, fmt.Errorf("could not find bucket %q", spec.Bucket)
+		}
+		bucketID = b
+	case len(spec.BucketID) != 0:
+		if err := bucketID.DecodeFromString(spec.BucketID); err != nil {
+			return deps, storage.TagKeysSpec{}, 0, 0, err
+		}
+	}
+
+	if !spec.Start.IsZero() {
+		start = a.ResolveTime(spec.Start)
+	}
+	if !spec.Stop.IsZero() {
+		stop = a.ResolveTime(spec.Stop)
+	}
+
+	return deps, storage.TagKeysSpec{
+		OrganizationID: orgID,
+		BucketID:       bucketID,
+		Predicate:      spec.Predicate,
+	}, start, stop, nil
+}
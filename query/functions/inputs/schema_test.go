@@ -0,0 +1,77 @@
+package inputs_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/querytest"
+	_ "github.com/influxdata/platform/query/builtin"
+	"github.com/influxdata/platform/query/functions/inputs"
+)
+
+func TestTagKeys_NewQuery(t *testing.T) {
+	tests := []querytest.NewQueryTestCase{
+		{
+			Name:    "tagKeys no bucket",
+			Raw:     `tagKeys()`,
+			WantErr: true,
+		},
+		{
+			Name:    "tagKeys conflicting args",
+			Raw:     `tagKeys(bucket:"b", bucketID:"aaaabbbbccccdddd")`,
+			WantErr: true,
+		},
+		{
+			Name: "tagKeys",
+			Raw:  `tagKeys(bucket:"telegraf", start:-1h)`,
+			Want: &flux.Spec{
+				Operations: []*flux.Operation{
+					{
+						ID: "tagKeys0",
+						Spec: &inputs.TagKeysOpSpec{
+							Bucket: "telegraf",
+							Start: flux.Time{
+								Relative:   -1 * time.Hour,
+								IsRelative: true,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			Name:    "tagValues no tag",
+			Raw:     `tagValues(bucket:"telegraf")`,
+			WantErr: true,
+		},
+		{
+			Name: "tagValues",
+			Raw:  `tagValues(bucketID:"aaaabbbbccccdddd", tag:"_measurement", stop:-1h)`,
+			Want: &flux.Spec{
+				Operations: []*flux.Operation{
+					{
+						ID: "tagValues0",
+						Spec: &inputs.TagValuesOpSpec{
+							TagKeysOpSpec: inputs.TagKeysOpSpec{
+								BucketID: "aaaabbbbccccdddd",
+								Stop: flux.Time{
+									Relative:   -1 * time.Hour,
+									IsRelative: true,
+								},
+							},
+							Tag: "_measurement",
+						},
+					},
+				},
+			},
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			querytest.NewQueryTestHelper(t, tc)
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package storage
+
+import (
+	"math"
+
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/functions/inputs"
+	"github.com/influxdata/flux/functions/transformations"
+	"github.com/influxdata/flux/plan"
+)
+
+func init() {
+	for _, kind := range []plan.ProcedureKind{
+		transformations.MinKind,
+		transformations.MaxKind,
+		transformations.FirstKind,
+		transformations.LastKind,
+		transformations.MeanKind,
+	} {
+		plan.RegisterPhysicalRules(
+			PushDownAggregateRule{Kind: kind},
+			PushDownWindowAggregateRule{Kind: kind},
+		)
+	}
+}
+
+// PushDownAggregateRule pushes an aggregate or selector that reduces each series to a
+// single value into the `from` it reads, so that storage returns that value per series
+// rather than every point. Storage returns the values of a series unchanged when it cannot
+// compute the aggregate for their type, so the aggregate itself is left in the plan; applied
+// to the value computed by storage, it yields the same result.
+type PushDownAggregateRule struct {
+	Kind plan.ProcedureKind
+}
+
+func (rule PushDownAggregateRule) Name() string {
+	return "PushDownAggregateRule(" + string(rule.Kind) + ")"
+}
+
+func (rule PushDownAggregateRule) Pattern() plan.Pattern {
+	return plan.Pat(rule.Kind, plan.Pat(inputs.FromKind))
+}
+
+func (rule PushDownAggregateRule) Rewrite(node plan.PlanNode) (plan.PlanNode, bool, error) {
+	fromNode := node.Predecessors()[0]
+	fromSpec, ok := pushDownFromSpec(fromNode)
+	if !ok || !canPushDownAggregate(node.ProcedureSpec(), fromSpec) {
+		return node, false, nil
+	}
+
+	newFromSpec := fromSpec.Copy().(*inputs.FromProcedureSpec)
+	newFromSpec.AggregateSet = true
+	newFromSpec.AggregateMethod = string(rule.Kind)
+	if err := fromNode.ReplaceSpec(newFromSpec); err != nil {
+		return nil, false, err
+	}
+	return node, true, nil
+}
+
+// PushDownWindowAggregateRule pushes an aggregate or selector of the windows of a `from`
+// into the `from`, as `aggregateWindow` does, so that storage returns a value per window of
+// each series. As with PushDownAggregateRule, the window and aggregate are left in the plan.
+type PushDownWindowAggregateRule struct {
+	Kind plan.ProcedureKind
+}
+
+func (rule PushDownWindowAggregateRule) Name() string {
+	return "PushDownWindowAggregateRule(" + string(rule.Kind) + ")"
+}
+
+func (rule PushDownWindowAggregateRule) Pattern() plan.Pattern {
+	return plan.Pat(rule.Kind, plan.Pat(transformations.WindowKind, plan.Pat(inputs.FromKind)))
+}
+
+func (rule PushDownWindowAggregateRule) Rewrite(node plan.PlanNode) (plan.PlanNode, bool, error) {
+	windowNode := node.Predecessors()[0]
+	windowSpec := windowNode.ProcedureSpec().(*transformations.WindowProcedureSpec)
+	fromNode := windowNode.Predecessors()[0]
+	fromSpec, ok := pushDownFromSpec(fromNode)
+	if !ok || len(windowNode.Successors()) != 1 || !canPushDownAggregate(node.ProcedureSpec(), fromSpec) {
+		return node, false, nil
+	}
+
+	// Storage only computes fixed windows, over the time of each point.
+	w := windowSpec.Window
+	if w.Every <= 0 || w.Every == math.MaxInt64 || w.Period != w.Every || w.Round != 0 {
+		return node, false, nil
+	}
+	// Storage returns no value for a window without points, so it cannot create empty tables.
+	if windowSpec.CreateEmpty {
+		return node, false, nil
+	}
+	if !isDefaultColumn(windowSpec.TimeColumn, execute.DefaultTimeColLabel) ||
+		!isDefaultColumn(windowSpec.StartColumn, execute.DefaultStartColLabel) ||
+		!isDefaultColumn(windowSpec.StopColumn, execute.DefaultStopColLabel) {
+		return node, false, nil
+	}
+
+	newFromSpec := fromSpec.Copy().(*inputs.FromProcedureSpec)
+	newFromSpec.AggregateSet = true
+	newFromSpec.AggregateMethod = string(rule.Kind)
+	newFromSpec.WindowSet = true
+	newFromSpec.Window = w
+	if err := fromNode.ReplaceSpec(newFromSpec); err != nil {
+		return nil, false, err
+	}
+	return node, true, nil
+}
+
+// pushDownFromSpec returns the spec of fromNode if an aggregate can be pushed into it.
+// The aggregate must be the only consumer of the from, and storage only aggregates
+// the points of each series within bounds.
+func pushDownFromSpec(fromNode plan.PlanNode) (*inputs.FromProcedureSpec, bool) {
+	fromSpec := fromNode.ProcedureSpec().(*inputs.FromProcedureSpec)
+	if len(fromNode.Successors()) != 1 || !fromSpec.BoundsSet {
+		return nil, false
+	}
+	if fromSpec.AggregateSet || fromSpec.GroupingSet || fromSpec.LimitSet || fromSpec.WindowSet {
+		return nil, false
+	}
+	return fromSpec, true
+}
+
+// isDefaultColumn reports whether the column of a window is its default column.
+func isDefaultColumn(column, label string) bool {
+	return column == "" || column == label
+}
+
+// canPushDownAggregate reports whether storage computes the same values as spec.
+// Storage only aggregates the _value column, and the first and last points of a
+// series are those of a read in ascending order.
+func canPushDownAggregate(spec plan.ProcedureSpec, fromSpec *inputs.FromProcedureSpec) bool {
+	switch spec := spec.(type) {
+	case *transformations.MinProcedureSpec:
+		return isValueColumn(spec.Column)
+	case *transformations.MaxProcedureSpec:
+		return isValueColumn(spec.Column)
+	case *transformations.FirstProcedureSpec:
+		return isValueColumn(spec.Column) && !fromSpec.DescendingSet
+	case *transformations.LastProcedureSpec:
+		return isValueColumn(spec.Column) && !fromSpec.DescendingSet
+	case *transformations.MeanProcedureSpec:
+		return len(spec.Columns) == 1 && spec.Columns[0] == execute.DefaultValueColLabel
+	default:
+		return false
+	}
+}
+
+// isValueColumn reports whether the column of a selector is the _value column,
+// which it is by default.
+func isValueColumn(column string) bool {
+	return column == "" || column == execute.DefaultValueColLabel
+}
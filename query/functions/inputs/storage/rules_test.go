@@ -0,0 +1,281 @@
+package storage_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/functions/inputs"
+	"github.com/influxdata/flux/functions/transformations"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/plan/plantest"
+	"github.com/influxdata/flux/semantic/semantictest"
+	"github.com/influxdata/platform/query/functions/inputs/storage"
+)
+
+func TestPushDownAggregateRule(t *testing.T) {
+	bounds := flux.Bounds{
+		Start: flux.Time{Absolute: time.Unix(0, 0)},
+		Stop:  flux.Time{Absolute: time.Unix(10, 0)},
+	}
+	from := func(fn func(spec *inputs.FromProcedureSpec)) *inputs.FromProcedureSpec {
+		spec := &inputs.FromProcedureSpec{Bucket: "my-bucket", BoundsSet: true, Bounds: bounds}
+		if fn != nil {
+			fn(spec)
+		}
+		return spec
+	}
+	aggregated := func(method string) *inputs.FromProcedureSpec {
+		return from(func(spec *inputs.FromProcedureSpec) {
+			spec.AggregateSet = true
+			spec.AggregateMethod = method
+		})
+	}
+
+	window := func(every time.Duration) *transformations.WindowProcedureSpec {
+		return &transformations.WindowProcedureSpec{
+			Window:      plan.WindowSpec{Every: flux.Duration(every), Period: flux.Duration(every)},
+			TimeColumn:  execute.DefaultTimeColLabel,
+			StartColumn: execute.DefaultStartColLabel,
+			StopColumn:  execute.DefaultStopColLabel,
+		}
+	}
+	slidingWindow := window(time.Minute)
+	slidingWindow.Window.Period = flux.Duration(5 * time.Minute)
+	emptyWindow := window(time.Minute)
+	emptyWindow.CreateEmpty = true
+
+	max := &transformations.MaxProcedureSpec{}
+	first := &transformations.FirstProcedureSpec{}
+	mean := &transformations.MeanProcedureSpec{AggregateConfig: execute.DefaultAggregateConfig}
+
+	tests := []struct {
+		name   string
+		before *plantest.PlanSpec
+		after  *plantest.PlanSpec
+	}{
+		{
+			name: "from max",
+			// from -> max  =>  from(max) -> max
+			before: &plantest.PlanSpec{
+				Nodes: []plan.PlanNode{
+					plan.CreatePhysicalNode("from", from(nil)),
+					plan.CreatePhysicalNode("max", max),
+				},
+				Edges: [][2]int{{0, 1}},
+			},
+			after: &plantest.PlanSpec{
+				Nodes: []plan.PlanNode{
+					plan.CreatePhysicalNode("from", aggregated("max")),
+					plan.CreatePhysicalNode("max", max),
+				},
+				Edges: [][2]int{{0, 1}},
+			},
+		},
+		{
+			name: "from mean",
+			before: &plantest.PlanSpec{
+				Nodes: []plan.PlanNode{
+					plan.CreatePhysicalNode("from", from(nil)),
+					plan.CreatePhysicalNode("mean", mean),
+				},
+				Edges: [][2]int{{0, 1}},
+			},
+			after: &plantest.PlanSpec{
+				Nodes: []plan.PlanNode{
+					plan.CreatePhysicalNode("from", aggregated("mean")),
+					plan.CreatePhysicalNode("mean", mean),
+				},
+				Edges: [][2]int{{0, 1}},
+			},
+		},
+		{
+			name: "from window mean",
+			// from -> window -> mean  =>  from(window, mean) -> window -> mean
+			before: &plantest.PlanSpec{
+				Nodes: []plan.PlanNode{
+					plan.CreatePhysicalNode("from", from(nil)),
+					plan.CreatePhysicalNode("window", window(time.Minute)),
+					plan.CreatePhysicalNode("mean", mean),
+				},
+				Edges: [][2]int{{0, 1}, {1, 2}},
+			},
+			after: &plantest.PlanSpec{
+				Nodes: []plan.PlanNode{
+					plan.CreatePhysicalNode("from", from(func(spec *inputs.FromProcedureSpec) {
+						spec.AggregateSet = true
+						spec.AggregateMethod = "mean"
+						spec.WindowSet = true
+						spec.Window = plan.WindowSpec{Every: flux.Duration(time.Minute), Period: flux.Duration(time.Minute)}
+					})),
+					plan.CreatePhysicalNode("window", window(time.Minute)),
+					plan.CreatePhysicalNode("mean", mean),
+				},
+				Edges: [][2]int{{0, 1}, {1, 2}},
+			},
+		},
+		{
+			name: "sliding window",
+			before: &plantest.PlanSpec{
+				Nodes: []plan.PlanNode{
+					plan.CreatePhysicalNode("from", from(nil)),
+					plan.CreatePhysicalNode("window", slidingWindow),
+					plan.CreatePhysicalNode("mean", mean),
+				},
+				Edges: [][2]int{{0, 1}, {1, 2}},
+			},
+			after: &plantest.PlanSpec{
+				Nodes: []plan.PlanNode{
+					plan.CreatePhysicalNode("from", from(nil)),
+					plan.CreatePhysicalNode("window", slidingWindow),
+					plan.CreatePhysicalNode("mean", mean),
+				},
+				Edges: [][2]int{{0, 1}, {1, 2}},
+			},
+		},
+		{
+			name: "create empty windows",
+			before: &plantest.PlanSpec{
+				Nodes: []plan.PlanNode{
+					plan.CreatePhysicalNode("from", from(nil)),
+					plan.CreatePhysicalNode("window", emptyWindow),
+					plan.CreatePhysicalNode("mean", mean),
+				},
+				Edges: [][2]int{{0, 1}, {1, 2}},
+			},
+			after: &plantest.PlanSpec{
+				Nodes: []plan.PlanNode{
+					plan.CreatePhysicalNode("from", from(nil)),
+					plan.CreatePhysicalNode("window", emptyWindow),
+					plan.CreatePhysicalNode("mean", mean),
+				},
+				Edges: [][2]int{{0, 1}, {1, 2}},
+			},
+		},
+		{
+			name: "selector column",
+			before: &plantest.PlanSpec{
+				Nodes: []plan.PlanNode{
+					plan.CreatePhysicalNode("from", from(nil)),
+					plan.CreatePhysicalNode("max", &transformations.MaxProcedureSpec{
+						SelectorConfig: execute.SelectorConfig{Column: "_time"},
+					}),
+				},
+				Edges: [][2]int{{0, 1}},
+			},
+			after: &plantest.PlanSpec{
+				Nodes: []plan.PlanNode{
+					plan.CreatePhysicalNode("from", from(nil)),
+					plan.CreatePhysicalNode("max", &transformations.MaxProcedureSpec{
+						SelectorConfig: execute.SelectorConfig{Column: "_time"},
+					}),
+				},
+				Edges: [][2]int{{0, 1}},
+			},
+		},
+		{
+			name: "descending first",
+			before: &plantest.PlanSpec{
+				Nodes: []plan.PlanNode{
+					plan.CreatePhysicalNode("from", from(func(spec *inputs.FromProcedureSpec) {
+						spec.DescendingSet = true
+						spec.Descending = true
+					})),
+					plan.CreatePhysicalNode("first", first),
+				},
+				Edges: [][2]int{{0, 1}},
+			},
+			after: &plantest.PlanSpec{
+				Nodes: []plan.PlanNode{
+					plan.CreatePhysicalNode("from", from(func(spec *inputs.FromProcedureSpec) {
+						spec.DescendingSet = true
+						spec.Descending = true
+					})),
+					plan.CreatePhysicalNode("first", first),
+				},
+				Edges: [][2]int{{0, 1}},
+			},
+		},
+		{
+			name: "grouped",
+			before: &plantest.PlanSpec{
+				Nodes: []plan.PlanNode{
+					plan.CreatePhysicalNode("from", from(func(spec *inputs.FromProcedureSpec) {
+						spec.GroupingSet = true
+					})),
+					plan.CreatePhysicalNode("first", first),
+				},
+				Edges: [][2]int{{0, 1}},
+			},
+			after: &plantest.PlanSpec{
+				Nodes: []plan.PlanNode{
+					plan.CreatePhysicalNode("from", from(func(spec *inputs.FromProcedureSpec) {
+						spec.GroupingSet = true
+					})),
+					plan.CreatePhysicalNode("first", first),
+				},
+				Edges: [][2]int{{0, 1}},
+			},
+		},
+		{
+			name: "multiple successors",
+			// from -> max, from -> first  =>  unchanged
+			before: &plantest.PlanSpec{
+				Nodes: []plan.PlanNode{
+					plan.CreatePhysicalNode("from", from(nil)),
+					plan.CreatePhysicalNode("max", max),
+					plan.CreatePhysicalNode("first", first),
+				},
+				Edges: [][2]int{{0, 1}, {0, 2}},
+			},
+			after: &plantest.PlanSpec{
+				Nodes: []plan.PlanNode{
+					plan.CreatePhysicalNode("from", from(nil)),
+					plan.CreatePhysicalNode("max", max),
+					plan.CreatePhysicalNode("first", first),
+				},
+				Edges: [][2]int{{0, 1}, {0, 2}},
+			},
+		},
+	}
+
+	rules := []plan.Rule{
+		storage.PushDownAggregateRule{Kind: transformations.MaxKind},
+		storage.PushDownAggregateRule{Kind: transformations.FirstKind},
+		storage.PushDownAggregateRule{Kind: transformations.MeanKind},
+		storage.PushDownWindowAggregateRule{Kind: transformations.MeanKind},
+	}
+
+	type attrs struct {
+		ID   plan.NodeID
+		Spec plan.ProcedureSpec
+	}
+	walk := func(ps *plan.PlanSpec) []attrs {
+		var nodes []attrs
+		ps.BottomUpWalk(func(node plan.PlanNode) error {
+			nodes = append(nodes, attrs{ID: node.ID(), Spec: node.ProcedureSpec()})
+			return nil
+		})
+		return nodes
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			planner := plan.NewPhysicalPlanner(
+				plan.OnlyPhysicalRules(rules...),
+				plan.DisableValidatation(),
+			)
+			pp, err := planner.Plan(plantest.CreatePlanSpec(tc.before))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want, got := walk(plantest.CreatePlanSpec(tc.after)), walk(pp)
+			if !cmp.Equal(want, got, semantictest.CmpOptions...) {
+				t.Errorf("unexpected plan, -want/+got:\n%v", cmp.Diff(want, got, semantictest.CmpOptions...))
+			}
+		})
+	}
+}
@@ -189,6 +189,12 @@ type ReadSpec struct {
 
 	AggregateMethod string
 
+	// WindowEvery, when non-zero, applies the aggregate to windows of that many
+	// nanoseconds, starting WindowOffset nanoseconds after the Unix epoch,
+	// producing a value per window of each series.
+	WindowEvery  int64
+	WindowOffset int64
+
 	// OrderByTime indicates that series reads should produce all
 	// series for a time before producing any series for a larger time.
 	// By default this is false meaning all values of time are produced for a given series,
@@ -206,7 +212,34 @@ type ReadSpec struct {
 	RetentionPolicy string // required by InfluxDB OSS
 }
 
+// TagKeysSpec specifies the series of a bucket whose tag keys are read.
+type TagKeysSpec struct {
+	OrganizationID platform.ID
+	BucketID       platform.ID
+	Predicate      *semantic.FunctionExpression
+}
+
+// TagValuesSpec specifies the tag and the series of a bucket whose tag values are read.
+type TagValuesSpec struct {
+	TagKeysSpec
+	TagKey string
+}
+
+// SchemaReader reads the tag keys and tag values of the series of a bucket from the index.
+// The measurement and field of each series are the tags _measurement and _field.
+// A zero start or stop leaves the time range unbounded.
+type SchemaReader interface {
+	// ReadTagKeys returns the sorted tag keys of the series matching spec
+	// with data between start and stop.
+	ReadTagKeys(ctx context.Context, spec TagKeysSpec, start, stop execute.Time) ([]string, error)
+
+	// ReadTagValues returns the sorted values of the tag spec.TagKey of the series
+	// matching spec with data between start and stop.
+	ReadTagValues(ctx context.Context, spec TagValuesSpec, start, stop execute.Time) ([]string, error)
+}
+
 type Reader interface {
+	SchemaReader
 	Read(ctx context.Context, rs ReadSpec, start, stop execute.Time) (flux.TableIterator, error)
 	Close()
 }
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"sort"
+	"sync/atomic"
 
 	"github.com/influxdata/flux"
 	"github.com/influxdata/flux/execute"
@@ -41,6 +42,61 @@ type ToOpSpec struct {
 	MeasurementColumn string                       `json:"measurementColumn"`
 	TagColumns        []string                     `json:"tagColumns"`
 	FieldFn           *semantic.FunctionExpression `json:"fieldFn"`
+
+	// RowLimit, when set, limits the rows written by the query. It is shared by the
+	// `to` operations of a query and is not part of the serialized spec.
+	RowLimit *RowLimit `json:"-"`
+}
+
+// ErrRowLimitExceeded is returned by `to` when writing a table would exceed the row
+// limit of its query. The rows of the table are not written.
+var ErrRowLimitExceeded = errors.New("query exceeded its row limit")
+
+// RowLimit counts the rows written by the `to` operations of a query. It is safe for
+// concurrent use.
+type RowLimit struct {
+	// Max is the number of rows that may be written. Zero means no limit.
+	Max int64
+
+	n        int64 // Accessed atomically.
+	exceeded int32 // Accessed atomically.
+}
+
+// SetRowLimit limits the rows written by every `to` operation of spec to l.
+func SetRowLimit(spec *flux.Spec, l *RowLimit) {
+	for _, op := range spec.Operations {
+		if s, ok := op.Spec.(*ToOpSpec); ok {
+			s.RowLimit = l
+		}
+	}
+}
+
+// take counts n more rows to be written, returning ErrRowLimitExceeded without
+// counting them if they would exceed the limit.
+func (l *RowLimit) take(n int) error {
+	if l == nil {
+		return nil
+	}
+	for {
+		cur := atomic.LoadInt64(&l.n)
+		if l.Max > 0 && cur+int64(n) > l.Max {
+			atomic.StoreInt32(&l.exceeded, 1)
+			return ErrRowLimitExceeded
+		}
+		if atomic.CompareAndSwapInt64(&l.n, cur, cur+int64(n)) {
+			return nil
+		}
+	}
+}
+
+// Count returns the number of rows written.
+func (l *RowLimit) Count() int64 {
+	return atomic.LoadInt64(&l.n)
+}
+
+// Exceeded reports whether a write was stopped by the limit.
+func (l *RowLimit) Exceeded() bool {
+	return atomic.LoadInt32(&l.exceeded) == 1
 }
 
 func init() {
@@ -206,6 +262,7 @@ func (o *ToProcedureSpec) Copy() plan.ProcedureSpec {
 			MeasurementColumn: s.MeasurementColumn,
 			TagColumns:        append([]string(nil), s.TagColumns...),
 			FieldFn:           fn,
+			RowLimit:          s.RowLimit,
 		},
 	}
 	return res
@@ -572,6 +629,13 @@ func writeTable(t *ToTransformation, tbl flux.Table) error {
 			}
 		}
 		points, err = tsdb.ExplodePoints(*orgID, *bucketID, points)
+		if err != nil {
+			return err
+		}
+		// Count the rows before they are written, so that no rows beyond the limit are.
+		if err := spec.RowLimit.take(er.Len()); err != nil {
+			return err
+		}
 		return d.PointsWriter.WritePoints(points)
 	})
 }
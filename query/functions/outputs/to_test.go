@@ -17,6 +17,7 @@ import (
 	"github.com/influxdata/platform/query/querytest"
 	"github.com/influxdata/platform/tsdb"
 	"testing"
+	"time"
 )
 
 func TestTo_Query(t *testing.T) {
@@ -573,6 +574,90 @@ c,tag2=e humidity=65i,temperature=4.0 41`),
 	}
 }
 
+func TestTo_RowLimit(t *testing.T) {
+	oid, _ := (mockOrgLookup{}).Lookup(context.Background(), "my-org")
+	bid, _ := (mockBucketLookup{}).Lookup(oid, "my-bucket")
+	limit := &outputs.RowLimit{Max: 4}
+	spec := &outputs.ToProcedureSpec{
+		Spec: &outputs.ToOpSpec{
+			Org:               "my-org",
+			Bucket:            "my-bucket",
+			TimeColumn:        "_time",
+			MeasurementColumn: "_measurement",
+			RowLimit:          limit,
+		},
+	}
+	table := func(m string) flux.Table {
+		return executetest.MustCopyTable(&executetest.Table{
+			KeyCols: []string{"_measurement"},
+			ColMeta: []flux.ColMeta{
+				{Label: "_time", Type: flux.TTime},
+				{Label: "_measurement", Type: flux.TString},
+				{Label: "_field", Type: flux.TString},
+				{Label: "_value", Type: flux.TFloat},
+			},
+			Data: [][]interface{}{
+				{execute.Time(11), m, "_value", 1.0},
+				{execute.Time(21), m, "_value", 2.0},
+				{execute.Time(31), m, "_value", 3.0},
+			},
+		})
+	}
+
+	deps := mockDependencies()
+	d := executetest.NewDataset(executetest.RandomDatasetID())
+	c := execute.NewTableBuilderCache(executetest.UnlimitedAllocator)
+	c.SetTriggerSpec(execute.DefaultTriggerSpec)
+	tx, err := outputs.NewToTransformation(d, c, spec.Copy().(*outputs.ToProcedureSpec), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parentID := executetest.RandomDatasetID()
+	if err := tx.Process(parentID, table("a")); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Process(parentID, table("b")); err != outputs.ErrRowLimitExceeded {
+		t.Fatalf("unexpected error: got %v, want %v", err, outputs.ErrRowLimitExceeded)
+	}
+
+	// The second table would exceed the limit, so none of its rows are written.
+	pw := deps.PointsWriter.(*mock.PointsWriter)
+	want := mockPoints(oid, bid, `a _value=1.0 11
+a _value=2.0 21
+a _value=3.0 31`)
+	if got, want := pointsToStr(pw.Points), pointsToStr(want); !cmp.Equal(got, want) {
+		t.Errorf("unexpected points written -want/+got\n%s", cmp.Diff(want, got))
+	}
+	if got := limit.Count(); got != 3 {
+		t.Errorf("unexpected row count: got %d, want 3", got)
+	}
+	if !limit.Exceeded() {
+		t.Error("expected the row limit to be exceeded")
+	}
+}
+
+func TestSetRowLimit(t *testing.T) {
+	spec, err := flux.Compile(context.Background(), `from(bucket:"mydb") |> to(bucket:"series1", org:"fred")`, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	limit := &outputs.RowLimit{Max: 10}
+	outputs.SetRowLimit(spec, limit)
+
+	var n int
+	for _, op := range spec.Operations {
+		if s, ok := op.Spec.(*outputs.ToOpSpec); ok {
+			n++
+			if s.RowLimit != limit {
+				t.Errorf("operation %q does not have the row limit", op.ID)
+			}
+		}
+	}
+	if n != 1 {
+		t.Fatalf("expected one to operation, got %d", n)
+	}
+}
+
 func mockDependencies() outputs.ToDependencies {
 	return outputs.ToDependencies{
 		BucketLookup:       mockBucketLookup{},
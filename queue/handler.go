@@ -0,0 +1,100 @@
+package queue
+
+import (
+	"time"
+
+	"github.com/influxdata/platform/nats"
+	"github.com/influxdata/platform/storage"
+	"github.com/influxdata/platform/tsdb"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+const (
+	// AckWait is how long NATS waits for a message of the queue to be acknowledged
+	// before it redelivers it, which spaces out the attempts to write a message while
+	// the engine fails.
+	AckWait = 30 * time.Second
+
+	// MaxInflight is the number of messages delivered to a Handler that may be waiting
+	// to be written. Messages are written one at a time, so each must be written
+	// within AckWait of the write of the first of them starting.
+	MaxInflight = 25
+)
+
+// Handler is a nats.Handler that writes the points of the messages of the queue to
+// PointsWriter. Messages are acknowledged once written, or once the engine rejects their
+// points as a partial write, which cannot succeed when retried. A message that fails
+// with another error, such as a full cache or a closed engine, is left unacknowledged,
+// and NATS redelivers it after AckWait. It is not retried by the handler, since the
+// messages delivered behind it would then be redelivered, and written twice, once
+// the retries exceeded AckWait.
+type Handler struct {
+	PointsWriter storage.PointsWriter
+	Logger       *zap.Logger
+
+	metrics *handlerMetrics
+}
+
+// NewHandler returns a handler that writes to w.
+func NewHandler(w storage.PointsWriter) *Handler {
+	return &Handler{
+		PointsWriter: w,
+		Logger:       zap.NewNop(),
+		metrics:      newHandlerMetrics(),
+	}
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (h *Handler) PrometheusCollectors() []prometheus.Collector {
+	return h.metrics.PrometheusCollectors()
+}
+
+// Process writes the points of a message and acknowledges it.
+func (h *Handler) Process(s nats.Subscription, m nats.Message) {
+	defer h.updateBuffered(s)
+
+	points, err := decodePoints(m.Data())
+	if err != nil {
+		// The message can never be written, so it is dropped rather than redelivered.
+		h.Logger.Error("Failed to decode queued points", zap.Error(err))
+		h.metrics.DroppedPoints.Inc()
+		h.ack(m)
+		return
+	}
+
+	err = h.PointsWriter.WritePoints(points)
+	if err == nil {
+		h.metrics.WrittenPoints.Add(float64(len(points)))
+		h.ack(m)
+		return
+	}
+
+	if pwe, ok := err.(tsdb.PartialWriteError); ok {
+		h.Logger.Info("Queued points dropped", zap.Int("dropped", pwe.Dropped), zap.Error(err))
+		h.metrics.WrittenPoints.Add(float64(len(points) - pwe.Dropped))
+		h.metrics.DroppedPoints.Add(float64(pwe.Dropped))
+		h.ack(m)
+		return
+	}
+
+	h.Logger.Info("Failed to write queued points, leaving them to be redelivered", zap.Error(err))
+	h.metrics.Unacked.Inc()
+}
+
+func (h *Handler) ack(m nats.Message) {
+	if err := m.Ack(); err != nil {
+		h.Logger.Info("Failed to acknowledge queued points", zap.Error(err))
+	}
+}
+
+// updateBuffered sets the messages delivered to s that are waiting to be written. They
+// are at most MaxInflight, so they are not the depth of the queue on the server.
+func (h *Handler) updateBuffered(s nats.Subscription) {
+	msgs, bytes, err := s.Pending()
+	if err != nil {
+		return
+	}
+	h.metrics.BufferedMessages.Set(float64(msgs))
+	h.metrics.BufferedBytes.Set(float64(bytes))
+}
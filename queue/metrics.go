@@ -0,0 +1,93 @@
+package queue
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// namespace is the leading part of all published metrics for the write queue.
+const namespace = "write_queue"
+
+// writerMetrics are the counts of the writes published to the queue.
+type writerMetrics struct {
+	PublishedMessages prometheus.Counter
+	PublishedPoints   prometheus.Counter
+	PublishedBytes    prometheus.Counter
+}
+
+func newWriterMetrics() *writerMetrics {
+	return &writerMetrics{
+		PublishedMessages: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "published_messages_total",
+			Help:      "Number of messages of points published to the queue.",
+		}),
+		PublishedPoints: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "published_points_total",
+			Help:      "Number of points published to the queue.",
+		}),
+		PublishedBytes: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "published_bytes_total",
+			Help:      "Number of bytes of points published to the queue.",
+		}),
+	}
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (m *writerMetrics) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		m.PublishedMessages,
+		m.PublishedPoints,
+		m.PublishedBytes,
+	}
+}
+
+// handlerMetrics are the messages buffered by a handler and the counts of the points
+// written from the queue.
+type handlerMetrics struct {
+	BufferedMessages prometheus.Gauge
+	BufferedBytes    prometheus.Gauge
+	WrittenPoints    prometheus.Counter
+	DroppedPoints    prometheus.Counter
+	Unacked          prometheus.Counter
+}
+
+func newHandlerMetrics() *handlerMetrics {
+	return &handlerMetrics{
+		BufferedMessages: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "buffered_messages",
+			Help:      "Number of messages delivered to the subscriber that are waiting to be written, at most the messages that may be in flight.",
+		}),
+		BufferedBytes: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "buffered_bytes",
+			Help:      "Number of bytes of messages delivered to the subscriber that are waiting to be written.",
+		}),
+		WrittenPoints: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "written_points_total",
+			Help:      "Number of points of the queue written to the engine.",
+		}),
+		DroppedPoints: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "dropped_points_total",
+			Help:      "Number of points of the queue that the engine rejected, or that could not be decoded.",
+		}),
+		Unacked: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "unacked_messages_total",
+			Help:      "Number of messages left unacknowledged, to be redelivered, after their write failed.",
+		}),
+	}
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (m *handlerMetrics) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		m.BufferedMessages,
+		m.BufferedBytes,
+		m.WrittenPoints,
+		m.DroppedPoints,
+		m.Unacked,
+	}
+}
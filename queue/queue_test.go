@@ -0,0 +1,210 @@
+package queue_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/platform/kit/prom"
+	"github.com/influxdata/platform/kit/prom/promtest"
+	"github.com/influxdata/platform/mock"
+	"github.com/influxdata/platform/models"
+	"github.com/influxdata/platform/nats"
+	"github.com/influxdata/platform/queue"
+	"github.com/influxdata/platform/tsdb"
+)
+
+// pointsWriter returns errs from its first writes, and records the points of the
+// writes that succeed.
+type pointsWriter struct {
+	mu     sync.Mutex
+	errs   []error
+	points []models.Point
+	writes int
+}
+
+func (w *pointsWriter) WritePoints(points []models.Point) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.writes++
+	if len(w.errs) > 0 {
+		err := w.errs[0]
+		w.errs = w.errs[1:]
+		return err
+	}
+	w.points = append(w.points, points...)
+	return nil
+}
+
+func (w *pointsWriter) Points() []models.Point {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.points
+}
+
+type message struct {
+	data  []byte
+	acked bool
+}
+
+func (m *message) Data() []byte { return m.data }
+func (m *message) Ack() error   { m.acked = true; return nil }
+
+type subscription struct{}
+
+var _ nats.Subscription = subscription{}
+
+func (subscription) Pending() (int64, int64, error) { return 3, 300, nil }
+func (subscription) Delivered() (int64, error)      { return 0, nil }
+func (subscription) Close() error                   { return nil }
+
+// publisher records the data of the messages published.
+type publisher struct {
+	messages [][]byte
+}
+
+func (p *publisher) Publish(subject string, r io.Reader) error {
+	b, err := ioutil.ReadAll(r)
+	p.messages = append(p.messages, b)
+	return err
+}
+
+func newPoints(n int) []models.Point {
+	points := make([]models.Point, n)
+	for i := range points {
+		points[i] = models.MustNewPoint("cpu", models.NewTags(map[string]string{"host": fmt.Sprintf("server%d", i)}), models.Fields{"usage": float64(i)}, time.Unix(int64(i), 0))
+	}
+	return points
+}
+
+func TestWriter_Handler(t *testing.T) {
+	pub, sub := mock.NewNats()
+	w := &pointsWriter{}
+	h := queue.NewHandler(w)
+	if err := sub.Subscribe(queue.Subject, queue.Group, h); err != nil {
+		t.Fatal(err)
+	}
+
+	want := newPoints(10)
+	if err := queue.NewWriter(pub).WritePoints(want); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(w.Points()) < len(want) && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	got := w.Points()
+	if len(got) != len(want) {
+		t.Fatalf("got %d points, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].String() != want[i].String() {
+			t.Errorf("got point %s, want %s", got[i], want[i])
+		}
+	}
+}
+
+func TestWriter_Split(t *testing.T) {
+	p := &publisher{}
+	w := queue.NewWriter(p)
+
+	// Each point is about 100 bytes, so they do not fit in a single message.
+	if err := w.WritePoints(newPoints(10000)); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.messages) < 2 {
+		t.Fatalf("got %d messages, want the points split into more", len(p.messages))
+	}
+
+	pw := &pointsWriter{}
+	h := queue.NewHandler(pw)
+	for _, data := range p.messages {
+		m := &message{data: data}
+		h.Process(subscription{}, m)
+		if !m.acked {
+			t.Fatal("message not acknowledged")
+		}
+	}
+	if got := len(pw.Points()); got != 10000 {
+		t.Fatalf("got %d points, want 10000", got)
+	}
+}
+
+func TestHandler_Process(t *testing.T) {
+	tests := []struct {
+		name    string
+		errs    []error
+		acked   bool
+		writes  int
+		written float64
+		dropped float64
+		unacked float64
+	}{
+		{
+			name:    "written",
+			acked:   true,
+			writes:  1,
+			written: 2,
+		},
+		{
+			name:    "partial write",
+			errs:    []error{tsdb.PartialWriteError{Reason: "field type conflict", Dropped: 1}},
+			acked:   true,
+			writes:  1,
+			written: 1,
+			dropped: 1,
+		},
+		{
+			name:    "left to be redelivered",
+			errs:    []error{errors.New("cache full")},
+			acked:   false,
+			writes:  1,
+			unacked: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &publisher{}
+			if err := queue.NewWriter(p).WritePoints(newPoints(2)); err != nil {
+				t.Fatal(err)
+			}
+
+			w := &pointsWriter{errs: tt.errs}
+			h := queue.NewHandler(w)
+			reg := prom.NewRegistry()
+			reg.MustRegister(h.PrometheusCollectors()...)
+
+			m := &message{data: p.messages[0]}
+			h.Process(subscription{}, m)
+
+			if m.acked != tt.acked {
+				t.Errorf("got acked %v, want %v", m.acked, tt.acked)
+			}
+			if w.writes != tt.writes {
+				t.Errorf("got %d writes, want %d", w.writes, tt.writes)
+			}
+
+			mfs := promtest.MustGather(t, reg)
+			for name, want := range map[string]float64{
+				"write_queue_written_points_total":   tt.written,
+				"write_queue_dropped_points_total":   tt.dropped,
+				"write_queue_unacked_messages_total": tt.unacked,
+			} {
+				if got := promtest.MustFindMetric(t, mfs, name, nil).GetCounter().GetValue(); got != want {
+					t.Errorf("got %s %v, want %v", name, got, want)
+				}
+			}
+			if got := promtest.MustFindMetric(t, mfs, "write_queue_buffered_messages", nil).GetGauge().GetValue(); got != 3 {
+				t.Errorf("got buffered messages %v, want 3", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+// Package queue is a durable write queue between ingest and the storage engine. A Writer
+// publishes the points of writes to a NATS streaming subject, and a Handler of a queue
+// subscription to the subject writes them to the engine, leaving a message to be
+// redelivered when its write fails.
+//
+// The channel of the subject keeps at most a number of bytes of messages, and no limit
+// of messages or age, as configured by Limits. Once it is full, NATS discards its oldest
+// messages, whether or not they have been written, so the limit should exceed the
+// writes received while the engine is stalled.
+package queue
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/influxdata/platform/models"
+	"github.com/influxdata/platform/nats"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// Subject is the NATS subject of the points of queued writes.
+	Subject = "writes"
+
+	// Group is the queue group of the subscribers that write queued points to the engine.
+	Group = "writes"
+
+	// DefaultMaxSize is the default number of bytes of messages that the channel of the
+	// subject keeps.
+	DefaultMaxSize = 8 << 30
+
+	// maxMessageSize is the size of the points of a message above which a write is split
+	// into more messages, below the 1MB maximum payload of NATS.
+	maxMessageSize = 512 * 1024
+)
+
+// Limits returns the limits of the channel of the subject, which keeps at most maxSize
+// bytes of messages.
+func Limits(maxSize int64) nats.ChannelLimits {
+	return nats.ChannelLimits{MaxBytes: maxSize}
+}
+
+// Writer is a storage.PointsWriter that publishes points to the queue, rather than
+// writing them to the engine. A write succeeds once its points are persisted by the
+// queue, so errors of the engine, such as field type conflicts, are not returned.
+type Writer struct {
+	Publisher nats.Publisher
+	metrics   *writerMetrics
+}
+
+// NewWriter returns a Writer that publishes with p.
+func NewWriter(p nats.Publisher) *Writer {
+	return &Writer{Publisher: p, metrics: newWriterMetrics()}
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (w *Writer) PrometheusCollectors() []prometheus.Collector {
+	return w.metrics.PrometheusCollectors()
+}
+
+// WritePoints publishes points to the queue, in as many messages as their size requires.
+func (w *Writer) WritePoints(points []models.Point) error {
+	var buf bytes.Buffer
+	var n int
+	for _, pt := range points {
+		b, err := pt.MarshalBinary()
+		if err != nil {
+			return err
+		}
+
+		if buf.Len() > 0 && buf.Len()+len(b) > maxMessageSize {
+			if err := w.publish(&buf, n); err != nil {
+				return err
+			}
+			n = 0
+		}
+		appendPoint(&buf, b)
+		n++
+	}
+
+	if buf.Len() == 0 {
+		return nil
+	}
+	return w.publish(&buf, n)
+}
+
+func (w *Writer) publish(buf *bytes.Buffer, n int) error {
+	size := buf.Len()
+	if err := w.Publisher.Publish(Subject, bytes.NewReader(buf.Bytes())); err != nil {
+		return fmt.Errorf("failed to queue points: %v", err)
+	}
+	// The publisher may read the message after Publish returns, so it is not reused.
+	*buf = bytes.Buffer{}
+
+	w.metrics.PublishedMessages.Inc()
+	w.metrics.PublishedPoints.Add(float64(n))
+	w.metrics.PublishedBytes.Add(float64(size))
+	return nil
+}
+
+// appendPoint appends the binary of a point, prefixed by its length, to buf.
+func appendPoint(buf *bytes.Buffer, b []byte) {
+	var l [binary.MaxVarintLen64]byte
+	buf.Write(l[:binary.PutUvarint(l[:], uint64(len(b)))])
+	buf.Write(b)
+}
+
+// decodePoints returns the points of the data of a message.
+func decodePoints(data []byte) ([]models.Point, error) {
+	var points []models.Point
+	for len(data) > 0 {
+		l, n := binary.Uvarint(data)
+		if n <= 0 || uint64(len(data)-n) < l {
+			return nil, fmt.Errorf("truncated point")
+		}
+		data = data[n:]
+
+		pt, err := models.NewPointFromBytes(data[:l])
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, pt)
+		data = data[l:]
+	}
+	return points, nil
+}
@@ -0,0 +1,148 @@
+package platform
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// ReplicationService describes a service for managing replications of buckets.
+type ReplicationService interface {
+	// FindReplicationByID finds a single replication by its ID.
+	FindReplicationByID(ctx context.Context, id ID) (*Replication, error)
+
+	// FindReplications returns all replications.
+	FindReplications(ctx context.Context) ([]*Replication, error)
+
+	// CreateReplication creates a new replication and assigns it an ID.
+	CreateReplication(ctx context.Context, r *Replication) error
+
+	// UpdateReplication updates a single replication with a changeset.
+	UpdateReplication(ctx context.Context, id ID, upd ReplicationUpdate) (*Replication, error)
+
+	// DeleteReplication removes a replication by its ID.
+	DeleteReplication(ctx context.Context, id ID) error
+}
+
+// ReplicationDropPolicy is what a replication drops when its queue is full.
+type ReplicationDropPolicy string
+
+// Drop policies of replications.
+const (
+	// ReplicationDropOldest replications drop the oldest queued writes to make room
+	// for new writes.
+	ReplicationDropOldest ReplicationDropPolicy = "oldest"
+	// ReplicationDropNewest replications drop new writes while their queue is full.
+	ReplicationDropNewest ReplicationDropPolicy = "newest"
+)
+
+// DefaultReplicationMaxQueueSize is the maximum size of the queue of a replication that
+// does not set one.
+const DefaultReplicationMaxQueueSize = 64 * 1024 * 1024
+
+// Replication mirrors every write accepted into a local bucket to a bucket of a remote
+// instance. Writes are queued on disk until the remote instance accepts them, so that
+// they survive restarts and outages of either instance.
+type Replication struct {
+	ID             ID     `json:"id,omitempty"`
+	Name           string `json:"name"`
+	OrganizationID ID     `json:"organizationID,omitempty"`
+	LocalBucketID  ID     `json:"localBucketID,omitempty"`
+	// RemoteURL is the address of the remote instance, such as "https://cloud:9999".
+	RemoteURL string `json:"remoteURL"`
+	// RemoteToken is the token of an authorization to write to the remote bucket. It
+	// is never returned over HTTP.
+	RemoteToken          string `json:"remoteToken,omitempty"`
+	RemoteOrganizationID ID     `json:"remoteOrganizationID,omitempty"`
+	RemoteBucketID       ID     `json:"remoteBucketID,omitempty"`
+	// MaxQueueSizeBytes is the maximum size of the queue of writes waiting to be sent,
+	// DefaultReplicationMaxQueueSize if zero.
+	MaxQueueSizeBytes int64 `json:"maxQueueSizeBytes,omitempty"`
+	// DropPolicy is what is dropped once the queue is full, the oldest writes if empty.
+	DropPolicy ReplicationDropPolicy `json:"dropPolicy,omitempty"`
+}
+
+// Valid returns an error if a replication is missing a required field, has an invalid
+// remote URL or queue size, or has an unknown drop policy.
+func (r *Replication) Valid() error {
+	switch {
+	case r.Name == "":
+		return fmt.Errorf("name empty")
+	case !r.OrganizationID.Valid():
+		return fmt.Errorf("organization id invalid")
+	case !r.LocalBucketID.Valid():
+		return fmt.Errorf("local bucket id invalid")
+	case r.RemoteURL == "":
+		return fmt.Errorf("remote url empty")
+	case r.RemoteToken == "":
+		return fmt.Errorf("remote token empty")
+	case !r.RemoteOrganizationID.Valid():
+		return fmt.Errorf("remote organization id invalid")
+	case !r.RemoteBucketID.Valid():
+		return fmt.Errorf("remote bucket id invalid")
+	case r.MaxQueueSizeBytes < 0:
+		return fmt.Errorf("max queue size negative")
+	}
+
+	u, err := url.Parse(r.RemoteURL)
+	if err != nil {
+		return fmt.Errorf("invalid remote url: %v", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("invalid remote url %q", r.RemoteURL)
+	}
+
+	switch r.DropPolicy {
+	case "", ReplicationDropOldest, ReplicationDropNewest:
+	default:
+		return fmt.Errorf("unknown drop policy %q", r.DropPolicy)
+	}
+	return nil
+}
+
+// MaxQueueSize returns the maximum size of the queue of the replication.
+func (r *Replication) MaxQueueSize() int64 {
+	if r.MaxQueueSizeBytes == 0 {
+		return DefaultReplicationMaxQueueSize
+	}
+	return r.MaxQueueSizeBytes
+}
+
+// ReplicationUpdate is a changeset of a replication. Only fields that are set are
+// updated. The local bucket of a replication cannot be changed.
+type ReplicationUpdate struct {
+	Name                 *string                `json:"name,omitempty"`
+	RemoteURL            *string                `json:"remoteURL,omitempty"`
+	RemoteToken          *string                `json:"remoteToken,omitempty"`
+	RemoteOrganizationID *ID                    `json:"remoteOrganizationID,omitempty"`
+	RemoteBucketID       *ID                    `json:"remoteBucketID,omitempty"`
+	MaxQueueSizeBytes    *int64                 `json:"maxQueueSizeBytes,omitempty"`
+	DropPolicy           *ReplicationDropPolicy `json:"dropPolicy,omitempty"`
+}
+
+// Apply applies the fields that are set to a replication, and returns an error if the
+// updated replication is not valid.
+func (u *ReplicationUpdate) Apply(r *Replication) error {
+	if u.Name != nil {
+		r.Name = *u.Name
+	}
+	if u.RemoteURL != nil {
+		r.RemoteURL = *u.RemoteURL
+	}
+	if u.RemoteToken != nil {
+		r.RemoteToken = *u.RemoteToken
+	}
+	if u.RemoteOrganizationID != nil {
+		r.RemoteOrganizationID = *u.RemoteOrganizationID
+	}
+	if u.RemoteBucketID != nil {
+		r.RemoteBucketID = *u.RemoteBucketID
+	}
+	if u.MaxQueueSizeBytes != nil {
+		r.MaxQueueSizeBytes = *u.MaxQueueSizeBytes
+	}
+	if u.DropPolicy != nil {
+		r.DropPolicy = *u.DropPolicy
+	}
+	return r.Valid()
+}
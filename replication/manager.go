@@ -0,0 +1,327 @@
+// Package replication mirrors the writes accepted into local buckets to the buckets of
+// remote instances, as configured by the resources of a platform.ReplicationService.
+// The writes of each replication are queued on disk until the remote instance accepts
+// them, so that they survive restarts and outages of either instance.
+package replication
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/influxdata/platform"
+	"github.com/influxdata/platform/models"
+	"github.com/influxdata/platform/tsdb"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultSyncInterval is how often the running replications are synced with their
+	// configuration.
+	DefaultSyncInterval = 10 * time.Second
+
+	// maxRecordSize is the size of line protocol at which the points of a write are
+	// split into several writes to the remote instance.
+	maxRecordSize = 1024 * 1024
+)
+
+// running is the queue of a replication and the replicator that sends it.
+type running struct {
+	q  *queue
+	rp *replicator
+}
+
+// Manager runs the replications of a ReplicationService. The queue of each replication
+// is a directory of Dir named by the ID of the replication.
+type Manager struct {
+	Replications platform.ReplicationService
+	Dir          string
+	// NewWriteService returns the client that writes to the remote instance of a
+	// replication.
+	NewWriteService func(r *platform.Replication) platform.WriteService
+
+	Logger       *zap.Logger
+	SyncInterval time.Duration
+	MaxBackoff   time.Duration
+
+	mu      sync.RWMutex
+	running map[platform.ID]*running
+	// buckets are the queues of the replications of each local bucket.
+	buckets map[platform.ID][]*queue
+	// errs are the reasons that replications could not be started, so that they are
+	// logged only when they change.
+	errs    map[platform.ID]string
+	metrics *metrics
+}
+
+// NewManager returns a manager of the replications of rs, that queues writes in dir
+// and sends them with the clients returned by fn.
+func NewManager(rs platform.ReplicationService, dir string, fn func(r *platform.Replication) platform.WriteService) *Manager {
+	return &Manager{
+		Replications:    rs,
+		Dir:             dir,
+		NewWriteService: fn,
+		Logger:          zap.NewNop(),
+		SyncInterval:    DefaultSyncInterval,
+		MaxBackoff:      DefaultMaxBackoff,
+		running:         make(map[platform.ID]*running),
+		buckets:         make(map[platform.ID][]*queue),
+		errs:            make(map[platform.ID]string),
+		metrics:         newMetrics(),
+	}
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (m *Manager) PrometheusCollectors() []prometheus.Collector {
+	return m.metrics.PrometheusCollectors()
+}
+
+// Run syncs the replications every SyncInterval until ctx is done, and then closes
+// them.
+func (m *Manager) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.SyncInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := m.Sync(ctx); err != nil {
+			m.Logger.Info("Failed to sync replications", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			m.Close()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sync starts the replications that are not running and restarts those whose
+// configuration has changed, keeping their queues. It stops the replications that
+// have been deleted and removes their queues.
+func (m *Manager) Sync(ctx context.Context) error {
+	rs, err := m.Replications.FindReplications(ctx)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	found := make(map[platform.ID]struct{}, len(rs))
+	for _, r := range rs {
+		found[r.ID] = struct{}{}
+
+		if run, ok := m.running[r.ID]; ok {
+			if !reflect.DeepEqual(run.rp.r, *r) {
+				run.rp.close()
+				run.q.setLimits(r.MaxQueueSize(), r.DropPolicy)
+				run.rp = m.newReplicator(r, run.q)
+			}
+			continue
+		}
+
+		if err := m.start(r); err != nil {
+			if msg := err.Error(); m.errs[r.ID] != msg {
+				m.errs[r.ID] = msg
+				m.Logger.Info("Replication not running", zap.Stringer("replication_id", r.ID), zap.String("name", r.Name), zap.Error(err))
+			}
+			continue
+		}
+		delete(m.errs, r.ID)
+	}
+
+	for id := range m.running {
+		if _, ok := found[id]; !ok {
+			m.stop(id)
+			m.removeQueue(id)
+		}
+	}
+	for id := range m.errs {
+		if _, ok := found[id]; !ok {
+			delete(m.errs, id)
+		}
+	}
+
+	// Remove the queues of the replications deleted while the manager was not running.
+	fis, err := ioutil.ReadDir(m.Dir)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, fi := range fis {
+		id, err := platform.IDFromString(fi.Name())
+		if err != nil || !fi.IsDir() {
+			continue
+		}
+		if _, ok := found[*id]; !ok {
+			m.removeQueue(*id)
+		}
+	}
+
+	m.index(rs)
+	return nil
+}
+
+// index sets the queues of the local bucket of each running replication of rs. It must
+// be called with mu held.
+func (m *Manager) index(rs []*platform.Replication) {
+	m.buckets = make(map[platform.ID][]*queue)
+	for _, r := range rs {
+		if run, ok := m.running[r.ID]; ok {
+			m.buckets[r.LocalBucketID] = append(m.buckets[r.LocalBucketID], run.q)
+		}
+	}
+}
+
+// start opens the queue of r and starts sending it. It must be called with mu held.
+func (m *Manager) start(r *platform.Replication) error {
+	q, err := openQueue(m.queueDir(r.ID), r.MaxQueueSize(), r.DropPolicy, m.metrics.queueMetrics(r.ID.String()))
+	if err != nil {
+		return err
+	}
+
+	m.running[r.ID] = &running{q: q, rp: m.newReplicator(r, q)}
+	m.Logger.Info("Replication started", zap.Stringer("replication_id", r.ID), zap.Stringer("local_bucket_id", r.LocalBucketID), zap.String("remote_url", r.RemoteURL))
+	return nil
+}
+
+func (m *Manager) newReplicator(r *platform.Replication, q *queue) *replicator {
+	logger := m.Logger.With(zap.Stringer("replication_id", r.ID))
+	return newReplicator(*r, q, m.NewWriteService(r), m.metrics, m.MaxBackoff, logger)
+}
+
+// stop stops the replication id if it is running, and closes its queue. It must be
+// called with mu held.
+func (m *Manager) stop(id platform.ID) {
+	run, ok := m.running[id]
+	if !ok {
+		return
+	}
+	delete(m.running, id)
+
+	run.rp.close()
+	if err := run.q.close(); err != nil {
+		m.Logger.Info("Failed to close replication queue", zap.Stringer("replication_id", id), zap.Error(err))
+	}
+}
+
+// removeQueue removes the queue and the metrics of the deleted replication id.
+func (m *Manager) removeQueue(id platform.ID) {
+	m.metrics.delete(id.String())
+	if err := os.RemoveAll(m.queueDir(id)); err != nil {
+		m.Logger.Info("Failed to remove replication queue", zap.Stringer("replication_id", id), zap.Error(err))
+	}
+}
+
+func (m *Manager) queueDir(id platform.ID) string {
+	return filepath.Join(m.Dir, id.String())
+}
+
+// Close stops every replication, leaving the writes that have not been sent in their
+// queues.
+func (m *Manager) Close() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for id := range m.running {
+		m.stop(id)
+	}
+	m.buckets = make(map[platform.ID][]*queue)
+}
+
+// enqueue adds the line protocol of the exploded points that are written into the
+// local buckets of replications to their queues.
+func (m *Manager) enqueue(points []models.Point) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if len(m.buckets) == 0 {
+		return
+	}
+
+	lines := make(map[platform.ID][]byte)
+	for _, pt := range points {
+		var name [16]byte
+		if copy(name[:], pt.Name()) != len(name) {
+			continue
+		}
+		_, bucket := tsdb.DecodeName(name)
+
+		qs, ok := m.buckets[bucket]
+		if !ok {
+			continue
+		}
+
+		buf, err := appendLine(lines[bucket], pt)
+		if err != nil {
+			m.Logger.Info("Failed to encode replicated point", zap.Error(err))
+			continue
+		}
+		if len(buf) >= maxRecordSize {
+			m.append(qs, buf)
+			buf = buf[:0]
+		}
+		lines[bucket] = buf
+	}
+
+	for bucket, buf := range lines {
+		if len(buf) > 0 {
+			m.append(m.buckets[bucket], buf)
+		}
+	}
+}
+
+// append adds data to each of qs.
+func (m *Manager) append(qs []*queue, data []byte) {
+	for _, q := range qs {
+		if err := q.append(data); err != nil {
+			m.Logger.Info("Failed to queue replicated write", zap.Error(err))
+		}
+	}
+}
+
+// Service is a ReplicationService that syncs the replications of a Manager when they
+// are created, updated or deleted, rather than at its next sync.
+type Service struct {
+	platform.ReplicationService
+	Manager *Manager
+}
+
+// CreateReplication creates a replication and starts it.
+func (s *Service) CreateReplication(ctx context.Context, r *platform.Replication) error {
+	if err := s.ReplicationService.CreateReplication(ctx, r); err != nil {
+		return err
+	}
+	s.sync(ctx)
+	return nil
+}
+
+// UpdateReplication updates a replication and restarts it.
+func (s *Service) UpdateReplication(ctx context.Context, id platform.ID, upd platform.ReplicationUpdate) (*platform.Replication, error) {
+	r, err := s.ReplicationService.UpdateReplication(ctx, id, upd)
+	if err != nil {
+		return nil, err
+	}
+	s.sync(ctx)
+	return r, nil
+}
+
+// DeleteReplication deletes a replication, stops it and removes its queue.
+func (s *Service) DeleteReplication(ctx context.Context, id platform.ID) error {
+	if err := s.ReplicationService.DeleteReplication(ctx, id); err != nil {
+		return err
+	}
+	s.sync(ctx)
+	return nil
+}
+
+func (s *Service) sync(ctx context.Context) {
+	if err := s.Manager.Sync(ctx); err != nil {
+		s.Manager.Logger.Info("Failed to sync replications", zap.Error(err))
+	}
+}
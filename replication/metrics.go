@@ -0,0 +1,88 @@
+package replication
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// namespace is the leading part of all published metrics for the replications.
+const namespace = "replication"
+
+// metrics are the metrics of each replication, labelled by the ID of the replication.
+type metrics struct {
+	QueueBytes *prometheus.GaugeVec
+	Lag        *prometheus.GaugeVec
+	Sent       *prometheus.CounterVec
+	Dropped    *prometheus.CounterVec
+	Rejected   *prometheus.CounterVec
+	Failures   *prometheus.CounterVec
+}
+
+func newMetrics() *metrics {
+	names := []string{"replication_id"}
+
+	return &metrics{
+		QueueBytes: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "queue_bytes",
+			Help:      "Number of bytes of the queue of writes waiting to be sent.",
+		}, names),
+
+		Lag: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "lag_seconds",
+			Help:      "Age of the oldest write waiting to be sent.",
+		}, names),
+
+		Sent: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "sent_bytes_total",
+			Help:      "Number of bytes of line protocol written to the remote bucket.",
+		}, names),
+
+		Dropped: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "dropped_bytes_total",
+			Help:      "Number of bytes of line protocol dropped because the queue was full or corrupt.",
+		}, names),
+
+		Rejected: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "rejected_bytes_total",
+			Help:      "Number of bytes of line protocol rejected by the remote instance.",
+		}, names),
+
+		Failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "failed_writes_total",
+			Help:      "Number of writes to the remote instance that failed and were retried.",
+		}, names),
+	}
+}
+
+// queueMetrics returns the metrics of the queue of the replication id.
+func (m *metrics) queueMetrics(id string) queueMetrics {
+	return queueMetrics{
+		Bytes:   m.QueueBytes.WithLabelValues(id),
+		Dropped: m.Dropped.WithLabelValues(id),
+	}
+}
+
+// delete removes the metrics of the replication id.
+func (m *metrics) delete(id string) {
+	m.QueueBytes.DeleteLabelValues(id)
+	m.Lag.DeleteLabelValues(id)
+	m.Sent.DeleteLabelValues(id)
+	m.Dropped.DeleteLabelValues(id)
+	m.Rejected.DeleteLabelValues(id)
+	m.Failures.DeleteLabelValues(id)
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (m *metrics) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		m.QueueBytes,
+		m.Lag,
+		m.Sent,
+		m.Dropped,
+		m.Rejected,
+		m.Failures,
+	}
+}
@@ -0,0 +1,560 @@
+package replication
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/platform"
+	"github.com/influxdata/platform/pkg/file"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// segmentExt is the extension of the segment files of a queue.
+	segmentExt = ".seg"
+
+	// headFile is the name of the file that holds the position of the oldest record of
+	// a queue that has not been sent.
+	headFile = "head"
+
+	// recordHeaderSize is the size of the header of a record: the length and checksum
+	// of its data, and the time that it was queued.
+	recordHeaderSize = 16
+
+	// minSegmentSize and maxSegmentSize bound the size of the segments of a queue, which
+	// is an eighth of the maximum size of the queue.
+	minSegmentSize = 4 * 1024
+	maxSegmentSize = 16 * 1024 * 1024
+
+	// headInterval is the minimum time between writes of the head file as records are
+	// removed. The records removed since the last write are sent again if the process
+	// stops before the next.
+	headInterval = time.Second
+)
+
+var (
+	errQueueClosed   = errors.New("queue closed")
+	errCorruptRecord = errors.New("corrupt record")
+)
+
+// position is the offset of a record within a segment.
+type position struct {
+	segment uint64
+	offset  int64
+}
+
+// segment is a file of records appended to a queue.
+type segment struct {
+	id   uint64
+	f    *os.File
+	size int64
+}
+
+// record is the data of a record of a queue and the time that it was queued.
+type record struct {
+	data     []byte
+	enqueued time.Time
+
+	// start and next are the positions of the record and the record after it.
+	start, next position
+}
+
+// queueMetrics are the metrics that a queue updates as records are added and removed.
+type queueMetrics struct {
+	Bytes   prometheus.Gauge
+	Dropped prometheus.Counter
+}
+
+// queue is a durable FIFO queue of records. Records are appended to segment files in
+// a directory, and the position of the oldest record is kept in a file of the
+// directory, so that records that have not been removed survive restarts. Appended
+// records are synced to disk before append returns. The head file is written at most
+// every headInterval as records are removed, so a record may be sent more than once,
+// but is never lost. A queue is safe for concurrent use, but records must only be
+// removed by a single goroutine.
+type queue struct {
+	dir     string
+	metrics queueMetrics
+
+	// notify receives a value when a record is appended.
+	notify chan struct{}
+
+	mu          sync.Mutex
+	maxSize     int64
+	policy      platform.ReplicationDropPolicy
+	segmentSize int64
+	segments    []*segment
+	head        position
+	size        int64
+	closed      bool
+
+	// headWritten is when the head file was last written, and headDirty is whether the
+	// head has moved since.
+	headWritten time.Time
+	headDirty   bool
+}
+
+// openQueue opens the queue of the directory dir, creating it if it does not exist.
+// The last record of the queue is discarded if it was only partly written.
+func openQueue(dir string, maxSize int64, policy platform.ReplicationDropPolicy, m queueMetrics) (_ *queue, err error) {
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return nil, err
+	}
+
+	q := &queue{
+		dir:     dir,
+		metrics: m,
+		notify:  make(chan struct{}, 1),
+	}
+	q.setLimits(maxSize, policy)
+	defer func() {
+		if err != nil {
+			q.close()
+		}
+	}()
+
+	head, err := readHead(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	ids, err := segmentIDs(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		path := q.segmentPath(id)
+		if id < head.segment {
+			// The segment was sent, but not removed before the queue was closed.
+			if err := os.Remove(path); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		f, err := os.OpenFile(path, os.O_RDWR, 0666)
+		if err != nil {
+			return nil, err
+		}
+		fi, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		q.segments = append(q.segments, &segment{id: id, f: f, size: fi.Size()})
+	}
+
+	if len(q.segments) == 0 {
+		id := head.segment
+		if id == 0 {
+			id = 1
+		}
+		if _, err := q.createSegment(id); err != nil {
+			return nil, err
+		}
+		head = position{segment: id}
+	}
+
+	if first := q.segments[0]; head.segment != first.id {
+		head = position{segment: first.id}
+	} else if head.offset > first.size {
+		head.offset = first.size
+	}
+	q.head = head
+
+	if err := q.recover(); err != nil {
+		return nil, err
+	}
+
+	for _, s := range q.segments {
+		q.size += s.size
+	}
+	q.size -= q.head.offset
+	q.metrics.Bytes.Set(float64(q.size))
+	return q, nil
+}
+
+// segmentIDs returns the sorted IDs of the segment files of dir.
+func segmentIDs(dir string) ([]uint64, error) {
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []uint64
+	for _, fi := range fis {
+		name := fi.Name()
+		if fi.IsDir() || !strings.HasSuffix(name, segmentExt) {
+			continue
+		}
+		id, err := strconv.ParseUint(strings.TrimSuffix(name, segmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids, nil
+}
+
+// readHead returns the position kept in the head file of dir, or the zero position if
+// there is no head file.
+func readHead(dir string) (position, error) {
+	b, err := ioutil.ReadFile(filepath.Join(dir, headFile))
+	if os.IsNotExist(err) {
+		return position{}, nil
+	} else if err != nil {
+		return position{}, err
+	}
+	if len(b) != 16 {
+		return position{}, fmt.Errorf("invalid head file of %d bytes", len(b))
+	}
+	return position{
+		segment: binary.BigEndian.Uint64(b[0:8]),
+		offset:  int64(binary.BigEndian.Uint64(b[8:16])),
+	}, nil
+}
+
+// writeHead replaces the head file of the queue with the position of its head, and
+// syncs it and the directory to disk. It must be called with mu held.
+func (q *queue) writeHead() error {
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[0:8], q.head.segment)
+	binary.BigEndian.PutUint64(b[8:16], uint64(q.head.offset))
+
+	path := filepath.Join(q.dir, headFile)
+	f, err := os.OpenFile(path+".tmp", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b[:]); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := file.RenameFile(path+".tmp", path); err != nil {
+		return err
+	}
+	if err := file.SyncDir(q.dir); err != nil {
+		return err
+	}
+
+	q.headWritten, q.headDirty = time.Now(), false
+	return nil
+}
+
+func (q *queue) segmentPath(id uint64) string {
+	return filepath.Join(q.dir, fmt.Sprintf("%020d%s", id, segmentExt))
+}
+
+// createSegment creates an empty segment and adds it to the end of the queue.
+func (q *queue) createSegment(id uint64) (*segment, error) {
+	f, err := os.OpenFile(q.segmentPath(id), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
+	if err != nil {
+		return nil, err
+	}
+	// The directory is synced, so that the records synced to the segment are found.
+	if err := file.SyncDir(q.dir); err != nil {
+		f.Close()
+		return nil, err
+	}
+	s := &segment{id: id, f: f}
+	q.segments = append(q.segments, s)
+	return s, nil
+}
+
+// recover truncates the last segment after its last complete record, which is where
+// an append was interrupted if the process stopped while writing.
+func (q *queue) recover() error {
+	tail := q.segments[len(q.segments)-1]
+
+	var offset int64
+	if tail.id == q.head.segment {
+		offset = q.head.offset
+	}
+	for offset < tail.size {
+		_, _, n, err := readRecord(tail, offset)
+		if err != nil {
+			break
+		}
+		offset += n
+	}
+
+	if offset == tail.size {
+		return nil
+	}
+	if err := tail.f.Truncate(offset); err != nil {
+		return err
+	}
+	tail.size = offset
+	return nil
+}
+
+// readRecord returns the data of the record at offset of s, the time that it was
+// queued and its size.
+func readRecord(s *segment, offset int64) ([]byte, time.Time, int64, error) {
+	if offset+recordHeaderSize > s.size {
+		return nil, time.Time{}, 0, errCorruptRecord
+	}
+	var hdr [recordHeaderSize]byte
+	if _, err := s.f.ReadAt(hdr[:], offset); err != nil {
+		return nil, time.Time{}, 0, err
+	}
+
+	length := int64(binary.BigEndian.Uint32(hdr[0:4]))
+	n := recordHeaderSize + length
+	if offset+n > s.size {
+		return nil, time.Time{}, 0, errCorruptRecord
+	}
+
+	data := make([]byte, length)
+	if _, err := s.f.ReadAt(data, offset+recordHeaderSize); err != nil {
+		return nil, time.Time{}, 0, err
+	}
+	if crc32.ChecksumIEEE(data) != binary.BigEndian.Uint32(hdr[4:8]) {
+		return nil, time.Time{}, 0, errCorruptRecord
+	}
+	return data, time.Unix(0, int64(binary.BigEndian.Uint64(hdr[8:16]))), n, nil
+}
+
+// recordSize returns the size of the record at offset of s.
+func recordSize(s *segment, offset int64) (int64, error) {
+	if offset+recordHeaderSize > s.size {
+		return 0, errCorruptRecord
+	}
+	var b [4]byte
+	if _, err := s.f.ReadAt(b[:], offset); err != nil {
+		return 0, err
+	}
+	n := recordHeaderSize + int64(binary.BigEndian.Uint32(b[:]))
+	if offset+n > s.size {
+		return 0, errCorruptRecord
+	}
+	return n, nil
+}
+
+// setLimits sets the maximum size of the queue and what is dropped once it is reached,
+// which apply to the records appended after.
+func (q *queue) setLimits(maxSize int64, policy platform.ReplicationDropPolicy) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.maxSize, q.policy = maxSize, policy
+	q.segmentSize = maxSize / 8
+	if q.segmentSize < minSegmentSize {
+		q.segmentSize = minSegmentSize
+	} else if q.segmentSize > maxSegmentSize {
+		q.segmentSize = maxSegmentSize
+	}
+}
+
+// append adds a record of data to the end of the queue. If the queue is full, the
+// oldest records are dropped to make room for it, or it is dropped, as set by the
+// drop policy.
+func (q *queue) append(data []byte) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return errQueueClosed
+	}
+
+	n := recordHeaderSize + int64(len(data))
+	if n > q.maxSize || (q.size+n > q.maxSize && q.policy == platform.ReplicationDropNewest) {
+		q.metrics.Dropped.Add(float64(len(data)))
+		return nil
+	}
+
+	if q.size+n > q.maxSize {
+		for q.size > 0 && q.size+n > q.maxSize {
+			if err := q.dropHead(); err != nil {
+				return err
+			}
+		}
+		if err := q.writeHead(); err != nil {
+			return err
+		}
+	}
+
+	tail := q.segments[len(q.segments)-1]
+	if tail.size > 0 && tail.size+n > q.segmentSize {
+		var err error
+		if tail, err = q.createSegment(tail.id + 1); err != nil {
+			return err
+		}
+		if err := q.removeSent(); err != nil {
+			return err
+		}
+	}
+
+	b := make([]byte, n)
+	binary.BigEndian.PutUint32(b[0:4], uint32(len(data)))
+	binary.BigEndian.PutUint32(b[4:8], crc32.ChecksumIEEE(data))
+	binary.BigEndian.PutUint64(b[8:16], uint64(time.Now().UnixNano()))
+	copy(b[recordHeaderSize:], data)
+	// What is written of a failed write is overwritten by the next append, as the
+	// size of the segment is not changed.
+	if _, err := tail.f.WriteAt(b, tail.size); err != nil {
+		return err
+	}
+	if err := tail.f.Sync(); err != nil {
+		return err
+	}
+	tail.size += n
+	q.size += n
+	q.metrics.Bytes.Set(float64(q.size))
+
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// dropHead drops the oldest record. It must be called with mu held.
+func (q *queue) dropHead() error {
+	if err := q.removeSent(); err != nil {
+		return err
+	}
+
+	s := q.segments[0]
+	n, err := recordSize(s, q.head.offset)
+	if err == errCorruptRecord {
+		// Drop the rest of the segment, as the record after cannot be found.
+		n = s.size - q.head.offset
+	} else if err != nil {
+		return err
+	}
+
+	q.head.offset += n
+	q.size -= n
+	q.metrics.Dropped.Add(float64(n - recordHeaderSize))
+	q.metrics.Bytes.Set(float64(q.size))
+	return nil
+}
+
+// removeSent removes the segments before the head whose records have all been
+// removed. It must be called with mu held.
+func (q *queue) removeSent() error {
+	for len(q.segments) > 1 && q.head.offset >= q.segments[0].size {
+		s := q.segments[0]
+		q.segments = q.segments[1:]
+		q.head = position{segment: q.segments[0].id}
+
+		if err := s.f.Close(); err != nil {
+			return err
+		}
+		if err := os.Remove(s.f.Name()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// peek returns the oldest record, or false if the queue is empty. A record that is
+// corrupt is dropped with the rest of its segment, and errCorruptRecord is returned.
+func (q *queue) peek() (*record, bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return nil, false, errQueueClosed
+	}
+	if err := q.removeSent(); err != nil {
+		return nil, false, err
+	}
+
+	s := q.segments[0]
+	if q.head.offset >= s.size {
+		return nil, false, nil
+	}
+
+	data, enqueued, n, err := readRecord(s, q.head.offset)
+	if err == errCorruptRecord {
+		dropped := s.size - q.head.offset
+		q.head.offset = s.size
+		q.size -= dropped
+		q.metrics.Dropped.Add(float64(dropped))
+		q.metrics.Bytes.Set(float64(q.size))
+		if err := q.writeHead(); err != nil {
+			return nil, false, err
+		}
+		return nil, false, errCorruptRecord
+	} else if err != nil {
+		return nil, false, err
+	}
+
+	return &record{
+		data:     data,
+		enqueued: enqueued,
+		start:    q.head,
+		next:     position{segment: s.id, offset: q.head.offset + n},
+	}, true, nil
+}
+
+// remove removes r, which was returned by peek, from the queue. It does nothing if r
+// has been dropped since.
+func (q *queue) remove(r *record) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return errQueueClosed
+	}
+	if q.head != r.start {
+		return nil
+	}
+
+	q.size -= r.next.offset - r.start.offset
+	q.head = r.next
+	q.headDirty = true
+	q.metrics.Bytes.Set(float64(q.size))
+	if err := q.removeSent(); err != nil {
+		return err
+	}
+	// The head file is written once the queue is empty, so that an idle queue does
+	// not send its last records again.
+	if q.size > 0 && time.Since(q.headWritten) < headInterval {
+		return nil
+	}
+	return q.writeHead()
+}
+
+// close writes the head file, if the head has moved since it was last written, and
+// closes the segment files of the queue.
+func (q *queue) close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return nil
+	}
+	q.closed = true
+
+	var err error
+	if q.headDirty {
+		err = q.writeHead()
+	}
+	for _, s := range q.segments {
+		if e := s.f.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
@@ -0,0 +1,154 @@
+package replication
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/influxdata/platform"
+)
+
+func openTestQueue(t *testing.T, dir string, maxSize int64) *queue {
+	t.Helper()
+	q, err := openQueue(dir, maxSize, platform.ReplicationDropOldest, newMetrics().queueMetrics("test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return q
+}
+
+// readAll removes and returns the data of every record of q.
+func readAll(t *testing.T, q *queue) []string {
+	t.Helper()
+	var got []string
+	for {
+		rec, ok, err := q.peek()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			return got
+		}
+		got = append(got, string(rec.data))
+		if err := q.remove(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestQueue_Reopen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "replication-queue-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Records larger than half a segment roll a segment for each record.
+	q := openTestQueue(t, dir, 8*minSegmentSize)
+	big := string(make([]byte, minSegmentSize/2+1))
+	for _, s := range []string{"a", big, "b", big, "c"} {
+		if err := q.append([]byte(s)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rec, _, err := q.peek()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := q.remove(rec); err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate a process stopped while appending a record.
+	tail := q.segments[len(q.segments)-1]
+	if _, err := tail.f.WriteAt([]byte{0, 0, 1, 0, 1, 2}, tail.size); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.close(); err != nil {
+		t.Fatal(err)
+	}
+
+	q = openTestQueue(t, dir, 8*minSegmentSize)
+	defer q.close()
+	if err := q.append([]byte("d")); err != nil {
+		t.Fatal(err)
+	}
+
+	got := readAll(t, q)
+	want := []string{big, "b", big, "c", "d"}
+	if len(got) != len(want) {
+		t.Fatalf("got %d records, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("record %d: got %d bytes, want %d", i, len(got[i]), len(want[i]))
+		}
+	}
+
+	ids, err := segmentIDs(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 1 {
+		t.Errorf("got %d segments after reading every record, want 1", len(ids))
+	}
+}
+
+func TestQueue_RemoveWritesHead(t *testing.T) {
+	dir, err := ioutil.TempDir("", "replication-queue-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	q := openTestQueue(t, dir, 8*minSegmentSize)
+	for _, s := range []string{"a", "b", "c"} {
+		if err := q.append([]byte(s)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	remove := func() position {
+		t.Helper()
+		rec, _, err := q.peek()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := q.remove(rec); err != nil {
+			t.Fatal(err)
+		}
+		return rec.next
+	}
+	assertHead := func(want position) {
+		t.Helper()
+		if got, err := readHead(dir); err != nil {
+			t.Fatal(err)
+		} else if got != want {
+			t.Errorf("got head file %+v, want %+v", got, want)
+		}
+	}
+
+	// The first remove writes the head file, and the next, within headInterval, does not.
+	afterA := remove()
+	assertHead(afterA)
+	afterB := remove()
+	assertHead(afterA)
+
+	// Closing the queue writes the head that moved since.
+	if err := q.close(); err != nil {
+		t.Fatal(err)
+	}
+	assertHead(afterB)
+
+	q = openTestQueue(t, dir, 8*minSegmentSize)
+	if got := readAll(t, q); len(got) != 1 || got[0] != "c" {
+		t.Errorf("got records %q, want [c]", got)
+	}
+
+	// Emptying the queue writes the head file, so that an idle queue does not send
+	// its last records again.
+	assertHead(position{segment: afterB.segment, offset: q.head.offset})
+	if err := q.close(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,384 @@
+package replication_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/platform"
+	"github.com/influxdata/platform/inmem"
+	"github.com/influxdata/platform/kit/errors"
+	"github.com/influxdata/platform/kit/prom"
+	"github.com/influxdata/platform/kit/prom/promtest"
+	"github.com/influxdata/platform/mock"
+	"github.com/influxdata/platform/models"
+	"github.com/influxdata/platform/replication"
+	"github.com/influxdata/platform/tsdb"
+)
+
+const (
+	orgID          = platform.ID(0x1000)
+	bucketID       = platform.ID(0x2000)
+	otherBucketID  = platform.ID(0x2001)
+	remoteOrgID    = platform.ID(0x3000)
+	remoteBucketID = platform.ID(0x4000)
+)
+
+// remote is a platform.WriteService that records the line protocol written to it.
+type remote struct {
+	mu    sync.Mutex
+	err   error
+	lines []string
+}
+
+func (r *remote) Write(ctx context.Context, org, bucket platform.ID, rd io.Reader) error {
+	b, err := ioutil.ReadAll(rd)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+	if org != remoteOrgID || bucket != remoteBucketID {
+		return fmt.Errorf("unexpected org %s and bucket %s", org, bucket)
+	}
+	r.lines = append(r.lines, strings.Split(strings.TrimSpace(string(b)), "\n")...)
+	return nil
+}
+
+func (r *remote) setErr(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.err = err
+}
+
+func (r *remote) Lines() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.lines...)
+}
+
+// waitLines waits for the remote to receive n lines.
+func (r *remote) waitLines(t *testing.T, n int) []string {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		lines := r.Lines()
+		if len(lines) >= n {
+			return lines
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("got %d lines, want %d: %v", len(lines), n, lines)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+type fixture struct {
+	dir     string
+	svc     *inmem.Service
+	remote  *remote
+	manager *replication.Manager
+	writer  *replication.Writer
+	points  *mock.PointsWriter
+	reg     *prom.Registry
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "replication-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fixture{dir: dir, svc: inmem.NewService(), remote: &remote{}}
+	f.open()
+	return f
+}
+
+// open opens a manager of the queues of the fixture, as after a restart.
+func (f *fixture) open() {
+	f.manager = replication.NewManager(f.svc, f.dir, func(*platform.Replication) platform.WriteService {
+		return f.remote
+	})
+	f.manager.MaxBackoff = 10 * time.Millisecond
+	f.points = &mock.PointsWriter{}
+	f.writer = replication.NewWriter(f.points, f.manager)
+	f.reg = prom.NewRegistry()
+	f.reg.MustRegister(f.manager.PrometheusCollectors()...)
+}
+
+func (f *fixture) Close() {
+	f.manager.Close()
+	os.RemoveAll(f.dir)
+}
+
+// create creates and starts a replication of the local bucket.
+func (f *fixture) create(t *testing.T, maxSize int64, policy platform.ReplicationDropPolicy) *platform.Replication {
+	t.Helper()
+	r := &platform.Replication{
+		Name:                 "cloud",
+		OrganizationID:       orgID,
+		LocalBucketID:        bucketID,
+		RemoteURL:            "http://cloud:9999",
+		RemoteToken:          "token",
+		RemoteOrganizationID: remoteOrgID,
+		RemoteBucketID:       remoteBucketID,
+		MaxQueueSizeBytes:    maxSize,
+		DropPolicy:           policy,
+	}
+	s := &replication.Service{ReplicationService: f.svc, Manager: f.manager}
+	if err := s.CreateReplication(context.Background(), r); err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+// write writes the line protocol lp to bucket.
+func (f *fixture) write(t *testing.T, bucket platform.ID, lp string) {
+	t.Helper()
+	points, err := models.ParsePointsString(lp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exploded, err := tsdb.ExplodePoints(orgID, bucket, points)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.writer.WritePoints(exploded); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (f *fixture) metric(t *testing.T, name string, r *platform.Replication) float64 {
+	t.Helper()
+	mfs := promtest.MustGather(t, f.reg)
+	m := promtest.MustFindMetric(t, mfs, name, map[string]string{"replication_id": r.ID.String()})
+	if g := m.GetGauge(); g != nil {
+		return g.GetValue()
+	}
+	return m.GetCounter().GetValue()
+}
+
+// waitMetric waits for the metric name of r to satisfy fn.
+func (f *fixture) waitMetric(t *testing.T, name string, r *platform.Replication, fn func(float64) bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for v := f.metric(t, name, r); !fn(v); v = f.metric(t, name, r) {
+		if time.Now().After(deadline) {
+			t.Fatalf("got %s of %v", name, v)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestWriter(t *testing.T) {
+	f := newFixture(t)
+	defer f.Close()
+
+	r := f.create(t, 0, "")
+	f.write(t, bucketID, "cpu,host=a usage=1,idle=99i 10\nmem,host=a free=2 10")
+	f.write(t, otherBucketID, "cpu,host=b usage=3 10")
+
+	lines := f.remote.waitLines(t, 3)
+	want := []string{
+		"cpu,host=a usage=1 10",
+		"cpu,host=a idle=99i 10",
+		"mem,host=a free=2 10",
+	}
+	if fmt.Sprint(lines) != fmt.Sprint(want) {
+		t.Errorf("got lines %q, want %q", lines, want)
+	}
+	if n := len(f.points.Points); n != 4 {
+		t.Errorf("got %d points written locally, want 4", n)
+	}
+
+	f.waitMetric(t, "replication_sent_bytes_total", r, func(v float64) bool { return v > 0 })
+	f.waitMetric(t, "replication_queue_bytes", r, func(v float64) bool { return v == 0 })
+}
+
+func TestWriter_WriteError(t *testing.T) {
+	f := newFixture(t)
+	defer f.Close()
+
+	f.create(t, 0, "")
+	f.points.ForceError(fmt.Errorf("engine closed"))
+
+	points, err := models.ParsePointsString("cpu usage=1 10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exploded, err := tsdb.ExplodePoints(orgID, bucketID, points)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.writer.WritePoints(exploded); err == nil {
+		t.Fatal("expected error")
+	}
+
+	f.points.ForceError(nil)
+	f.write(t, bucketID, "cpu usage=2 20")
+	if lines := f.remote.waitLines(t, 1); len(lines) != 1 || lines[0] != "cpu usage=2 20" {
+		t.Errorf("got lines %q, want only the accepted write", lines)
+	}
+}
+
+func TestManager_Restart(t *testing.T) {
+	f := newFixture(t)
+	defer f.Close()
+
+	r := f.create(t, 0, "")
+	f.remote.setErr(fmt.Errorf("connection refused"))
+	f.write(t, bucketID, "cpu usage=1 10")
+	f.write(t, bucketID, "cpu usage=2 20")
+
+	if got := f.metric(t, "replication_queue_bytes", r); got == 0 {
+		t.Error("got no bytes queued")
+	}
+	f.manager.Close()
+
+	f.remote.setErr(nil)
+	f.open()
+	if err := f.manager.Sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := f.remote.waitLines(t, 2)
+	want := []string{"cpu usage=1 10", "cpu usage=2 20"}
+	if fmt.Sprint(lines) != fmt.Sprint(want) {
+		t.Errorf("got lines %q, want %q", lines, want)
+	}
+}
+
+func TestManager_DropPolicy(t *testing.T) {
+	tests := []struct {
+		policy platform.ReplicationDropPolicy
+		want   string
+	}{
+		{policy: platform.ReplicationDropOldest, want: "cpu usage=9 90"},
+		{policy: platform.ReplicationDropNewest, want: "cpu usage=0 0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			f := newFixture(t)
+			defer f.Close()
+
+			// Room for a single write of a point.
+			r := f.create(t, 40, tt.policy)
+			f.remote.setErr(fmt.Errorf("connection refused"))
+			for i := 0; i < 10; i++ {
+				f.write(t, bucketID, fmt.Sprintf("cpu usage=%d %d", i, i*10))
+			}
+
+			if got := f.metric(t, "replication_dropped_bytes_total", r); got == 0 {
+				t.Error("got no bytes dropped")
+			}
+			if got := f.metric(t, "replication_queue_bytes", r); got > 40 {
+				t.Errorf("got %v bytes queued, want at most 40", got)
+			}
+
+			f.remote.setErr(nil)
+			lines := f.remote.waitLines(t, 1)
+			if len(lines) != 1 || lines[0] != tt.want {
+				t.Errorf("got lines %q, want %q", lines, tt.want)
+			}
+		})
+	}
+}
+
+func TestManager_Rejected(t *testing.T) {
+	f := newFixture(t)
+	defer f.Close()
+
+	r := f.create(t, 0, "")
+	f.remote.setErr(&errors.Error{Code: 400, Err: "field type conflict"})
+	f.write(t, bucketID, "cpu usage=1 10")
+
+	f.waitMetric(t, "replication_rejected_bytes_total", r, func(v float64) bool { return v > 0 })
+
+	f.remote.setErr(nil)
+	f.write(t, bucketID, "cpu usage=2 20")
+	if lines := f.remote.waitLines(t, 1); len(lines) != 1 || lines[0] != "cpu usage=2 20" {
+		t.Errorf("got lines %q, want only the write after the rejected one", lines)
+	}
+}
+
+// tokenRemote is a remote that only accepts writes with a token.
+type tokenRemote struct {
+	*remote
+	token string
+}
+
+func (r tokenRemote) Write(ctx context.Context, org, bucket platform.ID, rd io.Reader) error {
+	if r.token != "new-token" {
+		return &errors.Error{Code: 401, Err: "authorization not found"}
+	}
+	return r.remote.Write(ctx, org, bucket, rd)
+}
+
+func TestService_UpdateToken(t *testing.T) {
+	f := newFixture(t)
+	defer f.Close()
+
+	f.manager.NewWriteService = func(r *platform.Replication) platform.WriteService {
+		return tokenRemote{remote: f.remote, token: r.RemoteToken}
+	}
+	r := f.create(t, 0, "")
+
+	// Writes are kept while the remote token is invalid, rather than rejected.
+	f.write(t, bucketID, "cpu usage=1 10")
+	f.waitMetric(t, "replication_failed_writes_total", r, func(v float64) bool { return v > 0 })
+	if got := f.metric(t, "replication_rejected_bytes_total", r); got != 0 {
+		t.Errorf("got %v bytes rejected, want 0", got)
+	}
+
+	token := "new-token"
+	s := &replication.Service{ReplicationService: f.svc, Manager: f.manager}
+	if _, err := s.UpdateReplication(context.Background(), r.ID, platform.ReplicationUpdate{RemoteToken: &token}); err != nil {
+		t.Fatal(err)
+	}
+
+	if lines := f.remote.waitLines(t, 1); len(lines) != 1 || lines[0] != "cpu usage=1 10" {
+		t.Errorf("got lines %q, want the write queued before the update", lines)
+	}
+}
+
+func TestService_Delete(t *testing.T) {
+	f := newFixture(t)
+	defer f.Close()
+
+	r := f.create(t, 0, "")
+	f.remote.setErr(fmt.Errorf("connection refused"))
+	f.write(t, bucketID, "cpu usage=1 10")
+
+	dir := filepath.Join(f.dir, r.ID.String())
+	if _, err := os.Stat(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	s := &replication.Service{ReplicationService: f.svc, Manager: f.manager}
+	if err := s.DeleteReplication(context.Background(), r.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("got error %v for the queue of a deleted replication, want not exist", err)
+	}
+
+	f.remote.setErr(nil)
+	f.write(t, bucketID, "cpu usage=2 20")
+	time.Sleep(50 * time.Millisecond)
+	if lines := f.remote.Lines(); len(lines) != 0 {
+		t.Errorf("got lines %q of a deleted replication", lines)
+	}
+}
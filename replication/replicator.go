@@ -0,0 +1,157 @@
+package replication
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/influxdata/platform"
+	kerrors "github.com/influxdata/platform/kit/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultMaxBackoff is the maximum wait between the attempts to send a write to the
+	// remote instance.
+	DefaultMaxBackoff = 30 * time.Second
+
+	// initialBackoff is the wait after the first failed attempt to send a write, which
+	// doubles after each attempt up to the maximum.
+	initialBackoff = 100 * time.Millisecond
+
+	// writeTimeout is the maximum time of an attempt to send a write.
+	writeTimeout = 30 * time.Second
+)
+
+// replicator sends the writes of the queue of a replication to its remote bucket,
+// retrying them until they are accepted or rejected by the remote instance.
+type replicator struct {
+	r          platform.Replication
+	q          *queue
+	w          platform.WriteService
+	logger     *zap.Logger
+	maxBackoff time.Duration
+
+	lag                      prometheus.Gauge
+	sent, rejected, failures prometheus.Counter
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func newReplicator(r platform.Replication, q *queue, w platform.WriteService, m *metrics, maxBackoff time.Duration, logger *zap.Logger) *replicator {
+	id := r.ID.String()
+	ctx, cancel := context.WithCancel(context.Background())
+	rp := &replicator{
+		r:          r,
+		q:          q,
+		w:          w,
+		logger:     logger,
+		maxBackoff: maxBackoff,
+		lag:        m.Lag.WithLabelValues(id),
+		sent:       m.Sent.WithLabelValues(id),
+		rejected:   m.Rejected.WithLabelValues(id),
+		failures:   m.Failures.WithLabelValues(id),
+		ctx:        ctx,
+		cancel:     cancel,
+		done:       make(chan struct{}),
+	}
+	go rp.run()
+	return rp
+}
+
+// close stops the replicator, leaving the write that is being sent in the queue.
+func (rp *replicator) close() {
+	rp.cancel()
+	<-rp.done
+}
+
+func (rp *replicator) run() {
+	defer close(rp.done)
+
+	backoff := initialBackoff
+	for {
+		rec, ok, err := rp.q.peek()
+		if err == errQueueClosed {
+			return
+		} else if err != nil {
+			rp.logger.Info("Failed to read queued write", zap.Error(err))
+			if !rp.wait(backoff) {
+				return
+			}
+			continue
+		}
+
+		if !ok {
+			rp.lag.Set(0)
+			select {
+			case <-rp.ctx.Done():
+				return
+			case <-rp.q.notify:
+			}
+			continue
+		}
+		rp.lag.Set(time.Since(rec.enqueued).Seconds())
+
+		ctx, cancel := context.WithTimeout(rp.ctx, writeTimeout)
+		err = rp.w.Write(ctx, rp.r.RemoteOrganizationID, rp.r.RemoteBucketID, bytes.NewReader(rec.data))
+		cancel()
+
+		switch {
+		case rp.ctx.Err() != nil:
+			return
+		case err == nil:
+			rp.sent.Add(float64(len(rec.data)))
+		case rejected(err):
+			// The write can never succeed, so it is dropped rather than retried.
+			rp.logger.Info("Remote instance rejected replicated write", zap.Int("bytes", len(rec.data)), zap.Error(err))
+			rp.rejected.Add(float64(len(rec.data)))
+		default:
+			rp.logger.Info("Failed to send replicated write", zap.Duration("backoff", backoff), zap.Error(err))
+			rp.failures.Inc()
+			if !rp.wait(backoff) {
+				return
+			}
+			if backoff *= 2; backoff > rp.maxBackoff {
+				backoff = rp.maxBackoff
+			}
+			continue
+		}
+
+		backoff = initialBackoff
+		if err := rp.q.remove(rec); err != nil {
+			if err == errQueueClosed {
+				return
+			}
+			rp.logger.Info("Failed to remove sent write from queue", zap.Error(err))
+		}
+	}
+}
+
+// wait waits for d, and returns false if the replicator is closed first.
+func (rp *replicator) wait(d time.Duration) bool {
+	select {
+	case <-rp.ctx.Done():
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
+
+// rejected returns true if err is the remote instance rejecting a write as invalid or
+// too large, which cannot succeed when retried. Other errors, such as an unavailable
+// instance or a revoked token, may be resolved.
+func rejected(err error) bool {
+	e, ok := err.(*kerrors.Error)
+	if !ok {
+		return false
+	}
+	switch e.Code {
+	case http.StatusBadRequest, http.StatusRequestEntityTooLarge, http.StatusUnprocessableEntity:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package replication
+
+import (
+	"bytes"
+
+	"github.com/influxdata/platform/models"
+	"github.com/influxdata/platform/storage"
+	"github.com/influxdata/platform/tsdb"
+)
+
+// Writer is a storage.PointsWriter that writes exploded points to PointsWriter, and
+// queues the points that are accepted into buckets with replications to be sent to
+// their remote buckets.
+type Writer struct {
+	PointsWriter storage.PointsWriter
+	Manager      *Manager
+}
+
+// NewWriter returns a writer to w that queues the points of the replications of m.
+func NewWriter(w storage.PointsWriter, m *Manager) *Writer {
+	return &Writer{PointsWriter: w, Manager: m}
+}
+
+// WritePoints writes points and queues those that are accepted, leaving out the points
+// that are dropped by a partial write.
+func (w *Writer) WritePoints(points []models.Point) error {
+	err := w.PointsWriter.WritePoints(points)
+	if err == nil {
+		w.Manager.enqueue(points)
+		return nil
+	}
+
+	pwe, ok := err.(tsdb.PartialWriteError)
+	if !ok {
+		return err
+	}
+
+	keys := make(map[string]struct{}, len(pwe.DroppedKeys))
+	for _, k := range pwe.DroppedKeys {
+		keys[string(k)] = struct{}{}
+	}
+
+	accepted := make([]models.Point, 0, len(points))
+	for i, pt := range points {
+		if _, ok := pwe.Conflicts[i]; ok {
+			continue
+		}
+		if _, ok := keys[string(pt.Key())]; ok {
+			continue
+		}
+		accepted = append(accepted, pt)
+	}
+	w.Manager.enqueue(accepted)
+	return err
+}
+
+// appendLine appends the line protocol of an exploded point to buf, with its original
+// measurement and without the tags of its measurement and field.
+func appendLine(buf []byte, pt models.Point) ([]byte, error) {
+	tags := pt.Tags()
+	measurement := tags.Get(tsdb.MeasurementTagKeyBytes)
+
+	others := make(models.Tags, 0, len(tags))
+	for _, t := range tags {
+		if bytes.Equal(t.Key, tsdb.MeasurementTagKeyBytes) || bytes.Equal(t.Key, tsdb.FieldKeyTagKeyBytes) {
+			continue
+		}
+		others = append(others, t)
+	}
+
+	fields, err := pt.Fields()
+	if err != nil {
+		return buf, err
+	}
+
+	line, err := models.NewPoint(string(measurement), others, fields, pt.Time())
+	if err != nil {
+		return buf, err
+	}
+	return append(line.AppendString(buf), '\n'), nil
+}
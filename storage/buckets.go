@@ -0,0 +1,136 @@
+package storage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/influxdata/platform"
+	"github.com/influxdata/platform/models"
+	"github.com/influxdata/platform/tsdb"
+)
+
+// bucketRefreshInterval is how often the engine reads the settings of buckets again.
+const bucketRefreshInterval = 10 * time.Second
+
+// bucketCache holds the settings of every bucket that the engine depends on, such as
+// partition durations. It is read from a BucketFinder when the engine opens, and then
+// periodically, so that changes to buckets take effect after a short delay.
+type bucketCache struct {
+	finder BucketFinder
+
+	mu      sync.RWMutex
+	buckets map[platform.ID]*platform.Bucket
+	// schemas are the explicit schemas of the buckets that have one.
+	schemas map[platform.ID]bucketSchema
+}
+
+// bucketSchema is the type of each field of each measurement of an explicit bucket schema.
+type bucketSchema map[string]map[string]string
+
+func newBucketSchema(measurements []platform.MeasurementSchema) bucketSchema {
+	s := make(bucketSchema, len(measurements))
+	for _, m := range measurements {
+		fields := make(map[string]string, len(m.Fields))
+		for _, f := range m.Fields {
+			fields[f.Name] = f.Type
+		}
+		s[m.Name] = fields
+	}
+	return s
+}
+
+// check returns why a point whose field of measurement has type typ does not match the
+// schema, and false if it does.
+func (s bucketSchema) check(measurement, field []byte, typ models.FieldType) (tsdb.SchemaMismatchError, bool) {
+	fields, ok := s[string(measurement)]
+	if !ok {
+		return tsdb.SchemaMismatchError{Measurement: string(measurement)}, true
+	}
+	schemaType, ok := fields[string(field)]
+	if got := SchemaFieldType(typ); !ok || got != schemaType {
+		return tsdb.SchemaMismatchError{
+			Measurement: string(measurement),
+			Field:       string(field),
+			Type:        got,
+			SchemaType:  schemaType,
+		}, true
+	}
+	return tsdb.SchemaMismatchError{}, false
+}
+
+func newBucketCache(finder BucketFinder) *bucketCache {
+	return &bucketCache{
+		finder:  finder,
+		buckets: make(map[platform.ID]*platform.Bucket),
+		schemas: make(map[platform.ID]bucketSchema),
+	}
+}
+
+// refresh reads the settings of every bucket again.
+func (c *bucketCache) refresh(ctx context.Context) error {
+	buckets, _, err := c.finder.FindBuckets(ctx, platform.BucketFilter{})
+	if err != nil {
+		return err
+	}
+
+	m := make(map[platform.ID]*platform.Bucket, len(buckets))
+	schemas := make(map[platform.ID]bucketSchema)
+	for _, b := range buckets {
+		m[b.ID] = b
+		if b.SchemaType == platform.SchemaTypeExplicit {
+			schemas[b.ID] = newBucketSchema(b.Schema)
+		}
+	}
+
+	c.mu.Lock()
+	c.buckets, c.schemas = m, schemas
+	c.mu.Unlock()
+	return nil
+}
+
+// bucket returns the bucket of the measurement name, which encodes the organization and bucket
+// IDs of the data. It returns nil if the bucket is unknown.
+func (c *bucketCache) bucket(name []byte) *platform.Bucket {
+	bucketID, ok := bucketIDOf(name)
+	if !ok {
+		return nil
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.buckets[bucketID]
+}
+
+// schema returns the explicit schema of the bucket of the measurement name, or nil if the
+// bucket has none or is unknown.
+func (c *bucketCache) schema(name []byte) bucketSchema {
+	bucketID, ok := bucketIDOf(name)
+	if !ok {
+		return nil
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.schemas[bucketID]
+}
+
+// bucketIDOf returns the bucket ID encoded by the measurement name.
+func bucketIDOf(name []byte) (platform.ID, bool) {
+	if len(name) != platform.IDLength {
+		return 0, false
+	}
+	var n [platform.IDLength]byte
+	copy(n[:], name)
+	_, bucketID := tsdb.DecodeName(n)
+	return bucketID, true
+}
+
+// partitionDuration returns the partition duration of the bucket of the measurement name.
+// It satisfies tsm1.PartitionDurationFunc.
+func (c *bucketCache) partitionDuration(name []byte) time.Duration {
+	if b := c.bucket(name); b != nil {
+		return b.PartitionDuration
+	}
+	return 0
+}
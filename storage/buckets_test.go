@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/platform"
+	"github.com/influxdata/platform/tsdb"
+)
+
+func TestBucketCache_partitionDuration(t *testing.T) {
+	buckets := []*platform.Bucket{
+		{ID: 10, OrganizationID: 1, PartitionDuration: 24 * time.Hour},
+		{ID: 11, OrganizationID: 1},
+	}
+	finder := NewTestBucketFinder()
+	finder.FindBucketsFn = func(context.Context, platform.BucketFilter, ...platform.FindOptions) ([]*platform.Bucket, int, error) {
+		return buckets, len(buckets), nil
+	}
+
+	name := func(bucketID platform.ID) []byte {
+		n := tsdb.EncodeName(1, bucketID)
+		return n[:]
+	}
+
+	c := newBucketCache(finder)
+	if got := c.partitionDuration(name(10)); got != 0 {
+		t.Fatalf("expected no partitioning before the buckets are read, got %v", got)
+	}
+
+	if err := c.refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name []byte
+		exp  time.Duration
+	}{
+		{name: name(10), exp: 24 * time.Hour},
+		{name: name(11), exp: 0},      // Not partitioned.
+		{name: name(12), exp: 0},      // Unknown bucket.
+		{name: []byte("cpu"), exp: 0}, // Not the name of a bucket.
+	}
+	for _, tt := range tests {
+		if got := c.partitionDuration(tt.name); got != tt.exp {
+			t.Errorf("partition duration of %q: got %v, exp %v", tt.name, got, tt.exp)
+		}
+	}
+
+	// Changes to buckets take effect once they are read again.
+	buckets = buckets[1:]
+	if err := c.refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.partitionDuration(name(10)); got != 0 {
+		t.Fatalf("expected no partitioning of a removed bucket, got %v", got)
+	}
+}
@@ -11,9 +11,12 @@ import (
 
 const (
 	DefaultRetentionInterval   = 1 * time.Hour
+	DefaultPurgeInterval       = 1 * time.Minute
 	DefaultValidateKeys        = false
 	DefaultTraceLoggingEnabled = false
 
+	DefaultLastValueCacheMaxMemorySize = 64 * 1024 * 1024 // 64MB
+
 	DefaultSeriesFileDirectoryName = "_series"
 	DefaultIndexDirectoryName      = "index"
 	DefaultWALDirectoryName        = "wal"
@@ -25,6 +28,9 @@ type Config struct {
 	// Frequency of retention in seconds.
 	RetentionInterval toml.Duration `toml:"retention-interval"`
 
+	// Frequency at which the data of deleted buckets and organizations is purged.
+	PurgeInterval toml.Duration `toml:"purge-interval"`
+
 	// Enables unicode validation on series keys on write.
 	ValidateKeys bool `toml:"validate-keys"`
 
@@ -45,18 +51,37 @@ type Config struct {
 	// Index config.
 	Index     tsi1.Config `toml:"index"`
 	IndexPath string      `toml:"index-path"` // Overrides the default path.
+
+	// Last-value cache config.
+	LastValueCache LastValueCacheConfig `toml:"last-value-cache"`
+}
+
+// LastValueCacheConfig holds the configuration of the cache of the last value of every
+// series, which serves reads of the latest points without reading TSM blocks.
+type LastValueCacheConfig struct {
+	// Enables the last-value cache.
+	Enabled bool `toml:"enabled"`
+
+	// MaxMemorySize is the size the cache may reach before the least recently used
+	// values are evicted.
+	MaxMemorySize toml.Size `toml:"max-memory-size"`
 }
 
 // NewConfig initialises a new config for an Engine.
 func NewConfig() Config {
 	return Config{
 		RetentionInterval:   toml.Duration(DefaultRetentionInterval),
+		PurgeInterval:       toml.Duration(DefaultPurgeInterval),
 		ValidateKeys:        DefaultValidateKeys,
 		TraceLoggingEnabled: DefaultTraceLoggingEnabled,
 
 		WAL:    tsm1.NewWALConfig(),
 		Engine: tsm1.NewConfig(),
 		Index:  tsi1.NewConfig(),
+
+		LastValueCache: LastValueCacheConfig{
+			MaxMemorySize: toml.Size(DefaultLastValueCacheMaxMemorySize),
+		},
 	}
 }
 
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/influxdata/influxql"
+	"github.com/influxdata/platform"
 	"github.com/influxdata/platform/logger"
 	"github.com/influxdata/platform/models"
 	"github.com/influxdata/platform/tsdb"
@@ -25,6 +26,18 @@ var timeBytes = []byte("time")
 // it's closed.
 var ErrEngineClosed = errors.New("engine is closed")
 
+// OverloadedError is returned by writes that the engine rejects until it has caught
+// up, such as writes to a full cache, which succeed again once the cache has been
+// snapshotted. Such writes should be retried later rather than dropped.
+type OverloadedError struct {
+	Err error
+}
+
+// Error implements the error interface.
+func (e OverloadedError) Error() string {
+	return fmt.Sprintf("engine overloaded: %v", e.Err)
+}
+
 type Engine struct {
 	config   Config
 	path     string
@@ -38,6 +51,11 @@ type Engine struct {
 	engine            *tsm1.Engine
 	wal               *tsm1.WAL
 	retentionEnforcer *retentionEnforcer
+	bucketPurger      *bucketPurger
+	buckets           *bucketCache    // nil unless the engine has a BucketFinder
+	lastValues        *lastValueCache // nil when the last-value cache is disabled
+	fieldTypes        *fieldTypeRegistry
+	writeMetrics      *writeMetrics
 
 	// Tracks all goroutines started by the Engine.
 	wg sync.WaitGroup
@@ -92,6 +110,23 @@ func WithRetentionEnforcer(finder BucketFinder) Option {
 	}
 }
 
+// WithBucketPurger initialises a bucket purger on the engine, which removes the data
+// of the deleted buckets and organizations recorded by purgeService.
+func WithBucketPurger(purgeService platform.BucketPurgeService) Option {
+	return func(e *Engine) {
+		e.bucketPurger = newBucketPurger(e, purgeService)
+	}
+}
+
+// WithBucketFinder makes the engine read the settings of buckets from finder, such as the
+// duration of the time partitions of their data.
+func WithBucketFinder(finder BucketFinder) Option {
+	return func(e *Engine) {
+		e.buckets = newBucketCache(finder)
+		e.engine.WithPartitionDuration(e.buckets.partitionDuration)
+	}
+}
+
 // WithFileStoreObserver makes the engine have the provided file store observer.
 func WithFileStoreObserver(obs tsm1.FileStoreObserver) Option {
 	return func(e *Engine) {
@@ -110,9 +145,11 @@ func WithCompactionPlanner(planner tsm1.CompactionPlanner) Option {
 // TSM engine.
 func NewEngine(path string, c Config, options ...Option) *Engine {
 	e := &Engine{
-		config: c,
-		path:   path,
-		logger: zap.NewNop(),
+		config:       c,
+		path:         path,
+		fieldTypes:   newFieldTypeRegistry(),
+		writeMetrics: newWriteMetrics(),
+		logger:       zap.NewNop(),
 	}
 
 	// Initialize series file.
@@ -160,6 +197,7 @@ func (e *Engine) WithLogger(log *zap.Logger) {
 	e.index.WithLogger(e.logger)
 	e.engine.WithLogger(e.logger)
 	e.retentionEnforcer.WithLogger(e.logger)
+	e.bucketPurger.WithLogger(e.logger)
 }
 
 // PrometheusCollectors returns all the prometheus collectors associated with
@@ -169,7 +207,13 @@ func (e *Engine) PrometheusCollectors() []prometheus.Collector {
 	// TODO(edd): Get prom metrics for TSM.
 	// TODO(edd): Get prom metrics for index.
 	// TODO(edd): Get prom metrics for series file.
-	metrics = append(metrics, e.retentionEnforcer.PrometheusCollectors()...)
+	metrics = append(metrics, e.writeMetrics.PrometheusCollectors()...)
+	if e.retentionEnforcer != nil {
+		metrics = append(metrics, e.retentionEnforcer.PrometheusCollectors()...)
+	}
+	if e.bucketPurger != nil {
+		metrics = append(metrics, e.bucketPurger.PrometheusCollectors()...)
+	}
 	return metrics
 }
 
@@ -191,16 +235,37 @@ func (e *Engine) Open() error {
 		return err
 	}
 
+	// The partition durations of buckets are read before any snapshot may be written.
+	if e.buckets != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), engineAPITimeout)
+		err := e.buckets.refresh(ctx)
+		cancel()
+		if err != nil {
+			e.logger.Error("Failed to read buckets", zap.Error(err))
+		}
+	}
+
 	if err := e.engine.Open(); err != nil {
 		return err
 	}
 	e.engine.SetCompactionsEnabled(true) // TODO(edd):is this needed?
 
+	if err := e.fieldTypes.load(e.engine); err != nil {
+		return err
+	}
+
+	if e.config.LastValueCache.Enabled {
+		e.lastValues = newLastValueCache(uint64(e.config.LastValueCache.MaxMemorySize))
+	}
+
 	e.closing = make(chan struct{})
 	// TODO(edd) background tasks will be run in priority order via a scheduler.
 	// For now we will just run on an interval as we only have the retention
 	// policy enforcer.
 	e.runRetentionEnforcer()
+	e.runBucketPurger()
+	e.runBucketRefresher()
+	e.runLastValueLoader()
 
 	return nil
 }
@@ -242,6 +307,126 @@ func (e *Engine) runRetentionEnforcer() {
 	}()
 }
 
+// runBucketPurger runs the bucket purger in a separate goroutine.
+//
+// Any purges left over from before the engine was opened are resumed immediately,
+// and newly deleted buckets and organizations are purged on an interval.
+func (e *Engine) runBucketPurger() {
+	if e.bucketPurger == nil {
+		return
+	}
+
+	interval := time.Duration(e.config.PurgeInterval)
+	if interval == 0 {
+		e.logger.Info("Bucket purger disabled")
+		return // Purger disabled.
+	} else if interval < 0 {
+		e.logger.Error("Negative purge interval", logger.DurationLiteral("check_interval", interval))
+		return
+	}
+
+	l := e.logger.With(zap.String("component", "bucket_purger"), logger.DurationLiteral("check_interval", interval))
+	l.Info("Starting")
+
+	// A purge in progress is interrupted when the engine is closed, and resumed when it is
+	// next opened.
+	ctx, cancel := context.WithCancel(context.Background())
+	ticker := time.NewTicker(interval)
+	e.wg.Add(2)
+	go func() {
+		defer e.wg.Done()
+		// It's safe to read closing without a lock because it's never
+		// modified if this goroutine is active.
+		<-e.closing
+		cancel()
+	}()
+	go func() {
+		defer e.wg.Done()
+		defer ticker.Stop()
+
+		e.bucketPurger.run(ctx)
+		for {
+			select {
+			case <-ctx.Done():
+				l.Info("Stopping")
+				return
+			case <-ticker.C:
+				e.bucketPurger.run(ctx)
+			}
+		}
+	}()
+}
+
+// runBucketRefresher reads the settings of buckets again on an interval, in a separate goroutine.
+func (e *Engine) runBucketRefresher() {
+	if e.buckets == nil {
+		return
+	}
+
+	l := e.logger.With(zap.String("component", "bucket_cache"), logger.DurationLiteral("check_interval", bucketRefreshInterval))
+
+	ticker := time.NewTicker(bucketRefreshInterval)
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		defer ticker.Stop()
+
+		for {
+			// It's safe to read closing without a lock because it's never
+			// modified if this goroutine is active.
+			select {
+			case <-e.closing:
+				return
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), engineAPITimeout)
+				if err := e.buckets.refresh(ctx); err != nil {
+					l.Info("Failed to read buckets", zap.Error(err))
+				}
+				cancel()
+			}
+		}
+	}()
+}
+
+// runLastValueLoader loads the last-value cache in a separate goroutine, so that opening the
+// engine does not wait for the last value of every series to be read. Until it is done, the
+// last values of series are read into the cache by the reads that miss it.
+func (e *Engine) runLastValueLoader() {
+	if e.lastValues == nil {
+		return
+	}
+
+	l := e.logger.With(zap.String("component", "last_value_cache"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	e.wg.Add(2)
+	go func() {
+		defer e.wg.Done()
+		// It's safe to read closing without a lock because it's never
+		// modified if this goroutine is active.
+		select {
+		case <-e.closing:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	go func() {
+		defer e.wg.Done()
+		defer cancel()
+
+		start := time.Now()
+		itr, err := e.engine.CreateCursorIterator(ctx)
+		if err == nil {
+			err = e.lastValues.load(ctx, itr, e.index)
+		}
+		if err != nil && ctx.Err() == nil {
+			l.Error("Failed to load last values", zap.Error(err))
+			return
+		}
+		l.Info("Loaded last values", logger.DurationLiteral("duration", time.Since(start)))
+	}()
+}
+
 // Close closes the store and all underlying resources. It returns an error if
 // any of the underlying systems fail to close.
 func (e *Engine) Close() error {
@@ -289,45 +474,77 @@ func (e *Engine) CreateCursorIterator(ctx context.Context) (tsdb.CursorIterator,
 	return e.engine.CreateCursorIterator(ctx)
 }
 
+// CreateLastCursorIterator returns a CursorIterator for reads of the last value of each
+// series. When the last value of a series between the start and end time of a request is
+// in the last-value cache, the cursor returns only that value; otherwise it returns every
+// value of the series, as the cursors of CreateCursorIterator do.
+func (e *Engine) CreateLastCursorIterator(ctx context.Context) (tsdb.CursorIterator, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return nil, ErrEngineClosed
+	}
+
+	itr, err := e.engine.CreateCursorIterator(ctx)
+	if err != nil || e.lastValues == nil {
+		return itr, err
+	}
+	return &lastValueCursorIterator{cache: e.lastValues, itr: itr}, nil
+}
+
 // WritePoints writes the provided points to the engine.
 //
 // The Engine expects all points to have been correctly validated by the caller.
 // WritePoints will however determine if there are any field type conflicts, and
-// return an appropriate error in that case.
+// return an appropriate error in that case. Points that do not match the explicit
+// schema of their bucket are dropped as well, once the engine has read the schema
+// from its BucketFinder.
 func (e *Engine) WritePoints(points []models.Point) error {
 	collection := tsdb.NewSeriesCollection(points)
+	var (
+		conflicts  map[int]tsdb.FieldTypeConflictError
+		mismatches map[int]tsdb.SchemaMismatchError
+		reserved   []fieldTypeKey
+	)
+	defer func() { e.fieldTypes.release(reserved) }()
 
 	j := 0
 	for iter := collection.Iterator(); iter.Next(); {
 		tags := iter.Tags()
 
-		if tags.Len() > 0 && bytes.Equal(tags[0].Key, tsdb.FieldKeyTagKeyBytes) && bytes.Equal(tags[0].Value, timeBytes) {
-			// Field key "time" is invalid
+		if reason := e.invalidKey(iter.Name(), tags, iter.Key()); reason != "" {
 			if collection.Reason == "" {
-				collection.Reason = fmt.Sprintf("invalid field key: input field %q is invalid", timeBytes)
+				collection.Reason = reason
 			}
 			collection.Dropped++
 			collection.DroppedKeys = append(collection.DroppedKeys, iter.Key())
 			continue
 		}
 
-		// Filter out any tags with key equal to "time": they are invalid.
-		if tags.Get(timeBytes) != nil {
-			if collection.Reason == "" {
-				collection.Reason = fmt.Sprintf("invalid tag key: input tag %q on measurement %q is invalid", timeBytes, iter.Name())
+		measurement, field := tags.Get(tsdb.MeasurementTagKeyBytes), tags.Get(tsdb.FieldKeyTagKeyBytes)
+		if mismatch, ok := e.schemaMismatch(iter.Name(), measurement, field, iter.Type()); ok {
+			if mismatches == nil {
+				mismatches = make(map[int]tsdb.SchemaMismatchError)
 			}
-			collection.Dropped++
-			collection.DroppedKeys = append(collection.DroppedKeys, iter.Key())
+			mismatches[iter.Index()] = mismatch
 			continue
 		}
 
-		// Drop any series with invalid unicode characters in the key.
-		if e.config.ValidateKeys && !models.ValidKeyTokens(string(iter.Name()), tags) {
-			if collection.Reason == "" {
-				collection.Reason = fmt.Sprintf("key contains invalid unicode: %q", iter.Key())
+		// Drop any point whose field has another type than the field of its measurement.
+		typ, key := e.fieldTypes.reserve(iter.Name(), measurement, field, iter.Type())
+		if key != nil {
+			reserved = append(reserved, *key)
+		}
+		if typ != iter.Type() {
+			if conflicts == nil {
+				conflicts = make(map[int]tsdb.FieldTypeConflictError)
+			}
+			conflicts[iter.Index()] = tsdb.FieldTypeConflictError{
+				Measurement:  string(measurement),
+				Field:        string(field),
+				Type:         SchemaFieldType(iter.Type()),
+				ExistingType: SchemaFieldType(typ),
 			}
-			collection.Dropped++
-			collection.DroppedKeys = append(collection.DroppedKeys, iter.Key())
 			continue
 		}
 
@@ -340,6 +557,7 @@ func (e *Engine) WritePoints(points []models.Point) error {
 	defer e.mu.RUnlock()
 
 	if e.closing == nil {
+		e.writeMetrics.rejected("closed", len(points))
 		return ErrEngineClosed
 	}
 
@@ -352,11 +570,95 @@ func (e *Engine) WritePoints(points []models.Point) error {
 		}
 	}
 
+	values, err := tsm1.PointsToValues(collection.Points)
+	if err != nil {
+		return err
+	}
+
 	// Write the points to the cache and WAL.
-	if err := e.engine.WritePoints(collection.Points); err != nil {
+	if err := e.engine.WriteValues(values); err != nil {
+		if _, ok := err.(tsm1.CacheFullError); ok {
+			e.writeMetrics.rejected("cache_full", len(points))
+			return OverloadedError{Err: err}
+		}
+		return err
+	}
+	if e.lastValues != nil {
+		e.lastValues.write(values)
+	}
+
+	// The types of the fields of the points written are added before their reservations
+	// are released.
+	for iter := collection.Iterator(); iter.Next(); {
+		tags := iter.Tags()
+		e.fieldTypes.commit(iter.Name(), tags.Get(tsdb.MeasurementTagKeyBytes), tags.Get(tsdb.FieldKeyTagKeyBytes), iter.Type())
+	}
+
+	err = collection.PartialWriteError()
+	if len(conflicts) == 0 && len(mismatches) == 0 {
 		return err
 	}
-	return collection.PartialWriteError()
+	pwe, ok := err.(tsdb.PartialWriteError)
+	if !ok {
+		pwe.Reason = firstDropReason(len(points), conflicts, mismatches)
+	}
+	pwe.Dropped += len(conflicts) + len(mismatches)
+	pwe.Conflicts = conflicts
+	pwe.SchemaMismatches = mismatches
+	return pwe
+}
+
+// schemaMismatch returns why a point whose field of measurement of the bucket name has
+// type typ does not match the explicit schema of the bucket, and false if it does or the
+// bucket has no explicit schema.
+func (e *Engine) schemaMismatch(name, measurement, field []byte, typ models.FieldType) (tsdb.SchemaMismatchError, bool) {
+	if e.buckets == nil {
+		return tsdb.SchemaMismatchError{}, false
+	}
+	schema := e.buckets.schema(name)
+	if schema == nil {
+		return tsdb.SchemaMismatchError{}, false
+	}
+	return schema.check(measurement, field, typ)
+}
+
+// firstDropReason returns the reason of the first of n points dropped for a field type
+// conflict or schema mismatch.
+func firstDropReason(n int, conflicts map[int]tsdb.FieldTypeConflictError, mismatches map[int]tsdb.SchemaMismatchError) string {
+	first, reason := n, ""
+	for i, c := range conflicts {
+		if i < first {
+			first, reason = i, c.Error()
+		}
+	}
+	for i, m := range mismatches {
+		if i < first {
+			first, reason = i, m.Error()
+		}
+	}
+	return reason
+}
+
+// invalidKey returns why the series key of an exploded point with the name and tags
+// cannot be written, or an empty string if it can.
+func (e *Engine) invalidKey(name []byte, tags models.Tags, key []byte) string {
+	// Field key "time" is invalid
+	if tags.Len() > 0 && bytes.Equal(tags[0].Key, tsdb.FieldKeyTagKeyBytes) && bytes.Equal(tags[0].Value, timeBytes) {
+		return fmt.Sprintf("invalid field key: input field %q is invalid", timeBytes)
+	}
+
+	// Tags with key equal to "time" are invalid.
+	if tags.Get(timeBytes) != nil {
+		return fmt.Sprintf("invalid tag key: input tag %q on measurement %q is invalid", timeBytes, name)
+	}
+
+	// Series with invalid unicode characters in the key are invalid. The name is the
+	// encoded organization and bucket IDs, whose bytes are not text, so the measurement
+	// tag is validated in its place.
+	if e.config.ValidateKeys && !models.ValidKeyTokens(string(tags.Get(tsdb.MeasurementTagKeyBytes)), tags) {
+		return fmt.Sprintf("key contains invalid unicode: %q", key)
+	}
+	return ""
 }
 
 // DeleteSeriesRangeWithPredicate deletes all series data iterated over if fn returns
@@ -367,7 +669,78 @@ func (e *Engine) DeleteSeriesRangeWithPredicate(itr tsdb.SeriesIterator, fn func
 	if e.closing == nil {
 		return ErrEngineClosed
 	}
-	return e.engine.DeleteSeriesRangeWithPredicate(itr, fn)
+
+	// The field types of the buckets with deleted data are read again afterwards, as
+	// fields may no longer have any data.
+	deleted := make(deletedRanges)
+	pred := fn
+	fn = func(name []byte, tags models.Tags) (int64, int64, bool) {
+		min, max, ok := pred(name, tags)
+		if ok {
+			deleted.add(name, min, max)
+		}
+		return min, max, ok
+	}
+	err := e.engine.DeleteSeriesRangeWithPredicate(itr, fn)
+	if lerr := e.afterDelete(deleted); err == nil {
+		err = lerr
+	}
+	return err
+}
+
+// DeletePartitionsWithPredicate removes the time partitions of the engine for which fn
+// returns true for the name of every measurement with data in the partition.
+func (e *Engine) DeletePartitionsWithPredicate(fn func(name []byte, min, max int64) bool) error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return ErrEngineClosed
+	}
+
+	deleted := make(deletedRanges)
+	pred := fn
+	fn = func(name []byte, min, max int64) bool {
+		ok := pred(name, min, max)
+		if ok {
+			deleted.add(name, min, max)
+		}
+		return ok
+	}
+	err := e.engine.DeletePartitionsWithPredicate(fn)
+	if lerr := e.afterDelete(deleted); err == nil {
+		err = lerr
+	}
+	return err
+}
+
+// deletedRanges are the ranges of time that may have been deleted from the series of each
+// bucket, widened to cover every range deleted from any of its series.
+type deletedRanges map[string][2]int64
+
+func (d deletedRanges) add(name []byte, min, max int64) {
+	if r, ok := d[string(name)]; ok {
+		if r[0] < min {
+			min = r[0]
+		}
+		if r[1] > max {
+			max = r[1]
+		}
+	}
+	d[string(name)] = [2]int64{min, max}
+}
+
+// afterDelete updates the last-value cache and field types of the buckets with deleted data,
+// once the data is deleted so that they cannot be read again from the data being deleted.
+func (e *Engine) afterDelete(deleted deletedRanges) error {
+	for name, r := range deleted {
+		if e.lastValues != nil {
+			e.lastValues.deleteRange([]byte(name), r[0], r[1])
+		}
+		if err := e.fieldTypes.loadBucket(e.engine, []byte(name)); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // SeriesCardinality returns the number of series in the engine.
@@ -1,15 +1,21 @@
 package storage_test
 
 import (
+	"context"
 	"io/ioutil"
 	"os"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/influxdata/platform"
+	"github.com/influxdata/platform/kit/prom"
+	"github.com/influxdata/platform/kit/prom/promtest"
 	"github.com/influxdata/platform/models"
 	"github.com/influxdata/platform/storage"
+	"github.com/influxdata/platform/toml"
 	"github.com/influxdata/platform/tsdb"
+	"github.com/influxdata/platform/tsdb/tsm1"
 )
 
 func TestEngine_WriteAndIndex(t *testing.T) {
@@ -115,6 +121,79 @@ func TestWrite_TimeField(t *testing.T) {
 	}
 }
 
+func TestEngine_CreateSeriesCursor_MeasurementPrefix(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+
+	for _, ids := range [][2]platform.ID{{1, 10}, {1, 11}, {2, 20}} {
+		points, err := tsdb.ExplodePoints(ids[0], ids[1], []models.Point{
+			models.MustNewPoint("cpu", models.NewTags(map[string]string{"host": "a"}), map[string]interface{}{"value": 1.0}, time.Unix(1, 2)),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := engine.Engine.WritePoints(points); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	org := tsdb.EncodeName(1, 0)
+	cur, err := engine.CreateSeriesCursor(context.Background(), storage.SeriesCursorRequest{MeasurementPrefix: org[:platform.IDLength/2]}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cur.Close()
+
+	names := map[string]bool{}
+	for {
+		row, err := cur.Next()
+		if err != nil {
+			t.Fatal(err)
+		} else if row == nil {
+			break
+		}
+		names[string(row.Name)] = true
+	}
+
+	b10, b11 := tsdb.EncodeName(1, 10), tsdb.EncodeName(1, 11)
+	if len(names) != 2 || !names[string(b10[:])] || !names[string(b11[:])] {
+		t.Fatalf("got series of measurements %v, expected only the buckets of organization 1", names)
+	}
+}
+
+func TestEngine_ValidateKeys(t *testing.T) {
+	config := storage.NewConfig()
+	config.ValidateKeys = true
+	engine := NewEngine(config)
+	defer engine.Close()
+	engine.MustOpen()
+
+	// The encoded IDs of the organization and bucket hold bytes that are not valid in
+	// keys, which must not drop the points of the bucket.
+	org, bucket := platform.ID(1), platform.ID(0xff)
+	points, err := tsdb.ExplodePoints(org, bucket, []models.Point{
+		models.MustNewPoint("cpu", models.NewTags(map[string]string{"host": "a"}), map[string]interface{}{"value": 1.0}, time.Unix(1, 2)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.Engine.WritePoints(points); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	points, err = tsdb.ExplodePoints(org, bucket, []models.Point{
+		models.MustNewPoint("cpu", models.NewTags(map[string]string{"host": "\xff"}), map[string]interface{}{"value": 1.0}, time.Unix(1, 2)),
+		models.MustNewPoint("m\xff", models.NewTags(map[string]string{"host": "a"}), map[string]interface{}{"value": 1.0}, time.Unix(1, 2)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err, ok := engine.Engine.WritePoints(points).(tsdb.PartialWriteError); !ok || err.Dropped != 2 {
+		t.Fatalf("got error %v, expected both points with invalid keys dropped", err)
+	}
+}
+
 func TestEngine_WriteAddNewField(t *testing.T) {
 	engine := NewDefaultEngine()
 	defer engine.Close()
@@ -201,16 +280,120 @@ func TestEngine_WALDisabled(t *testing.T) {
 	}
 }
 
+func TestEngine_CacheFull(t *testing.T) {
+	config := storage.NewConfig()
+	config.Engine.Cache.MaxMemorySize = toml.Size(1)
+
+	engine := NewEngine(config)
+	defer engine.Close()
+	engine.MustOpen()
+	reg := prom.NewRegistry()
+	reg.MustRegister(engine.PrometheusCollectors()...)
+
+	pt := models.MustNewPoint(
+		"cpu",
+		models.NewTags(map[string]string{"host": "server"}),
+		map[string]interface{}{"value": 1.0},
+		time.Unix(1, 2),
+	)
+
+	err := engine.Write1xPoints([]models.Point{pt})
+	if _, ok := err.(storage.OverloadedError); !ok {
+		t.Fatalf("got error %v, want an OverloadedError", err)
+	}
+	if _, ok := err.(storage.OverloadedError).Err.(tsm1.CacheFullError); !ok {
+		t.Errorf("got error %v, want a CacheFullError", err)
+	}
+
+	mfs := promtest.MustGather(t, reg)
+	m := promtest.MustFindMetric(t, mfs, "storage_writer_rejected_points_total", map[string]string{"reason": "cache_full"})
+	if got := m.GetCounter().GetValue(); got != 1 {
+		t.Errorf("got %v rejected points, want 1", got)
+	}
+}
+
+func TestEngine_BucketPurger(t *testing.T) {
+	org, bucket, other := platform.ID(1), platform.ID(2), platform.ID(3)
+	purges := &bucketPurgeService{}
+
+	c := storage.NewConfig()
+	c.PurgeInterval = toml.Duration(10 * time.Millisecond)
+	engine := NewEngine(c, storage.WithBucketPurger(purges))
+	defer engine.Close()
+	engine.MustOpen()
+
+	pt := models.MustNewPoint("cpu", models.NewTags(map[string]string{"host": "a"}), map[string]interface{}{"value": 1.0}, time.Unix(1, 0))
+	for _, b := range []platform.ID{bucket, other} {
+		points, err := tsdb.ExplodePoints(org, b, []models.Point{pt})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := engine.Engine.WritePoints(points); err != nil {
+			t.Fatal(err)
+		}
+	}
+	purges.add(&platform.BucketPurge{OrganizationID: org, BucketID: bucket})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for purges.pending() > 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the purge to complete")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Only the series of the other bucket remains in the index.
+	if got, exp := engine.SeriesCardinality(), int64(1); got != exp {
+		t.Fatalf("got %v series, exp %v series in index", got, exp)
+	}
+}
+
+type bucketPurgeService struct {
+	mu     sync.Mutex
+	purges []*platform.BucketPurge
+}
+
+func (s *bucketPurgeService) add(p *platform.BucketPurge) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.purges = append(s.purges, p)
+}
+
+func (s *bucketPurgeService) pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.purges)
+}
+
+func (s *bucketPurgeService) FindBucketPurges(ctx context.Context) ([]*platform.BucketPurge, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*platform.BucketPurge(nil), s.purges...), nil
+}
+
+func (s *bucketPurgeService) DeleteBucketPurge(ctx context.Context, orgID, bucketID platform.ID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var kept []*platform.BucketPurge
+	for _, p := range s.purges {
+		if p.OrganizationID != orgID || p.BucketID != bucketID {
+			kept = append(kept, p)
+		}
+	}
+	s.purges = kept
+	return nil
+}
+
 type Engine struct {
 	path string
 	*storage.Engine
 }
 
 // NewEngine create a new wrapper around a storage engine.
-func NewEngine(c storage.Config) *Engine {
+func NewEngine(c storage.Config, options ...storage.Option) *Engine {
 	path, _ := ioutil.TempDir("", "storage_engine_test")
 
-	engine := storage.NewEngine(path, c)
+	engine := storage.NewEngine(path, c, options...)
 	return &Engine{
 		path:   path,
 		Engine: engine,
@@ -0,0 +1,278 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/influxdata/influxql"
+	"github.com/influxdata/platform"
+	"github.com/influxdata/platform/models"
+	"github.com/influxdata/platform/tsdb"
+	"github.com/influxdata/platform/tsdb/tsm1"
+)
+
+// errWalkDone stops a walk of the keys of the engine.
+var errWalkDone = errors.New("walk done")
+
+// FindBucketSchema returns the type of each field of each measurement of the data of the
+// bucket. The schema type of the bucket is not known to the engine, and is left empty.
+func (e *Engine) FindBucketSchema(ctx context.Context, orgID, bucketID platform.ID) (*platform.BucketSchema, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return nil, ErrEngineClosed
+	}
+
+	name := tsdb.EncodeName(orgID, bucketID)
+	return &platform.BucketSchema{
+		BucketID:     bucketID,
+		Measurements: e.fieldTypes.schema(name[:]),
+	}, nil
+}
+
+// SchemaFieldType returns the name of a field type in a bucket schema.
+func SchemaFieldType(typ models.FieldType) string {
+	switch typ {
+	case models.Float:
+		return platform.FieldTypeFloat
+	case models.Integer:
+		return platform.FieldTypeInteger
+	case models.Unsigned:
+		return platform.FieldTypeUnsigned
+	case models.String:
+		return platform.FieldTypeString
+	case models.Boolean:
+		return platform.FieldTypeBoolean
+	default:
+		return ""
+	}
+}
+
+// fieldTypeRegistry is the type of each field of each measurement of each bucket. It is
+// built from the keys of the engine when the engine is opened, and updated as points are
+// written, so that a point whose field has another type than the field of its measurement
+// is rejected before it is written.
+type fieldTypeRegistry struct {
+	mu sync.RWMutex
+	// buckets are the field types by bucket name, measurement and field.
+	buckets map[string]measurementFieldTypes
+	// pending are the types of the fields without a type that are being written, which
+	// are only added to buckets once a point of the field is written.
+	pending map[fieldTypeKey]*pendingFieldType
+}
+
+type measurementFieldTypes map[string]map[string]models.FieldType
+
+// fieldTypeKey is a field of a measurement of a bucket.
+type fieldTypeKey struct {
+	name, measurement, field string
+}
+
+// pendingFieldType is the type of a field reserved by the writes of points of the field.
+type pendingFieldType struct {
+	typ    models.FieldType
+	writes int
+}
+
+func newFieldTypeRegistry() *fieldTypeRegistry {
+	return &fieldTypeRegistry{
+		buckets: make(map[string]measurementFieldTypes),
+		pending: make(map[fieldTypeKey]*pendingFieldType),
+	}
+}
+
+// load replaces the field types of every bucket with those of the keys of engine.
+func (r *fieldTypeRegistry) load(engine *tsm1.Engine) error {
+	buckets, err := readFieldTypes(engine, nil)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.buckets = buckets
+	r.mu.Unlock()
+	return nil
+}
+
+// loadBucket replaces the field types of the bucket name with those of the keys of engine,
+// after some of its data is deleted. The registry is locked while the keys are read, so
+// that the types of the points written meanwhile are not lost.
+func (r *fieldTypeRegistry) loadBucket(engine *tsm1.Engine, name []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Every key of the bucket starts with its escaped name, followed by its tags.
+	buckets, err := readFieldTypes(engine, append(models.MakeKey(name, nil), ','))
+	if err != nil {
+		return err
+	}
+
+	if b, ok := buckets[string(name)]; ok {
+		r.buckets[string(name)] = b
+	} else {
+		delete(r.buckets, string(name))
+	}
+	return nil
+}
+
+// readFieldTypes returns the field types of the keys of engine that start with prefix.
+func readFieldTypes(engine *tsm1.Engine, prefix []byte) (map[string]measurementFieldTypes, error) {
+	buckets := make(map[string]measurementFieldTypes)
+	add := func(key []byte, typ models.FieldType) {
+		seriesKey, field := tsm1.SeriesAndFieldFromCompositeKey(key)
+		name, tags := models.ParseKeyBytes(seriesKey)
+		setFieldType(buckets, name, tags.Get(tsdb.MeasurementTagKeyBytes), field, typ)
+	}
+
+	err := engine.FileStore.WalkKeys(prefix, func(key []byte, typ byte) error {
+		if !bytes.HasPrefix(key, prefix) {
+			return errWalkDone
+		}
+		if t, ok := influxQLFieldType(tsm1.BlockTypeToInfluxQLDataType(typ)); ok {
+			add(key, t)
+		}
+		return nil
+	})
+	if err != nil && err != errWalkDone {
+		return nil, err
+	}
+
+	for _, key := range engine.Cache.Keys() {
+		if !bytes.HasPrefix(key, prefix) {
+			continue
+		}
+		if typ, err := engine.Cache.Type(key); err == nil {
+			add(key, typ)
+		}
+	}
+	return buckets, nil
+}
+
+// reserve returns the type of the field of the measurement of the bucket name. If the
+// field has no type, typ is reserved as its type until the write of the point is done,
+// and the field is returned so that the write releases it; the type is added by commit
+// once a point of the field is written.
+func (r *fieldTypeRegistry) reserve(name, measurement, field []byte, typ models.FieldType) (models.FieldType, *fieldTypeKey) {
+	r.mu.RLock()
+	existing, ok := r.buckets[string(name)][string(measurement)][string(field)]
+	r.mu.RUnlock()
+	if ok {
+		return existing, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if existing, ok := r.buckets[string(name)][string(measurement)][string(field)]; ok {
+		return existing, nil
+	}
+	key := fieldTypeKey{name: string(name), measurement: string(measurement), field: string(field)}
+	p, ok := r.pending[key]
+	if !ok {
+		p = &pendingFieldType{typ: typ}
+		r.pending[key] = p
+	} else if p.typ != typ {
+		return p.typ, nil
+	}
+	p.writes++
+	return typ, &key
+}
+
+// commit adds the type of the field of the measurement of the bucket name, of a point
+// that was written, unless the field already has a type.
+func (r *fieldTypeRegistry) commit(name, measurement, field []byte, typ models.FieldType) {
+	r.mu.RLock()
+	_, ok := r.buckets[string(name)][string(measurement)][string(field)]
+	r.mu.RUnlock()
+	if ok {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.buckets[string(name)][string(measurement)][string(field)]; !ok {
+		setFieldType(r.buckets, name, measurement, field, typ)
+	}
+}
+
+// release releases the types reserved for the fields by a write once it is done. The type
+// of a field is forgotten once no write reserves it, unless a point was committed.
+func (r *fieldTypeRegistry) release(keys []fieldTypeKey) {
+	if len(keys) == 0 {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, key := range keys {
+		if p, ok := r.pending[key]; ok {
+			if p.writes--; p.writes == 0 {
+				delete(r.pending, key)
+			}
+		}
+	}
+}
+
+// fieldType returns the type of the field of the measurement of the bucket name, and
+// whether the measurement has any field.
+func (r *fieldTypeRegistry) fieldType(name, measurement, field []byte) (typ models.FieldType, ok, measurementOK bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	fields, measurementOK := r.buckets[string(name)][string(measurement)]
+	typ, ok = fields[string(field)]
+	return typ, ok, measurementOK
+}
+
+// schema returns the type of each field of each measurement of the bucket name.
+func (r *fieldTypeRegistry) schema(name []byte) []platform.MeasurementSchema {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	measurements := make([]platform.MeasurementSchema, 0, len(r.buckets[string(name)]))
+	for m, fields := range r.buckets[string(name)] {
+		ms := platform.MeasurementSchema{
+			Name:   m,
+			Fields: make([]platform.FieldSchema, 0, len(fields)),
+		}
+		for f, typ := range fields {
+			ms.Fields = append(ms.Fields, platform.FieldSchema{Name: f, Type: SchemaFieldType(typ)})
+		}
+		sort.Slice(ms.Fields, func(i, j int) bool { return ms.Fields[i].Name < ms.Fields[j].Name })
+		measurements = append(measurements, ms)
+	}
+	sort.Slice(measurements, func(i, j int) bool { return measurements[i].Name < measurements[j].Name })
+	return measurements
+}
+
+func setFieldType(buckets map[string]measurementFieldTypes, name, measurement, field []byte, typ models.FieldType) {
+	b, ok := buckets[string(name)]
+	if !ok {
+		b = make(measurementFieldTypes)
+		buckets[string(name)] = b
+	}
+	fields, ok := b[string(measurement)]
+	if !ok {
+		fields = make(map[string]models.FieldType)
+		b[string(measurement)] = fields
+	}
+	fields[string(field)] = typ
+}
+
+func influxQLFieldType(typ influxql.DataType) (models.FieldType, bool) {
+	switch typ {
+	case influxql.Float:
+		return models.Float, true
+	case influxql.Integer:
+		return models.Integer, true
+	case influxql.Unsigned:
+		return models.Unsigned, true
+	case influxql.String:
+		return models.String, true
+	case influxql.Boolean:
+		return models.Boolean, true
+	default:
+		return models.Empty, false
+	}
+}
@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/influxdata/platform/models"
+)
+
+func TestFieldTypeRegistry_reserve(t *testing.T) {
+	r := newFieldTypeRegistry()
+	name, measurement, field := []byte("bucket"), []byte("cpu"), []byte("usage")
+
+	// A field reserved by a write conflicts with other types until the write is done.
+	typ, key := r.reserve(name, measurement, field, models.Float)
+	if typ != models.Float || key == nil {
+		t.Fatalf("got type %v and reservation %v, expected a reserved float", typ, key)
+	}
+	if typ, other := r.reserve(name, measurement, field, models.Integer); typ != models.Float || other != nil {
+		t.Fatalf("got type %v and reservation %v of a reserved float", typ, other)
+	}
+
+	// The type of a write that failed is forgotten.
+	r.release([]fieldTypeKey{*key})
+	if _, ok, _ := r.fieldType(name, measurement, field); ok {
+		t.Fatal("expected no type of a field whose write failed")
+	}
+	if len(r.pending) != 0 {
+		t.Fatalf("got %d reservations after the write is done", len(r.pending))
+	}
+
+	// The type of a written point is kept.
+	typ, key = r.reserve(name, measurement, field, models.Integer)
+	if typ != models.Integer || key == nil {
+		t.Fatalf("got type %v and reservation %v, expected a reserved integer", typ, key)
+	}
+	r.commit(name, measurement, field, models.Integer)
+	r.release([]fieldTypeKey{*key})
+	if typ, ok, _ := r.fieldType(name, measurement, field); !ok || typ != models.Integer {
+		t.Fatalf("got type %v, %v of a written field, expected integer", typ, ok)
+	}
+}
@@ -0,0 +1,150 @@
+package storage_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/influxdata/platform"
+	"github.com/influxdata/platform/mock"
+	"github.com/influxdata/platform/models"
+	"github.com/influxdata/platform/storage"
+	"github.com/influxdata/platform/tsdb"
+)
+
+func TestEngine_FindBucketSchema(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+
+	org, bucket := platform.ID(1), platform.ID(2)
+	write := func(pts ...models.Point) error {
+		points, err := tsdb.ExplodePoints(org, bucket, pts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return engine.Engine.WritePoints(points)
+	}
+
+	if err := write(
+		models.MustNewPoint("cpu", models.NewTags(map[string]string{"host": "a"}), map[string]interface{}{"usage": 1.0, "cores": int64(4)}, time.Unix(1, 0)),
+		models.MustNewPoint("log", nil, map[string]interface{}{"msg": "started", "ok": true}, time.Unix(1, 0)),
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	// An integer usage conflicts with the float usage of another series of the measurement.
+	err := write(
+		models.MustNewPoint("cpu", models.NewTags(map[string]string{"host": "a"}), map[string]interface{}{"usage": 2.0}, time.Unix(2, 0)),
+		models.MustNewPoint("cpu", models.NewTags(map[string]string{"host": "b"}), map[string]interface{}{"usage": int64(2)}, time.Unix(2, 0)),
+	)
+	pwe, ok := err.(tsdb.PartialWriteError)
+	if !ok {
+		t.Fatalf("got error %v, expected a partial write", err)
+	}
+	expConflicts := map[int]tsdb.FieldTypeConflictError{
+		1: {Measurement: "cpu", Field: "usage", Type: platform.FieldTypeInteger, ExistingType: platform.FieldTypeFloat},
+	}
+	if !reflect.DeepEqual(pwe.Conflicts, expConflicts) || pwe.Dropped != 1 {
+		t.Fatalf("got %d dropped with conflicts %v, expected 1 with %v", pwe.Dropped, pwe.Conflicts, expConflicts)
+	}
+	if exp := `field type conflict: input field "usage" on measurement "cpu" is type integer, already exists as type float`; pwe.Reason != exp {
+		t.Fatalf("got reason %q, expected %q", pwe.Reason, exp)
+	}
+
+	exp := &platform.BucketSchema{
+		BucketID: bucket,
+		Measurements: []platform.MeasurementSchema{
+			{Name: "cpu", Fields: []platform.FieldSchema{
+				{Name: "cores", Type: platform.FieldTypeInteger},
+				{Name: "usage", Type: platform.FieldTypeFloat},
+			}},
+			{Name: "log", Fields: []platform.FieldSchema{
+				{Name: "msg", Type: platform.FieldTypeString},
+				{Name: "ok", Type: platform.FieldTypeBoolean},
+			}},
+		},
+	}
+	check := func(t *testing.T) {
+		schema, err := engine.FindBucketSchema(context.Background(), org, bucket)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(schema, exp) {
+			t.Fatalf("got schema %+v, expected %+v", schema, exp)
+		}
+	}
+	t.Run("written", check)
+
+	// The field types are read from the data of the engine when it is opened again.
+	engine.Engine.Close()
+	engine.MustOpen()
+	t.Run("reopened", check)
+
+	// A bucket without data has no measurements.
+	schema, err := engine.FindBucketSchema(context.Background(), org, platform.ID(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(schema.Measurements) != 0 {
+		t.Fatalf("got measurements %v for an empty bucket", schema.Measurements)
+	}
+}
+
+func TestEngine_WritePoints_ExplicitSchema(t *testing.T) {
+	org, bucket := platform.ID(1), platform.ID(2)
+	finder := mock.NewBucketService()
+	finder.FindBucketsFn = func(context.Context, platform.BucketFilter, ...platform.FindOptions) ([]*platform.Bucket, int, error) {
+		return []*platform.Bucket{{
+			ID:             bucket,
+			OrganizationID: org,
+			SchemaType:     platform.SchemaTypeExplicit,
+			Schema: []platform.MeasurementSchema{
+				{Name: "cpu", Fields: []platform.FieldSchema{{Name: "usage", Type: platform.FieldTypeFloat}}},
+			},
+		}}, 1, nil
+	}
+
+	engine := NewEngine(storage.NewConfig(), storage.WithBucketFinder(finder))
+	defer engine.Close()
+	engine.MustOpen()
+
+	points, err := tsdb.ExplodePoints(org, bucket, []models.Point{
+		models.MustNewPoint("cpu", nil, map[string]interface{}{"usage": 1.0}, time.Unix(1, 0)),
+		models.MustNewPoint("cpu", nil, map[string]interface{}{"usage": int64(2)}, time.Unix(2, 0)),
+		models.MustNewPoint("cpu", nil, map[string]interface{}{"idle": 3.0}, time.Unix(3, 0)),
+		models.MustNewPoint("mem", nil, map[string]interface{}{"free": 4.0}, time.Unix(4, 0)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = engine.Engine.WritePoints(points)
+	pwe, ok := err.(tsdb.PartialWriteError)
+	if !ok {
+		t.Fatalf("got error %v, expected a partial write", err)
+	}
+	exp := map[int]tsdb.SchemaMismatchError{
+		1: {Measurement: "cpu", Field: "usage", Type: platform.FieldTypeInteger, SchemaType: platform.FieldTypeFloat},
+		2: {Measurement: "cpu", Field: "idle", Type: platform.FieldTypeFloat},
+		3: {Measurement: "mem"},
+	}
+	if !reflect.DeepEqual(pwe.SchemaMismatches, exp) || pwe.Dropped != 3 {
+		t.Fatalf("got %d dropped with schema mismatches %v, expected 3 with %v", pwe.Dropped, pwe.SchemaMismatches, exp)
+	}
+	if exp := `input field "usage" on measurement "cpu" is type integer, schema declares type float`; pwe.Reason != exp {
+		t.Fatalf("got reason %q, expected %q", pwe.Reason, exp)
+	}
+
+	schema, err := engine.FindBucketSchema(context.Background(), org, bucket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expSchema := []platform.MeasurementSchema{
+		{Name: "cpu", Fields: []platform.FieldSchema{{Name: "usage", Type: platform.FieldTypeFloat}}},
+	}
+	if !reflect.DeepEqual(schema.Measurements, expSchema) {
+		t.Fatalf("got measurements %+v, expected %+v", schema.Measurements, expSchema)
+	}
+}
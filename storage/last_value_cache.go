@@ -0,0 +1,497 @@
+package storage
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/influxdata/platform/models"
+	"github.com/influxdata/platform/tsdb"
+	"github.com/influxdata/platform/tsdb/cursors"
+	"github.com/influxdata/platform/tsdb/tsi1"
+	"github.com/influxdata/platform/tsdb/tsm1"
+)
+
+// lastValueOverhead approximates the memory used by an entry of the last-value cache,
+// besides its key and value.
+const lastValueOverhead = 128
+
+// errLastValueCacheFull is returned when a value read into the last-value cache does not fit.
+var errLastValueCacheFull = errors.New("last-value cache is full")
+
+// lastValueCache holds the last value of the series of each bucket, so that reads of the
+// latest points do not have to read TSM blocks.
+//
+// The last value of a series is read into the cache by the first read that misses it, and
+// the series of every bucket are loaded in the background once the engine is open, until the
+// cache is full. Writes and deletes keep the values held up to date. Its size is bounded by
+// evicting the least recently used values.
+//
+// A bucket is complete while the cache holds the last value of every one of its series, so
+// that a write of a series the cache does not hold is the first of that series, and is added.
+// A bucket becomes complete once all its series are loaded, and stops being complete when one
+// of its values is evicted or deleted.
+type lastValueCache struct {
+	mu      sync.Mutex
+	maxSize uint64
+	size    uint64
+	buckets map[string]*bucketLastValues
+	lru     *list.List // of *lastValue, most recently used first
+}
+
+// bucketLastValues holds the last values of the series of a bucket.
+type bucketLastValues struct {
+	name   string
+	values map[string]*list.Element
+
+	// complete is true while the last value of every series of the bucket is held.
+	complete bool
+
+	// fills are the reads of last values of series of the bucket in progress, by key.
+	fills map[string]*lastValueFill
+
+	// changes counts the writes of series that are not held and the values removed, so that
+	// a load of the bucket can tell whether it is still complete once it is done.
+	changes uint64
+}
+
+// lastValueFill tracks the reads of the last value of a series into the cache. Writes and
+// deletes of the series increment gen, so that a value read before them is not added.
+type lastValueFill struct {
+	refs int
+	gen  uint64
+}
+
+type lastValue struct {
+	bucket *bucketLastValues
+	key    string
+	value  tsm1.Value
+}
+
+func newLastValueCache(maxSize uint64) *lastValueCache {
+	return &lastValueCache{
+		maxSize: maxSize,
+		buckets: make(map[string]*bucketLastValues),
+		lru:     list.New(),
+	}
+}
+
+// load reads the last value of every series of index into the cache with itr, bucket by
+// bucket, until the cache is full or ctx is done. Each bucket whose series are all loaded
+// becomes complete, unless it was written to or deleted from in the meantime.
+func (c *lastValueCache) load(ctx context.Context, itr tsdb.CursorIterator, index *tsi1.Index) error {
+	mitr, err := index.MeasurementIterator()
+	if err != nil || mitr == nil {
+		return err
+	}
+	defer mitr.Close()
+
+	for {
+		name, err := mitr.Next()
+		if err != nil || name == nil {
+			return err
+		}
+		if err := c.loadBucket(ctx, itr, index, name); err == errLastValueCacheFull {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+// loadBucket reads the last value of every series of the bucket name into the cache.
+func (c *lastValueCache) loadBucket(ctx context.Context, itr tsdb.CursorIterator, index *tsi1.Index, name []byte) error {
+	sitr, err := index.MeasurementSeriesIDIterator(name)
+	if err != nil || sitr == nil {
+		return err
+	}
+	defer sitr.Close()
+
+	c.mu.Lock()
+	b := c.bucketByName(name)
+	changes := b.changes
+	c.mu.Unlock()
+
+	complete := true
+	var key []byte
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		elem, err := sitr.Next()
+		if err != nil {
+			return err
+		} else if elem.SeriesID.IsZero() {
+			break
+		}
+
+		skey := index.SeriesFile().SeriesKey(elem.SeriesID)
+		if len(skey) == 0 {
+			continue
+		}
+		sname, tags := tsdb.ParseSeriesKey(skey)
+		key = models.AppendMakeKey(key[:0], sname, tags)
+		key = tsm1.SeriesFieldKeyBytes(string(key), string(tags.Get(tsdb.FieldKeyTagKeyBytes)))
+
+		v, added, err := c.fill(ctx, itr, key, false)
+		if err != nil {
+			return err
+		} else if v != nil && !added {
+			complete = false
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if complete && c.buckets[b.name] == b && b.changes == changes {
+		b.complete = true
+	}
+	return nil
+}
+
+// fill reads the last value of the series and field key with itr into the cache, unless it is
+// already held, and returns it. The value is not added if the series is written or deleted
+// while it is read, as it may no longer be the last one, and added is false. When evict is
+// true, the least recently used values are evicted to make room for it; otherwise
+// errLastValueCacheFull is returned if it does not fit.
+//
+// The value is read without holding the lock of the cache, so that writes are not held up.
+func (c *lastValueCache) fill(ctx context.Context, itr tsdb.CursorIterator, key []byte, evict bool) (v tsm1.Value, added bool, err error) {
+	c.mu.Lock()
+	b := c.bucket(key)
+	if e, ok := b.values[string(key)]; ok {
+		c.lru.MoveToFront(e)
+		c.mu.Unlock()
+		return e.Value.(*lastValue).value, true, nil
+	}
+	f := b.fills[string(key)]
+	if f == nil {
+		f = &lastValueFill{}
+		b.fills[string(key)] = f
+	}
+	f.refs++
+	gen := f.gen
+	c.mu.Unlock()
+
+	var req cursors.CursorRequest
+	seriesKey, field := tsm1.SeriesAndFieldFromCompositeKey(key)
+	req.Name, req.Tags = models.ParseKeyBytes(seriesKey)
+	req.Field = string(field)
+	v, err = readLastValue(ctx, itr, &req)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	defer c.release(b)
+	if f.refs--; f.refs == 0 {
+		delete(b.fills, string(key))
+	}
+
+	if err != nil || v == nil || f.gen != gen || c.buckets[b.name] != b {
+		return v, false, err
+	}
+	if e, ok := b.values[string(key)]; ok {
+		// Another read added the value first.
+		return e.Value.(*lastValue).value, true, nil
+	}
+	if !evict && c.size+lastValueSize(key, v) > c.maxSize {
+		return v, false, errLastValueCacheFull
+	}
+	c.insert(b, string(key), v)
+	c.evict()
+	_, added = b.values[string(key)]
+	return v, added, nil
+}
+
+// readLastValue returns the last value of the series and field of req, or nil if it has none.
+func readLastValue(ctx context.Context, itr tsdb.CursorIterator, req *cursors.CursorRequest) (tsm1.Value, error) {
+	req.Ascending = false
+	req.StartTime = models.MinNanoTime
+	req.EndTime = models.MaxNanoTime
+
+	cur, err := itr.Next(ctx, req)
+	if err != nil || cur == nil {
+		return nil, err
+	}
+	defer cur.Close()
+
+	switch cur := cur.(type) {
+	case cursors.FloatArrayCursor:
+		if a := cur.Next(); a.Len() > 0 {
+			return tsm1.NewFloatValue(a.Timestamps[0], a.Values[0]), nil
+		}
+	case cursors.IntegerArrayCursor:
+		if a := cur.Next(); a.Len() > 0 {
+			return tsm1.NewIntegerValue(a.Timestamps[0], a.Values[0]), nil
+		}
+	case cursors.UnsignedArrayCursor:
+		if a := cur.Next(); a.Len() > 0 {
+			return tsm1.NewUnsignedValue(a.Timestamps[0], a.Values[0]), nil
+		}
+	case cursors.StringArrayCursor:
+		if a := cur.Next(); a.Len() > 0 {
+			return tsm1.NewStringValue(a.Timestamps[0], a.Values[0]), nil
+		}
+	case cursors.BooleanArrayCursor:
+		if a := cur.Next(); a.Len() > 0 {
+			return tsm1.NewBooleanValue(a.Timestamps[0], a.Values[0]), nil
+		}
+	}
+	return nil, cur.Err()
+}
+
+// write updates the cache with values written to the engine, keyed by series and field key.
+func (c *lastValueCache) write(values map[string][]tsm1.Value) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, vs := range values {
+		if len(vs) == 0 {
+			continue
+		}
+
+		// Of values with the same timestamp, the last one written is kept.
+		last := vs[0]
+		for _, v := range vs[1:] {
+			if v.UnixNano() >= last.UnixNano() {
+				last = v
+			}
+		}
+
+		b, ok := c.buckets[string(models.ParseName([]byte(key)))]
+		if !ok {
+			continue // Nothing of the bucket is held or being read.
+		}
+		if e, ok := b.values[key]; ok {
+			lv := e.Value.(*lastValue)
+			if last.UnixNano() >= lv.value.UnixNano() {
+				c.size += uint64(last.Size())
+				c.size -= uint64(lv.value.Size())
+				lv.value = last
+			}
+			c.lru.MoveToFront(e)
+		} else if b.complete {
+			c.insert(b, key, last)
+		} else {
+			b.changes++
+			if f, ok := b.fills[key]; ok {
+				f.gen++
+			}
+		}
+	}
+	c.evict()
+}
+
+// get returns the last value of the series and field key, if the cache holds it.
+func (c *lastValueCache) get(key []byte) (tsm1.Value, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	b, ok := c.buckets[string(models.ParseName(key))]
+	if !ok {
+		return nil, false
+	}
+	e, ok := b.values[string(key)]
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(e)
+	return e.Value.(*lastValue).value, true
+}
+
+// deleteRange removes the values of the series of the bucket name between min and max, once
+// they are deleted from the engine. The bucket is no longer complete if any value is removed,
+// as the earlier values of its series are unknown.
+func (c *lastValueCache) deleteRange(name []byte, min, max int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	b, ok := c.buckets[string(name)]
+	if !ok {
+		return
+	}
+
+	// Values being read may have been deleted since.
+	b.changes++
+	for _, f := range b.fills {
+		f.gen++
+	}
+
+	for _, e := range b.values {
+		if t := e.Value.(*lastValue).value.UnixNano(); t >= min && t <= max {
+			b.complete = false
+			c.remove(e)
+		}
+	}
+	c.release(b)
+}
+
+// bucket returns the last values of the bucket of the series and field key.
+func (c *lastValueCache) bucket(key []byte) *bucketLastValues {
+	return c.bucketByName(models.ParseName(key))
+}
+
+// bucketByName returns the last values of the bucket name.
+func (c *lastValueCache) bucketByName(name []byte) *bucketLastValues {
+	b, ok := c.buckets[string(name)]
+	if !ok {
+		b = &bucketLastValues{
+			name:   string(name),
+			values: make(map[string]*list.Element),
+			fills:  make(map[string]*lastValueFill),
+		}
+		c.buckets[b.name] = b
+	}
+	return b
+}
+
+// release forgets b once it holds nothing the cache can use.
+func (c *lastValueCache) release(b *bucketLastValues) {
+	if len(b.values) == 0 && len(b.fills) == 0 && !b.complete && c.buckets[b.name] == b {
+		delete(c.buckets, b.name)
+	}
+}
+
+func (c *lastValueCache) insert(b *bucketLastValues, key string, v tsm1.Value) {
+	b.values[key] = c.lru.PushFront(&lastValue{bucket: b, key: key, value: v})
+	c.size += lastValueSize([]byte(key), v)
+}
+
+func (c *lastValueCache) remove(e *list.Element) {
+	lv := c.lru.Remove(e).(*lastValue)
+	delete(lv.bucket.values, lv.key)
+	c.size -= lastValueSize([]byte(lv.key), lv.value)
+}
+
+// evict removes the least recently used values until the cache is no larger than its
+// maximum size.
+func (c *lastValueCache) evict() {
+	for c.size > c.maxSize && c.lru.Len() > 0 {
+		e := c.lru.Back()
+		b := e.Value.(*lastValue).bucket
+		b.complete = false
+		b.changes++
+		c.remove(e)
+		c.release(b)
+	}
+}
+
+func lastValueSize(key []byte, v tsm1.Value) uint64 {
+	return uint64(len(key) + v.Size() + lastValueOverhead)
+}
+
+// lastValueCursorIterator is a CursorIterator whose cursors return only the last value
+// of a series between the start and end time of the request when the cache holds it.
+type lastValueCursorIterator struct {
+	cache *lastValueCache
+	itr   tsdb.CursorIterator
+}
+
+func (q *lastValueCursorIterator) Next(ctx context.Context, r *cursors.CursorRequest) (cursors.Cursor, error) {
+	key := tsm1.SeriesFieldKeyBytes(string(models.MakeKey(r.Name, r.Tags)), r.Field)
+	v, ok := q.cache.get(key)
+	if !ok {
+		var err error
+		if v, ok, err = q.cache.fill(ctx, q.itr, key, true); err != nil {
+			return nil, err
+		}
+	}
+	if !ok || v.UnixNano() > r.EndTime {
+		// The last value between the start and end time is not cached.
+		return q.itr.Next(ctx, r)
+	}
+
+	// As v is the last value of the series, the series has no value after the start time
+	// when v is before it.
+	in := v.UnixNano() >= r.StartTime
+	switch x := v.Value().(type) {
+	case float64:
+		a := cursors.NewFloatArrayLen(0)
+		if in {
+			a.Timestamps, a.Values = []int64{v.UnixNano()}, []float64{x}
+		}
+		return &floatLastValueCursor{a: a}, nil
+	case int64:
+		a := cursors.NewIntegerArrayLen(0)
+		if in {
+			a.Timestamps, a.Values = []int64{v.UnixNano()}, []int64{x}
+		}
+		return &integerLastValueCursor{a: a}, nil
+	case uint64:
+		a := cursors.NewUnsignedArrayLen(0)
+		if in {
+			a.Timestamps, a.Values = []int64{v.UnixNano()}, []uint64{x}
+		}
+		return &unsignedLastValueCursor{a: a}, nil
+	case string:
+		a := cursors.NewStringArrayLen(0)
+		if in {
+			a.Timestamps, a.Values = []int64{v.UnixNano()}, []string{x}
+		}
+		return &stringLastValueCursor{a: a}, nil
+	case bool:
+		a := cursors.NewBooleanArrayLen(0)
+		if in {
+			a.Timestamps, a.Values = []int64{v.UnixNano()}, []bool{x}
+		}
+		return &booleanLastValueCursor{a: a}, nil
+	default:
+		return q.itr.Next(ctx, r)
+	}
+}
+
+type floatLastValueCursor struct{ a *cursors.FloatArray }
+
+func (c *floatLastValueCursor) Close()     {}
+func (c *floatLastValueCursor) Err() error { return nil }
+
+func (c *floatLastValueCursor) Next() *cursors.FloatArray {
+	a := c.a
+	c.a = cursors.NewFloatArrayLen(0)
+	return a
+}
+
+type integerLastValueCursor struct{ a *cursors.IntegerArray }
+
+func (c *integerLastValueCursor) Close()     {}
+func (c *integerLastValueCursor) Err() error { return nil }
+
+func (c *integerLastValueCursor) Next() *cursors.IntegerArray {
+	a := c.a
+	c.a = cursors.NewIntegerArrayLen(0)
+	return a
+}
+
+type unsignedLastValueCursor struct{ a *cursors.UnsignedArray }
+
+func (c *unsignedLastValueCursor) Close()     {}
+func (c *unsignedLastValueCursor) Err() error { return nil }
+
+func (c *unsignedLastValueCursor) Next() *cursors.UnsignedArray {
+	a := c.a
+	c.a = cursors.NewUnsignedArrayLen(0)
+	return a
+}
+
+type stringLastValueCursor struct{ a *cursors.StringArray }
+
+func (c *stringLastValueCursor) Close()     {}
+func (c *stringLastValueCursor) Err() error { return nil }
+
+func (c *stringLastValueCursor) Next() *cursors.StringArray {
+	a := c.a
+	c.a = cursors.NewStringArrayLen(0)
+	return a
+}
+
+type booleanLastValueCursor struct{ a *cursors.BooleanArray }
+
+func (c *booleanLastValueCursor) Close()     {}
+func (c *booleanLastValueCursor) Err() error { return nil }
+
+func (c *booleanLastValueCursor) Next() *cursors.BooleanArray {
+	a := c.a
+	c.a = cursors.NewBooleanArrayLen(0)
+	return a
+}
@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/platform/tsdb/cursors"
+	"github.com/influxdata/platform/tsdb/tsm1"
+)
+
+func TestLastValueCache_fill(t *testing.T) {
+	key := func(bucket, host string) []byte {
+		return tsm1.SeriesFieldKeyBytes(bucket+",host="+host, "value")
+	}
+
+	t.Run("new bucket of a full cache", func(t *testing.T) {
+		c := newLastValueCache(lastValueSize(key("a", "a"), tsm1.NewFloatValue(0, 0)))
+		itr := &lastValueCursorIteratorStub{v: tsm1.NewFloatValue(10, 1)}
+
+		for _, k := range [][]byte{key("a", "a"), key("b", "a")} {
+			if _, added, err := c.fill(context.Background(), itr, k, true); err != nil {
+				t.Fatal(err)
+			} else if !added {
+				t.Fatalf("expected the last value of %q to be added", k)
+			}
+		}
+		if _, ok := c.get(key("a", "a")); ok {
+			t.Fatal("expected the least recently used value to be evicted")
+		}
+		if _, ok := c.get(key("b", "a")); !ok {
+			t.Fatal("expected the value of the new bucket to be held")
+		}
+
+		// Without eviction, a value that does not fit is not added.
+		if _, _, err := c.fill(context.Background(), itr, key("c", "a"), false); err != errLastValueCacheFull {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("written while read", func(t *testing.T) {
+		c := newLastValueCache(1 << 20)
+		k := key("a", "a")
+		itr := &lastValueCursorIteratorStub{v: tsm1.NewFloatValue(10, 1)}
+		itr.read = func() {
+			c.write(map[string][]tsm1.Value{string(k): {tsm1.NewFloatValue(20, 2)}})
+		}
+
+		v, added, err := c.fill(context.Background(), itr, k, true)
+		if err != nil {
+			t.Fatal(err)
+		} else if added || v.UnixNano() != 10 {
+			t.Fatalf("expected the value read to be returned but not added, got %v, added %v", v, added)
+		}
+		if _, ok := c.get(k); ok {
+			t.Fatal("expected a value read before a write not to be held")
+		}
+	})
+
+	t.Run("deleted while read", func(t *testing.T) {
+		c := newLastValueCache(1 << 20)
+		k := key("a", "a")
+		itr := &lastValueCursorIteratorStub{v: tsm1.NewFloatValue(10, 1)}
+		itr.read = func() { c.deleteRange([]byte("a"), 0, 100) }
+
+		if _, added, err := c.fill(context.Background(), itr, k, true); err != nil {
+			t.Fatal(err)
+		} else if added {
+			t.Fatal("expected a value read before a delete not to be added")
+		}
+	})
+
+	t.Run("complete bucket", func(t *testing.T) {
+		c := newLastValueCache(1 << 20)
+		c.bucketByName([]byte("a")).complete = true
+
+		// Writes of new series of a complete bucket are added.
+		c.write(map[string][]tsm1.Value{string(key("a", "b")): {tsm1.NewFloatValue(20, 2)}})
+		if _, ok := c.get(key("a", "b")); !ok {
+			t.Fatal("expected a new series of a complete bucket to be added")
+		}
+
+		// Once a value of the bucket is deleted, the earlier values of its series are unknown.
+		c.deleteRange([]byte("a"), 15, 25)
+		c.write(map[string][]tsm1.Value{string(key("a", "b")): {tsm1.NewFloatValue(5, 2)}})
+		if _, ok := c.get(key("a", "b")); ok {
+			t.Fatal("expected a write to an incomplete bucket not to be added")
+		}
+	})
+}
+
+// lastValueCursorIteratorStub returns float cursors of the single value v, calling read
+// when the value is read.
+type lastValueCursorIteratorStub struct {
+	v    tsm1.Value
+	read func()
+}
+
+func (itr *lastValueCursorIteratorStub) Next(ctx context.Context, r *cursors.CursorRequest) (cursors.Cursor, error) {
+	if itr.read != nil {
+		itr.read()
+	}
+	a := cursors.NewFloatArrayLen(1)
+	a.Timestamps[0], a.Values[0] = itr.v.UnixNano(), itr.v.Value().(float64)
+	return &floatLastValueCursor{a: a}, nil
+}
@@ -0,0 +1,129 @@
+package storage_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/influxdata/platform"
+	"github.com/influxdata/platform/models"
+	"github.com/influxdata/platform/storage"
+	"github.com/influxdata/platform/toml"
+	"github.com/influxdata/platform/tsdb"
+	"github.com/influxdata/platform/tsdb/cursors"
+)
+
+func TestEngine_CreateLastCursorIterator(t *testing.T) {
+	config := storage.NewConfig()
+	config.LastValueCache.Enabled = true
+	engine := NewEngine(config)
+	defer engine.Close()
+	engine.MustOpen()
+
+	pt := models.MustNewPoint("cpu", models.NewTags(map[string]string{"host": "a"}), map[string]interface{}{"value": 1.0}, time.Unix(0, 10))
+	pts := []models.Point{
+		pt,
+		models.MustNewPoint("cpu", models.NewTags(map[string]string{"host": "a"}), map[string]interface{}{"value": 2.0}, time.Unix(0, 20)),
+	}
+	if err := engine.Write1xPoints(pts); err != nil {
+		t.Fatal(err)
+	}
+	// A point written before the last one must not replace it.
+	pt.SetTime(time.Unix(0, 5))
+	if err := engine.Write1xPoints([]models.Point{pt}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		start, end int64
+		exp        []int64
+	}{
+		{name: "last value in range", start: 0, end: 100, exp: []int64{20}},
+		{name: "last value after range", start: 0, end: 15, exp: []int64{5, 10}},
+		{name: "last value before range", start: 25, end: 100, exp: nil},
+	}
+
+	check := func(t *testing.T) {
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				if got := engine.readTimestamps(t, "cpu", "host", "a", "value", tt.start, tt.end); !reflect.DeepEqual(got, tt.exp) {
+					t.Fatalf("got timestamps %v, expected %v", got, tt.exp)
+				}
+			})
+		}
+	}
+	t.Run("written", check)
+
+	// The cache is rebuilt when the engine is opened again.
+	engine.Engine.Close()
+	engine.MustOpen()
+	t.Run("reopened", check)
+}
+
+func TestEngine_CreateLastCursorIterator_Full(t *testing.T) {
+	config := storage.NewConfig()
+	config.LastValueCache.Enabled = true
+	config.LastValueCache.MaxMemorySize = toml.Size(1)
+	engine := NewEngine(config)
+	defer engine.Close()
+	engine.MustOpen()
+
+	pts := []models.Point{
+		models.MustNewPoint("cpu", models.NewTags(map[string]string{"host": "a"}), map[string]interface{}{"value": 1.0}, time.Unix(0, 10)),
+		models.MustNewPoint("cpu", models.NewTags(map[string]string{"host": "a"}), map[string]interface{}{"value": 2.0}, time.Unix(0, 20)),
+	}
+	if err := engine.Write1xPoints(pts); err != nil {
+		t.Fatal(err)
+	}
+
+	// Nothing fits in the cache, so every value is read.
+	if got, exp := engine.readTimestamps(t, "cpu", "host", "a", "value", 0, 100), []int64{10, 20}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("got timestamps %v, expected %v", got, exp)
+	}
+}
+
+// readTimestamps returns the timestamps of the values returned by a cursor of
+// CreateLastCursorIterator for the field of the series of measurement m with the tag k=v.
+func (e *Engine) readTimestamps(t *testing.T, m, k, v, field string, start, end int64) []int64 {
+	t.Helper()
+
+	org, _ := platform.IDFromString("3131313131313131")
+	bucket, _ := platform.IDFromString("3232323232323232")
+	name := tsdb.EncodeName(*org, *bucket)
+	tags := models.NewTags(map[string]string{
+		tsdb.MeasurementTagKey: m,
+		k:                      v,
+		tsdb.FieldKeyTagKey:    field,
+	})
+
+	itr, err := e.CreateLastCursorIterator(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cur, err := itr.Next(context.Background(), &cursors.CursorRequest{
+		Name:      name[:],
+		Tags:      tags,
+		Field:     field,
+		Ascending: true,
+		StartTime: start,
+		EndTime:   end,
+	})
+	if err != nil {
+		t.Fatal(err)
+	} else if cur == nil {
+		t.Fatal("expected a cursor")
+	}
+	defer cur.Close()
+
+	var timestamps []int64
+	fc, ok := cur.(cursors.FloatArrayCursor)
+	if !ok {
+		t.Fatalf("got cursor %T, expected a float cursor", cur)
+	}
+	for a := fc.Next(); a.Len() > 0; a = fc.Next() {
+		timestamps = append(timestamps, a.Timestamps...)
+	}
+	return timestamps
+}
@@ -7,6 +7,50 @@ const namespace = "storage"
 
 const retentionSubsystem = "retention" // sub-system associated with metrics for writing points.
 
+const purgeSubsystem = "bucket_purge" // sub-system associated with metrics for purging deleted buckets.
+
+const writerSubsystem = "writer" // sub-system associated with metrics for writing points.
+
+// writeMetrics is a set of metrics concerned with the writes that the engine rejects.
+type writeMetrics struct {
+	RejectedWrites *prometheus.CounterVec
+	RejectedPoints *prometheus.CounterVec
+}
+
+func newWriteMetrics() *writeMetrics {
+	names := []string{"reason"}
+
+	return &writeMetrics{
+		RejectedWrites: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: writerSubsystem,
+			Name:      "rejected_writes_total",
+			Help:      "Number of writes rejected because the engine was overloaded or closed.",
+		}, names),
+
+		RejectedPoints: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: writerSubsystem,
+			Name:      "rejected_points_total",
+			Help:      "Number of points of the writes rejected because the engine was overloaded or closed.",
+		}, names),
+	}
+}
+
+// rejected counts a write of n points that was rejected for reason.
+func (wm *writeMetrics) rejected(reason string, n int) {
+	wm.RejectedWrites.WithLabelValues(reason).Inc()
+	wm.RejectedPoints.WithLabelValues(reason).Add(float64(n))
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (wm *writeMetrics) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		wm.RejectedWrites,
+		wm.RejectedPoints,
+	}
+}
+
 // retentionMetrics is a set of metrics concerned with tracking data about retention policies.
 type retentionMetrics struct {
 	Checks        *prometheus.CounterVec
@@ -63,3 +107,57 @@ func (rm *retentionMetrics) PrometheusCollectors() []prometheus.Collector {
 		rm.Series,
 	}
 }
+
+// purgeMetrics is a set of metrics concerned with tracking the purging of deleted buckets and organizations.
+type purgeMetrics struct {
+	Purges   *prometheus.CounterVec
+	Duration *prometheus.HistogramVec
+	Pending  prometheus.Gauge
+	Series   prometheus.Counter
+}
+
+func newPurgeMetrics() *purgeMetrics {
+	names := []string{"status"}
+
+	return &purgeMetrics{
+		Purges: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: purgeSubsystem,
+			Name:      "purges_total",
+			Help:      "Number of bucket or organization purges attempted.",
+		}, names),
+
+		Duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: purgeSubsystem,
+			Name:      "duration_seconds",
+			Help:      "Time taken to purge a bucket or organization.",
+			// 25 buckets spaced exponentially between 1s and ~14m
+			Buckets: prometheus.ExponentialBuckets(1, 1.32, 25),
+		}, names),
+
+		Pending: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: purgeSubsystem,
+			Name:      "pending",
+			Help:      "Number of deleted buckets or organizations whose data has yet to be purged.",
+		}),
+
+		Series: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: purgeSubsystem,
+			Name:      "series_total",
+			Help:      "Number of series purged.",
+		}),
+	}
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (pm *purgeMetrics) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		pm.Purges,
+		pm.Duration,
+		pm.Pending,
+		pm.Series,
+	}
+}
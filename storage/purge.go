@@ -0,0 +1,162 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/influxdata/platform"
+	"github.com/influxdata/platform/logger"
+	"github.com/influxdata/platform/models"
+	"github.com/influxdata/platform/tsdb"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// purgeProgressInterval is how many series are purged between progress log messages.
+const purgeProgressInterval = 10000
+
+// The bucketPurger removes all data of deleted buckets and organizations from the engine.
+//
+// Purges are recorded by the BucketPurgeService when a bucket or organization is deleted,
+// and are only marked complete once all of their series have been deleted. A purge that is
+// interrupted, for example by a restart, is therefore resumed the next time the purger runs.
+type bucketPurger struct {
+	// Engine provides access to data stored on the engine
+	Engine Deleter
+
+	// PurgeService provides the purges that have yet to complete.
+	PurgeService platform.BucketPurgeService
+
+	logger *zap.Logger
+
+	purgeMetrics *purgeMetrics
+}
+
+// newBucketPurger returns a new purger that removes the data of deleted buckets
+// and organizations recorded by purgeService.
+func newBucketPurger(engine Deleter, purgeService platform.BucketPurgeService) *bucketPurger {
+	return &bucketPurger{
+		Engine:       engine,
+		PurgeService: purgeService,
+		logger:       zap.NewNop(),
+		purgeMetrics: newPurgeMetrics(),
+	}
+}
+
+// WithLogger sets the logger l on the purger. It must be called before Open.
+func (s *bucketPurger) WithLogger(l *zap.Logger) {
+	if s == nil {
+		return // Not initialised
+	}
+	s.logger = l.With(zap.String("component", "bucket_purger"))
+}
+
+// run purges the data of every deleted bucket and organization that has yet to be purged.
+// It returns early once ctx is done, leaving the remaining purges to the next run.
+func (s *bucketPurger) run(ctx context.Context) {
+	log, logEnd := logger.NewOperation(s.logger, "Bucket purge", "bucket_purge")
+	defer logEnd()
+
+	findCtx, cancel := context.WithTimeout(ctx, bucketAPITimeout)
+	purges, err := s.PurgeService.FindBucketPurges(findCtx)
+	cancel()
+	if err != nil {
+		log.Error("Unable to find pending purges", zap.Error(err))
+		return
+	}
+	s.purgeMetrics.Pending.Set(float64(len(purges)))
+
+	for i, p := range purges {
+		now := time.Now()
+		status := "ok"
+		if err := s.purge(ctx, log, p); err != nil && ctx.Err() != nil {
+			log.Info("Purge interrupted", zap.Stringer("org_id", p.OrganizationID), zap.Stringer("bucket_id", p.BucketID))
+			return
+		} else if err != nil {
+			log.Error("Purge not successful", zap.Error(err), zap.Stringer("org_id", p.OrganizationID), zap.Stringer("bucket_id", p.BucketID))
+			status = "error"
+		}
+		s.purgeMetrics.Duration.With(prometheus.Labels{"status": status}).Observe(time.Since(now).Seconds())
+		s.purgeMetrics.Purges.With(prometheus.Labels{"status": status}).Inc()
+		if status == "ok" {
+			s.purgeMetrics.Pending.Set(float64(len(purges) - i - 1))
+		}
+	}
+}
+
+// purge deletes all data of the bucket or organization of p, and marks p as complete.
+// The series are no longer read once ctx is done.
+func (s *bucketPurger) purge(ctx context.Context, log *zap.Logger, p *platform.BucketPurge) error {
+	log = log.With(zap.Stringer("org_id", p.OrganizationID))
+	if p.BucketID.Valid() {
+		log = log.With(zap.Stringer("bucket_id", p.BucketID))
+	}
+
+	var (
+		req   SeriesCursorRequest
+		match func(name []byte) bool
+	)
+	if p.BucketID.Valid() {
+		name := tsdb.EncodeName(p.OrganizationID, p.BucketID)
+		req.Measurements = tsdb.NewMeasurementSliceIterator([][]byte{name[:]})
+		match = func(n []byte) bool { return bytes.Equal(n, name[:]) }
+	} else {
+		// The organization ID is the first half of every measurement name of the organization.
+		name := tsdb.EncodeName(p.OrganizationID, 0)
+		req.MeasurementPrefix = name[:platform.IDLength/2]
+		match = func(n []byte) bool { return len(n) == platform.IDLength && bytes.HasPrefix(n, req.MeasurementPrefix) }
+	}
+
+	cursorCtx, cancel := context.WithTimeout(ctx, engineAPITimeout)
+	defer cancel()
+	cur, err := s.Engine.CreateSeriesCursor(cursorCtx, req, nil)
+	if err != nil {
+		return err
+	}
+	defer cur.Close()
+
+	log.Info("Purging deleted data")
+
+	var seriesPurged uint64
+	fn := func(name []byte, tags models.Tags) (int64, int64, bool) {
+		if !match(name) {
+			return 0, 0, false
+		}
+
+		if n := atomic.AddUint64(&seriesPurged, 1); n%purgeProgressInterval == 0 {
+			log.Info("Purge in progress", zap.Uint64("series_purged", n))
+		}
+		s.purgeMetrics.Series.Inc()
+		return math.MinInt64, math.MaxInt64, true
+	}
+
+	if err := s.Engine.DeleteSeriesRangeWithPredicate(newSeriesIteratorAdapter(contextSeriesCursor{cur, ctx}), fn); err != nil {
+		return err
+	}
+	log.Info("Purge complete", zap.Uint64("series_purged", atomic.LoadUint64(&seriesPurged)))
+
+	deleteCtx, cancel := context.WithTimeout(ctx, bucketAPITimeout)
+	defer cancel()
+	return s.PurgeService.DeleteBucketPurge(deleteCtx, p.OrganizationID, p.BucketID)
+}
+
+// contextSeriesCursor is a SeriesCursor that stops with the error of ctx once ctx is done.
+type contextSeriesCursor struct {
+	SeriesCursor
+	ctx context.Context
+}
+
+func (cur contextSeriesCursor) Next() (*SeriesCursorRow, error) {
+	if err := cur.ctx.Err(); err != nil {
+		return nil, err
+	}
+	return cur.SeriesCursor.Next()
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (s *bucketPurger) PrometheusCollectors() []prometheus.Collector {
+	return s.purgeMetrics.PrometheusCollectors()
+}
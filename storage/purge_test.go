@@ -0,0 +1,154 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"math"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/influxdata/influxql"
+	"github.com/influxdata/platform"
+	"github.com/influxdata/platform/models"
+	"github.com/influxdata/platform/tsdb"
+)
+
+func TestBucketPurger_run(t *testing.T) {
+	name := func(org, bucket platform.ID) []byte {
+		n := tsdb.EncodeName(org, bucket)
+		return n[:]
+	}
+	names := [][]byte{
+		name(1, 10),
+		name(1, 11),
+		name(2, 20),
+		name(2, 21),
+		name(3, 30),
+		[]byte("zyzwrong"),
+	}
+
+	purges := []*platform.BucketPurge{
+		{OrganizationID: 1, BucketID: 10},
+		{OrganizationID: 2},
+	}
+	purgeService := &TestBucketPurgeService{Purges: purges}
+
+	var matched []string
+	var prefixes [][]byte
+	engine := NewTestEngine()
+	engine.CreateSeriesCursorFn = func(_ context.Context, req SeriesCursorRequest, _ influxql.Expr) (SeriesCursor, error) {
+		prefixes = append(prefixes, req.MeasurementPrefix)
+		return engine.SeriesCursor, nil
+	}
+	engine.DeleteSeriesRangeWithPredicateFn = func(_ tsdb.SeriesIterator, fn func([]byte, models.Tags) (int64, int64, bool)) error {
+		for _, n := range names {
+			from, to, shouldDelete := fn(n, nil)
+			if !shouldDelete {
+				continue
+			}
+			if from != math.MinInt64 || to != math.MaxInt64 {
+				t.Fatalf("got range [%d, %d], expected all time", from, to)
+			}
+			matched = append(matched, string(n))
+		}
+		return nil
+	}
+
+	purger := newBucketPurger(engine, purgeService)
+	purger.run(context.Background())
+
+	exp := []string{string(name(1, 10)), string(name(2, 20)), string(name(2, 21))}
+	sort.Strings(matched)
+	sort.Strings(exp)
+	if !reflect.DeepEqual(matched, exp) {
+		t.Fatalf("got matched series\n%q\nexpected\n%q", matched, exp)
+	}
+	if len(purgeService.Purges) != 0 {
+		t.Fatalf("expected all purges to be complete, got %d pending", len(purgeService.Purges))
+	}
+
+	// Only the measurements of the organization are read when an organization is purged.
+	org := name(2, 0)
+	if exp := [][]byte{nil, org[:platform.IDLength/2]}; !reflect.DeepEqual(prefixes, exp) {
+		t.Fatalf("got measurement prefixes %q, expected %q", prefixes, exp)
+	}
+}
+
+func TestBucketPurger_runResumes(t *testing.T) {
+	purgeService := &TestBucketPurgeService{
+		Purges: []*platform.BucketPurge{{OrganizationID: 1, BucketID: 10}},
+	}
+
+	engine := NewTestEngine()
+	engine.DeleteSeriesRangeWithPredicateFn = func(tsdb.SeriesIterator, func([]byte, models.Tags) (int64, int64, bool)) error {
+		return errors.New("interrupted")
+	}
+
+	purger := newBucketPurger(engine, purgeService)
+	purger.run(context.Background())
+
+	// An unsuccessful purge remains pending, so that the next run retries it.
+	if len(purgeService.Purges) != 1 {
+		t.Fatalf("expected the purge to remain pending, got %d pending", len(purgeService.Purges))
+	}
+
+	engine.DeleteSeriesRangeWithPredicateFn = func(tsdb.SeriesIterator, func([]byte, models.Tags) (int64, int64, bool)) error {
+		return nil
+	}
+	purger.run(context.Background())
+	if len(purgeService.Purges) != 0 {
+		t.Fatalf("expected the purge to be complete, got %d pending", len(purgeService.Purges))
+	}
+}
+
+func TestBucketPurger_runCanceled(t *testing.T) {
+	purgeService := &TestBucketPurgeService{
+		Purges: []*platform.BucketPurge{{OrganizationID: 1}, {OrganizationID: 2}},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var purged int
+	engine := NewTestEngine()
+	engine.SeriesCursor.NextFn = func() (*SeriesCursorRow, error) {
+		return &SeriesCursorRow{Name: []byte("m")}, nil
+	}
+	engine.DeleteSeriesRangeWithPredicateFn = func(itr tsdb.SeriesIterator, fn func([]byte, models.Tags) (int64, int64, bool)) error {
+		purged++
+		// The engine is closed while the series are read.
+		cancel()
+		for {
+			if elem, err := itr.Next(); err != nil || elem == nil {
+				return err
+			}
+		}
+	}
+
+	purger := newBucketPurger(engine, purgeService)
+	purger.run(ctx)
+
+	if purged != 1 {
+		t.Fatalf("expected the purger to stop after the interrupted purge, got %d purges", purged)
+	}
+	if len(purgeService.Purges) != 2 {
+		t.Fatalf("expected both purges to remain pending, got %d pending", len(purgeService.Purges))
+	}
+}
+
+type TestBucketPurgeService struct {
+	Purges []*platform.BucketPurge
+}
+
+func (s *TestBucketPurgeService) FindBucketPurges(ctx context.Context) ([]*platform.BucketPurge, error) {
+	return append([]*platform.BucketPurge(nil), s.Purges...), nil
+}
+
+func (s *TestBucketPurgeService) DeleteBucketPurge(ctx context.Context, orgID, bucketID platform.ID) error {
+	for i, p := range s.Purges {
+		if p.OrganizationID == orgID && p.BucketID == bucketID {
+			s.Purges = append(s.Purges[:i], s.Purges[i+1:]...)
+			return nil
+		}
+	}
+	return errors.New("purge not found")
+}
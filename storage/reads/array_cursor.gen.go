@@ -8,6 +8,7 @@ package reads
 
 import (
 	"errors"
+	"sort"
 
 	"github.com/influxdata/platform/tsdb/cursors"
 )
@@ -83,6 +84,17 @@ LOOP:
 	return c.res
 }
 
+// SeekTo implements cursors.Seeker, discarding any values read but not yet
+// returned that are before t.
+func (c *floatArrayFilterCursor) SeekTo(t int64) {
+	i := sort.Search(len(c.tmp.Timestamps), func(i int) bool {
+		return c.tmp.Timestamps[i] >= t
+	})
+	c.tmp.Timestamps = c.tmp.Timestamps[i:]
+	c.tmp.Values = c.tmp.Values[i:]
+	seek(c.FloatArrayCursor, t)
+}
+
 type floatMultiShardArrayCursor struct {
 	cursors.FloatArrayCursor
 	cursorContext
@@ -126,6 +138,12 @@ func (c *floatMultiShardArrayCursor) Next() *cursors.FloatArray {
 	}
 }
 
+// SeekTo implements cursors.Seeker. Values that are skipped do not count
+// towards the points limit.
+func (c *floatMultiShardArrayCursor) SeekTo(t int64) {
+	seek(c.FloatArrayCursor, t)
+}
+
 func (c *floatMultiShardArrayCursor) nextArrayCursor() bool {
 	if len(c.itrs) == 0 {
 		return false
@@ -201,6 +219,114 @@ func (c floatArraySumCursor) Next() *cursors.FloatArray {
 	}
 }
 
+type floatArrayMinCursor struct {
+	cursors.FloatArrayCursor
+	res *cursors.FloatArray
+}
+
+func newFloatArrayMinCursor(cur cursors.FloatArrayCursor) *floatArrayMinCursor {
+	return &floatArrayMinCursor{
+		FloatArrayCursor: cur,
+		res:              cursors.NewFloatArrayLen(1),
+	}
+}
+
+// Next returns the smallest value and its timestamp. Of several equal values,
+// the first is returned.
+func (c *floatArrayMinCursor) Next() *cursors.FloatArray {
+	a := c.FloatArrayCursor.Next()
+	if len(a.Timestamps) == 0 {
+		return a
+	}
+
+	ts, min := a.Timestamps[0], a.Values[0]
+	for {
+		for i, v := range a.Values {
+			if v < min {
+				ts, min = a.Timestamps[i], v
+			}
+		}
+		a = c.FloatArrayCursor.Next()
+		if len(a.Timestamps) == 0 {
+			c.res.Timestamps[0] = ts
+			c.res.Values[0] = min
+			return c.res
+		}
+	}
+}
+
+type floatArrayMaxCursor struct {
+	cursors.FloatArrayCursor
+	res *cursors.FloatArray
+}
+
+func newFloatArrayMaxCursor(cur cursors.FloatArrayCursor) *floatArrayMaxCursor {
+	return &floatArrayMaxCursor{
+		FloatArrayCursor: cur,
+		res:              cursors.NewFloatArrayLen(1),
+	}
+}
+
+// Next returns the largest value and its timestamp. Of several equal values,
+// the first is returned.
+func (c *floatArrayMaxCursor) Next() *cursors.FloatArray {
+	a := c.FloatArrayCursor.Next()
+	if len(a.Timestamps) == 0 {
+		return a
+	}
+
+	ts, max := a.Timestamps[0], a.Values[0]
+	for {
+		for i, v := range a.Values {
+			if v > max {
+				ts, max = a.Timestamps[i], v
+			}
+		}
+		a = c.FloatArrayCursor.Next()
+		if len(a.Timestamps) == 0 {
+			c.res.Timestamps[0] = ts
+			c.res.Values[0] = max
+			return c.res
+		}
+	}
+}
+
+type floatFloatMeanArrayCursor struct {
+	cursors.FloatArrayCursor
+	res *cursors.FloatArray
+}
+
+func newFloatFloatMeanArrayCursor(cur cursors.FloatArrayCursor) *floatFloatMeanArrayCursor {
+	return &floatFloatMeanArrayCursor{
+		FloatArrayCursor: cur,
+		res:              cursors.NewFloatArrayLen(1),
+	}
+}
+
+// Next returns the mean of all values, with the timestamp of the first value.
+func (c *floatFloatMeanArrayCursor) Next() *cursors.FloatArray {
+	a := c.FloatArrayCursor.Next()
+	if len(a.Timestamps) == 0 {
+		return &cursors.FloatArray{}
+	}
+
+	ts := a.Timestamps[0]
+	var sum float64
+	var n int
+	for {
+		for _, v := range a.Values {
+			sum += float64(v)
+		}
+		n += len(a.Values)
+		a = c.FloatArrayCursor.Next()
+		if len(a.Timestamps) == 0 {
+			c.res.Timestamps[0] = ts
+			c.res.Values[0] = sum / float64(n)
+			return c.res
+		}
+	}
+}
+
 type integerFloatCountArrayCursor struct {
 	cursors.FloatArrayCursor
 }
@@ -225,6 +351,341 @@ func (c *integerFloatCountArrayCursor) Next() *cursors.IntegerArray {
 	}
 }
 
+type floatArrayFirstCursor struct {
+	cursors.FloatArrayCursor
+	res  *cursors.FloatArray
+	done bool
+}
+
+func newFloatArrayFirstCursor(cur cursors.FloatArrayCursor) *floatArrayFirstCursor {
+	return &floatArrayFirstCursor{
+		FloatArrayCursor: cur,
+		res:              cursors.NewFloatArrayLen(1),
+	}
+}
+
+// Next returns the first value of the cursor. The remaining values are never read.
+func (c *floatArrayFirstCursor) Next() *cursors.FloatArray {
+	if c.done {
+		return &cursors.FloatArray{}
+	}
+	c.done = true
+
+	a := c.FloatArrayCursor.Next()
+	if len(a.Timestamps) == 0 {
+		return a
+	}
+
+	c.res.Timestamps[0] = a.Timestamps[0]
+	c.res.Values[0] = a.Values[0]
+	return c.res
+}
+
+type floatArrayLastCursor struct {
+	cursors.FloatArrayCursor
+	res *cursors.FloatArray
+}
+
+func newFloatArrayLastCursor(cur cursors.FloatArrayCursor) *floatArrayLastCursor {
+	return &floatArrayLastCursor{
+		FloatArrayCursor: cur,
+		res:              cursors.NewFloatArrayLen(1),
+	}
+}
+
+// Next returns the last value of the cursor.
+func (c *floatArrayLastCursor) Next() *cursors.FloatArray {
+	a := c.FloatArrayCursor.Next()
+	if len(a.Timestamps) == 0 {
+		return a
+	}
+
+	for {
+		i := len(a.Timestamps) - 1
+		c.res.Timestamps[0] = a.Timestamps[i]
+		c.res.Values[0] = a.Values[i]
+		a = c.FloatArrayCursor.Next()
+		if len(a.Timestamps) == 0 {
+			return c.res
+		}
+	}
+}
+
+// floatArrayWindowReader reads the values of a cursor one window at a time.
+type floatArrayWindowReader struct {
+	cur    cursors.FloatArrayCursor
+	window window
+	rem    cursors.FloatArray // values read from cur that have not been returned
+	stop   int64              // the end of the current window
+	res    cursors.FloatArray
+}
+
+// nextWindow moves to the window of the next value, returning false when there
+// are no more values.
+func (r *floatArrayWindowReader) nextWindow() bool {
+	if r.rem.Len() == 0 {
+		a := r.cur.Next()
+		r.rem.Timestamps, r.rem.Values = a.Timestamps, a.Values
+		if r.rem.Len() == 0 {
+			return false
+		}
+	}
+	r.stop = r.window.stop(r.rem.Timestamps[0])
+	return true
+}
+
+// next returns the next values of the current window, or no values at the
+// end of the window.
+func (r *floatArrayWindowReader) next() *cursors.FloatArray {
+	if r.rem.Len() == 0 {
+		a := r.cur.Next()
+		r.rem.Timestamps, r.rem.Values = a.Timestamps, a.Values
+	}
+	n := sort.Search(len(r.rem.Timestamps), func(i int) bool {
+		return r.rem.Timestamps[i] >= r.stop
+	})
+	r.res.Timestamps, r.res.Values = r.rem.Timestamps[:n], r.rem.Values[:n]
+	r.rem.Timestamps, r.rem.Values = r.rem.Timestamps[n:], r.rem.Values[n:]
+	return &r.res
+}
+
+// skipWindow moves past the remaining values of the current window. The
+// values are not read if the cursor can seek past them.
+func (r *floatArrayWindowReader) skipWindow() {
+	if r.rem.Len() > 0 && r.rem.Timestamps[r.rem.Len()-1] >= r.stop {
+		r.next()
+		return
+	}
+	r.rem.Timestamps, r.rem.Values = nil, nil
+	if !seek(r.cur, r.stop) {
+		for r.next().Len() > 0 {
+		}
+	}
+}
+
+type floatWindowFirstArrayCursor struct {
+	cursors.FloatArrayCursor
+	r   floatArrayWindowReader
+	res *cursors.FloatArray
+}
+
+func newFloatWindowFirstArrayCursor(cur cursors.FloatArrayCursor, w window) *floatWindowFirstArrayCursor {
+	return &floatWindowFirstArrayCursor{
+		FloatArrayCursor: cur,
+		r:                floatArrayWindowReader{cur: cur, window: w},
+		res:              cursors.NewFloatArrayLen(MaxPointsPerBlock),
+	}
+}
+
+// Next returns the first value of each window. The remaining values of each
+// window are skipped without being read where possible.
+func (c *floatWindowFirstArrayCursor) Next() *cursors.FloatArray {
+	c.res.Timestamps, c.res.Values = c.res.Timestamps[:0], c.res.Values[:0]
+	for c.res.Len() < MaxPointsPerBlock && c.r.nextWindow() {
+		a := c.r.next()
+		c.res.Timestamps = append(c.res.Timestamps, a.Timestamps[0])
+		c.res.Values = append(c.res.Values, a.Values[0])
+		c.r.skipWindow()
+	}
+	return c.res
+}
+
+type floatWindowLastArrayCursor struct {
+	cursors.FloatArrayCursor
+	r   floatArrayWindowReader
+	res *cursors.FloatArray
+}
+
+func newFloatWindowLastArrayCursor(cur cursors.FloatArrayCursor, w window) *floatWindowLastArrayCursor {
+	return &floatWindowLastArrayCursor{
+		FloatArrayCursor: cur,
+		r:                floatArrayWindowReader{cur: cur, window: w},
+		res:              cursors.NewFloatArrayLen(MaxPointsPerBlock),
+	}
+}
+
+// Next returns the last value of each window.
+func (c *floatWindowLastArrayCursor) Next() *cursors.FloatArray {
+	c.res.Timestamps, c.res.Values = c.res.Timestamps[:0], c.res.Values[:0]
+	for c.res.Len() < MaxPointsPerBlock && c.r.nextWindow() {
+		var ts int64
+		var v float64
+		for a := c.r.next(); a.Len() > 0; a = c.r.next() {
+			ts, v = a.Timestamps[a.Len()-1], a.Values[a.Len()-1]
+		}
+		c.res.Timestamps = append(c.res.Timestamps, ts)
+		c.res.Values = append(c.res.Values, v)
+	}
+	return c.res
+}
+
+type integerFloatWindowCountArrayCursor struct {
+	cursors.FloatArrayCursor
+	r   floatArrayWindowReader
+	res *cursors.IntegerArray
+}
+
+func newIntegerFloatWindowCountArrayCursor(cur cursors.FloatArrayCursor, w window) *integerFloatWindowCountArrayCursor {
+	return &integerFloatWindowCountArrayCursor{
+		FloatArrayCursor: cur,
+		r:                floatArrayWindowReader{cur: cur, window: w},
+		res:              cursors.NewIntegerArrayLen(MaxPointsPerBlock),
+	}
+}
+
+// Next returns the number of values of each window, with the timestamp of
+// the first value.
+func (c *integerFloatWindowCountArrayCursor) Next() *cursors.IntegerArray {
+	c.res.Timestamps, c.res.Values = c.res.Timestamps[:0], c.res.Values[:0]
+	for c.res.Len() < MaxPointsPerBlock && c.r.nextWindow() {
+		a := c.r.next()
+		ts := a.Timestamps[0]
+		var n int64
+		for ; a.Len() > 0; a = c.r.next() {
+			n += int64(a.Len())
+		}
+		c.res.Timestamps = append(c.res.Timestamps, ts)
+		c.res.Values = append(c.res.Values, n)
+	}
+	return c.res
+}
+
+type floatWindowSumArrayCursor struct {
+	cursors.FloatArrayCursor
+	r   floatArrayWindowReader
+	res *cursors.FloatArray
+}
+
+func newFloatWindowSumArrayCursor(cur cursors.FloatArrayCursor, w window) *floatWindowSumArrayCursor {
+	return &floatWindowSumArrayCursor{
+		FloatArrayCursor: cur,
+		r:                floatArrayWindowReader{cur: cur, window: w},
+		res:              cursors.NewFloatArrayLen(MaxPointsPerBlock),
+	}
+}
+
+// Next returns the sum of the values of each window, with the timestamp of
+// the first value.
+func (c *floatWindowSumArrayCursor) Next() *cursors.FloatArray {
+	c.res.Timestamps, c.res.Values = c.res.Timestamps[:0], c.res.Values[:0]
+	for c.res.Len() < MaxPointsPerBlock && c.r.nextWindow() {
+		a := c.r.next()
+		ts := a.Timestamps[0]
+		var acc float64
+		for ; a.Len() > 0; a = c.r.next() {
+			for _, v := range a.Values {
+				acc += v
+			}
+		}
+		c.res.Timestamps = append(c.res.Timestamps, ts)
+		c.res.Values = append(c.res.Values, acc)
+	}
+	return c.res
+}
+
+type floatWindowMinArrayCursor struct {
+	cursors.FloatArrayCursor
+	r   floatArrayWindowReader
+	res *cursors.FloatArray
+}
+
+func newFloatWindowMinArrayCursor(cur cursors.FloatArrayCursor, w window) *floatWindowMinArrayCursor {
+	return &floatWindowMinArrayCursor{
+		FloatArrayCursor: cur,
+		r:                floatArrayWindowReader{cur: cur, window: w},
+		res:              cursors.NewFloatArrayLen(MaxPointsPerBlock),
+	}
+}
+
+// Next returns the smallest value of each window and its timestamp. Of several
+// equal values, the first is returned.
+func (c *floatWindowMinArrayCursor) Next() *cursors.FloatArray {
+	c.res.Timestamps, c.res.Values = c.res.Timestamps[:0], c.res.Values[:0]
+	for c.res.Len() < MaxPointsPerBlock && c.r.nextWindow() {
+		a := c.r.next()
+		ts, min := a.Timestamps[0], a.Values[0]
+		for ; a.Len() > 0; a = c.r.next() {
+			for i, v := range a.Values {
+				if v < min {
+					ts, min = a.Timestamps[i], v
+				}
+			}
+		}
+		c.res.Timestamps = append(c.res.Timestamps, ts)
+		c.res.Values = append(c.res.Values, min)
+	}
+	return c.res
+}
+
+type floatWindowMaxArrayCursor struct {
+	cursors.FloatArrayCursor
+	r   floatArrayWindowReader
+	res *cursors.FloatArray
+}
+
+func newFloatWindowMaxArrayCursor(cur cursors.FloatArrayCursor, w window) *floatWindowMaxArrayCursor {
+	return &floatWindowMaxArrayCursor{
+		FloatArrayCursor: cur,
+		r:                floatArrayWindowReader{cur: cur, window: w},
+		res:              cursors.NewFloatArrayLen(MaxPointsPerBlock),
+	}
+}
+
+// Next returns the largest value of each window and its timestamp. Of several
+// equal values, the first is returned.
+func (c *floatWindowMaxArrayCursor) Next() *cursors.FloatArray {
+	c.res.Timestamps, c.res.Values = c.res.Timestamps[:0], c.res.Values[:0]
+	for c.res.Len() < MaxPointsPerBlock && c.r.nextWindow() {
+		a := c.r.next()
+		ts, max := a.Timestamps[0], a.Values[0]
+		for ; a.Len() > 0; a = c.r.next() {
+			for i, v := range a.Values {
+				if v > max {
+					ts, max = a.Timestamps[i], v
+				}
+			}
+		}
+		c.res.Timestamps = append(c.res.Timestamps, ts)
+		c.res.Values = append(c.res.Values, max)
+	}
+	return c.res
+}
+
+type floatFloatWindowMeanArrayCursor struct {
+	cursors.FloatArrayCursor
+	r   floatArrayWindowReader
+	res *cursors.FloatArray
+}
+
+func newFloatFloatWindowMeanArrayCursor(cur cursors.FloatArrayCursor, w window) *floatFloatWindowMeanArrayCursor {
+	return &floatFloatWindowMeanArrayCursor{
+		FloatArrayCursor: cur,
+		r:                floatArrayWindowReader{cur: cur, window: w},
+		res:              cursors.NewFloatArrayLen(MaxPointsPerBlock),
+	}
+}
+
+// Next returns the mean of the values of each window, with the timestamp of
+// the first value.
+func (c *floatFloatWindowMeanArrayCursor) Next() *cursors.FloatArray {
+	c.res.Timestamps, c.res.Values = c.res.Timestamps[:0], c.res.Values[:0]
+	for c.res.Len() < MaxPointsPerBlock && c.r.nextWindow() {
+		a := c.r.next()
+		ts := a.Timestamps[0]
+		var sum float64
+		var n int
+		for ; a.Len() > 0; a = c.r.next() {
+			for _, v := range a.Values {
+				sum += float64(v)
+			}
+			n += a.Len()
+		}
+		c.res.Timestamps = append(c.res.Timestamps, ts)
+		c.res.Values = append(c.res.Values, sum/float64(n))
+	}
+	return c.res
+}
+
 type floatEmptyArrayCursor struct {
 	res cursors.FloatArray
 }
@@ -299,6 +760,17 @@ LOOP:
 	return c.res
 }
 
+// SeekTo implements cursors.Seeker, discarding any values read but not yet
+// returned that are before t.
+func (c *integerArrayFilterCursor) SeekTo(t int64) {
+	i := sort.Search(len(c.tmp.Timestamps), func(i int) bool {
+		return c.tmp.Timestamps[i] >= t
+	})
+	c.tmp.Timestamps = c.tmp.Timestamps[i:]
+	c.tmp.Values = c.tmp.Values[i:]
+	seek(c.IntegerArrayCursor, t)
+}
+
 type integerMultiShardArrayCursor struct {
 	cursors.IntegerArrayCursor
 	cursorContext
@@ -342,6 +814,12 @@ func (c *integerMultiShardArrayCursor) Next() *cursors.IntegerArray {
 	}
 }
 
+// SeekTo implements cursors.Seeker. Values that are skipped do not count
+// towards the points limit.
+func (c *integerMultiShardArrayCursor) SeekTo(t int64) {
+	seek(c.IntegerArrayCursor, t)
+}
+
 func (c *integerMultiShardArrayCursor) nextArrayCursor() bool {
 	if len(c.itrs) == 0 {
 		return false
@@ -417,38 +895,481 @@ func (c integerArraySumCursor) Next() *cursors.IntegerArray {
 	}
 }
 
-type integerIntegerCountArrayCursor struct {
+type integerArrayMinCursor struct {
 	cursors.IntegerArrayCursor
+	res *cursors.IntegerArray
 }
 
-func (c *integerIntegerCountArrayCursor) Next() *cursors.IntegerArray {
+func newIntegerArrayMinCursor(cur cursors.IntegerArrayCursor) *integerArrayMinCursor {
+	return &integerArrayMinCursor{
+		IntegerArrayCursor: cur,
+		res:                cursors.NewIntegerArrayLen(1),
+	}
+}
+
+// Next returns the smallest value and its timestamp. Of several equal values,
+// the first is returned.
+func (c *integerArrayMinCursor) Next() *cursors.IntegerArray {
 	a := c.IntegerArrayCursor.Next()
 	if len(a.Timestamps) == 0 {
-		return &cursors.IntegerArray{}
+		return a
 	}
 
-	ts := a.Timestamps[0]
-	var acc int64
+	ts, min := a.Timestamps[0], a.Values[0]
 	for {
-		acc += int64(len(a.Timestamps))
+		for i, v := range a.Values {
+			if v < min {
+				ts, min = a.Timestamps[i], v
+			}
+		}
 		a = c.IntegerArrayCursor.Next()
 		if len(a.Timestamps) == 0 {
-			res := cursors.NewIntegerArrayLen(1)
-			res.Timestamps[0] = ts
-			res.Values[0] = acc
-			return res
+			c.res.Timestamps[0] = ts
+			c.res.Values[0] = min
+			return c.res
 		}
 	}
 }
 
-type integerEmptyArrayCursor struct {
-	res cursors.IntegerArray
+type integerArrayMaxCursor struct {
+	cursors.IntegerArrayCursor
+	res *cursors.IntegerArray
 }
 
-var IntegerEmptyArrayCursor cursors.IntegerArrayCursor = &integerEmptyArrayCursor{}
+func newIntegerArrayMaxCursor(cur cursors.IntegerArrayCursor) *integerArrayMaxCursor {
+	return &integerArrayMaxCursor{
+		IntegerArrayCursor: cur,
+		res:                cursors.NewIntegerArrayLen(1),
+	}
+}
 
-func (c *integerEmptyArrayCursor) Err() error                  { return nil }
-func (c *integerEmptyArrayCursor) Close()                      {}
+// Next returns the largest value and its timestamp. Of several equal values,
+// the first is returned.
+func (c *integerArrayMaxCursor) Next() *cursors.IntegerArray {
+	a := c.IntegerArrayCursor.Next()
+	if len(a.Timestamps) == 0 {
+		return a
+	}
+
+	ts, max := a.Timestamps[0], a.Values[0]
+	for {
+		for i, v := range a.Values {
+			if v > max {
+				ts, max = a.Timestamps[i], v
+			}
+		}
+		a = c.IntegerArrayCursor.Next()
+		if len(a.Timestamps) == 0 {
+			c.res.Timestamps[0] = ts
+			c.res.Values[0] = max
+			return c.res
+		}
+	}
+}
+
+type floatIntegerMeanArrayCursor struct {
+	cursors.IntegerArrayCursor
+	res *cursors.FloatArray
+}
+
+func newFloatIntegerMeanArrayCursor(cur cursors.IntegerArrayCursor) *floatIntegerMeanArrayCursor {
+	return &floatIntegerMeanArrayCursor{
+		IntegerArrayCursor: cur,
+		res:                cursors.NewFloatArrayLen(1),
+	}
+}
+
+// Next returns the mean of all values, with the timestamp of the first value.
+func (c *floatIntegerMeanArrayCursor) Next() *cursors.FloatArray {
+	a := c.IntegerArrayCursor.Next()
+	if len(a.Timestamps) == 0 {
+		return &cursors.FloatArray{}
+	}
+
+	ts := a.Timestamps[0]
+	var sum float64
+	var n int
+	for {
+		for _, v := range a.Values {
+			sum += float64(v)
+		}
+		n += len(a.Values)
+		a = c.IntegerArrayCursor.Next()
+		if len(a.Timestamps) == 0 {
+			c.res.Timestamps[0] = ts
+			c.res.Values[0] = sum / float64(n)
+			return c.res
+		}
+	}
+}
+
+type integerIntegerCountArrayCursor struct {
+	cursors.IntegerArrayCursor
+}
+
+func (c *integerIntegerCountArrayCursor) Next() *cursors.IntegerArray {
+	a := c.IntegerArrayCursor.Next()
+	if len(a.Timestamps) == 0 {
+		return &cursors.IntegerArray{}
+	}
+
+	ts := a.Timestamps[0]
+	var acc int64
+	for {
+		acc += int64(len(a.Timestamps))
+		a = c.IntegerArrayCursor.Next()
+		if len(a.Timestamps) == 0 {
+			res := cursors.NewIntegerArrayLen(1)
+			res.Timestamps[0] = ts
+			res.Values[0] = acc
+			return res
+		}
+	}
+}
+
+type integerArrayFirstCursor struct {
+	cursors.IntegerArrayCursor
+	res  *cursors.IntegerArray
+	done bool
+}
+
+func newIntegerArrayFirstCursor(cur cursors.IntegerArrayCursor) *integerArrayFirstCursor {
+	return &integerArrayFirstCursor{
+		IntegerArrayCursor: cur,
+		res:                cursors.NewIntegerArrayLen(1),
+	}
+}
+
+// Next returns the first value of the cursor. The remaining values are never read.
+func (c *integerArrayFirstCursor) Next() *cursors.IntegerArray {
+	if c.done {
+		return &cursors.IntegerArray{}
+	}
+	c.done = true
+
+	a := c.IntegerArrayCursor.Next()
+	if len(a.Timestamps) == 0 {
+		return a
+	}
+
+	c.res.Timestamps[0] = a.Timestamps[0]
+	c.res.Values[0] = a.Values[0]
+	return c.res
+}
+
+type integerArrayLastCursor struct {
+	cursors.IntegerArrayCursor
+	res *cursors.IntegerArray
+}
+
+func newIntegerArrayLastCursor(cur cursors.IntegerArrayCursor) *integerArrayLastCursor {
+	return &integerArrayLastCursor{
+		IntegerArrayCursor: cur,
+		res:                cursors.NewIntegerArrayLen(1),
+	}
+}
+
+// Next returns the last value of the cursor.
+func (c *integerArrayLastCursor) Next() *cursors.IntegerArray {
+	a := c.IntegerArrayCursor.Next()
+	if len(a.Timestamps) == 0 {
+		return a
+	}
+
+	for {
+		i := len(a.Timestamps) - 1
+		c.res.Timestamps[0] = a.Timestamps[i]
+		c.res.Values[0] = a.Values[i]
+		a = c.IntegerArrayCursor.Next()
+		if len(a.Timestamps) == 0 {
+			return c.res
+		}
+	}
+}
+
+// integerArrayWindowReader reads the values of a cursor one window at a time.
+type integerArrayWindowReader struct {
+	cur    cursors.IntegerArrayCursor
+	window window
+	rem    cursors.IntegerArray // values read from cur that have not been returned
+	stop   int64                // the end of the current window
+	res    cursors.IntegerArray
+}
+
+// nextWindow moves to the window of the next value, returning false when there
+// are no more values.
+func (r *integerArrayWindowReader) nextWindow() bool {
+	if r.rem.Len() == 0 {
+		a := r.cur.Next()
+		r.rem.Timestamps, r.rem.Values = a.Timestamps, a.Values
+		if r.rem.Len() == 0 {
+			return false
+		}
+	}
+	r.stop = r.window.stop(r.rem.Timestamps[0])
+	return true
+}
+
+// next returns the next values of the current window, or no values at the
+// end of the window.
+func (r *integerArrayWindowReader) next() *cursors.IntegerArray {
+	if r.rem.Len() == 0 {
+		a := r.cur.Next()
+		r.rem.Timestamps, r.rem.Values = a.Timestamps, a.Values
+	}
+	n := sort.Search(len(r.rem.Timestamps), func(i int) bool {
+		return r.rem.Timestamps[i] >= r.stop
+	})
+	r.res.Timestamps, r.res.Values = r.rem.Timestamps[:n], r.rem.Values[:n]
+	r.rem.Timestamps, r.rem.Values = r.rem.Timestamps[n:], r.rem.Values[n:]
+	return &r.res
+}
+
+// skipWindow moves past the remaining values of the current window. The
+// values are not read if the cursor can seek past them.
+func (r *integerArrayWindowReader) skipWindow() {
+	if r.rem.Len() > 0 && r.rem.Timestamps[r.rem.Len()-1] >= r.stop {
+		r.next()
+		return
+	}
+	r.rem.Timestamps, r.rem.Values = nil, nil
+	if !seek(r.cur, r.stop) {
+		for r.next().Len() > 0 {
+		}
+	}
+}
+
+type integerWindowFirstArrayCursor struct {
+	cursors.IntegerArrayCursor
+	r   integerArrayWindowReader
+	res *cursors.IntegerArray
+}
+
+func newIntegerWindowFirstArrayCursor(cur cursors.IntegerArrayCursor, w window) *integerWindowFirstArrayCursor {
+	return &integerWindowFirstArrayCursor{
+		IntegerArrayCursor: cur,
+		r:                  integerArrayWindowReader{cur: cur, window: w},
+		res:                cursors.NewIntegerArrayLen(MaxPointsPerBlock),
+	}
+}
+
+// Next returns the first value of each window. The remaining values of each
+// window are skipped without being read where possible.
+func (c *integerWindowFirstArrayCursor) Next() *cursors.IntegerArray {
+	c.res.Timestamps, c.res.Values = c.res.Timestamps[:0], c.res.Values[:0]
+	for c.res.Len() < MaxPointsPerBlock && c.r.nextWindow() {
+		a := c.r.next()
+		c.res.Timestamps = append(c.res.Timestamps, a.Timestamps[0])
+		c.res.Values = append(c.res.Values, a.Values[0])
+		c.r.skipWindow()
+	}
+	return c.res
+}
+
+type integerWindowLastArrayCursor struct {
+	cursors.IntegerArrayCursor
+	r   integerArrayWindowReader
+	res *cursors.IntegerArray
+}
+
+func newIntegerWindowLastArrayCursor(cur cursors.IntegerArrayCursor, w window) *integerWindowLastArrayCursor {
+	return &integerWindowLastArrayCursor{
+		IntegerArrayCursor: cur,
+		r:                  integerArrayWindowReader{cur: cur, window: w},
+		res:                cursors.NewIntegerArrayLen(MaxPointsPerBlock),
+	}
+}
+
+// Next returns the last value of each window.
+func (c *integerWindowLastArrayCursor) Next() *cursors.IntegerArray {
+	c.res.Timestamps, c.res.Values = c.res.Timestamps[:0], c.res.Values[:0]
+	for c.res.Len() < MaxPointsPerBlock && c.r.nextWindow() {
+		var ts int64
+		var v int64
+		for a := c.r.next(); a.Len() > 0; a = c.r.next() {
+			ts, v = a.Timestamps[a.Len()-1], a.Values[a.Len()-1]
+		}
+		c.res.Timestamps = append(c.res.Timestamps, ts)
+		c.res.Values = append(c.res.Values, v)
+	}
+	return c.res
+}
+
+type integerIntegerWindowCountArrayCursor struct {
+	cursors.IntegerArrayCursor
+	r   integerArrayWindowReader
+	res *cursors.IntegerArray
+}
+
+func newIntegerIntegerWindowCountArrayCursor(cur cursors.IntegerArrayCursor, w window) *integerIntegerWindowCountArrayCursor {
+	return &integerIntegerWindowCountArrayCursor{
+		IntegerArrayCursor: cur,
+		r:                  integerArrayWindowReader{cur: cur, window: w},
+		res:                cursors.NewIntegerArrayLen(MaxPointsPerBlock),
+	}
+}
+
+// Next returns the number of values of each window, with the timestamp of
+// the first value.
+func (c *integerIntegerWindowCountArrayCursor) Next() *cursors.IntegerArray {
+	c.res.Timestamps, c.res.Values = c.res.Timestamps[:0], c.res.Values[:0]
+	for c.res.Len() < MaxPointsPerBlock && c.r.nextWindow() {
+		a := c.r.next()
+		ts := a.Timestamps[0]
+		var n int64
+		for ; a.Len() > 0; a = c.r.next() {
+			n += int64(a.Len())
+		}
+		c.res.Timestamps = append(c.res.Timestamps, ts)
+		c.res.Values = append(c.res.Values, n)
+	}
+	return c.res
+}
+
+type integerWindowSumArrayCursor struct {
+	cursors.IntegerArrayCursor
+	r   integerArrayWindowReader
+	res *cursors.IntegerArray
+}
+
+func newIntegerWindowSumArrayCursor(cur cursors.IntegerArrayCursor, w window) *integerWindowSumArrayCursor {
+	return &integerWindowSumArrayCursor{
+		IntegerArrayCursor: cur,
+		r:                  integerArrayWindowReader{cur: cur, window: w},
+		res:                cursors.NewIntegerArrayLen(MaxPointsPerBlock),
+	}
+}
+
+// Next returns the sum of the values of each window, with the timestamp of
+// the first value.
+func (c *integerWindowSumArrayCursor) Next() *cursors.IntegerArray {
+	c.res.Timestamps, c.res.Values = c.res.Timestamps[:0], c.res.Values[:0]
+	for c.res.Len() < MaxPointsPerBlock && c.r.nextWindow() {
+		a := c.r.next()
+		ts := a.Timestamps[0]
+		var acc int64
+		for ; a.Len() > 0; a = c.r.next() {
+			for _, v := range a.Values {
+				acc += v
+			}
+		}
+		c.res.Timestamps = append(c.res.Timestamps, ts)
+		c.res.Values = append(c.res.Values, acc)
+	}
+	return c.res
+}
+
+type integerWindowMinArrayCursor struct {
+	cursors.IntegerArrayCursor
+	r   integerArrayWindowReader
+	res *cursors.IntegerArray
+}
+
+func newIntegerWindowMinArrayCursor(cur cursors.IntegerArrayCursor, w window) *integerWindowMinArrayCursor {
+	return &integerWindowMinArrayCursor{
+		IntegerArrayCursor: cur,
+		r:                  integerArrayWindowReader{cur: cur, window: w},
+		res:                cursors.NewIntegerArrayLen(MaxPointsPerBlock),
+	}
+}
+
+// Next returns the smallest value of each window and its timestamp. Of several
+// equal values, the first is returned.
+func (c *integerWindowMinArrayCursor) Next() *cursors.IntegerArray {
+	c.res.Timestamps, c.res.Values = c.res.Timestamps[:0], c.res.Values[:0]
+	for c.res.Len() < MaxPointsPerBlock && c.r.nextWindow() {
+		a := c.r.next()
+		ts, min := a.Timestamps[0], a.Values[0]
+		for ; a.Len() > 0; a = c.r.next() {
+			for i, v := range a.Values {
+				if v < min {
+					ts, min = a.Timestamps[i], v
+				}
+			}
+		}
+		c.res.Timestamps = append(c.res.Timestamps, ts)
+		c.res.Values = append(c.res.Values, min)
+	}
+	return c.res
+}
+
+type integerWindowMaxArrayCursor struct {
+	cursors.IntegerArrayCursor
+	r   integerArrayWindowReader
+	res *cursors.IntegerArray
+}
+
+func newIntegerWindowMaxArrayCursor(cur cursors.IntegerArrayCursor, w window) *integerWindowMaxArrayCursor {
+	return &integerWindowMaxArrayCursor{
+		IntegerArrayCursor: cur,
+		r:                  integerArrayWindowReader{cur: cur, window: w},
+		res:                cursors.NewIntegerArrayLen(MaxPointsPerBlock),
+	}
+}
+
+// Next returns the largest value of each window and its timestamp. Of several
+// equal values, the first is returned.
+func (c *integerWindowMaxArrayCursor) Next() *cursors.IntegerArray {
+	c.res.Timestamps, c.res.Values = c.res.Timestamps[:0], c.res.Values[:0]
+	for c.res.Len() < MaxPointsPerBlock && c.r.nextWindow() {
+		a := c.r.next()
+		ts, max := a.Timestamps[0], a.Values[0]
+		for ; a.Len() > 0; a = c.r.next() {
+			for i, v := range a.Values {
+				if v > max {
+					ts, max = a.Timestamps[i], v
+				}
+			}
+		}
+		c.res.Timestamps = append(c.res.Timestamps, ts)
+		c.res.Values = append(c.res.Values, max)
+	}
+	return c.res
+}
+
+type floatIntegerWindowMeanArrayCursor struct {
+	cursors.IntegerArrayCursor
+	r   integerArrayWindowReader
+	res *cursors.FloatArray
+}
+
+func newFloatIntegerWindowMeanArrayCursor(cur cursors.IntegerArrayCursor, w window) *floatIntegerWindowMeanArrayCursor {
+	return &floatIntegerWindowMeanArrayCursor{
+		IntegerArrayCursor: cur,
+		r:                  integerArrayWindowReader{cur: cur, window: w},
+		res:                cursors.NewFloatArrayLen(MaxPointsPerBlock),
+	}
+}
+
+// Next returns the mean of the values of each window, with the timestamp of
+// the first value.
+func (c *floatIntegerWindowMeanArrayCursor) Next() *cursors.FloatArray {
+	c.res.Timestamps, c.res.Values = c.res.Timestamps[:0], c.res.Values[:0]
+	for c.res.Len() < MaxPointsPerBlock && c.r.nextWindow() {
+		a := c.r.next()
+		ts := a.Timestamps[0]
+		var sum float64
+		var n int
+		for ; a.Len() > 0; a = c.r.next() {
+			for _, v := range a.Values {
+				sum += float64(v)
+			}
+			n += a.Len()
+		}
+		c.res.Timestamps = append(c.res.Timestamps, ts)
+		c.res.Values = append(c.res.Values, sum/float64(n))
+	}
+	return c.res
+}
+
+type integerEmptyArrayCursor struct {
+	res cursors.IntegerArray
+}
+
+var IntegerEmptyArrayCursor cursors.IntegerArrayCursor = &integerEmptyArrayCursor{}
+
+func (c *integerEmptyArrayCursor) Err() error                  { return nil }
+func (c *integerEmptyArrayCursor) Close()                      {}
 func (c *integerEmptyArrayCursor) Next() *cursors.IntegerArray { return &c.res }
 
 // ********************
@@ -515,6 +1436,17 @@ LOOP:
 	return c.res
 }
 
+// SeekTo implements cursors.Seeker, discarding any values read but not yet
+// returned that are before t.
+func (c *unsignedArrayFilterCursor) SeekTo(t int64) {
+	i := sort.Search(len(c.tmp.Timestamps), func(i int) bool {
+		return c.tmp.Timestamps[i] >= t
+	})
+	c.tmp.Timestamps = c.tmp.Timestamps[i:]
+	c.tmp.Values = c.tmp.Values[i:]
+	seek(c.UnsignedArrayCursor, t)
+}
+
 type unsignedMultiShardArrayCursor struct {
 	cursors.UnsignedArrayCursor
 	cursorContext
@@ -558,6 +1490,12 @@ func (c *unsignedMultiShardArrayCursor) Next() *cursors.UnsignedArray {
 	}
 }
 
+// SeekTo implements cursors.Seeker. Values that are skipped do not count
+// towards the points limit.
+func (c *unsignedMultiShardArrayCursor) SeekTo(t int64) {
+	seek(c.UnsignedArrayCursor, t)
+}
+
 func (c *unsignedMultiShardArrayCursor) nextArrayCursor() bool {
 	if len(c.itrs) == 0 {
 		return false
@@ -565,96 +1503,539 @@ func (c *unsignedMultiShardArrayCursor) nextArrayCursor() bool {
 
 	c.UnsignedArrayCursor.Close()
 
-	var itr cursors.CursorIterator
-	var cur cursors.Cursor
-	for cur == nil && len(c.itrs) > 0 {
-		itr, c.itrs = c.itrs[0], c.itrs[1:]
-		cur, _ = itr.Next(c.ctx, c.req)
+	var itr cursors.CursorIterator
+	var cur cursors.Cursor
+	for cur == nil && len(c.itrs) > 0 {
+		itr, c.itrs = c.itrs[0], c.itrs[1:]
+		cur, _ = itr.Next(c.ctx, c.req)
+	}
+
+	var ok bool
+	if cur != nil {
+		var next cursors.UnsignedArrayCursor
+		next, ok = cur.(cursors.UnsignedArrayCursor)
+		if !ok {
+			cur.Close()
+			next = UnsignedEmptyArrayCursor
+			c.itrs = nil
+			c.err = errors.New("expected unsigned cursor")
+		} else {
+			if c.filter != nil {
+				c.filter.reset(next)
+				next = c.filter
+			}
+		}
+		c.UnsignedArrayCursor = next
+	} else {
+		c.UnsignedArrayCursor = UnsignedEmptyArrayCursor
+	}
+
+	return ok
+}
+
+type unsignedArraySumCursor struct {
+	cursors.UnsignedArrayCursor
+	ts  [1]int64
+	vs  [1]uint64
+	res *cursors.UnsignedArray
+}
+
+func newUnsignedArraySumCursor(cur cursors.UnsignedArrayCursor) *unsignedArraySumCursor {
+	return &unsignedArraySumCursor{
+		UnsignedArrayCursor: cur,
+		res:                 &cursors.UnsignedArray{},
+	}
+}
+
+func (c unsignedArraySumCursor) Next() *cursors.UnsignedArray {
+	a := c.UnsignedArrayCursor.Next()
+	if len(a.Timestamps) == 0 {
+		return a
+	}
+
+	ts := a.Timestamps[0]
+	var acc uint64
+
+	for {
+		for _, v := range a.Values {
+			acc += v
+		}
+		a = c.UnsignedArrayCursor.Next()
+		if len(a.Timestamps) == 0 {
+			c.ts[0] = ts
+			c.vs[0] = acc
+			c.res.Timestamps = c.ts[:]
+			c.res.Values = c.vs[:]
+			return c.res
+		}
+	}
+}
+
+type unsignedArrayMinCursor struct {
+	cursors.UnsignedArrayCursor
+	res *cursors.UnsignedArray
+}
+
+func newUnsignedArrayMinCursor(cur cursors.UnsignedArrayCursor) *unsignedArrayMinCursor {
+	return &unsignedArrayMinCursor{
+		UnsignedArrayCursor: cur,
+		res:                 cursors.NewUnsignedArrayLen(1),
+	}
+}
+
+// Next returns the smallest value and its timestamp. Of several equal values,
+// the first is returned.
+func (c *unsignedArrayMinCursor) Next() *cursors.UnsignedArray {
+	a := c.UnsignedArrayCursor.Next()
+	if len(a.Timestamps) == 0 {
+		return a
+	}
+
+	ts, min := a.Timestamps[0], a.Values[0]
+	for {
+		for i, v := range a.Values {
+			if v < min {
+				ts, min = a.Timestamps[i], v
+			}
+		}
+		a = c.UnsignedArrayCursor.Next()
+		if len(a.Timestamps) == 0 {
+			c.res.Timestamps[0] = ts
+			c.res.Values[0] = min
+			return c.res
+		}
+	}
+}
+
+type unsignedArrayMaxCursor struct {
+	cursors.UnsignedArrayCursor
+	res *cursors.UnsignedArray
+}
+
+func newUnsignedArrayMaxCursor(cur cursors.UnsignedArrayCursor) *unsignedArrayMaxCursor {
+	return &unsignedArrayMaxCursor{
+		UnsignedArrayCursor: cur,
+		res:                 cursors.NewUnsignedArrayLen(1),
+	}
+}
+
+// Next returns the largest value and its timestamp. Of several equal values,
+// the first is returned.
+func (c *unsignedArrayMaxCursor) Next() *cursors.UnsignedArray {
+	a := c.UnsignedArrayCursor.Next()
+	if len(a.Timestamps) == 0 {
+		return a
+	}
+
+	ts, max := a.Timestamps[0], a.Values[0]
+	for {
+		for i, v := range a.Values {
+			if v > max {
+				ts, max = a.Timestamps[i], v
+			}
+		}
+		a = c.UnsignedArrayCursor.Next()
+		if len(a.Timestamps) == 0 {
+			c.res.Timestamps[0] = ts
+			c.res.Values[0] = max
+			return c.res
+		}
+	}
+}
+
+type floatUnsignedMeanArrayCursor struct {
+	cursors.UnsignedArrayCursor
+	res *cursors.FloatArray
+}
+
+func newFloatUnsignedMeanArrayCursor(cur cursors.UnsignedArrayCursor) *floatUnsignedMeanArrayCursor {
+	return &floatUnsignedMeanArrayCursor{
+		UnsignedArrayCursor: cur,
+		res:                 cursors.NewFloatArrayLen(1),
+	}
+}
+
+// Next returns the mean of all values, with the timestamp of the first value.
+func (c *floatUnsignedMeanArrayCursor) Next() *cursors.FloatArray {
+	a := c.UnsignedArrayCursor.Next()
+	if len(a.Timestamps) == 0 {
+		return &cursors.FloatArray{}
+	}
+
+	ts := a.Timestamps[0]
+	var sum float64
+	var n int
+	for {
+		for _, v := range a.Values {
+			sum += float64(v)
+		}
+		n += len(a.Values)
+		a = c.UnsignedArrayCursor.Next()
+		if len(a.Timestamps) == 0 {
+			c.res.Timestamps[0] = ts
+			c.res.Values[0] = sum / float64(n)
+			return c.res
+		}
+	}
+}
+
+type integerUnsignedCountArrayCursor struct {
+	cursors.UnsignedArrayCursor
+}
+
+func (c *integerUnsignedCountArrayCursor) Next() *cursors.IntegerArray {
+	a := c.UnsignedArrayCursor.Next()
+	if len(a.Timestamps) == 0 {
+		return &cursors.IntegerArray{}
+	}
+
+	ts := a.Timestamps[0]
+	var acc int64
+	for {
+		acc += int64(len(a.Timestamps))
+		a = c.UnsignedArrayCursor.Next()
+		if len(a.Timestamps) == 0 {
+			res := cursors.NewIntegerArrayLen(1)
+			res.Timestamps[0] = ts
+			res.Values[0] = acc
+			return res
+		}
+	}
+}
+
+type unsignedArrayFirstCursor struct {
+	cursors.UnsignedArrayCursor
+	res  *cursors.UnsignedArray
+	done bool
+}
+
+func newUnsignedArrayFirstCursor(cur cursors.UnsignedArrayCursor) *unsignedArrayFirstCursor {
+	return &unsignedArrayFirstCursor{
+		UnsignedArrayCursor: cur,
+		res:                 cursors.NewUnsignedArrayLen(1),
+	}
+}
+
+// Next returns the first value of the cursor. The remaining values are never read.
+func (c *unsignedArrayFirstCursor) Next() *cursors.UnsignedArray {
+	if c.done {
+		return &cursors.UnsignedArray{}
+	}
+	c.done = true
+
+	a := c.UnsignedArrayCursor.Next()
+	if len(a.Timestamps) == 0 {
+		return a
+	}
+
+	c.res.Timestamps[0] = a.Timestamps[0]
+	c.res.Values[0] = a.Values[0]
+	return c.res
+}
+
+type unsignedArrayLastCursor struct {
+	cursors.UnsignedArrayCursor
+	res *cursors.UnsignedArray
+}
+
+func newUnsignedArrayLastCursor(cur cursors.UnsignedArrayCursor) *unsignedArrayLastCursor {
+	return &unsignedArrayLastCursor{
+		UnsignedArrayCursor: cur,
+		res:                 cursors.NewUnsignedArrayLen(1),
+	}
+}
+
+// Next returns the last value of the cursor.
+func (c *unsignedArrayLastCursor) Next() *cursors.UnsignedArray {
+	a := c.UnsignedArrayCursor.Next()
+	if len(a.Timestamps) == 0 {
+		return a
+	}
+
+	for {
+		i := len(a.Timestamps) - 1
+		c.res.Timestamps[0] = a.Timestamps[i]
+		c.res.Values[0] = a.Values[i]
+		a = c.UnsignedArrayCursor.Next()
+		if len(a.Timestamps) == 0 {
+			return c.res
+		}
+	}
+}
+
+// unsignedArrayWindowReader reads the values of a cursor one window at a time.
+type unsignedArrayWindowReader struct {
+	cur    cursors.UnsignedArrayCursor
+	window window
+	rem    cursors.UnsignedArray // values read from cur that have not been returned
+	stop   int64                 // the end of the current window
+	res    cursors.UnsignedArray
+}
+
+// nextWindow moves to the window of the next value, returning false when there
+// are no more values.
+func (r *unsignedArrayWindowReader) nextWindow() bool {
+	if r.rem.Len() == 0 {
+		a := r.cur.Next()
+		r.rem.Timestamps, r.rem.Values = a.Timestamps, a.Values
+		if r.rem.Len() == 0 {
+			return false
+		}
+	}
+	r.stop = r.window.stop(r.rem.Timestamps[0])
+	return true
+}
+
+// next returns the next values of the current window, or no values at the
+// end of the window.
+func (r *unsignedArrayWindowReader) next() *cursors.UnsignedArray {
+	if r.rem.Len() == 0 {
+		a := r.cur.Next()
+		r.rem.Timestamps, r.rem.Values = a.Timestamps, a.Values
+	}
+	n := sort.Search(len(r.rem.Timestamps), func(i int) bool {
+		return r.rem.Timestamps[i] >= r.stop
+	})
+	r.res.Timestamps, r.res.Values = r.rem.Timestamps[:n], r.rem.Values[:n]
+	r.rem.Timestamps, r.rem.Values = r.rem.Timestamps[n:], r.rem.Values[n:]
+	return &r.res
+}
+
+// skipWindow moves past the remaining values of the current window. The
+// values are not read if the cursor can seek past them.
+func (r *unsignedArrayWindowReader) skipWindow() {
+	if r.rem.Len() > 0 && r.rem.Timestamps[r.rem.Len()-1] >= r.stop {
+		r.next()
+		return
+	}
+	r.rem.Timestamps, r.rem.Values = nil, nil
+	if !seek(r.cur, r.stop) {
+		for r.next().Len() > 0 {
+		}
+	}
+}
+
+type unsignedWindowFirstArrayCursor struct {
+	cursors.UnsignedArrayCursor
+	r   unsignedArrayWindowReader
+	res *cursors.UnsignedArray
+}
+
+func newUnsignedWindowFirstArrayCursor(cur cursors.UnsignedArrayCursor, w window) *unsignedWindowFirstArrayCursor {
+	return &unsignedWindowFirstArrayCursor{
+		UnsignedArrayCursor: cur,
+		r:                   unsignedArrayWindowReader{cur: cur, window: w},
+		res:                 cursors.NewUnsignedArrayLen(MaxPointsPerBlock),
+	}
+}
+
+// Next returns the first value of each window. The remaining values of each
+// window are skipped without being read where possible.
+func (c *unsignedWindowFirstArrayCursor) Next() *cursors.UnsignedArray {
+	c.res.Timestamps, c.res.Values = c.res.Timestamps[:0], c.res.Values[:0]
+	for c.res.Len() < MaxPointsPerBlock && c.r.nextWindow() {
+		a := c.r.next()
+		c.res.Timestamps = append(c.res.Timestamps, a.Timestamps[0])
+		c.res.Values = append(c.res.Values, a.Values[0])
+		c.r.skipWindow()
+	}
+	return c.res
+}
+
+type unsignedWindowLastArrayCursor struct {
+	cursors.UnsignedArrayCursor
+	r   unsignedArrayWindowReader
+	res *cursors.UnsignedArray
+}
+
+func newUnsignedWindowLastArrayCursor(cur cursors.UnsignedArrayCursor, w window) *unsignedWindowLastArrayCursor {
+	return &unsignedWindowLastArrayCursor{
+		UnsignedArrayCursor: cur,
+		r:                   unsignedArrayWindowReader{cur: cur, window: w},
+		res:                 cursors.NewUnsignedArrayLen(MaxPointsPerBlock),
+	}
+}
+
+// Next returns the last value of each window.
+func (c *unsignedWindowLastArrayCursor) Next() *cursors.UnsignedArray {
+	c.res.Timestamps, c.res.Values = c.res.Timestamps[:0], c.res.Values[:0]
+	for c.res.Len() < MaxPointsPerBlock && c.r.nextWindow() {
+		var ts int64
+		var v uint64
+		for a := c.r.next(); a.Len() > 0; a = c.r.next() {
+			ts, v = a.Timestamps[a.Len()-1], a.Values[a.Len()-1]
+		}
+		c.res.Timestamps = append(c.res.Timestamps, ts)
+		c.res.Values = append(c.res.Values, v)
+	}
+	return c.res
+}
+
+type integerUnsignedWindowCountArrayCursor struct {
+	cursors.UnsignedArrayCursor
+	r   unsignedArrayWindowReader
+	res *cursors.IntegerArray
+}
+
+func newIntegerUnsignedWindowCountArrayCursor(cur cursors.UnsignedArrayCursor, w window) *integerUnsignedWindowCountArrayCursor {
+	return &integerUnsignedWindowCountArrayCursor{
+		UnsignedArrayCursor: cur,
+		r:                   unsignedArrayWindowReader{cur: cur, window: w},
+		res:                 cursors.NewIntegerArrayLen(MaxPointsPerBlock),
+	}
+}
+
+// Next returns the number of values of each window, with the timestamp of
+// the first value.
+func (c *integerUnsignedWindowCountArrayCursor) Next() *cursors.IntegerArray {
+	c.res.Timestamps, c.res.Values = c.res.Timestamps[:0], c.res.Values[:0]
+	for c.res.Len() < MaxPointsPerBlock && c.r.nextWindow() {
+		a := c.r.next()
+		ts := a.Timestamps[0]
+		var n int64
+		for ; a.Len() > 0; a = c.r.next() {
+			n += int64(a.Len())
+		}
+		c.res.Timestamps = append(c.res.Timestamps, ts)
+		c.res.Values = append(c.res.Values, n)
+	}
+	return c.res
+}
+
+type unsignedWindowSumArrayCursor struct {
+	cursors.UnsignedArrayCursor
+	r   unsignedArrayWindowReader
+	res *cursors.UnsignedArray
+}
+
+func newUnsignedWindowSumArrayCursor(cur cursors.UnsignedArrayCursor, w window) *unsignedWindowSumArrayCursor {
+	return &unsignedWindowSumArrayCursor{
+		UnsignedArrayCursor: cur,
+		r:                   unsignedArrayWindowReader{cur: cur, window: w},
+		res:                 cursors.NewUnsignedArrayLen(MaxPointsPerBlock),
 	}
+}
 
-	var ok bool
-	if cur != nil {
-		var next cursors.UnsignedArrayCursor
-		next, ok = cur.(cursors.UnsignedArrayCursor)
-		if !ok {
-			cur.Close()
-			next = UnsignedEmptyArrayCursor
-			c.itrs = nil
-			c.err = errors.New("expected unsigned cursor")
-		} else {
-			if c.filter != nil {
-				c.filter.reset(next)
-				next = c.filter
+// Next returns the sum of the values of each window, with the timestamp of
+// the first value.
+func (c *unsignedWindowSumArrayCursor) Next() *cursors.UnsignedArray {
+	c.res.Timestamps, c.res.Values = c.res.Timestamps[:0], c.res.Values[:0]
+	for c.res.Len() < MaxPointsPerBlock && c.r.nextWindow() {
+		a := c.r.next()
+		ts := a.Timestamps[0]
+		var acc uint64
+		for ; a.Len() > 0; a = c.r.next() {
+			for _, v := range a.Values {
+				acc += v
 			}
 		}
-		c.UnsignedArrayCursor = next
-	} else {
-		c.UnsignedArrayCursor = UnsignedEmptyArrayCursor
+		c.res.Timestamps = append(c.res.Timestamps, ts)
+		c.res.Values = append(c.res.Values, acc)
 	}
-
-	return ok
+	return c.res
 }
 
-type unsignedArraySumCursor struct {
+type unsignedWindowMinArrayCursor struct {
 	cursors.UnsignedArrayCursor
-	ts  [1]int64
-	vs  [1]uint64
+	r   unsignedArrayWindowReader
 	res *cursors.UnsignedArray
 }
 
-func newUnsignedArraySumCursor(cur cursors.UnsignedArrayCursor) *unsignedArraySumCursor {
-	return &unsignedArraySumCursor{
+func newUnsignedWindowMinArrayCursor(cur cursors.UnsignedArrayCursor, w window) *unsignedWindowMinArrayCursor {
+	return &unsignedWindowMinArrayCursor{
 		UnsignedArrayCursor: cur,
-		res:                 &cursors.UnsignedArray{},
+		r:                   unsignedArrayWindowReader{cur: cur, window: w},
+		res:                 cursors.NewUnsignedArrayLen(MaxPointsPerBlock),
 	}
 }
 
-func (c unsignedArraySumCursor) Next() *cursors.UnsignedArray {
-	a := c.UnsignedArrayCursor.Next()
-	if len(a.Timestamps) == 0 {
-		return a
+// Next returns the smallest value of each window and its timestamp. Of several
+// equal values, the first is returned.
+func (c *unsignedWindowMinArrayCursor) Next() *cursors.UnsignedArray {
+	c.res.Timestamps, c.res.Values = c.res.Timestamps[:0], c.res.Values[:0]
+	for c.res.Len() < MaxPointsPerBlock && c.r.nextWindow() {
+		a := c.r.next()
+		ts, min := a.Timestamps[0], a.Values[0]
+		for ; a.Len() > 0; a = c.r.next() {
+			for i, v := range a.Values {
+				if v < min {
+					ts, min = a.Timestamps[i], v
+				}
+			}
+		}
+		c.res.Timestamps = append(c.res.Timestamps, ts)
+		c.res.Values = append(c.res.Values, min)
 	}
+	return c.res
+}
 
-	ts := a.Timestamps[0]
-	var acc uint64
+type unsignedWindowMaxArrayCursor struct {
+	cursors.UnsignedArrayCursor
+	r   unsignedArrayWindowReader
+	res *cursors.UnsignedArray
+}
 
-	for {
-		for _, v := range a.Values {
-			acc += v
-		}
-		a = c.UnsignedArrayCursor.Next()
-		if len(a.Timestamps) == 0 {
-			c.ts[0] = ts
-			c.vs[0] = acc
-			c.res.Timestamps = c.ts[:]
-			c.res.Values = c.vs[:]
-			return c.res
+func newUnsignedWindowMaxArrayCursor(cur cursors.UnsignedArrayCursor, w window) *unsignedWindowMaxArrayCursor {
+	return &unsignedWindowMaxArrayCursor{
+		UnsignedArrayCursor: cur,
+		r:                   unsignedArrayWindowReader{cur: cur, window: w},
+		res:                 cursors.NewUnsignedArrayLen(MaxPointsPerBlock),
+	}
+}
+
+// Next returns the largest value of each window and its timestamp. Of several
+// equal values, the first is returned.
+func (c *unsignedWindowMaxArrayCursor) Next() *cursors.UnsignedArray {
+	c.res.Timestamps, c.res.Values = c.res.Timestamps[:0], c.res.Values[:0]
+	for c.res.Len() < MaxPointsPerBlock && c.r.nextWindow() {
+		a := c.r.next()
+		ts, max := a.Timestamps[0], a.Values[0]
+		for ; a.Len() > 0; a = c.r.next() {
+			for i, v := range a.Values {
+				if v > max {
+					ts, max = a.Timestamps[i], v
+				}
+			}
 		}
+		c.res.Timestamps = append(c.res.Timestamps, ts)
+		c.res.Values = append(c.res.Values, max)
 	}
+	return c.res
 }
 
-type integerUnsignedCountArrayCursor struct {
+type floatUnsignedWindowMeanArrayCursor struct {
 	cursors.UnsignedArrayCursor
+	r   unsignedArrayWindowReader
+	res *cursors.FloatArray
 }
 
-func (c *integerUnsignedCountArrayCursor) Next() *cursors.IntegerArray {
-	a := c.UnsignedArrayCursor.Next()
-	if len(a.Timestamps) == 0 {
-		return &cursors.IntegerArray{}
+func newFloatUnsignedWindowMeanArrayCursor(cur cursors.UnsignedArrayCursor, w window) *floatUnsignedWindowMeanArrayCursor {
+	return &floatUnsignedWindowMeanArrayCursor{
+		UnsignedArrayCursor: cur,
+		r:                   unsignedArrayWindowReader{cur: cur, window: w},
+		res:                 cursors.NewFloatArrayLen(MaxPointsPerBlock),
 	}
+}
 
-	ts := a.Timestamps[0]
-	var acc int64
-	for {
-		acc += int64(len(a.Timestamps))
-		a = c.UnsignedArrayCursor.Next()
-		if len(a.Timestamps) == 0 {
-			res := cursors.NewIntegerArrayLen(1)
-			res.Timestamps[0] = ts
-			res.Values[0] = acc
-			return res
+// Next returns the mean of the values of each window, with the timestamp of
+// the first value.
+func (c *floatUnsignedWindowMeanArrayCursor) Next() *cursors.FloatArray {
+	c.res.Timestamps, c.res.Values = c.res.Timestamps[:0], c.res.Values[:0]
+	for c.res.Len() < MaxPointsPerBlock && c.r.nextWindow() {
+		a := c.r.next()
+		ts := a.Timestamps[0]
+		var sum float64
+		var n int
+		for ; a.Len() > 0; a = c.r.next() {
+			for _, v := range a.Values {
+				sum += float64(v)
+			}
+			n += a.Len()
 		}
+		c.res.Timestamps = append(c.res.Timestamps, ts)
+		c.res.Values = append(c.res.Values, sum/float64(n))
 	}
+	return c.res
 }
 
 type unsignedEmptyArrayCursor struct {
@@ -731,6 +2112,17 @@ LOOP:
 	return c.res
 }
 
+// SeekTo implements cursors.Seeker, discarding any values read but not yet
+// returned that are before t.
+func (c *stringArrayFilterCursor) SeekTo(t int64) {
+	i := sort.Search(len(c.tmp.Timestamps), func(i int) bool {
+		return c.tmp.Timestamps[i] >= t
+	})
+	c.tmp.Timestamps = c.tmp.Timestamps[i:]
+	c.tmp.Values = c.tmp.Values[i:]
+	seek(c.StringArrayCursor, t)
+}
+
 type stringMultiShardArrayCursor struct {
 	cursors.StringArrayCursor
 	cursorContext
@@ -774,6 +2166,12 @@ func (c *stringMultiShardArrayCursor) Next() *cursors.StringArray {
 	}
 }
 
+// SeekTo implements cursors.Seeker. Values that are skipped do not count
+// towards the points limit.
+func (c *stringMultiShardArrayCursor) SeekTo(t int64) {
+	seek(c.StringArrayCursor, t)
+}
+
 func (c *stringMultiShardArrayCursor) nextArrayCursor() bool {
 	if len(c.itrs) == 0 {
 		return false
@@ -835,6 +2233,205 @@ func (c *integerStringCountArrayCursor) Next() *cursors.IntegerArray {
 	}
 }
 
+type stringArrayFirstCursor struct {
+	cursors.StringArrayCursor
+	res  *cursors.StringArray
+	done bool
+}
+
+func newStringArrayFirstCursor(cur cursors.StringArrayCursor) *stringArrayFirstCursor {
+	return &stringArrayFirstCursor{
+		StringArrayCursor: cur,
+		res:               cursors.NewStringArrayLen(1),
+	}
+}
+
+// Next returns the first value of the cursor. The remaining values are never read.
+func (c *stringArrayFirstCursor) Next() *cursors.StringArray {
+	if c.done {
+		return &cursors.StringArray{}
+	}
+	c.done = true
+
+	a := c.StringArrayCursor.Next()
+	if len(a.Timestamps) == 0 {
+		return a
+	}
+
+	c.res.Timestamps[0] = a.Timestamps[0]
+	c.res.Values[0] = a.Values[0]
+	return c.res
+}
+
+type stringArrayLastCursor struct {
+	cursors.StringArrayCursor
+	res *cursors.StringArray
+}
+
+func newStringArrayLastCursor(cur cursors.StringArrayCursor) *stringArrayLastCursor {
+	return &stringArrayLastCursor{
+		StringArrayCursor: cur,
+		res:               cursors.NewStringArrayLen(1),
+	}
+}
+
+// Next returns the last value of the cursor.
+func (c *stringArrayLastCursor) Next() *cursors.StringArray {
+	a := c.StringArrayCursor.Next()
+	if len(a.Timestamps) == 0 {
+		return a
+	}
+
+	for {
+		i := len(a.Timestamps) - 1
+		c.res.Timestamps[0] = a.Timestamps[i]
+		c.res.Values[0] = a.Values[i]
+		a = c.StringArrayCursor.Next()
+		if len(a.Timestamps) == 0 {
+			return c.res
+		}
+	}
+}
+
+// stringArrayWindowReader reads the values of a cursor one window at a time.
+type stringArrayWindowReader struct {
+	cur    cursors.StringArrayCursor
+	window window
+	rem    cursors.StringArray // values read from cur that have not been returned
+	stop   int64               // the end of the current window
+	res    cursors.StringArray
+}
+
+// nextWindow moves to the window of the next value, returning false when there
+// are no more values.
+func (r *stringArrayWindowReader) nextWindow() bool {
+	if r.rem.Len() == 0 {
+		a := r.cur.Next()
+		r.rem.Timestamps, r.rem.Values = a.Timestamps, a.Values
+		if r.rem.Len() == 0 {
+			return false
+		}
+	}
+	r.stop = r.window.stop(r.rem.Timestamps[0])
+	return true
+}
+
+// next returns the next values of the current window, or no values at the
+// end of the window.
+func (r *stringArrayWindowReader) next() *cursors.StringArray {
+	if r.rem.Len() == 0 {
+		a := r.cur.Next()
+		r.rem.Timestamps, r.rem.Values = a.Timestamps, a.Values
+	}
+	n := sort.Search(len(r.rem.Timestamps), func(i int) bool {
+		return r.rem.Timestamps[i] >= r.stop
+	})
+	r.res.Timestamps, r.res.Values = r.rem.Timestamps[:n], r.rem.Values[:n]
+	r.rem.Timestamps, r.rem.Values = r.rem.Timestamps[n:], r.rem.Values[n:]
+	return &r.res
+}
+
+// skipWindow moves past the remaining values of the current window. The
+// values are not read if the cursor can seek past them.
+func (r *stringArrayWindowReader) skipWindow() {
+	if r.rem.Len() > 0 && r.rem.Timestamps[r.rem.Len()-1] >= r.stop {
+		r.next()
+		return
+	}
+	r.rem.Timestamps, r.rem.Values = nil, nil
+	if !seek(r.cur, r.stop) {
+		for r.next().Len() > 0 {
+		}
+	}
+}
+
+type stringWindowFirstArrayCursor struct {
+	cursors.StringArrayCursor
+	r   stringArrayWindowReader
+	res *cursors.StringArray
+}
+
+func newStringWindowFirstArrayCursor(cur cursors.StringArrayCursor, w window) *stringWindowFirstArrayCursor {
+	return &stringWindowFirstArrayCursor{
+		StringArrayCursor: cur,
+		r:                 stringArrayWindowReader{cur: cur, window: w},
+		res:               cursors.NewStringArrayLen(MaxPointsPerBlock),
+	}
+}
+
+// Next returns the first value of each window. The remaining values of each
+// window are skipped without being read where possible.
+func (c *stringWindowFirstArrayCursor) Next() *cursors.StringArray {
+	c.res.Timestamps, c.res.Values = c.res.Timestamps[:0], c.res.Values[:0]
+	for c.res.Len() < MaxPointsPerBlock && c.r.nextWindow() {
+		a := c.r.next()
+		c.res.Timestamps = append(c.res.Timestamps, a.Timestamps[0])
+		c.res.Values = append(c.res.Values, a.Values[0])
+		c.r.skipWindow()
+	}
+	return c.res
+}
+
+type stringWindowLastArrayCursor struct {
+	cursors.StringArrayCursor
+	r   stringArrayWindowReader
+	res *cursors.StringArray
+}
+
+func newStringWindowLastArrayCursor(cur cursors.StringArrayCursor, w window) *stringWindowLastArrayCursor {
+	return &stringWindowLastArrayCursor{
+		StringArrayCursor: cur,
+		r:                 stringArrayWindowReader{cur: cur, window: w},
+		res:               cursors.NewStringArrayLen(MaxPointsPerBlock),
+	}
+}
+
+// Next returns the last value of each window.
+func (c *stringWindowLastArrayCursor) Next() *cursors.StringArray {
+	c.res.Timestamps, c.res.Values = c.res.Timestamps[:0], c.res.Values[:0]
+	for c.res.Len() < MaxPointsPerBlock && c.r.nextWindow() {
+		var ts int64
+		var v string
+		for a := c.r.next(); a.Len() > 0; a = c.r.next() {
+			ts, v = a.Timestamps[a.Len()-1], a.Values[a.Len()-1]
+		}
+		c.res.Timestamps = append(c.res.Timestamps, ts)
+		c.res.Values = append(c.res.Values, v)
+	}
+	return c.res
+}
+
+type integerStringWindowCountArrayCursor struct {
+	cursors.StringArrayCursor
+	r   stringArrayWindowReader
+	res *cursors.IntegerArray
+}
+
+func newIntegerStringWindowCountArrayCursor(cur cursors.StringArrayCursor, w window) *integerStringWindowCountArrayCursor {
+	return &integerStringWindowCountArrayCursor{
+		StringArrayCursor: cur,
+		r:                 stringArrayWindowReader{cur: cur, window: w},
+		res:               cursors.NewIntegerArrayLen(MaxPointsPerBlock),
+	}
+}
+
+// Next returns the number of values of each window, with the timestamp of
+// the first value.
+func (c *integerStringWindowCountArrayCursor) Next() *cursors.IntegerArray {
+	c.res.Timestamps, c.res.Values = c.res.Timestamps[:0], c.res.Values[:0]
+	for c.res.Len() < MaxPointsPerBlock && c.r.nextWindow() {
+		a := c.r.next()
+		ts := a.Timestamps[0]
+		var n int64
+		for ; a.Len() > 0; a = c.r.next() {
+			n += int64(a.Len())
+		}
+		c.res.Timestamps = append(c.res.Timestamps, ts)
+		c.res.Values = append(c.res.Values, n)
+	}
+	return c.res
+}
+
 type stringEmptyArrayCursor struct {
 	res cursors.StringArray
 }
@@ -909,6 +2506,17 @@ LOOP:
 	return c.res
 }
 
+// SeekTo implements cursors.Seeker, discarding any values read but not yet
+// returned that are before t.
+func (c *booleanArrayFilterCursor) SeekTo(t int64) {
+	i := sort.Search(len(c.tmp.Timestamps), func(i int) bool {
+		return c.tmp.Timestamps[i] >= t
+	})
+	c.tmp.Timestamps = c.tmp.Timestamps[i:]
+	c.tmp.Values = c.tmp.Values[i:]
+	seek(c.BooleanArrayCursor, t)
+}
+
 type booleanMultiShardArrayCursor struct {
 	cursors.BooleanArrayCursor
 	cursorContext
@@ -952,6 +2560,12 @@ func (c *booleanMultiShardArrayCursor) Next() *cursors.BooleanArray {
 	}
 }
 
+// SeekTo implements cursors.Seeker. Values that are skipped do not count
+// towards the points limit.
+func (c *booleanMultiShardArrayCursor) SeekTo(t int64) {
+	seek(c.BooleanArrayCursor, t)
+}
+
 func (c *booleanMultiShardArrayCursor) nextArrayCursor() bool {
 	if len(c.itrs) == 0 {
 		return false
@@ -1013,6 +2627,205 @@ func (c *integerBooleanCountArrayCursor) Next() *cursors.IntegerArray {
 	}
 }
 
+type booleanArrayFirstCursor struct {
+	cursors.BooleanArrayCursor
+	res  *cursors.BooleanArray
+	done bool
+}
+
+func newBooleanArrayFirstCursor(cur cursors.BooleanArrayCursor) *booleanArrayFirstCursor {
+	return &booleanArrayFirstCursor{
+		BooleanArrayCursor: cur,
+		res:                cursors.NewBooleanArrayLen(1),
+	}
+}
+
+// Next returns the first value of the cursor. The remaining values are never read.
+func (c *booleanArrayFirstCursor) Next() *cursors.BooleanArray {
+	if c.done {
+		return &cursors.BooleanArray{}
+	}
+	c.done = true
+
+	a := c.BooleanArrayCursor.Next()
+	if len(a.Timestamps) == 0 {
+		return a
+	}
+
+	c.res.Timestamps[0] = a.Timestamps[0]
+	c.res.Values[0] = a.Values[0]
+	return c.res
+}
+
+type booleanArrayLastCursor struct {
+	cursors.BooleanArrayCursor
+	res *cursors.BooleanArray
+}
+
+func newBooleanArrayLastCursor(cur cursors.BooleanArrayCursor) *booleanArrayLastCursor {
+	return &booleanArrayLastCursor{
+		BooleanArrayCursor: cur,
+		res:                cursors.NewBooleanArrayLen(1),
+	}
+}
+
+// Next returns the last value of the cursor.
+func (c *booleanArrayLastCursor) Next() *cursors.BooleanArray {
+	a := c.BooleanArrayCursor.Next()
+	if len(a.Timestamps) == 0 {
+		return a
+	}
+
+	for {
+		i := len(a.Timestamps) - 1
+		c.res.Timestamps[0] = a.Timestamps[i]
+		c.res.Values[0] = a.Values[i]
+		a = c.BooleanArrayCursor.Next()
+		if len(a.Timestamps) == 0 {
+			return c.res
+		}
+	}
+}
+
+// booleanArrayWindowReader reads the values of a cursor one window at a time.
+type booleanArrayWindowReader struct {
+	cur    cursors.BooleanArrayCursor
+	window window
+	rem    cursors.BooleanArray // values read from cur that have not been returned
+	stop   int64                // the end of the current window
+	res    cursors.BooleanArray
+}
+
+// nextWindow moves to the window of the next value, returning false when there
+// are no more values.
+func (r *booleanArrayWindowReader) nextWindow() bool {
+	if r.rem.Len() == 0 {
+		a := r.cur.Next()
+		r.rem.Timestamps, r.rem.Values = a.Timestamps, a.Values
+		if r.rem.Len() == 0 {
+			return false
+		}
+	}
+	r.stop = r.window.stop(r.rem.Timestamps[0])
+	return true
+}
+
+// next returns the next values of the current window, or no values at the
+// end of the window.
+func (r *booleanArrayWindowReader) next() *cursors.BooleanArray {
+	if r.rem.Len() == 0 {
+		a := r.cur.Next()
+		r.rem.Timestamps, r.rem.Values = a.Timestamps, a.Values
+	}
+	n := sort.Search(len(r.rem.Timestamps), func(i int) bool {
+		return r.rem.Timestamps[i] >= r.stop
+	})
+	r.res.Timestamps, r.res.Values = r.rem.Timestamps[:n], r.rem.Values[:n]
+	r.rem.Timestamps, r.rem.Values = r.rem.Timestamps[n:], r.rem.Values[n:]
+	return &r.res
+}
+
+// skipWindow moves past the remaining values of the current window. The
+// values are not read if the cursor can seek past them.
+func (r *booleanArrayWindowReader) skipWindow() {
+	if r.rem.Len() > 0 && r.rem.Timestamps[r.rem.Len()-1] >= r.stop {
+		r.next()
+		return
+	}
+	r.rem.Timestamps, r.rem.Values = nil, nil
+	if !seek(r.cur, r.stop) {
+		for r.next().Len() > 0 {
+		}
+	}
+}
+
+type booleanWindowFirstArrayCursor struct {
+	cursors.BooleanArrayCursor
+	r   booleanArrayWindowReader
+	res *cursors.BooleanArray
+}
+
+func newBooleanWindowFirstArrayCursor(cur cursors.BooleanArrayCursor, w window) *booleanWindowFirstArrayCursor {
+	return &booleanWindowFirstArrayCursor{
+		BooleanArrayCursor: cur,
+		r:                  booleanArrayWindowReader{cur: cur, window: w},
+		res:                cursors.NewBooleanArrayLen(MaxPointsPerBlock),
+	}
+}
+
+// Next returns the first value of each window. The remaining values of each
+// window are skipped without being read where possible.
+func (c *booleanWindowFirstArrayCursor) Next() *cursors.BooleanArray {
+	c.res.Timestamps, c.res.Values = c.res.Timestamps[:0], c.res.Values[:0]
+	for c.res.Len() < MaxPointsPerBlock && c.r.nextWindow() {
+		a := c.r.next()
+		c.res.Timestamps = append(c.res.Timestamps, a.Timestamps[0])
+		c.res.Values = append(c.res.Values, a.Values[0])
+		c.r.skipWindow()
+	}
+	return c.res
+}
+
+type booleanWindowLastArrayCursor struct {
+	cursors.BooleanArrayCursor
+	r   booleanArrayWindowReader
+	res *cursors.BooleanArray
+}
+
+func newBooleanWindowLastArrayCursor(cur cursors.BooleanArrayCursor, w window) *booleanWindowLastArrayCursor {
+	return &booleanWindowLastArrayCursor{
+		BooleanArrayCursor: cur,
+		r:                  booleanArrayWindowReader{cur: cur, window: w},
+		res:                cursors.NewBooleanArrayLen(MaxPointsPerBlock),
+	}
+}
+
+// Next returns the last value of each window.
+func (c *booleanWindowLastArrayCursor) Next() *cursors.BooleanArray {
+	c.res.Timestamps, c.res.Values = c.res.Timestamps[:0], c.res.Values[:0]
+	for c.res.Len() < MaxPointsPerBlock && c.r.nextWindow() {
+		var ts int64
+		var v bool
+		for a := c.r.next(); a.Len() > 0; a = c.r.next() {
+			ts, v = a.Timestamps[a.Len()-1], a.Values[a.Len()-1]
+		}
+		c.res.Timestamps = append(c.res.Timestamps, ts)
+		c.res.Values = append(c.res.Values, v)
+	}
+	return c.res
+}
+
+type integerBooleanWindowCountArrayCursor struct {
+	cursors.BooleanArrayCursor
+	r   booleanArrayWindowReader
+	res *cursors.IntegerArray
+}
+
+func newIntegerBooleanWindowCountArrayCursor(cur cursors.BooleanArrayCursor, w window) *integerBooleanWindowCountArrayCursor {
+	return &integerBooleanWindowCountArrayCursor{
+		BooleanArrayCursor: cur,
+		r:                  booleanArrayWindowReader{cur: cur, window: w},
+		res:                cursors.NewIntegerArrayLen(MaxPointsPerBlock),
+	}
+}
+
+// Next returns the number of values of each window, with the timestamp of
+// the first value.
+func (c *integerBooleanWindowCountArrayCursor) Next() *cursors.IntegerArray {
+	c.res.Timestamps, c.res.Values = c.res.Timestamps[:0], c.res.Values[:0]
+	for c.res.Len() < MaxPointsPerBlock && c.r.nextWindow() {
+		a := c.r.next()
+		ts := a.Timestamps[0]
+		var n int64
+		for ; a.Len() > 0; a = c.r.next() {
+			n += int64(a.Len())
+		}
+		c.res.Timestamps = append(c.res.Timestamps, ts)
+		c.res.Values = append(c.res.Values, n)
+	}
+	return c.res
+}
+
 type booleanEmptyArrayCursor struct {
 	res cursors.BooleanArray
 }
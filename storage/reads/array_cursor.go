@@ -3,6 +3,7 @@ package reads
 import (
 	"context"
 	"fmt"
+	"math"
 
 	"github.com/influxdata/platform/storage/reads/datatypes"
 	"github.com/influxdata/platform/tsdb/cursors"
@@ -16,16 +17,30 @@ func (v *singleValue) Value(key string) (interface{}, bool) {
 	return v.v, true
 }
 
-func newAggregateArrayCursor(ctx context.Context, agg *datatypes.Aggregate, cursor cursors.Cursor) cursors.Cursor {
+func newAggregateArrayCursor(ctx context.Context, agg *datatypes.Aggregate, win *datatypes.Window, cursor cursors.Cursor) cursors.Cursor {
 	if cursor == nil {
 		return nil
 	}
 
+	if win != nil && win.Every > 0 {
+		return newWindowAggregateArrayCursor(agg, newWindow(win), cursor)
+	}
+
 	switch agg.Type {
 	case datatypes.AggregateTypeSum:
 		return newSumArrayCursor(cursor)
 	case datatypes.AggregateTypeCount:
 		return newCountArrayCursor(cursor)
+	case datatypes.AggregateTypeMin:
+		return newMinArrayCursor(cursor)
+	case datatypes.AggregateTypeMax:
+		return newMaxArrayCursor(cursor)
+	case datatypes.AggregateTypeFirst:
+		return newFirstArrayCursor(cursor)
+	case datatypes.AggregateTypeLast:
+		return newLastArrayCursor(cursor)
+	case datatypes.AggregateTypeMean:
+		return newMeanArrayCursor(cursor)
 	default:
 		// TODO(sgc): should be validated higher up
 		panic("invalid aggregate")
@@ -63,6 +78,206 @@ func newCountArrayCursor(cur cursors.Cursor) cursors.Cursor {
 	}
 }
 
+// The min, max and mean cursors only support numeric values. The cursors of other types
+// are returned unchanged, so that all of their values are read.
+
+func newMinArrayCursor(cur cursors.Cursor) cursors.Cursor {
+	switch cur := cur.(type) {
+	case cursors.FloatArrayCursor:
+		return newFloatArrayMinCursor(cur)
+	case cursors.IntegerArrayCursor:
+		return newIntegerArrayMinCursor(cur)
+	case cursors.UnsignedArrayCursor:
+		return newUnsignedArrayMinCursor(cur)
+	default:
+		return cur
+	}
+}
+
+func newMaxArrayCursor(cur cursors.Cursor) cursors.Cursor {
+	switch cur := cur.(type) {
+	case cursors.FloatArrayCursor:
+		return newFloatArrayMaxCursor(cur)
+	case cursors.IntegerArrayCursor:
+		return newIntegerArrayMaxCursor(cur)
+	case cursors.UnsignedArrayCursor:
+		return newUnsignedArrayMaxCursor(cur)
+	default:
+		return cur
+	}
+}
+
+func newMeanArrayCursor(cur cursors.Cursor) cursors.Cursor {
+	switch cur := cur.(type) {
+	case cursors.FloatArrayCursor:
+		return newFloatFloatMeanArrayCursor(cur)
+	case cursors.IntegerArrayCursor:
+		return newFloatIntegerMeanArrayCursor(cur)
+	case cursors.UnsignedArrayCursor:
+		return newFloatUnsignedMeanArrayCursor(cur)
+	default:
+		return cur
+	}
+}
+
+func newFirstArrayCursor(cur cursors.Cursor) cursors.Cursor {
+	switch cur := cur.(type) {
+	case cursors.FloatArrayCursor:
+		return newFloatArrayFirstCursor(cur)
+	case cursors.IntegerArrayCursor:
+		return newIntegerArrayFirstCursor(cur)
+	case cursors.UnsignedArrayCursor:
+		return newUnsignedArrayFirstCursor(cur)
+	case cursors.StringArrayCursor:
+		return newStringArrayFirstCursor(cur)
+	case cursors.BooleanArrayCursor:
+		return newBooleanArrayFirstCursor(cur)
+	default:
+		panic(fmt.Sprintf("unreachable: %T", cur))
+	}
+}
+
+func newLastArrayCursor(cur cursors.Cursor) cursors.Cursor {
+	switch cur := cur.(type) {
+	case cursors.FloatArrayCursor:
+		return newFloatArrayLastCursor(cur)
+	case cursors.IntegerArrayCursor:
+		return newIntegerArrayLastCursor(cur)
+	case cursors.UnsignedArrayCursor:
+		return newUnsignedArrayLastCursor(cur)
+	case cursors.StringArrayCursor:
+		return newStringArrayLastCursor(cur)
+	case cursors.BooleanArrayCursor:
+		return newBooleanArrayLastCursor(cur)
+	default:
+		panic(fmt.Sprintf("unreachable: %T", cur))
+	}
+}
+
+// window divides time into windows of every nanoseconds, starting offset
+// nanoseconds after the Unix epoch.
+type window struct {
+	every, offset int64
+}
+
+func newWindow(win *datatypes.Window) window {
+	offset := win.Offset % win.Every
+	if offset < 0 {
+		offset += win.Every
+	}
+	return window{every: win.Every, offset: offset}
+}
+
+// stop returns the end, exclusive, of the window that contains t.
+func (w window) stop(t int64) int64 {
+	r := (t - w.offset) % w.every
+	if r < 0 {
+		r += w.every
+	}
+	start := t - r
+	if start > math.MaxInt64-w.every {
+		return math.MaxInt64
+	}
+	return start + w.every
+}
+
+// seek moves cur to the first value at or after t if it implements
+// cursors.Seeker, and reports whether it did.
+func seek(cur cursors.Cursor, t int64) bool {
+	s, ok := cur.(cursors.Seeker)
+	if ok {
+		s.SeekTo(t)
+	}
+	return ok
+}
+
+// newWindowAggregateArrayCursor returns a cursor of the aggregate of each window of cursor.
+// As with newAggregateArrayCursor, cursors of values that cannot be aggregated are
+// returned unchanged.
+func newWindowAggregateArrayCursor(agg *datatypes.Aggregate, w window, cursor cursors.Cursor) cursors.Cursor {
+	switch agg.Type {
+	case datatypes.AggregateTypeCount:
+		switch cur := cursor.(type) {
+		case cursors.FloatArrayCursor:
+			return newIntegerFloatWindowCountArrayCursor(cur, w)
+		case cursors.IntegerArrayCursor:
+			return newIntegerIntegerWindowCountArrayCursor(cur, w)
+		case cursors.UnsignedArrayCursor:
+			return newIntegerUnsignedWindowCountArrayCursor(cur, w)
+		case cursors.StringArrayCursor:
+			return newIntegerStringWindowCountArrayCursor(cur, w)
+		case cursors.BooleanArrayCursor:
+			return newIntegerBooleanWindowCountArrayCursor(cur, w)
+		}
+	case datatypes.AggregateTypeFirst:
+		switch cur := cursor.(type) {
+		case cursors.FloatArrayCursor:
+			return newFloatWindowFirstArrayCursor(cur, w)
+		case cursors.IntegerArrayCursor:
+			return newIntegerWindowFirstArrayCursor(cur, w)
+		case cursors.UnsignedArrayCursor:
+			return newUnsignedWindowFirstArrayCursor(cur, w)
+		case cursors.StringArrayCursor:
+			return newStringWindowFirstArrayCursor(cur, w)
+		case cursors.BooleanArrayCursor:
+			return newBooleanWindowFirstArrayCursor(cur, w)
+		}
+	case datatypes.AggregateTypeLast:
+		switch cur := cursor.(type) {
+		case cursors.FloatArrayCursor:
+			return newFloatWindowLastArrayCursor(cur, w)
+		case cursors.IntegerArrayCursor:
+			return newIntegerWindowLastArrayCursor(cur, w)
+		case cursors.UnsignedArrayCursor:
+			return newUnsignedWindowLastArrayCursor(cur, w)
+		case cursors.StringArrayCursor:
+			return newStringWindowLastArrayCursor(cur, w)
+		case cursors.BooleanArrayCursor:
+			return newBooleanWindowLastArrayCursor(cur, w)
+		}
+	case datatypes.AggregateTypeSum:
+		switch cur := cursor.(type) {
+		case cursors.FloatArrayCursor:
+			return newFloatWindowSumArrayCursor(cur, w)
+		case cursors.IntegerArrayCursor:
+			return newIntegerWindowSumArrayCursor(cur, w)
+		case cursors.UnsignedArrayCursor:
+			return newUnsignedWindowSumArrayCursor(cur, w)
+		}
+	case datatypes.AggregateTypeMin:
+		switch cur := cursor.(type) {
+		case cursors.FloatArrayCursor:
+			return newFloatWindowMinArrayCursor(cur, w)
+		case cursors.IntegerArrayCursor:
+			return newIntegerWindowMinArrayCursor(cur, w)
+		case cursors.UnsignedArrayCursor:
+			return newUnsignedWindowMinArrayCursor(cur, w)
+		}
+	case datatypes.AggregateTypeMax:
+		switch cur := cursor.(type) {
+		case cursors.FloatArrayCursor:
+			return newFloatWindowMaxArrayCursor(cur, w)
+		case cursors.IntegerArrayCursor:
+			return newIntegerWindowMaxArrayCursor(cur, w)
+		case cursors.UnsignedArrayCursor:
+			return newUnsignedWindowMaxArrayCursor(cur, w)
+		}
+	case datatypes.AggregateTypeMean:
+		switch cur := cursor.(type) {
+		case cursors.FloatArrayCursor:
+			return newFloatFloatWindowMeanArrayCursor(cur, w)
+		case cursors.IntegerArrayCursor:
+			return newFloatIntegerWindowMeanArrayCursor(cur, w)
+		case cursors.UnsignedArrayCursor:
+			return newFloatUnsignedWindowMeanArrayCursor(cur, w)
+		}
+	default:
+		// TODO(sgc): should be validated higher up
+		panic("invalid aggregate")
+	}
+	return cursor
+}
+
 type cursorContext struct {
 	ctx   context.Context
 	req   *cursors.CursorRequest
@@ -158,6 +373,6 @@ func (m *multiShardArrayCursors) createCursor(row SeriesRow) cursors.Cursor {
 	}
 }
 
-func (m *multiShardArrayCursors) newAggregateCursor(ctx context.Context, agg *datatypes.Aggregate, cursor cursors.Cursor) cursors.Cursor {
-	return newAggregateArrayCursor(ctx, agg, cursor)
+func (m *multiShardArrayCursors) newAggregateCursor(ctx context.Context, agg *datatypes.Aggregate, win *datatypes.Window, cursor cursors.Cursor) cursors.Cursor {
+	return newAggregateArrayCursor(ctx, agg, win, cursor)
 }
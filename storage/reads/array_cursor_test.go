@@ -0,0 +1,211 @@
+package reads
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/platform/storage/reads/datatypes"
+	"github.com/influxdata/platform/tsdb/cursors"
+)
+
+// integerArrayCursor returns each of its arrays in turn.
+type integerArrayCursor struct {
+	arrays []*cursors.IntegerArray
+}
+
+func (c *integerArrayCursor) Close()     {}
+func (c *integerArrayCursor) Err() error { return nil }
+
+func (c *integerArrayCursor) Next() *cursors.IntegerArray {
+	if len(c.arrays) == 0 {
+		return &cursors.IntegerArray{}
+	}
+	a := c.arrays[0]
+	c.arrays = c.arrays[1:]
+	return a
+}
+
+func TestNewAggregateArrayCursor(t *testing.T) {
+	newCursor := func() cursors.Cursor {
+		return &integerArrayCursor{arrays: []*cursors.IntegerArray{
+			{Timestamps: []int64{1, 2, 3}, Values: []int64{4, 1, 6}},
+			{Timestamps: []int64{4, 5}, Values: []int64{6, 1}},
+		}}
+	}
+
+	tests := []struct {
+		agg  datatypes.Aggregate_AggregateType
+		want interface{}
+	}{
+		{
+			agg:  datatypes.AggregateTypeMin,
+			want: &cursors.IntegerArray{Timestamps: []int64{2}, Values: []int64{1}},
+		},
+		{
+			agg:  datatypes.AggregateTypeMax,
+			want: &cursors.IntegerArray{Timestamps: []int64{3}, Values: []int64{6}},
+		},
+		{
+			agg:  datatypes.AggregateTypeFirst,
+			want: &cursors.IntegerArray{Timestamps: []int64{1}, Values: []int64{4}},
+		},
+		{
+			agg:  datatypes.AggregateTypeLast,
+			want: &cursors.IntegerArray{Timestamps: []int64{5}, Values: []int64{1}},
+		},
+		{
+			agg:  datatypes.AggregateTypeMean,
+			want: &cursors.FloatArray{Timestamps: []int64{1}, Values: []float64{3.6}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.agg.String(), func(t *testing.T) {
+			cur := newAggregateArrayCursor(context.Background(), &datatypes.Aggregate{Type: tt.agg}, nil, newCursor())
+
+			var got, next interface{}
+			switch cur := cur.(type) {
+			case cursors.IntegerArrayCursor:
+				got, next = cur.Next(), cur.Next()
+				if len(next.(*cursors.IntegerArray).Timestamps) != 0 {
+					t.Errorf("unexpected values after aggregate: %v", next)
+				}
+			case cursors.FloatArrayCursor:
+				got, next = cur.Next(), cur.Next()
+				if len(next.(*cursors.FloatArray).Timestamps) != 0 {
+					t.Errorf("unexpected values after aggregate: %v", next)
+				}
+			default:
+				t.Fatalf("unexpected cursor type %T", cur)
+			}
+
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("unexpected aggregate, -want/+got:\n%v", cmp.Diff(tt.want, got))
+			}
+		})
+	}
+}
+
+// seekingIntegerArrayCursor is an integerArrayCursor that records the times it seeks to.
+type seekingIntegerArrayCursor struct {
+	integerArrayCursor
+	seeks []int64
+}
+
+func (c *seekingIntegerArrayCursor) SeekTo(t int64) {
+	c.seeks = append(c.seeks, t)
+	for len(c.arrays) > 0 {
+		a := c.arrays[0]
+		i := 0
+		for i < len(a.Timestamps) && a.Timestamps[i] < t {
+			i++
+		}
+		if i < len(a.Timestamps) {
+			c.arrays[0] = &cursors.IntegerArray{Timestamps: a.Timestamps[i:], Values: a.Values[i:]}
+			return
+		}
+		c.arrays = c.arrays[1:]
+	}
+}
+
+func TestNewWindowAggregateArrayCursor(t *testing.T) {
+	newCursor := func() *seekingIntegerArrayCursor {
+		return &seekingIntegerArrayCursor{integerArrayCursor: integerArrayCursor{arrays: []*cursors.IntegerArray{
+			{Timestamps: []int64{1, 2, 3}, Values: []int64{4, 1, 6}},
+			{Timestamps: []int64{4, 5}, Values: []int64{6, 1}},
+			{Timestamps: []int64{12, 13}, Values: []int64{3, 8}},
+			{Timestamps: []int64{25}, Values: []int64{2}},
+		}}}
+	}
+
+	tests := []struct {
+		name   string
+		agg    datatypes.Aggregate_AggregateType
+		offset int64
+		want   interface{}
+	}{
+		{
+			name: "min",
+			agg:  datatypes.AggregateTypeMin,
+			want: &cursors.IntegerArray{Timestamps: []int64{2, 12, 25}, Values: []int64{1, 3, 2}},
+		},
+		{
+			name: "max",
+			agg:  datatypes.AggregateTypeMax,
+			want: &cursors.IntegerArray{Timestamps: []int64{3, 13, 25}, Values: []int64{6, 8, 2}},
+		},
+		{
+			name: "first",
+			agg:  datatypes.AggregateTypeFirst,
+			want: &cursors.IntegerArray{Timestamps: []int64{1, 12, 25}, Values: []int64{4, 3, 2}},
+		},
+		{
+			name:   "first with offset",
+			agg:    datatypes.AggregateTypeFirst,
+			offset: 5,
+			want:   &cursors.IntegerArray{Timestamps: []int64{1, 5, 25}, Values: []int64{4, 1, 2}},
+		},
+		{
+			name: "last",
+			agg:  datatypes.AggregateTypeLast,
+			want: &cursors.IntegerArray{Timestamps: []int64{5, 13, 25}, Values: []int64{1, 8, 2}},
+		},
+		{
+			name: "count",
+			agg:  datatypes.AggregateTypeCount,
+			want: &cursors.IntegerArray{Timestamps: []int64{1, 12, 25}, Values: []int64{5, 2, 1}},
+		},
+		{
+			name: "sum",
+			agg:  datatypes.AggregateTypeSum,
+			want: &cursors.IntegerArray{Timestamps: []int64{1, 12, 25}, Values: []int64{18, 11, 2}},
+		},
+		{
+			name: "mean",
+			agg:  datatypes.AggregateTypeMean,
+			want: &cursors.FloatArray{Timestamps: []int64{1, 12, 25}, Values: []float64{3.6, 5.5, 2}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			win := &datatypes.Window{Every: 10, Offset: tt.offset}
+			cur := newAggregateArrayCursor(context.Background(), &datatypes.Aggregate{Type: tt.agg}, win, newCursor())
+
+			var got interface{}
+			switch cur := cur.(type) {
+			case cursors.IntegerArrayCursor:
+				got = cur.Next()
+			case cursors.FloatArrayCursor:
+				got = cur.Next()
+			default:
+				t.Fatalf("unexpected cursor type %T", cur)
+			}
+
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("unexpected aggregate, -want/+got:\n%v", cmp.Diff(tt.want, got))
+			}
+		})
+	}
+}
+
+func TestWindowFirstArrayCursor_Seek(t *testing.T) {
+	cur := &seekingIntegerArrayCursor{integerArrayCursor: integerArrayCursor{arrays: []*cursors.IntegerArray{
+		{Timestamps: []int64{1, 2}, Values: []int64{1, 2}},
+		{Timestamps: []int64{3, 12}, Values: []int64{3, 4}},
+		{Timestamps: []int64{13, 14}, Values: []int64{5, 6}},
+	}}}
+
+	first := newIntegerWindowFirstArrayCursor(cur, window{every: 10})
+	want := &cursors.IntegerArray{Timestamps: []int64{1, 12}, Values: []int64{1, 4}}
+	if got := first.Next(); !cmp.Equal(got, want) {
+		t.Errorf("unexpected values, -want/+got:\n%v", cmp.Diff(want, got))
+	}
+
+	// Once the first value of a window is read, the cursor seeks past the
+	// remaining values of the window rather than reading them.
+	if got, want := cur.seeks, []int64{10, 20}; !cmp.Equal(got, want) {
+		t.Errorf("unexpected seeks, -want/+got:\n%v", cmp.Diff(want, got))
+	}
+}
@@ -59,7 +59,7 @@ func (x ReadRequest_Group) String() string {
 	return proto.EnumName(ReadRequest_Group_name, int32(x))
 }
 func (ReadRequest_Group) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_storage_common_01b6ac29b3fb8162, []int{0, 0}
+	return fileDescriptor_storage_common_357365d0971b9daa, []int{0, 0}
 }
 
 type ReadRequest_HintFlags int32
@@ -89,7 +89,7 @@ func (x ReadRequest_HintFlags) String() string {
 	return proto.EnumName(ReadRequest_HintFlags_name, int32(x))
 }
 func (ReadRequest_HintFlags) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_storage_common_01b6ac29b3fb8162, []int{0, 1}
+	return fileDescriptor_storage_common_357365d0971b9daa, []int{0, 1}
 }
 
 type Aggregate_AggregateType int32
@@ -98,24 +98,39 @@ const (
 	AggregateTypeNone  Aggregate_AggregateType = 0
 	AggregateTypeSum   Aggregate_AggregateType = 1
 	AggregateTypeCount Aggregate_AggregateType = 2
+	AggregateTypeMin   Aggregate_AggregateType = 3
+	AggregateTypeMax   Aggregate_AggregateType = 4
+	AggregateTypeFirst Aggregate_AggregateType = 5
+	AggregateTypeLast  Aggregate_AggregateType = 6
+	AggregateTypeMean  Aggregate_AggregateType = 7
 )
 
 var Aggregate_AggregateType_name = map[int32]string{
 	0: "NONE",
 	1: "SUM",
 	2: "COUNT",
+	3: "MIN",
+	4: "MAX",
+	5: "FIRST",
+	6: "LAST",
+	7: "MEAN",
 }
 var Aggregate_AggregateType_value = map[string]int32{
 	"NONE":  0,
 	"SUM":   1,
 	"COUNT": 2,
+	"MIN":   3,
+	"MAX":   4,
+	"FIRST": 5,
+	"LAST":  6,
+	"MEAN":  7,
 }
 
 func (x Aggregate_AggregateType) String() string {
 	return proto.EnumName(Aggregate_AggregateType_name, int32(x))
 }
 func (Aggregate_AggregateType) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_storage_common_01b6ac29b3fb8162, []int{1, 0}
+	return fileDescriptor_storage_common_357365d0971b9daa, []int{1, 0}
 }
 
 type ReadResponse_FrameType int32
@@ -138,7 +153,7 @@ func (x ReadResponse_FrameType) String() string {
 	return proto.EnumName(ReadResponse_FrameType_name, int32(x))
 }
 func (ReadResponse_FrameType) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_storage_common_01b6ac29b3fb8162, []int{3, 0}
+	return fileDescriptor_storage_common_357365d0971b9daa, []int{4, 0}
 }
 
 type ReadResponse_DataType int32
@@ -170,7 +185,7 @@ func (x ReadResponse_DataType) String() string {
 	return proto.EnumName(ReadResponse_DataType_name, int32(x))
 }
 func (ReadResponse_DataType) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_storage_common_01b6ac29b3fb8162, []int{3, 1}
+	return fileDescriptor_storage_common_357365d0971b9daa, []int{4, 1}
 }
 
 // Request message for Storage.Read.
@@ -187,6 +202,9 @@ type ReadRequest struct {
 	// Aggregate specifies an optional aggregate to apply to the data.
 	// TODO(sgc): switch to slice for multiple aggregates in a single request
 	Aggregate *Aggregate `protobuf:"bytes,9,opt,name=aggregate" json:"aggregate,omitempty"`
+	// Window specifies an optional window to which Aggregate is applied, producing
+	// a value for each window of each series rather than a single value.
+	Window    *Window    `protobuf:"bytes,14,opt,name=window" json:"window,omitempty"`
 	Predicate *Predicate `protobuf:"bytes,5,opt,name=predicate" json:"predicate,omitempty"`
 	// SeriesLimit determines the maximum number of series to be returned for the request. Specify 0 for no limit.
 	SeriesLimit int64 `protobuf:"varint,6,opt,name=series_limit,json=seriesLimit,proto3" json:"series_limit,omitempty"`
@@ -208,7 +226,7 @@ func (m *ReadRequest) Reset()         { *m = ReadRequest{} }
 func (m *ReadRequest) String() string { return proto.CompactTextString(m) }
 func (*ReadRequest) ProtoMessage()    {}
 func (*ReadRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_storage_common_01b6ac29b3fb8162, []int{0}
+	return fileDescriptor_storage_common_357365d0971b9daa, []int{0}
 }
 func (m *ReadRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -247,7 +265,7 @@ func (m *Aggregate) Reset()         { *m = Aggregate{} }
 func (m *Aggregate) String() string { return proto.CompactTextString(m) }
 func (*Aggregate) ProtoMessage()    {}
 func (*Aggregate) Descriptor() ([]byte, []int) {
-	return fileDescriptor_storage_common_01b6ac29b3fb8162, []int{1}
+	return fileDescriptor_storage_common_357365d0971b9daa, []int{1}
 }
 func (m *Aggregate) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -276,6 +294,48 @@ func (m *Aggregate) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_Aggregate proto.InternalMessageInfo
 
+type Window struct {
+	// Every is the duration of each window, in nanoseconds.
+	Every int64 `protobuf:"varint,1,opt,name=every,proto3" json:"every,omitempty"`
+	// Offset shifts the start of the windows from the Unix epoch, in nanoseconds.
+	Offset               int64    `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Window) Reset()         { *m = Window{} }
+func (m *Window) String() string { return proto.CompactTextString(m) }
+func (*Window) ProtoMessage()    {}
+func (*Window) Descriptor() ([]byte, []int) {
+	return fileDescriptor_storage_common_357365d0971b9daa, []int{2}
+}
+func (m *Window) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *Window) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_Window.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (dst *Window) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Window.Merge(dst, src)
+}
+func (m *Window) XXX_Size() int {
+	return m.Size()
+}
+func (m *Window) XXX_DiscardUnknown() {
+	xxx_messageInfo_Window.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Window proto.InternalMessageInfo
+
 type Tag struct {
 	Key                  []byte   `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
 	Value                []byte   `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
@@ -287,7 +347,7 @@ func (m *Tag) Reset()         { *m = Tag{} }
 func (m *Tag) String() string { return proto.CompactTextString(m) }
 func (*Tag) ProtoMessage()    {}
 func (*Tag) Descriptor() ([]byte, []int) {
-	return fileDescriptor_storage_common_01b6ac29b3fb8162, []int{2}
+	return fileDescriptor_storage_common_357365d0971b9daa, []int{3}
 }
 func (m *Tag) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -327,7 +387,7 @@ func (m *ReadResponse) Reset()         { *m = ReadResponse{} }
 func (m *ReadResponse) String() string { return proto.CompactTextString(m) }
 func (*ReadResponse) ProtoMessage()    {}
 func (*ReadResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_storage_common_01b6ac29b3fb8162, []int{3}
+	return fileDescriptor_storage_common_357365d0971b9daa, []int{4}
 }
 func (m *ReadResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -374,7 +434,7 @@ func (m *ReadResponse_Frame) Reset()         { *m = ReadResponse_Frame{} }
 func (m *ReadResponse_Frame) String() string { return proto.CompactTextString(m) }
 func (*ReadResponse_Frame) ProtoMessage()    {}
 func (*ReadResponse_Frame) Descriptor() ([]byte, []int) {
-	return fileDescriptor_storage_common_01b6ac29b3fb8162, []int{3, 0}
+	return fileDescriptor_storage_common_357365d0971b9daa, []int{4, 0}
 }
 func (m *ReadResponse_Frame) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -677,7 +737,7 @@ func (m *ReadResponse_GroupFrame) Reset()         { *m = ReadResponse_GroupFrame
 func (m *ReadResponse_GroupFrame) String() string { return proto.CompactTextString(m) }
 func (*ReadResponse_GroupFrame) ProtoMessage()    {}
 func (*ReadResponse_GroupFrame) Descriptor() ([]byte, []int) {
-	return fileDescriptor_storage_common_01b6ac29b3fb8162, []int{3, 1}
+	return fileDescriptor_storage_common_357365d0971b9daa, []int{4, 1}
 }
 func (m *ReadResponse_GroupFrame) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -717,7 +777,7 @@ func (m *ReadResponse_SeriesFrame) Reset()         { *m = ReadResponse_SeriesFra
 func (m *ReadResponse_SeriesFrame) String() string { return proto.CompactTextString(m) }
 func (*ReadResponse_SeriesFrame) ProtoMessage()    {}
 func (*ReadResponse_SeriesFrame) Descriptor() ([]byte, []int) {
-	return fileDescriptor_storage_common_01b6ac29b3fb8162, []int{3, 2}
+	return fileDescriptor_storage_common_357365d0971b9daa, []int{4, 2}
 }
 func (m *ReadResponse_SeriesFrame) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -757,7 +817,7 @@ func (m *ReadResponse_FloatPointsFrame) Reset()         { *m = ReadResponse_Floa
 func (m *ReadResponse_FloatPointsFrame) String() string { return proto.CompactTextString(m) }
 func (*ReadResponse_FloatPointsFrame) ProtoMessage()    {}
 func (*ReadResponse_FloatPointsFrame) Descriptor() ([]byte, []int) {
-	return fileDescriptor_storage_common_01b6ac29b3fb8162, []int{3, 3}
+	return fileDescriptor_storage_common_357365d0971b9daa, []int{4, 3}
 }
 func (m *ReadResponse_FloatPointsFrame) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -797,7 +857,7 @@ func (m *ReadResponse_IntegerPointsFrame) Reset()         { *m = ReadResponse_In
 func (m *ReadResponse_IntegerPointsFrame) String() string { return proto.CompactTextString(m) }
 func (*ReadResponse_IntegerPointsFrame) ProtoMessage()    {}
 func (*ReadResponse_IntegerPointsFrame) Descriptor() ([]byte, []int) {
-	return fileDescriptor_storage_common_01b6ac29b3fb8162, []int{3, 4}
+	return fileDescriptor_storage_common_357365d0971b9daa, []int{4, 4}
 }
 func (m *ReadResponse_IntegerPointsFrame) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -837,7 +897,7 @@ func (m *ReadResponse_UnsignedPointsFrame) Reset()         { *m = ReadResponse_U
 func (m *ReadResponse_UnsignedPointsFrame) String() string { return proto.CompactTextString(m) }
 func (*ReadResponse_UnsignedPointsFrame) ProtoMessage()    {}
 func (*ReadResponse_UnsignedPointsFrame) Descriptor() ([]byte, []int) {
-	return fileDescriptor_storage_common_01b6ac29b3fb8162, []int{3, 5}
+	return fileDescriptor_storage_common_357365d0971b9daa, []int{4, 5}
 }
 func (m *ReadResponse_UnsignedPointsFrame) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -877,7 +937,7 @@ func (m *ReadResponse_BooleanPointsFrame) Reset()         { *m = ReadResponse_Bo
 func (m *ReadResponse_BooleanPointsFrame) String() string { return proto.CompactTextString(m) }
 func (*ReadResponse_BooleanPointsFrame) ProtoMessage()    {}
 func (*ReadResponse_BooleanPointsFrame) Descriptor() ([]byte, []int) {
-	return fileDescriptor_storage_common_01b6ac29b3fb8162, []int{3, 6}
+	return fileDescriptor_storage_common_357365d0971b9daa, []int{4, 6}
 }
 func (m *ReadResponse_BooleanPointsFrame) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -917,7 +977,7 @@ func (m *ReadResponse_StringPointsFrame) Reset()         { *m = ReadResponse_Str
 func (m *ReadResponse_StringPointsFrame) String() string { return proto.CompactTextString(m) }
 func (*ReadResponse_StringPointsFrame) ProtoMessage()    {}
 func (*ReadResponse_StringPointsFrame) Descriptor() ([]byte, []int) {
-	return fileDescriptor_storage_common_01b6ac29b3fb8162, []int{3, 7}
+	return fileDescriptor_storage_common_357365d0971b9daa, []int{4, 7}
 }
 func (m *ReadResponse_StringPointsFrame) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -956,7 +1016,7 @@ func (m *CapabilitiesResponse) Reset()         { *m = CapabilitiesResponse{} }
 func (m *CapabilitiesResponse) String() string { return proto.CompactTextString(m) }
 func (*CapabilitiesResponse) ProtoMessage()    {}
 func (*CapabilitiesResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_storage_common_01b6ac29b3fb8162, []int{4}
+	return fileDescriptor_storage_common_357365d0971b9daa, []int{5}
 }
 func (m *CapabilitiesResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -994,7 +1054,7 @@ func (m *HintsResponse) Reset()         { *m = HintsResponse{} }
 func (m *HintsResponse) String() string { return proto.CompactTextString(m) }
 func (*HintsResponse) ProtoMessage()    {}
 func (*HintsResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_storage_common_01b6ac29b3fb8162, []int{5}
+	return fileDescriptor_storage_common_357365d0971b9daa, []int{6}
 }
 func (m *HintsResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1037,7 +1097,7 @@ func (m *TimestampRange) Reset()         { *m = TimestampRange{} }
 func (m *TimestampRange) String() string { return proto.CompactTextString(m) }
 func (*TimestampRange) ProtoMessage()    {}
 func (*TimestampRange) Descriptor() ([]byte, []int) {
-	return fileDescriptor_storage_common_01b6ac29b3fb8162, []int{6}
+	return fileDescriptor_storage_common_357365d0971b9daa, []int{7}
 }
 func (m *TimestampRange) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1070,6 +1130,7 @@ func init() {
 	proto.RegisterType((*ReadRequest)(nil), "influxdata.platform.storage.ReadRequest")
 	proto.RegisterMapType((map[string]string)(nil), "influxdata.platform.storage.ReadRequest.TraceEntry")
 	proto.RegisterType((*Aggregate)(nil), "influxdata.platform.storage.Aggregate")
+	proto.RegisterType((*Window)(nil), "influxdata.platform.storage.Window")
 	proto.RegisterType((*Tag)(nil), "influxdata.platform.storage.Tag")
 	proto.RegisterType((*ReadResponse)(nil), "influxdata.platform.storage.ReadResponse")
 	proto.RegisterType((*ReadResponse_Frame)(nil), "influxdata.platform.storage.ReadResponse.Frame")
@@ -1382,6 +1443,16 @@ func (m *ReadRequest) MarshalTo(dAtA []byte) (int, error) {
 		}
 		i += n4
 	}
+	if m.Window != nil {
+		dAtA[i] = 0x72
+		i++
+		i = encodeVarintStorageCommon(dAtA, i, uint64(m.Window.Size()))
+		n5, err := m.Window.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n5
+	}
 	return i, nil
 }
 
@@ -1408,6 +1479,34 @@ func (m *Aggregate) MarshalTo(dAtA []byte) (int, error) {
 	return i, nil
 }
 
+func (m *Window) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Window) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Every != 0 {
+		dAtA[i] = 0x8
+		i++
+		i = encodeVarintStorageCommon(dAtA, i, uint64(m.Every))
+	}
+	if m.Offset != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintStorageCommon(dAtA, i, uint64(m.Offset))
+	}
+	return i, nil
+}
+
 func (m *Tag) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
@@ -1484,11 +1583,11 @@ func (m *ReadResponse_Frame) MarshalTo(dAtA []byte) (int, error) {
 	var l int
 	_ = l
 	if m.Data != nil {
-		nn5, err := m.Data.MarshalTo(dAtA[i:])
+		nn6, err := m.Data.MarshalTo(dAtA[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += nn5
+		i += nn6
 	}
 	return i, nil
 }
@@ -1499,11 +1598,11 @@ func (m *ReadResponse_Frame_Series) MarshalTo(dAtA []byte) (int, error) {
 		dAtA[i] = 0xa
 		i++
 		i = encodeVarintStorageCommon(dAtA, i, uint64(m.Series.Size()))
-		n6, err := m.Series.MarshalTo(dAtA[i:])
+		n7, err := m.Series.MarshalTo(dAtA[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n6
+		i += n7
 	}
 	return i, nil
 }
@@ -1513,11 +1612,11 @@ func (m *ReadResponse_Frame_FloatPoints) MarshalTo(dAtA []byte) (int, error) {
 		dAtA[i] = 0x12
 		i++
 		i = encodeVarintStorageCommon(dAtA, i, uint64(m.FloatPoints.Size()))
-		n7, err := m.FloatPoints.MarshalTo(dAtA[i:])
+		n8, err := m.FloatPoints.MarshalTo(dAtA[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n7
+		i += n8
 	}
 	return i, nil
 }
@@ -1527,11 +1626,11 @@ func (m *ReadResponse_Frame_IntegerPoints) MarshalTo(dAtA []byte) (int, error) {
 		dAtA[i] = 0x1a
 		i++
 		i = encodeVarintStorageCommon(dAtA, i, uint64(m.IntegerPoints.Size()))
-		n8, err := m.IntegerPoints.MarshalTo(dAtA[i:])
+		n9, err := m.IntegerPoints.MarshalTo(dAtA[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n8
+		i += n9
 	}
 	return i, nil
 }
@@ -1541,11 +1640,11 @@ func (m *ReadResponse_Frame_UnsignedPoints) MarshalTo(dAtA []byte) (int, error)
 		dAtA[i] = 0x22
 		i++
 		i = encodeVarintStorageCommon(dAtA, i, uint64(m.UnsignedPoints.Size()))
-		n9, err := m.UnsignedPoints.MarshalTo(dAtA[i:])
+		n10, err := m.UnsignedPoints.MarshalTo(dAtA[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n9
+		i += n10
 	}
 	return i, nil
 }
@@ -1555,11 +1654,11 @@ func (m *ReadResponse_Frame_BooleanPoints) MarshalTo(dAtA []byte) (int, error) {
 		dAtA[i] = 0x2a
 		i++
 		i = encodeVarintStorageCommon(dAtA, i, uint64(m.BooleanPoints.Size()))
-		n10, err := m.BooleanPoints.MarshalTo(dAtA[i:])
+		n11, err := m.BooleanPoints.MarshalTo(dAtA[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n10
+		i += n11
 	}
 	return i, nil
 }
@@ -1569,11 +1668,11 @@ func (m *ReadResponse_Frame_StringPoints) MarshalTo(dAtA []byte) (int, error) {
 		dAtA[i] = 0x32
 		i++
 		i = encodeVarintStorageCommon(dAtA, i, uint64(m.StringPoints.Size()))
-		n11, err := m.StringPoints.MarshalTo(dAtA[i:])
+		n12, err := m.StringPoints.MarshalTo(dAtA[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n11
+		i += n12
 	}
 	return i, nil
 }
@@ -1583,11 +1682,11 @@ func (m *ReadResponse_Frame_Group) MarshalTo(dAtA []byte) (int, error) {
 		dAtA[i] = 0x3a
 		i++
 		i = encodeVarintStorageCommon(dAtA, i, uint64(m.Group.Size()))
-		n12, err := m.Group.MarshalTo(dAtA[i:])
+		n13, err := m.Group.MarshalTo(dAtA[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n12
+		i += n13
 	}
 	return i, nil
 }
@@ -1689,8 +1788,8 @@ func (m *ReadResponse_FloatPointsFrame) MarshalTo(dAtA []byte) (int, error) {
 		i++
 		i = encodeVarintStorageCommon(dAtA, i, uint64(len(m.Values)*8))
 		for _, num := range m.Values {
-			f13 := math.Float64bits(float64(num))
-			encoding_binary.LittleEndian.PutUint64(dAtA[i:], uint64(f13))
+			f14 := math.Float64bits(float64(num))
+			encoding_binary.LittleEndian.PutUint64(dAtA[i:], uint64(f14))
 			i += 8
 		}
 	}
@@ -1722,22 +1821,22 @@ func (m *ReadResponse_IntegerPointsFrame) MarshalTo(dAtA []byte) (int, error) {
 		}
 	}
 	if len(m.Values) > 0 {
-		dAtA15 := make([]byte, len(m.Values)*10)
-		var j14 int
+		dAtA16 := make([]byte, len(m.Values)*10)
+		var j15 int
 		for _, num1 := range m.Values {
 			num := uint64(num1)
 			for num >= 1<<7 {
-				dAtA15[j14] = uint8(uint64(num)&0x7f | 0x80)
+				dAtA16[j15] = uint8(uint64(num)&0x7f | 0x80)
 				num >>= 7
-				j14++
+				j15++
 			}
-			dAtA15[j14] = uint8(num)
-			j14++
+			dAtA16[j15] = uint8(num)
+			j15++
 		}
 		dAtA[i] = 0x12
 		i++
-		i = encodeVarintStorageCommon(dAtA, i, uint64(j14))
-		i += copy(dAtA[i:], dAtA15[:j14])
+		i = encodeVarintStorageCommon(dAtA, i, uint64(j15))
+		i += copy(dAtA[i:], dAtA16[:j15])
 	}
 	return i, nil
 }
@@ -1767,21 +1866,21 @@ func (m *ReadResponse_UnsignedPointsFrame) MarshalTo(dAtA []byte) (int, error) {
 		}
 	}
 	if len(m.Values) > 0 {
-		dAtA17 := make([]byte, len(m.Values)*10)
-		var j16 int
+		dAtA18 := make([]byte, len(m.Values)*10)
+		var j17 int
 		for _, num := range m.Values {
 			for num >= 1<<7 {
-				dAtA17[j16] = uint8(uint64(num)&0x7f | 0x80)
+				dAtA18[j17] = uint8(uint64(num)&0x7f | 0x80)
 				num >>= 7
-				j16++
+				j17++
 			}
-			dAtA17[j16] = uint8(num)
-			j16++
+			dAtA18[j17] = uint8(num)
+			j17++
 		}
 		dAtA[i] = 0x12
 		i++
-		i = encodeVarintStorageCommon(dAtA, i, uint64(j16))
-		i += copy(dAtA[i:], dAtA17[:j16])
+		i = encodeVarintStorageCommon(dAtA, i, uint64(j17))
+		i += copy(dAtA[i:], dAtA18[:j17])
 	}
 	return i, nil
 }
@@ -2007,6 +2106,10 @@ func (m *ReadRequest) Size() (n int) {
 		l = m.ReadSource.Size()
 		n += 1 + l + sovStorageCommon(uint64(l))
 	}
+	if m.Window != nil {
+		l = m.Window.Size()
+		n += 1 + l + sovStorageCommon(uint64(l))
+	}
 	return n
 }
 
@@ -2019,6 +2122,18 @@ func (m *Aggregate) Size() (n int) {
 	return n
 }
 
+func (m *Window) Size() (n int) {
+	var l int
+	_ = l
+	if m.Every != 0 {
+		n += 1 + sovStorageCommon(uint64(m.Every))
+	}
+	if m.Offset != 0 {
+		n += 1 + sovStorageCommon(uint64(m.Offset))
+	}
+	return n
+}
+
 func (m *Tag) Size() (n int) {
 	var l int
 	_ = l
@@ -2677,6 +2792,39 @@ func (m *ReadRequest) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 14:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Window", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStorageCommon
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthStorageCommon
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Window == nil {
+				m.Window = &Window{}
+			}
+			if err := m.Window.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipStorageCommon(dAtA[iNdEx:])
@@ -2767,6 +2915,94 @@ func (m *Aggregate) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *Window) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowStorageCommon
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Window: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Window: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Every", wireType)
+			}
+			m.Every = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStorageCommon
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Every |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Offset", wireType)
+			}
+			m.Offset = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStorageCommon
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Offset |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipStorageCommon(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthStorageCommon
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func (m *Tag) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
@@ -4585,106 +4821,112 @@ var (
 )
 
 func init() {
-	proto.RegisterFile("storage_common.proto", fileDescriptor_storage_common_01b6ac29b3fb8162)
-}
-
-var fileDescriptor_storage_common_01b6ac29b3fb8162 = []byte{
-	// 1539 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x57, 0x5f, 0x8f, 0x2a, 0x49,
-	0x15, 0xa7, 0xf9, 0xcf, 0xe1, 0xcf, 0xf4, 0xad, 0xc5, 0x09, 0xdb, 0xd7, 0x85, 0x5e, 0x62, 0x36,
-	0xa8, 0x2b, 0xa3, 0xec, 0x6e, 0xbc, 0xb9, 0xea, 0x03, 0xcc, 0x70, 0x07, 0x9c, 0x19, 0x98, 0x14,
-	0x8c, 0x71, 0x4d, 0x0c, 0xa9, 0x81, 0xa2, 0xb7, 0xb3, 0x4d, 0x77, 0xdb, 0xdd, 0x6c, 0x86, 0xc4,
-	0x77, 0x37, 0x3c, 0x5d, 0x5f, 0x4d, 0x48, 0x4c, 0x7c, 0xf4, 0xdd, 0xcf, 0x70, 0xdf, 0xf4, 0x13,
-	0x10, 0xc5, 0x0f, 0x61, 0xe2, 0x93, 0xa9, 0xaa, 0x6e, 0x68, 0x66, 0xae, 0x13, 0x78, 0xab, 0x3a,
-	0x7f, 0x7e, 0xbf, 0x53, 0xa7, 0xcf, 0x39, 0x55, 0x0d, 0x45, 0xd7, 0xb3, 0x1c, 0xa2, 0xd1, 0xd1,
-	0xd8, 0x9a, 0xcd, 0x2c, 0xb3, 0x6e, 0x3b, 0x96, 0x67, 0xa1, 0x97, 0xba, 0x39, 0x35, 0xe6, 0x0f,
-	0x13, 0xe2, 0x91, 0xba, 0x6d, 0x10, 0x6f, 0x6a, 0x39, 0xb3, 0xba, 0x6f, 0xa9, 0x14, 0x35, 0x4b,
-	0xb3, 0xb8, 0xdd, 0x19, 0x5b, 0x09, 0x17, 0xe5, 0xa5, 0x66, 0x59, 0x9a, 0x41, 0xcf, 0xf8, 0xee,
-	0x7e, 0x3e, 0x3d, 0xa3, 0x33, 0xdb, 0x5b, 0xf8, 0xca, 0x0f, 0x1f, 0x2b, 0x89, 0x19, 0xa8, 0x4e,
-	0x6c, 0x87, 0x4e, 0xf4, 0x31, 0xf1, 0xa8, 0x10, 0x54, 0xff, 0x93, 0x86, 0x2c, 0xa6, 0x64, 0x82,
-	0xe9, 0xef, 0xe6, 0xd4, 0xf5, 0x90, 0x01, 0x27, 0x9e, 0x3e, 0xa3, 0xae, 0x47, 0x66, 0xf6, 0xc8,
-	0x21, 0xa6, 0x46, 0x4b, 0x51, 0x55, 0xaa, 0x65, 0x1b, 0x3f, 0xac, 0x3f, 0x13, 0x65, 0x7d, 0x18,
-	0xf8, 0x60, 0xe6, 0xd2, 0x3a, 0x7d, 0xb7, 0xae, 0x44, 0x36, 0xeb, 0x4a, 0x61, 0x5f, 0x8e, 0x0b,
-	0xde, 0xde, 0x1e, 0x95, 0x01, 0x26, 0xd4, 0x1d, 0x53, 0x73, 0xa2, 0x9b, 0x5a, 0x29, 0xa6, 0x4a,
-	0xb5, 0x34, 0x0e, 0x49, 0xd0, 0xa7, 0x00, 0x9a, 0x63, 0xcd, 0xed, 0xd1, 0xd7, 0x74, 0xe1, 0x96,
-	0xe2, 0x6a, 0xac, 0x96, 0x69, 0xe5, 0x37, 0xeb, 0x4a, 0xe6, 0x92, 0x49, 0xaf, 0xe8, 0xc2, 0xc5,
-	0x19, 0x2d, 0x58, 0xa2, 0x0b, 0xc8, 0x6c, 0x8f, 0x57, 0x4a, 0xf0, 0xa8, 0x3f, 0x79, 0x36, 0xea,
-	0xdb, 0xc0, 0x1a, 0xef, 0x1c, 0x51, 0x03, 0x72, 0x2e, 0x75, 0x74, 0xea, 0x8e, 0x0c, 0x7d, 0xa6,
-	0x7b, 0xa5, 0xa4, 0x2a, 0xd5, 0x62, 0xad, 0x93, 0xcd, 0xba, 0x92, 0x1d, 0x70, 0xf9, 0x35, 0x13,
-	0xe3, 0xac, 0xbb, 0xdb, 0xa0, 0x2f, 0x20, 0xef, 0xfb, 0x58, 0xd3, 0xa9, 0x4b, 0xbd, 0x52, 0x8a,
-	0x3b, 0xc9, 0x9b, 0x75, 0x25, 0x27, 0x9c, 0xfa, 0x5c, 0x8e, 0x7d, 0x68, 0xb1, 0x63, 0x54, 0xb6,
-	0xa5, 0x9b, 0x5e, 0x40, 0x95, 0xde, 0x51, 0xdd, 0x72, 0xb9, 0x4f, 0x65, 0xef, 0x36, 0xec, 0x90,
-	0x44, 0xd3, 0x1c, 0xaa, 0xb1, 0x43, 0x66, 0x0e, 0x38, 0x64, 0x33, 0xb0, 0xc6, 0x3b, 0x47, 0x34,
-	0x84, 0x84, 0xe7, 0x90, 0x31, 0x2d, 0x81, 0x1a, 0xab, 0x65, 0x1b, 0x9f, 0x3d, 0x8b, 0x10, 0xaa,
-	0x8f, 0xfa, 0x90, 0x79, 0xb5, 0x4d, 0xcf, 0x59, 0xb4, 0x32, 0x9b, 0x75, 0x25, 0xc1, 0xf7, 0x58,
-	0x80, 0xa1, 0x0b, 0x48, 0xf0, 0xaf, 0x51, 0xca, 0xaa, 0x52, 0xad, 0xd0, 0xa8, 0x1f, 0x8c, 0xca,
-	0x3f, 0x27, 0x16, 0xce, 0xe8, 0x53, 0x48, 0x7c, 0xc5, 0xce, 0x5b, 0xca, 0xa9, 0x52, 0x2d, 0xd5,
-	0x3a, 0x65, 0x34, 0x1d, 0x26, 0xf8, 0xef, 0xba, 0x92, 0x61, 0x8b, 0x37, 0x06, 0xd1, 0x5c, 0x2c,
-	0x8c, 0x50, 0x1b, 0xb2, 0x0e, 0x25, 0x93, 0x91, 0x6b, 0xcd, 0x9d, 0x31, 0x2d, 0xe5, 0x79, 0x46,
-	0x8a, 0x75, 0xd1, 0x02, 0xf5, 0xa0, 0x05, 0xea, 0x4d, 0x73, 0xd1, 0x2a, 0x6c, 0xd6, 0x15, 0x60,
-	0xb4, 0x03, 0x6e, 0x8b, 0xc1, 0xd9, 0xae, 0x95, 0x57, 0x00, 0xbb, 0xa3, 0x21, 0x19, 0x62, 0x5f,
-	0xd3, 0x45, 0x49, 0x52, 0xa5, 0x5a, 0x06, 0xb3, 0x25, 0x2a, 0x42, 0xe2, 0x1b, 0x62, 0xcc, 0x45,
-	0x37, 0x64, 0xb0, 0xd8, 0xbc, 0x8e, 0xbe, 0x92, 0xaa, 0x7f, 0x90, 0x20, 0xc1, 0xe3, 0x47, 0x1f,
-	0x01, 0x5c, 0xe2, 0xfe, 0xdd, 0xed, 0xa8, 0xd7, 0xef, 0xb5, 0xe5, 0x88, 0x92, 0x5f, 0xae, 0x54,
-	0x51, 0xa9, 0x3d, 0xcb, 0xa4, 0xe8, 0x25, 0x64, 0x84, 0xba, 0x79, 0x7d, 0x2d, 0x4b, 0x4a, 0x6e,
-	0xb9, 0x52, 0xd3, 0x5c, 0xdb, 0x34, 0x0c, 0xf4, 0x21, 0xa4, 0x85, 0xb2, 0xf5, 0xa5, 0x1c, 0x55,
-	0xb2, 0xcb, 0x95, 0x9a, 0xe2, 0xba, 0xd6, 0x02, 0x7d, 0x0c, 0x39, 0xa1, 0x6a, 0xff, 0xfa, 0xbc,
-	0x7d, 0x3b, 0x94, 0x63, 0xca, 0xc9, 0x72, 0xa5, 0x66, 0xb9, 0xba, 0xfd, 0x30, 0xa6, 0xb6, 0xa7,
-	0xc4, 0xbf, 0xfd, 0x4b, 0x39, 0x52, 0xfd, 0xab, 0x04, 0xbb, 0xfc, 0x30, 0xba, 0x4e, 0xb7, 0x37,
-	0x0c, 0x82, 0xe1, 0x74, 0x4c, 0xcb, 0x63, 0xf9, 0x1e, 0x14, 0x7c, 0xe5, 0xe8, 0xb6, 0xdf, 0xed,
-	0x0d, 0x07, 0xb2, 0xa4, 0xc8, 0xcb, 0x95, 0x9a, 0x13, 0x16, 0xa2, 0xfa, 0xc2, 0x56, 0x83, 0x36,
-	0xee, 0xb6, 0x07, 0x72, 0x34, 0x6c, 0x25, 0x2a, 0x1b, 0x9d, 0x41, 0x91, 0x5b, 0x0d, 0xce, 0x3b,
-	0xed, 0x9b, 0x26, 0x3b, 0xdd, 0x68, 0xd8, 0xbd, 0x69, 0xcb, 0x71, 0xe5, 0x3b, 0xcb, 0x95, 0xfa,
-	0x82, 0xd9, 0x0e, 0xc6, 0x5f, 0xd1, 0x19, 0x69, 0x1a, 0x06, 0x9b, 0x07, 0x7e, 0xb4, 0x7f, 0x97,
-	0x20, 0xb3, 0xad, 0x4d, 0xd4, 0x81, 0xb8, 0xb7, 0xb0, 0x29, 0x4f, 0x79, 0xa1, 0xf1, 0xf9, 0x61,
-	0x15, 0xbd, 0x5b, 0x0d, 0x17, 0x36, 0xc5, 0x1c, 0xa1, 0xfa, 0x00, 0xf9, 0x3d, 0x31, 0xaa, 0x40,
-	0xdc, 0xcf, 0x01, 0x8f, 0x67, 0x4f, 0xc9, 0x93, 0xf1, 0x11, 0xc4, 0x06, 0x77, 0x37, 0xb2, 0xa4,
-	0x14, 0x97, 0x2b, 0x55, 0xde, 0xd3, 0x0f, 0xe6, 0x33, 0xf4, 0x31, 0x24, 0xce, 0xfb, 0x77, 0xbd,
-	0xa1, 0x1c, 0x55, 0x4e, 0x97, 0x2b, 0x15, 0xed, 0x19, 0x9c, 0x5b, 0x73, 0x33, 0xc8, 0xff, 0x8f,
-	0x20, 0x36, 0x24, 0x5a, 0xb8, 0x78, 0x72, 0xef, 0x29, 0x9e, 0x9c, 0x5f, 0x3c, 0xd5, 0x3f, 0x16,
-	0x20, 0x27, 0x9a, 0xc0, 0xb5, 0x2d, 0xd3, 0xa5, 0xe8, 0x06, 0x92, 0x53, 0x87, 0xcc, 0xa8, 0x5b,
-	0x92, 0x78, 0x57, 0x9e, 0x1d, 0xd0, 0x3f, 0xc2, 0xb5, 0xfe, 0x86, 0xf9, 0xb5, 0xe2, 0x6c, 0xec,
-	0x62, 0x1f, 0x44, 0xf9, 0x36, 0x09, 0x09, 0x2e, 0x47, 0x7d, 0x48, 0x8a, 0xb9, 0xc3, 0x83, 0xca,
-	0x36, 0xbe, 0x38, 0x1c, 0x58, 0x7c, 0x63, 0x0e, 0xd3, 0x89, 0x60, 0x1f, 0x06, 0xd9, 0x90, 0x9b,
-	0x1a, 0x16, 0xf1, 0x46, 0x62, 0x32, 0xf9, 0x57, 0xc4, 0xeb, 0x23, 0xe2, 0x65, 0xde, 0xa2, 0xca,
-	0x44, 0xe8, 0x7c, 0xe8, 0x85, 0xa4, 0x9d, 0x08, 0xce, 0x4e, 0x77, 0x5b, 0xf4, 0x00, 0x05, 0xdd,
-	0xf4, 0xa8, 0x46, 0x9d, 0x80, 0x33, 0xc6, 0x39, 0x7f, 0x7e, 0x38, 0x67, 0x57, 0xf8, 0x87, 0x59,
-	0x5f, 0x6c, 0xd6, 0x95, 0xfc, 0x9e, 0xbc, 0x13, 0xc1, 0x79, 0x3d, 0x2c, 0x40, 0xbf, 0x87, 0x93,
-	0xb9, 0xe9, 0xea, 0x9a, 0x49, 0x27, 0x01, 0x75, 0x9c, 0x53, 0xff, 0xe2, 0x70, 0xea, 0x3b, 0x1f,
-	0x20, 0xcc, 0x8d, 0xd8, 0xfd, 0xb8, 0xaf, 0xe8, 0x44, 0x70, 0x61, 0xbe, 0x27, 0x61, 0xe7, 0xbe,
-	0xb7, 0x2c, 0x83, 0x12, 0x33, 0x20, 0x4f, 0x1c, 0x7b, 0xee, 0x96, 0xf0, 0x7f, 0x72, 0xee, 0x3d,
-	0x39, 0x3b, 0xf7, 0x7d, 0x58, 0x80, 0x3c, 0xc8, 0xbb, 0x9e, 0xa3, 0x9b, 0x5a, 0x40, 0x9c, 0xe4,
-	0xc4, 0x3f, 0x3b, 0xa2, 0x76, 0xb8, 0x7b, 0x98, 0x57, 0x5c, 0x88, 0x21, 0x71, 0x27, 0x82, 0x73,
-	0x6e, 0x68, 0x8f, 0xae, 0x83, 0x2b, 0x24, 0xc5, 0xd9, 0x3e, 0x3f, 0x9c, 0x8d, 0xcf, 0xc3, 0xa0,
-	0x50, 0x05, 0x48, 0x2b, 0x09, 0x71, 0xe6, 0xa9, 0x3c, 0x00, 0xec, 0xd4, 0xe8, 0x13, 0x48, 0x7b,
-	0x44, 0x13, 0x6f, 0x0a, 0xd6, 0x69, 0xb9, 0x56, 0x76, 0xb3, 0xae, 0xa4, 0x86, 0x44, 0xe3, 0x2f,
-	0x8a, 0x94, 0x27, 0x16, 0xa8, 0x05, 0xc8, 0x26, 0x8e, 0xa7, 0x7b, 0xba, 0x65, 0x32, 0xeb, 0xd1,
-	0x37, 0xc4, 0x60, 0xb5, 0xce, 0x3c, 0x8a, 0x9b, 0x75, 0x45, 0xbe, 0x0d, 0xb4, 0x57, 0x74, 0xf1,
-	0x2b, 0x62, 0xb8, 0x58, 0xb6, 0x1f, 0x49, 0x94, 0x3f, 0x49, 0x90, 0x0d, 0xf5, 0x10, 0x7a, 0x0d,
-	0x71, 0x8f, 0x68, 0x41, 0x87, 0xab, 0xcf, 0x3f, 0xaa, 0x88, 0xe6, 0xb7, 0x34, 0xf7, 0x41, 0x7d,
-	0xc8, 0x30, 0xc3, 0x11, 0x1f, 0x94, 0x51, 0x3e, 0x28, 0x1b, 0x87, 0xe7, 0xe7, 0x82, 0x78, 0x84,
-	0x8f, 0xc9, 0xf4, 0xc4, 0x5f, 0x29, 0xbf, 0x04, 0xf9, 0x71, 0x23, 0xb2, 0x27, 0xd9, 0xf6, 0x91,
-	0x26, 0xc2, 0x94, 0x71, 0x48, 0x82, 0x4e, 0x21, 0xc9, 0xc7, 0x97, 0x48, 0x84, 0x84, 0xfd, 0x9d,
-	0x72, 0x0d, 0xe8, 0x69, 0x83, 0x1d, 0x89, 0x16, 0xdb, 0xa2, 0xdd, 0xc0, 0x07, 0xef, 0xe9, 0x99,
-	0x23, 0xe1, 0xe2, 0xe1, 0xe0, 0x9e, 0x76, 0xc1, 0x91, 0x68, 0xe9, 0x2d, 0xda, 0x15, 0xbc, 0x78,
-	0x52, 0xda, 0x47, 0x82, 0x65, 0x02, 0xb0, 0xea, 0x00, 0x32, 0x1c, 0xc0, 0xbf, 0xaa, 0x92, 0xfe,
-	0x45, 0x1b, 0x51, 0x3e, 0x58, 0xae, 0xd4, 0x93, 0xad, 0xca, 0xbf, 0x6b, 0x2b, 0x90, 0xdc, 0xde,
-	0xd7, 0xfb, 0x06, 0x22, 0x16, 0xff, 0x26, 0xfa, 0x9b, 0x04, 0xe9, 0xe0, 0x7b, 0xa3, 0xef, 0x42,
-	0xe2, 0xcd, 0x75, 0xbf, 0x39, 0x94, 0x23, 0xca, 0x8b, 0xe5, 0x4a, 0xcd, 0x07, 0x0a, 0xfe, 0xe9,
-	0x91, 0x0a, 0xa9, 0x6e, 0x6f, 0xd8, 0xbe, 0x6c, 0xe3, 0x00, 0x32, 0xd0, 0xfb, 0x9f, 0x13, 0x55,
-	0x21, 0x7d, 0xd7, 0x1b, 0x74, 0x2f, 0x7b, 0xed, 0x0b, 0x39, 0x2a, 0xee, 0xc8, 0xc0, 0x24, 0xf8,
-	0x46, 0x0c, 0xa5, 0xd5, 0xef, 0x5f, 0xb7, 0x9b, 0x3d, 0x39, 0xb6, 0x8f, 0xe2, 0xe7, 0x1d, 0x95,
-	0x21, 0x39, 0x18, 0xe2, 0x6e, 0xef, 0x52, 0x8e, 0x2b, 0x68, 0xb9, 0x52, 0x0b, 0x81, 0x81, 0x48,
-	0xa5, 0x1f, 0xf8, 0x9f, 0x25, 0x28, 0x9e, 0x13, 0x9b, 0xdc, 0xeb, 0x86, 0xee, 0xe9, 0xd4, 0xdd,
-	0xde, 0x8d, 0x7d, 0x88, 0x8f, 0x89, 0x1d, 0xf4, 0xcd, 0xf3, 0x43, 0xe8, 0x7d, 0x00, 0x4c, 0xe8,
-	0xf2, 0xc7, 0x1d, 0xe6, 0x40, 0xca, 0x4f, 0x21, 0xb3, 0x15, 0x1d, 0xf5, 0xde, 0x3b, 0x81, 0x3c,
-	0x7f, 0x8d, 0x06, 0xc8, 0xd5, 0x57, 0xf0, 0xe8, 0x37, 0x87, 0x39, 0xbb, 0x1e, 0x71, 0x3c, 0x0e,
-	0x18, 0xc3, 0x62, 0xc3, 0x48, 0xa8, 0x39, 0xe1, 0x80, 0x31, 0xcc, 0x96, 0x8d, 0xb7, 0x51, 0x48,
-	0x0d, 0x44, 0xd0, 0xe8, 0xb7, 0x10, 0x67, 0xed, 0x8a, 0x6a, 0x87, 0x3e, 0x9a, 0x95, 0xef, 0x1f,
-	0xdc, 0xfb, 0x3f, 0x96, 0xd0, 0x97, 0x90, 0x0b, 0xa7, 0x05, 0x9d, 0x3e, 0x79, 0x21, 0xb7, 0xd9,
-	0x1f, 0xa4, 0xf2, 0x93, 0xa3, 0x33, 0x8b, 0xae, 0x40, 0x3c, 0xcf, 0xff, 0x2f, 0xe6, 0x0f, 0x9e,
-	0xc5, 0xdc, 0x4b, 0x66, 0xab, 0xf2, 0xee, 0x5f, 0xe5, 0xc8, 0xbb, 0x4d, 0x59, 0xfa, 0xc7, 0xa6,
-	0x2c, 0xfd, 0x73, 0x53, 0x96, 0xde, 0xfe, 0xbb, 0x1c, 0xf9, 0x0d, 0x9f, 0x7b, 0x6c, 0xec, 0xb9,
-	0xf7, 0x49, 0x0e, 0xfe, 0xd9, 0xff, 0x02, 0x00, 0x00, 0xff, 0xff, 0x62, 0x34, 0xad, 0x1c, 0x4b,
-	0x0f, 0x00, 0x00,
+	proto.RegisterFile("storage_common.proto", fileDescriptor_storage_common_357365d0971b9daa)
+}
+
+var fileDescriptor_storage_common_357365d0971b9daa = []byte{
+	// 1641 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x57, 0x4b, 0x6f, 0x2b, 0x49,
+	0x15, 0x76, 0xfb, 0xed, 0xe3, 0x47, 0xfa, 0xd6, 0x84, 0xc8, 0xd3, 0x97, 0x89, 0x7b, 0x0c, 0x1a,
+	0x19, 0x18, 0x1c, 0xc8, 0xcc, 0xc0, 0xd5, 0x1d, 0x58, 0xd8, 0xb9, 0x4e, 0x6c, 0xae, 0x1f, 0x51,
+	0xd9, 0x81, 0x19, 0x24, 0x64, 0x55, 0xe2, 0x4a, 0x4f, 0x6b, 0xec, 0xee, 0xa6, 0xbb, 0x7c, 0x27,
+	0x96, 0xd8, 0x33, 0xf2, 0x6a, 0xd8, 0x82, 0x2c, 0x21, 0xb1, 0x64, 0x8b, 0xf8, 0x0d, 0x77, 0xc9,
+	0x2f, 0xb0, 0xc0, 0xfc, 0x04, 0x76, 0xac, 0x50, 0x55, 0x75, 0xdb, 0xed, 0x24, 0x44, 0xf6, 0xae,
+	0xce, 0xeb, 0x3b, 0x8f, 0xae, 0x73, 0xea, 0x34, 0x1c, 0x7a, 0xcc, 0x76, 0x89, 0x41, 0x87, 0x37,
+	0xf6, 0x64, 0x62, 0x5b, 0x55, 0xc7, 0xb5, 0x99, 0x8d, 0x9e, 0x9b, 0xd6, 0xed, 0x78, 0x7a, 0x37,
+	0x22, 0x8c, 0x54, 0x9d, 0x31, 0x61, 0xb7, 0xb6, 0x3b, 0xa9, 0xfa, 0x9a, 0xda, 0xa1, 0x61, 0x1b,
+	0xb6, 0xd0, 0x3b, 0xe1, 0x27, 0x69, 0xa2, 0x3d, 0x37, 0x6c, 0xdb, 0x18, 0xd3, 0x13, 0x41, 0x5d,
+	0x4f, 0x6f, 0x4f, 0xe8, 0xc4, 0x61, 0x33, 0x5f, 0xf8, 0xee, 0x7d, 0x21, 0xb1, 0x02, 0xd1, 0x81,
+	0xe3, 0xd2, 0x91, 0x79, 0x43, 0x18, 0x95, 0x8c, 0xf2, 0xdf, 0x32, 0x90, 0xc5, 0x94, 0x8c, 0x30,
+	0xfd, 0xed, 0x94, 0x7a, 0x0c, 0x8d, 0xe1, 0x80, 0x99, 0x13, 0xea, 0x31, 0x32, 0x71, 0x86, 0x2e,
+	0xb1, 0x0c, 0x5a, 0x8c, 0xea, 0x4a, 0x25, 0x7b, 0xfa, 0x83, 0xea, 0x13, 0x51, 0x56, 0x07, 0x81,
+	0x0d, 0xe6, 0x26, 0xf5, 0xa3, 0xb7, 0xcb, 0x52, 0x64, 0xb5, 0x2c, 0x15, 0xb6, 0xf9, 0xb8, 0xc0,
+	0xb6, 0x68, 0x74, 0x0c, 0x30, 0xa2, 0xde, 0x0d, 0xb5, 0x46, 0xa6, 0x65, 0x14, 0x63, 0xba, 0x52,
+	0x49, 0xe3, 0x10, 0x07, 0x7d, 0x08, 0x60, 0xb8, 0xf6, 0xd4, 0x19, 0x7e, 0x49, 0x67, 0x5e, 0x31,
+	0xae, 0xc7, 0x2a, 0x99, 0x7a, 0x7e, 0xb5, 0x2c, 0x65, 0x2e, 0x38, 0xf7, 0x35, 0x9d, 0x79, 0x38,
+	0x63, 0x04, 0x47, 0xf4, 0x0a, 0x32, 0xeb, 0xf4, 0x8a, 0x09, 0x11, 0xf5, 0x07, 0x4f, 0x46, 0x7d,
+	0x19, 0x68, 0xe3, 0x8d, 0x21, 0x3a, 0x85, 0x9c, 0x47, 0x5d, 0x93, 0x7a, 0xc3, 0xb1, 0x39, 0x31,
+	0x59, 0x31, 0xa9, 0x2b, 0x95, 0x58, 0xfd, 0x60, 0xb5, 0x2c, 0x65, 0xfb, 0x82, 0xdf, 0xe6, 0x6c,
+	0x9c, 0xf5, 0x36, 0x04, 0xfa, 0x04, 0xf2, 0xbe, 0x8d, 0x7d, 0x7b, 0xeb, 0x51, 0x56, 0x4c, 0x09,
+	0x23, 0x75, 0xb5, 0x2c, 0xe5, 0xa4, 0x51, 0x4f, 0xf0, 0x71, 0xce, 0x0b, 0x51, 0xdc, 0x95, 0x63,
+	0x9b, 0x16, 0x0b, 0x5c, 0xa5, 0x37, 0xae, 0x2e, 0x05, 0xdf, 0x77, 0xe5, 0x6c, 0x08, 0x9e, 0x24,
+	0x31, 0x0c, 0x97, 0x1a, 0x3c, 0xc9, 0xcc, 0x0e, 0x49, 0xd6, 0x02, 0x6d, 0xbc, 0x31, 0x44, 0x03,
+	0x48, 0x30, 0x97, 0xdc, 0xd0, 0x22, 0xe8, 0xb1, 0x4a, 0xf6, 0xf4, 0xa3, 0x27, 0x11, 0x42, 0xf7,
+	0xa3, 0x3a, 0xe0, 0x56, 0x0d, 0x8b, 0xb9, 0xb3, 0x7a, 0x66, 0xb5, 0x2c, 0x25, 0x04, 0x8d, 0x25,
+	0x18, 0x7a, 0x05, 0x09, 0xf1, 0x35, 0x8a, 0x59, 0x5d, 0xa9, 0x14, 0x4e, 0xab, 0x3b, 0xa3, 0x8a,
+	0xcf, 0x89, 0xa5, 0x31, 0xfa, 0x10, 0x12, 0x5f, 0xf0, 0x7c, 0x8b, 0x39, 0x5d, 0xa9, 0xa4, 0xea,
+	0x47, 0xdc, 0x4d, 0x93, 0x33, 0xfe, 0xbb, 0x2c, 0x65, 0xf8, 0xe1, 0x7c, 0x4c, 0x0c, 0x0f, 0x4b,
+	0x25, 0xd4, 0x80, 0xac, 0x4b, 0xc9, 0x68, 0xe8, 0xd9, 0x53, 0xf7, 0x86, 0x16, 0xf3, 0xa2, 0x22,
+	0x87, 0x55, 0xd9, 0x02, 0xd5, 0xa0, 0x05, 0xaa, 0x35, 0x6b, 0x56, 0x2f, 0xac, 0x96, 0x25, 0xe0,
+	0x6e, 0xfb, 0x42, 0x17, 0x83, 0xbb, 0x3e, 0xa3, 0x4f, 0x21, 0xf9, 0x95, 0x69, 0x8d, 0xec, 0xaf,
+	0x8a, 0x05, 0x81, 0xf0, 0x9d, 0x27, 0x63, 0xff, 0x95, 0x50, 0xc5, 0xbe, 0x89, 0xf6, 0x02, 0x60,
+	0x53, 0x17, 0xa4, 0x42, 0xec, 0x4b, 0x3a, 0x2b, 0x2a, 0xba, 0x52, 0xc9, 0x60, 0x7e, 0x44, 0x87,
+	0x90, 0x78, 0x43, 0xc6, 0x53, 0xd9, 0x4a, 0x19, 0x2c, 0x89, 0x97, 0xd1, 0x17, 0x4a, 0xf9, 0xf7,
+	0x0a, 0x24, 0x44, 0xf2, 0xe8, 0x3d, 0x80, 0x0b, 0xdc, 0xbb, 0xba, 0x1c, 0x76, 0x7b, 0xdd, 0x86,
+	0x1a, 0xd1, 0xf2, 0xf3, 0x85, 0x2e, 0xaf, 0x79, 0xd7, 0xb6, 0x28, 0x7a, 0x0e, 0x19, 0x29, 0xae,
+	0xb5, 0xdb, 0xaa, 0xa2, 0xe5, 0xe6, 0x0b, 0x3d, 0x2d, 0xa4, 0xb5, 0xf1, 0x18, 0xbd, 0x0b, 0x69,
+	0x29, 0xac, 0x7f, 0xae, 0x46, 0xb5, 0xec, 0x7c, 0xa1, 0xa7, 0x84, 0xac, 0x3e, 0x43, 0xef, 0x43,
+	0x4e, 0x8a, 0x1a, 0x9f, 0x9d, 0x35, 0x2e, 0x07, 0x6a, 0x4c, 0x3b, 0x98, 0x2f, 0xf4, 0xac, 0x10,
+	0x37, 0xee, 0x6e, 0xa8, 0xc3, 0xb4, 0xf8, 0xd7, 0x7f, 0x39, 0x8e, 0x94, 0xff, 0xaa, 0xc0, 0xa6,
+	0xb8, 0xdc, 0x5d, 0xb3, 0xd5, 0x1d, 0x04, 0xc1, 0x08, 0x77, 0x5c, 0x2a, 0x62, 0xf9, 0x2e, 0x14,
+	0x7c, 0xe1, 0xf0, 0xb2, 0xd7, 0xea, 0x0e, 0xfa, 0xaa, 0xa2, 0xa9, 0xf3, 0x85, 0x9e, 0x93, 0x1a,
+	0xf2, 0xea, 0x86, 0xb5, 0xfa, 0x0d, 0xdc, 0x6a, 0xf4, 0xd5, 0x68, 0x58, 0x4b, 0xb6, 0x05, 0x3a,
+	0x81, 0x43, 0xa1, 0xd5, 0x3f, 0x6b, 0x36, 0x3a, 0x35, 0x9e, 0xdd, 0x70, 0xd0, 0xea, 0x34, 0xd4,
+	0xb8, 0xf6, 0xad, 0xf9, 0x42, 0x7f, 0xc6, 0x75, 0xfb, 0x37, 0x5f, 0xd0, 0x09, 0xa9, 0x8d, 0xc7,
+	0x7c, 0x98, 0xf8, 0xd1, 0xfe, 0x27, 0x0a, 0x99, 0xf5, 0xc5, 0x46, 0x4d, 0x88, 0xb3, 0x99, 0x43,
+	0x45, 0xc9, 0x0b, 0xa7, 0x1f, 0xef, 0xd6, 0x0e, 0x9b, 0xd3, 0x60, 0xe6, 0x50, 0x2c, 0x10, 0xca,
+	0x7f, 0x8a, 0x42, 0x7e, 0x8b, 0x8f, 0x4a, 0x10, 0xf7, 0x8b, 0x20, 0x02, 0xda, 0x12, 0x8a, 0x6a,
+	0xbc, 0x07, 0xb1, 0xfe, 0x55, 0x47, 0x55, 0xb4, 0xc3, 0xf9, 0x42, 0x57, 0xb7, 0xe4, 0xfd, 0xe9,
+	0x04, 0xbd, 0x0f, 0x89, 0xb3, 0xde, 0x55, 0x77, 0xa0, 0x46, 0xb5, 0xa3, 0xf9, 0x42, 0x47, 0x5b,
+	0x0a, 0x67, 0xf6, 0xd4, 0x62, 0x1c, 0xa1, 0xd3, 0xea, 0xaa, 0xb1, 0x47, 0x10, 0x3a, 0xa6, 0x25,
+	0xc4, 0xb5, 0xcf, 0xd4, 0xf8, 0x63, 0x62, 0x72, 0xc7, 0x1d, 0x9c, 0xb7, 0x70, 0x7f, 0xa0, 0x26,
+	0x1e, 0x71, 0x70, 0x6e, 0xba, 0x1e, 0xe3, 0x39, 0xb4, 0x6b, 0xfd, 0x81, 0x9a, 0x7c, 0x24, 0x87,
+	0x36, 0x91, 0x0a, 0x9d, 0x46, 0xad, 0xab, 0xa6, 0x1e, 0x51, 0xe8, 0x50, 0x62, 0xf9, 0x55, 0xff,
+	0x09, 0x24, 0xe5, 0xcd, 0xe7, 0x37, 0x9a, 0xbe, 0xa1, 0xae, 0xbc, 0xe5, 0x31, 0x2c, 0x09, 0x74,
+	0x04, 0x49, 0x7f, 0xfe, 0x45, 0x05, 0xdb, 0xa7, 0xca, 0x3f, 0x84, 0xd8, 0x80, 0x18, 0xe1, 0xc6,
+	0xc8, 0x3d, 0xd2, 0x18, 0x39, 0xbf, 0x31, 0xca, 0x7f, 0x28, 0x40, 0x4e, 0x4e, 0x07, 0xcf, 0xb1,
+	0x2d, 0x8f, 0xa2, 0x0e, 0x24, 0x6f, 0x5d, 0x32, 0xa1, 0x5e, 0x51, 0x11, 0xe3, 0xea, 0x64, 0x87,
+	0xc1, 0x22, 0x4d, 0xab, 0xe7, 0xdc, 0xae, 0x1e, 0xe7, 0xef, 0x11, 0xf6, 0x41, 0xb4, 0xaf, 0x93,
+	0x90, 0x10, 0x7c, 0xd4, 0x83, 0xa4, 0x1c, 0xc8, 0x22, 0xa8, 0xec, 0xe9, 0x27, 0xbb, 0x03, 0xcb,
+	0xfb, 0x2b, 0x60, 0x9a, 0x11, 0xec, 0xc3, 0x20, 0x07, 0x72, 0xb7, 0x63, 0x9b, 0xb0, 0xa1, 0x1c,
+	0xd9, 0xfe, 0xdb, 0xf9, 0x72, 0x8f, 0x78, 0xb9, 0xb5, 0xec, 0x20, 0x19, 0xba, 0x78, 0x0d, 0x42,
+	0xdc, 0x66, 0x04, 0x67, 0x6f, 0x37, 0x24, 0xba, 0x83, 0x82, 0x69, 0x31, 0x6a, 0x50, 0x37, 0xf0,
+	0x19, 0x13, 0x3e, 0x7f, 0xb6, 0xbb, 0xcf, 0x96, 0xb4, 0x0f, 0x7b, 0x7d, 0xb6, 0x5a, 0x96, 0xf2,
+	0x5b, 0xfc, 0x66, 0x04, 0xe7, 0xcd, 0x30, 0x03, 0xfd, 0x0e, 0x0e, 0xa6, 0x96, 0x67, 0x1a, 0x16,
+	0x1d, 0x05, 0xae, 0xe3, 0xc2, 0xf5, 0xcf, 0x77, 0x77, 0x7d, 0xe5, 0x03, 0x84, 0x7d, 0x23, 0xbe,
+	0x38, 0x6c, 0x0b, 0x9a, 0x11, 0x5c, 0x98, 0x6e, 0x71, 0x78, 0xde, 0xd7, 0xb6, 0x3d, 0xa6, 0xc4,
+	0x0a, 0x9c, 0x27, 0xf6, 0xcd, 0xbb, 0x2e, 0xed, 0x1f, 0xe4, 0xbd, 0xc5, 0xe7, 0x79, 0x5f, 0x87,
+	0x19, 0x88, 0x41, 0xde, 0x63, 0xae, 0x69, 0x19, 0x81, 0xe3, 0xa4, 0x70, 0xfc, 0xe9, 0x1e, 0x77,
+	0x47, 0x98, 0x87, 0xfd, 0xca, 0x4d, 0x21, 0xc4, 0x6e, 0x46, 0x70, 0xce, 0x0b, 0xd1, 0xa8, 0x1d,
+	0xbc, 0xad, 0x29, 0xe1, 0xed, 0xe3, 0xdd, 0xbd, 0x89, 0x59, 0x1f, 0x5c, 0x54, 0x09, 0x52, 0x4f,
+	0x42, 0x9c, 0x5b, 0x6a, 0x77, 0x00, 0x1b, 0x31, 0xfa, 0x00, 0xd2, 0x8c, 0x18, 0x72, 0xd9, 0xe2,
+	0x9d, 0x96, 0xab, 0x67, 0x57, 0xcb, 0x52, 0x6a, 0x40, 0x0c, 0xb1, 0x6a, 0xa5, 0x98, 0x3c, 0xa0,
+	0x3a, 0x20, 0x87, 0xb8, 0xcc, 0x64, 0xa6, 0x6d, 0x71, 0xed, 0xe1, 0x1b, 0x32, 0xe6, 0x77, 0x9d,
+	0x5b, 0x1c, 0xae, 0x96, 0x25, 0xf5, 0x32, 0x90, 0xbe, 0xa6, 0xb3, 0x5f, 0x92, 0xb1, 0x87, 0x55,
+	0xe7, 0x1e, 0x47, 0xfb, 0xa3, 0x02, 0xd9, 0x50, 0x0f, 0xa1, 0x97, 0x10, 0x67, 0xc4, 0x08, 0x3a,
+	0x5c, 0x7f, 0x7a, 0xdb, 0x24, 0x86, 0xdf, 0xd2, 0xc2, 0x06, 0xf5, 0x20, 0xc3, 0x15, 0x87, 0xe2,
+	0x11, 0x88, 0x8a, 0x47, 0xe0, 0x74, 0xf7, 0xfa, 0xbc, 0x22, 0x8c, 0x88, 0x27, 0x20, 0x3d, 0xf2,
+	0x4f, 0xda, 0x2f, 0x40, 0xbd, 0xdf, 0x88, 0x7c, 0x57, 0x5d, 0x6f, 0xaf, 0x32, 0x4c, 0x15, 0x87,
+	0x38, 0x7c, 0xf8, 0x89, 0xf1, 0x25, 0x0b, 0xa1, 0x60, 0x9f, 0xd2, 0xda, 0x80, 0x1e, 0x36, 0xd8,
+	0x9e, 0x68, 0xb1, 0x35, 0x5a, 0x07, 0xde, 0x79, 0xa4, 0x67, 0xf6, 0x84, 0x8b, 0x87, 0x83, 0x7b,
+	0xd8, 0x05, 0x7b, 0xa2, 0xa5, 0xd7, 0x68, 0xaf, 0xe1, 0xd9, 0x83, 0xab, 0xbd, 0x27, 0x58, 0x26,
+	0x00, 0x2b, 0xf7, 0x21, 0x23, 0x00, 0xfc, 0x57, 0x38, 0xe9, 0x2f, 0x11, 0x11, 0xed, 0x9d, 0xf9,
+	0x42, 0x3f, 0x58, 0x8b, 0xfc, 0x3d, 0xa2, 0x04, 0xc9, 0xf5, 0x2e, 0xb2, 0xad, 0x20, 0x63, 0xf1,
+	0x5f, 0xb0, 0xbf, 0x2b, 0x90, 0x0e, 0xbe, 0x37, 0xfa, 0x36, 0x24, 0xce, 0xdb, 0xbd, 0xda, 0x40,
+	0x8d, 0x68, 0xcf, 0xe6, 0x0b, 0x3d, 0x1f, 0x08, 0xc4, 0xa7, 0x47, 0x3a, 0xa4, 0x5a, 0xdd, 0x41,
+	0xe3, 0xa2, 0x81, 0x03, 0xc8, 0x40, 0xee, 0x7f, 0x4e, 0x54, 0x86, 0xf4, 0x55, 0xb7, 0xdf, 0xba,
+	0xe8, 0x36, 0x5e, 0xa9, 0x51, 0xf9, 0x3a, 0x07, 0x2a, 0xc1, 0x37, 0xe2, 0x28, 0xf5, 0x5e, 0xaf,
+	0xcd, 0x1f, 0xd7, 0xd8, 0x36, 0x8a, 0x5f, 0x77, 0x74, 0x0c, 0xc9, 0xfe, 0x00, 0xb7, 0xba, 0x17,
+	0x6a, 0x5c, 0x43, 0xf3, 0x85, 0x5e, 0x08, 0x14, 0x64, 0x29, 0xfd, 0xc0, 0xff, 0xac, 0xc0, 0xe1,
+	0x19, 0x71, 0xc8, 0xb5, 0x39, 0x36, 0x99, 0x49, 0xbd, 0xf5, 0xdb, 0xd8, 0x83, 0xf8, 0x0d, 0x71,
+	0x82, 0xbe, 0x79, 0x7a, 0x08, 0x3d, 0x06, 0xc0, 0x99, 0x9e, 0x58, 0x5c, 0xb1, 0x00, 0xd2, 0x7e,
+	0x0a, 0x99, 0x35, 0x6b, 0xaf, 0x5d, 0xf6, 0x00, 0xf2, 0x62, 0x4d, 0x0f, 0x90, 0xcb, 0x2f, 0xe0,
+	0xde, 0xff, 0x1f, 0x37, 0xf6, 0x18, 0x71, 0x59, 0xb0, 0x36, 0x08, 0x82, 0x3b, 0xa1, 0xd6, 0xc8,
+	0xdf, 0x19, 0xf8, 0xf1, 0xf4, 0x9b, 0x28, 0xa4, 0xfa, 0x32, 0x68, 0xf4, 0x1b, 0x88, 0xf3, 0x76,
+	0x45, 0x95, 0x5d, 0xff, 0x26, 0xb4, 0xef, 0xed, 0xdc, 0xfb, 0x3f, 0x52, 0xd0, 0xe7, 0x90, 0x0b,
+	0x97, 0x05, 0x1d, 0x3d, 0xf8, 0x75, 0x68, 0xf0, 0x5f, 0x6b, 0xed, 0xc7, 0x7b, 0x57, 0x16, 0xbd,
+	0x06, 0xf9, 0xdf, 0xf2, 0x7f, 0x31, 0xbf, 0xff, 0x24, 0xe6, 0x56, 0x31, 0xeb, 0xa5, 0xb7, 0xff,
+	0x3a, 0x8e, 0xbc, 0x5d, 0x1d, 0x2b, 0xff, 0x58, 0x1d, 0x2b, 0xff, 0x5c, 0x1d, 0x2b, 0xdf, 0xfc,
+	0xfb, 0x38, 0xf2, 0x6b, 0x31, 0xf7, 0xf8, 0xd8, 0xf3, 0xae, 0x93, 0x02, 0xfc, 0xa3, 0xff, 0x0d,
+	0x00, 0xa1, 0xf7, 0x5f, 0xd2, 0x64, 0x10, 0x00, 0x00,
 }
@@ -18,6 +18,7 @@ type groupResultSet struct {
 	ctx context.Context
 	req *datatypes.ReadRequest
 	agg *datatypes.Aggregate
+	win *datatypes.Window
 	mb  multiShardCursors
 
 	i       int
@@ -49,6 +50,7 @@ func NewGroupResultSet(ctx context.Context, req *datatypes.ReadRequest, newCurso
 		ctx:         ctx,
 		req:         req,
 		agg:         req.Aggregate,
+		win:         req.Window,
 		keys:        make([][]byte, len(req.GroupKeys)),
 		nilSort:     nilSortHi,
 		newCursorFn: newCursorFn,
@@ -72,6 +74,7 @@ func NewGroupResultSet(ctx context.Context, req *datatypes.ReadRequest, newCurso
 			ctx:  ctx,
 			mb:   g.mb,
 			agg:  req.Aggregate,
+			win:  req.Window,
 			vals: make([][]byte, len(req.GroupKeys)),
 		}
 
@@ -187,6 +190,7 @@ func groupNoneNextGroup(g *groupResultSet) GroupCursor {
 		ctx:  g.ctx,
 		mb:   g.mb,
 		agg:  g.agg,
+		win:  g.win,
 		cur:  cur,
 		keys: g.km.get(),
 	}
@@ -300,6 +304,7 @@ type groupNoneCursor struct {
 	ctx  context.Context
 	mb   multiShardCursors
 	agg  *datatypes.Aggregate
+	win  *datatypes.Window
 	cur  SeriesCursor
 	row  SeriesRow
 	keys [][]byte
@@ -325,7 +330,7 @@ func (c *groupNoneCursor) Next() bool {
 func (c *groupNoneCursor) Cursor() cursors.Cursor {
 	cur := c.mb.createCursor(c.row)
 	if c.agg != nil {
-		cur = c.mb.newAggregateCursor(c.ctx, c.agg, cur)
+		cur = c.mb.newAggregateCursor(c.ctx, c.agg, c.win, cur)
 	}
 	return cur
 }
@@ -334,6 +339,7 @@ type groupByCursor struct {
 	ctx  context.Context
 	mb   multiShardCursors
 	agg  *datatypes.Aggregate
+	win  *datatypes.Window
 	i    int
 	rows []*SeriesRow
 	keys [][]byte
@@ -362,7 +368,7 @@ func (c *groupByCursor) Next() bool {
 func (c *groupByCursor) Cursor() cursors.Cursor {
 	cur := c.mb.createCursor(*c.rows[c.i-1])
 	if c.agg != nil {
-		cur = c.mb.newAggregateCursor(c.ctx, c.agg, cur)
+		cur = c.mb.newAggregateCursor(c.ctx, c.agg, c.win, cur)
 	}
 	return cur
 }
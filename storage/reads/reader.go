@@ -13,6 +13,7 @@ import (
 	"github.com/influxdata/platform/models"
 	fstorage "github.com/influxdata/platform/query/functions/inputs/storage"
 	"github.com/influxdata/platform/storage/reads/datatypes"
+	"github.com/influxdata/platform/tsdb"
 	"github.com/influxdata/platform/tsdb/cursors"
 )
 
@@ -49,6 +50,53 @@ func (r *storeReader) Read(ctx context.Context, rs fstorage.ReadSpec, start, sto
 	}, nil
 }
 
+func (r *storeReader) ReadTagKeys(ctx context.Context, spec fstorage.TagKeysSpec, start, stop execute.Time) ([]string, error) {
+	req, err := r.tagKeysRequest(spec, start, stop)
+	if err != nil {
+		return nil, err
+	}
+	return r.s.TagKeys(ctx, req)
+}
+
+func (r *storeReader) ReadTagValues(ctx context.Context, spec fstorage.TagValuesSpec, start, stop execute.Time) ([]string, error) {
+	req, err := r.tagKeysRequest(spec.TagKeysSpec, start, stop)
+	if err != nil {
+		return nil, err
+	}
+
+	tagKey := spec.TagKey
+	switch tagKey {
+	case measurementKey:
+		tagKey = tsdb.MeasurementTagKey
+	case fieldKey:
+		tagKey = tsdb.FieldKeyTagKey
+	}
+	return r.s.TagValues(ctx, &TagValuesRequest{TagKeysRequest: *req, TagKey: tagKey})
+}
+
+func (r *storeReader) tagKeysRequest(spec fstorage.TagKeysSpec, start, stop execute.Time) (*TagKeysRequest, error) {
+	src, err := r.s.GetSource(fstorage.ReadSpec{
+		OrganizationID: spec.OrganizationID,
+		BucketID:       spec.BucketID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var req TagKeysRequest
+	if req.ReadSource, err = types.MarshalAny(src); err != nil {
+		return nil, err
+	}
+	if spec.Predicate != nil {
+		if req.Predicate, err = toStoragePredicate(spec.Predicate); err != nil {
+			return nil, err
+		}
+	}
+	req.TimestampRange.Start = int64(start)
+	req.TimestampRange.End = int64(stop)
+	return &req, nil
+}
+
 func (r *storeReader) Close() {}
 
 type tableIterator struct {
@@ -90,6 +138,12 @@ func (bi *tableIterator) Do(f func(flux.Table) error) error {
 		return err
 	} else if agg != datatypes.AggregateTypeNone {
 		req.Aggregate = &datatypes.Aggregate{Type: agg}
+		if bi.readSpec.WindowEvery > 0 {
+			req.Window = &datatypes.Window{
+				Every:  bi.readSpec.WindowEvery,
+				Offset: bi.readSpec.WindowOffset,
+			}
+		}
 	}
 
 	switch {
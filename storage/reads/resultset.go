@@ -10,12 +10,13 @@ import (
 
 type multiShardCursors interface {
 	createCursor(row SeriesRow) cursors.Cursor
-	newAggregateCursor(ctx context.Context, agg *datatypes.Aggregate, cursor cursors.Cursor) cursors.Cursor
+	newAggregateCursor(ctx context.Context, agg *datatypes.Aggregate, win *datatypes.Window, cursor cursors.Cursor) cursors.Cursor
 }
 
 type resultSet struct {
 	ctx context.Context
 	agg *datatypes.Aggregate
+	win *datatypes.Window
 	cur SeriesCursor
 	row SeriesRow
 	mb  multiShardCursors
@@ -25,6 +26,7 @@ func NewResultSet(ctx context.Context, req *datatypes.ReadRequest, cur SeriesCur
 	return &resultSet{
 		ctx: ctx,
 		agg: req.Aggregate,
+		win: req.Window,
 		cur: cur,
 		mb:  newMultiShardArrayCursors(ctx, req.TimestampRange.Start, req.TimestampRange.End, !req.Descending, req.PointsLimit),
 	}
@@ -60,7 +62,7 @@ func (r *resultSet) Next() bool {
 func (r *resultSet) Cursor() cursors.Cursor {
 	cur := r.mb.createCursor(r.row)
 	if r.agg != nil {
-		cur = r.mb.newAggregateCursor(r.ctx, r.agg, cur)
+		cur = r.mb.newAggregateCursor(r.ctx, r.agg, r.win, cur)
 	}
 	return cur
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/types"
 	"github.com/influxdata/platform/models"
 	fstorage "github.com/influxdata/platform/query/functions/inputs/storage"
 	"github.com/influxdata/platform/storage/reads/datatypes"
@@ -70,8 +71,30 @@ type GroupCursor interface {
 	Err() error
 }
 
+// TagKeysRequest selects the series whose tag keys are read.
+type TagKeysRequest struct {
+	ReadSource     *types.Any
+	TimestampRange datatypes.TimestampRange
+	Predicate      *datatypes.Predicate
+}
+
+// TagValuesRequest selects the tag and the series whose tag values are read.
+// Like the predicate, TagKey refers to the measurement and field by their storage
+// tag keys, tsdb.MeasurementTagKey and tsdb.FieldKeyTagKey.
+type TagValuesRequest struct {
+	TagKeysRequest
+	TagKey string
+}
+
 type Store interface {
 	Read(ctx context.Context, req *datatypes.ReadRequest) (ResultSet, error)
 	GroupRead(ctx context.Context, req *datatypes.ReadRequest) (GroupResultSet, error)
+
+	// TagKeys returns the sorted tag keys of the series selected by req.
+	TagKeys(ctx context.Context, req *TagKeysRequest) ([]string, error)
+
+	// TagValues returns the sorted values of a tag of the series selected by req.
+	TagValues(ctx context.Context, req *TagValuesRequest) ([]string, error)
+
 	GetSource(rs fstorage.ReadSpec) (proto.Message, error)
 }
@@ -21,10 +21,26 @@ type indexSeriesCursor struct {
 	row          reads.SeriesRow
 	eof          bool
 	hasValueExpr bool
+	normalize    bool
 }
 
-func newIndexSeriesCursor(ctx context.Context, src *readSource, req *datatypes.ReadRequest, engine *storage.Engine) (*indexSeriesCursor, error) {
-	queries, err := engine.CreateCursorIterator(ctx)
+func newIndexSeriesCursor(ctx context.Context, src *readSource, req *datatypes.ReadRequest, engine *storage.Engine, normalize bool) (*indexSeriesCursor, error) {
+	var (
+		cond influxql.Expr
+		err  error
+	)
+	if root := req.Predicate.GetRoot(); root != nil {
+		if cond, err = reads.NodeToExpr(root, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	var queries tsdb.CursorIterator
+	if isLastRead(req, cond) {
+		queries, err = engine.CreateLastCursorIterator(ctx)
+	} else {
+		queries, err = engine.CreateCursorIterator(ctx)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -45,16 +61,13 @@ func newIndexSeriesCursor(ctx context.Context, src *readSource, req *datatypes.R
 		Ascending:  true,
 		Ordered:    true,
 	}
-	p := &indexSeriesCursor{row: reads.SeriesRow{Query: tsdb.CursorIterators{queries}}}
+	p := &indexSeriesCursor{row: reads.SeriesRow{Query: tsdb.CursorIterators{queries}}, normalize: normalize}
 
 	m := tsdb.EncodeName(platform.ID(src.OrganizationID), platform.ID(src.BucketID))
 	mi := tsdb.NewMeasurementSliceIterator([][]byte{m[:]})
 
-	if root := req.Predicate.GetRoot(); root != nil {
-		if p.cond, err = reads.NodeToExpr(root, nil); err != nil {
-			return nil, err
-		}
-
+	if cond != nil {
+		p.cond = cond
 		p.hasValueExpr = reads.HasFieldValueKey(p.cond)
 		if !p.hasValueExpr {
 			opt.Condition = p.cond
@@ -74,6 +87,19 @@ func newIndexSeriesCursor(ctx context.Context, src *readSource, req *datatypes.R
 	return p, nil
 }
 
+// isLastRead returns whether req reads only the last value of each series, which may then
+// be served from the last-value cache of the engine. Values compared by cond must be read
+// from storage, as the last value that matches cond need not be the last value.
+func isLastRead(req *datatypes.ReadRequest, cond influxql.Expr) bool {
+	if req.Aggregate == nil || req.Aggregate.Type != datatypes.AggregateTypeLast {
+		return false
+	}
+	if req.Window != nil && req.Window.Every > 0 {
+		return false
+	}
+	return cond == nil || !reads.HasFieldValueKey(cond)
+}
+
 func (c *indexSeriesCursor) Close() {
 	if !c.eof {
 		c.eof = true
@@ -116,7 +142,9 @@ func (c *indexSeriesCursor) Next() *reads.SeriesRow {
 	c.row.Tags = copyTags(c.row.Tags, sr.Tags)
 	c.row.Field = string(c.row.Tags.Get(tsdb.FieldKeyTagKeyBytes))
 
-	normalizeTags(c.row.Tags)
+	if c.normalize {
+		normalizeTags(c.row.Tags)
+	}
 
 	if c.cond != nil && c.hasValueExpr {
 		// TODO(sgc): lazily evaluate valueCond
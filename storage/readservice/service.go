@@ -72,3 +72,21 @@ func (q *queryAdapter) Query(ctx context.Context, req *query.Request) (flux.Quer
 	ctx = context.WithValue(ctx, "org", req.OrganizationID.String())
 	return q.Controller.Query(ctx, req.Compiler)
 }
+
+// NewReader returns a Reader that reads the series of the buckets of engine.
+func NewReader(engine *storage.Engine) fstorage.Reader {
+	return reads.NewReader(newStore(engine))
+}
+
+// NewStore returns a Store that reads the series of the buckets of engine. Unlike the
+// series read by queries, the measurement and field of its series are tagged with their
+// storage keys, tsdb.MeasurementTagKey and tsdb.FieldKeyTagKey.
+func NewStore(engine *storage.Engine) reads.Store {
+	return &store{engine: engine, storageKeys: true}
+}
+
+// NewSchemaReader returns a SchemaReader that reads the tag keys and tag values
+// of the series of a bucket from the index of engine.
+func NewSchemaReader(engine *storage.Engine) fstorage.SchemaReader {
+	return reads.NewReader(newStore(engine))
+}
@@ -4,18 +4,27 @@ import (
 	"context"
 	"errors"
 	"math"
+	"sort"
 
 	"github.com/gogo/protobuf/proto"
 	"github.com/gogo/protobuf/types"
+	"github.com/influxdata/influxql"
+	"github.com/influxdata/platform"
 	"github.com/influxdata/platform/models"
 	fstorage "github.com/influxdata/platform/query/functions/inputs/storage"
 	"github.com/influxdata/platform/storage"
 	"github.com/influxdata/platform/storage/reads"
 	"github.com/influxdata/platform/storage/reads/datatypes"
+	"github.com/influxdata/platform/tsdb"
 )
 
 type store struct {
 	engine *storage.Engine
+
+	// storageKeys keeps the storage tag keys of the measurement and field of series,
+	// tsdb.MeasurementTagKey and tsdb.FieldKeyTagKey, rather than replacing them with
+	// the _measurement and _field keys of queries.
+	storageKeys bool
 }
 
 func newStore(engine *storage.Engine) *store {
@@ -49,7 +58,7 @@ func (s *store) Read(ctx context.Context, req *datatypes.ReadRequest) (reads.Res
 	}
 
 	var cur reads.SeriesCursor
-	if ic, err := newIndexSeriesCursor(ctx, source, req, s.engine); err != nil {
+	if ic, err := newIndexSeriesCursor(ctx, source, req, s.engine, !s.storageKeys); err != nil {
 		return nil, err
 	} else if ic == nil {
 		return nil, nil
@@ -91,7 +100,7 @@ func (s *store) GroupRead(ctx context.Context, req *datatypes.ReadRequest) (read
 	}
 
 	newCursor := func() (reads.SeriesCursor, error) {
-		cur, err := newIndexSeriesCursor(ctx, source, req, s.engine)
+		cur, err := newIndexSeriesCursor(ctx, source, req, s.engine, !s.storageKeys)
 		if cur == nil || err != nil {
 			return nil, err
 		}
@@ -101,6 +110,53 @@ func (s *store) GroupRead(ctx context.Context, req *datatypes.ReadRequest) (read
 	return reads.NewGroupResultSet(ctx, req, newCursor), nil
 }
 
+func (s *store) TagKeys(ctx context.Context, req *reads.TagKeysRequest) ([]string, error) {
+	source, start, end, err := getTagKeysSource(req)
+	if err != nil {
+		return nil, err
+	}
+
+	cond, err := tagKeysCondition(req)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := s.engine.TagKeys(ctx, platform.ID(source.OrganizationID), platform.ID(source.BucketID), start, end, cond)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.storageKeys {
+		return keys, nil
+	}
+
+	// Replace the storage keys of the measurement and field, keeping the keys sorted.
+	for i, key := range keys {
+		switch key {
+		case tsdb.MeasurementTagKey:
+			keys[i] = measurementKey
+		case tsdb.FieldKeyTagKey:
+			keys[i] = fieldKey
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func (s *store) TagValues(ctx context.Context, req *reads.TagValuesRequest) ([]string, error) {
+	source, start, end, err := getTagKeysSource(&req.TagKeysRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	cond, err := tagKeysCondition(&req.TagKeysRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.engine.TagValues(ctx, platform.ID(source.OrganizationID), platform.ID(source.BucketID), req.TagKey, start, end, cond)
+}
+
 // this is easier than fooling around with .proto files.
 
 type readSource struct {
@@ -121,12 +177,51 @@ func (s *store) GetSource(rs fstorage.ReadSpec) (proto.Message, error) {
 }
 
 func getReadSource(req *datatypes.ReadRequest) (*readSource, error) {
-	if req.ReadSource == nil {
+	return unmarshalReadSource(req.ReadSource)
+}
+
+// getTagKeysSource returns the read source and time range of req. An unset
+// start or end leaves the time range unbounded.
+func getTagKeysSource(req *reads.TagKeysRequest) (source *readSource, start, end int64, err error) {
+	if source, err = unmarshalReadSource(req.ReadSource); err != nil {
+		return nil, 0, 0, err
+	}
+
+	start, end = req.TimestampRange.Start, req.TimestampRange.End
+	if start <= 0 {
+		start = models.MinNanoTime
+	}
+	if end <= 0 {
+		end = models.MaxNanoTime
+	}
+	return source, start, end, nil
+}
+
+// tagKeysCondition returns the condition of the predicate of req. The predicate may only compare
+// tags, as only the index is consulted.
+func tagKeysCondition(req *reads.TagKeysRequest) (influxql.Expr, error) {
+	root := req.Predicate.GetRoot()
+	if root == nil {
+		return nil, nil
+	}
+
+	cond, err := reads.NodeToExpr(root, nil)
+	if err != nil {
+		return nil, err
+	}
+	if reads.HasFieldValueKey(cond) {
+		return nil, errors.New("predicate may not compare field values")
+	}
+	return cond, nil
+}
+
+func unmarshalReadSource(any *types.Any) (*readSource, error) {
+	if any == nil {
 		return nil, errors.New("missing read source")
 	}
 
 	var source readSource
-	if err := types.UnmarshalAny(req.ReadSource, &source); err != nil {
+	if err := types.UnmarshalAny(any, &source); err != nil {
 		return nil, err
 	}
 	return &source, nil
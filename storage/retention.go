@@ -26,6 +26,7 @@ const (
 type Deleter interface {
 	CreateSeriesCursor(context.Context, SeriesCursorRequest, influxql.Expr) (SeriesCursor, error)
 	DeleteSeriesRangeWithPredicate(tsdb.SeriesIterator, func([]byte, models.Tags) (int64, int64, bool)) error
+	DeletePartitionsWithPredicate(func(name []byte, min, max int64) bool) error
 }
 
 // A BucketFinder is responsible for providing access to buckets via a filter.
@@ -111,10 +112,17 @@ func (s *retentionEnforcer) run() {
 //
 // Any series data that (1) belongs to a bucket in the provided map and
 // (2) falls outside the bucket's indicated retention period will be deleted.
+//
+// Time partitions of the engine where all data has expired are removed whole.
+// Only the data of the remaining partitions is deleted series by series.
 func (s *retentionEnforcer) expireData(rpByBucketID map[platform.ID]time.Duration, now time.Time) error {
 	_, logEnd := logger.NewOperation(s.logger, "Data deletion", "data_deletion")
 	defer logEnd()
 
+	if err := s.expirePartitions(rpByBucketID, now); err != nil {
+		return err
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), engineAPITimeout)
 	defer cancel()
 	cur, err := s.Engine.CreateSeriesCursor(ctx, SeriesCursorRequest{}, nil)
@@ -182,6 +190,27 @@ func (s *retentionEnforcer) expireData(rpByBucketID map[platform.ID]time.Duratio
 	return s.Engine.DeleteSeriesRangeWithPredicate(newSeriesIteratorAdapter(cur), fn)
 }
 
+// expirePartitions removes the time partitions of the engine in which the data of every
+// bucket falls outside of the bucket's retention period.
+func (s *retentionEnforcer) expirePartitions(rpByBucketID map[platform.ID]time.Duration, now time.Time) error {
+	return s.Engine.DeletePartitionsWithPredicate(func(name []byte, min, max int64) bool {
+		if len(name) != platform.IDLength {
+			return false
+		}
+
+		var n [16]byte
+		copy(n[:], name)
+		_, bucketID := tsdb.DecodeName(n)
+
+		// Data of unknown buckets, or of buckets with an infinite retention period, is kept.
+		retentionPeriod, ok := rpByBucketID[bucketID]
+		if !ok || retentionPeriod == 0 {
+			return false
+		}
+		return max <= now.Add(-retentionPeriod).UnixNano()
+	})
+}
+
 // getRetentionPeriodPerBucket returns a map of (bucket ID -> retention period)
 // for all buckets.
 func (s *retentionEnforcer) getRetentionPeriodPerBucket() (map[platform.ID]time.Duration, error) {
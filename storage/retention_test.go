@@ -110,6 +110,49 @@ func TestService_expireData(t *testing.T) {
 	})
 }
 
+func TestService_expirePartitions(t *testing.T) {
+	engine := NewTestEngine()
+	service := newRetentionEnforcer(engine, NewTestBucketFinder())
+	now := time.Date(2018, 4, 10, 23, 12, 33, 0, time.UTC)
+
+	name := func(bucketID platform.ID) []byte {
+		n := tsdb.EncodeName(1, bucketID)
+		return n[:]
+	}
+	rpByBucketID := map[platform.ID]time.Duration{
+		10: 3 * time.Hour,
+		11: 24 * time.Hour,
+		12: 0,
+	}
+
+	cutoff := now.Add(-3 * time.Hour).UnixNano()
+	tests := []struct {
+		name string
+		max  int64
+		exp  bool
+	}{
+		{name: string(name(10)), max: cutoff, exp: true},
+		{name: string(name(10)), max: cutoff + 1, exp: false},
+		{name: string(name(11)), max: cutoff, exp: false},        // Longer retention period.
+		{name: string(name(12)), max: math.MinInt64, exp: false}, // Infinite retention period.
+		{name: string(name(13)), max: math.MinInt64, exp: false}, // Missing bucket.
+		{name: "zyzwrong", max: math.MinInt64, exp: false},
+	}
+
+	engine.DeletePartitionsWithPredicateFn = func(fn func([]byte, int64, int64) bool) error {
+		for _, tt := range tests {
+			if got := fn([]byte(tt.name), math.MinInt64, tt.max); got != tt.exp {
+				t.Errorf("partition ending at %d of %q: got %v, expected %v", tt.max, tt.name, got, tt.exp)
+			}
+		}
+		return nil
+	}
+
+	if err := service.expireData(rpByBucketID, now); err != nil {
+		t.Fatal(err)
+	}
+}
+
 // genMeasurementName generates a random measurement name or panics.
 func genMeasurementName() []byte {
 	b := make([]byte, 16)
@@ -131,6 +174,7 @@ func (f *TestSeriesCursor) Next() (*SeriesCursorRow, error) { return f.NextFn()
 type TestEngine struct {
 	CreateSeriesCursorFn             func(context.Context, SeriesCursorRequest, influxql.Expr) (SeriesCursor, error)
 	DeleteSeriesRangeWithPredicateFn func(tsdb.SeriesIterator, func([]byte, models.Tags) (int64, int64, bool)) error
+	DeletePartitionsWithPredicateFn  func(func([]byte, int64, int64) bool) error
 
 	SeriesCursor *TestSeriesCursor
 }
@@ -145,6 +189,7 @@ func NewTestEngine() *TestEngine {
 		SeriesCursor:                     cursor,
 		CreateSeriesCursorFn:             func(context.Context, SeriesCursorRequest, influxql.Expr) (SeriesCursor, error) { return cursor, nil },
 		DeleteSeriesRangeWithPredicateFn: func(tsdb.SeriesIterator, func([]byte, models.Tags) (int64, int64, bool)) error { return nil },
+		DeletePartitionsWithPredicateFn:  func(func([]byte, int64, int64) bool) error { return nil },
 	}
 }
 
@@ -156,6 +201,10 @@ func (e *TestEngine) DeleteSeriesRangeWithPredicate(itr tsdb.SeriesIterator, fn
 	return e.DeleteSeriesRangeWithPredicateFn(itr, fn)
 }
 
+func (e *TestEngine) DeletePartitionsWithPredicate(fn func([]byte, int64, int64) bool) error {
+	return e.DeletePartitionsWithPredicateFn(fn)
+}
+
 type TestBucketFinder struct {
 	FindBucketsFn func(context.Context, platform.BucketFilter, ...platform.FindOptions) ([]*platform.Bucket, int, error)
 }
@@ -0,0 +1,202 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/influxdata/influxql"
+	"github.com/influxdata/platform"
+	"github.com/influxdata/platform/models"
+	"github.com/influxdata/platform/tsdb"
+	"github.com/influxdata/platform/tsdb/tsm1"
+)
+
+// TagKeys returns the sorted tag keys of the series of the bucket that match predicate and
+// have data between start and end, inclusive. The measurement and field of each series are
+// stored as the tags tsdb.MeasurementTagKey and tsdb.FieldKeyTagKey, and so are included.
+// predicate may only compare tags, and may be nil to match all series.
+func (e *Engine) TagKeys(ctx context.Context, orgID, bucketID platform.ID, start, end int64, predicate influxql.Expr) ([]string, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return nil, ErrEngineClosed
+	}
+
+	name := tsdb.EncodeName(orgID, bucketID)
+	m := newSchemaMatcher(e, name[:], start, end)
+	if err := m.setPredicate(predicate); err != nil {
+		return nil, err
+	}
+
+	itr, err := e.index.TagKeyIterator(name[:])
+	if err != nil {
+		return nil, err
+	} else if itr == nil {
+		return []string{}, nil
+	}
+	defer itr.Close()
+
+	keys := []string{}
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		key, err := itr.Next()
+		if err != nil {
+			return nil, err
+		} else if key == nil {
+			return keys, nil
+		}
+
+		sitr, err := e.index.TagKeySeriesIDIterator(name[:], key)
+		if err != nil {
+			return nil, err
+		}
+		if ok, err := m.matchAny(sitr); err != nil {
+			return nil, err
+		} else if ok {
+			keys = append(keys, string(key))
+		}
+	}
+}
+
+// TagValues returns the sorted values of the tag tagKey of the series of the bucket that match
+// predicate and have data between start and end, inclusive. The measurements and fields of the
+// bucket are the values of the tags tsdb.MeasurementTagKey and tsdb.FieldKeyTagKey respectively.
+// predicate may only compare tags, and may be nil to match all series.
+func (e *Engine) TagValues(ctx context.Context, orgID, bucketID platform.ID, tagKey string, start, end int64, predicate influxql.Expr) ([]string, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return nil, ErrEngineClosed
+	}
+
+	name := tsdb.EncodeName(orgID, bucketID)
+	m := newSchemaMatcher(e, name[:], start, end)
+	if err := m.setPredicate(predicate); err != nil {
+		return nil, err
+	}
+
+	key := []byte(tagKey)
+	itr, err := e.index.TagValueIterator(name[:], key)
+	if err != nil {
+		return nil, err
+	} else if itr == nil {
+		return []string{}, nil
+	}
+	defer itr.Close()
+
+	values := []string{}
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		value, err := itr.Next()
+		if err != nil {
+			return nil, err
+		} else if value == nil {
+			return values, nil
+		}
+
+		sitr, err := e.index.TagValueSeriesIDIterator(name[:], key, value)
+		if err != nil {
+			return nil, err
+		}
+		if ok, err := m.matchAny(sitr); err != nil {
+			return nil, err
+		} else if ok {
+			values = append(values, string(value))
+		}
+	}
+}
+
+// schemaMatcher decides whether any of the series of a tag key or tag value match a predicate
+// and have data in a time range. Checking for data is skipped when the time range covers all
+// time, as the index only holds series that have not been entirely deleted.
+type schemaMatcher struct {
+	e       *Engine
+	name    []byte
+	start   int64
+	end     int64
+	allTime bool
+	matches *tsdb.SeriesIDSet // nil matches all series
+	key     []byte
+}
+
+func newSchemaMatcher(e *Engine, name []byte, start, end int64) *schemaMatcher {
+	return &schemaMatcher{
+		e:       e,
+		name:    name,
+		start:   start,
+		end:     end,
+		allTime: start <= models.MinNanoTime && end >= models.MaxNanoTime,
+	}
+}
+
+// setPredicate limits the matching series to those of the measurement that match predicate.
+func (m *schemaMatcher) setPredicate(predicate influxql.Expr) error {
+	if predicate == nil {
+		return nil
+	}
+	if err := validateTagPredicate(predicate); err != nil {
+		return err
+	}
+
+	itr, err := m.e.index.MeasurementSeriesByExprIterator(m.name, predicate)
+	if err != nil {
+		return err
+	}
+	m.matches = tsdb.NewSeriesIDSet()
+	if itr == nil {
+		return nil
+	}
+	defer itr.Close()
+
+	for {
+		elem, err := itr.Next()
+		if err != nil {
+			return err
+		} else if elem.SeriesID.IsZero() {
+			return nil
+		}
+		m.matches.AddNoLock(elem.SeriesID)
+	}
+}
+
+// matchAny reports whether any series of itr matches, reading only as far as the first match.
+// It closes itr.
+func (m *schemaMatcher) matchAny(itr tsdb.SeriesIDIterator) (bool, error) {
+	if itr == nil {
+		return false, nil
+	}
+	defer itr.Close()
+
+	for {
+		elem, err := itr.Next()
+		if err != nil {
+			return false, err
+		} else if elem.SeriesID.IsZero() {
+			return false, nil
+		}
+
+		if m.matches != nil && !m.matches.Contains(elem.SeriesID) {
+			continue
+		}
+		if m.allTime || m.hasData(elem.SeriesID) {
+			return true, nil
+		}
+	}
+}
+
+// hasData reports whether the series has data between the start and end of the matcher.
+func (m *schemaMatcher) hasData(id tsdb.SeriesID) bool {
+	skey := m.e.sfile.SeriesKey(id)
+	if len(skey) == 0 {
+		return false
+	}
+	name, tags := tsdb.ParseSeriesKey(skey)
+	m.key = models.AppendMakeKey(m.key[:0], name, tags)
+	field := tags.Get(tsdb.FieldKeyTagKeyBytes)
+	return m.e.engine.HasData(tsm1.SeriesFieldKeyBytes(string(m.key), string(field)), m.start, m.end)
+}
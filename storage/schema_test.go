@@ -0,0 +1,119 @@
+package storage_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxql"
+	"github.com/influxdata/platform"
+	"github.com/influxdata/platform/models"
+	"github.com/influxdata/platform/tsdb"
+)
+
+func TestEngine_TagKeysAndValues(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+
+	org, bucket, other := platform.ID(1), platform.ID(2), platform.ID(3)
+	pts := []models.Point{
+		models.MustNewPoint("cpu", models.NewTags(map[string]string{"host": "a"}), map[string]interface{}{"value": 1.0}, time.Unix(1, 0)),
+		models.MustNewPoint("cpu", models.NewTags(map[string]string{"host": "b"}), map[string]interface{}{"value": 1.0}, time.Unix(100, 0)),
+		models.MustNewPoint("mem", models.NewTags(map[string]string{"region": "west"}), map[string]interface{}{"free": 1.0}, time.Unix(50, 0)),
+	}
+	points, err := tsdb.ExplodePoints(org, bucket, pts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.Engine.WritePoints(points); err != nil {
+		t.Fatal(err)
+	}
+
+	// Series of another bucket are never included.
+	points, err = tsdb.ExplodePoints(org, other, []models.Point{
+		models.MustNewPoint("disk", models.NewTags(map[string]string{"path": "/"}), map[string]interface{}{"used": 1.0}, time.Unix(1, 0)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.Engine.WritePoints(points); err != nil {
+		t.Fatal(err)
+	}
+
+	allTime := [2]int64{models.MinNanoTime, models.MaxNanoTime}
+	tests := []struct {
+		name      string
+		tagKey    string // tag keys are read when empty
+		timeRange [2]int64
+		predicate string
+		exp       []string
+	}{
+		{
+			name:      "tag keys",
+			timeRange: allTime,
+			exp:       []string{"_f", "_m", "host", "region"},
+		},
+		{
+			name:      "tag keys in time range",
+			timeRange: [2]int64{0, int64(10 * time.Second)},
+			exp:       []string{"_f", "_m", "host"},
+		},
+		{
+			name:      "tag keys with predicate",
+			timeRange: allTime,
+			predicate: `_m = 'mem'`,
+			exp:       []string{"_f", "_m", "region"},
+		},
+		{
+			name:      "measurements",
+			tagKey:    "_m",
+			timeRange: allTime,
+			exp:       []string{"cpu", "mem"},
+		},
+		{
+			name:      "fields",
+			tagKey:    "_f",
+			timeRange: allTime,
+			exp:       []string{"free", "value"},
+		},
+		{
+			name:      "tag values in time range with predicate",
+			tagKey:    "host",
+			timeRange: [2]int64{int64(50 * time.Second), int64(200 * time.Second)},
+			predicate: `_m = 'cpu'`,
+			exp:       []string{"b"},
+		},
+		{
+			name:      "no matching series",
+			tagKey:    "host",
+			timeRange: allTime,
+			predicate: `_m = 'mem'`,
+			exp:       []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var predicate influxql.Expr
+			if tt.predicate != "" {
+				predicate = influxql.MustParseExpr(tt.predicate)
+			}
+
+			var got []string
+			var err error
+			if tt.tagKey == "" {
+				got, err = engine.TagKeys(context.Background(), org, bucket, tt.timeRange[0], tt.timeRange[1], predicate)
+			} else {
+				got, err = engine.TagValues(context.Background(), org, bucket, tt.tagKey, tt.timeRange[0], tt.timeRange[1], predicate)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.exp) {
+				t.Fatalf("got %q, expected %q", got, tt.exp)
+			}
+		})
+	}
+}
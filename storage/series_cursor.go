@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"bytes"
 	"errors"
 	"sort"
 	"sync"
@@ -18,6 +19,10 @@ type SeriesCursor interface {
 
 type SeriesCursorRequest struct {
 	Measurements tsdb.MeasurementIterator
+
+	// MeasurementPrefix restricts a cursor over all measurements to the measurements
+	// whose name starts with it. It is ignored if Measurements is set.
+	MeasurementPrefix []byte
 }
 
 // seriesCursor is an implementation of SeriesCursor over an tsi1.Index.
@@ -38,18 +43,7 @@ type SeriesCursorRow struct {
 
 // newSeriesCursor returns a new instance of SeriesCursor.
 func newSeriesCursor(req SeriesCursorRequest, index *tsi1.Index, cond influxql.Expr) (_ SeriesCursor, err error) {
-	// Only equality operators are allowed.
-	influxql.WalkFunc(cond, func(node influxql.Node) {
-		switch n := node.(type) {
-		case *influxql.BinaryExpr:
-			switch n.Op {
-			case influxql.EQ, influxql.NEQ, influxql.EQREGEX, influxql.NEQREGEX, influxql.OR, influxql.AND:
-			default:
-				err = errors.New("invalid tag comparison operator")
-			}
-		}
-	})
-	if err != nil {
+	if err := validateTagPredicate(cond); err != nil {
 		return nil, err
 	}
 
@@ -59,6 +53,9 @@ func newSeriesCursor(req SeriesCursorRequest, index *tsi1.Index, cond influxql.E
 		if err != nil {
 			return nil, err
 		}
+		if len(req.MeasurementPrefix) > 0 {
+			mitr = &prefixMeasurementIterator{itr: mitr, prefix: req.MeasurementPrefix}
+		}
 	}
 
 	return &seriesCursor{
@@ -68,6 +65,41 @@ func newSeriesCursor(req SeriesCursorRequest, index *tsi1.Index, cond influxql.E
 	}, nil
 }
 
+// prefixMeasurementIterator returns the measurements of itr whose name starts with prefix.
+type prefixMeasurementIterator struct {
+	itr    tsdb.MeasurementIterator
+	prefix []byte
+}
+
+func (itr *prefixMeasurementIterator) Close() error { return itr.itr.Close() }
+
+func (itr *prefixMeasurementIterator) Next() ([]byte, error) {
+	for {
+		name, err := itr.itr.Next()
+		if err != nil || name == nil {
+			return nil, err
+		} else if bytes.HasPrefix(name, itr.prefix) {
+			return name, nil
+		}
+	}
+}
+
+// validateTagPredicate returns an error if cond compares tags with anything other than
+// equality operators.
+func validateTagPredicate(cond influxql.Expr) (err error) {
+	influxql.WalkFunc(cond, func(node influxql.Node) {
+		switch n := node.(type) {
+		case *influxql.BinaryExpr:
+			switch n.Op {
+			case influxql.EQ, influxql.NEQ, influxql.EQREGEX, influxql.NEQREGEX, influxql.OR, influxql.AND:
+			default:
+				err = errors.New("invalid tag comparison operator")
+			}
+		}
+	})
+	return err
+}
+
 // Close closes the iterator.
 func (cur *seriesCursor) Close() (err error) {
 	cur.once.Do(func() {
@@ -0,0 +1,150 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/platform"
+	"github.com/influxdata/platform/models"
+	"github.com/influxdata/platform/tsdb"
+)
+
+// FindBucketStats returns statistics of the data of the bucket.
+//
+// The size, number of points and time range of the data are read from the TSM index of every file
+// and from the cache. The series of the bucket are counted from the index: their number, where each
+// field of a series counts separately, is kept up to date by the index, and the series of each
+// measurement are counted from the sets of series the index keeps for each tag value.
+func (e *Engine) FindBucketStats(ctx context.Context, orgID, bucketID platform.ID) (*platform.BucketStats, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return nil, ErrEngineClosed
+	}
+
+	name := tsdb.EncodeName(orgID, bucketID)
+	// Every series key of the bucket starts with its escaped name, followed by its tags.
+	prefix := append(models.MakeKey(name[:], nil), ',')
+	data, err := e.engine.PrefixStats(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &platform.BucketStats{
+		BucketID:     bucketID,
+		Size:         data.Size,
+		PointN:       data.PointN,
+		FieldSeriesN: int64(e.index.MeasurementCardinalityStats()[string(name[:])]),
+	}
+	if data.PointN > 0 {
+		stats.OldestTime = time.Unix(0, data.MinTime).UTC()
+		stats.NewestTime = time.Unix(0, data.MaxTime).UTC()
+	}
+
+	stats.Measurements, err = e.measurementCardinalities(ctx, name[:])
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range stats.Measurements {
+		// The measurement is part of every series, so no series belongs to two measurements.
+		stats.SeriesN += m.SeriesN
+	}
+	return stats, nil
+}
+
+// measurementCardinalities returns the cardinality of every measurement of the bucket with
+// the given name, in order of measurement name. The caller must hold the read lock of the engine.
+//
+// The series of a measurement with each of its fields are the intersection of the series of the
+// measurement with those of the field, which the index keeps for the tags tsdb.MeasurementTagKey
+// and tsdb.FieldKeyTagKey. The number of distinct series of the measurement is estimated as the
+// number of series of its most common field, which is exact when its series share their fields.
+func (e *Engine) measurementCardinalities(ctx context.Context, name []byte) ([]platform.MeasurementCardinality, error) {
+	fields, err := e.tagValueSeriesIDSets(ctx, name, tsdb.FieldKeyTagKeyBytes)
+	if err != nil {
+		return nil, err
+	}
+	measurements, err := e.tagValueSeriesIDSets(ctx, name, tsdb.MeasurementTagKeyBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	var cards []platform.MeasurementCardinality
+	for _, m := range measurements {
+		card := platform.MeasurementCardinality{
+			Name:         m.value,
+			FieldSeriesN: int64(m.ids.Cardinality()),
+		}
+		if card.FieldSeriesN == 0 {
+			continue
+		}
+		for _, f := range fields {
+			if n := int64(m.ids.And(f.ids).Cardinality()); n > card.SeriesN {
+				card.SeriesN = n
+			}
+		}
+		cards = append(cards, card)
+	}
+	return cards, nil
+}
+
+// tagValueSeriesIDs is a value of a tag and the IDs of its series.
+type tagValueSeriesIDs struct {
+	value string
+	ids   *tsdb.SeriesIDSet
+}
+
+// tagValueSeriesIDSets returns the IDs of the series of every value of the tag key of the
+// measurement name, in order of value.
+func (e *Engine) tagValueSeriesIDSets(ctx context.Context, name, key []byte) ([]tagValueSeriesIDs, error) {
+	itr, err := e.index.TagValueIterator(name, key)
+	if err != nil {
+		return nil, err
+	} else if itr == nil {
+		return nil, nil
+	}
+	defer itr.Close()
+
+	var a []tagValueSeriesIDs
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		value, err := itr.Next()
+		if err != nil {
+			return nil, err
+		} else if value == nil {
+			return a, nil
+		}
+
+		sitr, err := e.index.TagValueSeriesIDIterator(name, key, value)
+		if err != nil {
+			return nil, err
+		}
+		ids, err := readSeriesIDSet(sitr)
+		if err != nil {
+			return nil, err
+		}
+		a = append(a, tagValueSeriesIDs{value: string(value), ids: ids})
+	}
+}
+
+// readSeriesIDSet reads the IDs of itr into a set, and closes itr.
+func readSeriesIDSet(itr tsdb.SeriesIDIterator) (*tsdb.SeriesIDSet, error) {
+	ids := tsdb.NewSeriesIDSet()
+	if itr == nil {
+		return ids, nil
+	}
+	defer itr.Close()
+
+	for {
+		elem, err := itr.Next()
+		if err != nil {
+			return nil, err
+		} else if elem.SeriesID.IsZero() {
+			return ids, nil
+		}
+		ids.AddNoLock(elem.SeriesID)
+	}
+}
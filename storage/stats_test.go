@@ -0,0 +1,74 @@
+package storage_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/influxdata/platform"
+	"github.com/influxdata/platform/models"
+	"github.com/influxdata/platform/tsdb"
+)
+
+func TestEngine_FindBucketStats(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+
+	org, bucket, other := platform.ID(1), platform.ID(2), platform.ID(3)
+	pts := []models.Point{
+		models.MustNewPoint("cpu", models.NewTags(map[string]string{"host": "a"}), map[string]interface{}{"user": 1.0, "system": 2.0}, time.Unix(10, 0)),
+		models.MustNewPoint("cpu", models.NewTags(map[string]string{"host": "b"}), map[string]interface{}{"user": 1.0}, time.Unix(1, 0)),
+		models.MustNewPoint("cpu", models.NewTags(map[string]string{"host": "b"}), map[string]interface{}{"user": 1.0}, time.Unix(2, 0)),
+		models.MustNewPoint("mem", models.NewTags(map[string]string{"host": "a"}), map[string]interface{}{"free": 1.0}, time.Unix(5, 0)),
+	}
+	points, err := tsdb.ExplodePoints(org, bucket, pts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.Engine.WritePoints(points); err != nil {
+		t.Fatal(err)
+	}
+
+	// The data of other buckets is never included.
+	points, err = tsdb.ExplodePoints(org, other, []models.Point{
+		models.MustNewPoint("disk", models.NewTags(map[string]string{"path": "/"}), map[string]interface{}{"used": 1.0}, time.Unix(100, 0)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.Engine.WritePoints(points); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := engine.FindBucketStats(context.Background(), org, bucket)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := &platform.BucketStats{
+		BucketID:     bucket,
+		SeriesN:      3,
+		FieldSeriesN: 4,
+		PointN:       5,
+		OldestTime:   time.Unix(1, 0).UTC(),
+		NewestTime:   time.Unix(10, 0).UTC(),
+		Measurements: []platform.MeasurementCardinality{
+			{Name: "cpu", SeriesN: 2, FieldSeriesN: 3},
+			{Name: "mem", SeriesN: 1, FieldSeriesN: 1},
+		},
+	}
+	if !reflect.DeepEqual(stats, exp) {
+		t.Fatalf("unexpected stats:\ngot  %+v\nexp  %+v", stats, exp)
+	}
+
+	// A bucket without data has no points or measurements.
+	stats, err = engine.FindBucketStats(context.Background(), org, platform.ID(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := (&platform.BucketStats{BucketID: platform.ID(4)}); !reflect.DeepEqual(stats, exp) {
+		t.Fatalf("unexpected stats for empty bucket:\ngot  %+v\nexp  %+v", stats, exp)
+	}
+}
@@ -0,0 +1,125 @@
+package storage
+
+import (
+	"github.com/influxdata/platform/models"
+	"github.com/influxdata/platform/tsdb"
+)
+
+// PointsValidator describes the ability to validate points as a storage engine would
+// write them, without writing them.
+type PointsValidator interface {
+	ValidatePoints(v *WriteValidation, points []models.Point) error
+}
+
+// WriteValidation summarizes the points validated for a write that is not written. The
+// points of each call to ValidatePoints are validated against the data of the engine and
+// the points validated before them, as if these had been written.
+type WriteValidation struct {
+	// NewSeries is the number of series that would be created, each of a field of a
+	// measurement, as counted by the cardinality of a bucket.
+	NewSeries int
+	// NewMeasurements are the measurements that would be created, in the order of their
+	// first point.
+	NewMeasurements []string
+	// TypeConflicts is the number of points whose field has another type than the field
+	// of its measurement.
+	TypeConflicts int
+
+	series     map[string]struct{}
+	fieldTypes map[string]measurementFieldTypes
+}
+
+// NewWriteValidation returns the validation of a write of no points.
+func NewWriteValidation() *WriteValidation {
+	return &WriteValidation{
+		series:     make(map[string]struct{}),
+		fieldTypes: make(map[string]measurementFieldTypes),
+	}
+}
+
+// ValidatePoints validates exploded points as WritePoints would write them, adding them
+// to v, and returns the error that WritePoints would return. Nothing is written.
+func (e *Engine) ValidatePoints(v *WriteValidation, points []models.Point) error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return ErrEngineClosed
+	}
+
+	var pwe tsdb.PartialWriteError
+	var dropReason string
+	collection := tsdb.NewSeriesCollection(points)
+	for iter := collection.Iterator(); iter.Next(); {
+		name, tags, key := iter.Name(), iter.Tags(), iter.Key()
+
+		if reason := e.invalidKey(name, tags, key); reason != "" {
+			if pwe.Reason == "" {
+				pwe.Reason = reason
+			}
+			pwe.Dropped++
+			pwe.DroppedKeys = append(pwe.DroppedKeys, key)
+			continue
+		}
+
+		measurement, field := tags.Get(tsdb.MeasurementTagKeyBytes), tags.Get(tsdb.FieldKeyTagKeyBytes)
+		typ := iter.Type()
+		if mismatch, ok := e.schemaMismatch(name, measurement, field, typ); ok {
+			if pwe.SchemaMismatches == nil {
+				pwe.SchemaMismatches = make(map[int]tsdb.SchemaMismatchError)
+			}
+			if dropReason == "" {
+				dropReason = mismatch.Error()
+			}
+			pwe.SchemaMismatches[iter.Index()] = mismatch
+			pwe.Dropped++
+			continue
+		}
+
+		existing, ok, measurementOK := e.fieldTypes.fieldType(name, measurement, field)
+		if !ok {
+			var added bool
+			existing, added = v.fieldTypes[string(name)][string(measurement)][string(field)]
+			if !measurementOK && v.fieldTypes[string(name)][string(measurement)] == nil {
+				v.NewMeasurements = append(v.NewMeasurements, string(measurement))
+			}
+			if !added {
+				setFieldType(v.fieldTypes, name, measurement, field, typ)
+				existing = typ
+			}
+		}
+		if existing != typ {
+			if pwe.Conflicts == nil {
+				pwe.Conflicts = make(map[int]tsdb.FieldTypeConflictError)
+			}
+			conflict := tsdb.FieldTypeConflictError{
+				Measurement:  string(measurement),
+				Field:        string(field),
+				Type:         SchemaFieldType(typ),
+				ExistingType: SchemaFieldType(existing),
+			}
+			if dropReason == "" {
+				dropReason = conflict.Error()
+			}
+			pwe.Conflicts[iter.Index()] = conflict
+			pwe.Dropped++
+			v.TypeConflicts++
+			continue
+		}
+
+		if _, ok := v.series[string(key)]; ok {
+			continue
+		}
+		if id := e.sfile.SeriesID(name, tags, nil); id.IsZero() || e.sfile.IsDeleted(id) {
+			v.series[string(key)] = struct{}{}
+			v.NewSeries++
+		}
+	}
+
+	if pwe.Dropped == 0 {
+		return nil
+	}
+	if pwe.Reason == "" {
+		pwe.Reason = dropReason
+	}
+	return pwe
+}
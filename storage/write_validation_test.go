@@ -0,0 +1,84 @@
+package storage_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/influxdata/platform"
+	"github.com/influxdata/platform/models"
+	"github.com/influxdata/platform/storage"
+	"github.com/influxdata/platform/tsdb"
+)
+
+func TestEngine_ValidatePoints(t *testing.T) {
+	config := storage.NewConfig()
+	config.ValidateKeys = true
+	engine := NewEngine(config)
+	defer engine.Close()
+	engine.MustOpen()
+
+	org, bucket := platform.ID(1), platform.ID(2)
+	explode := func(pts ...models.Point) []models.Point {
+		points, err := tsdb.ExplodePoints(org, bucket, pts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return points
+	}
+
+	if err := engine.Engine.WritePoints(explode(
+		models.MustNewPoint("cpu", models.NewTags(map[string]string{"host": "a"}), map[string]interface{}{"usage": 1.0}, time.Unix(1, 0)),
+	)); err != nil {
+		t.Fatal(err)
+	}
+
+	// The series of host a exists, and the points of host b conflict with the float
+	// usage of cpu and the integer idle of the point before them.
+	v := storage.NewWriteValidation()
+	err := engine.ValidatePoints(v, explode(
+		models.MustNewPoint("cpu", models.NewTags(map[string]string{"host": "a"}), map[string]interface{}{"usage": 2.0, "idle": int64(1)}, time.Unix(2, 0)),
+		models.MustNewPoint("cpu", models.NewTags(map[string]string{"host": "b"}), map[string]interface{}{"usage": int64(2)}, time.Unix(2, 0)),
+		models.MustNewPoint("cpu", models.NewTags(map[string]string{"host": "b"}), map[string]interface{}{"idle": 3.0}, time.Unix(2, 0)),
+		models.MustNewPoint("mem", models.NewTags(map[string]string{"host": "a"}), map[string]interface{}{"free": 1.0}, time.Unix(2, 0)),
+		models.MustNewPoint("mem", models.NewTags(map[string]string{"host": "a"}), map[string]interface{}{"free": 2.0}, time.Unix(3, 0)),
+		models.MustNewPoint("log", models.NewTags(map[string]string{"host": "\xff"}), map[string]interface{}{"msg": "a"}, time.Unix(2, 0)),
+	))
+	pwe, ok := err.(tsdb.PartialWriteError)
+	if !ok {
+		t.Fatalf("got error %v, expected a partial write", err)
+	}
+	expConflicts := map[int]tsdb.FieldTypeConflictError{
+		2: {Measurement: "cpu", Field: "usage", Type: platform.FieldTypeInteger, ExistingType: platform.FieldTypeFloat},
+		3: {Measurement: "cpu", Field: "idle", Type: platform.FieldTypeFloat, ExistingType: platform.FieldTypeInteger},
+	}
+	if !reflect.DeepEqual(pwe.Conflicts, expConflicts) {
+		t.Errorf("got conflicts %v, expected %v", pwe.Conflicts, expConflicts)
+	}
+	if pwe.Dropped != 3 || len(pwe.DroppedKeys) != 1 {
+		t.Errorf("got %d dropped with %d invalid keys, expected 3 with 1", pwe.Dropped, len(pwe.DroppedKeys))
+	}
+
+	// The idle of host a, and the free of host a, are new series.
+	if v.NewSeries != 2 || v.TypeConflicts != 2 {
+		t.Errorf("got %d new series and %d conflicts, expected 2 and 2", v.NewSeries, v.TypeConflicts)
+	}
+	if exp := []string{"mem"}; !reflect.DeepEqual(v.NewMeasurements, exp) {
+		t.Errorf("got new measurements %v, expected %v", v.NewMeasurements, exp)
+	}
+
+	// Nothing was written.
+	schema, err := engine.FindBucketSchema(nil, org, bucket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []platform.MeasurementSchema{
+		{Name: "cpu", Fields: []platform.FieldSchema{{Name: "usage", Type: platform.FieldTypeFloat}}},
+	}
+	if !reflect.DeepEqual(schema.Measurements, exp) {
+		t.Errorf("got measurements %v after validating, expected %v", schema.Measurements, exp)
+	}
+	if n := engine.SeriesCardinality(); n != 1 {
+		t.Errorf("got %d series after validating, expected 1", n)
+	}
+}
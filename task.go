@@ -1,6 +1,9 @@
 package platform
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // Task is a task. 🎊
 type Task struct {
@@ -13,6 +16,10 @@ type Task struct {
 	Every        string `json:"every,omitempty"`
 	Cron         string `json:"cron,omitempty"`
 	Delay        string `json:"delay,omitempty"`
+	Revision     int    `json:"revision,omitempty"`
+
+	// Labels are free-form key/value pairs used to categorize tasks.
+	Labels map[string]string `json:"labels,omitempty"`
 }
 
 // Run is a record created when a run of a task is scheduled.
@@ -24,9 +31,47 @@ type Run struct {
 	StartedAt    string `json:"startedAt,omitempty"`
 	FinishedAt   string `json:"finishedAt,omitempty"`
 	RequestedAt  string `json:"requestedAt,omitempty"`
+	Revision     int    `json:"revision,omitempty"`
 	Log          Log    `json:"log"`
 }
 
+// TaskRevision is a recorded version of a task's script.
+// A new revision is recorded every time a task is created or its script is changed.
+type TaskRevision struct {
+	TaskID      ID     `json:"taskId"`
+	Revision    int    `json:"revision"`
+	Author      ID     `json:"author"`
+	CreatedAt   string `json:"createdAt"`
+	Name        string `json:"name"`
+	Flux        string `json:"flux"`
+	Every       string `json:"every,omitempty"`
+	Cron        string `json:"cron,omitempty"`
+	Delay       string `json:"delay,omitempty"`
+	Concurrency int64  `json:"concurrency"`
+	Retry       int64  `json:"retry"`
+}
+
+// TaskStats summarizes the runs of a task since it was last claimed by a scheduler.
+type TaskStats struct {
+	TaskID        ID    `json:"taskId"`
+	RunsSucceeded int64 `json:"runsSucceeded"`
+	RunsFailed    int64 `json:"runsFailed"`
+	RunsCanceled  int64 `json:"runsCanceled"`
+
+	// RowsWritten is the total number of rows produced by the task's runs.
+	RowsWritten int64 `json:"rowsWritten"`
+
+	LastRunDuration time.Duration `json:"lastRunDuration"`
+	MeanRunDuration time.Duration `json:"meanRunDuration"`
+	MaxRunDuration  time.Duration `json:"maxRunDuration"`
+
+	// Lag is how long after its scheduled time a run started.
+	LastLag time.Duration `json:"lastLag"`
+	MaxLag  time.Duration `json:"maxLag"`
+
+	LastFinishedAt time.Time `json:"lastFinishedAt,omitempty"`
+}
+
 // Log represents a link to a log resource
 type Log string
 
@@ -63,12 +108,30 @@ type TaskService interface {
 	// RetryRun creates and returns a new run (which is a retry of another run).
 	// The requestedAt parameter is the Unix timestamp that will be recorded for the retry.
 	RetryRun(ctx context.Context, taskID, runID ID, requestedAt int64) error
+
+	// FindTaskRevisions returns all recorded revisions of a task's script, oldest first,
+	// and the total count of revisions.
+	FindTaskRevisions(ctx context.Context, taskID ID) ([]*TaskRevision, int, error)
+
+	// FindTaskRevision returns a single revision of a task's script.
+	FindTaskRevision(ctx context.Context, taskID ID, revision int) (*TaskRevision, error)
+
+	// RollbackTask replaces a task's script with the script of an earlier revision.
+	// The rollback itself is recorded as a new revision.
+	RollbackTask(ctx context.Context, taskID ID, revision int) (*Task, error)
+
+	// FindTaskStats returns statistics about the runs of a task.
+	FindTaskStats(ctx context.Context, taskID ID) (*TaskStats, error)
 }
 
 // TaskUpdate represents updates to a task
 type TaskUpdate struct {
 	Flux   *string `json:"flux,omitempty"`
 	Status *string `json:"status,omitempty"`
+
+	// Labels replaces all of the task's labels.
+	// If nil, the labels are not modified; an empty map removes all labels.
+	Labels map[string]string `json:"labels"`
 }
 
 // TaskFilter represents a set of filters that restrict the returned results
@@ -76,6 +139,15 @@ type TaskFilter struct {
 	After        *ID
 	Organization *ID
 	User         *ID
+
+	// Name matches tasks whose name contains the given substring.
+	Name *string
+
+	// Status matches tasks with the given status.
+	Status *string
+
+	// Labels matches tasks that have every one of the given labels.
+	Labels map[string]string
 }
 
 // RunFilter represents a set of filters that restrict the returned results
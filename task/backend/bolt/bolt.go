@@ -9,6 +9,9 @@
 //    bucket(/tasks/v1/user_by_task_id) key(:task_id) -> The user ID (stored as encoded string) associated with given task.
 //    buket(/tasks/v1/name_by_task_id) key(:task_id) -> The user-supplied name of the script.
 //    bucket(/tasks/v1/run_ids) -> Counter for run IDs
+//    bucket(/tasks/v1/revisions).bucket(:task_id) key(:revision) -> JSON encoded backend.StoreTaskRevision.
+//                                    The sequence of the :task_id bucket is the task's current revision.
+//    bucket(/tasks/v1/labels_by_task_id) key(:task_id) -> JSON encoded labels of the task. Absent if the task has no labels.
 //    bucket(/tasks/v1/orgs).bucket(:org_id) key(:task_id) -> Empty content; presence of :task_id allows for lookup from org to tasks.
 //    bucket(/tasks/v1/users).bucket(:user_id) key(:task_id) -> Empty content; presence of :task_id allows for lookup from user to tasks.
 // Note that task IDs are stored big-endian uint64s for sorting purposes,
@@ -18,6 +21,8 @@ package bolt
 
 import (
 	"context"
+	"encoding/binary"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"time"
@@ -53,14 +58,16 @@ type Store struct {
 const basePath = "/tasks/v1/"
 
 var (
-	tasksPath    = []byte(basePath + "tasks")
-	orgsPath     = []byte(basePath + "orgs")
-	usersPath    = []byte(basePath + "users")
-	taskMetaPath = []byte(basePath + "task_meta")
-	orgByTaskID  = []byte(basePath + "org_by_task_id")
-	userByTaskID = []byte(basePath + "user_by_task_id")
-	nameByTaskID = []byte(basePath + "name_by_task_id")
-	runIDs       = []byte(basePath + "run_ids")
+	tasksPath      = []byte(basePath + "tasks")
+	orgsPath       = []byte(basePath + "orgs")
+	usersPath      = []byte(basePath + "users")
+	taskMetaPath   = []byte(basePath + "task_meta")
+	orgByTaskID    = []byte(basePath + "org_by_task_id")
+	userByTaskID   = []byte(basePath + "user_by_task_id")
+	nameByTaskID   = []byte(basePath + "name_by_task_id")
+	runIDs         = []byte(basePath + "run_ids")
+	revisions      = []byte(basePath + "revisions")
+	labelsByTaskID = []byte(basePath + "labels_by_task_id")
 )
 
 // New gives us a new Store based on "github.com/coreos/bbolt"
@@ -80,7 +87,7 @@ func New(db *bolt.DB, rootBucket string) (*Store, error) {
 		for _, b := range [][]byte{
 			tasksPath, orgsPath, usersPath, taskMetaPath,
 			orgByTaskID, userByTaskID,
-			nameByTaskID, runIDs,
+			nameByTaskID, runIDs, revisions, labelsByTaskID,
 		} {
 			_, err := root.CreateBucketIfNotExists(b)
 			if err != nil {
@@ -125,6 +132,11 @@ func (s *Store) CreateTask(ctx context.Context, req backend.CreateTaskRequest) (
 			return err
 		}
 
+		// labels
+		if err := putLabels(b, encodedID, req.Labels); err != nil {
+			return err
+		}
+
 		// Encode org ID
 		encodedOrg, err := req.Org.Encode()
 		if err != nil {
@@ -169,6 +181,17 @@ func (s *Store) CreateTask(ctx context.Context, req backend.CreateTaskRequest) (
 			return err
 		}
 
+		// first revision
+		if _, err := putRevision(b, encodedID, backend.StoreTaskRevision{
+			TaskID:    id,
+			Author:    req.User,
+			CreatedAt: time.Now().Unix(),
+			Script:    req.Script,
+			Options:   o,
+		}); err != nil {
+			return err
+		}
+
 		stm := backend.StoreTaskMeta{
 			MaxConcurrency:  int32(o.Concurrency),
 			Status:          string(req.Status),
@@ -217,6 +240,15 @@ func (s *Store) UpdateTask(ctx context.Context, req backend.UpdateTaskRequest) (
 		}
 		res.OldScript = string(v)
 
+		var userID, orgID platform.ID
+		if err := userID.Decode(b.Bucket(userByTaskID).Get(encodedID)); err != nil {
+			return err
+		}
+
+		if err := orgID.Decode(b.Bucket(orgByTaskID).Get(encodedID)); err != nil {
+			return err
+		}
+
 		newScript := req.Script
 		if req.Script == "" {
 			// Need to build op from existing script.
@@ -225,22 +257,46 @@ func (s *Store) UpdateTask(ctx context.Context, req backend.UpdateTaskRequest) (
 				return err
 			}
 			newScript = string(v)
-		} else {
+		} else if req.Script != string(v) {
+			// An unchanged script is not a new revision.
 			if err := bt.Put(encodedID, []byte(req.Script)); err != nil {
 				return err
 			}
 			if err := b.Bucket(nameByTaskID).Put(encodedID, []byte(op.Name)); err != nil {
 				return err
 			}
-		}
 
-		var userID, orgID platform.ID
-		if err := userID.Decode(b.Bucket(userByTaskID).Get(encodedID)); err != nil {
-			return err
-		}
+			// A task created before revisions were recorded keeps its current script as its
+			// first revision, so that it can be rolled back to.
+			if currentRevision(b, encodedID) == 0 {
+				oldOp, err := options.FromScript(string(v))
+				if err != nil {
+					return err
+				}
+				if _, err := putRevision(b, encodedID, backend.StoreTaskRevision{
+					TaskID:    req.ID,
+					Author:    userID,
+					CreatedAt: time.Now().Unix(),
+					Script:    string(v),
+					Options:   oldOp,
+				}); err != nil {
+					return err
+				}
+			}
 
-		if err := orgID.Decode(b.Bucket(orgByTaskID).Get(encodedID)); err != nil {
-			return err
+			author := req.User
+			if !author.Valid() {
+				author = userID
+			}
+			if _, err := putRevision(b, encodedID, backend.StoreTaskRevision{
+				TaskID:    req.ID,
+				Author:    author,
+				CreatedAt: time.Now().Unix(),
+				Script:    req.Script,
+				Options:   op,
+			}); err != nil {
+				return err
+			}
 		}
 
 		stmBytes := b.Bucket(taskMetaPath).Get(encodedID)
@@ -264,12 +320,24 @@ func (s *Store) UpdateTask(ctx context.Context, req backend.UpdateTaskRequest) (
 		}
 		res.NewMeta = stm
 
+		if req.Labels != nil {
+			if err := putLabels(b, encodedID, req.Labels); err != nil {
+				return err
+			}
+		}
+		labels, err := getLabels(b, encodedID)
+		if err != nil {
+			return err
+		}
+
 		res.NewTask = backend.StoreTask{
-			ID:     req.ID,
-			Org:    orgID,
-			User:   userID,
-			Name:   op.Name,
-			Script: newScript,
+			ID:       req.ID,
+			Org:      orgID,
+			User:     userID,
+			Name:     op.Name,
+			Script:   newScript,
+			Revision: currentRevision(b, encodedID),
+			Labels:   labels,
 		}
 
 		return nil
@@ -326,6 +394,27 @@ func (s *Store) ListTasks(ctx context.Context, params backend.TaskSearchParams)
 		} else {
 			c = b.Bucket(tasksPath).Cursor()
 		}
+
+		// matches reports whether the task with the given encoded ID matches the search filters of params.
+		matches := func(encodedID []byte) (bool, error) {
+			if !params.HasSearchFilters() {
+				return true, nil
+			}
+			labels, err := getLabels(b, encodedID)
+			if err != nil {
+				return false, err
+			}
+			var stm backend.StoreTaskMeta
+			if err := stm.Unmarshal(b.Bucket(taskMetaPath).Get(encodedID)); err != nil {
+				return false, err
+			}
+			t := backend.StoreTask{
+				Name:   string(b.Bucket(nameByTaskID).Get(encodedID)),
+				Labels: labels,
+			}
+			return params.MatchesSearch(t, backend.TaskStatus(stm.Status)), nil
+		}
+
 		if params.After.Valid() {
 			encodedAfter, err := params.After.Encode()
 			if err != nil {
@@ -333,6 +422,11 @@ func (s *Store) ListTasks(ctx context.Context, params backend.TaskSearchParams)
 			}
 			c.Seek(encodedAfter)
 			for k, _ := c.Next(); k != nil && len(taskIDs) < lim; k, _ = c.Next() {
+				if ok, err := matches(k); err != nil {
+					return err
+				} else if !ok {
+					continue
+				}
 				var nID platform.ID
 				if err := nID.Decode(k); err != nil {
 					return err
@@ -341,6 +435,11 @@ func (s *Store) ListTasks(ctx context.Context, params backend.TaskSearchParams)
 			}
 		} else {
 			for k, _ := c.First(); k != nil && len(taskIDs) < lim; k, _ = c.Next() {
+				if ok, err := matches(k); err != nil {
+					return err
+				} else if !ok {
+					continue
+				}
 				var nID platform.ID
 				if err := nID.Decode(k); err != nil {
 					return err
@@ -364,6 +463,11 @@ func (s *Store) ListTasks(ctx context.Context, params backend.TaskSearchParams)
 				tasks[i].Task.ID = taskIDs[i]
 				tasks[i].Task.Script = string(b.Bucket(tasksPath).Get(encodedID))
 				tasks[i].Task.Name = string(b.Bucket(nameByTaskID).Get(encodedID))
+				tasks[i].Task.Revision = currentRevision(b, encodedID)
+				tasks[i].Task.Labels, err = getLabels(b, encodedID)
+				if err != nil {
+					return err
+				}
 			}
 		}
 		if params.Org.Valid() {
@@ -462,6 +566,8 @@ func (s *Store) ListTasks(ctx context.Context, params backend.TaskSearchParams)
 func (s *Store) FindTaskByID(ctx context.Context, id platform.ID) (*backend.StoreTask, error) {
 	var userID, orgID platform.ID
 	var script, name string
+	var revision int
+	var labels map[string]string
 	encodedID, err := id.Encode()
 	if err != nil {
 		return nil, err
@@ -483,18 +589,23 @@ func (s *Store) FindTaskByID(ctx context.Context, id platform.ID) (*backend.Stor
 		}
 
 		name = string(b.Bucket(nameByTaskID).Get(encodedID))
-		return nil
+		revision = currentRevision(b, encodedID)
+		var err error
+		labels, err = getLabels(b, encodedID)
+		return err
 	})
 	if err != nil {
 		return nil, err
 	}
 
 	return &backend.StoreTask{
-		ID:     id,
-		Org:    orgID,
-		User:   userID,
-		Name:   name,
-		Script: script,
+		ID:       id,
+		Org:      orgID,
+		User:     userID,
+		Name:     name,
+		Script:   script,
+		Revision: revision,
+		Labels:   labels,
 	}, err
 }
 
@@ -523,6 +634,8 @@ func (s *Store) FindTaskByIDWithMeta(ctx context.Context, id platform.ID) (*back
 	var stmBytes []byte
 	var userID, orgID platform.ID
 	var script, name string
+	var revision int
+	var labels map[string]string
 	encodedID, err := id.Encode()
 	if err != nil {
 		return nil, nil, err
@@ -547,7 +660,10 @@ func (s *Store) FindTaskByIDWithMeta(ctx context.Context, id platform.ID) (*back
 		}
 
 		name = string(b.Bucket(nameByTaskID).Get(encodedID))
-		return nil
+		revision = currentRevision(b, encodedID)
+		var err error
+		labels, err = getLabels(b, encodedID)
+		return err
 	})
 	if err != nil {
 		return nil, nil, err
@@ -559,14 +675,82 @@ func (s *Store) FindTaskByIDWithMeta(ctx context.Context, id platform.ID) (*back
 	}
 
 	return &backend.StoreTask{
-		ID:     id,
-		Org:    orgID,
-		User:   userID,
-		Name:   name,
-		Script: script,
+		ID:       id,
+		Org:      orgID,
+		User:     userID,
+		Name:     name,
+		Script:   script,
+		Revision: revision,
+		Labels:   labels,
 	}, &stm, nil
 }
 
+// FindTaskRevisions returns all the revisions of a task's script, oldest first.
+func (s *Store) FindTaskRevisions(ctx context.Context, id platform.ID) ([]backend.StoreTaskRevision, error) {
+	encodedID, err := id.Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	var revs []backend.StoreTaskRevision
+	err = s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(s.bucket)
+		if b.Bucket(tasksPath).Get(encodedID) == nil {
+			return backend.ErrTaskNotFound
+		}
+
+		rb := b.Bucket(revisions).Bucket(encodedID)
+		if rb == nil {
+			// The task was created before revisions were recorded.
+			return nil
+		}
+
+		return rb.ForEach(func(_, v []byte) error {
+			var rev backend.StoreTaskRevision
+			if err := json.Unmarshal(v, &rev); err != nil {
+				return err
+			}
+			revs = append(revs, rev)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return revs, nil
+}
+
+// FindTaskRevision returns a single revision of a task's script.
+func (s *Store) FindTaskRevision(ctx context.Context, id platform.ID, revision int) (*backend.StoreTaskRevision, error) {
+	encodedID, err := id.Encode()
+	if err != nil {
+		return nil, err
+	}
+	if revision < 1 {
+		return nil, backend.ErrRevisionNotFound
+	}
+
+	var rev backend.StoreTaskRevision
+	err = s.db.View(func(tx *bolt.Tx) error {
+		rb := tx.Bucket(s.bucket).Bucket(revisions).Bucket(encodedID)
+		if rb == nil {
+			return backend.ErrRevisionNotFound
+		}
+
+		v := rb.Get(encodeRevision(revision))
+		if v == nil {
+			return backend.ErrRevisionNotFound
+		}
+		return json.Unmarshal(v, &rev)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &rev, nil
+}
+
 // DeleteTask deletes the task.
 func (s *Store) DeleteTask(ctx context.Context, id platform.ID) (deleted bool, err error) {
 	encodedID, err := id.Encode()
@@ -596,6 +780,12 @@ func (s *Store) DeleteTask(ctx context.Context, id platform.ID) (deleted bool, e
 		if err := b.Bucket(nameByTaskID).Delete(encodedID); err != nil {
 			return err
 		}
+		if err := deleteRevisions(b, encodedID); err != nil {
+			return err
+		}
+		if err := b.Bucket(labelsByTaskID).Delete(encodedID); err != nil {
+			return err
+		}
 
 		org := b.Bucket(orgByTaskID).Get(encodedID)
 		if len(org) > 0 {
@@ -753,6 +943,12 @@ func (s *Store) DeleteUser(ctx context.Context, id platform.ID) error {
 			if err := b.Bucket(nameByTaskID).Delete(k); err != nil {
 				return err
 			}
+			if err := deleteRevisions(b, k); err != nil {
+				return err
+			}
+			if err := b.Bucket(labelsByTaskID).Delete(k); err != nil {
+				return err
+			}
 
 			org := b.Bucket(orgByTaskID).Get(k)
 			if len(org) > 0 {
@@ -817,6 +1013,12 @@ func (s *Store) DeleteOrg(ctx context.Context, id platform.ID) error {
 			if err := b.Bucket(nameByTaskID).Delete(k); err != nil {
 				return err
 			}
+			if err := deleteRevisions(b, k); err != nil {
+				return err
+			}
+			if err := b.Bucket(labelsByTaskID).Delete(k); err != nil {
+				return err
+			}
 			user := b.Bucket(userByTaskID).Get(k)
 			if len(user) > 0 {
 				ub := b.Bucket(usersPath).Bucket(user)
@@ -836,3 +1038,79 @@ func (s *Store) DeleteOrg(ctx context.Context, id platform.ID) error {
 		}
 	})
 }
+
+// encodeRevision returns the key of a revision within a task's revisions bucket.
+func encodeRevision(revision int) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(revision))
+	return b
+}
+
+// putRevision stores rev as the next revision of the task with the given encoded ID.
+// The revision number is assigned from the sequence of the task's revisions bucket and returned.
+func putRevision(b *bolt.Bucket, encodedID []byte, rev backend.StoreTaskRevision) (int, error) {
+	rb, err := b.Bucket(revisions).CreateBucketIfNotExists(encodedID)
+	if err != nil {
+		return 0, err
+	}
+
+	seq, err := rb.NextSequence()
+	if err != nil {
+		return 0, err
+	}
+	rev.Revision = int(seq)
+
+	v, err := json.Marshal(rev)
+	if err != nil {
+		return 0, err
+	}
+
+	return rev.Revision, rb.Put(encodeRevision(rev.Revision), v)
+}
+
+// currentRevision returns the latest revision number of the task with the given encoded ID,
+// or 0 if the task has no recorded revisions.
+func currentRevision(b *bolt.Bucket, encodedID []byte) int {
+	rb := b.Bucket(revisions).Bucket(encodedID)
+	if rb == nil {
+		return 0
+	}
+	return int(rb.Sequence())
+}
+
+// deleteRevisions removes all the recorded revisions of the task with the given encoded ID.
+func deleteRevisions(b *bolt.Bucket, encodedID []byte) error {
+	if err := b.Bucket(revisions).DeleteBucket(encodedID); err != nil && err != bolt.ErrBucketNotFound {
+		return err
+	}
+	return nil
+}
+
+// putLabels stores the labels of the task with the given encoded ID.
+// Empty labels are not stored.
+func putLabels(b *bolt.Bucket, encodedID []byte, labels map[string]string) error {
+	lb := b.Bucket(labelsByTaskID)
+	if len(labels) == 0 {
+		return lb.Delete(encodedID)
+	}
+
+	v, err := json.Marshal(labels)
+	if err != nil {
+		return err
+	}
+	return lb.Put(encodedID, v)
+}
+
+// getLabels returns the labels of the task with the given encoded ID, or nil if it has none.
+func getLabels(b *bolt.Bucket, encodedID []byte) (map[string]string, error) {
+	v := b.Bucket(labelsByTaskID).Get(encodedID)
+	if v == nil {
+		return nil, nil
+	}
+
+	var labels map[string]string
+	if err := json.Unmarshal(v, &labels); err != nil {
+		return nil, err
+	}
+	return labels, nil
+}
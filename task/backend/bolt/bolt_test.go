@@ -1,6 +1,7 @@
 package bolt_test
 
 import (
+	"context"
 	"io/ioutil"
 	"os"
 	"testing"
@@ -49,3 +50,86 @@ func TestBoltStore(t *testing.T) {
 		},
 	)(t)
 }
+
+func TestBoltStore_UpdateTaskWithoutRevisions(t *testing.T) {
+	const script = `option task = {
+		name: "a task",
+		every: 1m,
+	}
+
+from(bucket:"x") |> range(start:-1h)`
+
+	const script2 = `option task = {
+		name: "a task",
+		every: 5m,
+	}
+
+from(bucket:"y") |> range(start:-1h)`
+
+	f, err := ioutil.TempFile("", "influx_bolt_task_store_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+
+	db, err := bolt.Open(f.Name(), os.ModeTemporary, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := boltstore.New(db, "testbucket")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	ctx := context.Background()
+	id, err := s.CreateTask(ctx, backend.CreateTaskRequest{Org: 1, User: 2, Script: script})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Remove the revisions of the task, as for a task created before revisions were recorded.
+	if err := db.Update(func(tx *bolt.Tx) error {
+		encodedID, err := id.Encode()
+		if err != nil {
+			return err
+		}
+		return tx.Bucket([]byte("testbucket")).Bucket([]byte("/tasks/v1/revisions")).DeleteBucket(encodedID)
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := s.UpdateTask(ctx, backend.UpdateTaskRequest{ID: id, Script: script2, User: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.NewTask.Revision != 2 {
+		t.Fatalf("expected updated task to be at revision 2, got %d", res.NewTask.Revision)
+	}
+
+	rev, err := s.FindTaskRevision(ctx, id, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rev.Script != script || rev.Author != 2 {
+		t.Fatalf("expected the original script by the owner as revision 1, got %q by %s", rev.Script, rev.Author)
+	}
+
+	// Roll the task back to its original script.
+	res, err = s.UpdateTask(ctx, backend.UpdateTaskRequest{ID: id, Script: rev.Script})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.NewTask.Script != script || res.NewTask.Revision != 3 {
+		t.Fatalf("expected rolled back task at revision 3, got revision %d with script %q", res.NewTask.Revision, res.NewTask.Script)
+	}
+
+	revs, err := s.FindTaskRevisions(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revs) != 3 {
+		t.Fatalf("expected 3 revisions, got %d", len(revs))
+	}
+}
@@ -4,14 +4,18 @@ package executor
 
 import (
 	"context"
+	"errors"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/influxdata/flux"
 	"github.com/influxdata/flux/lang"
 	"github.com/influxdata/platform/logger"
 	"github.com/influxdata/platform/query"
+	"github.com/influxdata/platform/query/functions/outputs"
 	"github.com/influxdata/platform/task/backend"
+	"github.com/influxdata/platform/task/options"
 	"go.uber.org/zap"
 )
 
@@ -38,7 +42,12 @@ func (e *queryServiceExecutor) Execute(ctx context.Context, run backend.QueuedRu
 		return nil, err
 	}
 
-	return newSyncRunPromise(ctx, run, e, t), nil
+	opts, err := options.FromScript(t.Script)
+	if err != nil {
+		return nil, err
+	}
+
+	return newSyncRunPromise(ctx, run, e, t, opts), nil
 }
 
 func (e *queryServiceExecutor) Wait() {
@@ -50,6 +59,7 @@ type syncRunPromise struct {
 	qr     backend.QueuedRun
 	svc    query.QueryService
 	t      *backend.StoreTask
+	opts   options.Options
 	ctx    context.Context
 	cancel context.CancelFunc
 	logger *zap.Logger
@@ -63,14 +73,20 @@ type syncRunPromise struct {
 
 var _ backend.RunPromise = (*syncRunPromise)(nil)
 
-func newSyncRunPromise(ctx context.Context, qr backend.QueuedRun, e *queryServiceExecutor, t *backend.StoreTask) *syncRunPromise {
-	ctx, cancel := context.WithCancel(ctx)
+func newSyncRunPromise(ctx context.Context, qr backend.QueuedRun, e *queryServiceExecutor, t *backend.StoreTask, opts options.Options) *syncRunPromise {
+	var cancel context.CancelFunc
+	if opts.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
 	opLogger := e.logger.With(zap.Stringer("task_id", qr.TaskID), zap.Stringer("run_id", qr.RunID))
 	log, logEnd := logger.NewOperation(opLogger, "Executing task", "execute")
 	rp := &syncRunPromise{
 		qr:     qr,
 		svc:    e.svc,
 		t:      t,
+		opts:   opts,
 		logger: log,
 		logEnd: logEnd,
 		ctx:    ctx,
@@ -133,6 +149,9 @@ func (p *syncRunPromise) doQuery(wg *sync.WaitGroup) {
 		p.finish(nil, err)
 		return
 	}
+	spec.Resources.MemoryBytesQuota = p.opts.MemoryLimit
+	limit := &outputs.RowLimit{Max: p.opts.RowLimit}
+	outputs.SetRowLimit(spec, limit)
 
 	req := &query.Request{
 		OrganizationID: p.t.Org,
@@ -142,6 +161,9 @@ func (p *syncRunPromise) doQuery(wg *sync.WaitGroup) {
 	}
 	it, err := p.svc.Query(p.ctx, req)
 	if err != nil {
+		if p.timedOut() {
+			err = backend.ErrRunTimedOut
+		}
 		// Assume the error should not be part of the runResult.
 		p.finish(nil, err)
 		return
@@ -149,16 +171,36 @@ func (p *syncRunPromise) doQuery(wg *sync.WaitGroup) {
 	defer it.Release()
 
 	// Drain the result iterator.
+	var rows rowCounter
 	for it.More() {
 		// Consume the full iterator so that we don't leak outstanding iterators.
 		res := it.Next()
-		if err := exhaustResultIterators(res); err != nil {
+		if err := exhaustResultIterators(res, &rows); err != nil {
+			if limit.Exceeded() {
+				// Finishing cancels the query, so the remaining results don't need to be drained.
+				p.finish(&runResult{err: errRowLimitExceeded, rows: rows.count()}, nil)
+				return
+			}
 			p.logger.Info("Error exhausting result iterator", zap.Error(err), zap.String("name", res.Name()))
 		}
 	}
 
+	if p.timedOut() {
+		p.finish(nil, backend.ErrRunTimedOut)
+		return
+	}
+
+	err = it.Err()
+	if err != nil && limit.Exceeded() {
+		err = errRowLimitExceeded
+	}
 	// Is it okay to assume it.Err will be set if the query context is canceled?
-	p.finish(&runResult{err: it.Err()}, nil)
+	p.finish(&runResult{err: err, rows: rows.count()}, nil)
+}
+
+// timedOut reports whether p's context was canceled because the task's timeout elapsed.
+func (p *syncRunPromise) timedOut() bool {
+	return p.ctx.Err() == context.DeadlineExceeded
 }
 
 func (p *syncRunPromise) cancelOnContextDone(wg *sync.WaitGroup) {
@@ -168,10 +210,14 @@ func (p *syncRunPromise) cancelOnContextDone(wg *sync.WaitGroup) {
 	case <-p.ready:
 		// Nothing to do.
 	case <-p.ctx.Done():
-		// Maybe the parent context was canceled,
+		// Maybe the parent context was canceled, or the run timed out,
 		// or maybe finish was called already.
 		// If it's the latter, this call to finish will be a no-op.
-		p.finish(nil, p.ctx.Err())
+		err := p.ctx.Err()
+		if p.timedOut() {
+			err = backend.ErrRunTimedOut
+		}
+		p.finish(nil, err)
 	}
 }
 
@@ -196,10 +242,18 @@ func (e *asyncQueryServiceExecutor) Execute(ctx context.Context, run backend.Que
 		return nil, err
 	}
 
+	opts, err := options.FromScript(t.Script)
+	if err != nil {
+		return nil, err
+	}
+
 	spec, err := flux.Compile(ctx, t.Script, time.Unix(run.Now, 0))
 	if err != nil {
 		return nil, err
 	}
+	spec.Resources.MemoryBytesQuota = opts.MemoryLimit
+	limit := &outputs.RowLimit{Max: opts.RowLimit}
+	outputs.SetRowLimit(spec, limit)
 
 	req := &query.Request{
 		OrganizationID: t.Org,
@@ -212,7 +266,7 @@ func (e *asyncQueryServiceExecutor) Execute(ctx context.Context, run backend.Que
 		return nil, err
 	}
 
-	return newAsyncRunPromise(run, q, e), nil
+	return newAsyncRunPromise(run, q, e, opts, limit), nil
 }
 
 func (e *asyncQueryServiceExecutor) Wait() {
@@ -224,6 +278,10 @@ type asyncRunPromise struct {
 	qr backend.QueuedRun
 	q  flux.Query
 
+	limit   *outputs.RowLimit
+	rows    rowCounter
+	timeout *time.Timer // Fires when the task's timeout elapses. Nil if there is no timeout.
+
 	logger *zap.Logger
 	logEnd func()
 
@@ -235,19 +293,24 @@ type asyncRunPromise struct {
 
 var _ backend.RunPromise = (*asyncRunPromise)(nil)
 
-func newAsyncRunPromise(qr backend.QueuedRun, q flux.Query, e *asyncQueryServiceExecutor) *asyncRunPromise {
+func newAsyncRunPromise(qr backend.QueuedRun, q flux.Query, e *asyncQueryServiceExecutor, opts options.Options, limit *outputs.RowLimit) *asyncRunPromise {
 	opLogger := e.logger.With(zap.Stringer("task_id", qr.TaskID), zap.Stringer("run_id", qr.RunID))
 	log, logEnd := logger.NewOperation(opLogger, "Executing task", "execute")
 
 	p := &asyncRunPromise{
 		qr:    qr,
 		q:     q,
+		limit: limit,
 		ready: make(chan struct{}),
 
 		logger: log,
 		logEnd: logEnd,
 	}
 
+	if opts.Timeout > 0 {
+		p.timeout = time.NewTimer(opts.Timeout)
+	}
+
 	e.wg.Add(1)
 	go p.followQuery(&e.wg)
 	return p
@@ -279,15 +342,28 @@ func (p *asyncRunPromise) followQuery(wg *sync.WaitGroup) {
 	// Always need to call Done after query is finished.
 	defer p.q.Done()
 
+	// A nil channel blocks forever, so a run without a timeout never times out.
+	var timeout <-chan time.Time
+	if p.timeout != nil {
+		defer p.timeout.Stop()
+		timeout = p.timeout.C
+	}
+
 	select {
 	case <-p.ready:
 		// The promise was finished somewhere else, so we don't need to call p.finish.
 		// But we do need to cancel the flux. This could be a no-op.
 		p.q.Cancel()
+	case <-timeout:
+		p.finish(nil, backend.ErrRunTimedOut)
+		p.q.Cancel()
 	case results, ok := <-p.q.Ready():
 		if !ok {
 			// Something went wrong with the flux. Set the error in the run result.
 			rr := &runResult{err: p.q.Err()}
+			if p.limit.Exceeded() {
+				rr.err = errRowLimitExceeded
+			}
 			p.finish(rr, nil)
 			return
 		}
@@ -299,16 +375,20 @@ func (p *asyncRunPromise) followQuery(wg *sync.WaitGroup) {
 			r := res
 			go func() {
 				defer wg.Done()
-				if err := exhaustResultIterators(r); err != nil {
+				if err := exhaustResultIterators(r, &p.rows); err != nil && !p.limit.Exceeded() {
 					p.logger.Info("Error exhausting result iterator", zap.Error(err), zap.String("name", r.Name()))
 				}
 			}()
 		}
 		wg.Wait()
 
+		if p.limit.Exceeded() {
+			p.finish(&runResult{err: errRowLimitExceeded, rows: p.rows.count()}, nil)
+			return
+		}
+
 		// Otherwise, query was successful.
-		// TODO(mr): collect query statistics, once RunResult interface supports them?
-		p.finish(new(runResult), nil)
+		p.finish(&runResult{rows: p.rows.count()}, nil)
 	}
 }
 
@@ -332,17 +412,41 @@ func (p *asyncRunPromise) finish(res *runResult, err error) {
 type runResult struct {
 	err       error
 	retryable bool
+	rows      int64
 }
 
 var _ backend.RunResult = (*runResult)(nil)
 
-func (rr *runResult) Err() error        { return rr.err }
-func (rr *runResult) IsRetryable() bool { return rr.retryable }
+func (rr *runResult) Err() error         { return rr.err }
+func (rr *runResult) IsRetryable() bool  { return rr.retryable }
+func (rr *runResult) RowsWritten() int64 { return rr.rows }
+
+// errRowLimitExceeded is the error of a run that would write more rows than its task's rowLimit option allows.
+// The limit is enforced by the run's to() calls before they write, so no rows beyond the limit are written.
+var errRowLimitExceeded = errors.New("run exceeded the row limit of its task")
+
+// rowCounter counts the rows produced by a run.
+// It is safe for concurrent use.
+type rowCounter struct {
+	n int64 // Accessed atomically.
+}
+
+// add counts n more rows.
+func (c *rowCounter) add(n int) {
+	atomic.AddInt64(&c.n, int64(n))
+}
+
+// count returns the number of rows counted so far.
+func (c *rowCounter) count() int64 {
+	return atomic.LoadInt64(&c.n)
+}
 
-// exhaustResultIterators drains all the iterators from a flux query Result.
-func exhaustResultIterators(res flux.Result) error {
+// exhaustResultIterators drains all the iterators from a flux query Result,
+// counting the rows in rows.
+func exhaustResultIterators(res flux.Result, rows *rowCounter) error {
 	return res.Tables().Do(func(tbl flux.Table) error {
-		return tbl.Do(func(flux.ColReader) error {
+		return tbl.Do(func(cr flux.ColReader) error {
+			rows.add(cr.Len())
 			return nil
 		})
 	})
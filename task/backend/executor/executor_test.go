@@ -258,6 +258,7 @@ func TestExecutor(t *testing.T) {
 		testExecutorQueryFailure(t, fn)
 		testExecutorPromiseCancel(t, fn)
 		testExecutorServiceError(t, fn)
+		testExecutorTimeout(t, fn)
 		testExecutorWait(t, fn)
 	}
 }
@@ -384,6 +385,39 @@ func testExecutorPromiseCancel(t *testing.T, fn createSysFn) {
 	})
 }
 
+func testExecutorTimeout(t *testing.T, fn createSysFn) {
+	var orgID = platformtesting.MustIDBase16("aaaaaaaaaaaaaaaa")
+	var userID = platformtesting.MustIDBase16("baaaaaaaaaaaaaab")
+	sys := fn()
+	t.Run(sys.name+"/Timeout", func(t *testing.T) {
+		t.Parallel()
+		script := fmt.Sprintf(`option task = {
+			name: %q,
+			every: 1m,
+			timeout: 1s,
+		}
+		from(bucket: "one") |> toHTTP(url: "http://example.com")`, t.Name())
+		tid, err := sys.st.CreateTask(context.Background(), backend.CreateTaskRequest{Org: orgID, User: userID, Script: script})
+		if err != nil {
+			t.Fatal(err)
+		}
+		qr := backend.QueuedRun{TaskID: tid, RunID: platform.ID(1), Now: 123}
+		rp, err := sys.ex.Execute(context.Background(), qr)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The query is never unblocked, so the run can only finish by timing out.
+		res, err := rp.Wait()
+		if err != backend.ErrRunTimedOut {
+			t.Fatalf("expected ErrRunTimedOut, got %v", err)
+		}
+		if res != nil {
+			t.Fatalf("expected nil result after timeout, got %#v", res)
+		}
+	})
+}
+
 func testExecutorServiceError(t *testing.T, fn createSysFn) {
 	var orgID = platformtesting.MustIDBase16("aaaaaaaaaaaaaaaa")
 	var userID = platformtesting.MustIDBase16("baaaaaaaaaaaaaab")
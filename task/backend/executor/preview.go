@@ -0,0 +1,62 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/functions/transformations"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/platform"
+	"github.com/influxdata/platform/query"
+	"github.com/influxdata/platform/query/functions/outputs"
+	"github.com/influxdata/platform/task/backend"
+	"github.com/influxdata/platform/task/options"
+)
+
+// Preview executes the script of t against svc as though it were run at now, without writing any data.
+// Every call to to() in the script is replaced by a yield named after the to() operation,
+// so the tables the task would have written are returned as results instead.
+//
+// If the script's task options are invalid, Preview returns a *platform.Error with code EInvalid.
+func Preview(ctx context.Context, svc query.QueryService, t *backend.StoreTask, now time.Time) (flux.ResultIterator, error) {
+	opts, err := options.FromScript(t.Script)
+	if err != nil {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  fmt.Sprintf("invalid task options: %v", err),
+			Op:   "task/executor.Preview",
+		}
+	}
+
+	spec, err := flux.Compile(ctx, t.Script, now)
+	if err != nil {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  fmt.Sprintf("failed to compile script: %v", err),
+			Op:   "task/executor.Preview",
+		}
+	}
+	spec.Resources.MemoryBytesQuota = opts.MemoryLimit
+	interceptTo(spec)
+
+	req := &query.Request{
+		OrganizationID: t.Org,
+		Compiler: lang.SpecCompiler{
+			Spec: spec,
+		},
+	}
+	return svc.Query(ctx, req)
+}
+
+// interceptTo replaces every to() operation in spec with a yield of the same name.
+// The operation IDs are kept, so the edges of the spec remain valid.
+func interceptTo(spec *flux.Spec) {
+	for _, op := range spec.Operations {
+		if op.Spec.Kind() != outputs.ToKind {
+			continue
+		}
+		op.Spec = &transformations.YieldOpSpec{Name: string(op.ID)}
+	}
+}
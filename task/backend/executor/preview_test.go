@@ -0,0 +1,70 @@
+package executor_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/functions/transformations"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/platform"
+	"github.com/influxdata/platform/query"
+	"github.com/influxdata/platform/task/backend"
+	"github.com/influxdata/platform/task/backend/executor"
+)
+
+// specQueryService records the spec of the last query it received.
+type specQueryService struct {
+	spec *flux.Spec
+}
+
+var errSpecRecorded = errors.New("spec recorded")
+
+func (s *specQueryService) Query(ctx context.Context, req *query.Request) (flux.ResultIterator, error) {
+	s.spec = req.Compiler.(lang.SpecCompiler).Spec
+	return nil, errSpecRecorded
+}
+
+func TestPreview_InterceptsTo(t *testing.T) {
+	const script = `option task = {name: "preview", every: 1m}
+from(bucket: "in") |> range(start: -1m) |> to(bucket: "out", org: "o")`
+
+	svc := new(specQueryService)
+	st := &backend.StoreTask{ID: 1, Org: 2, Script: script}
+	if _, err := executor.Preview(context.Background(), svc, st, time.Unix(123, 0)); err != errSpecRecorded {
+		t.Fatalf("expected the query to reach the query service, got %v", err)
+	}
+
+	var yields int
+	for _, op := range svc.spec.Operations {
+		if op.Spec.Kind() == "to" {
+			t.Fatalf("expected to() operation %q to be replaced", op.ID)
+		}
+		if y, ok := op.Spec.(*transformations.YieldOpSpec); ok {
+			if y.Name != string(op.ID) {
+				t.Fatalf("expected yield to be named %q, got %q", op.ID, y.Name)
+			}
+			yields++
+		}
+	}
+	if yields != 1 {
+		t.Fatalf("expected 1 yield, got %d", yields)
+	}
+}
+
+func TestPreview_InvalidOptions(t *testing.T) {
+	const script = `option task = {name: "preview"}
+from(bucket: "in") |> range(start: -1m)`
+
+	svc := new(specQueryService)
+	st := &backend.StoreTask{ID: 1, Org: 2, Script: script}
+	_, err := executor.Preview(context.Background(), svc, st, time.Unix(123, 0))
+	if platform.ErrorCode(err) != platform.EInvalid {
+		t.Fatalf("expected an invalid error, got %v", err)
+	}
+	if svc.spec != nil {
+		t.Fatal("expected no query to be executed")
+	}
+}
@@ -28,7 +28,7 @@ func (r *runReaderWriter) UpdateRunState(ctx context.Context, rlb RunLogBase, wh
 		switch status {
 		case RunStarted:
 			r.StartedAt = whenStr
-		case RunFail, RunSuccess, RunCanceled:
+		case RunFail, RunSuccess, RunCanceled, RunTimedOut:
 			r.FinishedAt = whenStr
 		}
 	}
@@ -42,6 +42,7 @@ func (r *runReaderWriter) UpdateRunState(ctx context.Context, rlb RunLogBase, wh
 			TaskID:       rlb.Task.ID,
 			Status:       status.String(),
 			ScheduledFor: sf.Format(time.RFC3339),
+			Revision:     rlb.Task.Revision,
 		}
 		if rlb.RequestedAt != 0 {
 			run.RequestedAt = time.Unix(rlb.RequestedAt, 0).UTC().Format(time.RFC3339)
@@ -25,14 +25,18 @@ type inmem struct {
 	tasks []StoreTask
 
 	runners map[string]StoreTaskMeta
+
+	// Task ID -> revisions of the task's script, oldest first.
+	revisions map[string][]StoreTaskRevision
 }
 
 // NewInMemStore returns a new in-memory store.
 // This store is not designed to be efficient, it is here for testing purposes.
 func NewInMemStore() Store {
 	return &inmem{
-		idgen:   snowflake.NewIDGenerator(),
-		runners: map[string]StoreTaskMeta{},
+		idgen:     snowflake.NewIDGenerator(),
+		runners:   map[string]StoreTaskMeta{},
+		revisions: map[string][]StoreTaskRevision{},
 	}
 }
 
@@ -53,12 +57,24 @@ func (s *inmem) CreateTask(_ context.Context, req CreateTaskRequest) (platform.I
 		Name: o.Name,
 
 		Script: req.Script,
+
+		Revision: 1,
+
+		Labels: copyLabels(req.Labels),
 	}
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	s.tasks = append(s.tasks, task)
+	s.revisions[id.String()] = []StoreTaskRevision{{
+		TaskID:    id,
+		Revision:  1,
+		Author:    req.User,
+		CreatedAt: time.Now().Unix(),
+		Script:    req.Script,
+		Options:   o,
+	}}
 
 	stm := StoreTaskMeta{
 		MaxConcurrency:  int32(o.Concurrency),
@@ -100,10 +116,45 @@ func (s *inmem) UpdateTask(_ context.Context, req UpdateTaskRequest) (UpdateTask
 			if err != nil {
 				return res, err
 			}
-		} else {
+		} else if req.Script != t.Script {
+			// An unchanged script is not a new revision.
 			t.Script = req.Script
+
+			author := req.User
+			if !author.Valid() {
+				author = t.User
+			}
+			revs := s.revisions[idStr]
+			if len(revs) == 0 {
+				// A task without revisions keeps its current script as its first revision,
+				// so that it can be rolled back to.
+				oldOp, err := options.FromScript(res.OldScript)
+				if err != nil {
+					return res, err
+				}
+				revs = append(revs, StoreTaskRevision{
+					TaskID:    t.ID,
+					Revision:  1,
+					Author:    t.User,
+					CreatedAt: time.Now().Unix(),
+					Script:    res.OldScript,
+					Options:   oldOp,
+				})
+			}
+			t.Revision = len(revs) + 1
+			s.revisions[idStr] = append(revs, StoreTaskRevision{
+				TaskID:    t.ID,
+				Revision:  t.Revision,
+				Author:    author,
+				CreatedAt: time.Now().Unix(),
+				Script:    req.Script,
+				Options:   op,
+			})
 		}
 		t.Name = op.Name
+		if req.Labels != nil {
+			t.Labels = copyLabels(req.Labels)
+		}
 
 		s.tasks[n] = t
 		res.NewTask = t
@@ -176,6 +227,9 @@ func (s *inmem) ListTasks(_ context.Context, params TaskSearchParams) ([]StoreTa
 		if user.Valid() && user != t.User {
 			continue
 		}
+		if params.HasSearchFilters() && !params.MatchesSearch(t, TaskStatus(s.runners[t.ID.String()].Status)) {
+			continue
+		}
 
 		out = append(out, StoreTaskWithMeta{Task: t})
 		if len(out) >= lim {
@@ -244,6 +298,32 @@ func (s *inmem) FindTaskMetaByID(ctx context.Context, id platform.ID) (*StoreTas
 	return &meta, nil
 }
 
+func (s *inmem) FindTaskRevisions(_ context.Context, id platform.ID) ([]StoreTaskRevision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	revs, ok := s.revisions[id.String()]
+	if !ok {
+		return nil, ErrTaskNotFound
+	}
+
+	// Return a copy of the revisions.
+	return append([]StoreTaskRevision(nil), revs...), nil
+}
+
+func (s *inmem) FindTaskRevision(_ context.Context, id platform.ID, revision int) (*StoreTaskRevision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	revs := s.revisions[id.String()]
+	if revision < 1 || revision > len(revs) {
+		return nil, ErrRevisionNotFound
+	}
+
+	rev := revs[revision-1]
+	return &rev, nil
+}
+
 func (s *inmem) DeleteTask(_ context.Context, id platform.ID) (deleted bool, err error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -263,6 +343,7 @@ func (s *inmem) DeleteTask(_ context.Context, id platform.ID) (deleted bool, err
 	// Delete entry from slice.
 	s.tasks = append(s.tasks[:idx], s.tasks[idx+1:]...)
 	delete(s.runners, id.String())
+	delete(s.revisions, id.String())
 	return true, nil
 }
 
@@ -361,6 +442,9 @@ func (s *inmem) delete(ctx context.Context, id platform.ID, f func(StoreTask) pl
 	for i := range deletingTasks {
 		delete(s.runners, s.tasks[i].ID.String())
 	}
+	for _, id := range deletingTasks {
+		delete(s.revisions, id.String())
+	}
 	s.tasks = newTasks
 	return nil
 }
@@ -382,3 +466,15 @@ func (s *inmem) DeleteOrg(ctx context.Context, id platform.ID) error {
 func (s *inmem) DeleteUser(ctx context.Context, id platform.ID) error {
 	return s.delete(ctx, id, getUser)
 }
+
+// copyLabels returns a copy of labels, or nil if labels is empty.
+func copyLabels(labels map[string]string) map[string]string {
+	if len(labels) == 0 {
+		return nil
+	}
+	c := make(map[string]string, len(labels))
+	for k, v := range labels {
+		c[k] = v
+	}
+	return c
+}
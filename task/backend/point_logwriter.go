@@ -14,6 +14,10 @@ const (
 	runIDField        = "runID"
 	scheduledForField = "scheduledFor"
 	requestedAtField  = "requestedAt"
+	revisionField     = "revision"
+	durationField     = "durationSeconds"
+	lagField          = "lagSeconds"
+	rowsWrittenField  = "rowsWritten"
 
 	taskIDTag = "taskID"
 	statusTag = "status"
@@ -28,7 +32,7 @@ type PointsWriter interface {
 	WritePoints(points []models.Point) error
 }
 
-// PointLogWriter writes task and run logs as time-series points.
+// PointLogWriter writes task and run logs, and run statistics, as time-series points.
 type PointLogWriter struct {
 	pointsWriter PointsWriter
 }
@@ -43,12 +47,15 @@ func (p *PointLogWriter) UpdateRunState(ctx context.Context, rlb RunLogBase, whe
 		models.NewTag([]byte(statusTag), []byte(status.String())),
 		models.NewTag([]byte(taskIDTag), []byte(rlb.Task.ID.String())),
 	}
-	fields := make(map[string]interface{}, 3)
+	fields := make(map[string]interface{}, 4)
 	fields[runIDField] = rlb.RunID.String()
 	fields[scheduledForField] = time.Unix(rlb.RunScheduledFor, 0).UTC().Format(time.RFC3339)
 	if rlb.RequestedAt != 0 {
 		fields[requestedAtField] = time.Unix(rlb.RequestedAt, 0).UTC().Format(time.RFC3339)
 	}
+	if rlb.Task.Revision != 0 {
+		fields[revisionField] = int64(rlb.Task.Revision)
+	}
 
 	pt, err := models.NewPoint("records", tags, fields, when)
 	if err != nil {
@@ -85,3 +92,31 @@ func (p *PointLogWriter) AddRunLog(ctx context.Context, rlb RunLogBase, when tim
 
 	return p.pointsWriter.WritePoints(exploded)
 }
+
+// WriteRunStats writes the statistics of a finished run as a point in the "stats" measurement,
+// so that the performance of a task can be monitored over time.
+func (p *PointLogWriter) WriteRunStats(ctx context.Context, rlb RunLogBase, stats RunStats) error {
+	tags := models.Tags{
+		models.NewTag([]byte(statusTag), []byte(stats.Status.String())),
+		models.NewTag([]byte(taskIDTag), []byte(rlb.Task.ID.String())),
+	}
+	fields := map[string]interface{}{
+		runIDField:        rlb.RunID.String(),
+		scheduledForField: stats.ScheduledFor.UTC().Format(time.RFC3339),
+		durationField:     stats.Duration().Seconds(),
+		lagField:          stats.Lag().Seconds(),
+		rowsWrittenField:  stats.RowsWritten,
+	}
+
+	pt, err := models.NewPoint("stats", tags, fields, stats.FinishedAt)
+	if err != nil {
+		return err
+	}
+
+	exploded, err := tsdb.ExplodePoints(rlb.Task.Org, taskSystemBucketID, []models.Point{pt})
+	if err != nil {
+		return err
+	}
+
+	return p.pointsWriter.WritePoints(exploded)
+}
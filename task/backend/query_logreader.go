@@ -268,6 +268,8 @@ func (re *runExtractor) extractRecord(cr flux.ColReader) error {
 				r.RequestedAt = cr.Strings(j)[i]
 			case scheduledForField:
 				r.ScheduledFor = cr.Strings(j)[i]
+			case revisionField:
+				r.Revision = int(cr.Ints(j)[i])
 			case "status":
 				r.Status = cr.Strings(j)[i]
 			case "runID":
@@ -284,7 +286,7 @@ func (re *runExtractor) extractRecord(cr flux.ColReader) error {
 				r.TaskID = *id
 			case RunStarted.String():
 				r.StartedAt = cr.Times(j)[i].Time().Format(time.RFC3339Nano)
-			case RunSuccess.String(), RunFail.String(), RunCanceled.String():
+			case RunSuccess.String(), RunFail.String(), RunCanceled.String(), RunTimedOut.String():
 				r.FinishedAt = cr.Times(j)[i].Time().Format(time.RFC3339Nano)
 			}
 		}
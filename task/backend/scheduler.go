@@ -19,6 +19,9 @@ var (
 	// ErrRunCanceled is returned from the RunResult when a Run is Canceled.  It is used mostly internally.
 	ErrRunCanceled = errors.New("run canceled")
 
+	// ErrRunTimedOut is returned from the RunResult when a Run exceeds its task's timeout option.
+	ErrRunTimedOut = errors.New("run timed out")
+
 	// ErrTaskNotClaimed is returned when attempting to operate against a task that must be claimed but is not.
 	ErrTaskNotClaimed = errors.New("task not claimed")
 
@@ -76,6 +79,7 @@ type RunPromise interface {
 	// Wait blocks until the run completes.
 	// Wait may be called concurrently.
 	// Subsequent calls to Wait will return identical values.
+	// If the run exceeds its task's timeout option, Wait returns nil, ErrRunTimedOut.
 	Wait() (RunResult, error)
 
 	// Cancel interrupts the RunFuture.
@@ -92,7 +96,8 @@ type RunResult interface {
 	// IsRetryable returns true if the error was non-terminal and the run is eligible for retry.
 	IsRetryable() bool
 
-	// TODO(mr): add more detail here like number of points written, execution time, etc.
+	// RowsWritten returns the number of rows produced by the run.
+	RowsWritten() int64
 }
 
 // Scheduler accepts tasks and handles their scheduling.
@@ -120,6 +125,9 @@ type Scheduler interface {
 
 	// Cancel stops an executing run.
 	CancelRun(ctx context.Context, taskID, runID platform.ID) error
+
+	// TaskStats returns statistics about the runs of a task since this scheduler claimed it.
+	TaskStats(taskID platform.ID) platform.TaskStats
 }
 
 // TickSchedulerOption is a option you can use to modify the schedulers behavior.
@@ -151,6 +159,22 @@ func WithLogger(logger *zap.Logger) TickSchedulerOption {
 	}
 }
 
+// WithRunStatsWriter sets a RunStatsWriter which is given the statistics of every finished run.
+// If not set, run statistics are only kept in memory.
+func WithRunStatsWriter(w RunStatsWriter) TickSchedulerOption {
+	return func(s *TickScheduler) {
+		s.statsWriter = w
+	}
+}
+
+// WithMaxTaskMetrics sets how many tasks have their own labeled Prometheus metrics.
+// The metrics of any further tasks are reported together, under the task ID "other".
+func WithMaxTaskMetrics(n int) TickSchedulerOption {
+	return func(s *TickScheduler) {
+		s.metrics.maxTasks = n
+	}
+}
+
 // NewScheduler returns a new scheduler with the given desired state and the given now UTC timestamp.
 func NewScheduler(desiredState DesiredState, executor Executor, lw LogWriter, now int64, opts ...TickSchedulerOption) *TickScheduler {
 	o := &TickScheduler{
@@ -162,6 +186,7 @@ func NewScheduler(desiredState DesiredState, executor Executor, lw LogWriter, no
 		logger:         zap.NewNop(),
 		wg:             &sync.WaitGroup{},
 		metrics:        newSchedulerMetrics(),
+		stats:          newTaskStatsTracker(),
 	}
 
 	for _, opt := range opts {
@@ -179,7 +204,9 @@ type TickScheduler struct {
 	now    int64
 	logger *zap.Logger
 
-	metrics *schedulerMetrics
+	metrics     *schedulerMetrics
+	stats       *taskStatsTracker
+	statsWriter RunStatsWriter
 
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -210,6 +237,11 @@ func (s *TickScheduler) CancelRun(_ context.Context, taskID, runID platform.ID)
 	return nil
 }
 
+// TaskStats returns statistics about the runs of a task since this scheduler claimed it.
+func (s *TickScheduler) TaskStats(taskID platform.ID) platform.TaskStats {
+	return s.stats.Get(taskID)
+}
+
 // Tick updates the time of the scheduler.
 // Any owned tasks who are due to execute and who have a free concurrency slot,
 // will begin a new execution.
@@ -352,6 +384,7 @@ func (s *TickScheduler) ReleaseTask(taskID platform.ID) error {
 	delete(s.taskSchedulers, taskID)
 
 	s.metrics.ReleaseTask(taskID.String())
+	s.stats.Delete(taskID)
 
 	return nil
 }
@@ -384,7 +417,9 @@ type taskScheduler struct {
 
 	logger *zap.Logger
 
-	metrics *schedulerMetrics
+	metrics     *schedulerMetrics
+	stats       *taskStatsTracker
+	statsWriter RunStatsWriter
 
 	nextDueMu     sync.RWMutex // Protects following fields.
 	nextDue       int64        // Unix timestamp of next due.
@@ -415,6 +450,8 @@ func newTaskScheduler(
 		running:       make(map[platform.ID]runCtx, meta.MaxConcurrency),
 		logger:        s.logger.With(zap.String("task_id", task.ID.String())),
 		metrics:       s.metrics,
+		stats:         s.stats,
+		statsWriter:   s.statsWriter,
 		nextDue:       firstDue,
 		nextDueSource: math.MinInt64,
 		hasQueue:      len(meta.ManualRuns) > 0,
@@ -571,7 +608,8 @@ func (r *runner) RestartRun(qr QueuedRun) bool {
 		r.ts.running[qr.RunID] = rCtx
 	}
 	r.ts.runningMu.Unlock()
-	go r.executeAndWait(rCtx.Context, qr, runLogger)
+	label := r.ts.metrics.StartRun(r.task.ID.String())
+	go r.executeAndWait(rCtx.Context, qr, label, runLogger)
 
 	r.updateRunState(qr, RunStarted, runLogger)
 	return true
@@ -606,7 +644,8 @@ func (r *runner) startFromWorking(now int64) {
 
 	runLogger.Info("Created run; beginning execution")
 	r.wg.Add(1)
-	go r.executeAndWait(ctx, qr, runLogger)
+	label := r.ts.metrics.StartRun(r.task.ID.String())
+	go r.executeAndWait(ctx, qr, label, runLogger)
 
 	r.updateRunState(qr, RunStarted, runLogger)
 }
@@ -618,18 +657,21 @@ func (r *runner) clearRunning(id platform.ID) {
 	r.ts.runningMu.Unlock()
 }
 
-func (r *runner) executeAndWait(ctx context.Context, qr QueuedRun, runLogger *zap.Logger) {
+// executeAndWait executes qr and waits for its result.
+// The run's metrics are reported under label, as returned by StartRun.
+func (r *runner) executeAndWait(ctx context.Context, qr QueuedRun, label string, runLogger *zap.Logger) {
 	defer r.wg.Done()
 
 	sp, spCtx := opentracing.StartSpanFromContext(ctx, "task.run.execution")
 	defer sp.Finish()
 
+	stats := RunStats{ScheduledFor: time.Unix(qr.Now, 0), StartedAt: time.Now()}
 	rp, err := r.executor.Execute(spCtx, qr)
 
 	if err != nil {
 		// TODO(mr): retry? and log error.
 		atomic.StoreUint32(r.state, runnerIdle)
-		r.updateRunState(qr, RunFail, runLogger)
+		r.finishRun(qr, label, RunFail, stats, runLogger)
 		return
 	}
 
@@ -651,40 +693,73 @@ func (r *runner) executeAndWait(ctx context.Context, qr QueuedRun, runLogger *za
 	}()
 
 	// TODO(mr): handle res.IsRetryable().
-	_, err = rp.Wait()
+	res, err := rp.Wait()
 	close(ready)
 	if err != nil {
 		if err == ErrRunCanceled {
 			_ = r.desiredState.FinishRun(r.ctx, qr.TaskID, qr.RunID)
-			r.updateRunState(qr, RunCanceled, runLogger)
+			r.finishRun(qr, label, RunCanceled, stats, runLogger)
 
 			// Move on to the next execution, for a canceled run.
 			r.startFromWorking(atomic.LoadInt64(r.ts.now))
 			return
 		}
+		if err == ErrRunTimedOut {
+			_ = r.desiredState.FinishRun(r.ctx, qr.TaskID, qr.RunID)
+			r.finishRun(qr, label, RunTimedOut, stats, runLogger)
+
+			// Release the run's concurrency slot and move on to the next execution.
+			r.startFromWorking(atomic.LoadInt64(r.ts.now))
+			return
+		}
 
 		runLogger.Info("Failed to wait for execution result", zap.Error(err))
 		// TODO(mr): retry?
-		r.updateRunState(qr, RunFail, runLogger)
+		r.finishRun(qr, label, RunFail, stats, runLogger)
 		atomic.StoreUint32(r.state, runnerIdle)
 		return
 	}
+	stats.RowsWritten = res.RowsWritten()
 
 	if err := r.desiredState.FinishRun(r.ctx, qr.TaskID, qr.RunID); err != nil {
 		runLogger.Info("Failed to finish run", zap.Error(err))
 		// TODO(mr): retry?
 		// Need to think about what it means if there was an error finishing a run.
 		atomic.StoreUint32(r.state, runnerIdle)
-		r.updateRunState(qr, RunFail, runLogger)
+		r.finishRun(qr, label, RunFail, stats, runLogger)
 		return
 	}
-	r.updateRunState(qr, RunSuccess, runLogger)
+	r.finishRun(qr, label, RunSuccess, stats, runLogger)
 	runLogger.Info("Execution succeeded")
 
 	// Check again if there is a new run available, without returning to idle state.
 	r.startFromWorking(atomic.LoadInt64(r.ts.now))
 }
 
+// finishRun records the statistics of a finished run, and then updates its state to s.
+func (r *runner) finishRun(qr QueuedRun, label string, s RunStatus, stats RunStats, runLogger *zap.Logger) {
+	stats.Status = s
+	stats.FinishedAt = time.Now()
+
+	r.ts.stats.Add(r.task.ID, stats)
+	r.ts.metrics.FinishRun(label, s == RunSuccess)
+	r.ts.metrics.RecordRun(label, stats)
+
+	if r.ts.statsWriter != nil {
+		rlb := RunLogBase{
+			Task:            r.task,
+			RunID:           qr.RunID,
+			RunScheduledFor: qr.Now,
+			RequestedAt:     qr.RequestedAt,
+		}
+		if err := r.ts.statsWriter.WriteRunStats(r.ctx, rlb, stats); err != nil {
+			runLogger.Info("Error writing run statistics", zap.Error(err))
+		}
+	}
+
+	r.updateRunState(qr, s, runLogger)
+}
+
 func (r *runner) updateRunState(qr QueuedRun, s RunStatus, runLogger *zap.Logger) {
 	rlb := RunLogBase{
 		Task:            r.task,
@@ -695,17 +770,15 @@ func (r *runner) updateRunState(qr QueuedRun, s RunStatus, runLogger *zap.Logger
 
 	switch s {
 	case RunStarted:
-		r.ts.metrics.StartRun(r.task.ID.String())
 		r.logWriter.AddRunLog(r.ctx, rlb, time.Now(), fmt.Sprintf("Started task from script: %q", r.task.Script))
 	case RunSuccess:
-		r.ts.metrics.FinishRun(r.task.ID.String(), true)
 		r.logWriter.AddRunLog(r.ctx, rlb, time.Now(), "Completed successfully")
 	case RunFail:
-		r.ts.metrics.FinishRun(r.task.ID.String(), false)
 		r.logWriter.AddRunLog(r.ctx, rlb, time.Now(), "Failed")
 	case RunCanceled:
-		r.ts.metrics.FinishRun(r.task.ID.String(), false)
 		r.logWriter.AddRunLog(r.ctx, rlb, time.Now(), "Canceled")
+	case RunTimedOut:
+		r.logWriter.AddRunLog(r.ctx, rlb, time.Now(), "Timed out")
 	default: // We are deliberately not handling RunQueued yet.
 		// There is not really a notion of being queued in this runner architecture.
 		runLogger.Warn("Unhandled run state", zap.Stringer("state", s))
@@ -1,10 +1,23 @@
 package backend
 
-import "github.com/prometheus/client_golang/prometheus"
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DefaultMaxTaskMetrics is the default number of tasks that have their own labeled metrics.
+const DefaultMaxTaskMetrics = 1000
+
+// otherTaskID is the task ID label of the metrics of tasks beyond the cardinality limit.
+const otherTaskID = "other"
 
 // schedulerMetrics is a collection of metrics relating to task scheduling.
 // All of its methods which accept task IDs, take them as strings,
 // under the assumption that it is at least somewhat likely the caller already has a stringified version of the ID.
+//
+// To bound the cardinality of the per-task metrics, only the first maxTasks tasks are labeled with their own ID.
+// A run is reported under the label resolved when it starts, so its gauges return to zero when it finishes.
 type schedulerMetrics struct {
 	totalRunsComplete *prometheus.CounterVec
 	totalRunsActive   prometheus.Gauge
@@ -12,8 +25,16 @@ type schedulerMetrics struct {
 	runsComplete *prometheus.CounterVec
 	runsActive   *prometheus.GaugeVec
 
+	runDuration *prometheus.HistogramVec
+	scheduleLag *prometheus.HistogramVec
+	rowsWritten *prometheus.CounterVec
+
 	claimsComplete *prometheus.CounterVec
 	claimsActive   prometheus.Gauge
+
+	mu       sync.Mutex          // Protects labeled.
+	labeled  map[string]struct{} // Task IDs that have their own labeled metrics.
+	maxTasks int                 // Zero means no limit.
 }
 
 func newSchedulerMetrics() *schedulerMetrics {
@@ -47,6 +68,27 @@ func newSchedulerMetrics() *schedulerMetrics {
 			Help:      "Total number of runs that have started but not yet completed, split out by task ID.",
 		}, []string{"task_id"}),
 
+		runDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "run_duration_seconds",
+			Help:      "Duration of completed runs, split out by task ID.",
+			Buckets:   prometheus.ExponentialBuckets(0.01, 4, 10),
+		}, []string{"task_id"}),
+		scheduleLag: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "schedule_lag_seconds",
+			Help:      "Time between when runs were scheduled for and when they started, split out by task ID.",
+			Buckets:   prometheus.ExponentialBuckets(0.01, 4, 10),
+		}, []string{"task_id"}),
+		rowsWritten: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "rows_written",
+			Help:      "Number of rows produced by completed runs, split out by task ID.",
+		}, []string{"task_id"}),
+
 		claimsComplete: prometheus.NewCounterVec(prometheus.CounterOpts{
 			Namespace: namespace,
 			Subsystem: subsystem,
@@ -59,6 +101,9 @@ func newSchedulerMetrics() *schedulerMetrics {
 			Name:      "claims_active",
 			Help:      "Total number of claims currently held.",
 		}),
+
+		labeled:  make(map[string]struct{}),
+		maxTasks: DefaultMaxTaskMetrics,
 	}
 }
 
@@ -69,26 +114,46 @@ func (sm *schedulerMetrics) PrometheusCollectors() []prometheus.Collector {
 		sm.totalRunsActive,
 		sm.runsComplete,
 		sm.runsActive,
+		sm.runDuration,
+		sm.scheduleLag,
+		sm.rowsWritten,
 		sm.claimsComplete,
 		sm.claimsActive,
 	}
 }
 
 // StartRun adjusts the metrics to indicate a run is in progress for the given task ID.
-func (sm *schedulerMetrics) StartRun(tid string) {
+// It returns the label the run is reported under, which must be passed to FinishRun and RecordRun.
+func (sm *schedulerMetrics) StartRun(tid string) string {
+	label := sm.taskLabel(tid)
 	sm.totalRunsActive.Inc()
-	sm.runsActive.WithLabelValues(tid).Inc()
+	sm.runsActive.WithLabelValues(label).Inc()
+	return label
 }
 
-// FinishRun adjusts the metrics to indicate a run is no longer in progress for the given task ID.
-func (sm *schedulerMetrics) FinishRun(tid string, succeeded bool) {
+// FinishRun adjusts the metrics to indicate a run started under the given label is no longer in progress.
+func (sm *schedulerMetrics) FinishRun(label string, succeeded bool) {
 	status := statusString(succeeded)
 
 	sm.totalRunsActive.Dec()
 	sm.totalRunsComplete.WithLabelValues(status).Inc()
 
-	sm.runsActive.WithLabelValues(tid).Dec()
-	sm.runsComplete.WithLabelValues(tid, status).Inc()
+	if !sm.hasLabel(label) {
+		// The task was released while the run was in progress, and its metrics were deleted.
+		return
+	}
+	sm.runsActive.WithLabelValues(label).Dec()
+	sm.runsComplete.WithLabelValues(label, status).Inc()
+}
+
+// RecordRun observes the duration, schedule lag and rows written of a finished run started under the given label.
+func (sm *schedulerMetrics) RecordRun(label string, stats RunStats) {
+	if !sm.hasLabel(label) {
+		return
+	}
+	sm.runDuration.WithLabelValues(label).Observe(stats.Duration().Seconds())
+	sm.scheduleLag.WithLabelValues(label).Observe(stats.Lag().Seconds())
+	sm.rowsWritten.WithLabelValues(label).Add(float64(stats.RowsWritten))
 }
 
 // ClaimTask adjusts the metrics to indicate the result of an attempted claim.
@@ -105,9 +170,50 @@ func (sm *schedulerMetrics) ClaimTask(succeeded bool) {
 // We are not (currently) tracking failed releases, so only call this on a successful release.
 func (sm *schedulerMetrics) ReleaseTask(tid string) {
 	sm.claimsActive.Dec()
+
+	sm.mu.Lock()
+	_, ok := sm.labeled[tid]
+	delete(sm.labeled, tid)
+	sm.mu.Unlock()
+	if !ok {
+		// The task was reported under otherTaskID, which other tasks may still be using.
+		return
+	}
+
 	sm.runsActive.DeleteLabelValues(tid)
 	sm.runsComplete.DeleteLabelValues(tid, statusString(true))
 	sm.runsComplete.DeleteLabelValues(tid, statusString(false))
+	sm.runDuration.DeleteLabelValues(tid)
+	sm.scheduleLag.DeleteLabelValues(tid)
+	sm.rowsWritten.DeleteLabelValues(tid)
+}
+
+// taskLabel returns the task ID label to use for the metrics of the given task ID.
+// Once maxTasks tasks have their own labels, any other task is labeled otherTaskID.
+func (sm *schedulerMetrics) taskLabel(tid string) string {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if _, ok := sm.labeled[tid]; ok {
+		return tid
+	}
+	if sm.maxTasks > 0 && len(sm.labeled) >= sm.maxTasks {
+		return otherTaskID
+	}
+	sm.labeled[tid] = struct{}{}
+	return tid
+}
+
+// hasLabel reports whether the metrics labeled with label still exist.
+func (sm *schedulerMetrics) hasLabel(label string) bool {
+	if label == otherTaskID {
+		return true
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	_, ok := sm.labeled[label]
+	return ok
 }
 
 func statusString(succeeded bool) string {
@@ -507,6 +507,22 @@ func TestScheduler_RunLog(t *testing.T) {
 	}
 
 	pollForRunStatus(t, rl, task.ID, 3, 2, backend.RunCanceled.String())
+
+	// One last run, which times out.
+	s.Tick(9)
+	promises, err = e.PollForNumberRunning(task.ID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pollForRunStatus(t, rl, task.ID, 4, 3, backend.RunStarted.String())
+
+	promises[0].Finish(nil, backend.ErrRunTimedOut)
+	if _, err := e.PollForNumberRunning(task.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	pollForRunStatus(t, rl, task.ID, 4, 3, backend.RunTimedOut.String())
 }
 
 func TestScheduler_Metrics(t *testing.T) {
@@ -579,7 +595,7 @@ func TestScheduler_Metrics(t *testing.T) {
 	}
 
 	// Runs active decreases as run finishes.
-	e.RunningFor(task.ID)[0].Finish(mock.NewRunResult(nil, false), nil)
+	e.RunningFor(task.ID)[0].Finish(mock.NewRunResult(nil, false).WithRowsWritten(5), nil)
 	if _, err := e.PollForNumberRunning(task.ID, 1); err != nil {
 		t.Fatal(err)
 	}
@@ -596,6 +612,18 @@ func TestScheduler_Metrics(t *testing.T) {
 	if got := *m.Counter.Value; got != 1 {
 		t.Fatalf("expected 1 run succeeded for task ID %s, got %v", task.ID.String(), got)
 	}
+	m = promtest.MustFindMetric(t, mfs, "task_scheduler_run_duration_seconds", map[string]string{"task_id": task.ID.String()})
+	if got := *m.Histogram.SampleCount; got != 1 {
+		t.Fatalf("expected 1 run duration observed for task ID %s, got %v", task.ID.String(), got)
+	}
+	m = promtest.MustFindMetric(t, mfs, "task_scheduler_schedule_lag_seconds", map[string]string{"task_id": task.ID.String()})
+	if got := *m.Histogram.SampleCount; got != 1 {
+		t.Fatalf("expected 1 schedule lag observed for task ID %s, got %v", task.ID.String(), got)
+	}
+	m = promtest.MustFindMetric(t, mfs, "task_scheduler_rows_written", map[string]string{"task_id": task.ID.String()})
+	if got := *m.Counter.Value; got != 5 {
+		t.Fatalf("expected 5 rows written for task ID %s, got %v", task.ID.String(), got)
+	}
 
 	e.RunningFor(task.ID)[0].Finish(mock.NewRunResult(nil, false), errors.New("failed to execute"))
 	if _, err := e.PollForNumberRunning(task.ID, 0); err != nil {
@@ -629,6 +657,9 @@ func TestScheduler_Metrics(t *testing.T) {
 	if m := promtest.FindMetric(mfs, "task_scheduler_runs_complete", map[string]string{"task_id": task.ID.String(), "status": "failure"}); m != nil {
 		t.Fatalf("expected metric to be removed after releasing a task, got %v", m)
 	}
+	if m := promtest.FindMetric(mfs, "task_scheduler_run_duration_seconds", map[string]string{"task_id": task.ID.String()}); m != nil {
+		t.Fatalf("expected metric to be removed after releasing a task, got %v", m)
+	}
 
 	m = promtest.MustFindMetric(t, mfs, "task_scheduler_claims_active", nil)
 	if got := *m.Gauge.Value; got != 0 {
@@ -636,6 +667,174 @@ func TestScheduler_Metrics(t *testing.T) {
 	}
 }
 
+func TestScheduler_MetricsCardinality(t *testing.T) {
+	d := mock.NewDesiredState()
+	e := mock.NewExecutor()
+	s := backend.NewScheduler(d, e, backend.NopLogWriter{}, 5, backend.WithMaxTaskMetrics(1))
+	s.Start(context.Background())
+	defer s.Stop()
+
+	reg := prom.NewRegistry()
+	reg.MustRegister(s.PrometheusCollectors()...)
+
+	meta := &backend.StoreTaskMeta{
+		MaxConcurrency:  1,
+		EffectiveCron:   "@every 1s",
+		LatestCompleted: 5,
+	}
+	for _, id := range []platform.ID{1, 2} {
+		d.SetTaskMeta(id, *meta)
+		if err := s.ClaimTask(&backend.StoreTask{ID: id}, meta); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s.Tick(6)
+	for _, id := range []platform.ID{1, 2} {
+		if _, err := e.PollForNumberRunning(id, 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Only one task may have its own label; the other is reported as "other".
+	mfs := promtest.MustGather(t, reg)
+	var labeled platform.ID
+	for _, id := range []platform.ID{1, 2} {
+		if promtest.FindMetric(mfs, "task_scheduler_runs_active", map[string]string{"task_id": id.String()}) != nil {
+			if labeled.Valid() {
+				t.Fatalf("expected only one task to have labeled metrics, got tasks %s and %s", labeled, id)
+			}
+			labeled = id
+		}
+	}
+	if !labeled.Valid() {
+		t.Fatal("expected one task to have labeled metrics")
+	}
+	m := promtest.MustFindMetric(t, mfs, "task_scheduler_runs_active", map[string]string{"task_id": "other"})
+	if got := *m.Gauge.Value; got != 1 {
+		t.Fatalf("expected 1 run active for other tasks, got %v", got)
+	}
+
+	// Releasing the labeled task frees its label,
+	// but the run of the other task finishes under the label it started with.
+	unlabeled := platform.ID(1)
+	if labeled == unlabeled {
+		unlabeled = 2
+	}
+	if err := s.ReleaseTask(labeled); err != nil {
+		t.Fatal(err)
+	}
+	e.RunningFor(unlabeled)[0].Finish(mock.NewRunResult(nil, false), nil)
+	if _, err := e.PollForNumberRunning(unlabeled, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	const attempts = 20
+	for i := 0; ; i++ {
+		mfs = promtest.MustGather(t, reg)
+		m = promtest.MustFindMetric(t, mfs, "task_scheduler_runs_active", map[string]string{"task_id": "other"})
+		if *m.Gauge.Value == 0 {
+			break
+		}
+		if i == attempts {
+			t.Fatalf("expected 0 runs active for other tasks, got %v", *m.Gauge.Value)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if m := promtest.FindMetric(mfs, "task_scheduler_runs_active", map[string]string{"task_id": unlabeled.String()}); m != nil {
+		t.Fatalf("expected no labeled metric for task %s, got %v", unlabeled, m)
+	}
+}
+
+func TestScheduler_TaskStats(t *testing.T) {
+	d := mock.NewDesiredState()
+	e := mock.NewExecutor()
+	s := backend.NewScheduler(d, e, backend.NopLogWriter{}, 5)
+	s.Start(context.Background())
+	defer s.Stop()
+
+	task := &backend.StoreTask{
+		ID: platform.ID(1),
+	}
+	meta := &backend.StoreTaskMeta{
+		MaxConcurrency:  1,
+		EffectiveCron:   "@every 1s",
+		LatestCompleted: 5,
+	}
+
+	d.SetTaskMeta(task.ID, *meta)
+	if err := s.ClaimTask(task, meta); err != nil {
+		t.Fatal(err)
+	}
+
+	if stats := s.TaskStats(task.ID); stats.TaskID != task.ID || stats.RunsSucceeded != 0 {
+		t.Fatalf("expected empty stats before any run, got %#v", stats)
+	}
+
+	s.Tick(6)
+	promises, err := e.PollForNumberRunning(task.ID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	promises[0].Finish(mock.NewRunResult(nil, false).WithRowsWritten(3), nil)
+	if _, err := e.PollForNumberRunning(task.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	pollForTaskStats(t, s, task.ID, 1)
+
+	s.Tick(7)
+	promises, err = e.PollForNumberRunning(task.ID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	promises[0].Finish(nil, errors.New("forced failure"))
+	if _, err := e.PollForNumberRunning(task.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	stats := pollForTaskStats(t, s, task.ID, 2)
+	if stats.RunsSucceeded != 1 || stats.RunsFailed != 1 {
+		t.Fatalf("expected 1 succeeded and 1 failed run, got %#v", stats)
+	}
+	if stats.RowsWritten != 3 {
+		t.Fatalf("expected 3 rows written, got %d", stats.RowsWritten)
+	}
+	// The runs were scheduled for 1970, so they started long after they were due.
+	if stats.MaxLag < time.Hour {
+		t.Fatalf("expected a large schedule lag, got %v", stats.MaxLag)
+	}
+	if stats.LastFinishedAt.IsZero() {
+		t.Fatal("expected the finish time of the last run to be recorded")
+	}
+
+	// Stats are forgotten once the task is released.
+	if err := s.ReleaseTask(task.ID); err != nil {
+		t.Fatal(err)
+	}
+	if stats := s.TaskStats(task.ID); stats.RunsSucceeded != 0 || stats.RunsFailed != 0 {
+		t.Fatalf("expected stats to be reset after release, got %#v", stats)
+	}
+}
+
+// pollForTaskStats waits for the scheduler to record count finished runs of the given task, and returns its stats.
+func pollForTaskStats(t *testing.T, s *backend.TickScheduler, taskID platform.ID, count int64) platform.TaskStats {
+	t.Helper()
+
+	const numAttempts = 20
+	var stats platform.TaskStats
+	for i := 0; i < numAttempts; i++ {
+		if i > 0 {
+			time.Sleep(10 * time.Millisecond)
+		}
+		stats = s.TaskStats(taskID)
+		if stats.RunsSucceeded+stats.RunsFailed+stats.RunsCanceled == count {
+			return stats
+		}
+	}
+	t.Fatalf("did not see %d finished runs for task %s in time; last stats were %#v", count, taskID, stats)
+	return stats
+}
+
 type fakeWaitExecutor struct {
 	wait chan struct{}
 }
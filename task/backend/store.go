@@ -33,6 +33,9 @@ var (
 
 	// ErrRunNotFinished is returned when a retry is invalid due to the run not being finished yet.
 	ErrRunNotFinished = errors.New("run is still in progress")
+
+	// ErrRevisionNotFound is returned when searching for a task revision that doesn't exist.
+	ErrRevisionNotFound = errors.New("revision not found")
 )
 
 type TaskStatus string
@@ -64,6 +67,7 @@ const (
 	RunSuccess
 	RunFail
 	RunCanceled
+	RunTimedOut
 )
 
 func (r RunStatus) String() string {
@@ -76,6 +80,8 @@ func (r RunStatus) String() string {
 		return "failed"
 	case RunCanceled:
 		return "canceled"
+	case RunTimedOut:
+		return "timedout"
 	}
 	panic(fmt.Sprintf("unknown RunStatus: %d", r))
 }
@@ -155,6 +161,9 @@ type CreateTaskRequest struct {
 	// The initial task status.
 	// If empty, will be treated as DefaultTaskStatus.
 	Status TaskStatus
+
+	// Free-form labels of the task. May be nil.
+	Labels map[string]string
 }
 
 // UpdateTaskRequest encapsulates requested changes to a task.
@@ -166,9 +175,17 @@ type UpdateTaskRequest struct {
 	// If empty, do not modify the existing script.
 	Script string
 
+	// The user making the change, recorded as the author of the new revision.
+	// If invalid, the task's owner is recorded instead.
+	User platform.ID
+
 	// The new desired task status.
 	// If empty, do not modify the existing status.
 	Status TaskStatus
+
+	// The new labels of the task, replacing the existing labels.
+	// If nil, do not modify the existing labels; if empty, remove all labels.
+	Labels map[string]string
 }
 
 // UpdateTaskResult describes the result of modifying a single task.
@@ -207,6 +224,14 @@ type Store interface {
 	// FindTaskByIDWithMeta combines finding the task and the meta into a single call.
 	FindTaskByIDWithMeta(ctx context.Context, id platform.ID) (*StoreTask, *StoreTaskMeta, error)
 
+	// FindTaskRevisions returns every recorded revision of the task's script, ordered from oldest to newest.
+	// If no task matches the ID, ErrTaskNotFound is returned.
+	FindTaskRevisions(ctx context.Context, id platform.ID) ([]StoreTaskRevision, error)
+
+	// FindTaskRevision returns a single revision of the task's script.
+	// If the revision does not exist, the returned revision is nil and ErrRevisionNotFound is returned.
+	FindTaskRevision(ctx context.Context, id platform.ID, revision int) (*StoreTaskRevision, error)
+
 	// DeleteTask returns whether an entry matching the given ID was deleted.
 	// If err is non-nil, deleted is false.
 	// If err is nil, deleted is false if no entry matched the ID,
@@ -311,6 +336,15 @@ type TaskSearchParams struct {
 	// Return tasks starting after this ID.
 	After platform.ID
 
+	// Return tasks whose name contains this substring. May be empty.
+	Name string
+
+	// Return tasks with this exact status. May be empty.
+	Status TaskStatus
+
+	// Return tasks that have all of these labels. May be nil.
+	Labels map[string]string
+
 	// Size of each page. Must be non-negative.
 	// If zero, the implementation picks an appropriate default page size.
 	// Valid page sizes are implementation-dependent.
@@ -329,6 +363,31 @@ type StoreTask struct {
 
 	// The script content of the task.
 	Script string
+
+	// The revision number of Script.
+	// Zero if the task was stored before revisions were recorded.
+	Revision int
+
+	// Free-form labels of the task. Nil if the task has no labels.
+	Labels map[string]string
+}
+
+// StoreTaskRevision is a stored, immutable version of a task's script.
+type StoreTaskRevision struct {
+	TaskID platform.ID
+
+	// Revision numbers start at 1 and increase by one every time the script changes.
+	Revision int
+
+	// The ID of the user who submitted the script.
+	Author platform.ID
+
+	// Unix timestamp of when the revision was recorded.
+	CreatedAt int64
+
+	// The script content of the revision and the options parsed from it.
+	Script  string
+	Options options.Options
 }
 
 // StoreTaskWithMeta is a single struct with a StoreTask and a StoreTaskMeta.
@@ -375,18 +434,23 @@ func (StoreValidation) CreateArgs(req CreateTaskRequest) (options.Options, error
 		return o, err
 	}
 
+	if err := validateLabels(req.Labels); err != nil {
+		return o, err
+	}
+
 	return o, nil
 }
 
 // UpdateArgs validates the UpdateTaskRequest.
-// If the update only includes a new status (i.e. req.Script is empty), the returned options are zero.
-// If the update contains neither a new script nor a new status, or if the script is invalid, an error is returned.
+// If the update does not include a new script (i.e. req.Script is empty), the returned options are zero.
+// If the update contains neither a new script, nor a new status, nor new labels,
+// or if the script is invalid, an error is returned.
 func (StoreValidation) UpdateArgs(req UpdateTaskRequest) (options.Options, error) {
 	var missing []string
 	var o options.Options
 
-	if req.Script == "" && req.Status == "" {
-		missing = append(missing, "script or status")
+	if req.Script == "" && req.Status == "" && req.Labels == nil {
+		missing = append(missing, "script, status or labels")
 	} else {
 		if req.Script != "" {
 			var err error
@@ -398,6 +462,9 @@ func (StoreValidation) UpdateArgs(req UpdateTaskRequest) (options.Options, error
 		if err := req.Status.validate(true); err != nil {
 			return o, err
 		}
+		if err := validateLabels(req.Labels); err != nil {
+			return o, err
+		}
 	}
 
 	if !req.ID.Valid() {
@@ -410,3 +477,36 @@ func (StoreValidation) UpdateArgs(req UpdateTaskRequest) (options.Options, error
 
 	return o, nil
 }
+
+// validateLabels returns an error if any of the labels has an empty key.
+func validateLabels(labels map[string]string) error {
+	for k := range labels {
+		if k == "" {
+			return errors.New("task label keys must not be empty")
+		}
+	}
+	return nil
+}
+
+// MatchesSearch reports whether the task, with the given status, matches the
+// name, status and label filters of params.
+// The organization, user and paging fields of params are not considered.
+func (params TaskSearchParams) MatchesSearch(t StoreTask, status TaskStatus) bool {
+	if params.Name != "" && !strings.Contains(t.Name, params.Name) {
+		return false
+	}
+	if params.Status != "" && params.Status != status {
+		return false
+	}
+	for k, v := range params.Labels {
+		if lv, ok := t.Labels[k]; !ok || lv != v {
+			return false
+		}
+	}
+	return true
+}
+
+// HasSearchFilters reports whether params filters tasks by name, status or labels.
+func (params TaskSearchParams) HasSearchFilters() bool {
+	return params.Name != "" || params.Status != "" || len(params.Labels) > 0
+}
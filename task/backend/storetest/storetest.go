@@ -29,6 +29,8 @@ func NewStoreTest(name string, cf CreateStoreFunc, df DestroyStoreFunc, funcName
 			"FindTask",
 			"FindMeta",
 			"FindTaskByIDWithMeta",
+			"FindTaskRevisions",
+			"SearchTasks",
 			"DeleteTask",
 			"CreateNextRun",
 			"FinishRun",
@@ -42,6 +44,8 @@ func NewStoreTest(name string, cf CreateStoreFunc, df DestroyStoreFunc, funcName
 		"FindTask":             testStoreFindTask,
 		"FindMeta":             testStoreFindMeta,
 		"FindTaskByIDWithMeta": testStoreFindByIDWithMeta,
+		"FindTaskRevisions":    testStoreFindTaskRevisions,
+		"SearchTasks":          testStoreSearchTasks,
 		"DeleteTask":           testStoreDelete,
 		"CreateNextRun":        testStoreCreateNextRun,
 		"FinishRun":            testStoreFinishRun,
@@ -254,6 +258,240 @@ from(bucket:"y") |> range(start:-1h)`
 	})
 }
 
+func testStoreSearchTasks(t *testing.T, create CreateStoreFunc, destroy DestroyStoreFunc) {
+	const fmtScript = `option task = {
+		name: %q,
+		every: 1m,
+	}
+
+from(bucket:"x") |> range(start:-1h)`
+
+	s := create(t)
+	defer destroy(t, s)
+
+	org := platform.ID(1)
+	user := platform.ID(2)
+	for _, req := range []backend.CreateTaskRequest{
+		{Org: org, User: user, Script: fmt.Sprintf(fmtScript, "cpu rollup"), Labels: map[string]string{"category": "rollup", "team": "a"}},
+		{Org: org, User: user, Script: fmt.Sprintf(fmtScript, "mem rollup"), Labels: map[string]string{"category": "rollup", "team": "b"}},
+		{Org: org, User: user, Script: fmt.Sprintf(fmtScript, "alerting"), Labels: map[string]string{"category": "alert"}, Status: backend.TaskInactive},
+		{Org: org, User: user, Script: fmt.Sprintf(fmtScript, "unlabeled")},
+	} {
+		if _, err := s.CreateTask(context.Background(), req); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	names := func(ts []backend.StoreTaskWithMeta) []string {
+		out := make([]string, len(ts))
+		for i := range ts {
+			out[i] = ts[i].Task.Name
+		}
+		return out
+	}
+
+	for _, c := range []struct {
+		name   string
+		params backend.TaskSearchParams
+		exp    []string
+	}{
+		{name: "no filters", params: backend.TaskSearchParams{Org: org}, exp: []string{"cpu rollup", "mem rollup", "alerting", "unlabeled"}},
+		{name: "one label", params: backend.TaskSearchParams{Org: org, Labels: map[string]string{"category": "rollup"}}, exp: []string{"cpu rollup", "mem rollup"}},
+		{name: "two labels", params: backend.TaskSearchParams{Org: org, Labels: map[string]string{"category": "rollup", "team": "b"}}, exp: []string{"mem rollup"}},
+		{name: "name substring", params: backend.TaskSearchParams{Org: org, Name: "roll"}, exp: []string{"cpu rollup", "mem rollup"}},
+		{name: "status", params: backend.TaskSearchParams{User: user, Status: backend.TaskInactive}, exp: []string{"alerting"}},
+		{name: "no match", params: backend.TaskSearchParams{Labels: map[string]string{"category": "missing"}}, exp: []string{}},
+		{name: "page size applies after filtering", params: backend.TaskSearchParams{Org: org, Name: "u", PageSize: 2}, exp: []string{"cpu rollup", "mem rollup"}},
+	} {
+		ts, err := s.ListTasks(context.Background(), c.params)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if got := names(ts); fmt.Sprint(got) != fmt.Sprint(c.exp) {
+			t.Fatalf("%s: expected tasks %q, got %q", c.name, c.exp, got)
+		}
+	}
+
+	ts, err := s.ListTasks(context.Background(), backend.TaskSearchParams{Org: org, Name: "cpu"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ts) != 1 || ts[0].Task.Labels["team"] != "a" {
+		t.Fatalf("expected listed task to include its labels, got %+v", ts)
+	}
+	id := ts[0].Task.ID
+
+	// Updating only the labels replaces them.
+	res, err := s.UpdateTask(context.Background(), backend.UpdateTaskRequest{ID: id, Labels: map[string]string{"category": "alert"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.NewTask.Labels) != 1 || res.NewTask.Labels["category"] != "alert" {
+		t.Fatalf("unexpected labels after update: %v", res.NewTask.Labels)
+	}
+	task, err := s.FindTaskByID(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(task.Labels) != 1 || task.Labels["category"] != "alert" {
+		t.Fatalf("unexpected labels of found task: %v", task.Labels)
+	}
+
+	// Updating without labels leaves them alone, and an empty map removes them.
+	if _, err := s.UpdateTask(context.Background(), backend.UpdateTaskRequest{ID: id, Status: backend.TaskInactive}); err != nil {
+		t.Fatal(err)
+	}
+	if task, _, err = s.FindTaskByIDWithMeta(context.Background(), id); err != nil {
+		t.Fatal(err)
+	}
+	if task.Labels["category"] != "alert" {
+		t.Fatalf("expected labels to be unchanged, got %v", task.Labels)
+	}
+	if _, err := s.UpdateTask(context.Background(), backend.UpdateTaskRequest{ID: id, Labels: map[string]string{}}); err != nil {
+		t.Fatal(err)
+	}
+	if task, err = s.FindTaskByID(context.Background(), id); err != nil {
+		t.Fatal(err)
+	}
+	if len(task.Labels) != 0 {
+		t.Fatalf("expected labels to be removed, got %v", task.Labels)
+	}
+
+	if _, err := s.CreateTask(context.Background(), backend.CreateTaskRequest{Org: org, User: user, Script: fmt.Sprintf(fmtScript, "x"), Labels: map[string]string{"": "v"}}); err == nil {
+		t.Fatal("expected error for label with empty key")
+	}
+}
+
+func testStoreFindTaskRevisions(t *testing.T, create CreateStoreFunc, destroy DestroyStoreFunc) {
+	const script = `option task = {
+		name: "a task",
+		every: 1m,
+	}
+
+from(bucket:"x") |> range(start:-1h)`
+
+	const script2 = `option task = {
+		name: "a task2",
+		every: 5m,
+		concurrency: 2,
+	}
+
+from(bucket:"y") |> range(start:-1h)`
+
+	s := create(t)
+	defer destroy(t, s)
+
+	id, err := s.CreateTask(context.Background(), backend.CreateTaskRequest{Org: 1, User: 2, Script: script})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	task, err := s.FindTaskByID(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Revision != 1 {
+		t.Fatalf("expected new task to be at revision 1, got %d", task.Revision)
+	}
+
+	// Changing only the status must not record a revision.
+	if _, err := s.UpdateTask(context.Background(), backend.UpdateTaskRequest{ID: id, Status: backend.TaskInactive}); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := s.UpdateTask(context.Background(), backend.UpdateTaskRequest{ID: id, Script: script2, User: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.NewTask.Revision != 2 {
+		t.Fatalf("expected updated task to be at revision 2, got %d", res.NewTask.Revision)
+	}
+
+	// Submitting the same script again must not record a revision.
+	res, err = s.UpdateTask(context.Background(), backend.UpdateTaskRequest{ID: id, Script: script2, User: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.NewTask.Revision != 2 {
+		t.Fatalf("expected task with unchanged script to stay at revision 2, got %d", res.NewTask.Revision)
+	}
+
+	// An update without a user is attributed to the task's owner.
+	if _, err := s.UpdateTask(context.Background(), backend.UpdateTaskRequest{ID: id, Script: script}); err != nil {
+		t.Fatal(err)
+	}
+
+	task, err = s.FindTaskByID(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Revision != 3 {
+		t.Fatalf("expected task to be at revision 3, got %d", task.Revision)
+	}
+
+	revs, err := s.FindTaskRevisions(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revs) != 3 {
+		t.Fatalf("expected 3 revisions, got %d", len(revs))
+	}
+	for i, exp := range []struct {
+		author platform.ID
+		script string
+		name   string
+		every  time.Duration
+	}{
+		{author: 2, script: script, name: "a task", every: time.Minute},
+		{author: 3, script: script2, name: "a task2", every: 5 * time.Minute},
+		{author: 2, script: script, name: "a task", every: time.Minute},
+	} {
+		rev := revs[i]
+		if rev.TaskID != id {
+			t.Fatalf("revision %d: expected task ID %s, got %s", i+1, id, rev.TaskID)
+		}
+		if rev.Revision != i+1 {
+			t.Fatalf("expected revision %d, got %d", i+1, rev.Revision)
+		}
+		if rev.Author != exp.author {
+			t.Fatalf("revision %d: expected author %s, got %s", i+1, exp.author, rev.Author)
+		}
+		if rev.Script != exp.script {
+			t.Fatalf("revision %d: unexpected script %q", i+1, rev.Script)
+		}
+		if rev.Options.Name != exp.name || rev.Options.Every != exp.every {
+			t.Fatalf("revision %d: unexpected options %+v", i+1, rev.Options)
+		}
+		if rev.CreatedAt == 0 {
+			t.Fatalf("revision %d: missing creation time", i+1)
+		}
+	}
+
+	rev, err := s.FindTaskRevision(context.Background(), id, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rev.Script != script2 || rev.Options.Concurrency != 2 {
+		t.Fatalf("unexpected revision 2: %+v", rev)
+	}
+
+	for _, n := range []int{0, 4} {
+		if _, err := s.FindTaskRevision(context.Background(), id, n); err != backend.ErrRevisionNotFound {
+			t.Fatalf("expected ErrRevisionNotFound for revision %d, got %v", n, err)
+		}
+	}
+
+	if _, err := s.DeleteTask(context.Background(), id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.FindTaskRevisions(context.Background(), id); err != backend.ErrTaskNotFound {
+		t.Fatalf("expected ErrTaskNotFound after deleting task, got %v", err)
+	}
+	if _, err := s.FindTaskRevision(context.Background(), id, 1); err != backend.ErrRevisionNotFound {
+		t.Fatalf("expected ErrRevisionNotFound after deleting task, got %v", err)
+	}
+}
+
 func testStoreListTasks(t *testing.T, create CreateStoreFunc, destroy DestroyStoreFunc) {
 	const scriptFmt = `option task = {
 		name: "testStoreListTasks %d",
@@ -0,0 +1,116 @@
+package backend
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/influxdata/platform"
+)
+
+// RunStats describes a single finished run.
+type RunStats struct {
+	Status RunStatus
+
+	ScheduledFor time.Time
+	StartedAt    time.Time
+	FinishedAt   time.Time
+
+	// RowsWritten is the number of rows produced by the run.
+	RowsWritten int64
+}
+
+// Duration returns how long the run took to execute.
+func (s RunStats) Duration() time.Duration {
+	return s.FinishedAt.Sub(s.StartedAt)
+}
+
+// Lag returns how long after its scheduled time the run started.
+func (s RunStats) Lag() time.Duration {
+	return s.StartedAt.Sub(s.ScheduledFor)
+}
+
+// RunStatsWriter records the statistics of finished runs.
+type RunStatsWriter interface {
+	WriteRunStats(ctx context.Context, rlb RunLogBase, stats RunStats) error
+}
+
+// taskStats accumulates the RunStats of a single task.
+type taskStats struct {
+	platform.TaskStats
+
+	totalDuration time.Duration // Sum of the durations of all finished runs.
+}
+
+func (ts *taskStats) add(rs RunStats) {
+	switch rs.Status {
+	case RunSuccess:
+		ts.RunsSucceeded++
+	case RunCanceled:
+		ts.RunsCanceled++
+	default:
+		ts.RunsFailed++
+	}
+	ts.RowsWritten += rs.RowsWritten
+
+	d := rs.Duration()
+	ts.totalDuration += d
+	ts.LastRunDuration = d
+	if d > ts.MaxRunDuration {
+		ts.MaxRunDuration = d
+	}
+	ts.MeanRunDuration = ts.totalDuration / time.Duration(ts.RunsSucceeded+ts.RunsFailed+ts.RunsCanceled)
+
+	lag := rs.Lag()
+	ts.LastLag = lag
+	if lag > ts.MaxLag {
+		ts.MaxLag = lag
+	}
+
+	ts.LastFinishedAt = rs.FinishedAt
+}
+
+// taskStatsTracker keeps the statistics of each task claimed by a scheduler.
+// It is safe for concurrent use.
+type taskStatsTracker struct {
+	mu    sync.Mutex
+	tasks map[platform.ID]*taskStats
+}
+
+func newTaskStatsTracker() *taskStatsTracker {
+	return &taskStatsTracker{tasks: make(map[platform.ID]*taskStats)}
+}
+
+// Add records rs as a finished run of the given task.
+func (t *taskStatsTracker) Add(taskID platform.ID, rs RunStats) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	ts, ok := t.tasks[taskID]
+	if !ok {
+		ts = &taskStats{TaskStats: platform.TaskStats{TaskID: taskID}}
+		t.tasks[taskID] = ts
+	}
+	ts.add(rs)
+}
+
+// Get returns the statistics of the given task.
+// A task without any finished runs has zero statistics.
+func (t *taskStatsTracker) Get(taskID platform.ID) platform.TaskStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	ts, ok := t.tasks[taskID]
+	if !ok {
+		return platform.TaskStats{TaskID: taskID}
+	}
+	return ts.TaskStats
+}
+
+// Delete forgets the statistics of the given task.
+func (t *taskStatsTracker) Delete(taskID platform.ID) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.tasks, taskID)
+}
@@ -161,6 +161,10 @@ func (s *Scheduler) CancelRun(_ context.Context, taskID, runID platform.ID) erro
 	return nil
 }
 
+func (s *Scheduler) TaskStats(taskID platform.ID) platform.TaskStats {
+	return platform.TaskStats{TaskID: taskID}
+}
+
 // DesiredState is a mock implementation of DesiredState (used by NewScheduler).
 type DesiredState struct {
 	mu sync.Mutex
@@ -424,6 +428,7 @@ func (p *RunPromise) Finish(r backend.RunResult, err error) {
 type RunResult struct {
 	err         error
 	isRetryable bool
+	rowsWritten int64
 }
 
 var _ backend.RunResult = (*RunResult)(nil)
@@ -439,3 +444,13 @@ func (rr *RunResult) Err() error {
 func (rr *RunResult) IsRetryable() bool {
 	return rr.isRetryable
 }
+
+// WithRowsWritten sets the number of rows rr reports as written, and returns rr.
+func (rr *RunResult) WithRowsWritten(n int64) *RunResult {
+	rr.rowsWritten = n
+	return rr
+}
+
+func (rr *RunResult) RowsWritten() int64 {
+	return rr.rowsWritten
+}
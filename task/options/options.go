@@ -45,6 +45,17 @@ type Options struct {
 	Concurrency int64
 
 	Retry int64
+
+	// Timeout is the maximum duration of a single run. A zero value means runs never time out.
+	Timeout time.Duration
+
+	// MemoryLimit is the maximum number of bytes a single run's query may allocate.
+	// A zero value means no limit.
+	MemoryLimit int64
+
+	// RowLimit is the maximum number of rows a single run may write with to().
+	// A zero value means no limit.
+	RowLimit int64
 }
 
 // FromScript extracts Options from a Flux script.
@@ -127,6 +138,27 @@ func FromScript(script string) (Options, error) {
 		opt.Retry = retryVal.Int()
 	}
 
+	if timeoutVal, ok := optObject.Get("timeout"); ok {
+		if err := checkNature(timeoutVal.PolyType().Nature(), semantic.Duration); err != nil {
+			return opt, err
+		}
+		opt.Timeout = timeoutVal.Duration().Duration()
+	}
+
+	if memoryLimitVal, ok := optObject.Get("memoryLimit"); ok {
+		if err := checkNature(memoryLimitVal.PolyType().Nature(), semantic.Int); err != nil {
+			return opt, err
+		}
+		opt.MemoryLimit = memoryLimitVal.Int()
+	}
+
+	if rowLimitVal, ok := optObject.Get("rowLimit"); ok {
+		if err := checkNature(rowLimitVal.PolyType().Nature(), semantic.Int); err != nil {
+			return opt, err
+		}
+		opt.RowLimit = rowLimitVal.Int()
+	}
+
 	if err := opt.Validate(); err != nil {
 		return opt, err
 	}
@@ -182,6 +214,20 @@ func (o *Options) Validate() error {
 		errs = append(errs, fmt.Sprintf("retry exceeded max of %d", maxRetry))
 	}
 
+	if o.Timeout < 0 {
+		errs = append(errs, "timeout must not be negative")
+	} else if o.Timeout.Truncate(time.Second) != o.Timeout {
+		errs = append(errs, "timeout option must be expressible as whole seconds")
+	}
+
+	if o.MemoryLimit < 0 {
+		errs = append(errs, "memoryLimit must not be negative")
+	}
+
+	if o.RowLimit < 0 {
+		errs = append(errs, "rowLimit must not be negative")
+	}
+
 	if len(errs) == 0 {
 		return nil
 	}
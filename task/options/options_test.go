@@ -31,6 +31,15 @@ func scriptGenerator(opt options.Options, body string) string {
 	if opt.Retry != 0 {
 		taskData = fmt.Sprintf("%s  retry: %d,\n", taskData, opt.Retry)
 	}
+	if opt.Timeout != 0 {
+		taskData = fmt.Sprintf("%s  timeout: %s,\n", taskData, opt.Timeout.String())
+	}
+	if opt.MemoryLimit != 0 {
+		taskData = fmt.Sprintf("%s  memoryLimit: %d,\n", taskData, opt.MemoryLimit)
+	}
+	if opt.RowLimit != 0 {
+		taskData = fmt.Sprintf("%s  rowLimit: %d,\n", taskData, opt.RowLimit)
+	}
 	if body == "" {
 		body = `from(bucket: "test")
     |> range(start:-1h)`
@@ -52,7 +61,9 @@ func TestFromScript(t *testing.T) {
 		{script: scriptGenerator(options.Options{Name: "name", Cron: "* * * * *", Concurrency: 2, Retry: 3, Delay: -time.Minute}, ""), exp: options.Options{Name: "name", Cron: "* * * * *", Concurrency: 2, Retry: 3, Delay: -time.Minute}},
 		{script: scriptGenerator(options.Options{Name: "name", Every: 5 * time.Second}, ""), exp: options.Options{Name: "name", Every: 5 * time.Second, Concurrency: 1, Retry: 1}},
 		{script: scriptGenerator(options.Options{Name: "name", Cron: "* * * * *"}, ""), exp: options.Options{Name: "name", Cron: "* * * * *", Concurrency: 1, Retry: 1}},
+		{script: scriptGenerator(options.Options{Name: "name", Every: time.Hour, Timeout: 5 * time.Minute, MemoryLimit: 1 << 20, RowLimit: 1000}, ""), exp: options.Options{Name: "name", Every: time.Hour, Concurrency: 1, Retry: 1, Timeout: 5 * time.Minute, MemoryLimit: 1 << 20, RowLimit: 1000}},
 		{script: scriptGenerator(options.Options{Name: "name", Every: time.Hour, Cron: "* * * * *"}, ""), shouldErr: true},
+		{script: scriptGenerator(options.Options{Name: "name", Every: time.Hour, RowLimit: -1}, ""), shouldErr: true},
 		{script: scriptGenerator(options.Options{Name: "name", Concurrency: 1000, Every: time.Hour}, ""), shouldErr: true},
 		{script: "option task = {\n  name: \"name\",\n  concurrency: 0,\n  every: 1m0s,\n\n}\n\nfrom(bucket: \"test\")\n    |> range(start:-1h)", shouldErr: true},
 		{script: "option task = {\n  name: \"name\",\n  concurrency: 1,\n  every: 1,\n\n}\n\nfrom(bucket: \"test\")\n    |> range(start:-1h)", shouldErr: true},
@@ -144,6 +155,24 @@ func TestValidate(t *testing.T) {
 	if err := bad.Validate(); err == nil {
 		t.Error("expected error for retry too large")
 	}
+
+	*bad = good
+	bad.Timeout = -time.Minute
+	if err := bad.Validate(); err == nil {
+		t.Error("expected error for negative timeout")
+	}
+
+	*bad = good
+	bad.Timeout = 1500 * time.Millisecond
+	if err := bad.Validate(); err == nil {
+		t.Error("expected error for sub-second timeout resolution")
+	}
+
+	*bad = good
+	bad.MemoryLimit = -1
+	if err := bad.Validate(); err == nil {
+		t.Error("expected error for negative memoryLimit")
+	}
 }
 
 func TestEffectiveCronString(t *testing.T) {
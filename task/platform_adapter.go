@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/influxdata/platform"
+	pcontext "github.com/influxdata/platform/context"
 	"github.com/influxdata/platform/task/backend"
 	"github.com/influxdata/platform/task/options"
 )
@@ -13,11 +14,14 @@ import (
 type RunController interface {
 	CancelRun(ctx context.Context, taskID, runID platform.ID) error
 	//TODO: add retry run to this.
+
+	// TaskStats returns statistics about the runs of a task.
+	TaskStats(taskID platform.ID) platform.TaskStats
 }
 
 // PlatformAdapter wraps a task.Store into the platform.TaskService interface.
 func PlatformAdapter(s backend.Store, r backend.LogReader, rc RunController) platform.TaskService {
-	return pAdapter{s: s, r: r}
+	return pAdapter{s: s, r: r, rc: rc}
 }
 
 type pAdapter struct {
@@ -55,6 +59,13 @@ func (p pAdapter) FindTasks(ctx context.Context, filter platform.TaskFilter) ([]
 	if filter.After != nil {
 		params.After = *filter.After
 	}
+	if filter.Name != nil {
+		params.Name = *filter.Name
+	}
+	if filter.Status != nil {
+		params.Status = backend.TaskStatus(*filter.Status)
+	}
+	params.Labels = filter.Labels
 	ts, err := p.s.ListTasks(ctx, params)
 	if err != nil {
 		return nil, 0, err
@@ -91,6 +102,7 @@ func (p pAdapter) CreateTask(ctx context.Context, t *platform.Task) error {
 		Script:        t.Flux,
 		ScheduleAfter: scheduleAfter,
 		Status:        backend.TaskStatus(t.Status),
+		Labels:        t.Labels,
 	}
 
 	id, err := p.s.CreateTask(ctx, req)
@@ -100,22 +112,34 @@ func (p pAdapter) CreateTask(ctx context.Context, t *platform.Task) error {
 	t.ID = id
 	t.Every = opts.Every.String()
 	t.Cron = opts.Cron
+	// A newly created task is always at its first revision.
+	t.Revision = 1
 
 	return nil
 }
 
 func (p pAdapter) UpdateTask(ctx context.Context, id platform.ID, upd platform.TaskUpdate) (*platform.Task, error) {
-	if upd.Flux == nil && upd.Status == nil {
+	if upd.Flux == nil && upd.Status == nil && upd.Labels == nil {
 		return nil, errors.New("cannot update task without content")
 	}
 
-	req := backend.UpdateTaskRequest{ID: id}
+	req := backend.UpdateTaskRequest{ID: id, Labels: upd.Labels}
 	if upd.Flux != nil {
 		req.Script = *upd.Flux
 	}
 	if upd.Status != nil {
 		req.Status = backend.TaskStatus(*upd.Status)
 	}
+	return p.updateTask(ctx, req)
+}
+
+// updateTask applies req to the store and returns the resulting task.
+func (p pAdapter) updateTask(ctx context.Context, req backend.UpdateTaskRequest) (*platform.Task, error) {
+	id := req.ID
+	if auth, err := pcontext.GetAuthorizer(ctx); err == nil {
+		req.User = auth.GetUserID()
+	}
+
 	res, err := p.s.UpdateTask(ctx, req)
 	if err != nil {
 		return nil, err
@@ -127,14 +151,16 @@ func (p pAdapter) UpdateTask(ctx context.Context, id platform.ID, upd platform.T
 	}
 
 	task := &platform.Task{
-		ID:     id,
-		Name:   opts.Name,
-		Status: res.NewMeta.Status,
-		Owner:  platform.User{},
-		Flux:   res.NewTask.Script,
-		Every:  opts.Every.String(),
-		Cron:   opts.Cron,
-		Delay:  opts.Delay.String(),
+		ID:       id,
+		Name:     opts.Name,
+		Status:   res.NewMeta.Status,
+		Owner:    platform.User{},
+		Flux:     res.NewTask.Script,
+		Every:    opts.Every.String(),
+		Cron:     opts.Cron,
+		Delay:    opts.Delay.String(),
+		Revision: res.NewTask.Revision,
+		Labels:   res.NewTask.Labels,
 	}
 
 	t, err := p.s.FindTaskByID(ctx, id)
@@ -198,6 +224,36 @@ func (p pAdapter) RetryRun(ctx context.Context, taskID, id platform.ID, requeste
 	return p.s.ManuallyRunTimeRange(ctx, run.TaskID, t, t, requestedAt)
 }
 
+func (p pAdapter) FindTaskRevisions(ctx context.Context, taskID platform.ID) ([]*platform.TaskRevision, int, error) {
+	revs, err := p.s.FindTaskRevisions(ctx, taskID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	prevs := make([]*platform.TaskRevision, len(revs))
+	for i := range revs {
+		prevs[i] = toPlatformTaskRevision(revs[i])
+	}
+	return prevs, len(prevs), nil
+}
+
+func (p pAdapter) FindTaskRevision(ctx context.Context, taskID platform.ID, revision int) (*platform.TaskRevision, error) {
+	rev, err := p.s.FindTaskRevision(ctx, taskID, revision)
+	if err != nil {
+		return nil, err
+	}
+	return toPlatformTaskRevision(*rev), nil
+}
+
+func (p pAdapter) RollbackTask(ctx context.Context, taskID platform.ID, revision int) (*platform.Task, error) {
+	rev, err := p.s.FindTaskRevision(ctx, taskID, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.updateTask(ctx, backend.UpdateTaskRequest{ID: taskID, Script: rev.Script})
+}
+
 func (p pAdapter) CancelRun(ctx context.Context, taskID, runID platform.ID) error {
 	return p.rc.CancelRun(ctx, taskID, runID)
 }
@@ -216,8 +272,10 @@ func toPlatformTask(t backend.StoreTask, m *backend.StoreTaskMeta) (*platform.Ta
 			ID:   t.User,
 			Name: "", // TODO(mr): how to get owner name?
 		},
-		Flux: t.Script,
-		Cron: opts.Cron,
+		Flux:     t.Script,
+		Cron:     opts.Cron,
+		Revision: t.Revision,
+		Labels:   t.Labels,
 	}
 	if opts.Every != 0 {
 		pt.Every = opts.Every.String()
@@ -230,3 +288,37 @@ func toPlatformTask(t backend.StoreTask, m *backend.StoreTaskMeta) (*platform.Ta
 	}
 	return pt, nil
 }
+
+func toPlatformTaskRevision(r backend.StoreTaskRevision) *platform.TaskRevision {
+	pr := &platform.TaskRevision{
+		TaskID:      r.TaskID,
+		Revision:    r.Revision,
+		Author:      r.Author,
+		CreatedAt:   time.Unix(r.CreatedAt, 0).UTC().Format(time.RFC3339),
+		Name:        r.Options.Name,
+		Flux:        r.Script,
+		Cron:        r.Options.Cron,
+		Concurrency: r.Options.Concurrency,
+		Retry:       r.Options.Retry,
+	}
+	if r.Options.Every != 0 {
+		pr.Every = r.Options.Every.String()
+	}
+	if r.Options.Delay != 0 {
+		pr.Delay = r.Options.Delay.String()
+	}
+	return pr
+}
+
+func (p pAdapter) FindTaskStats(ctx context.Context, taskID platform.ID) (*platform.TaskStats, error) {
+	t, err := p.s.FindTaskByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, backend.ErrTaskNotFound
+	}
+
+	stats := p.rc.TaskStats(taskID)
+	return &stats, nil
+}
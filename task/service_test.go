@@ -10,6 +10,7 @@ import (
 	_ "github.com/influxdata/platform/query/builtin"
 	"github.com/influxdata/platform/task/backend"
 	boltstore "github.com/influxdata/platform/task/backend/bolt"
+	"github.com/influxdata/platform/task/mock"
 	"github.com/influxdata/platform/task/servicetest"
 )
 
@@ -23,7 +24,7 @@ func inMemFactory(t *testing.T) (*servicetest.System, context.CancelFunc) {
 		st.Close()
 	}()
 
-	return &servicetest.System{S: st, LR: lrw, LW: lrw, Ctx: ctx}, cancel
+	return &servicetest.System{S: st, LR: lrw, LW: lrw, Sch: mock.NewScheduler(), Ctx: ctx}, cancel
 }
 
 func boltFactory(t *testing.T) (*servicetest.System, context.CancelFunc) {
@@ -53,7 +54,7 @@ func boltFactory(t *testing.T) (*servicetest.System, context.CancelFunc) {
 		}
 	}()
 
-	return &servicetest.System{S: st, LR: lrw, LW: lrw, Ctx: ctx}, cancel
+	return &servicetest.System{S: st, LR: lrw, LW: lrw, Sch: mock.NewScheduler(), Ctx: ctx}, cancel
 }
 
 func TestTaskService(t *testing.T) {
@@ -107,7 +107,12 @@ func testTaskCRUD(t *testing.T, sys *System) {
 	userID := idGen.ID()
 
 	// Create a task.
-	task := &platform.Task{Organization: orgID, Owner: platform.User{ID: userID}, Flux: fmt.Sprintf(scriptFmt, 0)}
+	task := &platform.Task{
+		Organization: orgID,
+		Owner:        platform.User{ID: userID},
+		Flux:         fmt.Sprintf(scriptFmt, 0),
+		Labels:       map[string]string{"category": "crud"},
+	}
 	if err := sys.ts.CreateTask(sys.Ctx, task); err != nil {
 		t.Fatal(err)
 	}
@@ -144,6 +149,24 @@ func testTaskCRUD(t *testing.T, sys *System) {
 	}
 	found["FindTasks with User filter"] = fs[0]
 
+	name := "#0"
+	fs, _, err = sys.ts.FindTasks(sys.Ctx, platform.TaskFilter{Organization: &orgID, Name: &name, Labels: map[string]string{"category": "crud"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fs) != 1 {
+		t.Fatalf("expected 1 task returned, got %d: %#v", len(fs), fs)
+	}
+	found["FindTasks with Name and Labels filter"] = fs[0]
+
+	fs, _, err = sys.ts.FindTasks(sys.Ctx, platform.TaskFilter{Organization: &orgID, Labels: map[string]string{"category": "other"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fs) != 0 {
+		t.Fatalf("expected no tasks returned for unmatched label, got %d: %#v", len(fs), fs)
+	}
+
 	for fn, f := range found {
 		if f.Organization != orgID {
 			t.Fatalf("%s: wrong organization returned; want %s, got %s", fn, orgID.String(), f.Organization.String())
@@ -167,6 +190,9 @@ func testTaskCRUD(t *testing.T, sys *System) {
 		if f.Status != string(backend.DefaultTaskStatus) {
 			t.Fatalf(`%s: wrong default task status; want %q, got %q`, fn, backend.DefaultTaskStatus, f.Status)
 		}
+		if f.Labels["category"] != "crud" {
+			t.Fatalf(`%s: wrong labels returned; want category "crud", got %v`, fn, f.Labels)
+		}
 	}
 
 	// Update task: script only.
@@ -214,6 +240,58 @@ func testTaskCRUD(t *testing.T, sys *System) {
 		t.Fatalf("expected task status to be inactive, got %q", f.Status)
 	}
 
+	// Update task: labels only.
+	f, err = sys.ts.UpdateTask(sys.Ctx, origID, platform.TaskUpdate{Labels: map[string]string{"category": "updated"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Flux != newFlux {
+		t.Fatalf("flux unexpected updated: %s", f.Flux)
+	}
+	if len(f.Labels) != 1 || f.Labels["category"] != "updated" {
+		t.Fatalf("wrong labels from update: %v", f.Labels)
+	}
+
+	stats, err := sys.ts.FindTaskStats(sys.Ctx, origID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.TaskID != origID {
+		t.Fatalf("expected stats for task %s, got %s", origID, stats.TaskID)
+	}
+
+	// Every script change was recorded as a revision; the status-only and labels-only updates were not.
+	revs, _, err := sys.ts.FindTaskRevisions(sys.Ctx, origID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revs) != 3 {
+		t.Fatalf("expected 3 revisions, got %d", len(revs))
+	}
+	if revs[1].Flux != fmt.Sprintf(scriptFmt, 99) {
+		t.Fatalf("wrong flux for revision 2: %q", revs[1].Flux)
+	}
+
+	rev, err := sys.ts.FindTaskRevision(sys.Ctx, origID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rev.Flux != fmt.Sprintf(scriptFmt, 0) || rev.Name != "task #0" {
+		t.Fatalf("wrong revision 1: %#v", rev)
+	}
+
+	// Roll back to the second revision.
+	f, err = sys.ts.RollbackTask(sys.Ctx, origID, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Flux != fmt.Sprintf(scriptFmt, 99) {
+		t.Fatalf("flux not rolled back: %s", f.Flux)
+	}
+	if f.Revision != 4 {
+		t.Fatalf("expected rollback to be recorded as revision 4, got %d", f.Revision)
+	}
+
 	// Delete task.
 	if err := sys.ts.DeleteTask(sys.Ctx, origID); err != nil {
 		t.Fatal(err)
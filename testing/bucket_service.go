@@ -972,9 +972,11 @@ func UpdateBucket(
 	t *testing.T,
 ) {
 	type args struct {
-		name      string
-		id        platform.ID
-		retention int
+		name       string
+		id         platform.ID
+		retention  int
+		schemaType platform.SchemaType
+		schema     []platform.MeasurementSchema
 	}
 	type wants struct {
 		err    error
@@ -1095,6 +1097,43 @@ func UpdateBucket(
 				},
 			},
 		},
+		{
+			name: "update schema",
+			fields: BucketFields{
+				Organizations: []*platform.Organization{
+					{
+						Name: "theorg",
+						ID:   MustIDBase16(orgOneID),
+					},
+				},
+				Buckets: []*platform.Bucket{
+					{
+						ID:             MustIDBase16(bucketOneID),
+						OrganizationID: MustIDBase16(orgOneID),
+						Name:           "bucket1",
+					},
+				},
+			},
+			args: args{
+				id:         MustIDBase16(bucketOneID),
+				schemaType: platform.SchemaTypeExplicit,
+				schema: []platform.MeasurementSchema{
+					{Name: "cpu", Fields: []platform.FieldSchema{{Name: "usage", Type: platform.FieldTypeFloat}}},
+				},
+			},
+			wants: wants{
+				bucket: &platform.Bucket{
+					ID:             MustIDBase16(bucketOneID),
+					OrganizationID: MustIDBase16(orgOneID),
+					Organization:   "theorg",
+					Name:           "bucket1",
+					SchemaType:     platform.SchemaTypeExplicit,
+					Schema: []platform.MeasurementSchema{
+						{Name: "cpu", Fields: []platform.FieldSchema{{Name: "usage", Type: platform.FieldTypeFloat}}},
+					},
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -1111,6 +1150,10 @@ func UpdateBucket(
 				d := time.Duration(tt.args.retention) * time.Minute
 				upd.RetentionPeriod = &d
 			}
+			if tt.args.schemaType != "" {
+				upd.SchemaType = &tt.args.schemaType
+			}
+			upd.Schema = tt.args.schema
 
 			bucket, err := s.UpdateBucket(ctx, tt.args.id, upd)
 			if (err != nil) != (tt.wants.err != nil) {
@@ -0,0 +1,410 @@
+package testing
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/influxdata/platform"
+	kerrors "github.com/influxdata/platform/kit/errors"
+	"github.com/influxdata/platform/mock"
+)
+
+var listenerCmpOptions = cmp.Options{
+	cmp.Transformer("Sort", func(in []*platform.Listener) []*platform.Listener {
+		out := append([]*platform.Listener(nil), in...)
+		sort.Slice(out, func(i, j int) bool {
+			return out[i].ID.String() > out[j].ID.String()
+		})
+		return out
+	}),
+}
+
+// ListenerFields defines fields for a listener test
+type ListenerFields struct {
+	Listeners   []*platform.Listener
+	IDGenerator platform.IDGenerator
+}
+
+// ListenerService tests all the service functions.
+func ListenerService(
+	init func(ListenerFields, *testing.T) (platform.ListenerService, func()), t *testing.T,
+) {
+	listenerService(init, t, listenerCmpOptions)
+}
+
+// RedactedListenerService tests all the service functions of a service that does not
+// return the tokens of listeners, such as the service over HTTP.
+func RedactedListenerService(
+	init func(ListenerFields, *testing.T) (platform.ListenerService, func()), t *testing.T,
+) {
+	opts := append(cmp.Options{cmpopts.IgnoreFields(platform.Listener{}, "Token")}, listenerCmpOptions...)
+	listenerService(init, t, opts)
+}
+
+func listenerService(
+	init func(ListenerFields, *testing.T) (platform.ListenerService, func()), t *testing.T, opts cmp.Options,
+) {
+	tests := []struct {
+		name string
+		fn   func(init func(ListenerFields, *testing.T) (platform.ListenerService, func()),
+			t *testing.T, opts cmp.Options)
+	}{
+		{
+			name: "CreateListener",
+			fn:   CreateListener,
+		},
+		{
+			name: "FindListenerByID",
+			fn:   FindListenerByID,
+		},
+		{
+			name: "UpdateListener",
+			fn:   UpdateListener,
+		},
+		{
+			name: "DeleteListener",
+			fn:   DeleteListener,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(init, t, opts)
+		})
+	}
+}
+
+// newTestListener returns a valid UDP listener with the ID id.
+func newTestListener(id, name string) *platform.Listener {
+	return &platform.Listener{
+		ID:             MustIDBase16(id),
+		Name:           name,
+		Protocol:       platform.ListenerProtocolUDP,
+		BindAddress:    ":8089",
+		OrganizationID: MustIDBase16(orgOneID),
+		BucketID:       MustIDBase16(bucketOneID),
+		Token:          "token",
+	}
+}
+
+// CreateListener tests platform.ListenerService CreateListener interface method
+func CreateListener(init func(ListenerFields, *testing.T) (platform.ListenerService, func()), t *testing.T, opts cmp.Options) {
+	type args struct {
+		listener *platform.Listener
+	}
+	type wants struct {
+		err       error
+		listeners []*platform.Listener
+	}
+
+	tests := []struct {
+		name   string
+		fields ListenerFields
+		args   args
+		wants  wants
+	}{
+		{
+			name: "creating a listener assigns the listener an id and adds it to the store",
+			fields: ListenerFields{
+				IDGenerator: &mock.IDGenerator{
+					IDFn: func() platform.ID {
+						return MustIDBase16(idA)
+					},
+				},
+				Listeners: []*platform.Listener{
+					newTestListener(idB, "existing-listener"),
+				},
+			},
+			args: args{
+				listener: &platform.Listener{
+					Name:           "my-listener",
+					Protocol:       platform.ListenerProtocolUDP,
+					BindAddress:    ":8089",
+					OrganizationID: MustIDBase16(orgOneID),
+					BucketID:       MustIDBase16(bucketOneID),
+					Token:          "token",
+				},
+			},
+			wants: wants{
+				listeners: []*platform.Listener{
+					newTestListener(idB, "existing-listener"),
+					newTestListener(idA, "my-listener"),
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, done := init(tt.fields, t)
+			defer done()
+			ctx := context.TODO()
+
+			err := s.CreateListener(ctx, tt.args.listener)
+			diffErrors(err, tt.wants.err, t)
+
+			listeners, err := s.FindListeners(ctx)
+			if err != nil {
+				t.Fatalf("failed to retrieve listeners: %v", err)
+			}
+			if diff := cmp.Diff(listeners, tt.wants.listeners, opts...); diff != "" {
+				t.Errorf("listeners are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+// FindListenerByID tests platform.ListenerService FindListenerByID interface method
+func FindListenerByID(init func(ListenerFields, *testing.T) (platform.ListenerService, func()), t *testing.T, opts cmp.Options) {
+	type args struct {
+		id platform.ID
+	}
+	type wants struct {
+		err      error
+		listener *platform.Listener
+	}
+
+	tests := []struct {
+		name   string
+		fields ListenerFields
+		args   args
+		wants  wants
+	}{
+		{
+			name: "finding a listener that exists by id",
+			fields: ListenerFields{
+				Listeners: []*platform.Listener{
+					newTestListener(idA, "existing-listener-a"),
+					newTestListener(idB, "existing-listener-b"),
+				},
+			},
+			args: args{
+				id: MustIDBase16(idB),
+			},
+			wants: wants{
+				listener: newTestListener(idB, "existing-listener-b"),
+			},
+		},
+		{
+			name: "finding a listener that does not exist",
+			fields: ListenerFields{
+				Listeners: []*platform.Listener{},
+			},
+			args: args{
+				id: MustIDBase16(idA),
+			},
+			wants: wants{
+				err: fmt.Errorf("listener with ID %s not found", idA),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, done := init(tt.fields, t)
+			defer done()
+			ctx := context.TODO()
+
+			listener, err := s.FindListenerByID(ctx, tt.args.id)
+			diffErrors(err, tt.wants.err, t)
+
+			if diff := cmp.Diff(listener, tt.wants.listener, opts...); diff != "" {
+				t.Errorf("listener is different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+// UpdateListener tests platform.ListenerService UpdateListener interface method
+func UpdateListener(init func(ListenerFields, *testing.T) (platform.ListenerService, func()), t *testing.T, opts cmp.Options) {
+	type args struct {
+		id     platform.ID
+		update platform.ListenerUpdate
+	}
+	type wants struct {
+		err       error
+		listeners []*platform.Listener
+	}
+
+	tcp := platform.ListenerProtocolTCP
+	addr := ":8090"
+	empty := ""
+
+	updated := newTestListener(idB, "existing-listener-b")
+	updated.Protocol = tcp
+	updated.BindAddress = addr
+
+	format := platform.ListenerFormatGraphite
+	templates := &platform.ListenerGraphite{
+		Templates: []string{"servers.* .host.measurement*"},
+		Tags:      map[string]string{"dc": "east"},
+	}
+	graphite := newTestListener(idA, "existing-listener-a")
+	graphite.Format = format
+	graphite.Graphite = templates
+
+	tests := []struct {
+		name   string
+		fields ListenerFields
+		args   args
+		wants  wants
+	}{
+		{
+			name: "updating a listener's protocol and address",
+			fields: ListenerFields{
+				Listeners: []*platform.Listener{
+					newTestListener(idA, "existing-listener-a"),
+					newTestListener(idB, "existing-listener-b"),
+				},
+			},
+			args: args{
+				id:     MustIDBase16(idB),
+				update: platform.ListenerUpdate{Protocol: &tcp, BindAddress: &addr},
+			},
+			wants: wants{
+				listeners: []*platform.Listener{
+					newTestListener(idA, "existing-listener-a"),
+					updated,
+				},
+			},
+		},
+		{
+			name: "updating a listener to graphite with templates",
+			fields: ListenerFields{
+				Listeners: []*platform.Listener{
+					newTestListener(idA, "existing-listener-a"),
+				},
+			},
+			args: args{
+				id:     MustIDBase16(idA),
+				update: platform.ListenerUpdate{Format: &format, Graphite: templates},
+			},
+			wants: wants{
+				listeners: []*platform.Listener{graphite},
+			},
+		},
+		{
+			name: "updating a listener to be invalid fails",
+			fields: ListenerFields{
+				Listeners: []*platform.Listener{
+					newTestListener(idA, "existing-listener-a"),
+				},
+			},
+			args: args{
+				id:     MustIDBase16(idA),
+				update: platform.ListenerUpdate{Token: &empty},
+			},
+			wants: wants{
+				err: fmt.Errorf("token empty"),
+				listeners: []*platform.Listener{
+					newTestListener(idA, "existing-listener-a"),
+				},
+			},
+		},
+		{
+			name: "updating a non-existent listener fails",
+			fields: ListenerFields{
+				Listeners: []*platform.Listener{},
+			},
+			args: args{
+				id:     MustIDBase16(idA),
+				update: platform.ListenerUpdate{BindAddress: &addr},
+			},
+			wants: wants{
+				err:       fmt.Errorf("listener with ID %s not found", idA),
+				listeners: []*platform.Listener{},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, done := init(tt.fields, t)
+			defer done()
+			ctx := context.TODO()
+
+			_, err := s.UpdateListener(ctx, tt.args.id, tt.args.update)
+			diffErrors(err, tt.wants.err, t)
+
+			listeners, err := s.FindListeners(ctx)
+			if err != nil {
+				t.Fatalf("failed to retrieve listeners: %v", err)
+			}
+			if diff := cmp.Diff(listeners, tt.wants.listeners, opts...); diff != "" {
+				t.Errorf("listeners are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+// DeleteListener tests platform.ListenerService DeleteListener interface method
+func DeleteListener(init func(ListenerFields, *testing.T) (platform.ListenerService, func()), t *testing.T, opts cmp.Options) {
+	type args struct {
+		id platform.ID
+	}
+	type wants struct {
+		err       error
+		listeners []*platform.Listener
+	}
+
+	tests := []struct {
+		name   string
+		fields ListenerFields
+		args   args
+		wants  wants
+	}{
+		{
+			name: "deleting a listener",
+			fields: ListenerFields{
+				Listeners: []*platform.Listener{
+					newTestListener(idA, "existing-listener"),
+				},
+			},
+			args: args{
+				id: MustIDBase16(idA),
+			},
+			wants: wants{
+				listeners: []*platform.Listener{},
+			},
+		},
+		{
+			name: "deleting a listener that doesn't exist",
+			fields: ListenerFields{
+				Listeners: []*platform.Listener{
+					newTestListener(idA, "existing-listener"),
+				},
+			},
+			args: args{
+				id: MustIDBase16(idB),
+			},
+			wants: wants{
+				err: kerrors.Errorf(kerrors.NotFound, "listener with ID %s not found", idB),
+				listeners: []*platform.Listener{
+					newTestListener(idA, "existing-listener"),
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, done := init(tt.fields, t)
+			defer done()
+			ctx := context.TODO()
+
+			err := s.DeleteListener(ctx, tt.args.id)
+			diffErrors(err, tt.wants.err, t)
+
+			listeners, err := s.FindListeners(ctx)
+			if err != nil {
+				t.Fatalf("failed to retrieve listeners: %v", err)
+			}
+			if diff := cmp.Diff(listeners, tt.wants.listeners, opts...); diff != "" {
+				t.Errorf("listeners are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
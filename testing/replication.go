@@ -0,0 +1,497 @@
+package testing
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/influxdata/platform"
+	kerrors "github.com/influxdata/platform/kit/errors"
+	"github.com/influxdata/platform/mock"
+)
+
+var replicationCmpOptions = cmp.Options{
+	cmp.Transformer("Sort", func(in []*platform.Replication) []*platform.Replication {
+		out := append([]*platform.Replication(nil), in...)
+		sort.Slice(out, func(i, j int) bool {
+			return out[i].ID.String() > out[j].ID.String()
+		})
+		return out
+	}),
+}
+
+// ReplicationFields defines fields for a replication test
+type ReplicationFields struct {
+	Replications []*platform.Replication
+	IDGenerator  platform.IDGenerator
+}
+
+// ReplicationService tests all the service functions.
+func ReplicationService(
+	init func(ReplicationFields, *testing.T) (platform.ReplicationService, func()), t *testing.T,
+) {
+	replicationService(init, t, replicationCmpOptions)
+}
+
+// RedactedReplicationService tests all the service functions of a service that does not
+// return the remote tokens of replications, such as the service over HTTP.
+func RedactedReplicationService(
+	init func(ReplicationFields, *testing.T) (platform.ReplicationService, func()), t *testing.T,
+) {
+	opts := append(cmp.Options{cmpopts.IgnoreFields(platform.Replication{}, "RemoteToken")}, replicationCmpOptions...)
+	replicationService(init, t, opts)
+}
+
+func replicationService(
+	init func(ReplicationFields, *testing.T) (platform.ReplicationService, func()), t *testing.T, opts cmp.Options,
+) {
+	tests := []struct {
+		name string
+		fn   func(init func(ReplicationFields, *testing.T) (platform.ReplicationService, func()),
+			t *testing.T, opts cmp.Options)
+	}{
+		{
+			name: "CreateReplication",
+			fn:   CreateReplication,
+		},
+		{
+			name: "FindReplicationByID",
+			fn:   FindReplicationByID,
+		},
+		{
+			name: "UpdateReplication",
+			fn:   UpdateReplication,
+		},
+		{
+			name: "DeleteReplication",
+			fn:   DeleteReplication,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(init, t, opts)
+		})
+	}
+}
+
+// newTestReplication returns a valid replication with the ID id.
+func newTestReplication(id, name string) *platform.Replication {
+	return &platform.Replication{
+		ID:                   MustIDBase16(id),
+		Name:                 name,
+		OrganizationID:       MustIDBase16(orgOneID),
+		LocalBucketID:        MustIDBase16(bucketOneID),
+		RemoteURL:            "https://remote:9999",
+		RemoteToken:          "token",
+		RemoteOrganizationID: MustIDBase16(orgTwoID),
+		RemoteBucketID:       MustIDBase16(bucketTwoID),
+	}
+}
+
+// CreateReplication tests platform.ReplicationService CreateReplication interface method
+func CreateReplication(init func(ReplicationFields, *testing.T) (platform.ReplicationService, func()), t *testing.T, opts cmp.Options) {
+	type args struct {
+		replication *platform.Replication
+	}
+	type wants struct {
+		err          error
+		replications []*platform.Replication
+	}
+
+	tests := []struct {
+		name   string
+		fields ReplicationFields
+		args   args
+		wants  wants
+	}{
+		{
+			name: "creating a replication assigns the replication an id and adds it to the store",
+			fields: ReplicationFields{
+				IDGenerator: &mock.IDGenerator{
+					IDFn: func() platform.ID {
+						return MustIDBase16(idA)
+					},
+				},
+				Replications: []*platform.Replication{
+					newTestReplication(idB, "existing-replication"),
+				},
+			},
+			args: args{
+				replication: &platform.Replication{
+					Name:                 "my-replication",
+					OrganizationID:       MustIDBase16(orgOneID),
+					LocalBucketID:        MustIDBase16(bucketOneID),
+					RemoteURL:            "https://remote:9999",
+					RemoteToken:          "token",
+					RemoteOrganizationID: MustIDBase16(orgTwoID),
+					RemoteBucketID:       MustIDBase16(bucketTwoID),
+				},
+			},
+			wants: wants{
+				replications: []*platform.Replication{
+					newTestReplication(idB, "existing-replication"),
+					newTestReplication(idA, "my-replication"),
+				},
+			},
+		},
+		{
+			name: "creating a replication to a remote url without a scheme fails",
+			fields: ReplicationFields{
+				IDGenerator: mock.NewIDGenerator(idA, t),
+				Replications: []*platform.Replication{
+					newTestReplication(idB, "existing-replication"),
+				},
+			},
+			args: args{
+				replication: &platform.Replication{
+					Name:                 "my-replication",
+					OrganizationID:       MustIDBase16(orgOneID),
+					LocalBucketID:        MustIDBase16(bucketOneID),
+					RemoteURL:            "remote:9999",
+					RemoteToken:          "token",
+					RemoteOrganizationID: MustIDBase16(orgTwoID),
+					RemoteBucketID:       MustIDBase16(bucketTwoID),
+				},
+			},
+			wants: wants{
+				err: fmt.Errorf(`invalid remote url "remote:9999"`),
+				replications: []*platform.Replication{
+					newTestReplication(idB, "existing-replication"),
+				},
+			},
+		},
+		{
+			name: "creating a replication without a remote token fails",
+			fields: ReplicationFields{
+				IDGenerator: mock.NewIDGenerator(idA, t),
+				Replications: []*platform.Replication{
+					newTestReplication(idB, "existing-replication"),
+				},
+			},
+			args: args{
+				replication: &platform.Replication{
+					Name:                 "my-replication",
+					OrganizationID:       MustIDBase16(orgOneID),
+					LocalBucketID:        MustIDBase16(bucketOneID),
+					RemoteURL:            "https://remote:9999",
+					RemoteOrganizationID: MustIDBase16(orgTwoID),
+					RemoteBucketID:       MustIDBase16(bucketTwoID),
+				},
+			},
+			wants: wants{
+				err: fmt.Errorf("remote token empty"),
+				replications: []*platform.Replication{
+					newTestReplication(idB, "existing-replication"),
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, done := init(tt.fields, t)
+			defer done()
+			ctx := context.TODO()
+
+			err := s.CreateReplication(ctx, tt.args.replication)
+			diffErrors(err, tt.wants.err, t)
+
+			replications, err := s.FindReplications(ctx)
+			if err != nil {
+				t.Fatalf("failed to retrieve replications: %v", err)
+			}
+			if diff := cmp.Diff(replications, tt.wants.replications, opts...); diff != "" {
+				t.Errorf("replications are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+// FindReplicationByID tests platform.ReplicationService FindReplicationByID interface method
+func FindReplicationByID(init func(ReplicationFields, *testing.T) (platform.ReplicationService, func()), t *testing.T, opts cmp.Options) {
+	type args struct {
+		id platform.ID
+	}
+	type wants struct {
+		err         error
+		replication *platform.Replication
+	}
+
+	tests := []struct {
+		name   string
+		fields ReplicationFields
+		args   args
+		wants  wants
+	}{
+		{
+			name: "finding a replication that exists by id",
+			fields: ReplicationFields{
+				Replications: []*platform.Replication{
+					newTestReplication(idA, "existing-replication-a"),
+					newTestReplication(idB, "existing-replication-b"),
+				},
+			},
+			args: args{
+				id: MustIDBase16(idB),
+			},
+			wants: wants{
+				replication: newTestReplication(idB, "existing-replication-b"),
+			},
+		},
+		{
+			name: "finding a replication that does not exist",
+			fields: ReplicationFields{
+				Replications: []*platform.Replication{},
+			},
+			args: args{
+				id: MustIDBase16(idA),
+			},
+			wants: wants{
+				err: fmt.Errorf("replication with ID %s not found", idA),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, done := init(tt.fields, t)
+			defer done()
+			ctx := context.TODO()
+
+			replication, err := s.FindReplicationByID(ctx, tt.args.id)
+			diffErrors(err, tt.wants.err, t)
+
+			if diff := cmp.Diff(replication, tt.wants.replication, opts...); diff != "" {
+				t.Errorf("replication is different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+// UpdateReplication tests platform.ReplicationService UpdateReplication interface method
+func UpdateReplication(init func(ReplicationFields, *testing.T) (platform.ReplicationService, func()), t *testing.T, opts cmp.Options) {
+	type args struct {
+		id     platform.ID
+		update platform.ReplicationUpdate
+	}
+	type wants struct {
+		err          error
+		replications []*platform.Replication
+	}
+
+	remote := "https://other:9999"
+	token := "other-token"
+	empty := ""
+	ftp := "ftp://other:9999"
+	name := "renamed"
+
+	renamed := newTestReplication(idA, name)
+
+	updated := newTestReplication(idB, "existing-replication-b")
+	updated.RemoteURL = remote
+	updated.RemoteToken = token
+
+	size := int64(1024)
+	newest := platform.ReplicationDropNewest
+	queue := newTestReplication(idA, "existing-replication-a")
+	queue.MaxQueueSizeBytes = size
+	queue.DropPolicy = newest
+
+	tests := []struct {
+		name   string
+		fields ReplicationFields
+		args   args
+		wants  wants
+	}{
+		{
+			name: "updating a replication's remote url and token",
+			fields: ReplicationFields{
+				Replications: []*platform.Replication{
+					newTestReplication(idA, "existing-replication-a"),
+					newTestReplication(idB, "existing-replication-b"),
+				},
+			},
+			args: args{
+				id:     MustIDBase16(idB),
+				update: platform.ReplicationUpdate{RemoteURL: &remote, RemoteToken: &token},
+			},
+			wants: wants{
+				replications: []*platform.Replication{
+					newTestReplication(idA, "existing-replication-a"),
+					updated,
+				},
+			},
+		},
+		{
+			name: "updating a replication's queue size and drop policy",
+			fields: ReplicationFields{
+				Replications: []*platform.Replication{
+					newTestReplication(idA, "existing-replication-a"),
+				},
+			},
+			args: args{
+				id:     MustIDBase16(idA),
+				update: platform.ReplicationUpdate{MaxQueueSizeBytes: &size, DropPolicy: &newest},
+			},
+			wants: wants{
+				replications: []*platform.Replication{queue},
+			},
+		},
+		{
+			name: "updating a replication's name keeps its remote token",
+			fields: ReplicationFields{
+				Replications: []*platform.Replication{
+					newTestReplication(idA, "existing-replication-a"),
+				},
+			},
+			args: args{
+				id:     MustIDBase16(idA),
+				update: platform.ReplicationUpdate{Name: &name},
+			},
+			wants: wants{
+				replications: []*platform.Replication{renamed},
+			},
+		},
+		{
+			name: "updating a replication to a remote url that is not http fails",
+			fields: ReplicationFields{
+				Replications: []*platform.Replication{
+					newTestReplication(idA, "existing-replication-a"),
+				},
+			},
+			args: args{
+				id:     MustIDBase16(idA),
+				update: platform.ReplicationUpdate{RemoteURL: &ftp},
+			},
+			wants: wants{
+				err: fmt.Errorf(`invalid remote url "ftp://other:9999"`),
+				replications: []*platform.Replication{
+					newTestReplication(idA, "existing-replication-a"),
+				},
+			},
+		},
+		{
+			name: "updating a replication to be invalid fails",
+			fields: ReplicationFields{
+				Replications: []*platform.Replication{
+					newTestReplication(idA, "existing-replication-a"),
+				},
+			},
+			args: args{
+				id:     MustIDBase16(idA),
+				update: platform.ReplicationUpdate{RemoteToken: &empty},
+			},
+			wants: wants{
+				err: fmt.Errorf("remote token empty"),
+				replications: []*platform.Replication{
+					newTestReplication(idA, "existing-replication-a"),
+				},
+			},
+		},
+		{
+			name: "updating a non-existent replication fails",
+			fields: ReplicationFields{
+				Replications: []*platform.Replication{},
+			},
+			args: args{
+				id:     MustIDBase16(idA),
+				update: platform.ReplicationUpdate{RemoteURL: &remote},
+			},
+			wants: wants{
+				err:          fmt.Errorf("replication with ID %s not found", idA),
+				replications: []*platform.Replication{},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, done := init(tt.fields, t)
+			defer done()
+			ctx := context.TODO()
+
+			_, err := s.UpdateReplication(ctx, tt.args.id, tt.args.update)
+			diffErrors(err, tt.wants.err, t)
+
+			replications, err := s.FindReplications(ctx)
+			if err != nil {
+				t.Fatalf("failed to retrieve replications: %v", err)
+			}
+			if diff := cmp.Diff(replications, tt.wants.replications, opts...); diff != "" {
+				t.Errorf("replications are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+// DeleteReplication tests platform.ReplicationService DeleteReplication interface method
+func DeleteReplication(init func(ReplicationFields, *testing.T) (platform.ReplicationService, func()), t *testing.T, opts cmp.Options) {
+	type args struct {
+		id platform.ID
+	}
+	type wants struct {
+		err          error
+		replications []*platform.Replication
+	}
+
+	tests := []struct {
+		name   string
+		fields ReplicationFields
+		args   args
+		wants  wants
+	}{
+		{
+			name: "deleting a replication",
+			fields: ReplicationFields{
+				Replications: []*platform.Replication{
+					newTestReplication(idA, "existing-replication"),
+				},
+			},
+			args: args{
+				id: MustIDBase16(idA),
+			},
+			wants: wants{
+				replications: []*platform.Replication{},
+			},
+		},
+		{
+			name: "deleting a replication that doesn't exist",
+			fields: ReplicationFields{
+				Replications: []*platform.Replication{
+					newTestReplication(idA, "existing-replication"),
+				},
+			},
+			args: args{
+				id: MustIDBase16(idB),
+			},
+			wants: wants{
+				err: kerrors.Errorf(kerrors.NotFound, "replication with ID %s not found", idB),
+				replications: []*platform.Replication{
+					newTestReplication(idA, "existing-replication"),
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, done := init(tt.fields, t)
+			defer done()
+			ctx := context.TODO()
+
+			err := s.DeleteReplication(ctx, tt.args.id)
+			diffErrors(err, tt.wants.err, t)
+
+			replications, err := s.FindReplications(ctx)
+			if err != nil {
+				t.Fatalf("failed to retrieve replications: %v", err)
+			}
+			if diff := cmp.Diff(replications, tt.wants.replications, opts...); diff != "" {
+				t.Errorf("replications are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
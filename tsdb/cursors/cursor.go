@@ -38,6 +38,13 @@ type BooleanArrayCursor interface {
 	Next() *BooleanArray
 }
 
+// A Seeker is a cursor that can move past values without reading them.
+type Seeker interface {
+	// SeekTo moves the cursor to the first value at or after t, if that is
+	// after its current position.
+	SeekTo(t int64)
+}
+
 type CursorRequest struct {
 	Name      []byte
 	Tags      models.Tags
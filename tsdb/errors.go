@@ -45,8 +45,58 @@ type PartialWriteError struct {
 
 	// A sorted slice of series keys that were dropped.
 	DroppedKeys [][]byte
+
+	// Conflicts are the field type conflicts of the points that were dropped, by the
+	// index of the point in the write. Their series keys are not in DroppedKeys, as
+	// the other points of their series may have been written.
+	Conflicts map[int]FieldTypeConflictError
+
+	// SchemaMismatches are why the points that were dropped do not match the explicit
+	// schema of their bucket, by the index of the point in the write. Like those of
+	// Conflicts, their series keys are not in DroppedKeys.
+	SchemaMismatches map[int]SchemaMismatchError
 }
 
 func (e PartialWriteError) Error() string {
 	return fmt.Sprintf("partial write: %s dropped=%d", e.Reason, e.Dropped)
 }
+
+// FieldTypeConflictError is the field type conflict of a point whose field has a
+// different type than the field of its measurement.
+type FieldTypeConflictError struct {
+	Measurement string
+	Field       string
+	// Type is the type of the field of the point, and ExistingType the type of the field
+	// of the measurement.
+	Type         string
+	ExistingType string
+}
+
+func (e FieldTypeConflictError) Error() string {
+	return fmt.Sprintf("%s: input field %q on measurement %q is type %s, already exists as type %s",
+		ErrFieldTypeConflict, e.Field, e.Measurement, e.Type, e.ExistingType)
+}
+
+// SchemaMismatchError is why a point does not match the explicit schema of its bucket:
+// its measurement is not in the schema when Field is empty, its field is not in the
+// schema of its measurement when SchemaType is empty, and its field has another type
+// than the schema declares otherwise.
+type SchemaMismatchError struct {
+	Measurement string
+	Field       string
+	// Type is the type of the field of the point, and SchemaType the type the schema
+	// declares for the field.
+	Type       string
+	SchemaType string
+}
+
+func (e SchemaMismatchError) Error() string {
+	switch {
+	case e.Field == "":
+		return fmt.Sprintf("measurement %q is not in the schema of the bucket", e.Measurement)
+	case e.SchemaType == "":
+		return fmt.Sprintf("field %q is not in the schema of measurement %q", e.Field, e.Measurement)
+	default:
+		return fmt.Sprintf("input field %q on measurement %q is type %s, schema declares type %s", e.Field, e.Measurement, e.Type, e.SchemaType)
+	}
+}
@@ -37,6 +37,10 @@ type SeriesFile struct {
 
 	refs sync.RWMutex // RWMutex to track references to the SeriesFile that are in use.
 
+	// ReadOnly opens the series file without creating or writing to any of its files, for
+	// tools that only inspect it. It must be set before Open.
+	ReadOnly bool
+
 	Logger *zap.Logger
 }
 
@@ -60,7 +64,11 @@ func (f *SeriesFile) Open() error {
 	defer f.refs.Unlock()
 
 	// Create path if it doesn't exist.
-	if err := os.MkdirAll(filepath.Join(f.path), 0777); err != nil {
+	if f.ReadOnly {
+		if _, err := os.Stat(f.path); err != nil {
+			return err
+		}
+	} else if err := os.MkdirAll(filepath.Join(f.path), 0777); err != nil {
 		return err
 	}
 
@@ -70,6 +78,7 @@ func (f *SeriesFile) Open() error {
 		// TODO(edd): These partition initialisation should be moved up to NewSeriesFile.
 		p := NewSeriesPartition(i, f.SeriesPartitionPath(i))
 		p.Logger = f.Logger.With(zap.Int("partition", p.ID()))
+		p.ReadOnly = f.ReadOnly
 		if err := p.Open(); err != nil {
 			f.Close()
 			return err
@@ -217,6 +217,50 @@ func TestSeriesFile_DeleteSeriesID(t *testing.T) {
 	}
 }
 
+func TestSeriesFile_ReadOnly(t *testing.T) {
+	sfile := MustOpenSeriesFile()
+	defer sfile.Close()
+
+	if err := sfile.CreateSeriesListIfNotExists(&tsdb.SeriesCollection{
+		Names: [][]byte{[]byte("m1")},
+		Tags:  []models.Tags{{}},
+		Types: []models.FieldType{models.String},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	id := sfile.SeriesID([]byte("m1"), nil, nil)
+	if err := sfile.SeriesFile.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	sfile.SeriesFile = tsdb.NewSeriesFile(sfile.Path())
+	sfile.ReadOnly = true
+	if err := sfile.Open(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := sfile.SeriesID([]byte("m1"), nil, nil); got != id {
+		t.Fatalf("unexpected series id: got %d, exp %d", got.RawID(), id.RawID())
+	}
+	if err := sfile.CreateSeriesListIfNotExists(&tsdb.SeriesCollection{
+		Names: [][]byte{[]byte("m2")},
+		Tags:  []models.Tags{{}},
+		Types: []models.FieldType{models.String},
+	}); err != tsdb.ErrSeriesPartitionReadOnly {
+		t.Fatalf("unexpected error creating a series: %v", err)
+	}
+	if err := sfile.DeleteSeriesID(id); err != tsdb.ErrSeriesPartitionReadOnly {
+		t.Fatalf("unexpected error deleting a series: %v", err)
+	}
+
+	// A missing series file is not created.
+	missing := tsdb.NewSeriesFile(sfile.Path() + "-missing")
+	missing.ReadOnly = true
+	if err := missing.Open(); !os.IsNotExist(err) {
+		t.Fatalf("unexpected error opening a missing series file: %v", err)
+	}
+}
+
 // Series represents name/tagset pairs that are used in testing.
 type Series struct {
 	Name    []byte
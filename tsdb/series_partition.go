@@ -18,6 +18,7 @@ import (
 var (
 	ErrSeriesPartitionClosed              = errors.New("tsdb: series partition closed")
 	ErrSeriesPartitionCompactionCancelled = errors.New("tsdb: series partition compaction cancelled")
+	ErrSeriesPartitionReadOnly            = errors.New("tsdb: series partition is read-only")
 )
 
 // DefaultSeriesPartitionCompactThreshold is the number of series IDs to hold in the in-memory
@@ -44,6 +45,9 @@ type SeriesPartition struct {
 
 	CompactThreshold int
 
+	// ReadOnly opens the partition without creating or writing to any of its files.
+	ReadOnly bool
+
 	Logger *zap.Logger
 }
 
@@ -66,8 +70,10 @@ func (p *SeriesPartition) Open() error {
 	}
 
 	// Create path if it doesn't exist.
-	if err := os.MkdirAll(filepath.Join(p.path), 0777); err != nil {
-		return err
+	if !p.ReadOnly {
+		if err := os.MkdirAll(filepath.Join(p.path), 0777); err != nil {
+			return err
+		}
 	}
 
 	// Open components.
@@ -77,8 +83,10 @@ func (p *SeriesPartition) Open() error {
 		}
 
 		// Init last segment for writes.
-		if err := p.activeSegment().InitForWrite(); err != nil {
-			return err
+		if !p.ReadOnly {
+			if err := p.activeSegment().InitForWrite(); err != nil {
+				return err
+			}
 		}
 
 		p.index = NewSeriesIndex(p.IndexPath())
@@ -126,7 +134,7 @@ func (p *SeriesPartition) openSegments() error {
 	}
 
 	// Create initial segment if none exist.
-	if len(p.segments) == 0 {
+	if len(p.segments) == 0 && !p.ReadOnly {
 		segment, err := CreateSeriesSegment(0, filepath.Join(p.path, "0000"))
 		if err != nil {
 			return err
@@ -210,6 +218,9 @@ func (p *SeriesPartition) CreateSeriesListIfNotExists(collection *SeriesCollecti
 	if writeRequired == 0 {
 		return nil
 	}
+	if p.ReadOnly {
+		return ErrSeriesPartitionReadOnly
+	}
 
 	type keyRange struct {
 		id     SeriesIDTyped
@@ -326,6 +337,8 @@ func (p *SeriesPartition) DeleteSeriesID(id SeriesID) error {
 
 	if p.closed {
 		return ErrSeriesPartitionClosed
+	} else if p.ReadOnly {
+		return ErrSeriesPartitionReadOnly
 	}
 
 	// Already tombstoned, ignore.
@@ -101,7 +101,7 @@ func (c *floatArrayAscendingCursor) Next() *tsdb.FloatArray {
 
 	if pos < len(c.res.Timestamps) {
 		if c.tsm.pos < len(tvals.Timestamps) {
-			if pos == 0 {
+			if pos == 0 && c.tsm.pos == 0 {
 				// optimization: all points served from TSM data
 				copy(c.res.Timestamps, tvals.Timestamps)
 				pos += copy(c.res.Values, tvals.Values)
@@ -150,6 +150,25 @@ func (c *floatArrayAscendingCursor) nextTSM() *tsdb.FloatArray {
 	return c.tsm.values
 }
 
+// SeekTo moves the cursor to the first value at or after t, if that is after its
+// current position. The TSM blocks that end before t are not decoded.
+func (c *floatArrayAscendingCursor) SeekTo(t int64) {
+	cvals := c.cache.values[c.cache.pos:]
+	c.cache.pos += sort.Search(len(cvals), func(i int) bool {
+		return cvals[i].UnixNano() >= t
+	})
+
+	tvals := c.tsm.values
+	if n := len(tvals.Timestamps); n > c.tsm.pos && tvals.Timestamps[n-1] < t {
+		c.tsm.keyCursor.markReadBefore(t)
+		tvals = c.nextTSM()
+	}
+	ts := tvals.Timestamps[c.tsm.pos:]
+	c.tsm.pos += sort.Search(len(ts), func(i int) bool {
+		return ts[i] >= t
+	})
+}
+
 type floatArrayDescendingCursor struct {
 	cache struct {
 		values Values
@@ -384,7 +403,7 @@ func (c *integerArrayAscendingCursor) Next() *tsdb.IntegerArray {
 
 	if pos < len(c.res.Timestamps) {
 		if c.tsm.pos < len(tvals.Timestamps) {
-			if pos == 0 {
+			if pos == 0 && c.tsm.pos == 0 {
 				// optimization: all points served from TSM data
 				copy(c.res.Timestamps, tvals.Timestamps)
 				pos += copy(c.res.Values, tvals.Values)
@@ -433,6 +452,25 @@ func (c *integerArrayAscendingCursor) nextTSM() *tsdb.IntegerArray {
 	return c.tsm.values
 }
 
+// SeekTo moves the cursor to the first value at or after t, if that is after its
+// current position. The TSM blocks that end before t are not decoded.
+func (c *integerArrayAscendingCursor) SeekTo(t int64) {
+	cvals := c.cache.values[c.cache.pos:]
+	c.cache.pos += sort.Search(len(cvals), func(i int) bool {
+		return cvals[i].UnixNano() >= t
+	})
+
+	tvals := c.tsm.values
+	if n := len(tvals.Timestamps); n > c.tsm.pos && tvals.Timestamps[n-1] < t {
+		c.tsm.keyCursor.markReadBefore(t)
+		tvals = c.nextTSM()
+	}
+	ts := tvals.Timestamps[c.tsm.pos:]
+	c.tsm.pos += sort.Search(len(ts), func(i int) bool {
+		return ts[i] >= t
+	})
+}
+
 type integerArrayDescendingCursor struct {
 	cache struct {
 		values Values
@@ -667,7 +705,7 @@ func (c *unsignedArrayAscendingCursor) Next() *tsdb.UnsignedArray {
 
 	if pos < len(c.res.Timestamps) {
 		if c.tsm.pos < len(tvals.Timestamps) {
-			if pos == 0 {
+			if pos == 0 && c.tsm.pos == 0 {
 				// optimization: all points served from TSM data
 				copy(c.res.Timestamps, tvals.Timestamps)
 				pos += copy(c.res.Values, tvals.Values)
@@ -716,6 +754,25 @@ func (c *unsignedArrayAscendingCursor) nextTSM() *tsdb.UnsignedArray {
 	return c.tsm.values
 }
 
+// SeekTo moves the cursor to the first value at or after t, if that is after its
+// current position. The TSM blocks that end before t are not decoded.
+func (c *unsignedArrayAscendingCursor) SeekTo(t int64) {
+	cvals := c.cache.values[c.cache.pos:]
+	c.cache.pos += sort.Search(len(cvals), func(i int) bool {
+		return cvals[i].UnixNano() >= t
+	})
+
+	tvals := c.tsm.values
+	if n := len(tvals.Timestamps); n > c.tsm.pos && tvals.Timestamps[n-1] < t {
+		c.tsm.keyCursor.markReadBefore(t)
+		tvals = c.nextTSM()
+	}
+	ts := tvals.Timestamps[c.tsm.pos:]
+	c.tsm.pos += sort.Search(len(ts), func(i int) bool {
+		return ts[i] >= t
+	})
+}
+
 type unsignedArrayDescendingCursor struct {
 	cache struct {
 		values Values
@@ -950,7 +1007,7 @@ func (c *stringArrayAscendingCursor) Next() *tsdb.StringArray {
 
 	if pos < len(c.res.Timestamps) {
 		if c.tsm.pos < len(tvals.Timestamps) {
-			if pos == 0 {
+			if pos == 0 && c.tsm.pos == 0 {
 				// optimization: all points served from TSM data
 				copy(c.res.Timestamps, tvals.Timestamps)
 				pos += copy(c.res.Values, tvals.Values)
@@ -999,6 +1056,25 @@ func (c *stringArrayAscendingCursor) nextTSM() *tsdb.StringArray {
 	return c.tsm.values
 }
 
+// SeekTo moves the cursor to the first value at or after t, if that is after its
+// current position. The TSM blocks that end before t are not decoded.
+func (c *stringArrayAscendingCursor) SeekTo(t int64) {
+	cvals := c.cache.values[c.cache.pos:]
+	c.cache.pos += sort.Search(len(cvals), func(i int) bool {
+		return cvals[i].UnixNano() >= t
+	})
+
+	tvals := c.tsm.values
+	if n := len(tvals.Timestamps); n > c.tsm.pos && tvals.Timestamps[n-1] < t {
+		c.tsm.keyCursor.markReadBefore(t)
+		tvals = c.nextTSM()
+	}
+	ts := tvals.Timestamps[c.tsm.pos:]
+	c.tsm.pos += sort.Search(len(ts), func(i int) bool {
+		return ts[i] >= t
+	})
+}
+
 type stringArrayDescendingCursor struct {
 	cache struct {
 		values Values
@@ -1233,7 +1309,7 @@ func (c *booleanArrayAscendingCursor) Next() *tsdb.BooleanArray {
 
 	if pos < len(c.res.Timestamps) {
 		if c.tsm.pos < len(tvals.Timestamps) {
-			if pos == 0 {
+			if pos == 0 && c.tsm.pos == 0 {
 				// optimization: all points served from TSM data
 				copy(c.res.Timestamps, tvals.Timestamps)
 				pos += copy(c.res.Values, tvals.Values)
@@ -1282,6 +1358,25 @@ func (c *booleanArrayAscendingCursor) nextTSM() *tsdb.BooleanArray {
 	return c.tsm.values
 }
 
+// SeekTo moves the cursor to the first value at or after t, if that is after its
+// current position. The TSM blocks that end before t are not decoded.
+func (c *booleanArrayAscendingCursor) SeekTo(t int64) {
+	cvals := c.cache.values[c.cache.pos:]
+	c.cache.pos += sort.Search(len(cvals), func(i int) bool {
+		return cvals[i].UnixNano() >= t
+	})
+
+	tvals := c.tsm.values
+	if n := len(tvals.Timestamps); n > c.tsm.pos && tvals.Timestamps[n-1] < t {
+		c.tsm.keyCursor.markReadBefore(t)
+		tvals = c.nextTSM()
+	}
+	ts := tvals.Timestamps[c.tsm.pos:]
+	c.tsm.pos += sort.Search(len(ts), func(i int) bool {
+		return ts[i] >= t
+	})
+}
+
 type booleanArrayDescendingCursor struct {
 	cache struct {
 		values Values
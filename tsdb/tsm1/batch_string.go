@@ -17,8 +17,13 @@ var (
 // StringArrayEncodeAll encodes src into b, returning b and any error encountered.
 // The returned slice may be of a different length and capactity to b.
 //
-// Currently only the string compression scheme used snappy.
+// Blocks with few distinct strings are dictionary encoded, and others are compressed
+// with snappy.
 func StringArrayEncodeAll(src []string, b []byte) ([]byte, error) {
+	if dict, indexes, ok := stringDictionary(src); ok {
+		return appendStringDictionary(b, dict, indexes)
+	}
+
 	srcSz := 2 + len(src)*binary.MaxVarintLen32 // strings should't be longer than 64kb
 	for i := range src {
 		srcSz += len(src[i])
@@ -62,9 +67,11 @@ func StringArrayEncodeAll(src []string, b []byte) ([]byte, error) {
 }
 
 func StringArrayDecodeAll(b []byte, dst []string) ([]string, error) {
-	// First byte stores the encoding type, only have snappy format
-	// currently so ignore for now.
-	if len(b) > 0 {
+	// First byte stores the encoding type; any encoding other than a
+	// dictionary is snappy.
+	if len(b) > 0 && b[0]>>4 == stringCompressedDictionary {
+		return stringArrayDecodeAllDictionary(b, dst)
+	} else if len(b) > 0 {
 		var err error
 		// it is important that to note that `snappy.Decode` always returns
 		// a newly allocated slice as the final strings reference this slice
@@ -76,7 +83,12 @@ func StringArrayDecodeAll(b []byte, dst []string) ([]string, error) {
 	} else {
 		return []string{}, nil
 	}
+	return stringArrayDecodeAllUncompressed(b, dst)
+}
 
+// stringArrayDecodeAllUncompressed decodes the strings of b, each prefixed with its
+// variable byte encoded length, into dst. The strings refer to b.
+func stringArrayDecodeAllUncompressed(b []byte, dst []string) ([]string, error) {
 	var (
 		i, l int
 	)
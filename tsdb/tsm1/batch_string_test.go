@@ -128,6 +128,47 @@ func TestStringArrayEncodeAll_Multi_Compressed(t *testing.T) {
 	}
 }
 
+func TestStringArrayEncodeAll_Dictionary(t *testing.T) {
+	levels := []string{"debug", "info", "warn", "error"}
+	src := make([]string, 1000)
+	for i := range src {
+		src[i] = levels[i*7%len(levels)]
+	}
+
+	b, err := StringArrayEncodeAll(src, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if b[0]>>4 != stringCompressedDictionary {
+		t.Fatalf("unexpected encoding: got %v, exp %v", b[0]>>4, stringCompressedDictionary)
+	}
+
+	got, err := StringArrayDecodeAll(b, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cmp.Equal(got, src) {
+		t.Fatalf("unexpected value: -got/+exp\n%s", cmp.Diff(got, src))
+	}
+
+	var dec StringDecoder
+	if err := dec.SetBytes(b); err != nil {
+		t.Fatalf("unexpected error creating string decoder: %v", err)
+	}
+	for i, v := range src {
+		if !dec.Next() {
+			t.Fatalf("unexpected next value: got false, exp true")
+		}
+		if v != dec.Read() {
+			t.Fatalf("unexpected value at pos %d: got %v, exp %v", i, dec.Read(), v)
+		}
+	}
+	if dec.Next() {
+		t.Fatalf("unexpected next value: got true, exp false")
+	}
+}
+
 func TestStringArrayEncodeAll_Quick(t *testing.T) {
 	var base []byte
 	quick.Check(func(values []string) bool {
@@ -282,6 +323,8 @@ func TestStringArrayDecodeAll_CorruptBytes(t *testing.T) {
 	cases := []string{
 		"\x10\x03\b\x03Hi", // Higher length than actual data
 		"\x10\x1dp\x9c\x90\x90\x90\x90\x90\x90\x90\x90\x90length overflow----",
+		"\x20\x02\x02hi", // Fewer dictionary strings than its size
+		"\x20\x01\x02hi\xf0\x00\x00\x00\x00\x00\x00\x05", // Index beyond the dictionary
 		"0t\x00\x01\x000\x00\x01\x000\x00\x01\x000\x00\x01\x000\x00\x01" +
 			"\x000\x00\x01\x000\x00\x01\x000\x00\x00\x00\xff:\x01\x00\x01\x00\x01" +
 			"\x00\x01\x00\x01\x00\x01\x00\x010\x010\x000\x010\x010\x010\x01" +
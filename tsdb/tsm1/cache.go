@@ -26,10 +26,23 @@ var (
 	ErrSnapshotInProgress = fmt.Errorf("snapshot in progress")
 )
 
+// CacheFullError is returned by writes that would grow a cache beyond the
+// cache-max-memory-size setting. Such writes succeed again once the cache has
+// been snapshotted.
+type CacheFullError struct {
+	Size  uint64 // Size of the cache had the write succeeded.
+	Limit uint64
+}
+
+// Error implements the error interface.
+func (e CacheFullError) Error() string {
+	return fmt.Sprintf("cache-max-memory-size exceeded: (%d/%d)", e.Size, e.Limit)
+}
+
 // ErrCacheMemorySizeLimitExceeded returns an error indicating an operation
 // could not be completed due to exceeding the cache-max-memory-size setting.
 func ErrCacheMemorySizeLimitExceeded(n, limit uint64) error {
-	return fmt.Errorf("cache-max-memory-size exceeded: (%d/%d)", n, limit)
+	return CacheFullError{Size: n, Limit: limit}
 }
 
 // entry is a set of values and some metadata.
@@ -97,6 +97,11 @@ type CompactionPlanner interface {
 type DefaultPlanner struct {
 	FileStore fileStore
 
+	// PartitionDuration returns the duration of the time partitions of each measurement.
+	// Files of different partitions are never compacted together. If nil, files are not
+	// partitioned.
+	PartitionDuration PartitionDurationFunc
+
 	// compactFullWriteColdDuration specifies the length of time after
 	// which if no writes have been committed to the WAL, the engine will
 	// do a full compaction of the TSM files in this shard. This duration
@@ -202,8 +207,12 @@ func (c *DefaultPlanner) ParseFileName(path string) (int, int, error) {
 
 // FullyCompacted returns true if the shard is fully compacted.
 func (c *DefaultPlanner) FullyCompacted() bool {
-	gens := c.findGenerations(false)
-	return len(gens) <= 1 && !gens.hasTombstones()
+	for _, gens := range partitionGenerations(c.findGenerations(false), c.PartitionDuration) {
+		if len(gens) > 1 || gens.hasTombstones() {
+			return false
+		}
+	}
+	return true
 }
 
 // ForceFull causes the planner to return a full compaction plan the next time
@@ -231,6 +240,21 @@ func (c *DefaultPlanner) PlanLevel(level int) []CompactionGroup {
 	// split across several files in sequence.
 	generations := c.findGenerations(true)
 
+	var cGroups []CompactionGroup
+	for _, generations := range partitionGenerations(generations, c.PartitionDuration) {
+		cGroups = append(cGroups, c.planLevel(generations, level)...)
+	}
+
+	if !c.acquire(cGroups) {
+		return nil
+	}
+
+	return cGroups
+}
+
+// planLevel returns the sets of TSM files to rewrite for a specific level, from generations
+// that all belong to the same partition.
+func (c *DefaultPlanner) planLevel(generations tsmGenerations, level int) []CompactionGroup {
 	// If there is only one generation and no tombstones, then there's nothing to
 	// do.
 	if len(generations) <= 1 && !generations.hasTombstones() {
@@ -302,10 +326,6 @@ func (c *DefaultPlanner) PlanLevel(level int) []CompactionGroup {
 		}
 	}
 
-	if !c.acquire(cGroups) {
-		return nil
-	}
-
 	return cGroups
 }
 
@@ -327,6 +347,21 @@ func (c *DefaultPlanner) PlanOptimize() []CompactionGroup {
 	// split across several files in sequence.
 	generations := c.findGenerations(true)
 
+	var cGroups []CompactionGroup
+	for _, generations := range partitionGenerations(generations, c.PartitionDuration) {
+		cGroups = append(cGroups, c.planOptimize(generations)...)
+	}
+
+	if !c.acquire(cGroups) {
+		return nil
+	}
+
+	return cGroups
+}
+
+// planOptimize returns the sets of TSM files to optimize, from generations that all belong
+// to the same partition.
+func (c *DefaultPlanner) planOptimize(generations tsmGenerations) []CompactionGroup {
 	// If there is only one generation and no tombstones, then there's nothing to
 	// do.
 	if len(generations) <= 1 && !generations.hasTombstones() {
@@ -394,10 +429,6 @@ func (c *DefaultPlanner) PlanOptimize() []CompactionGroup {
 		cGroups = append(cGroups, cGroup)
 	}
 
-	if !c.acquire(cGroups) {
-		return nil
-	}
-
 	return cGroups
 }
 
@@ -405,6 +436,7 @@ func (c *DefaultPlanner) PlanOptimize() []CompactionGroup {
 // multiple groups if possible to allow compactions to run concurrently.
 func (c *DefaultPlanner) Plan(lastWrite time.Time) []CompactionGroup {
 	generations := c.findGenerations(true)
+	partitions := partitionGenerations(generations, c.PartitionDuration)
 
 	c.mu.RLock()
 	forceFull := c.forceFull
@@ -420,56 +452,83 @@ func (c *DefaultPlanner) Plan(lastWrite time.Time) []CompactionGroup {
 			c.mu.Unlock()
 		}
 
-		var tsmFiles []string
-		var genCount int
-		for i, group := range generations {
-			var skip bool
-
-			// Skip the file if it's over the max size and contains a full block and it does not have any tombstones
-			if len(generations) > 2 && group.size() > uint64(maxTSMFileSize) && c.FileStore.BlockCount(group.files[0].Path, 1) == MaxPointsPerBlock && !group.hasTombstones() {
-				skip = true
+		// Each partition is fully compacted separately.
+		var groups []CompactionGroup
+		for _, generations := range partitions {
+			if group := c.planFull(generations); group != nil {
+				groups = append(groups, group)
 			}
+		}
 
-			// We need to look at the level of the next file because it may need to be combined with this generation
-			// but won't get picked up on it's own if this generation is skipped.  This allows the most recently
-			// created files to get picked up by the full compaction planner and avoids having a few less optimally
-			// compressed files.
-			if i < len(generations)-1 {
-				if generations[i+1].level() <= 3 {
-					skip = false
-				}
-			}
+		if len(groups) == 0 || !c.acquire(groups) {
+			return nil
+		}
+		return groups
+	}
 
-			if skip {
-				continue
-			}
+	// don't plan if nothing has changed in the filestore
+	if c.lastPlanCheck.After(c.FileStore.LastModified()) && !generations.hasTombstones() {
+		return nil
+	}
 
-			for _, f := range group.files {
-				tsmFiles = append(tsmFiles, f.Path)
+	c.lastPlanCheck = time.Now()
+
+	var tsmFiles []CompactionGroup
+	for _, generations := range partitions {
+		tsmFiles = append(tsmFiles, c.plan(generations)...)
+	}
+
+	if len(tsmFiles) == 0 || !c.acquire(tsmFiles) {
+		return nil
+	}
+	return tsmFiles
+}
+
+// planFull returns all TSM files of generations, which all belong to the same partition,
+// if they should be fully compacted.
+func (c *DefaultPlanner) planFull(generations tsmGenerations) CompactionGroup {
+	var tsmFiles []string
+	var genCount int
+	for i, group := range generations {
+		var skip bool
+
+		// Skip the file if it's over the max size and contains a full block and it does not have any tombstones
+		if len(generations) > 2 && group.size() > uint64(maxTSMFileSize) && c.FileStore.BlockCount(group.files[0].Path, 1) == MaxPointsPerBlock && !group.hasTombstones() {
+			skip = true
+		}
+
+		// We need to look at the level of the next file because it may need to be combined with this generation
+		// but won't get picked up on it's own if this generation is skipped.  This allows the most recently
+		// created files to get picked up by the full compaction planner and avoids having a few less optimally
+		// compressed files.
+		if i < len(generations)-1 {
+			if generations[i+1].level() <= 3 {
+				skip = false
 			}
-			genCount += 1
 		}
-		sort.Strings(tsmFiles)
 
-		// Make sure we have more than 1 file and more than 1 generation
-		if len(tsmFiles) <= 1 || genCount <= 1 {
-			return nil
+		if skip {
+			continue
 		}
 
-		group := []CompactionGroup{tsmFiles}
-		if !c.acquire(group) {
-			return nil
+		for _, f := range group.files {
+			tsmFiles = append(tsmFiles, f.Path)
 		}
-		return group
+		genCount += 1
 	}
+	sort.Strings(tsmFiles)
 
-	// don't plan if nothing has changed in the filestore
-	if c.lastPlanCheck.After(c.FileStore.LastModified()) && !generations.hasTombstones() {
+	// Make sure we have more than 1 file and more than 1 generation
+	if len(tsmFiles) <= 1 || genCount <= 1 {
 		return nil
 	}
 
-	c.lastPlanCheck = time.Now()
+	return tsmFiles
+}
 
+// plan returns the sets of TSM files to rewrite for level 4 or higher, from generations
+// that all belong to the same partition.
+func (c *DefaultPlanner) plan(generations tsmGenerations) []CompactionGroup {
 	// If there is only one generation, return early to avoid re-compacting the same file
 	// over and over again.
 	if len(generations) <= 1 && !generations.hasTombstones() {
@@ -593,9 +652,6 @@ func (c *DefaultPlanner) Plan(lastWrite time.Time) []CompactionGroup {
 		tsmFiles = append(tsmFiles, cGroup)
 	}
 
-	if !c.acquire(tsmFiles) {
-		return nil
-	}
 	return tsmFiles
 }
 
@@ -695,6 +751,11 @@ type Compactor struct {
 	// RateLimit is the limit for disk writes for all concurrent compactions.
 	RateLimit limiter.Rate
 
+	// PartitionDuration returns the duration of the time partitions of each measurement.
+	// The data of each partition of a snapshot is written to separate TSM files, except for
+	// small partitions; see minPartitionSnapshotValues. If nil, snapshots are not partitioned.
+	PartitionDuration PartitionDurationFunc
+
 	formatFileName FormatFileNameFunc
 	parseFileName  ParseFileNameFunc
 
@@ -836,15 +897,24 @@ func (c *Compactor) WriteSnapshot(cache *Cache) ([]string, error) {
 		throttle = false
 	}
 
-	splits := cache.Split(concurrency)
+	// Each partition of the snapshot is written to its own generation of files.
+	caches := []*Cache{cache}
+	if c.PartitionDuration != nil {
+		caches = cache.splitByPartition(c.PartitionDuration)
+	}
+
+	var splits []*Cache
+	for _, cache := range caches {
+		splits = append(splits, cache.Split(concurrency)...)
+	}
 
 	type res struct {
 		files []string
 		err   error
 	}
 
-	resC := make(chan res, concurrency)
-	for i := 0; i < concurrency; i++ {
+	resC := make(chan res, len(splits))
+	for i := 0; i < len(splits); i++ {
 		go func(sp *Cache) {
 			iter := NewCacheKeyIterator(sp, MaxPointsPerBlock, intC)
 			files, err := c.writeNewFiles(c.FileStore.NextGeneration(), 0, nil, iter, throttle)
@@ -854,8 +924,8 @@ func (c *Compactor) WriteSnapshot(cache *Cache) ([]string, error) {
 	}
 
 	var err error
-	files := make([]string, 0, concurrency)
-	for i := 0; i < concurrency; i++ {
+	files := make([]string, 0, len(splits))
+	for i := 0; i < len(splits); i++ {
 		result := <-resC
 		if result.err != nil {
 			err = result.err
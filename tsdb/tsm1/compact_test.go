@@ -1746,6 +1746,101 @@ func TestDefaultPlanner_PlanLevel_SmallestCompactionStep(t *testing.T) {
 	}
 }
 
+// Ensures that files of different time partitions are never compacted together.
+func TestDefaultPlanner_PlanLevel_Partitioned(t *testing.T) {
+	var data []tsm1.FileStat
+	for i := 1; i <= 16; i++ {
+		// Odd generations hold data of the first hour, even generations of the second hour.
+		min := int64((i+1)%2) * int64(time.Hour)
+		data = append(data, tsm1.FileStat{
+			Path:    fmt.Sprintf("%02d-01.tsm1", i),
+			Size:    1 * 1024 * 1024,
+			MinTime: min,
+			MaxTime: min + int64(time.Minute),
+			MinKey:  []byte("cpu,host=A#!~#value"),
+			MaxKey:  []byte("cpu,host=B#!~#value"),
+		})
+	}
+
+	cp := tsm1.NewDefaultPlanner(
+		&fakeFileStore{
+			PathsFn: func() []tsm1.FileStat {
+				return data
+			},
+		}, tsm1.DefaultCompactFullWriteColdDuration,
+	)
+	cp.PartitionDuration = func([]byte) time.Duration { return time.Hour }
+
+	tsm := cp.PlanLevel(1)
+	if exp, got := 2, len(tsm); got != exp {
+		t.Fatalf("compaction group length mismatch: got %v, exp %v", got, exp)
+	}
+
+	for i, group := range tsm {
+		if exp, got := 8, len(group); got != exp {
+			t.Fatalf("tsm file length mismatch: got %v, exp %v", got, exp)
+		}
+		for j, p := range group {
+			if got, exp := p, data[2*j+i].Path; got != exp {
+				t.Fatalf("tsm file mismatch: got %v, exp %v", got, exp)
+			}
+		}
+	}
+
+	if cp.FullyCompacted() {
+		t.Fatal("expected the partitions not to be fully compacted")
+	}
+}
+
+// Ensures that a generation per partition is fully compacted.
+func TestDefaultPlanner_FullyCompacted_Partitioned(t *testing.T) {
+	data := []tsm1.FileStat{
+		{
+			Path:    "01-04.tsm1",
+			Size:    1 * 1024 * 1024,
+			MinTime: 0,
+			MaxTime: int64(time.Minute),
+			MinKey:  []byte("cpu,host=A#!~#value"),
+			MaxKey:  []byte("cpu,host=B#!~#value"),
+		},
+		{
+			Path:    "02-04.tsm1",
+			Size:    1 * 1024 * 1024,
+			MinTime: int64(time.Hour),
+			MaxTime: int64(time.Hour + time.Minute),
+			MinKey:  []byte("cpu,host=A#!~#value"),
+			MaxKey:  []byte("cpu,host=B#!~#value"),
+		},
+	}
+
+	cp := tsm1.NewDefaultPlanner(
+		&fakeFileStore{
+			PathsFn: func() []tsm1.FileStat {
+				return data
+			},
+		}, tsm1.DefaultCompactFullWriteColdDuration,
+	)
+	if cp.FullyCompacted() {
+		t.Fatal("expected two generations not to be fully compacted")
+	}
+
+	// Measurements that are not partitioned are compacted as before.
+	cp.PartitionDuration = func([]byte) time.Duration { return 0 }
+	if cp.FullyCompacted() {
+		t.Fatal("expected two generations of data that is not partitioned not to be fully compacted")
+	}
+
+	cp.PartitionDuration = func([]byte) time.Duration { return time.Hour }
+	if !cp.FullyCompacted() {
+		t.Fatal("expected a generation per partition to be fully compacted")
+	}
+
+	cp.ForceFull()
+	if tsm := cp.Plan(time.Now()); len(tsm) != 0 {
+		t.Fatalf("expected no full compaction across partitions, got %v", tsm)
+	}
+}
+
 func TestDefaultPlanner_PlanLevel_SplitFile(t *testing.T) {
 	data := []tsm1.FileStat{
 		{
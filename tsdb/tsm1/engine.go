@@ -176,6 +176,10 @@ type Engine struct {
 
 	MaxPointsPerBlock int
 
+	// PartitionDuration returns the duration of the time partitions of each measurement.
+	// If nil, the data of the engine is not partitioned.
+	PartitionDuration PartitionDurationFunc
+
 	// CacheFlushMemorySizeThreshold specifies the minimum size threshold for
 	// the cache when the engine should write a snapshot to a TSM file
 	CacheFlushMemorySizeThreshold uint64
@@ -283,9 +287,22 @@ func (e *Engine) WithFileStoreObserver(obs FileStoreObserver) {
 
 func (e *Engine) WithCompactionPlanner(planner CompactionPlanner) {
 	planner.SetFileStore(e.FileStore)
+	if p, ok := planner.(*DefaultPlanner); ok && e.PartitionDuration != nil {
+		p.PartitionDuration = e.PartitionDuration
+	}
 	e.CompactionPlan = planner
 }
 
+// WithPartitionDuration partitions the data of each measurement by time, into partitions of
+// the duration returned by fn. See DeletePartitionsWithPredicate.
+func (e *Engine) WithPartitionDuration(fn PartitionDurationFunc) {
+	e.PartitionDuration = fn
+	e.Compactor.PartitionDuration = fn
+	if p, ok := e.CompactionPlan.(*DefaultPlanner); ok {
+		p.PartitionDuration = fn
+	}
+}
+
 // SetEnabled sets whether the engine is enabled.
 func (e *Engine) SetEnabled(enabled bool) {
 	e.enableCompactionsOnOpen = enabled
@@ -689,6 +706,16 @@ func (e *Engine) Free() error {
 // WritePoints writes metadata and point data into the engine.
 // It returns an error if new points are added to an existing key.
 func (e *Engine) WritePoints(points []models.Point) error {
+	values, err := PointsToValues(points)
+	if err != nil {
+		return err
+	}
+	return e.WriteValues(values)
+}
+
+// PointsToValues returns the values of the fields of points, keyed by the series and field
+// key of each field.
+func PointsToValues(points []models.Point) (map[string][]Value, error) {
 	values := make(map[string][]Value, len(points))
 	var (
 		keyBuf  []byte
@@ -709,19 +736,19 @@ func (e *Engine) WritePoints(points []models.Point) error {
 			case models.Float:
 				fv, err := iter.FloatValue()
 				if err != nil {
-					return err
+					return nil, err
 				}
 				v = NewFloatValue(t, fv)
 			case models.Integer:
 				iv, err := iter.IntegerValue()
 				if err != nil {
-					return err
+					return nil, err
 				}
 				v = NewIntegerValue(t, iv)
 			case models.Unsigned:
 				iv, err := iter.UnsignedValue()
 				if err != nil {
-					return err
+					return nil, err
 				}
 				v = NewUnsignedValue(t, iv)
 			case models.String:
@@ -729,16 +756,21 @@ func (e *Engine) WritePoints(points []models.Point) error {
 			case models.Boolean:
 				bv, err := iter.BooleanValue()
 				if err != nil {
-					return err
+					return nil, err
 				}
 				v = NewBooleanValue(t, bv)
 			default:
-				return fmt.Errorf("unknown field type for %s: %s", string(iter.FieldKey()), p.String())
+				return nil, fmt.Errorf("unknown field type for %s: %s", string(iter.FieldKey()), p.String())
 			}
 			values[string(keyBuf)] = append(values[string(keyBuf)], v)
 		}
 	}
 
+	return values, nil
+}
+
+// WriteValues writes values, keyed by series and field key, into the engine.
+func (e *Engine) WriteValues(values map[string][]Value) error {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 
@@ -1591,6 +1623,48 @@ func (e *Engine) KeyCursor(ctx context.Context, key []byte, t int64, ascending b
 	return e.FileStore.KeyCursor(ctx, key, t, ascending)
 }
 
+// HasData returns true if the cache or any TSM file holds data for key, the key of a
+// series and field, within the time range min to max, inclusive.
+func (e *Engine) HasData(key []byte, min, max int64) bool {
+	if len(e.Cache.Values(key).Include(min, max)) > 0 {
+		return true
+	}
+	return e.FileStore.HasData(key, min, max)
+}
+
+// PrefixStats returns the size, number of points and time range of the data of the keys
+// beginning with prefix. Points in the cache are counted, but add nothing to the size, and
+// the points of a snapshot being written to TSM files are not counted.
+func (e *Engine) PrefixStats(prefix []byte) (PrefixStats, error) {
+	stats, err := e.FileStore.PrefixStats(prefix)
+	if err != nil {
+		return PrefixStats{}, err
+	}
+
+	err = e.Cache.ApplyEntryFn(func(key []byte, entry *entry) error {
+		if !bytes.HasPrefix(key, prefix) {
+			return nil
+		}
+
+		entry.mu.RLock()
+		defer entry.mu.RUnlock()
+		if len(entry.values) == 0 {
+			return nil
+		}
+		min, max := entry.values[0].UnixNano(), entry.values[0].UnixNano()
+		for _, v := range entry.values[1:] {
+			if t := v.UnixNano(); t < min {
+				min = t
+			} else if t > max {
+				max = t
+			}
+		}
+		stats.add(0, len(entry.values), min, max)
+		return nil
+	})
+	return stats, err
+}
+
 // IteratorCost produces the cost of an iterator.
 func (e *Engine) IteratorCost(measurement string, opt query.IteratorOptions) (query.IteratorCost, error) {
 	// Determine if this measurement exists. If it does not, then no shards are
@@ -2,21 +2,26 @@ package tsm1_test
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io/ioutil"
 	"math"
 	"os"
 	"path/filepath"
+	"reflect"
 	"runtime"
+	"sort"
 	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/influxdata/influxdb/pkg/metrics"
 	"github.com/influxdata/influxql"
 	"github.com/influxdata/platform/logger"
 	"github.com/influxdata/platform/models"
 	"github.com/influxdata/platform/tsdb"
+	"github.com/influxdata/platform/tsdb/cursors"
 	"github.com/influxdata/platform/tsdb/tsi1"
 	"github.com/influxdata/platform/tsdb/tsm1"
 )
@@ -655,6 +660,126 @@ func TestEngine_DeleteSeriesRange_OutsideTime(t *testing.T) {
 	}
 }
 
+func TestEngine_DeletePartitionsWithPredicate(t *testing.T) {
+	day := int64(24 * time.Hour)
+
+	e, err := NewEngine()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// mock the planner so compactions don't run during the test
+	e.CompactionPlan = &mockPlanner{}
+	e.WithPartitionDuration(func([]byte) time.Duration { return time.Duration(day) })
+	if err := e.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+
+	if err := e.WritePointsString(
+		fmt.Sprintf("cpu,host=A value=1.1 %d", 1),
+		fmt.Sprintf("cpu,host=B value=1.2 %d", 2),
+		fmt.Sprintf("cpu,host=A value=1.3 %d", day+1),
+		fmt.Sprintf("mem,host=C value=1.4 %d", day+2),
+	); err != nil {
+		t.Fatal(err)
+	}
+	e.MustWriteSnapshot()
+
+	// The data of each partition is written to its own file.
+	if exp, got := 2, e.FileStore.Count(); got != exp {
+		t.Fatalf("file count mismatch: got %v, exp %v", got, exp)
+	}
+
+	// Only the first partition has no data besides that of cpu, and ends before day.
+	cpuExpired := func(name []byte, min, max int64) bool {
+		return string(name) == "cpu" && max < day
+	}
+	if err := e.DeletePartitionsWithPredicate(cpuExpired); err != nil {
+		t.Fatal(err)
+	}
+
+	if exp, got := 1, e.FileStore.Count(); got != exp {
+		t.Fatalf("file count mismatch: got %v, exp %v", got, exp)
+	}
+
+	keys := e.FileStore.Keys()
+	for _, exp := range []string{"cpu,host=A#!~#value", "mem,host=C#!~#value"} {
+		if _, ok := keys[exp]; !ok {
+			t.Fatalf("expected key %q to remain, got %v", exp, keys)
+		}
+	}
+
+	// cpu,host=B no longer has any data, so it is removed from the index.
+	if exp, got := uint64(2), e.SeriesIDSet().Cardinality(); got != exp {
+		t.Fatalf("series cardinality mismatch: got %v, exp %v", got, exp)
+	}
+
+	if err := e.DeletePartitionsWithPredicate(func(name []byte, min, max int64) bool { return true }); err != nil {
+		t.Fatal(err)
+	}
+	if exp, got := 0, e.FileStore.Count(); got != exp {
+		t.Fatalf("file count mismatch: got %v, exp %v", got, exp)
+	}
+	if exp, got := uint64(0), e.SeriesIDSet().Cardinality(); got != exp {
+		t.Fatalf("series cardinality mismatch: got %v, exp %v", got, exp)
+	}
+}
+
+// Ensures that a snapshot holding a little data of many partitions does not write a file per partition.
+func TestEngine_WriteSnapshot_Backfill(t *testing.T) {
+	day := int64(24 * time.Hour)
+
+	e, err := NewEngine()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// mock the planner so compactions don't run during the test
+	e.CompactionPlan = &mockPlanner{}
+	e.WithPartitionDuration(func(name []byte) time.Duration {
+		if string(name) == "cpu" {
+			return time.Duration(day)
+		}
+		return 0
+	})
+	if err := e.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+
+	var points []string
+	for i := int64(0); i < 30; i++ {
+		points = append(points, fmt.Sprintf("cpu,host=A value=%d %d", i, i*day))
+	}
+	points = append(points, fmt.Sprintf("mem,host=C value=1 %d", 0))
+	if err := e.WritePointsString(points...); err != nil {
+		t.Fatal(err)
+	}
+	e.MustWriteSnapshot()
+
+	// The latest partition is written to its own file. The data of the other, small,
+	// partitions is written together with the data that is not partitioned.
+	stats := e.FileStore.Stats()
+	if exp, got := 2, len(stats); got != exp {
+		t.Fatalf("file count mismatch: got %v, exp %v", got, exp)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].MaxTime < stats[j].MaxTime })
+	if got, exp := stats[0].MaxTime, 28*day; got != exp {
+		t.Fatalf("unexpected max time of the shared file: got %v, exp %v", got, exp)
+	}
+	if got, exp := stats[1].MinTime, 29*day; got != exp {
+		t.Fatalf("unexpected min time of the latest partition: got %v, exp %v", got, exp)
+	}
+
+	keys := e.FileStore.Keys()
+	for _, exp := range []string{"cpu,host=A#!~#value", "mem,host=C#!~#value"} {
+		if _, ok := keys[exp]; !ok {
+			t.Fatalf("expected key %q, got %v", exp, keys)
+		}
+	}
+}
+
 func TestEngine_LastModified(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping test in short mode")
@@ -916,6 +1041,102 @@ func BenchmarkEngine_WritePoints_Parallel(b *testing.B) {
 	}
 }
 
+func TestEngine_CursorSeek(t *testing.T) {
+	e := MustOpenEngine()
+	defer e.Close()
+
+	// Write three TSM files with a block each, and leave some values in the cache.
+	for _, pts := range [][]string{
+		{"cpu,host=A value=1 1", "cpu,host=A value=2 2"},
+		{"cpu,host=A value=3 3", "cpu,host=A value=4 4"},
+		{"cpu,host=A value=5 5", "cpu,host=A value=6 6"},
+	} {
+		if err := e.WritePointsString(pts...); err != nil {
+			t.Fatal(err)
+		}
+		if err := e.WriteSnapshot(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := e.WritePointsString("cpu,host=A value=7 7"); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := tsm1.NewContextWithMetricsGroup(context.Background())
+	itr, err := e.CreateCursorIterator(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cur, err := itr.Next(ctx, &tsdb.CursorRequest{
+		Name:      []byte("cpu"),
+		Tags:      models.NewTags(map[string]string{"host": "A"}),
+		Field:     "value",
+		Ascending: true,
+		StartTime: models.MinNanoTime,
+		EndTime:   models.MaxNanoTime,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cur.Close()
+
+	// Seeking past the second file skips its block without decoding it.
+	cur.(cursors.Seeker).SeekTo(6)
+	a := cur.(cursors.FloatArrayCursor).Next()
+	if got, exp := a.Timestamps, []int64{6, 7}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("unexpected timestamps: got %v, exp %v", got, exp)
+	}
+
+	var decoded int64
+	tsm1.MetricsGroupFromContext(ctx).ForEach(func(m metrics.Metric) {
+		if m.Name() == "float_blocks_decoded" {
+			decoded = m.(*metrics.Counter).Value()
+		}
+	})
+	if got, exp := decoded, int64(2); got != exp {
+		t.Fatalf("unexpected number of blocks decoded: got %d, exp %d", got, exp)
+	}
+}
+
+func TestEngine_PrefixStats(t *testing.T) {
+	e := MustOpenEngine()
+	defer e.Close()
+
+	for _, pts := range [][]string{
+		{"cpu,host=A value=1 1", "cpu,host=A value=2 2"},
+		{"cpu,host=B value=3 3", "mem,host=A value=4 20"},
+	} {
+		if err := e.WritePointsString(pts...); err != nil {
+			t.Fatal(err)
+		}
+		if err := e.WriteSnapshot(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Points in the cache are counted, but have no size.
+	if err := e.WritePointsString("cpu,host=B value=5 10", "cpu,host=B value=6 0"); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := e.PrefixStats([]byte("cpu,"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Size <= 0 {
+		t.Fatalf("unexpected size: %d", stats.Size)
+	}
+	stats.Size = 0
+	if got, exp := stats, (tsm1.PrefixStats{PointN: 5, MinTime: 0, MaxTime: 10}); got != exp {
+		t.Fatalf("unexpected stats: got %+v, exp %+v", got, exp)
+	}
+
+	if stats, err := e.PrefixStats([]byte("disk,")); err != nil {
+		t.Fatal(err)
+	} else if stats != (tsm1.PrefixStats{}) {
+		t.Fatalf("unexpected stats for missing prefix: %+v", stats)
+	}
+}
+
 // Engine is a test wrapper for tsm1.Engine.
 type Engine struct {
 	*tsm1.Engine
@@ -148,6 +148,9 @@ type TSMFile interface {
 
 	// Stats returns the statistics for the file.
 	MeasurementStats() (MeasurementStats, error)
+
+	// ReadBytes returns the checksum and the encoded bytes of the block identified by entry.
+	ReadBytes(entry *IndexEntry, b []byte) (uint32, []byte, error)
 }
 
 // FileStoreObserver is passed notifications before the file store adds or deletes files. In this way, it can
@@ -659,6 +662,83 @@ func (f *FileStore) Cost(key []byte, min, max int64) query.IteratorCost {
 	return f.cost(key, min, max)
 }
 
+// HasData returns true if any file in the FileStore holds a block for key that overlaps
+// the time range min to max, inclusive, and is not entirely deleted. As blocks are only
+// compared by their time range, the values of such a block may all fall outside of the range.
+func (f *FileStore) HasData(key []byte, min, max int64) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.hasData(key, min, max)
+}
+
+// PrefixStats returns the size, number of points and time range of the blocks of the keys
+// beginning with prefix. Blocks entirely deleted by tombstones are skipped, but all points of
+// the other blocks are counted, including those partially deleted or overwritten by later files.
+//
+// The size and time range of each block are read from the TSM index. The index does not record
+// the number of points of a block, so it is read from the timestamp header of the block, without
+// decoding its values. The files are referenced rather than locked while they are read, so that
+// writes and compactions are not held up.
+func (f *FileStore) PrefixStats(prefix []byte) (PrefixStats, error) {
+	f.mu.RLock()
+	files := make([]TSMFile, len(f.files))
+	copy(files, f.files)
+	for _, fd := range files {
+		fd.Ref()
+	}
+	f.mu.RUnlock()
+
+	defer func() {
+		for _, fd := range files {
+			fd.Unref()
+		}
+	}()
+
+	var stats PrefixStats
+	var entries []IndexEntry
+	for _, fd := range files {
+		for i, n := fd.Seek(prefix), fd.KeyCount(); i < n; i++ {
+			key, _ := fd.KeyAt(i)
+			if !bytes.HasPrefix(key, prefix) {
+				break
+			}
+			tombstones := fd.TombstoneRange(key)
+
+		ENTRIES:
+			for _, ie := range fd.ReadEntries(key, &entries) {
+				for _, t := range tombstones {
+					if t.Min <= ie.MinTime && t.Max >= ie.MaxTime {
+						continue ENTRIES
+					}
+				}
+
+				n, err := blockPointCount(fd, &ie)
+				if err != nil {
+					return PrefixStats{}, err
+				}
+				stats.add(int(ie.Size), n, ie.MinTime, ie.MaxTime)
+			}
+		}
+	}
+	return stats, nil
+}
+
+// blockPointCount returns the number of points of the block of ie, read from the header of
+// its timestamps.
+func blockPointCount(fd TSMFile, ie *IndexEntry) (int, error) {
+	_, b, err := fd.ReadBytes(ie, nil)
+	if err != nil {
+		return 0, err
+	} else if len(b) <= encodedBlockHeaderSize {
+		return 0, fmt.Errorf("block of %d bytes is too short", len(b))
+	}
+	tb, _, err := unpackBlock(b[encodedBlockHeaderSize:])
+	if err != nil {
+		return 0, err
+	}
+	return CountTimestamps(tb), nil
+}
+
 // Reader returns a TSMReader for path if one is currently managed by the FileStore.
 // Otherwise it returns nil. If it returns a file, you must call Unref on it when
 // you are done, and never use it after that.
@@ -971,6 +1051,36 @@ func (f *FileStore) cost(key []byte, min, max int64) query.IteratorCost {
 	return cost
 }
 
+func (f *FileStore) hasData(key []byte, min, max int64) bool {
+	var cache []IndexEntry
+	for _, fd := range f.files {
+		minTime, maxTime := fd.TimeRange()
+		if maxTime < min || minTime > max {
+			continue
+		}
+		tombstones := fd.TombstoneRange(key)
+
+		entries := fd.ReadEntries(key, &cache)
+	ENTRIES:
+		for i := 0; i < len(entries); i++ {
+			ie := entries[i]
+
+			if ie.MaxTime < min || ie.MinTime > max {
+				continue
+			}
+
+			// Skip any blocks only contain values that are tombstoned.
+			for _, t := range tombstones {
+				if t.Min <= ie.MinTime && t.Max >= ie.MaxTime {
+					continue ENTRIES
+				}
+			}
+			return true
+		}
+	}
+	return false
+}
+
 // locations returns the files and index blocks for a key and time.  ascending indicates
 // whether the key will be scan in ascending time order or descenging time order.
 // This function assumes the read-lock has been taken.
@@ -1284,6 +1394,14 @@ func (c *KeyCursor) seekDescending(t int64) {
 	}
 }
 
+// markReadBefore marks the values before t as read, as seeking an ascending cursor
+// does, so that the blocks that end before t are skipped without being decoded.
+func (c *KeyCursor) markReadBefore(t int64) {
+	for _, l := range c.seeks {
+		l.markRead(math.MinInt64, t-1)
+	}
+}
+
 // Next moves the cursor to the next position.
 // Data should be read by the ReadBlock functions.
 func (c *KeyCursor) Next() {
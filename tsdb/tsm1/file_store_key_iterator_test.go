@@ -178,6 +178,7 @@ func (*mockTSMFile) Stats() FileStat
 func (*mockTSMFile) BlockIterator() *BlockIterator                              { panic("implement me") }
 func (*mockTSMFile) Free() error                                                { panic("implement me") }
 func (*mockTSMFile) MeasurementStats() (MeasurementStats, error)                { panic("implement me") }
+func (*mockTSMFile) ReadBytes(*IndexEntry, []byte) (uint32, []byte, error)     { panic("implement me") }
 
 func (*mockTSMFile) ReadFloatBlockAt(*IndexEntry, *[]FloatValue) ([]FloatValue, error) {
 	panic("implement me")
@@ -0,0 +1,326 @@
+package tsm1
+
+import (
+	"bytes"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/influxdata/platform/models"
+	"github.com/influxdata/platform/pkg/bytesutil"
+	"go.uber.org/zap"
+)
+
+// Data is grouped into time partitions: consecutive, non-overlapping time ranges of
+// a fixed duration, chosen per measurement. Snapshots write the data of each partition
+// to separate TSM files, and the compaction planner never compacts files of different
+// partitions together, so that once all the data of a partition has expired its TSM
+// files can be removed whole, rather than by writing tombstones.
+
+// minPartitionSnapshotValues is the number of values below which a snapshot does not write
+// a partition to files of its own, unless it is the latest partition of its duration.
+// This keeps the snapshot of a backfill, which holds a little data of many partitions,
+// from writing many small files. The data of such partitions is written together with
+// the data that is not partitioned, and expires by tombstones.
+const minPartitionSnapshotValues = 100 * MaxPointsPerBlock
+
+// PartitionDurationFunc returns the duration of the time partitions of the data of the
+// measurement name. A zero duration means the data of the measurement is not partitioned.
+type PartitionDurationFunc func(name []byte) time.Duration
+
+// duration returns the partition duration of the data of key, which is either a series
+// key or a composite key. It is safe to call on a nil fn.
+func (fn PartitionDurationFunc) duration(key []byte) time.Duration {
+	if fn == nil || len(key) == 0 {
+		return 0
+	}
+	seriesKey, _ := SeriesAndFieldFromCompositeKey(key)
+	return fn(models.ParseName(seriesKey))
+}
+
+// timePartition identifies the time partition of the given duration beginning at start.
+// The zero timePartition stands for data that is not partitioned.
+type timePartition struct {
+	duration time.Duration
+	start    int64
+}
+
+// partitionStart returns the start of the partition of duration d containing t.
+func partitionStart(t int64, d time.Duration) int64 {
+	n := int64(d)
+	start := t - t%n
+	if t < 0 && t%n != 0 {
+		start -= n
+	}
+	return start
+}
+
+// partitionEnd returns the last time of the partition that starts at start.
+func partitionEnd(start int64, d time.Duration) int64 {
+	if start > math.MaxInt64-int64(d) {
+		return math.MaxInt64
+	}
+	return start + int64(d) - 1
+}
+
+// partitionOf returns the start of the partition of duration d containing all of min
+// through max. It returns false if the range spans several partitions, as is the case
+// for files written before partitioning was enabled.
+func partitionOf(min, max int64, d time.Duration) (int64, bool) {
+	start := partitionStart(min, d)
+	return start, partitionStart(max, d) == start
+}
+
+// filePartition returns the partition holding all the data of the file f. It returns false
+// if the data of f is not partitioned, or spans several partitions.
+func (fn PartitionDurationFunc) filePartition(f FileStat) (timePartition, bool) {
+	d := fn.duration(f.MinKey)
+	if d <= 0 || fn.duration(f.MaxKey) != d {
+		return timePartition{}, false
+	}
+	start, ok := partitionOf(f.MinTime, f.MaxTime, d)
+	if !ok {
+		return timePartition{}, false
+	}
+	return timePartition{duration: d, start: start}, true
+}
+
+// partition returns the partition holding the data of every file of the generation.
+func (t *tsmGeneration) partition(fn PartitionDurationFunc) (timePartition, bool) {
+	var p timePartition
+	for i, f := range t.files {
+		fp, ok := fn.filePartition(f)
+		if !ok || (i > 0 && fp != p) {
+			return timePartition{}, false
+		}
+		p = fp
+	}
+	return p, len(t.files) > 0
+}
+
+// partitionGenerations groups generations by the partition holding their data. Generations
+// within each group keep their relative order. All generations that are not within a single
+// partition form a group of their own. If fn is nil, partitioning is disabled and all
+// generations are returned as a single group.
+func partitionGenerations(generations tsmGenerations, fn PartitionDurationFunc) []tsmGenerations {
+	if fn == nil {
+		return []tsmGenerations{generations}
+	}
+
+	var (
+		partitions  []tsmGenerations
+		byPartition = make(map[timePartition]int)
+	)
+	for _, g := range generations {
+		// Generations that are not within a single partition are grouped under the zero partition.
+		p, _ := g.partition(fn)
+
+		i, ok := byPartition[p]
+		if !ok {
+			i = len(partitions)
+			byPartition[p] = i
+			partitions = append(partitions, nil)
+		}
+		partitions[i] = append(partitions[i], g)
+	}
+	return partitions
+}
+
+// splitByPartition splits the cache into one cache per partition, each holding the values
+// of the cache within that partition. The values of measurements that are not partitioned,
+// and those of small partitions other than the latest of their duration, are returned
+// together in a single cache; see minPartitionSnapshotValues. The caches are returned ordered
+// by partition. splitByPartition is intended for snapshots, which are no longer written to.
+func (c *Cache) splitByPartition(fn PartitionDurationFunc) []*Cache {
+	partitionOf := func(d time.Duration, v Value) timePartition {
+		if d <= 0 {
+			return timePartition{}
+		}
+		return timePartition{duration: d, start: partitionStart(v.UnixNano(), d)}
+	}
+
+	// Count the values of each partition, to find those written to files of their own.
+	counts := make(map[timePartition]int)
+	_ = c.store.applySerial(func(key []byte, e *entry) error {
+		d := fn.duration(key)
+		e.mu.RLock()
+		defer e.mu.RUnlock()
+		for _, v := range e.values {
+			counts[partitionOf(d, v)]++
+		}
+		return nil
+	})
+
+	latest := make(map[time.Duration]int64)
+	for p := range counts {
+		if start, ok := latest[p.duration]; !ok || p.start > start {
+			latest[p.duration] = p.start
+		}
+	}
+	own := func(p timePartition) bool {
+		return p.duration > 0 && (counts[p] >= minPartitionSnapshotValues || latest[p.duration] == p.start)
+	}
+
+	stores := make(map[timePartition]storer)
+	_ = c.store.applySerial(func(key []byte, e *entry) error {
+		d := fn.duration(key)
+		e.mu.RLock()
+		defer e.mu.RUnlock()
+
+		byPartition := make(map[timePartition]Values)
+		for _, v := range e.values {
+			p := partitionOf(d, v)
+			if !own(p) {
+				p = timePartition{}
+			}
+			byPartition[p] = append(byPartition[p], v)
+		}
+
+		for p, values := range byPartition {
+			store, ok := stores[p]
+			if !ok {
+				store, _ = newring(ringShards)
+				stores[p] = store
+			}
+			store.add(key, &entry{values: values, vtype: e.vtype})
+		}
+		return nil
+	})
+
+	partitions := make([]timePartition, 0, len(stores))
+	for p := range stores {
+		partitions = append(partitions, p)
+	}
+	sort.Slice(partitions, func(i, j int) bool {
+		if partitions[i].duration != partitions[j].duration {
+			return partitions[i].duration < partitions[j].duration
+		}
+		return partitions[i].start < partitions[j].start
+	})
+
+	caches := make([]*Cache, 0, len(partitions))
+	for _, p := range partitions {
+		caches = append(caches, &Cache{store: stores[p]})
+	}
+	return caches
+}
+
+// DeletePartitionsWithPredicate removes the TSM files of every partition in which all
+// data has expired, along with any series that no longer have data from the index.
+//
+// predicate is called with the name of each measurement with data in a partition, and the
+// time range of the partition. It must return true if all data of the measurement within
+// that time range can be removed. A partition is only removed if predicate returns true
+// for all of its measurements.
+//
+// Files that span several partitions, such as those written before partitioning was enabled,
+// are never removed; their expired data must be deleted with DeleteSeriesRangeWithPredicate.
+func (e *Engine) DeletePartitionsWithPredicate(predicate func(name []byte, min, max int64) bool) error {
+	fn := e.PartitionDuration
+	if fn == nil {
+		return nil // Partitioning disabled.
+	}
+
+	// Ensure that no compaction is using, or can start using, the files being removed.
+	e.disableLevelCompactions(true)
+	defer e.enableLevelCompactions(true)
+
+	partitions := make(map[timePartition][]string)
+	for _, f := range e.FileStore.Stats() {
+		if p, ok := fn.filePartition(f); ok {
+			partitions[p] = append(partitions[p], f.Path)
+		}
+	}
+
+	for p, files := range partitions {
+		end := partitionEnd(p.start, p.duration)
+		seriesKeys, expired := e.expiredPartitionSeries(files, p.start, end, predicate)
+		if !expired {
+			continue
+		}
+
+		if err := e.deletePartition(files, seriesKeys, p.start, end); err != nil {
+			return err
+		}
+		e.logger.Info("Removed expired partition",
+			zap.Int64("min", p.start),
+			zap.Int64("max", end),
+			zap.Int("files", len(files)),
+			zap.Int("series", len(seriesKeys)))
+	}
+	return nil
+}
+
+// expiredPartitionSeries returns the sorted series keys with data in files, and whether
+// predicate reports every measurement of those series as expired between min and max.
+func (e *Engine) expiredPartitionSeries(files []string, min, max int64, predicate func(name []byte, min, max int64) bool) ([][]byte, bool) {
+	var seriesKeys [][]byte
+	for _, path := range files {
+		r := e.FileStore.TSMReader(path)
+		if r == nil {
+			return nil, false // The file was removed concurrently.
+		}
+
+		var lastName, lastSeriesKey []byte
+		expired := true
+		for i, n := 0, r.KeyCount(); i < n && expired; i++ {
+			key, _ := r.KeyAt(i)
+			seriesKey, _ := SeriesAndFieldFromCompositeKey(key)
+			if bytes.Equal(seriesKey, lastSeriesKey) {
+				continue // Another field of the same series.
+			}
+			lastSeriesKey = seriesKey
+
+			// The key is copied, as it refers to the file, which is about to be removed.
+			seriesKeys = append(seriesKeys, bytesutil.Clone(seriesKey))
+
+			if name := models.ParseName(seriesKey); !bytes.Equal(name, lastName) {
+				lastName = name
+				expired = predicate(name, min, max)
+			}
+		}
+		r.Unref()
+
+		if !expired {
+			return nil, false
+		}
+	}
+
+	return bytesutil.SortDedup(seriesKeys), true
+}
+
+// deletePartition removes files, which hold the data of the series seriesKeys between min
+// and max, then removes any of those series without remaining data from the index.
+// Only the series of the partition are looked up; the rest of the index is not scanned.
+func (e *Engine) deletePartition(files []string, seriesKeys [][]byte, min, max int64) error {
+	// Ensure that the index does not compact away the series before we're done with them.
+	e.index.DisableCompactions()
+	defer e.index.EnableCompactions()
+	e.index.Wait()
+
+	e.sfile.DisableCompactions()
+	defer e.sfile.EnableCompactions()
+	e.sfile.Wait()
+
+	if err := e.FileStore.Replace(files, nil); err != nil {
+		return err
+	}
+
+	// The files are gone, so deleting the series within the partition only tombstones data
+	// that other files or the cache still hold for the partition, and removes the series
+	// that are left without data from the index.
+	var sz int
+	batch := make([][]byte, 0, 10000)
+	for _, key := range seriesKeys {
+		if sz >= deleteFlushThreshold {
+			if err := e.deleteSeriesRange(batch, min, max); err != nil {
+				return err
+			}
+			batch = batch[:0]
+			sz = 0
+		}
+		sz += len(key)
+		batch = append(batch, key)
+	}
+	return e.deleteSeriesRange(batch, min, max)
+}
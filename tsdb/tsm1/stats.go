@@ -219,3 +219,32 @@ func StatsFilename(tsmPath string) string {
 	}
 	return tsmPath + "." + TSSFileExtension
 }
+
+// PrefixStats summarizes the data of the keys with a common prefix.
+type PrefixStats struct {
+	Size    int64 // Size of the TSM blocks holding the data, in bytes.
+	PointN  int64 // Number of points.
+	MinTime int64 // Time of the oldest point, if PointN > 0.
+	MaxTime int64 // Time of the newest point, if PointN > 0.
+}
+
+// add adds n points between min and max, held in a block of sz bytes.
+func (s *PrefixStats) add(sz, n int, min, max int64) {
+	if s.PointN == 0 || min < s.MinTime {
+		s.MinTime = min
+	}
+	if s.PointN == 0 || max > s.MaxTime {
+		s.MaxTime = max
+	}
+	s.Size += int64(sz)
+	s.PointN += int64(n)
+}
+
+// Add adds other to s.
+func (s *PrefixStats) Add(other PrefixStats) {
+	if other.PointN == 0 {
+		s.Size += other.Size
+		return
+	}
+	s.add(int(other.Size), int(other.PointN), other.MinTime, other.MaxTime)
+}
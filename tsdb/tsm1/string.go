@@ -4,18 +4,35 @@ package tsm1
 // appended to byte slice prefixed with a variable byte length followed by the string
 // bytes.  The bytes are compressed using snappy compressor and a 1 byte header is used
 // to indicate the type of encoding.
+//
+// Blocks with few distinct strings are instead dictionary encoded.  The header is
+// followed by the variable byte encoded number of distinct strings, then each distinct
+// string prefixed with its variable byte length, then the index of each string into the
+// distinct strings, packed using simple8b.
 
 import (
 	"encoding/binary"
 	"fmt"
+	"unsafe"
 
 	"github.com/golang/snappy"
+	"github.com/influxdata/platform/pkg/encoding/simple8b"
 )
 
 // Note: an uncompressed format is not yet implemented.
 
-// stringCompressedSnappy is a compressed encoding using Snappy compression
-const stringCompressedSnappy = 1
+const (
+	// stringCompressedSnappy is a compressed encoding using Snappy compression
+	stringCompressedSnappy = 1
+
+	// stringCompressedDictionary is a compressed encoding using a dictionary of the
+	// distinct strings and simple8b packed indexes into it
+	stringCompressedDictionary = 2
+)
+
+// stringDictionaryMaxSize is the largest number of distinct strings of a dictionary
+// encoded block.
+const stringDictionaryMaxSize = 256
 
 // StringEncoder encodes multiple strings into a byte slice.
 type StringEncoder struct {
@@ -51,6 +68,14 @@ func (e *StringEncoder) Write(s string) {
 
 // Bytes returns a copy of the underlying buffer.
 func (e *StringEncoder) Bytes() ([]byte, error) {
+	values, err := stringArrayDecodeAllUncompressed(e.bytes, nil)
+	if err != nil {
+		return nil, err
+	}
+	if dict, indexes, ok := stringDictionary(values); ok {
+		return appendStringDictionary(nil, dict, indexes)
+	}
+
 	// Compress the currently appended bytes using snappy and prefix with
 	// a 1 byte header for future extension
 	data := snappy.Encode(nil, e.bytes)
@@ -63,13 +88,32 @@ type StringDecoder struct {
 	l   int
 	i   int
 	err error
+
+	// values holds the strings of a dictionary encoded block, which are read in turn
+	// instead of b.
+	values []string
+	dict   bool
 }
 
 // SetBytes initializes the decoder with bytes to read from.
 // This must be called before calling any other method.
 func (e *StringDecoder) SetBytes(b []byte) error {
-	// First byte stores the encoding type, only have snappy format
-	// currently so ignore for now.
+	e.l = 0
+	e.i = 0
+	e.err = nil
+
+	if len(b) > 0 && b[0]>>4 == stringCompressedDictionary {
+		values, err := stringArrayDecodeAllDictionary(b, e.values)
+		if err != nil {
+			return err
+		}
+		e.b = nil
+		e.values = values
+		e.dict = true
+		return nil
+	}
+
+	// Any other encoding is snappy.
 	var data []byte
 	if len(b) > 0 {
 		var err error
@@ -80,9 +124,8 @@ func (e *StringDecoder) SetBytes(b []byte) error {
 	}
 
 	e.b = data
-	e.l = 0
-	e.i = 0
-	e.err = nil
+	e.values = e.values[:0]
+	e.dict = false
 
 	return nil
 }
@@ -94,11 +137,19 @@ func (e *StringDecoder) Next() bool {
 	}
 
 	e.i += e.l
+	if e.dict {
+		return e.i < len(e.values)
+	}
 	return e.i < len(e.b)
 }
 
 // Read returns the next value from the decoder.
 func (e *StringDecoder) Read() string {
+	if e.dict {
+		e.l = 1
+		return e.values[e.i]
+	}
+
 	// Read the length of the string
 	length, n := binary.Uvarint(e.b[e.i:])
 	if n <= 0 {
@@ -127,3 +178,116 @@ func (e *StringDecoder) Read() string {
 func (e *StringDecoder) Error() error {
 	return e.err
 }
+
+// stringDictionary returns the distinct strings of src, in the order they first appear,
+// and the index of each string of src into them. It returns false when src has too many
+// distinct strings to benefit from dictionary encoding.
+func stringDictionary(src []string) ([]string, []uint64, bool) {
+	if len(src) == 0 {
+		return nil, nil, false
+	}
+
+	var (
+		dict    []string
+		m       = make(map[string]uint64)
+		indexes = make([]uint64, len(src))
+	)
+	for i, s := range src {
+		j, ok := m[s]
+		if !ok {
+			// Every distinct string must be used at least twice on average.
+			if len(dict) == stringDictionaryMaxSize || 2*(len(dict)+1) > len(src) {
+				return nil, nil, false
+			}
+			j = uint64(len(dict))
+			m[s] = j
+			dict = append(dict, s)
+		}
+		indexes[i] = j
+	}
+	return dict, indexes, true
+}
+
+// appendStringDictionary appends the dictionary encoding of dict and the indexes of
+// the strings into it to b.
+func appendStringDictionary(b []byte, dict []string, indexes []uint64) ([]byte, error) {
+	packed, err := simple8b.EncodeAll(indexes)
+	if err != nil {
+		return nil, err
+	}
+
+	sz := 1 + binary.MaxVarintLen64 + len(packed)*8
+	for _, s := range dict {
+		sz += binary.MaxVarintLen64 + len(s)
+	}
+	if cap(b) < sz {
+		b = make([]byte, sz)
+	} else {
+		b = b[:sz]
+	}
+
+	b[0] = stringCompressedDictionary << 4
+	n := 1
+	n += binary.PutUvarint(b[n:], uint64(len(dict)))
+	for _, s := range dict {
+		n += binary.PutUvarint(b[n:], uint64(len(s)))
+		n += copy(b[n:], s)
+	}
+	for _, v := range packed {
+		binary.BigEndian.PutUint64(b[n:], v)
+		n += 8
+	}
+	return b[:n], nil
+}
+
+// stringArrayDecodeAllDictionary decodes the dictionary encoded block b into dst.
+func stringArrayDecodeAllDictionary(b []byte, dst []string) ([]string, error) {
+	b = b[1:]
+	sz, n := binary.Uvarint(b)
+	if n <= 0 || sz > stringDictionaryMaxSize {
+		return []string{}, fmt.Errorf("failed to decode string block: invalid dictionary size")
+	}
+	b = b[n:]
+
+	// The strings of the dictionary refer to a copy of b, as b may be a mapped file.
+	var end int
+	for i := uint64(0); i < sz; i++ {
+		l, n := binary.Uvarint(b[end:])
+		if n <= 0 || uint64(len(b)-end-n) < l {
+			return []string{}, fmt.Errorf("failed to decode string block: invalid dictionary")
+		}
+		end += n + int(l)
+	}
+	data := append([]byte(nil), b[:end]...)
+	b = b[end:]
+
+	dict := make([]string, sz)
+	for i := range dict {
+		l, n := binary.Uvarint(data)
+		s := data[n : n+int(l)]
+		dict[i] = *(*string)(unsafe.Pointer(&s))
+		data = data[n+int(l):]
+	}
+
+	count, err := simple8b.CountBytes(b)
+	if err != nil {
+		return []string{}, err
+	}
+	indexes := make([]uint64, count)
+	if _, err := simple8b.DecodeBytesBigEndian(indexes, b); err != nil {
+		return []string{}, err
+	}
+
+	if cap(dst) < count {
+		dst = make([]string, count)
+	} else {
+		dst = dst[:count]
+	}
+	for i, j := range indexes {
+		if j >= sz {
+			return []string{}, fmt.Errorf("failed to decode string block: invalid dictionary index")
+		}
+		dst[i] = dict[j]
+	}
+	return dst, nil
+}
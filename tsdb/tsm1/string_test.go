@@ -130,6 +130,36 @@ func Test_StringEncoder_Quick(t *testing.T) {
 	}, nil)
 }
 
+func Test_StringEncoder_Dictionary(t *testing.T) {
+	values := make([]string, 100)
+	for i := range values {
+		values[i] = fmt.Sprintf("status %d", i%3)
+	}
+
+	enc := NewStringEncoder(1024)
+	for _, v := range values {
+		enc.Write(v)
+	}
+
+	b, err := enc.Bytes()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The encoders must agree on the encoding.
+	exp, err := StringArrayEncodeAll(values, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cmp.Equal(b, exp) {
+		t.Fatalf("unexpected bytes: -got/+exp\n%s", cmp.Diff(b, exp))
+	}
+
+	if b[0]>>4 != stringCompressedDictionary {
+		t.Fatalf("unexpected encoding: got %v, exp %v", b[0]>>4, stringCompressedDictionary)
+	}
+}
+
 func Test_StringDecoder_Empty(t *testing.T) {
 	var dec StringDecoder
 	if err := dec.SetBytes([]byte{}); err != nil {
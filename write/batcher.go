@@ -16,8 +16,45 @@ const (
 	DefaultMaxBytes = 500000
 	// DefaultInterval will flush every 10 seconds.
 	DefaultInterval = 10 * time.Second
+	// DefaultMaxRetries is the number of times a batch is retried while the destination is overloaded.
+	DefaultMaxRetries = 10
+	// DefaultMaxBackoff is the maximum wait before retrying a batch.
+	DefaultMaxBackoff = 30 * time.Second
+
+	// initialBackoff is the wait before the first retry of a batch when the destination
+	// does not say when to retry, which doubles with each retry.
+	initialBackoff = time.Second
 )
 
+// OverloadedError is returned by a write service for a write that was not accepted
+// because the destination is overloaded. The write can be retried after RetryAfter,
+// or after backing off if it is 0.
+type OverloadedError struct {
+	RetryAfter time.Duration
+	Err        error
+}
+
+// Error implements the error interface.
+func (e *OverloadedError) Error() string {
+	return e.Err.Error()
+}
+
+// Backoff returns the wait before retry n, counting from 0, of a write that failed with
+// e: its RetryAfter, or else an exponential backoff up to max.
+func (e *OverloadedError) Backoff(n int, max time.Duration) time.Duration {
+	if e.RetryAfter > 0 {
+		return e.RetryAfter
+	}
+	d := initialBackoff
+	for i := 0; i < n && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+	return d
+}
+
 // batcher is a write service that batches for another write service.
 var _ platform.WriteService = (*Batcher)(nil)
 
@@ -26,6 +63,16 @@ type Batcher struct {
 	MaxFlushBytes    int                   // MaxFlushBytes is the maximum number of bytes to buffer before flushing
 	MaxFlushInterval time.Duration         // MaxFlushInterval is the maximum amount of time to wait before flushing
 	Service          platform.WriteService // Service receives batches flushed from Batcher.
+
+	// MaxRetries is the number of times a batch is retried when Service returns an
+	// OverloadedError, DefaultMaxRetries when 0. Batches are not retried when it is negative.
+	MaxRetries int
+	// MaxBackoff is the maximum wait before retrying a batch, DefaultMaxBackoff when 0.
+	MaxBackoff time.Duration
+
+	// Checkpoint, when set, is called after every successful flush with the number of bytes
+	// of the input written so far. A failed write can be resumed by skipping that many bytes.
+	Checkpoint func(offset int64)
 }
 
 // Write reads r in batches and sends to the output.
@@ -106,6 +153,7 @@ func (b *Batcher) write(ctx context.Context, org, bucket platform.ID, lines <-ch
 
 	var line []byte
 	var more = true
+	var offset int64
 	// if read closes the channel normally, exit the loop
 	for more {
 		select {
@@ -115,22 +163,22 @@ func (b *Batcher) write(ctx context.Context, org, bucket platform.ID, lines <-ch
 			}
 			// write if we exceed the max lines OR read routine has finished
 			if len(buf) >= maxBytes || (!more && len(buf) > 0) {
-				r.Reset(buf)
 				timer.Reset(flushInterval)
-				if err := b.Service.Write(ctx, org, bucket, r); err != nil {
+				if err := b.flush(ctx, org, bucket, r, buf); err != nil {
 					errC <- err
 					return
 				}
+				offset = b.checkpoint(offset, len(buf))
 				buf = buf[:0]
 			}
 		case <-timer.C:
 			if len(buf) > 0 {
-				r.Reset(buf)
 				timer.Reset(flushInterval)
-				if err := b.Service.Write(ctx, org, bucket, r); err != nil {
+				if err := b.flush(ctx, org, bucket, r, buf); err != nil {
 					errC <- err
 					return
 				}
+				offset = b.checkpoint(offset, len(buf))
 				buf = buf[:0]
 			}
 		case <-ctx.Done():
@@ -142,6 +190,46 @@ func (b *Batcher) write(ctx context.Context, org, bucket platform.ID, lines <-ch
 	errC <- nil
 }
 
+// flush sends buf to the output, retrying while the output is overloaded.
+func (b *Batcher) flush(ctx context.Context, org, bucket platform.ID, r *bytes.Reader, buf []byte) error {
+	maxRetries := b.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = DefaultMaxRetries
+	}
+
+	maxBackoff := b.MaxBackoff
+	if maxBackoff == 0 {
+		maxBackoff = DefaultMaxBackoff
+	}
+
+	for n := 0; ; n++ {
+		r.Reset(buf)
+		err := b.Service.Write(ctx, org, bucket, r)
+		oe, ok := err.(*OverloadedError)
+		if !ok || n >= maxRetries {
+			return err
+		}
+
+		timer := time.NewTimer(oe.Backoff(n, maxBackoff))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// checkpoint reports that n more bytes of the input were written after offset, and returns
+// the new offset.
+func (b *Batcher) checkpoint(offset int64, n int) int64 {
+	offset += int64(n)
+	if b.Checkpoint != nil {
+		b.Checkpoint(offset)
+	}
+	return offset
+}
+
 // ScanLines is used in bufio.Scanner.Split to split lines of line protocol.
 func ScanLines(data []byte, atEOF bool) (advance int, token []byte, err error) {
 	if atEOF && len(data) == 0 {
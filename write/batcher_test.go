@@ -427,3 +427,100 @@ func TestBatcher_WriteTimeout(t *testing.T) {
 		t.Errorf(" Batcher.Write() with timeout got %s", got)
 	}
 }
+
+func TestBatcher_Checkpoint(t *testing.T) {
+	// The second flush fails, so only the first line is checkpointed.
+	var flushes int
+	svc := &mock.WriteService{
+		WriteF: func(ctx context.Context, org, bucket platform.ID, r io.Reader) error {
+			if _, err := ioutil.ReadAll(r); err != nil {
+				return err
+			}
+			if flushes++; flushes == 2 {
+				return fmt.Errorf("error")
+			}
+			return nil
+		},
+	}
+
+	var offsets []int64
+	b := &Batcher{
+		MaxFlushBytes: len("m1,t1=v1 f1=1\n"),
+		Service:       svc,
+		Checkpoint:    func(offset int64) { offsets = append(offsets, offset) },
+	}
+
+	r := strings.NewReader("m1,t1=v1 f1=1\nm2,t2=v2 f2=2\nm3,t3=v3 f3=3")
+	if err := b.Write(context.Background(), platform.ID(1), platform.ID(2), r); err == nil {
+		t.Fatal("Batcher.Write() expected error")
+	}
+	if want := []int64{14}; !cmp.Equal(offsets, want) {
+		t.Errorf("Batcher.Write() checkpoints -got/+want %s", cmp.Diff(offsets, want))
+	}
+}
+
+func TestBatcher_Overloaded(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxRetries int
+		overloaded int
+		want       []string
+		wantErr    bool
+	}{
+		{name: "retry", overloaded: 2, want: []string{"m1,t1=v1 f1=1\n", "m2,t2=v2 f2=2"}},
+		{name: "retries exhausted", maxRetries: 1, overloaded: 2, wantErr: true},
+		{name: "no retries", maxRetries: -1, overloaded: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The first writes are rejected as overloaded, and every write reads the whole batch.
+			var writes int
+			var got []string
+			svc := &mock.WriteService{
+				WriteF: func(ctx context.Context, org, bucket platform.ID, r io.Reader) error {
+					b, err := ioutil.ReadAll(r)
+					if err != nil {
+						return err
+					}
+					if writes++; writes <= tt.overloaded {
+						return &OverloadedError{RetryAfter: time.Millisecond, Err: fmt.Errorf("overloaded")}
+					}
+					got = append(got, string(b))
+					return nil
+				},
+			}
+
+			b := &Batcher{
+				MaxFlushBytes: len("m1,t1=v1 f1=1\n"),
+				Service:       svc,
+				MaxRetries:    tt.maxRetries,
+			}
+
+			r := strings.NewReader("m1,t1=v1 f1=1\nm2,t2=v2 f2=2")
+			err := b.Write(context.Background(), platform.ID(1), platform.ID(2), r)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Batcher.Write() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if _, ok := err.(*OverloadedError); tt.wantErr && !ok {
+				t.Errorf("Batcher.Write() error = %T, want *OverloadedError", err)
+			}
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("Batcher.Write() -got/+want %s", cmp.Diff(got, tt.want))
+			}
+		})
+	}
+}
+
+func TestOverloadedError_Backoff(t *testing.T) {
+	e := &OverloadedError{Err: fmt.Errorf("overloaded")}
+	for n, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second} {
+		if got := e.Backoff(n, 5*time.Second); got != want {
+			t.Errorf("Backoff(%d) = %v, want %v", n, got, want)
+		}
+	}
+
+	e.RetryAfter = 3 * time.Second
+	if got := e.Backoff(10, 5*time.Second); got != e.RetryAfter {
+		t.Errorf("Backoff() with Retry-After = %v, want %v", got, e.RetryAfter)
+	}
+}
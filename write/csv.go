@@ -0,0 +1,158 @@
+package write
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/csv"
+	"github.com/influxdata/platform/models"
+)
+
+// RowFunc is called with the point of each row of a write, numbered from 1, or with why
+// the row cannot be converted to a point. Returning an error stops the write.
+type RowFunc func(row int, pt models.Point, err error) error
+
+// Columns of annotated CSV that are not tags or fields of the points of a write.
+const (
+	csvMeasurementCol = "_measurement"
+	csvFieldCol       = "_field"
+	csvValueCol       = "_value"
+	csvTimeCol        = "_time"
+	csvStartCol       = "_start"
+	csvStopCol        = "_stop"
+)
+
+// DecodeCSV calls fn with the point of each row of the tables of annotated CSV, in the
+// format that flux/csv encodes query results, so that the results of a query can be written
+// back. The string columns of the group key of a table are the tags of its points, and the
+// _measurement and _time columns their measurement and time. If the table has a _field
+// column, the field of each point is named by it and has the value of the _value column.
+// Otherwise, every other column is a field. Tables of time ranges with _start and _stop
+// columns, as output by range, are written without them.
+//
+// It returns an error if the CSV cannot be decoded.
+func DecodeCSV(r io.Reader, fn RowFunc) error {
+	dec := csv.NewMultiResultDecoder(csv.ResultDecoderConfig{})
+	results, err := dec.Decode(ioutil.NopCloser(r))
+	if err != nil {
+		return err
+	}
+	defer results.Release()
+
+	row := 0
+	for results.More() {
+		err := results.Next().Tables().Do(func(tbl flux.Table) error {
+			cols := newCSVColumns(tbl.Key(), tbl.Cols())
+			return tbl.Do(func(cr flux.ColReader) error {
+				for i := 0; i < cr.Len(); i++ {
+					row++
+					pt, err := cols.point(cr, i)
+					if err := fn(row, pt, err); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return results.Err()
+}
+
+// csvColumns are the indexes of the columns of a table that make up its points, or -1
+// for columns that the table does not have.
+type csvColumns struct {
+	cols                      []flux.ColMeta
+	measurement, field, value int
+	time                      int
+	tags, fields              []int
+}
+
+func newCSVColumns(key flux.GroupKey, cols []flux.ColMeta) *csvColumns {
+	c := &csvColumns{cols: cols, measurement: -1, field: -1, value: -1, time: -1}
+	for j, col := range cols {
+		switch col.Label {
+		case csvMeasurementCol:
+			c.measurement = j
+		case csvFieldCol:
+			c.field = j
+		case csvValueCol:
+			c.value = j
+		case csvTimeCol:
+			c.time = j
+		}
+	}
+
+	for j, col := range cols {
+		switch {
+		case j == c.measurement || j == c.field || j == c.time:
+		case col.Label == csvStartCol || col.Label == csvStopCol:
+		case col.Type == flux.TString && key.HasCol(col.Label):
+			c.tags = append(c.tags, j)
+		case j == c.value && c.field >= 0:
+		default:
+			c.fields = append(c.fields, j)
+		}
+	}
+	return c
+}
+
+// point returns the point of row i of cr.
+func (c *csvColumns) point(cr flux.ColReader, i int) (models.Point, error) {
+	if c.measurement < 0 || c.cols[c.measurement].Type != flux.TString {
+		return nil, fmt.Errorf("table has no string %s column", csvMeasurementCol)
+	}
+	if c.time < 0 || c.cols[c.time].Type != flux.TTime {
+		return nil, fmt.Errorf("table has no time %s column", csvTimeCol)
+	}
+
+	tags := make(map[string]string, len(c.tags))
+	for _, j := range c.tags {
+		if v := cr.Strings(j)[i]; v != "" {
+			tags[c.cols[j].Label] = v
+		}
+	}
+
+	fields := make(models.Fields)
+	if c.field >= 0 {
+		if c.value < 0 {
+			return nil, fmt.Errorf("table has a %s column but no %s column", csvFieldCol, csvValueCol)
+		}
+		fields[cr.Strings(c.field)[i]] = csvValue(cr, c.cols[c.value], c.value, i)
+	}
+	for _, j := range c.fields {
+		fields[c.cols[j].Label] = csvValue(cr, c.cols[j], j, i)
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("row has no fields")
+	}
+
+	t := time.Unix(0, int64(cr.Times(c.time)[i]))
+	return models.NewPoint(cr.Strings(c.measurement)[i], models.NewTags(tags), fields, t)
+}
+
+// csvValue returns the value of row i of column j of cr, as a field value. Times are
+// written as integer nanoseconds since the epoch.
+func csvValue(cr flux.ColReader, col flux.ColMeta, j, i int) interface{} {
+	switch col.Type {
+	case flux.TFloat:
+		return cr.Floats(j)[i]
+	case flux.TInt:
+		return cr.Ints(j)[i]
+	case flux.TUInt:
+		return cr.UInts(j)[i]
+	case flux.TString:
+		return cr.Strings(j)[i]
+	case flux.TBool:
+		return cr.Bools(j)[i]
+	case flux.TTime:
+		return int64(cr.Times(j)[i])
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,88 @@
+package write_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/platform/models"
+	"github.com/influxdata/platform/write"
+)
+
+func TestDecodeCSV(t *testing.T) {
+	tests := []struct {
+		name   string
+		csv    string
+		points []string
+		errs   map[int]string
+	}{
+		{
+			name: "query output",
+			csv: `#datatype,string,long,dateTime:RFC3339,dateTime:RFC3339,dateTime:RFC3339,double,string,string,string
+#group,false,false,true,true,false,false,true,true,true
+#default,_result,,,,,,,,
+,result,table,_start,_stop,_time,_value,_field,_measurement,host
+,,0,2018-11-01T00:00:00Z,2018-11-02T00:00:00Z,2018-11-01T00:00:01Z,0.5,usage,cpu,a
+,,0,2018-11-01T00:00:00Z,2018-11-02T00:00:00Z,2018-11-01T00:00:02Z,0.7,usage,cpu,a
+
+#datatype,string,long,dateTime:RFC3339,dateTime:RFC3339,dateTime:RFC3339,long,string,string,string
+#group,false,false,true,true,false,false,true,true,true
+#default,_result,,,,,,,,
+,result,table,_start,_stop,_time,_value,_field,_measurement,host
+,,1,2018-11-01T00:00:00Z,2018-11-02T00:00:00Z,2018-11-01T00:00:01Z,4,cores,cpu,b
+`,
+			points: []string{
+				"cpu,host=a usage=0.5 1541030401000000000",
+				"cpu,host=a usage=0.7 1541030402000000000",
+				"cpu,host=b cores=4i 1541030401000000000",
+			},
+		},
+		{
+			name: "pivoted columns",
+			csv: `#datatype,string,long,dateTime:RFC3339,string,string,double,boolean
+#group,false,false,false,true,true,false,false
+#default,_result,,,,,,
+,result,table,_time,_measurement,host,usage,up
+,,0,2018-11-01T00:00:01Z,cpu,a,0.5,true
+`,
+			points: []string{"cpu,host=a up=true,usage=0.5 1541030401000000000"},
+		},
+		{
+			name: "rows without measurement",
+			csv: `#datatype,string,long,dateTime:RFC3339,double
+#group,false,false,false,false
+#default,_result,,,
+,result,table,_time,_value
+,,0,2018-11-01T00:00:01Z,0.5
+`,
+			errs: map[int]string{1: "table has no string _measurement column"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var points []string
+			errs := make(map[int]string)
+			err := write.DecodeCSV(strings.NewReader(tt.csv), func(row int, pt models.Point, err error) error {
+				if err != nil {
+					errs[row] = err.Error()
+				} else {
+					points = append(points, pt.String())
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(points, tt.points) {
+				t.Errorf("got points %q, want %q", points, tt.points)
+			}
+			if tt.errs == nil {
+				tt.errs = map[int]string{}
+			}
+			if !reflect.DeepEqual(errs, tt.errs) {
+				t.Errorf("got row errors %v, want %v", errs, tt.errs)
+			}
+		})
+	}
+}
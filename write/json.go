@@ -0,0 +1,226 @@
+package write
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/platform"
+	"github.com/influxdata/platform/models"
+)
+
+// JSONMapping declares the columns of the rows of a JSON write that are the measurement,
+// tags, fields and time of their points.
+type JSONMapping struct {
+	// Measurement is the column of the measurement of each point.
+	Measurement string   `json:"measurement"`
+	Tags        []string `json:"tags"`
+	// Fields are the types of the field columns, one of the platform.FieldType constants.
+	Fields map[string]string `json:"fields"`
+	// Time is the column of the time of each point, either a timestamp of the precision
+	// of the write or an RFC3339 string. Points have the time of the write if it is empty.
+	Time string `json:"time"`
+}
+
+// Validate returns an error if the mapping has no measurement or fields, or a field has
+// an unknown type.
+func (m *JSONMapping) Validate() error {
+	if m.Measurement == "" {
+		return fmt.Errorf("mapping has no measurement column")
+	}
+	if len(m.Fields) == 0 {
+		return fmt.Errorf("mapping has no field columns")
+	}
+	for col, typ := range m.Fields {
+		switch typ {
+		case platform.FieldTypeFloat, platform.FieldTypeInteger, platform.FieldTypeUnsigned, platform.FieldTypeString, platform.FieldTypeBoolean:
+		default:
+			return fmt.Errorf("field column %q has unknown type %q", col, typ)
+		}
+	}
+	return nil
+}
+
+// DecodeJSON calls fn with the point of each row of a JSON write, an object with a mapping
+// of the columns of its rows and an array of rows, each an object of columns:
+//
+//	{
+//	  "mapping": {"measurement": "name", "tags": ["host"], "fields": {"usage": "float"}, "time": "ts"},
+//	  "rows": [{"name": "cpu", "host": "a", "usage": 0.5, "ts": "2018-11-01T00:00:00Z"}]
+//	}
+//
+// The rows are decoded one at a time, so the mapping must come before them. Numeric times
+// are of precision, and rows without a time column have the time now. Columns of rows that
+// are not declared by the mapping are ignored, as are null columns.
+//
+// It returns an error if the JSON cannot be decoded.
+func DecodeJSON(r io.Reader, precision string, now time.Time, fn RowFunc) error {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+
+	var mapping *JSONMapping
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		switch tok {
+		case "mapping":
+			mapping = &JSONMapping{}
+			if err := dec.Decode(mapping); err != nil {
+				return err
+			}
+			if err := mapping.Validate(); err != nil {
+				return err
+			}
+		case "rows":
+			if mapping == nil {
+				return fmt.Errorf("rows must follow the mapping")
+			}
+			if err := decodeJSONRows(dec, mapping, precision, now, fn); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unknown key %v", tok)
+		}
+	}
+	return expectDelim(dec, '}')
+}
+
+func decodeJSONRows(dec *json.Decoder, m *JSONMapping, precision string, now time.Time, fn RowFunc) error {
+	if err := expectDelim(dec, '['); err != nil {
+		return err
+	}
+	for row := 1; dec.More(); row++ {
+		var cols map[string]interface{}
+		if err := dec.Decode(&cols); err != nil {
+			return fmt.Errorf("row %d: %v", row, err)
+		}
+		pt, err := m.point(cols, precision, now)
+		if err := fn(row, pt, err); err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, ']')
+}
+
+func expectDelim(dec *json.Decoder, d json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != d {
+		return fmt.Errorf("expected %v, got %v", d, tok)
+	}
+	return nil
+}
+
+// point returns the point of the columns of a row.
+func (m *JSONMapping) point(cols map[string]interface{}, precision string, now time.Time) (models.Point, error) {
+	name, ok := cols[m.Measurement].(string)
+	if !ok || name == "" {
+		return nil, fmt.Errorf("measurement column %q is not a string", m.Measurement)
+	}
+
+	tags := make(map[string]string, len(m.Tags))
+	for _, col := range m.Tags {
+		switch v := cols[col].(type) {
+		case nil:
+		case string:
+			if v != "" {
+				tags[col] = v
+			}
+		case json.Number:
+			tags[col] = v.String()
+		default:
+			return nil, fmt.Errorf("tag column %q is not a string", col)
+		}
+	}
+
+	fields := make(models.Fields, len(m.Fields))
+	for col, typ := range m.Fields {
+		v, ok := cols[col]
+		if !ok || v == nil {
+			continue
+		}
+		fv, err := jsonFieldValue(v, typ)
+		if err != nil {
+			return nil, fmt.Errorf("field column %q: %v", col, err)
+		}
+		fields[col] = fv
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("row has no fields")
+	}
+
+	t := now
+	if m.Time != "" {
+		var err error
+		if t, err = jsonTime(cols[m.Time], precision); err != nil {
+			return nil, fmt.Errorf("time column %q: %v", m.Time, err)
+		}
+	}
+
+	return models.NewPoint(name, models.NewTags(tags), fields, t)
+}
+
+// jsonFieldValue returns v as a field value of type typ.
+func jsonFieldValue(v interface{}, typ string) (interface{}, error) {
+	switch typ {
+	case platform.FieldTypeString:
+		if s, ok := v.(string); ok {
+			return s, nil
+		}
+	case platform.FieldTypeBoolean:
+		if b, ok := v.(bool); ok {
+			return b, nil
+		}
+	default:
+		n, ok := v.(json.Number)
+		if !ok {
+			break
+		}
+		switch typ {
+		case platform.FieldTypeFloat:
+			return strconv.ParseFloat(n.String(), 64)
+		case platform.FieldTypeInteger:
+			return strconv.ParseInt(n.String(), 10, 64)
+		case platform.FieldTypeUnsigned:
+			return strconv.ParseUint(n.String(), 10, 64)
+		}
+	}
+	return nil, fmt.Errorf("value %v is not of type %s", v, typ)
+}
+
+// jsonTime returns v as a time, either a timestamp of precision or an RFC3339 string.
+func jsonTime(v interface{}, precision string) (time.Time, error) {
+	switch v := v.(type) {
+	case json.Number:
+		ts, err := strconv.ParseInt(v.String(), 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return time.Unix(0, ts*precisionMultiplier(precision)), nil
+	case string:
+		return time.Parse(time.RFC3339Nano, v)
+	case nil:
+		return time.Time{}, fmt.Errorf("missing time")
+	default:
+		return time.Time{}, fmt.Errorf("value %v is not a time", v)
+	}
+}
+
+// precisionMultiplier returns the number of nanoseconds of a timestamp of precision.
+func precisionMultiplier(precision string) int64 {
+	if precision == "us" {
+		precision = "u"
+	}
+	return models.GetPrecisionMultiplier(precision)
+}
@@ -0,0 +1,95 @@
+package write_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/platform/models"
+	"github.com/influxdata/platform/write"
+)
+
+func TestDecodeJSON(t *testing.T) {
+	now := time.Unix(10, 0)
+	tests := []struct {
+		name      string
+		json      string
+		precision string
+		points    []string
+		errs      map[int]string
+		err       string
+	}{
+		{
+			name: "rows",
+			json: `{
+  "mapping": {"measurement": "name", "tags": ["host"], "fields": {"usage": "float", "cores": "integer", "up": "boolean"}, "time": "ts"},
+  "rows": [
+    {"name": "cpu", "host": "a", "usage": 1, "cores": 4, "up": true, "ts": 1},
+    {"name": "cpu", "host": "b", "usage": 0.5, "ts": "1970-01-01T00:00:02Z", "other": "ignored"},
+    {"name": "cpu", "host": "c", "cores": 1.5, "ts": 3},
+    {"host": "d", "usage": 1, "ts": 4}
+  ]
+}`,
+			precision: "s",
+			points: []string{
+				"cpu,host=a cores=4i,up=true,usage=1 1000000000",
+				"cpu,host=b usage=0.5 2000000000",
+			},
+			errs: map[int]string{
+				3: `field column "cores": strconv.ParseInt: parsing "1.5": invalid syntax`,
+				4: `measurement column "name" is not a string`,
+			},
+		},
+		{
+			name: "rows without time column",
+			json: `{"mapping": {"measurement": "name", "fields": {"msg": "string"}}, "rows": [{"name": "log", "msg": "started"}]}`,
+			points: []string{
+				`log msg="started" 10000000000`,
+			},
+		},
+		{
+			name: "rows before mapping",
+			json: `{"rows": [], "mapping": {"measurement": "name", "fields": {"msg": "string"}}}`,
+			err:  "rows must follow the mapping",
+		},
+		{
+			name: "unknown field type",
+			json: `{"mapping": {"measurement": "name", "fields": {"msg": "text"}}, "rows": []}`,
+			err:  `field column "msg" has unknown type "text"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var points []string
+			errs := make(map[int]string)
+			err := write.DecodeJSON(strings.NewReader(tt.json), tt.precision, now, func(row int, pt models.Point, err error) error {
+				if err != nil {
+					errs[row] = err.Error()
+				} else {
+					points = append(points, pt.String())
+				}
+				return nil
+			})
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("got error %v, want %s", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(points, tt.points) {
+				t.Errorf("got points %q, want %q", points, tt.points)
+			}
+			if tt.errs == nil {
+				tt.errs = map[int]string{}
+			}
+			if !reflect.DeepEqual(errs, tt.errs) {
+				t.Errorf("got row errors %v, want %v", errs, tt.errs)
+			}
+		})
+	}
+}